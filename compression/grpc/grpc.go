@@ -17,6 +17,7 @@ package grpc
 import (
 	"strings"
 
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/encoding/gzip"
 
 	"github.com/open-telemetry/opentelemetry-service/compression"
@@ -25,16 +26,20 @@ import (
 var (
 	// Map of opencensus compression types to grpc registered compression types
 	grpcCompressionKeyMap = map[string]string{
-		compression.Gzip: gzip.Name,
+		compression.Gzip:   gzip.Name,
+		compression.Snappy: snappyName,
+		compression.Zstd:   zstdName,
 	}
 )
 
 // GetGRPCCompressionKey returns the grpc registered compression key if the
-// passed in compression key is supported, and Unsupported otherwise
+// passed in compression key is supported, and Unsupported otherwise.
 func GetGRPCCompressionKey(compressionType string) string {
 	compressionKey := strings.ToLower(compressionType)
 	if encodingKey, ok := grpcCompressionKeyMap[compressionKey]; ok {
-		return encodingKey
+		if encoding.GetCompressor(encodingKey) != nil {
+			return encodingKey
+		}
 	}
 	return compression.Unsupported
 }
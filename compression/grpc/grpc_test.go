@@ -15,8 +15,15 @@
 package grpc
 
 import (
+	"bytes"
+	"io/ioutil"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+
 	"github.com/open-telemetry/opentelemetry-service/compression"
 )
 
@@ -29,7 +36,43 @@ func TestGetGRPCCompressionKey(t *testing.T) {
 		t.Error("Capitalization of Gzip should not matter")
 	}
 
+	if GetGRPCCompressionKey("snappy") != compression.Snappy {
+		t.Error("snappy is marked as supported but returned unsupported")
+	}
+
+	if GetGRPCCompressionKey("zstd") != compression.Zstd {
+		t.Error("zstd is marked as supported but returned unsupported")
+	}
+
 	if GetGRPCCompressionKey("badType") != compression.Unsupported {
 		t.Error("badType is not supported but was returned as supported")
 	}
 }
+
+func TestCompressorRoundTrip(t *testing.T) {
+	for _, name := range []string{compression.Gzip, compression.Snappy, compression.Zstd} {
+		t.Run(name, func(t *testing.T) {
+			testCompressorRoundTrip(t, name)
+		})
+	}
+}
+
+func testCompressorRoundTrip(t *testing.T, name string) {
+	c := encoding.GetCompressor(name)
+	require.NotNil(t, c)
+
+	payload := []byte(strings.Repeat("opentelemetry", 1000))
+	var buf bytes.Buffer
+	w, err := c.Compress(&buf)
+	require.NoError(t, err)
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.True(t, buf.Len() < len(payload))
+
+	r, err := c.Decompress(&buf)
+	require.NoError(t, err)
+	got, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload, got)
+}
@@ -0,0 +1,85 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/snappy"
+	"google.golang.org/grpc/encoding"
+)
+
+// snappyName is the name registered for the snappy compressor.
+const snappyName = "snappy"
+
+func init() {
+	c := &snappyCompressor{}
+	c.poolCompressor.New = func() interface{} {
+		return &snappyWriter{Writer: snappy.NewBufferedWriter(ioutil.Discard), pool: &c.poolCompressor}
+	}
+	c.poolDecompressor.New = func() interface{} {
+		return &snappyReader{Reader: snappy.NewReader(nil), pool: &c.poolDecompressor}
+	}
+	encoding.RegisterCompressor(c)
+}
+
+// snappyCompressor implements the grpc encoding.Compressor interface using the
+// snappy framing format. Writers and readers are pooled since each one holds
+// buffers sized for a full snappy block.
+type snappyCompressor struct {
+	poolCompressor   sync.Pool
+	poolDecompressor sync.Pool
+}
+
+func (c *snappyCompressor) Name() string {
+	return snappyName
+}
+
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.poolCompressor.Get().(*snappyWriter)
+	z.Writer.Reset(w)
+	return z, nil
+}
+
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	z := c.poolDecompressor.Get().(*snappyReader)
+	z.Reader.Reset(r)
+	return z, nil
+}
+
+type snappyWriter struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+func (z *snappyWriter) Close() error {
+	defer z.pool.Put(z)
+	return z.Writer.Close()
+}
+
+type snappyReader struct {
+	*snappy.Reader
+	pool *sync.Pool
+}
+
+func (z *snappyReader) Read(p []byte) (n int, err error) {
+	n, err = z.Reader.Read(p)
+	if err == io.EOF {
+		z.pool.Put(z)
+	}
+	return n, err
+}
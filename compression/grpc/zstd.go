@@ -0,0 +1,96 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// zstdName is the name registered for the zstd compressor.
+const zstdName = "zstd"
+
+func init() {
+	c := &zstdCompressor{}
+	c.poolCompressor.New = func() interface{} {
+		// Concurrency 1 keeps each encoder single threaded, gRPC already
+		// compresses messages of different streams in parallel.
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return &zstdWriter{Encoder: enc, pool: &c.poolCompressor}
+	}
+	c.poolDecompressor.New = func() interface{} {
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return &zstdReader{Decoder: dec, pool: &c.poolDecompressor}
+	}
+	encoding.RegisterCompressor(c)
+}
+
+// zstdCompressor implements the grpc encoding.Compressor interface using
+// zstd. Encoders and decoders are pooled since they are expensive to create.
+type zstdCompressor struct {
+	poolCompressor   sync.Pool
+	poolDecompressor sync.Pool
+}
+
+func (c *zstdCompressor) Name() string {
+	return zstdName
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.poolCompressor.Get().(*zstdWriter)
+	z.Encoder.Reset(w)
+	return z, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	z := c.poolDecompressor.Get().(*zstdReader)
+	if err := z.Decoder.Reset(r); err != nil {
+		c.poolDecompressor.Put(z)
+		return nil, err
+	}
+	return z, nil
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (z *zstdWriter) Close() error {
+	defer z.pool.Put(z)
+	return z.Encoder.Close()
+}
+
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (z *zstdReader) Read(p []byte) (n int, err error) {
+	n, err = z.Decoder.Read(p)
+	if err == io.EOF {
+		z.pool.Put(z)
+	}
+	return n, err
+}
@@ -20,12 +20,14 @@ package config
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/connector"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/extension"
 	"github.com/open-telemetry/opentelemetry-service/processor"
@@ -52,15 +54,20 @@ const (
 	errMissingPipelines
 	errPipelineMustHaveReceiver
 	errPipelineMustHaveExporter
-	errPipelineMustHaveProcessors
 	errExtensionNotExists
 	errPipelineReceiverNotExists
 	errPipelineProcessorNotExists
 	errPipelineExporterNotExists
-	errMetricPipelineCannotHaveProcessors
 	errUnmarshalError
 	errMissingReceivers
 	errMissingExporters
+	errUnknownConnectorType
+	errDuplicateConnectorName
+	errConnectorNameConflict
+	errConnectorNotConnected
+	errPipelinesCycle
+	errInvalidProcessorOrdering
+	errPipelineProcessorsOrder
 )
 
 type configError struct {
@@ -89,6 +96,9 @@ const (
 	// processorsKeyName is the configuration key name for processors section.
 	processorsKeyName = "processors"
 
+	// connectorsKeyName is the configuration key name for connectors section.
+	connectorsKeyName = "connectors"
+
 	// pipelinesKeyName is the configuration key name for pipelines section.
 	pipelinesKeyName = "pipelines"
 )
@@ -110,6 +120,9 @@ type Factories struct {
 
 	// Extensions maps extension type names in the config to the respective factory.
 	Extensions map[string]extension.Factory
+
+	// Connectors maps connector type names in the config to the respective factory.
+	Connectors map[string]connector.Factory
 }
 
 // Load loads a Config from Viper.
@@ -137,7 +150,8 @@ func Load(
 	}
 	config.Service = service
 
-	// Load data components (receivers, exporters, processores, and pipelines).
+	// Load data components (receivers, exporters, processores, connectors, and
+	// pipelines).
 
 	receivers, err := loadReceivers(v, factories.Receivers)
 	if err != nil {
@@ -157,6 +171,12 @@ func Load(
 	}
 	config.Processors = processors
 
+	connectors, err := loadConnectors(v, factories.Connectors)
+	if err != nil {
+		return nil, err
+	}
+	config.Connectors = connectors
+
 	pipelines, err := loadPipelines(v)
 	if err != nil {
 		return nil, err
@@ -169,6 +189,10 @@ func Load(
 		return nil, err
 	}
 
+	if err := validateProcessorsOrder(&config, factories.Processors, logger); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -247,7 +271,11 @@ func loadExtensions(v *viper.Viper, factories map[string]extension.Factory) (con
 
 		// Now that the default config struct is created we can Unmarshal into it
 		// and it will apply user-defined config on top of the default.
-		if err := subViper.UnmarshalKey(key, extensionCfg); err != nil {
+		var customUnmarshaler extension.CustomUnmarshaler
+		if f, ok := factory.(extension.CustomUnmarshalerFactory); ok {
+			customUnmarshaler = f.CustomUnmarshaler()
+		}
+		if err := unmarshal(subViper, key, extensionCfg, customUnmarshaler); err != nil {
 			return nil, &configError{
 				code: errUnmarshalError,
 				msg:  fmt.Sprintf("error reading settings for extension type %q: %v", typeStr, err),
@@ -267,6 +295,25 @@ func loadExtensions(v *viper.Viper, factories map[string]extension.Factory) (con
 	return extensions, nil
 }
 
+// unmarshal applies the settings of the component under viperKey to its
+// default config, with the custom unmarshaler of its factory if not nil.
+func unmarshal(
+	v *viper.Viper,
+	viperKey string,
+	intoCfg interface{},
+	customUnmarshaler func(v *viper.Viper, viperKey string, intoCfg interface{}) error,
+) error {
+	if customUnmarshaler != nil {
+		// This configuration requires a custom unmarshaler, use it.
+		return customUnmarshaler(v, viperKey, intoCfg)
+	}
+	// Standard viper unmarshaler is fine.
+	// TODO(ccaraman): UnmarshallExact should be used to catch erroneous config entries.
+	// 	This leads to quickly identifying config values that are not supported and reduce confusion for
+	// 	users.
+	return v.UnmarshalKey(viperKey, intoCfg)
+}
+
 func loadService(v *viper.Viper) (configmodels.Service, error) {
 	var service configmodels.Service
 	if err := v.UnmarshalKey(serviceKeyName, &service); err != nil {
@@ -325,18 +372,7 @@ func loadReceivers(v *viper.Viper, factories map[string]receiver.Factory) (confi
 
 		// Now that the default config struct is created we can Unmarshal into it
 		// and it will apply user-defined config on top of the default.
-		customUnmarshaler := factory.CustomUnmarshaler()
-		if customUnmarshaler != nil {
-			// This configuration requires a custom unmarshaler, use it.
-			err = customUnmarshaler(subViper, key, receiverCfg)
-		} else {
-			// Standard viper unmarshaler is fine.
-			// TODO(ccaraman): UnmarshallExact should be used to catch erroneous config entries.
-			// 	This leads to quickly identifying config values that are not supported and reduce confusion for
-			// 	users.
-			err = subViper.UnmarshalKey(key, receiverCfg)
-		}
-
+		err = unmarshal(subViper, key, receiverCfg, factory.CustomUnmarshaler())
 		if err != nil {
 			return nil, &configError{
 				code: errUnmarshalError,
@@ -402,7 +438,11 @@ func loadExporters(v *viper.Viper, factories map[string]exporter.Factory) (confi
 
 		// Now that the default config struct is created we can Unmarshal into it
 		// and it will apply user-defined config on top of the default.
-		if err := subViper.UnmarshalKey(key, exporterCfg); err != nil {
+		var customUnmarshaler exporter.CustomUnmarshaler
+		if f, ok := factory.(exporter.CustomUnmarshalerFactory); ok {
+			customUnmarshaler = f.CustomUnmarshaler()
+		}
+		if err := unmarshal(subViper, key, exporterCfg, customUnmarshaler); err != nil {
 			return nil, &configError{
 				code: errUnmarshalError,
 				msg:  fmt.Sprintf("error reading settings for exporter type %q: %v", typeStr, err),
@@ -459,7 +499,11 @@ func loadProcessors(v *viper.Viper, factories map[string]processor.Factory) (con
 
 		// Now that the default config struct is created we can Unmarshal into it
 		// and it will apply user-defined config on top of the default.
-		if err := subViper.UnmarshalKey(key, processorCfg); err != nil {
+		var customUnmarshaler processor.CustomUnmarshaler
+		if f, ok := factory.(processor.CustomUnmarshalerFactory); ok {
+			customUnmarshaler = f.CustomUnmarshaler()
+		}
+		if err := unmarshal(subViper, key, processorCfg, customUnmarshaler); err != nil {
 			return nil, &configError{
 				code: errUnmarshalError,
 				msg:  fmt.Sprintf("error reading settings for processor type %q: %v", typeStr, err),
@@ -479,6 +523,63 @@ func loadProcessors(v *viper.Viper, factories map[string]processor.Factory) (con
 	return processors, nil
 }
 
+func loadConnectors(v *viper.Viper, factories map[string]connector.Factory) (configmodels.Connectors, error) {
+	// Get the list of all "connectors" sub vipers from config source.
+	subViper := v.Sub(connectorsKeyName)
+
+	// Get the map of "connectors" sub-keys.
+	keyMap := v.GetStringMap(connectorsKeyName)
+
+	// Prepare resulting map.
+	connectors := make(configmodels.Connectors)
+
+	// Iterate over connectors and create a config for each.
+	for key := range keyMap {
+		// Decode the key into type and fullName components.
+		typeStr, fullName, err := decodeTypeAndName(key)
+		if err != nil || typeStr == "" {
+			return nil, &configError{
+				code: errInvalidTypeAndNameKey,
+				msg:  fmt.Sprintf("invalid key %q: %s", key, err.Error()),
+			}
+		}
+
+		// Find connector factory based on "type" that we read from config source.
+		factory := factories[typeStr]
+		if factory == nil {
+			return nil, &configError{
+				code: errUnknownConnectorType,
+				msg:  fmt.Sprintf("unknown connector type %q", typeStr),
+			}
+		}
+
+		// Create the default config for this connector.
+		connectorCfg := factory.CreateDefaultConfig()
+		connectorCfg.SetType(typeStr)
+		connectorCfg.SetName(fullName)
+
+		// Now that the default config struct is created we can Unmarshal into it
+		// and it will apply user-defined config on top of the default.
+		if err := unmarshal(subViper, key, connectorCfg, nil); err != nil {
+			return nil, &configError{
+				code: errUnmarshalError,
+				msg:  fmt.Sprintf("error reading settings for connector type %q: %v", typeStr, err),
+			}
+		}
+
+		if connectors[fullName] != nil {
+			return nil, &configError{
+				code: errDuplicateConnectorName,
+				msg:  fmt.Sprintf("duplicate connector name %q", fullName),
+			}
+		}
+
+		connectors[fullName] = connectorCfg
+	}
+
+	return connectors, nil
+}
+
 func loadPipelines(v *viper.Viper) (configmodels.Pipelines, error) {
 	// Get the list of all "pipelines" sub vipers from config source.
 	subViper := v.Sub(pipelinesKeyName)
@@ -549,10 +650,18 @@ func validateConfig(cfg *configmodels.Config, logger *zap.Logger) error {
 		return err
 	}
 
+	if err := validateConnectorNames(cfg); err != nil {
+		return err
+	}
+
 	if err := validatePipelines(cfg, logger); err != nil {
 		return err
 	}
 
+	if err := validateConnectors(cfg); err != nil {
+		return err
+	}
+
 	if err := validateReceivers(cfg); err != nil {
 		return err
 	}
@@ -657,7 +766,8 @@ func validatePipelineReceivers(
 	// Validate pipeline receiver name references.
 	for _, ref := range pipeline.Receivers {
 		// Check that the name referenced in the pipeline's Receivers exists in the top-level Receivers
-		if cfg.Receivers[ref] == nil {
+		// or Connectors.
+		if cfg.Receivers[ref] == nil && cfg.Connectors[ref] == nil {
 			return &configError{
 				code: errPipelineReceiverNotExists,
 				msg:  fmt.Sprintf("pipeline %q references receiver %q which does not exists", pipeline.Name, ref),
@@ -668,6 +778,16 @@ func validatePipelineReceivers(
 	// Remove disabled receivers.
 	rs := pipeline.Receivers[:0]
 	for _, ref := range pipeline.Receivers {
+		if conn := cfg.Connectors[ref]; conn != nil {
+			if conn.IsEnabled() {
+				rs = append(rs, ref)
+			} else {
+				logger.Info("pipeline references a disabled connector. Ignoring the connector.",
+					zap.String("pipeline", pipeline.Name),
+					zap.String("connector", ref))
+			}
+			continue
+		}
 		rcv := cfg.Receivers[ref]
 		if rcv.IsEnabled() {
 			// The receiver is enabled. Keep it in the pipeline.
@@ -699,7 +819,8 @@ func validatePipelineExporters(
 	// Validate pipeline exporter name references.
 	for _, ref := range pipeline.Exporters {
 		// Check that the name referenced in the pipeline's Exporters exists in the top-level Exporters
-		if cfg.Exporters[ref] == nil {
+		// or Connectors.
+		if cfg.Exporters[ref] == nil && cfg.Connectors[ref] == nil {
 			return &configError{
 				code: errPipelineExporterNotExists,
 				msg:  fmt.Sprintf("pipeline %q references exporter %q which does not exists", pipeline.Name, ref),
@@ -710,6 +831,16 @@ func validatePipelineExporters(
 	// Remove disabled exporters.
 	rs := pipeline.Exporters[:0]
 	for _, ref := range pipeline.Exporters {
+		if conn := cfg.Connectors[ref]; conn != nil {
+			if conn.IsEnabled() {
+				rs = append(rs, ref)
+			} else {
+				logger.Info("pipeline references a disabled connector. Ignoring the connector.",
+					zap.String("pipeline", pipeline.Name),
+					zap.String("connector", ref))
+			}
+			continue
+		}
 		exp := cfg.Exporters[ref]
 		if exp.IsEnabled() {
 			// The exporter is enabled. Keep it in the pipeline.
@@ -730,24 +861,6 @@ func validatePipelineProcessors(
 	pipeline *configmodels.Pipeline,
 	logger *zap.Logger,
 ) error {
-	if pipeline.InputType == configmodels.TracesDataType {
-		// Traces pipeline must have at least one processor.
-		if len(pipeline.Processors) == 0 {
-			return &configError{
-				code: errPipelineMustHaveProcessors,
-				msg:  fmt.Sprintf("pipeline %q must have at least one processor", pipeline.Name),
-			}
-		}
-	} else if pipeline.InputType == configmodels.MetricsDataType {
-		// Metrics pipeline cannot have processors.
-		if len(pipeline.Processors) > 0 {
-			return &configError{
-				code: errMetricPipelineCannotHaveProcessors,
-				msg:  fmt.Sprintf("metrics pipeline %q cannot have processors", pipeline.Name),
-			}
-		}
-	}
-
 	// Validate pipeline processor name references
 	for _, ref := range pipeline.Processors {
 		// Check that the name referenced in the pipeline's processors exists in the top-level processors.
@@ -822,3 +935,117 @@ func validateProcessors(cfg *configmodels.Config) {
 		}
 	}
 }
+
+func validateConnectorNames(cfg *configmodels.Config) error {
+	// The pipelines reference the connectors as receivers and exporters, their
+	// names must not be ambiguous.
+	for name := range cfg.Connectors {
+		if cfg.Receivers[name] != nil || cfg.Exporters[name] != nil {
+			return &configError{
+				code: errConnectorNameConflict,
+				msg:  fmt.Sprintf("connector %q has the name of a receiver or an exporter", name),
+			}
+		}
+	}
+	return nil
+}
+
+func validateConnectors(cfg *configmodels.Config) error {
+	// Remove disabled connectors.
+	for name, conn := range cfg.Connectors {
+		if !conn.IsEnabled() {
+			delete(cfg.Connectors, name)
+		}
+	}
+
+	// Each connector must link the pipelines exporting to it to the pipelines
+	// receiving from it.
+	for name := range cfg.Connectors {
+		exported, received := false, false
+		for _, pipeline := range cfg.Pipelines {
+			exported = exported || contains(pipeline.Exporters, name)
+			received = received || contains(pipeline.Receivers, name)
+		}
+		if !exported || !received {
+			return &configError{
+				code: errConnectorNotConnected,
+				msg: fmt.Sprintf("connector %q must be used as exporter by a pipeline and as receiver by another pipeline",
+					name),
+			}
+		}
+	}
+
+	return validatePipelinesAcyclic(cfg)
+}
+
+// validatePipelinesAcyclic checks that the pipelines linked by the connectors
+// don't form a cycle, the data would otherwise loop forever.
+func validatePipelinesAcyclic(cfg *configmodels.Config) error {
+	if len(cfg.Connectors) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Pipelines))
+	for name := range cfg.Pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// The pipelines receiving the data exported by each pipeline.
+	next := make(map[string][]string, len(names))
+	for _, from := range names {
+		for _, to := range names {
+			for _, ref := range cfg.Pipelines[from].Exporters {
+				if cfg.Connectors[ref] != nil && contains(cfg.Pipelines[to].Receivers, ref) {
+					next[from] = append(next[from], to)
+					break
+				}
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return &configError{
+				code: errPipelinesCycle,
+				msg:  fmt.Sprintf("pipelines are connected in a cycle: %s", strings.Join(append(path, name), " -> ")),
+			}
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, to := range next[name] {
+			if err := visit(to); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -15,12 +15,20 @@
 package config
 
 import (
+	"fmt"
 	"path"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/processor"
 )
 
 func TestDecodeConfig(t *testing.T) {
@@ -174,6 +182,131 @@ func TestDecodeConfig_MultiProto(t *testing.T) {
 		"Did not load receiver config correctly")
 }
 
+// joinExtraUnmarshaler accepts either a string or a list of strings for the
+// "extra" setting of the example components.
+func joinExtraUnmarshaler(v *viper.Viper, viperKey string, intoCfg interface{}) error {
+	extra := v.GetString(viperKey + ".extra")
+	if list, ok := v.Get(viperKey + ".extra").([]interface{}); ok {
+		extra = strings.Join(cast.ToStringSlice(list), ",")
+	}
+	switch cfg := intoCfg.(type) {
+	case *ExampleExporter:
+		cfg.ExtraSetting = extra
+	case *ExampleProcessor:
+		cfg.ExtraSetting = extra
+	case *ExampleExtension:
+		cfg.ExtraSetting = extra
+	default:
+		return fmt.Errorf("unexpected config type %T", intoCfg)
+	}
+	return nil
+}
+
+type customExporterFactory struct {
+	ExampleExporterFactory
+}
+
+func (f *customExporterFactory) CustomUnmarshaler() exporter.CustomUnmarshaler {
+	return joinExtraUnmarshaler
+}
+
+type customProcessorFactory struct {
+	ExampleProcessorFactory
+}
+
+func (f *customProcessorFactory) CustomUnmarshaler() processor.CustomUnmarshaler {
+	return joinExtraUnmarshaler
+}
+
+type customExtensionFactory struct {
+	ExampleExtensionFactory
+}
+
+func (f *customExtensionFactory) CustomUnmarshaler() extension.CustomUnmarshaler {
+	return joinExtraUnmarshaler
+}
+
+func TestDecodeConfig_CustomUnmarshaler(t *testing.T) {
+	factories, err := ExampleComponents()
+	assert.Nil(t, err)
+
+	// Without the custom unmarshalers the lists can't be decoded.
+	_, err = LoadConfigFile(t, path.Join(".", "testdata", "custom-unmarshaler.yaml"), factories)
+	assert.Error(t, err)
+
+	factories.Exporters["exampleexporter"] = &customExporterFactory{}
+	factories.Processors["exampleprocessor"] = &customProcessorFactory{}
+	factories.Extensions["exampleextension"] = &customExtensionFactory{}
+
+	config, err := LoadConfigFile(t, path.Join(".", "testdata", "custom-unmarshaler.yaml"), factories)
+	if err != nil {
+		t.Fatalf("unable to load config, %v", err)
+	}
+
+	assert.Equal(t, "exporter,list", config.Exporters["exampleexporter"].(*ExampleExporter).ExtraSetting)
+	assert.Equal(t, "exporter string", config.Exporters["exampleexporter/string"].(*ExampleExporter).ExtraSetting)
+	assert.Equal(t, "exampleexporter/string", config.Exporters["exampleexporter/string"].Name())
+	assert.Equal(t, "processor,list", config.Processors["exampleprocessor"].(*ExampleProcessor).ExtraSetting)
+	assert.Equal(t, "extension,list", config.Extensions["exampleextension"].(*ExampleExtension).ExtraSetting)
+}
+
+func TestDecodeConfig_Connectors(t *testing.T) {
+	factories, err := ExampleComponents()
+	assert.Nil(t, err)
+
+	config, err := LoadConfigFile(t, path.Join(".", "testdata", "connectors-config.yaml"), factories)
+	if err != nil {
+		t.Fatalf("unable to load config, %v", err)
+	}
+
+	// The disabled connector is removed from the config and the pipelines.
+	assert.Equal(t, 2, len(config.Connectors))
+	assert.Equal(t,
+		&ExampleConnector{
+			ConnectorSettings: configmodels.ConnectorSettings{
+				TypeVal: "exampleconnector",
+				NameVal: "exampleconnector",
+			},
+			ExtraSetting: "some connector string",
+		},
+		config.Connectors["exampleconnector"])
+	assert.Equal(t,
+		&ExampleConnector{
+			ConnectorSettings: configmodels.ConnectorSettings{
+				TypeVal: "exampleconnector",
+				NameVal: "exampleconnector/metrics",
+			},
+			ExtraSetting: "some metrics string",
+		},
+		config.Connectors["exampleconnector/metrics"])
+
+	assert.Equal(t, []string{"exampleconnector"}, config.Pipelines["traces"].Exporters)
+	assert.Equal(t, []string{"exampleconnector"}, config.Pipelines["traces/downstream"].Receivers)
+	assert.Equal(t, []string{"exampleconnector/metrics"}, config.Pipelines["metrics"].Exporters)
+	assert.Equal(t, []string{"exampleconnector/metrics"}, config.Pipelines["metrics/downstream"].Receivers)
+}
+
+func TestDecodeConfig_ConnectorNameConflict(t *testing.T) {
+	factories, err := ExampleComponents()
+	assert.Nil(t, err)
+	factories.Connectors["exampleexporter"] = &ExampleConnectorFactory{}
+
+	_, err = LoadConfigFile(t, path.Join(".", "testdata", "connector-name-conflict.yaml"), factories)
+	require.Error(t, err)
+	assert.Equal(t, errConnectorNameConflict, err.(*configError).code)
+}
+
+func TestDecodeConfig_PipelineWithoutProcessors(t *testing.T) {
+	factories, err := ExampleComponents()
+	assert.Nil(t, err)
+
+	// The traces pipelines without processors relay the raw data of the
+	// receivers to the exporters supporting it.
+	config, err := LoadConfigFile(t, path.Join(".", "testdata", "pipeline-without-processors.yaml"), factories)
+	require.NoError(t, err)
+	assert.Empty(t, config.Pipelines["traces"].Processors)
+}
+
 func TestDecodeConfig_Invalid(t *testing.T) {
 
 	var testCases = []struct {
@@ -200,8 +333,6 @@ func TestDecodeConfig_Invalid(t *testing.T) {
 		{name: "pipeline-must-have-receiver", expected: errPipelineMustHaveReceiver},
 		{name: "pipeline-exporter-not-exists", expected: errPipelineExporterNotExists},
 		{name: "pipeline-processor-not-exists", expected: errPipelineProcessorNotExists},
-		{name: "pipeline-must-have-processors", expected: errPipelineMustHaveProcessors},
-		{name: "metric-pipeline-cannot-have-processors", expected: errMetricPipelineCannotHaveProcessors},
 		{name: "unknown-extension-type", expected: errUnknownExtensionType},
 		{name: "unknown-receiver-type", expected: errUnknownReceiverType},
 		{name: "unknown-exporter-type", expected: errUnknownExporterType},
@@ -219,6 +350,10 @@ func TestDecodeConfig_Invalid(t *testing.T) {
 		{name: "duplicate-exporter", expected: errDuplicateExporterName},
 		{name: "duplicate-processor", expected: errDuplicateProcessorName},
 		{name: "duplicate-pipeline", expected: errDuplicatePipelineName},
+		{name: "unknown-connector-type", expected: errUnknownConnectorType},
+		{name: "duplicate-connector", expected: errDuplicateConnectorName},
+		{name: "connector-not-connected", expected: errConnectorNotConnected},
+		{name: "pipelines-cycle", expected: errPipelinesCycle},
 	}
 
 	factories, err := ExampleComponents()
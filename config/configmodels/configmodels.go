@@ -14,7 +14,8 @@
 
 // Package configmodels defines the data models for entities. This file defines the
 // models for V2 configuration format. The defined entities are:
-// Config (the top-level structure), Receivers, Exporters, Processors, Pipelines.
+// Config (the top-level structure), Receivers, Exporters, Processors, Connectors,
+// Pipelines.
 package configmodels
 
 /*
@@ -36,6 +37,7 @@ type Config struct {
 	Receivers  Receivers
 	Exporters  Exporters
 	Processors Processors
+	Connectors Connectors
 	Pipelines  Pipelines
 	Extensions Extensions
 	Service    Service
@@ -82,6 +84,21 @@ type Processor interface {
 // Processors is a map of names to Processors.
 type Processors map[string]Processor
 
+// Connector is the configuration of a connector. A connector is referenced as
+// an exporter by the pipelines whose data it consumes and as a receiver by the
+// pipelines it emits data into. Specific connectors must implement this
+// interface and will typically embed ConnectorSettings struct or a struct that
+// extends it.
+type Connector interface {
+	NamedEntity
+	IsEnabled() bool
+	Type() string
+	SetType(typeStr string)
+}
+
+// Connectors is a map of names to Connectors.
+type Connectors map[string]Connector
+
 // DataType is the data type that is supported for collection. We currently support
 // collecting metrics and traces, this can expand in the future (e.g. logs, events, etc).
 type DataType int
@@ -140,10 +157,32 @@ type Extension interface {
 // Extensions is a map of names to extensions.
 type Extensions map[string]Extension
 
+// ProcessorOrdering defines how the order of the processors of the pipelines
+// is checked against the capabilities of the processors.
+type ProcessorOrdering string
+
+const (
+	// ProcessorOrderingWarn logs a warning for the mis-ordered processors, it is
+	// the default.
+	ProcessorOrderingWarn ProcessorOrdering = "warn"
+
+	// ProcessorOrderingError fails the loading of the configuration if
+	// processors are mis-ordered.
+	ProcessorOrderingError ProcessorOrdering = "error"
+
+	// ProcessorOrderingAuto reorders the processors of the pipelines, logging
+	// the fixed order.
+	ProcessorOrderingAuto ProcessorOrdering = "auto"
+)
+
 // Service defines the configurable components of the service.
 type Service struct {
 	// Extensions is the ordered list of extensions configured for the service.
 	Extensions []string `mapstructure:"extensions"`
+
+	// ProcessorOrdering defines how the mis-ordered processors of the
+	// pipelines are handled, ProcessorOrderingWarn if empty.
+	ProcessorOrdering ProcessorOrdering `mapstructure:"processor-ordering"`
 }
 
 // Below are common setting structs for Receivers, Exporters and Processors.
@@ -261,6 +300,41 @@ func (proc *ProcessorSettings) IsEnabled() bool {
 
 var _ Processor = (*ProcessorSettings)(nil)
 
+// ConnectorSettings defines common settings for a connector configuration.
+// Specific connectors can embed this struct and extend it with more fields if needed.
+type ConnectorSettings struct {
+	TypeVal  string `mapstructure:"-"`
+	NameVal  string `mapstructure:"-"`
+	Disabled bool   `mapstructure:"disabled"`
+}
+
+// Name gets the connector name.
+func (cs *ConnectorSettings) Name() string {
+	return cs.NameVal
+}
+
+// SetName sets the connector name.
+func (cs *ConnectorSettings) SetName(name string) {
+	cs.NameVal = name
+}
+
+// Type sets the connector type.
+func (cs *ConnectorSettings) Type() string {
+	return cs.TypeVal
+}
+
+// SetType sets the connector type.
+func (cs *ConnectorSettings) SetType(typeStr string) {
+	cs.TypeVal = typeStr
+}
+
+// IsEnabled returns true if the entity is enabled.
+func (cs *ConnectorSettings) IsEnabled() bool {
+	return !cs.Disabled
+}
+
+var _ Connector = (*ConnectorSettings)(nil)
+
 // ExtensionSettings defines common settings for a service extension configuration.
 // Specific extensions can embed this struct and extend it with more fields if needed.
 type ExtensionSettings struct {
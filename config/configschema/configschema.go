@@ -0,0 +1,268 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configschema generates the JSON Schema of the configuration files
+// accepted by the service with a given set of factories, so that editors and
+// linters can validate them without running the service.
+package configschema
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// Version is the JSON Schema draft the generated schemas conform to.
+const Version = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema document, ready to be marshaled to JSON.
+type Schema map[string]interface{}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Generate returns the schema of the configuration files using the components
+// created by the given factories. The settings of each component are
+// described by reflecting over its default configuration, following the
+// mapstructure tags used to load it. The components with a custom unmarshaler
+// may accept settings that aren't in their configuration struct, so their
+// schemas allow additional properties.
+func Generate(factories config.Factories) Schema {
+	receivers := make(map[string]Schema, len(factories.Receivers))
+	for typeStr, f := range factories.Receivers {
+		receivers[typeStr] = componentSchema(f.CreateDefaultConfig(), f.CustomUnmarshaler() != nil)
+	}
+	processors := make(map[string]Schema, len(factories.Processors))
+	for typeStr, f := range factories.Processors {
+		cu, ok := f.(processor.CustomUnmarshalerFactory)
+		processors[typeStr] = componentSchema(f.CreateDefaultConfig(), ok && cu.CustomUnmarshaler() != nil)
+	}
+	exporters := make(map[string]Schema, len(factories.Exporters))
+	for typeStr, f := range factories.Exporters {
+		cu, ok := f.(exporter.CustomUnmarshalerFactory)
+		exporters[typeStr] = componentSchema(f.CreateDefaultConfig(), ok && cu.CustomUnmarshaler() != nil)
+	}
+	extensions := make(map[string]Schema, len(factories.Extensions))
+	for typeStr, f := range factories.Extensions {
+		cu, ok := f.(extension.CustomUnmarshalerFactory)
+		extensions[typeStr] = componentSchema(f.CreateDefaultConfig(), ok && cu.CustomUnmarshaler() != nil)
+	}
+	connectors := make(map[string]Schema, len(factories.Connectors))
+	for typeStr, f := range factories.Connectors {
+		connectors[typeStr] = componentSchema(f.CreateDefaultConfig(), false)
+	}
+
+	pipeline := typeSchema(reflect.TypeOf(configmodels.Pipeline{}), reflect.Value{}, false, nil)
+	pipelines := make(map[string]Schema)
+	for _, dataType := range []string{configmodels.TracesDataTypeStr, configmodels.MetricsDataTypeStr} {
+		pipelines[dataType] = pipeline
+	}
+
+	return Schema{
+		"$schema": Version,
+		"title":   "OpenTelemetry Service configuration",
+		"type":    "object",
+		"properties": Schema{
+			"receivers":  componentsSchema(receivers),
+			"processors": componentsSchema(processors),
+			"exporters":  componentsSchema(exporters),
+			"extensions": componentsSchema(extensions),
+			"connectors": componentsSchema(connectors),
+			"pipelines":  componentsSchema(pipelines),
+			"service":    typeSchema(reflect.TypeOf(configmodels.Service{}), reflect.Value{}, false, nil),
+		},
+	}
+}
+
+// componentsSchema returns the schema of a section of the configuration whose
+// keys are in the type[/name] format.
+func componentsSchema(components map[string]Schema) Schema {
+	types := make([]string, 0, len(components))
+	for typeStr := range components {
+		types = append(types, typeStr)
+	}
+	sort.Strings(types)
+
+	patterns := make(Schema, len(types))
+	for _, typeStr := range types {
+		patterns[fmt.Sprintf("^%s(/.+)?$", regexp.QuoteMeta(typeStr))] = components[typeStr]
+	}
+	return Schema{
+		"type":                 "object",
+		"patternProperties":    patterns,
+		"additionalProperties": false,
+	}
+}
+
+// componentSchema returns the schema of the settings of a component, with the
+// values of its default configuration as defaults. The settings may be
+// omitted, in which case the component uses its default configuration.
+func componentSchema(cfg interface{}, open bool) Schema {
+	v := reflect.ValueOf(cfg)
+	return Schema{
+		"anyOf": []Schema{
+			{"type": "null"},
+			typeSchema(v.Type(), v, open, nil),
+		},
+	}
+}
+
+// typeSchema returns the schema of the values of type t. If v is valid it is
+// the default value, whose fields are used as defaults of the properties. If
+// open is true the structs allow additional properties. The struct types in
+// visiting are being described, they are allowed in any of their fields to
+// stop the recursion.
+func typeSchema(t reflect.Type, v reflect.Value, open bool, visiting map[reflect.Type]bool) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			v = v.Elem()
+		}
+	}
+
+	if t == durationType {
+		// Durations are decoded from strings such as "5s", or from integers
+		// of nanoseconds.
+		s := Schema{"type": []string{"string", "integer"}}
+		if v.IsValid() && v.Int() != 0 {
+			s["default"] = time.Duration(v.Int()).String()
+		}
+		return s
+	}
+	if t.Kind() != reflect.String && reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return Schema{"type": "string"}
+	}
+
+	s := Schema{}
+	switch t.Kind() {
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+	case reflect.String:
+		s["type"] = "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s["type"] = "string"
+			return s
+		}
+		s["type"] = "array"
+		s["items"] = typeSchema(t.Elem(), reflect.Value{}, open, visiting)
+		return s
+	case reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = typeSchema(t.Elem(), reflect.Value{}, open, visiting)
+		return s
+	case reflect.Struct:
+		return structSchema(t, v, open, visiting)
+	default:
+		// Interfaces and anything else can't be described further.
+		return s
+	}
+
+	if v.IsValid() && !isZero(v) {
+		s["default"] = v.Interface()
+	}
+	return s
+}
+
+// structSchema returns the schema of the values of the struct type t, see
+// typeSchema.
+func structSchema(t reflect.Type, v reflect.Value, open bool, visiting map[reflect.Type]bool) Schema {
+	if visiting[t] {
+		return Schema{"type": "object"}
+	}
+	nested := make(map[reflect.Type]bool, len(visiting)+1)
+	for vt := range visiting {
+		nested[vt] = true
+	}
+	nested[t] = true
+
+	properties := Schema{}
+	addStructProperties(properties, t, v, open, nested)
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": open,
+	}
+}
+
+// addStructProperties adds the schemas of the fields of the struct type t to
+// properties, including the fields of the squashed structs.
+func addStructProperties(properties Schema, t reflect.Type, v reflect.Value, open bool, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported fields aren't decoded.
+			continue
+		}
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+
+		name, opts := parseTag(field.Tag.Get("mapstructure"))
+		if name == "-" {
+			continue
+		}
+		if opts == "squash" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if fv.IsValid() {
+					fv = fv.Elem()
+				}
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructProperties(properties, ft, fv, open, visiting)
+				continue
+			}
+		}
+		if name == "" {
+			// Viper keys are case insensitive, and lower case in the files.
+			name = strings.ToLower(field.Name)
+		}
+		properties[name] = typeSchema(field.Type, fv, open, visiting)
+	}
+}
+
+// parseTag splits a mapstructure tag into the name and the options.
+func parseTag(tag string) (name, opts string) {
+	items := strings.SplitN(tag, ",", 2)
+	name = items[0]
+	if len(items) == 2 {
+		opts = items[1]
+	}
+	return name, opts
+}
+
+// isZero reports if v is the zero value of its type.
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,111 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+func TestGenerate(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	require.NoError(t, err)
+
+	schema := Generate(factories)
+	assert.Equal(t, Version, schema["$schema"])
+
+	properties := schema["properties"].(Schema)
+	receivers := properties["receivers"].(Schema)["patternProperties"].(Schema)
+	require.Contains(t, receivers, "^examplereceiver(/.+)?$")
+	require.Contains(t, receivers, "^multireceiver(/.+)?$")
+
+	receiver := receivers["^examplereceiver(/.+)?$"].(Schema)["anyOf"].([]Schema)[1]
+	assert.Equal(t, false, receiver["additionalProperties"])
+	assert.Equal(t, Schema{
+		"disabled": Schema{"type": "boolean"},
+		"endpoint": Schema{"type": "string", "default": "localhost:1000"},
+		"extra":    Schema{"type": "string", "default": "some string"},
+	}, receiver["properties"])
+
+	pipelines := properties["pipelines"].(Schema)["patternProperties"].(Schema)
+	require.Contains(t, pipelines, "^traces(/.+)?$")
+	require.Contains(t, pipelines, "^metrics(/.+)?$")
+	pipeline := pipelines["^traces(/.+)?$"].(Schema)
+	assert.Equal(t, Schema{
+		"receivers":  Schema{"type": "array", "items": Schema{"type": "string"}},
+		"processors": Schema{"type": "array", "items": Schema{"type": "string"}},
+		"exporters":  Schema{"type": "array", "items": Schema{"type": "string"}},
+	}, pipeline["properties"])
+
+	assert.Contains(t, properties["service"].(Schema)["properties"], "extensions")
+
+	_, err = json.Marshal(schema)
+	assert.NoError(t, err)
+}
+
+type testTree struct {
+	Name     string       `mapstructure:"name"`
+	Children []*testTree  `mapstructure:"children"`
+	Settings testSettings `mapstructure:"settings"`
+}
+
+type testSettings struct {
+	configmodels.ExporterSettings `mapstructure:",squash"`
+	Timeout                       time.Duration     `mapstructure:"timeout"`
+	Ratio                         float64           `mapstructure:"ratio"`
+	Headers                       map[string]string `mapstructure:"headers"`
+	Untagged                      int
+	Ignored                       string `mapstructure:"-"`
+	unexported                    string
+}
+
+func TestTypeSchema(t *testing.T) {
+	v := reflect.ValueOf(&testTree{
+		Settings: testSettings{Timeout: 5 * time.Second, Ratio: 0.5},
+	})
+	assert.Equal(t, Schema{
+		"type": "object",
+		"properties": Schema{
+			"name": Schema{"type": "string"},
+			"children": Schema{
+				"type":  "array",
+				"items": Schema{"type": "object"},
+			},
+			"settings": Schema{
+				"type": "object",
+				"properties": Schema{
+					"disabled": Schema{"type": "boolean"},
+					"timeout":  Schema{"type": []string{"string", "integer"}, "default": "5s"},
+					"ratio":    Schema{"type": "number", "default": 0.5},
+					"headers": Schema{
+						"type":                 "object",
+						"additionalProperties": Schema{"type": "string"},
+					},
+					"untagged": Schema{"type": "integer"},
+				},
+				"additionalProperties": true,
+			},
+		},
+		"additionalProperties": true,
+	}, typeSchema(v.Type(), v, true, nil))
+}
@@ -0,0 +1,240 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configsecret resolves the references to secrets in the settings of
+// the components, so that the configuration doesn't embed them. A reference
+// has the form ${store:reference}, e.g. ${file:/etc/otelsvc/token}, and is
+// replaced by the value of the secret read from the store when the
+// configuration is loaded.
+package configsecret
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+)
+
+// Resolver reads the secrets of a store.
+type Resolver interface {
+	// Resolve returns the value of the secret identified by the reference,
+	// the part of ${store:reference} after the store name.
+	Resolve(reference string) (string, error)
+}
+
+// ResolverFunc is a function implementing Resolver.
+type ResolverFunc func(reference string) (string, error)
+
+// Resolve calls f(reference).
+func (f ResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// referencePattern matches the references to secrets, the first group is the
+// store and the second one the reference within the store.
+var referencePattern = regexp.MustCompile(`\$\{([a-zA-Z][a-zA-Z0-9_-]*):([^}]*)\}`)
+
+var (
+	mu        sync.RWMutex
+	resolvers = map[string]Resolver{
+		"file":  ResolverFunc(resolveFile),
+		"k8s":   &kubernetesResolver{apiServer: k8sapi.InCluster},
+		"vault": &vaultResolver{},
+	}
+	// secrets are the values resolved so far, see IsSecret.
+	secrets = map[string]struct{}{}
+)
+
+// RegisterResolver registers the resolver of the references to the given store.
+// It fails if the store already has a resolver.
+func RegisterResolver(store string, r Resolver) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := resolvers[store]; ok {
+		return fmt.Errorf("duplicate secret store %q", store)
+	}
+	resolvers[store] = r
+	return nil
+}
+
+// IsSecret reports if s is a setting value built from the secrets resolved by
+// Resolve, so that it is never shown.
+func IsSecret(s string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := secrets[s]
+	return ok
+}
+
+// Resolve replaces the references to secrets in the settings of the receivers,
+// processors, exporters, extensions and connectors of cfg by the values of the secrets.
+// The secrets are read every time it is called, e.g. when the configuration is
+// reloaded. The errors only mention the references, never the values.
+func Resolve(cfg *configmodels.Config) error {
+	r := &resolution{values: make(map[string]string)}
+	for name, c := range cfg.Receivers {
+		if err := r.walk(reflect.ValueOf(c)); err != nil {
+			return fmt.Errorf("cannot resolve the secrets of receiver %q: %v", name, err)
+		}
+	}
+	for name, c := range cfg.Processors {
+		if err := r.walk(reflect.ValueOf(c)); err != nil {
+			return fmt.Errorf("cannot resolve the secrets of processor %q: %v", name, err)
+		}
+	}
+	for name, c := range cfg.Exporters {
+		if err := r.walk(reflect.ValueOf(c)); err != nil {
+			return fmt.Errorf("cannot resolve the secrets of exporter %q: %v", name, err)
+		}
+	}
+	for name, c := range cfg.Extensions {
+		if err := r.walk(reflect.ValueOf(c)); err != nil {
+			return fmt.Errorf("cannot resolve the secrets of extension %q: %v", name, err)
+		}
+	}
+	for name, c := range cfg.Connectors {
+		if err := r.walk(reflect.ValueOf(c)); err != nil {
+			return fmt.Errorf("cannot resolve the secrets of connector %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// resolution resolves the references of a configuration, reading each secret
+// once.
+type resolution struct {
+	values map[string]string
+}
+
+// walk resolves the references in the settable strings of v.
+func (r *resolution) walk(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return r.walk(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := v.Elem()
+		if elem.Kind() != reflect.String {
+			return r.walk(elem)
+		}
+		if !v.CanSet() {
+			return nil
+		}
+		// The string held by an interface is not addressable.
+		s := reflect.New(elem.Type()).Elem()
+		s.Set(elem)
+		if err := r.walk(s); err != nil {
+			return err
+		}
+		v.Set(s)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if field := t.Field(i); field.PkgPath != "" && !field.Anonymous {
+				// Unexported field, the exported fields of the embedded
+				// structs are settable.
+				continue
+			}
+			if err := r.walk(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// The map values are not addressable, resolve a copy.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := r.walk(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := r.walk(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		s, err := r.resolveString(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	}
+	return nil
+}
+
+// resolveString replaces the references in s by the values of the secrets.
+func (r *resolution) resolveString(s string) (string, error) {
+	if !referencePattern.MatchString(s) {
+		return s, nil
+	}
+	var err error
+	resolved := referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		var value string
+		value, err = r.resolveReference(ref)
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+
+	mu.Lock()
+	secrets[resolved] = struct{}{}
+	mu.Unlock()
+	return resolved, nil
+}
+
+func (r *resolution) resolveReference(ref string) (string, error) {
+	if value, ok := r.values[ref]; ok {
+		return value, nil
+	}
+	match := referencePattern.FindStringSubmatch(ref)
+	store, reference := match[1], match[2]
+
+	mu.RLock()
+	resolver := resolvers[store]
+	mu.RUnlock()
+	if resolver == nil {
+		return "", fmt.Errorf("unknown secret store %q in %q", store, ref)
+	}
+	value, err := resolver.Resolve(reference)
+	if err != nil {
+		return "", fmt.Errorf("cannot read secret %q: %v", ref, err)
+	}
+
+	r.values[ref] = value
+	if value != "" {
+		mu.Lock()
+		secrets[value] = struct{}{}
+		mu.Unlock()
+	}
+	return value, nil
+}
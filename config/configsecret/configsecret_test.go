@@ -0,0 +1,99 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsecret
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+type testSettings struct {
+	Endpoint string
+	Headers  map[string]string
+	Tokens   []string
+	Extra    map[string]interface{}
+	Nested   *testSettings
+	ignored  string
+}
+
+type testConfig struct {
+	configmodels.ExporterSettings
+	testSettings
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configsecret")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	calls := 0
+	require.NoError(t, RegisterResolver("test", ResolverFunc(func(reference string) (string, error) {
+		calls++
+		return "value-of-" + reference, nil
+	})))
+	assert.Error(t, RegisterResolver("test", ResolverFunc(resolveFile)))
+
+	exp := &testConfig{testSettings: testSettings{
+		Endpoint: "backend:55678",
+		Headers:  map[string]string{"Authorization": "Bearer ${file:" + tokenFile + "}"},
+		Tokens:   []string{"${test:a}", "${test:b}"},
+		Extra:    map[string]interface{}{"key": "${test:a}", "count": 3},
+		Nested:   &testSettings{Endpoint: "${test:a}"},
+		ignored:  "${test:c}",
+	}}
+	cfg := &configmodels.Config{
+		Exporters: configmodels.Exporters{"test": exp},
+	}
+	require.NoError(t, Resolve(cfg))
+
+	assert.Equal(t, "backend:55678", exp.Endpoint)
+	assert.Equal(t, "Bearer file-token", exp.Headers["Authorization"])
+	assert.Equal(t, []string{"value-of-a", "value-of-b"}, exp.Tokens)
+	assert.Equal(t, "value-of-a", exp.Extra["key"])
+	assert.Equal(t, 3, exp.Extra["count"])
+	assert.Equal(t, "value-of-a", exp.Nested.Endpoint)
+	assert.Equal(t, "${test:c}", exp.ignored)
+	assert.Equal(t, 2, calls, "each secret must be read once")
+
+	assert.True(t, IsSecret("file-token"))
+	assert.True(t, IsSecret("Bearer file-token"))
+	assert.True(t, IsSecret("value-of-a"))
+	assert.False(t, IsSecret("backend:55678"))
+}
+
+func TestResolveErrors(t *testing.T) {
+	require.NoError(t, RegisterResolver("failing", ResolverFunc(func(reference string) (string, error) {
+		return "", errors.New("access denied")
+	})))
+
+	for _, value := range []string{"${unknown:a}", "${failing:a}", "${file:/nonexistent/token}"} {
+		cfg := &configmodels.Config{
+			Receivers: configmodels.Receivers{"test": &testConfig{testSettings: testSettings{Endpoint: value}}},
+		}
+		err := Resolve(cfg)
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), value)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+)
+
+// resolveFile reads the secret stored in the file at the given path, e.g. a
+// mounted Kubernetes or Docker secret. The trailing newlines are removed.
+func resolveFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// kubernetesResolver reads the keys of Kubernetes secrets, referenced as
+// namespace/name/key, or name/key for the secrets in the namespace of the
+// service account.
+type kubernetesResolver struct {
+	apiServer func() (*k8sapi.APIServer, error)
+}
+
+func (kr *kubernetesResolver) Resolve(reference string) (string, error) {
+	parts := strings.Split(reference, "/")
+	var namespace, name, key string
+	switch len(parts) {
+	case 2:
+		b, err := ioutil.ReadFile(filepath.Join(k8sapi.ServiceAccountDir, "namespace"))
+		if err != nil {
+			return "", fmt.Errorf("cannot read the namespace of the service account: %v", err)
+		}
+		namespace, name, key = strings.TrimSpace(string(b)), parts[0], parts[1]
+	case 3:
+		namespace, name, key = parts[0], parts[1], parts[2]
+	default:
+		return "", errors.New("the reference must be namespace/name/key or name/key")
+	}
+
+	apiServer, err := kr.apiServer()
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s",
+		apiServer.URL, url.PathEscape(namespace), url.PathEscape(name)), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if apiServer.Token != nil {
+		token, err := apiServer.Token()
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := getJSON(apiServer.Client, req, &secret); err != nil {
+		return "", err
+	}
+	encoded, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %q", namespace, name, key)
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid key %q of secret %s/%s: %v", key, namespace, name, err)
+	}
+	return string(value), nil
+}
+
+// vaultResolver reads the keys of Vault secrets, referenced as path#key, e.g.
+// secret/data/otelsvc#token. The secret engines returning the keys in "data",
+// such as KV version 1, and in "data.data", such as KV version 2, are
+// supported. The address of Vault and the token are read from the VAULT_ADDR
+// and VAULT_TOKEN environment variables, as with the Vault CLI.
+type vaultResolver struct {
+	client *http.Client
+}
+
+var defaultVaultClient = &http.Client{Timeout: 30 * time.Second}
+
+func (vr *vaultResolver) Resolve(reference string) (string, error) {
+	hash := strings.LastIndex(reference, "#")
+	if hash <= 0 || hash == len(reference)-1 {
+		return "", errors.New("the reference must be path#key")
+	}
+	path, key := strings.Trim(reference[:hash], "/"), reference[hash+1:]
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("VAULT_ADDR must be defined")
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	client := vr.client
+	if client == nil {
+		client = defaultVaultClient
+	}
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := getJSON(client, req, &secret); err != nil {
+		return "", err
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q", path, key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q of secret %q is not a string", key, path)
+	}
+	return s, nil
+}
+
+// getJSON sends the request and decodes the JSON response into v. The errors
+// don't include the body of the response, which could contain secrets.
+func getJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s failed with status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
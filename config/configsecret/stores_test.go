@@ -0,0 +1,122 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsecret
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+)
+
+func TestKubernetesResolver(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/api/v1/namespaces/monitoring/secrets/otelsvc" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{"token": "czNjcjN0", "invalid": "!"},
+		})
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "serviceaccount")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(orig string) { k8sapi.ServiceAccountDir = orig }(k8sapi.ServiceAccountDir)
+	k8sapi.ServiceAccountDir = dir
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "namespace"), []byte("monitoring\n"), 0600))
+
+	kr := &kubernetesResolver{apiServer: func() (*k8sapi.APIServer, error) {
+		return &k8sapi.APIServer{
+			URL:    srv.URL,
+			Client: srv.Client(),
+			Token:  func() (string, error) { return "sa-token", nil },
+		}, nil
+	}}
+
+	for _, reference := range []string{"monitoring/otelsvc/token", "otelsvc/token"} {
+		value, err := kr.Resolve(reference)
+		require.NoError(t, err, reference)
+		assert.Equal(t, "s3cr3t", value, reference)
+	}
+	assert.Equal(t, "Bearer sa-token", auth)
+
+	for _, reference := range []string{"token", "default/otelsvc/token", "otelsvc/missing", "otelsvc/invalid"} {
+		_, err := kr.Resolve(reference)
+		assert.Error(t, err, reference)
+	}
+}
+
+func TestVaultResolver(t *testing.T) {
+	var token string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Vault-Token")
+		switch r.URL.Path {
+		case "/v1/secret/data/otelsvc":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"token": "kv2-token"},
+					"metadata": map[string]interface{}{"version": 1},
+				},
+			})
+		case "/v1/kv/otelsvc":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"token": "kv1-token", "port": 8200},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	for _, env := range []string{"VAULT_ADDR", "VAULT_TOKEN"} {
+		if orig, ok := os.LookupEnv(env); ok {
+			defer os.Setenv(env, orig)
+		} else {
+			defer os.Unsetenv(env)
+		}
+	}
+	os.Unsetenv("VAULT_ADDR")
+	vr := &vaultResolver{client: srv.Client()}
+	_, err := vr.Resolve("secret/data/otelsvc#token")
+	assert.Error(t, err, "must fail without VAULT_ADDR")
+
+	os.Setenv("VAULT_ADDR", srv.URL+"/")
+	os.Setenv("VAULT_TOKEN", "vault-token")
+	value, err := vr.Resolve("secret/data/otelsvc#token")
+	require.NoError(t, err)
+	assert.Equal(t, "kv2-token", value)
+	assert.Equal(t, "vault-token", token)
+	value, err = vr.Resolve("kv/otelsvc#token")
+	require.NoError(t, err)
+	assert.Equal(t, "kv1-token", value)
+
+	for _, reference := range []string{"kv/otelsvc", "kv/otelsvc#", "kv/otelsvc#missing", "kv/otelsvc#port", "kv/missing#token"} {
+		_, err := vr.Resolve(reference)
+		assert.Error(t, err, reference)
+	}
+}
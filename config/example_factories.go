@@ -22,6 +22,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/connector"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
@@ -379,6 +380,98 @@ func (f *ExampleExtensionFactory) CreateExtension(
 
 var _ (extension.Factory) = (*ExampleExtensionFactory)(nil)
 
+// ExampleConnector is for testing purposes. We are defining an example config and factory
+// for "exampleconnector" connector type.
+type ExampleConnector struct {
+	configmodels.ConnectorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	ExtraSetting                   string                   `mapstructure:"extra"`
+}
+
+// ExampleConnectorFactory is factory for ExampleConnector.
+type ExampleConnectorFactory struct {
+}
+
+// Type gets the type of the Connector config created by this factory.
+func (f *ExampleConnectorFactory) Type() string {
+	return "exampleconnector"
+}
+
+// CreateDefaultConfig creates the default configuration for the Connector.
+func (f *ExampleConnectorFactory) CreateDefaultConfig() configmodels.Connector {
+	return &ExampleConnector{
+		ConnectorSettings: configmodels.ConnectorSettings{},
+		ExtraSetting:      "some connector string",
+	}
+}
+
+// CreateTracesToTraces creates a connector forwarding the traces to nextConsumer.
+func (f *ExampleConnectorFactory) CreateTracesToTraces(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Connector,
+) (connector.TraceConnector, error) {
+	return &ExampleConnectorConsumer{traceConsumer: nextConsumer}, nil
+}
+
+// CreateTracesToMetrics creates a connector emitting metrics from traces.
+func (f *ExampleConnectorFactory) CreateTracesToMetrics(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Connector,
+) (connector.TraceConnector, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// CreateMetricsToTraces creates a connector emitting traces from metrics.
+func (f *ExampleConnectorFactory) CreateMetricsToTraces(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Connector,
+) (connector.MetricsConnector, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// CreateMetricsToMetrics creates a connector forwarding the metrics to nextConsumer.
+func (f *ExampleConnectorFactory) CreateMetricsToMetrics(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Connector,
+) (connector.MetricsConnector, error) {
+	return &ExampleConnectorConsumer{metricsConsumer: nextConsumer}, nil
+}
+
+var _ (connector.Factory) = (*ExampleConnectorFactory)(nil)
+
+// ExampleConnectorConsumer forwards the data it receives to the next consumer.
+type ExampleConnectorConsumer struct {
+	traceConsumer   consumer.TraceConsumer
+	metricsConsumer consumer.MetricsConsumer
+	Started         bool
+	Stopped         bool
+}
+
+// ConsumeTraceData forwards the traces.
+func (ec *ExampleConnectorConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	return ec.traceConsumer.ConsumeTraceData(ctx, td)
+}
+
+// ConsumeMetricsData forwards the metrics.
+func (ec *ExampleConnectorConsumer) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	return ec.metricsConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// Start tells the connector to start.
+func (ec *ExampleConnectorConsumer) Start(host receiver.Host) error {
+	ec.Started = true
+	return nil
+}
+
+// Shutdown is invoked during shutdown.
+func (ec *ExampleConnectorConsumer) Shutdown() error {
+	ec.Stopped = true
+	return nil
+}
+
 // ExampleComponents registers example factories. This is only used by tests.
 func ExampleComponents() (
 	factories Factories,
@@ -402,6 +495,11 @@ func ExampleComponents() (
 	}
 
 	factories.Processors, err = processor.Build(&ExampleProcessorFactory{})
+	if err != nil {
+		return
+	}
+
+	factories.Connectors, err = connector.Build(&ExampleConnectorFactory{})
 
 	return
 }
@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// validateProcessorsOrder checks the order of the processors of the pipelines
+// against the capabilities of their factories, see processor.Capabilities. The
+// mis-ordered processors are handled according to the processor-ordering of
+// the service.
+func validateProcessorsOrder(
+	cfg *configmodels.Config,
+	factories map[string]processor.Factory,
+	logger *zap.Logger,
+) error {
+	ordering := cfg.Service.ProcessorOrdering
+	switch ordering {
+	case "":
+		ordering = configmodels.ProcessorOrderingWarn
+	case configmodels.ProcessorOrderingWarn, configmodels.ProcessorOrderingError, configmodels.ProcessorOrderingAuto:
+	default:
+		return &configError{
+			code: errInvalidProcessorOrdering,
+			msg:  fmt.Sprintf("invalid processor-ordering %q (must be warn, error or auto)", ordering),
+		}
+	}
+
+	capabilities := func(ref string) processor.Capabilities {
+		if f, ok := factories[cfg.Processors[ref].Type()].(processor.CapabilitiesFactory); ok {
+			return f.Capabilities()
+		}
+		return processor.Capabilities{}
+	}
+
+	// Sort the pipelines so that the same error is returned for the same config.
+	names := make([]string, 0, len(cfg.Pipelines))
+	for name := range cfg.Pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pipeline := cfg.Pipelines[name]
+		violations := processorsOrderViolations(pipeline.Processors, capabilities)
+		if len(violations) == 0 {
+			continue
+		}
+
+		switch ordering {
+		case configmodels.ProcessorOrderingError:
+			return &configError{
+				code: errPipelineProcessorsOrder,
+				msg:  fmt.Sprintf("pipeline %q has mis-ordered processors: %s", name, strings.Join(violations, "; ")),
+			}
+		case configmodels.ProcessorOrderingAuto:
+			pipeline.Processors = orderProcessors(pipeline.Processors, capabilities)
+			logger.Info("pipeline has mis-ordered processors. Reordering the processors.",
+				zap.String("pipeline", name),
+				zap.Strings("violations", violations),
+				zap.Strings("processors", pipeline.Processors))
+		default:
+			logger.Warn("pipeline has mis-ordered processors. Set the processor-ordering of the service to auto to reorder them.",
+				zap.String("pipeline", name),
+				zap.Strings("violations", violations))
+		}
+	}
+	return nil
+}
+
+// processorsOrderViolations returns a description of each pair of
+// mis-ordered processors of refs:
+// - a limiter must precede the processors that are not limiters.
+// - a batcher must precede the queues.
+func processorsOrderViolations(refs []string, capabilities func(string) processor.Capabilities) []string {
+	var violations []string
+	for i, ref := range refs {
+		c := capabilities(ref)
+		for _, prev := range refs[:i] {
+			p := capabilities(prev)
+			if c.Limiter && !p.Limiter {
+				violations = append(violations,
+					fmt.Sprintf("limiter processor %q must precede processor %q", ref, prev))
+			}
+			if c.Batcher && !c.Queue && p.Queue {
+				violations = append(violations,
+					fmt.Sprintf("batcher processor %q must precede queue processor %q", ref, prev))
+			}
+		}
+	}
+	return violations
+}
+
+// orderProcessors returns refs reordered so that it has no violations: the
+// limiters are moved first and the batchers following a queue are moved
+// before the first queue. The other processors keep their relative order.
+func orderProcessors(refs []string, capabilities func(string) processor.Capabilities) []string {
+	ordered := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if capabilities(ref).Limiter {
+			ordered = append(ordered, ref)
+		}
+	}
+
+	firstQueue := -1
+	for _, ref := range refs {
+		c := capabilities(ref)
+		switch {
+		case c.Limiter:
+			continue
+		case c.Batcher && !c.Queue && firstQueue >= 0:
+			ordered = append(ordered, "")
+			copy(ordered[firstQueue+1:], ordered[firstQueue:])
+			ordered[firstQueue] = ref
+			firstQueue++
+			continue
+		case c.Queue && firstQueue < 0:
+			firstQueue = len(ordered)
+		}
+		ordered = append(ordered, ref)
+	}
+	return ordered
+}
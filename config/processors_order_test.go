@@ -0,0 +1,142 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type capabilitiesProcessorFactory struct {
+	ExampleProcessorFactory
+	typeStr      string
+	capabilities processor.Capabilities
+}
+
+func (f *capabilitiesProcessorFactory) Type() string {
+	return f.typeStr
+}
+
+func (f *capabilitiesProcessorFactory) CreateDefaultConfig() configmodels.Processor {
+	return &ExampleProcessor{}
+}
+
+func (f *capabilitiesProcessorFactory) Capabilities() processor.Capabilities {
+	return f.capabilities
+}
+
+func loadProcessorsOrderConfig(t *testing.T, ordering string, logger *zap.Logger) (*configmodels.Config, error) {
+	factories, err := ExampleComponents()
+	require.NoError(t, err)
+	for _, f := range []*capabilitiesProcessorFactory{
+		{typeStr: "limiter", capabilities: processor.Capabilities{Limiter: true}},
+		{typeStr: "batcher", capabilities: processor.Capabilities{Batcher: true}},
+		{typeStr: "queue", capabilities: processor.Capabilities{Queue: true}},
+	} {
+		factories.Processors[f.typeStr] = f
+	}
+
+	file, err := os.Open(path.Join(".", "testdata", "processors-order.yaml"))
+	require.NoError(t, err)
+	defer file.Close()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(file))
+	if ordering != "" {
+		v.Set("service.processor-ordering", ordering)
+	}
+	return Load(v, factories, logger)
+}
+
+func TestProcessorsOrder_Warn(t *testing.T) {
+	for _, ordering := range []string{"", "warn"} {
+		core, logs := observer.New(zapcore.WarnLevel)
+		cfg, err := loadProcessorsOrderConfig(t, ordering, zap.New(core))
+		require.NoError(t, err)
+
+		// The processors are kept in their configured order.
+		assert.Equal(t, []string{"exampleprocessor", "queue", "batcher", "limiter"}, cfg.Pipelines["traces"].Processors)
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, "traces", logs.All()[0].ContextMap()["pipeline"])
+	}
+}
+
+func TestProcessorsOrder_Error(t *testing.T) {
+	_, err := loadProcessorsOrderConfig(t, "error", zap.NewNop())
+	require.Error(t, err)
+	assert.Equal(t, errPipelineProcessorsOrder, err.(*configError).code)
+	assert.Equal(t, `pipeline "traces" has mis-ordered processors: `+
+		`batcher processor "batcher" must precede queue processor "queue"; `+
+		`limiter processor "limiter" must precede processor "exampleprocessor"; `+
+		`limiter processor "limiter" must precede processor "queue"; `+
+		`limiter processor "limiter" must precede processor "batcher"`, err.Error())
+}
+
+func TestProcessorsOrder_Auto(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg, err := loadProcessorsOrderConfig(t, "auto", zap.New(core))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"limiter", "exampleprocessor", "batcher", "queue"}, cfg.Pipelines["traces"].Processors)
+	assert.Equal(t, []string{"limiter", "exampleprocessor", "batcher", "queue"}, cfg.Pipelines["traces/ordered"].Processors)
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestProcessorsOrder_InvalidOrdering(t *testing.T) {
+	_, err := loadProcessorsOrderConfig(t, "sort", zap.NewNop())
+	require.Error(t, err)
+	assert.Equal(t, errInvalidProcessorOrdering, err.(*configError).code)
+}
+
+func TestOrderProcessors(t *testing.T) {
+	capabilities := func(ref string) processor.Capabilities {
+		switch ref[0] {
+		case 'l':
+			return processor.Capabilities{Limiter: true}
+		case 'b':
+			return processor.Capabilities{Batcher: true}
+		case 'q':
+			return processor.Capabilities{Queue: true}
+		}
+		return processor.Capabilities{}
+	}
+
+	tests := []struct {
+		refs []string
+		want []string
+	}{
+		{nil, []string{}},
+		{[]string{"a", "l1", "b1", "q1"}, []string{"l1", "a", "b1", "q1"}},
+		{[]string{"q1", "a", "b1", "q2", "b2"}, []string{"b1", "b2", "q1", "a", "q2"}},
+		{[]string{"l1", "a", "l2", "q1", "s"}, []string{"l1", "l2", "a", "q1", "s"}},
+	}
+	for _, tt := range tests {
+		got := orderProcessors(tt.refs, capabilities)
+		assert.Equal(t, tt.want, got, "refs: %v", tt.refs)
+		assert.Empty(t, processorsOrderViolations(got, capabilities))
+	}
+	assert.Len(t, processorsOrderViolations([]string{"q1", "b1", "a", "l1"}, capabilities), 4)
+}
@@ -0,0 +1,48 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connector contains the interfaces of the connectors. A connector
+// links pipelines in-process: it consumes the data exported by some pipelines
+// and emits data, possibly of another data type, into other pipelines.
+package connector
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+// Connector has the life-cycle functions of the connectors.
+type Connector interface {
+	// Start is invoked after the pipelines are built and before the
+	// receivers are started. The connectors emitting data on their own, e.g.
+	// periodically, start doing it.
+	Start(host receiver.Host) error
+
+	// Shutdown is invoked during service shutdown, after the receivers are
+	// stopped and before the exporters are shut down, so that the connectors
+	// can flush their data into the pipelines they emit into.
+	Shutdown() error
+}
+
+// TraceConnector consumes the traces exported by pipelines.
+type TraceConnector interface {
+	consumer.TraceConsumer
+	Connector
+}
+
+// MetricsConnector consumes the metrics exported by pipelines.
+type MetricsConnector interface {
+	consumer.MetricsConsumer
+	Connector
+}
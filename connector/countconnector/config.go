@@ -0,0 +1,33 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countconnector
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/spancount"
+)
+
+// Config defines configuration for the count connector.
+type Config struct {
+	configmodels.ConnectorSettings `mapstructure:",squash"`
+
+	// Interval is the period at which the counts are emitted.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Metrics are the metrics counting the spans exported to this connector.
+	Metrics []spancount.MetricDefinition `mapstructure:"metrics"`
+}
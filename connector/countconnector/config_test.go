@@ -0,0 +1,71 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countconnector
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/spancount"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Connectors[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, len(cfg.Connectors), 2)
+
+	c0 := cfg.Connectors["count"]
+	assert.Equal(t, c0, factory.CreateDefaultConfig())
+
+	c1 := cfg.Connectors["count/custom"].(*Config)
+	assert.Equal(t, c1,
+		&Config{
+			ConnectorSettings: configmodels.ConnectorSettings{
+				TypeVal: typeStr,
+				NameVal: "count/custom",
+			},
+			Interval: 30 * time.Second,
+			Metrics: []spancount.MetricDefinition{
+				{
+					Name:        "error_spans",
+					Description: "Number of spans with an error status.",
+					ErrorsOnly:  true,
+				},
+				{
+					Name:      "http_requests",
+					Services:  []string{"frontend"},
+					SpanNames: []string{"GET /", "POST /cart"},
+					Attributes: []spancount.AttributeCondition{
+						{Key: "http.method", Values: []string{"GET", "POST"}},
+					},
+					LabelAttributes: []string{"http.method"},
+				},
+			},
+		})
+}
@@ -0,0 +1,134 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package countconnector counts the spans exported by trace pipelines with
+// metrics matching configurable conditions, and emits the counts into metrics
+// pipelines.
+package countconnector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/connector"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/spancount"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+type countConnector struct {
+	logger       *zap.Logger
+	name         string
+	interval     time.Duration
+	counter      *spancount.Counter
+	nextConsumer consumer.MetricsConsumer
+
+	mu       sync.Mutex
+	started  bool
+	stopOnce sync.Once
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+var _ connector.TraceConnector = (*countConnector)(nil)
+
+func newCountConnector(
+	logger *zap.Logger,
+	cfg Config,
+	nextConsumer consumer.MetricsConsumer,
+) (*countConnector, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	counter, err := spancount.NewCounter(cfg.Metrics, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &countConnector{
+		logger:       logger,
+		name:         cfg.Name(),
+		interval:     cfg.Interval,
+		counter:      counter,
+		nextConsumer: nextConsumer,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}, nil
+}
+
+// ConsumeTraceData counts the spans.
+func (cc *countConnector) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	cc.counter.Count(td.Node, td.Spans)
+	return nil
+}
+
+// Start starts emitting the counts periodically.
+func (cc *countConnector) Start(host receiver.Host) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.started {
+		return oterr.ErrAlreadyStarted
+	}
+	cc.started = true
+	go cc.emitLoop(host.Context())
+	return nil
+}
+
+// Shutdown stops the periodic emission and emits the counts a last time.
+func (cc *countConnector) Shutdown() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	cc.stopOnce.Do(func() {
+		close(cc.done)
+		if cc.started {
+			<-cc.stopped
+		} else {
+			cc.emit(context.Background())
+		}
+		err = nil
+	})
+	return err
+}
+
+func (cc *countConnector) emitLoop(ctx context.Context) {
+	defer close(cc.stopped)
+	ticker := time.NewTicker(cc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cc.emit(ctx)
+		case <-cc.done:
+			cc.emit(ctx)
+			return
+		}
+	}
+}
+
+func (cc *countConnector) emit(ctx context.Context) {
+	metrics := cc.counter.Metrics(time.Now())
+	if len(metrics) == 0 {
+		return
+	}
+	if err := cc.nextConsumer.ConsumeMetricsData(ctx, consumerdata.MetricsData{Metrics: metrics}); err != nil {
+		cc.logger.Warn("Failed to emit the span counts", zap.String("connector", cc.name), zap.Error(err))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countconnector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/spancount"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestCountConnector(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	// Long enough for the counts to be emitted only on shutdown.
+	cfg.Interval = time.Hour
+	cfg.Metrics = []spancount.MetricDefinition{{Name: "error_spans", ErrorsOnly: true}}
+	cc, err := newCountConnector(zap.NewNop(), *cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, cc.Start(receivertest.NewMockHost()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, cc.Start(receivertest.NewMockHost()))
+
+	err = cc.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+		Spans: []*tracepb.Span{
+			{Status: &tracepb.Status{Code: 2}},
+			{},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cc.Shutdown())
+	assert.Equal(t, oterr.ErrAlreadyStopped, cc.Shutdown())
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 1)
+	metric := got[0].Metrics[0]
+	assert.Equal(t, "error_spans", metric.MetricDescriptor.Name)
+	require.Len(t, metric.Timeseries, 1)
+	assert.Equal(t, "frontend", metric.Timeseries[0].LabelValues[0].Value)
+	assert.Equal(t, int64(1), metric.Timeseries[0].Points[0].GetInt64Value())
+}
+
+func TestCountConnector_Interval(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Interval = 10 * time.Millisecond
+	cfg.Metrics = []spancount.MetricDefinition{{Name: "spans"}}
+	cc, err := newCountConnector(zap.NewNop(), *cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, cc.Start(receivertest.NewMockHost()))
+	defer cc.Shutdown()
+
+	// Nothing is emitted until a span is counted.
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, sink.AllMetrics())
+
+	require.NoError(t, cc.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  &commonpb.Node{},
+		Spans: []*tracepb.Span{{}},
+	}))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.AllMetrics()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NotEmpty(t, sink.AllMetrics())
+}
+
+func TestCountConnector_ShutdownWithoutStart(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Metrics = []spancount.MetricDefinition{{Name: "spans"}}
+	cc, err := newCountConnector(zap.NewNop(), *cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, cc.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: []*tracepb.Span{{}}}))
+
+	// The counts are still flushed.
+	require.NoError(t, cc.Shutdown())
+	assert.Len(t, sink.AllMetrics(), 1)
+}
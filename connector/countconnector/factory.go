@@ -0,0 +1,98 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countconnector
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/connector"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+)
+
+// This file implements factory for the count connector.
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "count"
+
+	defaultInterval = 10 * time.Second
+)
+
+// Factory is the factory for the count connector.
+type Factory struct {
+}
+
+var _ connector.Factory = (*Factory)(nil)
+
+// Type gets the type of the Connector config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the count connector.
+func (f *Factory) CreateDefaultConfig() configmodels.Connector {
+	return &Config{
+		ConnectorSettings: configmodels.ConnectorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		Interval: defaultInterval,
+	}
+}
+
+// CreateTracesToTraces is not supported, the count connector only emits metrics.
+func (f *Factory) CreateTracesToTraces(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Connector,
+) (connector.TraceConnector, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// CreateTracesToMetrics creates a connector counting the spans it consumes.
+func (f *Factory) CreateTracesToMetrics(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Connector,
+) (connector.TraceConnector, error) {
+	cCfg := cfg.(*Config)
+	if cCfg.Interval <= 0 {
+		return nil, fmt.Errorf("%q config requires a positive value for \"interval\"", cCfg.Name())
+	}
+	return newCountConnector(logger, *cCfg, nextConsumer)
+}
+
+// CreateMetricsToTraces is not supported, the count connector only consumes traces.
+func (f *Factory) CreateMetricsToTraces(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Connector,
+) (connector.MetricsConnector, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// CreateMetricsToMetrics is not supported, the count connector only consumes traces.
+func (f *Factory) CreateMetricsToMetrics(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Connector,
+) (connector.MetricsConnector, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
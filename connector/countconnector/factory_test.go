@@ -0,0 +1,66 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/spancount"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+}
+
+func TestCreateConnector(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	tt, err := factory.CreateTracesToTraces(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.Equal(t, err, configerror.ErrDataTypeIsNotSupported)
+	assert.Nil(t, tt)
+	mt, err := factory.CreateMetricsToTraces(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.Equal(t, err, configerror.ErrDataTypeIsNotSupported)
+	assert.Nil(t, mt)
+	mm, err := factory.CreateMetricsToMetrics(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.Equal(t, err, configerror.ErrDataTypeIsNotSupported)
+	assert.Nil(t, mm)
+
+	// The default config does not define any metric.
+	tm, err := factory.CreateTracesToMetrics(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.NotNil(t, err)
+	assert.Nil(t, tm)
+
+	cfg.Metrics = []spancount.MetricDefinition{{Name: "spans"}}
+	tm, err = factory.CreateTracesToMetrics(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.Nil(t, err, "connector creation failed")
+	assert.NotNil(t, tm, "connector creation failed")
+
+	tm, err = factory.CreateTracesToMetrics(zap.NewNop(), nil, cfg)
+	assert.NotNil(t, err)
+	assert.Nil(t, tm)
+
+	cfg.Interval = 0
+	tm, err = factory.CreateTracesToMetrics(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.NotNil(t, err)
+	assert.Nil(t, tm)
+}
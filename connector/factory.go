@@ -0,0 +1,69 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connector
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+)
+
+// Factory is factory interface for connectors. The data types a connector
+// converts are declared by the create functions it supports, the others
+// return configerror.ErrDataTypeIsNotSupported.
+type Factory interface {
+	// Type gets the type of the Connector created by this factory.
+	Type() string
+
+	// CreateDefaultConfig creates the default configuration for the Connector.
+	CreateDefaultConfig() configmodels.Connector
+
+	// CreateTracesToTraces creates a connector consuming traces and emitting
+	// traces to nextConsumer.
+	CreateTracesToTraces(logger *zap.Logger, nextConsumer consumer.TraceConsumer,
+		cfg configmodels.Connector) (TraceConnector, error)
+
+	// CreateTracesToMetrics creates a connector consuming traces and emitting
+	// metrics to nextConsumer.
+	CreateTracesToMetrics(logger *zap.Logger, nextConsumer consumer.MetricsConsumer,
+		cfg configmodels.Connector) (TraceConnector, error)
+
+	// CreateMetricsToTraces creates a connector consuming metrics and
+	// emitting traces to nextConsumer.
+	CreateMetricsToTraces(logger *zap.Logger, nextConsumer consumer.TraceConsumer,
+		cfg configmodels.Connector) (MetricsConnector, error)
+
+	// CreateMetricsToMetrics creates a connector consuming metrics and
+	// emitting metrics to nextConsumer.
+	CreateMetricsToMetrics(logger *zap.Logger, nextConsumer consumer.MetricsConsumer,
+		cfg configmodels.Connector) (MetricsConnector, error)
+}
+
+// Build takes a list of connector factories and returns a map of type map[string]Factory
+// with factory type as keys. It returns a non-nil error when more than one factories
+// have the same type.
+func Build(factories ...Factory) (map[string]Factory, error) {
+	fMap := map[string]Factory{}
+	for _, f := range factories {
+		if _, ok := fMap[f.Type()]; ok {
+			return fMap, fmt.Errorf("duplicate connector factory %q", f.Type())
+		}
+		fMap[f.Type()] = f
+	}
+	return fMap, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+)
+
+type TestFactory struct {
+	name string
+}
+
+// Type gets the type of the Connector config created by this factory.
+func (f *TestFactory) Type() string {
+	return f.name
+}
+
+// CreateDefaultConfig creates the default configuration for the Connector.
+func (f *TestFactory) CreateDefaultConfig() configmodels.Connector {
+	return nil
+}
+
+// CreateTracesToTraces creates a traces to traces connector based on this config.
+func (f *TestFactory) CreateTracesToTraces(logger *zap.Logger, nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Connector) (TraceConnector, error) {
+	return nil, nil
+}
+
+// CreateTracesToMetrics creates a traces to metrics connector based on this config.
+func (f *TestFactory) CreateTracesToMetrics(logger *zap.Logger, nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Connector) (TraceConnector, error) {
+	return nil, nil
+}
+
+// CreateMetricsToTraces creates a metrics to traces connector based on this config.
+func (f *TestFactory) CreateMetricsToTraces(logger *zap.Logger, nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Connector) (MetricsConnector, error) {
+	return nil, nil
+}
+
+// CreateMetricsToMetrics creates a metrics to metrics connector based on this config.
+func (f *TestFactory) CreateMetricsToMetrics(logger *zap.Logger, nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Connector) (MetricsConnector, error) {
+	return nil, nil
+}
+
+func TestFactoriesBuilder(t *testing.T) {
+	type testCase struct {
+		in  []Factory
+		out map[string]Factory
+		err bool
+	}
+
+	testCases := []testCase{
+		{
+			in: []Factory{
+				&TestFactory{"conn1"},
+				&TestFactory{"conn2"},
+			},
+			out: map[string]Factory{
+				"conn1": &TestFactory{"conn1"},
+				"conn2": &TestFactory{"conn2"},
+			},
+			err: false,
+		},
+		{
+			in: []Factory{
+				&TestFactory{"conn1"},
+				&TestFactory{"conn1"},
+			},
+			err: true,
+		},
+	}
+
+	for _, c := range testCases {
+		out, err := Build(c.in...)
+		if c.err {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, c.out, out)
+	}
+}
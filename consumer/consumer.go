@@ -37,6 +37,20 @@ type TraceConsumer interface {
 	ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error
 }
 
+// RawTraceConsumer is an extra interface for TraceConsumer that can consume trace
+// data still serialized in the wire format it was received in, allowing receivers
+// to relay the data without decoding and translating it.
+//
+// AcceptsRawTraceFormat reports if the consumer, and all the consumers it sends the
+// data to, accept raw trace data in the given format.
+//
+// ConsumeRawTraceData receives consumerdata.RawTraceData in one of the accepted formats.
+// The payload must not be modified since it can be shared by multiple consumers.
+type RawTraceConsumer interface {
+	AcceptsRawTraceFormat(format string) bool
+	ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error
+}
+
 // DataConsumer is a union type that can accept traces and/or metrics.
 type DataConsumer interface {
 	TraceConsumer
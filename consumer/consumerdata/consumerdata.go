@@ -36,3 +36,11 @@ type TraceData struct {
 	Spans        []*tracepb.Span
 	SourceFormat string
 }
+
+// RawTraceData is a struct that holds trace data serialized in the wire format
+// it was received in.
+type RawTraceData struct {
+	// Format identifies the wire format of the payload.
+	Format  string
+	Payload []byte
+}
@@ -16,43 +16,105 @@
 package defaults
 
 import (
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/connector"
+	"github.com/open-telemetry/opentelemetry-service/connector/countconnector"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/elasticsearchexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegergrpcexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegerthrifthttpexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/loggingexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/prometheusexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/sapmexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/storeforwardexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/teeexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/zipkinexporter"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/extension/adaptivesamplingextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/backendhealthextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/bearertokenauthextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/effectiveconfigextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/leaderelectionextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/oidcauthextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/servicegraphextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/tracebufferextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/usagereportingextension"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/adaptivesamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/attributetypesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/cardinalityprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/clockskewprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/geoipprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/httpstatusprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/k8sprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/metricvalidationprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/nodefilterprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/pluginprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/probabilisticsamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/servicegraphprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/stalenessprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/starttimeprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/timestampsanitizerprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/tracebufferprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/wasmprocessor"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/heartbeatreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerkafkareceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/lightstepreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/protogrpcreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/samplesreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/sapmreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/vmmetricsreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/webhookreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/windowsperfcountersreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/zipkinreceiver"
 )
 
 // Components returns the default set of components used by the
 // opentelemetry service
 func Components() (
-	map[string]receiver.Factory,
-	map[string]processor.Factory,
-	map[string]exporter.Factory,
+	config.Factories,
 	error,
 ) {
 	errs := []error{}
+	extensions, err := extension.Build(
+		&servicegraphextension.Factory{},
+		&tracebufferextension.Factory{},
+		&effectiveconfigextension.Factory{},
+		&leaderelectionextension.Factory{},
+		&bearertokenauthextension.Factory{},
+		&oidcauthextension.Factory{},
+		&adaptivesamplingextension.Factory{},
+		&usagereportingextension.Factory{},
+		&backendhealthextension.Factory{},
+	)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	receivers, err := receiver.Build(
 		&jaegerreceiver.Factory{},
 		&zipkinreceiver.Factory{},
 		&prometheusreceiver.Factory{},
 		&opencensusreceiver.Factory{},
 		&vmmetricsreceiver.Factory{},
+		&lightstepreceiver.Factory{},
+		&sapmreceiver.Factory{},
+		&windowsperfcountersreceiver.Factory{},
+		&webhookreceiver.Factory{},
+		&heartbeatreceiver.Factory{},
+		&jaegerkafkareceiver.Factory{},
+		&protogrpcreceiver.Factory{},
+		&samplesreceiver.Factory{},
 	)
 	if err != nil {
 		errs = append(errs, err)
@@ -65,6 +127,10 @@ func Components() (
 		&zipkinexporter.Factory{},
 		&jaegergrpcexporter.Factory{},
 		&jaegerthrifthttpexporter.Factory{},
+		&sapmexporter.Factory{},
+		&teeexporter.Factory{},
+		&storeforwardexporter.Factory{},
+		&elasticsearchexporter.Factory{},
 	)
 	if err != nil {
 		errs = append(errs, err)
@@ -76,9 +142,40 @@ func Components() (
 		&nodebatcherprocessor.Factory{},
 		&tailsamplingprocessor.Factory{},
 		&probabilisticsamplerprocessor.Factory{},
+		&k8sprocessor.Factory{},
+		&starttimeprocessor.Factory{},
+		&servicegraphprocessor.Factory{},
+		&tracebufferprocessor.Factory{},
+		&cardinalityprocessor.Factory{},
+		&httpstatusprocessor.Factory{},
+		&stalenessprocessor.Factory{},
+		&pluginprocessor.Factory{},
+		&wasmprocessor.Factory{},
+		&metricvalidationprocessor.Factory{},
+		&adaptivesamplerprocessor.Factory{},
+		&attributetypesprocessor.Factory{},
+		&nodefilterprocessor.Factory{},
+		&geoipprocessor.Factory{},
+		&clockskewprocessor.Factory{},
+		&timestampsanitizerprocessor.Factory{},
+	)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	connectors, err := connector.Build(
+		&countconnector.Factory{},
 	)
 	if err != nil {
 		errs = append(errs, err)
 	}
-	return receivers, processors, exporters, oterr.CombineErrors(errs)
+
+	factories := config.Factories{
+		Extensions: extensions,
+		Receivers:  receivers,
+		Processors: processors,
+		Exporters:  exporters,
+		Connectors: connectors,
+	}
+	return factories, oterr.CombineErrors(errs)
 }
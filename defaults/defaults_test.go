@@ -22,34 +22,93 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/open-telemetry/opentelemetry-service/connector"
+	"github.com/open-telemetry/opentelemetry-service/connector/countconnector"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/elasticsearchexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegergrpcexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegerthrifthttpexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/loggingexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/prometheusexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/sapmexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/storeforwardexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/teeexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/zipkinexporter"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/extension/adaptivesamplingextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/backendhealthextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/bearertokenauthextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/effectiveconfigextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/leaderelectionextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/oidcauthextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/servicegraphextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/tracebufferextension"
+	"github.com/open-telemetry/opentelemetry-service/extension/usagereportingextension"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/adaptivesamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/attributetypesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/cardinalityprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/clockskewprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/geoipprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/httpstatusprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/k8sprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/metricvalidationprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/nodefilterprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/pluginprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/probabilisticsamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/servicegraphprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/stalenessprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/starttimeprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/timestampsanitizerprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/tracebufferprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/wasmprocessor"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/heartbeatreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerkafkareceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/lightstepreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/protogrpcreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/samplesreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/sapmreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/vmmetricsreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/webhookreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/windowsperfcountersreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/zipkinreceiver"
 )
 
 func TestDefaultComponents(t *testing.T) {
+	expectedExtensions := map[string]extension.Factory{
+		"service-graph":     &servicegraphextension.Factory{},
+		"trace-buffer":      &tracebufferextension.Factory{},
+		"effective-config":  &effectiveconfigextension.Factory{},
+		"leader-election":   &leaderelectionextension.Factory{},
+		"bearer-token-auth": &bearertokenauthextension.Factory{},
+		"oidc-auth":         &oidcauthextension.Factory{},
+		"adaptive-sampling": &adaptivesamplingextension.Factory{},
+		"usage-reporting":   &usagereportingextension.Factory{},
+		"backend-health":    &backendhealthextension.Factory{},
+	}
 	expectedReceivers := map[string]receiver.Factory{
-		"jaeger":     &jaegerreceiver.Factory{},
-		"zipkin":     &zipkinreceiver.Factory{},
-		"prometheus": &prometheusreceiver.Factory{},
-		"opencensus": &opencensusreceiver.Factory{},
-		"vmmetrics":  &vmmetricsreceiver.Factory{},
+		"jaeger":              &jaegerreceiver.Factory{},
+		"zipkin":              &zipkinreceiver.Factory{},
+		"prometheus":          &prometheusreceiver.Factory{},
+		"opencensus":          &opencensusreceiver.Factory{},
+		"vmmetrics":           &vmmetricsreceiver.Factory{},
+		"lightstep":           &lightstepreceiver.Factory{},
+		"sapm":                &sapmreceiver.Factory{},
+		"windowsperfcounters": &windowsperfcountersreceiver.Factory{},
+		"webhook":             &webhookreceiver.Factory{},
+		"heartbeat":           &heartbeatreceiver.Factory{},
+		"jaeger-kafka":        &jaegerkafkareceiver.Factory{},
+		"proto-grpc":          &protogrpcreceiver.Factory{},
+		"samples":             &samplesreceiver.Factory{},
 	}
 	expectedProcessors := map[string]processor.Factory{
 		"attributes":            &attributesprocessor.Factory{},
@@ -57,6 +116,22 @@ func TestDefaultComponents(t *testing.T) {
 		"batch":                 &nodebatcherprocessor.Factory{},
 		"tail-sampling":         &tailsamplingprocessor.Factory{},
 		"probabilistic-sampler": &probabilisticsamplerprocessor.Factory{},
+		"k8s-resource":          &k8sprocessor.Factory{},
+		"start-time":            &starttimeprocessor.Factory{},
+		"service-graph":         &servicegraphprocessor.Factory{},
+		"trace-buffer":          &tracebufferprocessor.Factory{},
+		"cardinality":           &cardinalityprocessor.Factory{},
+		"http-status":           &httpstatusprocessor.Factory{},
+		"staleness":             &stalenessprocessor.Factory{},
+		"plugin":                &pluginprocessor.Factory{},
+		"wasm":                  &wasmprocessor.Factory{},
+		"metric-validation":     &metricvalidationprocessor.Factory{},
+		"adaptive-sampler":      &adaptivesamplerprocessor.Factory{},
+		"attribute-types":       &attributetypesprocessor.Factory{},
+		"node-filter":           &nodefilterprocessor.Factory{},
+		"geoip":                 &geoipprocessor.Factory{},
+		"clock-skew":            &clockskewprocessor.Factory{},
+		"timestamp-sanitizer":   &timestampsanitizerprocessor.Factory{},
 	}
 	expectedExporters := map[string]exporter.Factory{
 		"opencensus":         &opencensusexporter.Factory{},
@@ -65,12 +140,21 @@ func TestDefaultComponents(t *testing.T) {
 		"zipkin":             &zipkinexporter.Factory{},
 		"jaeger-grpc":        &jaegergrpcexporter.Factory{},
 		"jaeger-thrift-http": &jaegerthrifthttpexporter.Factory{},
+		"sapm":               &sapmexporter.Factory{},
+		"tee":                &teeexporter.Factory{},
+		"store-and-forward":  &storeforwardexporter.Factory{},
+		"elasticsearch":      &elasticsearchexporter.Factory{},
+	}
+	expectedConnectors := map[string]connector.Factory{
+		"count": &countconnector.Factory{},
 	}
 
-	receivers, processors, exporters, err := Components()
+	factories, err := Components()
 	fmt.Println(err)
 	assert.Nil(t, err)
-	assert.Equal(t, expectedReceivers, receivers)
-	assert.Equal(t, expectedProcessors, processors)
-	assert.Equal(t, expectedExporters, exporters)
+	assert.Equal(t, expectedExtensions, factories.Extensions)
+	assert.Equal(t, expectedReceivers, factories.Receivers)
+	assert.Equal(t, expectedProcessors, factories.Processors)
+	assert.Equal(t, expectedExporters, factories.Exporters)
+	assert.Equal(t, expectedConnectors, factories.Connectors)
 }
@@ -0,0 +1,77 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearchexporter
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+)
+
+// Config defines configuration for the Elasticsearch exporter.
+type Config struct {
+	configmodels.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	// URL is the URL of the Elasticsearch or OpenSearch cluster (e.g.:
+	// https://elasticsearch.example.com:9200), the documents are sent to its
+	// bulk API.
+	URL string `mapstructure:"url"`
+
+	// Username and Password, if Username is not empty, are sent with HTTP
+	// basic authentication.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// APIKey, if not empty, is sent in the Authorization header of each
+	// request. It is the base64 encoding of the API key ID and API key joined
+	// by a colon, as returned by Elasticsearch.
+	APIKey string `mapstructure:"api-key"`
+
+	// Dataset and Namespace name the data stream the spans are written to:
+	// "traces-<dataset>-<namespace>". The defaults are "generic" and
+	// "default".
+	Dataset   string `mapstructure:"dataset"`
+	Namespace string `mapstructure:"namespace"`
+
+	// Timeout is the maximum timeout for HTTP request sending trace data. The
+	// default value is 5 seconds.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Headers are a set of headers to be added to the HTTP request sending
+	// trace data.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// UserAgent, if not empty, replaces the User-Agent header of the HTTP
+	// requests.
+	UserAgent string `mapstructure:"user-agent"`
+
+	// AttributeIndexing selects the span attributes written as indexed
+	// fields, the payload attributes are stored without being indexed.
+	AttributeIndexing attributeindex.Settings `mapstructure:"attribute-indexing"`
+
+	// CircuitBreaker makes the exporter fail fast, without sending the data,
+	// while the destination keeps failing.
+	CircuitBreaker exporterhelper.CircuitBreakerSettings `mapstructure:"circuit-breaker"`
+
+	// SortSpans sends the spans of each batch grouped by trace and ordered by
+	// start time, for the backends ingesting ordered spans more efficiently.
+	SortSpans bool `mapstructure:"sort-spans"`
+
+	// PropagateTraceContext sends the trace context of the exporter span
+	// with the requests, so that the backends can trace them as its children.
+	PropagateTraceContext bool `mapstructure:"propagate-trace-context"`
+}
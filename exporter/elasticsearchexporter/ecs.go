@@ -0,0 +1,229 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearchexporter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// ecsAttributes maps the span attributes with an Elastic Common Schema (ECS)
+// equivalent to their ECS field. The other attributes are written as labels.
+var ecsAttributes = map[string]string{
+	"http.method":                     "http.request.method",
+	"http.url":                        "url.full",
+	"http.path":                       "url.path",
+	"http.host":                       "url.domain",
+	"http.route":                      "transaction.route",
+	"http.user_agent":                 "user_agent.original",
+	tracetranslator.TagHTTPStatusCode: "http.response.status_code",
+	"net.peer.ip":                     "destination.ip",
+	"net.peer.name":                   "destination.domain",
+	"net.peer.port":                   "destination.port",
+	"db.type":                         "span.db.type",
+	"db.instance":                     "span.db.instance",
+	"db.statement":                    "span.db.statement",
+	"db.user":                         "span.db.user.name",
+	"error.message":                   "error.message",
+}
+
+// spanToECS converts a span to an ECS document. The fields are dotted, which
+// Elasticsearch expands to objects. Spans without a parent, or with the server
+// kind and a parent not known to be in the same process, are transactions in Elastic APM terms, the other
+// spans belong to the transaction of their parent. The payload attributes,
+// according to the indexing, are not indexed.
+func spanToECS(
+	node *commonpb.Node,
+	span *tracepb.Span,
+	indexing *attributeindex.Classifier,
+	dataset, namespace string,
+) map[string]interface{} {
+	doc := map[string]interface{}{
+		"data_stream.type":      dataStreamType,
+		"data_stream.dataset":   dataset,
+		"data_stream.namespace": namespace,
+		"trace.id":              hex.EncodeToString(span.TraceId),
+		"span.id":               hex.EncodeToString(span.SpanId),
+		"event.outcome":         "success",
+	}
+
+	start := timestampToTime(span.StartTime)
+	if !start.IsZero() {
+		doc["@timestamp"] = start.UTC().Format(time.RFC3339Nano)
+	}
+	var duration time.Duration
+	if end := timestampToTime(span.EndTime); !start.IsZero() && end.After(start) {
+		duration = end.Sub(start)
+	}
+	doc["event.duration"] = duration.Nanoseconds()
+
+	name := span.GetName().GetValue()
+	if isTransaction(span) {
+		doc["processor.event"] = "transaction"
+		doc["transaction.id"] = doc["span.id"]
+		doc["transaction.name"] = name
+		doc["transaction.type"] = transactionType(span.Kind)
+		doc["transaction.duration.us"] = duration.Nanoseconds() / 1e3
+	} else {
+		doc["processor.event"] = "span"
+		doc["span.name"] = name
+		doc["span.type"] = spanType(span.Kind)
+		doc["span.duration.us"] = duration.Nanoseconds() / 1e3
+	}
+	if len(span.ParentSpanId) > 0 {
+		doc["parent.id"] = hex.EncodeToString(span.ParentSpanId)
+	}
+	if kind := spanKind(span.Kind); kind != "" {
+		doc["span.kind"] = kind
+	}
+
+	if status := span.Status; status != nil && status.Code != 0 {
+		doc["event.outcome"] = "failure"
+		if status.Message != "" {
+			doc["error.message"] = status.Message
+		}
+	}
+
+	addNodeFields(doc, node)
+	addAttributes(doc, span.Attributes, indexing)
+	return doc
+}
+
+func isTransaction(span *tracepb.Span) bool {
+	if len(span.ParentSpanId) == 0 {
+		return true
+	}
+	return span.Kind == tracepb.Span_SERVER && !span.GetSameProcessAsParentSpan().GetValue()
+}
+
+func transactionType(kind tracepb.Span_SpanKind) string {
+	if kind == tracepb.Span_SERVER {
+		return "request"
+	}
+	return "custom"
+}
+
+func spanType(kind tracepb.Span_SpanKind) string {
+	if kind == tracepb.Span_CLIENT {
+		return "external"
+	}
+	return "custom"
+}
+
+func spanKind(kind tracepb.Span_SpanKind) string {
+	switch kind {
+	case tracepb.Span_CLIENT:
+		return "client"
+	case tracepb.Span_SERVER:
+		return "server"
+	}
+	return ""
+}
+
+func addNodeFields(doc map[string]interface{}, node *commonpb.Node) {
+	if name := node.GetServiceInfo().GetName(); name != "" {
+		doc["service.name"] = name
+	}
+	if hostname := node.GetIdentifier().GetHostName(); hostname != "" {
+		doc["host.hostname"] = hostname
+	}
+	if pid := node.GetIdentifier().GetPid(); pid != 0 {
+		doc["process.pid"] = pid
+	}
+	if lang := node.GetLibraryInfo().GetLanguage(); lang != commonpb.LibraryInfo_LANGUAGE_UNSPECIFIED {
+		doc["service.language.name"] = strings.ToLower(lang.String())
+	}
+	if version := node.GetLibraryInfo().GetExporterVersion(); version != "" {
+		doc["agent.version"] = version
+	}
+}
+
+// addAttributes adds the attributes with an ECS equivalent as their ECS field,
+// and the other attributes as labels: "labels" for the strings and booleans,
+// "numeric_labels" for the numbers. The dots of the label keys, not allowed by
+// ECS, are replaced with underscores. The payload attributes are encoded as a
+// JSON object in "event.original", which ECS stores without indexing it.
+func addAttributes(
+	doc map[string]interface{},
+	attributes *tracepb.Span_Attributes,
+	indexing *attributeindex.Classifier,
+) {
+	var payload map[string]interface{}
+	for key, attrib := range attributes.GetAttributeMap() {
+		value := attributeValue(attrib)
+		if value == nil {
+			continue
+		}
+		if indexing.IsPayload(key) {
+			if payload == nil {
+				payload = make(map[string]interface{})
+			}
+			payload[key] = value
+			continue
+		}
+		if field, ok := ecsAttributes[key]; ok {
+			doc[field] = value
+			continue
+		}
+
+		labelKey := strings.Replace(key, ".", "_", -1)
+		switch v := value.(type) {
+		case int64, float64:
+			doc["numeric_labels."+labelKey] = v
+		case bool:
+			doc["labels."+labelKey] = strconv.FormatBool(v)
+		default:
+			doc["labels."+labelKey] = v
+		}
+	}
+
+	if payload != nil {
+		// The values are strings, numbers and booleans, which can't fail to
+		// be encoded.
+		original, _ := json.Marshal(payload)
+		doc["event.original"] = string(original)
+	}
+}
+
+func attributeValue(attrib *tracepb.AttributeValue) interface{} {
+	switch v := attrib.GetValue().(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return v.StringValue.GetValue()
+	case *tracepb.AttributeValue_IntValue:
+		return v.IntValue
+	case *tracepb.AttributeValue_DoubleValue:
+		return v.DoubleValue
+	case *tracepb.AttributeValue_BoolValue:
+		return v.BoolValue
+	}
+	return nil
+}
+
+func timestampToTime(ts *timestamp.Timestamp) (t time.Time) {
+	if ts == nil {
+		return
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos))
+}
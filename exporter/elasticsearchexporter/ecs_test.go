@@ -0,0 +1,152 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearchexporter
+
+import (
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+)
+
+func TestSpanToECS_Transaction(t *testing.T) {
+	node := &commonpb.Node{
+		Identifier:  &commonpb.ProcessIdentifier{HostName: "host-1", Pid: 42},
+		LibraryInfo: &commonpb.LibraryInfo{Language: commonpb.LibraryInfo_GO_LANG, ExporterVersion: "0.22.0"},
+		ServiceInfo: &commonpb.ServiceInfo{Name: "checkout"},
+	}
+	span := &tracepb.Span{
+		TraceId:      []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		SpanId:       []byte{0, 0, 0, 0, 0, 0, 0, 2},
+		ParentSpanId: []byte{0, 0, 0, 0, 0, 0, 0, 3},
+		Name:         &tracepb.TruncatableString{Value: "GET /cart"},
+		Kind:         tracepb.Span_SERVER,
+		StartTime:    &timestamp.Timestamp{Seconds: 1571000000, Nanos: 500},
+		EndTime:      &timestamp.Timestamp{Seconds: 1571000001, Nanos: 500},
+		Status:       &tracepb.Status{Code: 13, Message: "internal"},
+		Attributes: &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+			"http.method":      {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "GET"}}},
+			"http.status_code": {Value: &tracepb.AttributeValue_IntValue{IntValue: 500}},
+			"cart.size":        {Value: &tracepb.AttributeValue_IntValue{IntValue: 3}},
+			"cart.empty":       {Value: &tracepb.AttributeValue_BoolValue{BoolValue: false}},
+			"customer.tier":    {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "gold"}}},
+		}},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"@timestamp":                "2019-10-13T20:53:20.0000005Z",
+		"data_stream.type":          "traces",
+		"data_stream.dataset":       "generic",
+		"data_stream.namespace":     "default",
+		"trace.id":                  "00000000000000000000000000000001",
+		"span.id":                   "0000000000000002",
+		"parent.id":                 "0000000000000003",
+		"span.kind":                 "server",
+		"processor.event":           "transaction",
+		"transaction.id":            "0000000000000002",
+		"transaction.name":          "GET /cart",
+		"transaction.type":          "request",
+		"transaction.duration.us":   int64(1000000),
+		"event.duration":            int64(1000000000),
+		"event.outcome":             "failure",
+		"error.message":             "internal",
+		"service.name":              "checkout",
+		"service.language.name":     "go_lang",
+		"host.hostname":             "host-1",
+		"process.pid":               uint32(42),
+		"agent.version":             "0.22.0",
+		"http.request.method":       "GET",
+		"http.response.status_code": int64(500),
+		"numeric_labels.cart_size":  int64(3),
+		"labels.cart_empty":         "false",
+		"labels.customer_tier":      "gold",
+	}, spanToECS(node, span, nil, "generic", "default"))
+}
+
+func TestSpanToECS_Span(t *testing.T) {
+	span := &tracepb.Span{
+		TraceId:                 []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		SpanId:                  []byte{0, 0, 0, 0, 0, 0, 0, 4},
+		ParentSpanId:            []byte{0, 0, 0, 0, 0, 0, 0, 2},
+		Name:                    &tracepb.TruncatableString{Value: "SELECT"},
+		Kind:                    tracepb.Span_CLIENT,
+		SameProcessAsParentSpan: &wrappers.BoolValue{Value: true},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"data_stream.type":      "traces",
+		"data_stream.dataset":   "db",
+		"data_stream.namespace": "staging",
+		"trace.id":              "00000000000000000000000000000001",
+		"span.id":               "0000000000000004",
+		"parent.id":             "0000000000000002",
+		"span.kind":             "client",
+		"processor.event":       "span",
+		"span.name":             "SELECT",
+		"span.type":             "external",
+		"span.duration.us":      int64(0),
+		"event.duration":        int64(0),
+		"event.outcome":         "success",
+	}, spanToECS(nil, span, nil, "db", "staging"))
+}
+
+func TestSpanToECS_AttributeIndexing(t *testing.T) {
+	span := &tracepb.Span{
+		TraceId: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		SpanId:  []byte{0, 0, 0, 0, 0, 0, 0, 2},
+		Attributes: &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+			"http.method":       {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "POST"}}},
+			"http.request.body": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "{}"}}},
+			"db.statement":      {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "SELECT 1"}}},
+			"cart.size":         {Value: &tracepb.AttributeValue_IntValue{IntValue: 3}},
+		}},
+	}
+	indexing := attributeindex.NewClassifier(attributeindex.Settings{
+		Payload: []string{"http.request.*", "db.statement"},
+	})
+
+	doc := spanToECS(nil, span, indexing, "generic", "default")
+	assert.Equal(t, "POST", doc["http.request.method"])
+	assert.Equal(t, int64(3), doc["numeric_labels.cart_size"])
+	assert.NotContains(t, doc, "span.db.statement")
+	assert.NotContains(t, doc, "labels.http_request_body")
+	assert.JSONEq(t, `{"http.request.body":"{}","db.statement":"SELECT 1"}`, doc["event.original"].(string))
+
+	doc = spanToECS(nil, span, nil, "generic", "default")
+	assert.Equal(t, "SELECT 1", doc["span.db.statement"])
+	assert.NotContains(t, doc, "event.original")
+}
+
+func TestIsTransaction(t *testing.T) {
+	parent := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	assert.True(t, isTransaction(&tracepb.Span{}))
+	assert.True(t, isTransaction(&tracepb.Span{ParentSpanId: parent, Kind: tracepb.Span_SERVER}))
+	assert.True(t, isTransaction(&tracepb.Span{
+		ParentSpanId:            parent,
+		Kind:                    tracepb.Span_SERVER,
+		SameProcessAsParentSpan: &wrappers.BoolValue{Value: false},
+	}))
+	assert.False(t, isTransaction(&tracepb.Span{
+		ParentSpanId:            parent,
+		Kind:                    tracepb.Span_SERVER,
+		SameProcessAsParentSpan: &wrappers.BoolValue{Value: true},
+	}))
+	assert.False(t, isTransaction(&tracepb.Span{ParentSpanId: parent, Kind: tracepb.Span_CLIENT}))
+}
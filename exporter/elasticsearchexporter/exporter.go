@@ -0,0 +1,203 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elasticsearchexporter implements an exporter that writes spans to
+// Elasticsearch or OpenSearch data streams, mapped to the Elastic Common
+// Schema (ECS) so that the Elastic APM UI can display them.
+package elasticsearchexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+)
+
+const (
+	// Default timeout for http request in seconds
+	defaultHTTPTimeout = time.Second * 5
+
+	// dataStreamType is the type of the data streams the spans are written
+	// to, the logs will be written to the "logs" data streams.
+	dataStreamType = "traces"
+
+	bulkPath = "/_bulk"
+)
+
+// bulkSender writes documents to a data stream with the bulk API.
+type bulkSender struct {
+	url       string
+	index     string
+	dataset   string
+	namespace string
+	username  string
+	password  string
+	apiKey    string
+	headers   map[string]string
+	client    *http.Client
+	indexing  *attributeindex.Classifier
+	propagate bool
+}
+
+// bulkResponse is the part of the response of the bulk API used to find the
+// documents that failed.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+func newTraceExporter(cfg *Config) (exporter.TraceExporter, error) {
+	s := newBulkSender(cfg)
+	return exporterhelper.NewTraceExporter(
+		cfg.Name(),
+		s.pushTraceData,
+		exporterhelper.WithSpanName("otelsvc.exporter."+cfg.Name()+".ConsumeTraceData"),
+		exporterhelper.WithRecordMetrics(true),
+		exporterhelper.WithCircuitBreaker(cfg.CircuitBreaker),
+		exporterhelper.WithSortedSpans(cfg.SortSpans))
+}
+
+func newBulkSender(cfg *Config) *bulkSender {
+	return &bulkSender{
+		url:       strings.TrimSuffix(cfg.URL, "/") + bulkPath,
+		index:     dataStreamType + "-" + cfg.Dataset + "-" + cfg.Namespace,
+		dataset:   cfg.Dataset,
+		namespace: cfg.Namespace,
+		username:  cfg.Username,
+		password:  cfg.Password,
+		apiKey:    cfg.APIKey,
+		headers:   exporterhelper.HeadersWithUserAgent(cfg.Headers, cfg.UserAgent),
+		client:    &http.Client{Timeout: cfg.Timeout},
+		indexing:  attributeindex.NewClassifier(cfg.AttributeIndexing),
+		propagate: cfg.PropagateTraceContext,
+	}
+}
+
+func (s *bulkSender) pushTraceData(
+	ctx context.Context,
+	td consumerdata.TraceData,
+) (droppedSpans int, err error) {
+
+	if len(td.Spans) == 0 {
+		return 0, nil
+	}
+
+	var body bytes.Buffer
+	action, err := json.Marshal(map[string]interface{}{
+		"create": map[string]string{"_index": s.index},
+	})
+	if err != nil {
+		return len(td.Spans), consumererror.Permanent(err)
+	}
+	enc := json.NewEncoder(&body)
+	spans := 0
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		if err := enc.Encode(spanToECS(td.Node, span, s.indexing, s.dataset, s.namespace)); err != nil {
+			return len(td.Spans), consumererror.Permanent(err)
+		}
+		spans++
+	}
+	if spans == 0 {
+		return 0, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, &body)
+	if err != nil {
+		return len(td.Spans), consumererror.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.apiKey)
+	}
+
+	if s.propagate {
+		exporterhelper.InjectHTTPTraceContext(ctx, req)
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return len(td.Spans), err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf(
+			"HTTP %d %q",
+			resp.StatusCode,
+			http.StatusText(resp.StatusCode))
+		// Retrying the requests rejected for their content doesn't help.
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			err = consumererror.Permanent(err)
+		}
+		return len(td.Spans), err
+	}
+
+	var bulkResp bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bulkResp); err != nil {
+		return len(td.Spans), fmt.Errorf("failed to decode the bulk response: %v", err)
+	}
+	if !bulkResp.Errors {
+		return 0, nil
+	}
+
+	// The documents written are not sent again, as retrying the batch would
+	// duplicate them.
+	failed := 0
+	var firstErr string
+	for _, item := range bulkResp.Items {
+		for _, result := range item {
+			if result.Status < http.StatusBadRequest {
+				continue
+			}
+			failed++
+			if firstErr == "" && result.Error != nil {
+				firstErr = result.Error.Type + ": " + result.Error.Reason
+			}
+		}
+	}
+	return failed, consumererror.Permanent(fmt.Errorf(
+		"%d of %d documents failed to be written to %q, first error: %s",
+		failed, spans, s.index, firstErr))
+}
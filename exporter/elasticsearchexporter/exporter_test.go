@@ -0,0 +1,192 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearchexporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+func testTraceData() consumerdata.TraceData {
+	return consumerdata.TraceData{
+		Node: &commonpb.Node{
+			ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+		},
+		Spans: []*tracepb.Span{
+			{
+				TraceId: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+				SpanId:  []byte{0, 0, 0, 0, 0, 0, 0, 2},
+				Name:    &tracepb.TruncatableString{Value: "a"},
+			},
+			nil,
+			{
+				TraceId:      []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+				SpanId:       []byte{0, 0, 0, 0, 0, 0, 0, 3},
+				ParentSpanId: []byte{0, 0, 0, 0, 0, 0, 0, 2},
+				Name:         &tracepb.TruncatableString{Value: "b"},
+			},
+		},
+	}
+}
+
+func newTestSender(url string, modify func(*Config)) *bulkSender {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.URL = url
+	if modify != nil {
+		modify(cfg)
+	}
+	return newBulkSender(cfg)
+}
+
+// readBulk returns the actions and documents of a bulk request.
+func readBulk(t *testing.T, r io.Reader) (actions, docs []map[string]interface{}) {
+	scanner := bufio.NewScanner(r)
+	for i := 0; scanner.Scan(); i++ {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		if i%2 == 0 {
+			actions = append(actions, line)
+		} else {
+			docs = append(docs, line)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return actions, docs
+}
+
+func TestExporter_PushTraceData(t *testing.T) {
+	var gotReq *http.Request
+	var actions, docs []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		actions, docs = readBulk(t, r.Body)
+		io.WriteString(w, `{"took":1,"errors":false,"items":[{"create":{"status":201}},{"create":{"status":201}}]}`)
+	}))
+	defer srv.Close()
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.URL = srv.URL + "/"
+	cfg.Username = "elastic"
+	cfg.Password = "changeme"
+	cfg.Dataset = "checkout"
+	cfg.Headers = map[string]string{"added-entry": "added value"}
+	cfg.UserAgent = "custom-agent/1.0"
+	exp, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+	require.NoError(t, exp.Shutdown())
+
+	require.NotNil(t, gotReq)
+	assert.Equal(t, http.MethodPost, gotReq.Method)
+	assert.Equal(t, bulkPath, gotReq.URL.Path)
+	assert.Equal(t, "application/x-ndjson", gotReq.Header.Get("Content-Type"))
+	assert.Equal(t, "added value", gotReq.Header.Get("added-entry"))
+	assert.Equal(t, "custom-agent/1.0", gotReq.Header.Get("User-Agent"))
+	user, password, ok := gotReq.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "elastic", user)
+	assert.Equal(t, "changeme", password)
+
+	want := map[string]interface{}{"create": map[string]interface{}{"_index": "traces-checkout-default"}}
+	assert.Equal(t, []map[string]interface{}{want, want}, actions)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "a", docs[0]["transaction.name"])
+	assert.Equal(t, "frontend", docs[0]["service.name"])
+	assert.Equal(t, "b", docs[1]["span.name"])
+}
+
+func TestExporter_APIKey(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		io.WriteString(w, `{"errors":false}`)
+	}))
+	defer srv.Close()
+
+	s := newTestSender(srv.URL, func(cfg *Config) { cfg.APIKey = "a2V5OnNlY3JldA==" })
+	dropped, err := s.pushTraceData(context.Background(), testTraceData())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.Equal(t, "ApiKey a2V5OnNlY3JldA==", gotHeader.Get("Authorization"))
+}
+
+func TestExporter_ItemErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"errors":true,"items":[`+
+			`{"create":{"status":201}},`+
+			`{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`)
+	}))
+	defer srv.Close()
+
+	s := newTestSender(srv.URL, nil)
+	dropped, err := s.pushTraceData(context.Background(), testTraceData())
+	assert.Equal(t, 1, dropped)
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err), "written documents must not be sent again")
+	assert.Contains(t, err.Error(), "mapper_parsing_exception: failed to parse")
+}
+
+func TestExporter_ErrorStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{status: http.StatusUnauthorized, permanent: true},
+		{status: http.StatusRequestEntityTooLarge, permanent: true},
+		{status: http.StatusTooManyRequests, permanent: false},
+		{status: http.StatusServiceUnavailable, permanent: false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			s := newTestSender(srv.URL, nil)
+			dropped, err := s.pushTraceData(context.Background(), testTraceData())
+			assert.Equal(t, 3, dropped)
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, consumererror.IsPermanent(err))
+		})
+	}
+}
+
+func TestExporter_NoSpans(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected without spans")
+	}))
+	defer srv.Close()
+
+	s := newTestSender(srv.URL, nil)
+	dropped, err := s.pushTraceData(context.Background(), consumerdata.TraceData{Spans: []*tracepb.Span{nil}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+}
@@ -0,0 +1,122 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearchexporter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "elasticsearch"
+
+	defaultDataset   = "generic"
+	defaultNamespace = "default"
+
+	// invalidDataStreamChars are the characters not allowed in the dataset
+	// and namespace of a data stream name. The hyphen separates the parts of
+	// the name.
+	invalidDataStreamChars = `-\/*?"<>| ,#:`
+)
+
+// Factory is the factory for Elasticsearch exporter.
+type Factory struct {
+}
+
+// Type gets the type of the Exporter config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for exporter.
+func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
+	return &Config{
+		ExporterSettings: configmodels.ExporterSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		Dataset:        defaultDataset,
+		Namespace:      defaultNamespace,
+		Timeout:        defaultHTTPTimeout,
+		CircuitBreaker: exporterhelper.NewDefaultCircuitBreakerSettings(),
+	}
+}
+
+// CreateTraceExporter creates a trace exporter based on this config.
+func (f *Factory) CreateTraceExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+) (exporter.TraceExporter, error) {
+
+	expCfg := config.(*Config)
+	if _, err := url.ParseRequestURI(expCfg.URL); err != nil {
+		return nil, fmt.Errorf(
+			"%q config requires a valid \"url\": %v",
+			expCfg.Name(),
+			err)
+	}
+
+	if expCfg.Timeout <= 0 {
+		return nil, fmt.Errorf(
+			"%q config requires a positive value for \"timeout\"",
+			expCfg.Name())
+	}
+
+	if expCfg.Username != "" && expCfg.APIKey != "" {
+		return nil, fmt.Errorf(
+			"%q config can't have both \"username\" and \"api-key\"",
+			expCfg.Name())
+	}
+
+	if err := validateDataStreamPart(expCfg.Name(), "dataset", expCfg.Dataset); err != nil {
+		return nil, err
+	}
+	if err := validateDataStreamPart(expCfg.Name(), "namespace", expCfg.Namespace); err != nil {
+		return nil, err
+	}
+
+	return newTraceExporter(expCfg)
+}
+
+// CreateMetricsExporter creates a metrics exporter based on this config.
+func (f *Factory) CreateMetricsExporter(
+	logger *zap.Logger,
+	cfg configmodels.Exporter,
+) (exporter.MetricsExporter, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// validateDataStreamPart checks that the value can be used in a data stream
+// name, which must be lowercase.
+func validateDataStreamPart(name, setting, value string) error {
+	switch {
+	case value == "":
+		return fmt.Errorf("%q config requires a non-empty %q", name, setting)
+	case value != strings.ToLower(value):
+		return fmt.Errorf("%q config requires a lowercase %q, got %q", name, setting, value)
+	case strings.ContainsAny(value, invalidDataStreamChars):
+		return fmt.Errorf("%q config requires a %q without any of %q, got %q", name, setting, invalidDataStreamChars, value)
+	}
+	return nil
+}
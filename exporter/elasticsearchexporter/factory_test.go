@@ -0,0 +1,106 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearchexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+}
+
+func TestCreateMetricsExporter(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+
+	_, err := factory.CreateMetricsExporter(zap.NewNop(), cfg)
+	assert.Equal(t, configerror.ErrDataTypeIsNotSupported, err)
+}
+
+func TestFactory_CreateTraceExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:    "empty_url",
+			modify:  func(cfg *Config) { cfg.URL = "" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid_url",
+			modify:  func(cfg *Config) { cfg.URL = "127.0.0.1:9200" },
+			wantErr: true,
+		},
+		{
+			name:    "negative_duration",
+			modify:  func(cfg *Config) { cfg.Timeout = -1 },
+			wantErr: true,
+		},
+		{
+			name: "username_and_api_key",
+			modify: func(cfg *Config) {
+				cfg.Username = "elastic"
+				cfg.APIKey = "a2V5OnNlY3JldA=="
+			},
+			wantErr: true,
+		},
+		{
+			name:    "empty_dataset",
+			modify:  func(cfg *Config) { cfg.Dataset = "" },
+			wantErr: true,
+		},
+		{
+			name:    "uppercase_dataset",
+			modify:  func(cfg *Config) { cfg.Dataset = "Checkout" },
+			wantErr: true,
+		},
+		{
+			name:    "hyphen_namespace",
+			modify:  func(cfg *Config) { cfg.Namespace = "us-east" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.URL = "http://localhost:9200"
+			tt.modify(cfg)
+			exp, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, exp)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, exp)
+			assert.NoError(t, exp.Shutdown())
+		})
+	}
+}
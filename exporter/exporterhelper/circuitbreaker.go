@@ -0,0 +1,173 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+// ErrCircuitBreakerOpen is returned, without pushing the data, while the
+// circuit breaker of the exporter is open.
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+var errInvalidCircuitBreakerSettings = errors.New(
+	"circuit breaker requires a positive \"min-requests\", \"window\" and \"open-duration\" and a \"failure-ratio\" in (0, 1]")
+
+// CircuitBreakerSettings defines the circuit breaker of an exporter. The
+// circuit opens when, within a window, the ratio of failed requests reaches
+// the failure ratio. While open the requests fail immediately. Once the open
+// duration elapsed the circuit is half-open: a single request is let through
+// to probe the destination, the circuit closes if it succeeds and opens again
+// otherwise. Permanent errors, caused by the data, are not failures.
+type CircuitBreakerSettings struct {
+	// Enabled enables the circuit breaker.
+	Enabled bool `mapstructure:"enabled"`
+	// FailureRatio is the ratio of failed requests opening the circuit.
+	FailureRatio float64 `mapstructure:"failure-ratio"`
+	// MinRequests is the minimum number of requests in the window before the
+	// circuit can open.
+	MinRequests int `mapstructure:"min-requests"`
+	// Window is the duration over which the failure ratio is computed.
+	Window time.Duration `mapstructure:"window"`
+	// OpenDuration is the time the circuit stays open before probing the
+	// destination.
+	OpenDuration time.Duration `mapstructure:"open-duration"`
+}
+
+// NewDefaultCircuitBreakerSettings returns the default settings of the
+// circuit breaker, which is disabled.
+func NewDefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		Enabled:      false,
+		FailureRatio: 0.5,
+		MinRequests:  10,
+		Window:       30 * time.Second,
+		OpenDuration: 30 * time.Second,
+	}
+}
+
+func (s CircuitBreakerSettings) validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.FailureRatio <= 0 || s.FailureRatio > 1 || s.MinRequests <= 0 || s.Window <= 0 || s.OpenDuration <= 0 {
+		return errInvalidCircuitBreakerSettings
+	}
+	return nil
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	// mu protects the fields below
+	mu          sync.Mutex
+	state       circuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+}
+
+func newCircuitBreaker(settings CircuitBreakerSettings) *circuitBreaker {
+	return &circuitBreaker{
+		settings:    settings,
+		now:         time.Now,
+		windowStart: time.Now(),
+	}
+}
+
+// allow reports whether a request can be sent, a request allowed must then be
+// recorded.
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.settings.OpenDuration {
+			return false
+		}
+		cb.state = circuitHalfOpen
+		cb.probing = true
+		return true
+	case circuitHalfOpen:
+		// Only the probe is let through.
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the result of a request allowed by the circuit breaker.
+func (cb *circuitBreaker) record(err error) {
+	failed := err != nil && !consumererror.IsPermanent(err)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	switch cb.state {
+	case circuitHalfOpen:
+		cb.probing = false
+		if failed {
+			cb.open(now)
+		} else {
+			cb.state = circuitClosed
+			cb.resetWindow(now)
+		}
+	case circuitClosed:
+		if now.Sub(cb.windowStart) >= cb.settings.Window {
+			cb.resetWindow(now)
+		}
+		cb.requests++
+		if !failed {
+			return
+		}
+		cb.failures++
+		if cb.requests >= cb.settings.MinRequests &&
+			float64(cb.failures) >= cb.settings.FailureRatio*float64(cb.requests) {
+			cb.open(now)
+		}
+	}
+}
+
+func (cb *circuitBreaker) open(now time.Time) {
+	cb.state = circuitOpen
+	cb.openedAt = now
+}
+
+func (cb *circuitBreaker) resetWindow(now time.Time) {
+	cb.windowStart = now
+	cb.requests = 0
+	cb.failures = 0
+}
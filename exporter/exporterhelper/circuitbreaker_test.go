@@ -0,0 +1,137 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+func newTestCircuitBreaker() (*circuitBreaker, *time.Time) {
+	cb := newCircuitBreaker(CircuitBreakerSettings{
+		Enabled:      true,
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       time.Minute,
+		OpenDuration: 10 * time.Second,
+	})
+	now := time.Unix(1000, 0)
+	cb.now = func() time.Time { return now }
+	cb.windowStart = now
+	return cb, &now
+}
+
+func TestCircuitBreakerSettings_Validate(t *testing.T) {
+	assert.NoError(t, NewDefaultCircuitBreakerSettings().validate())
+	assert.NoError(t, CircuitBreakerSettings{}.validate())
+
+	valid := NewDefaultCircuitBreakerSettings()
+	valid.Enabled = true
+	assert.NoError(t, valid.validate())
+
+	for _, mutate := range []func(*CircuitBreakerSettings){
+		func(s *CircuitBreakerSettings) { s.FailureRatio = 0 },
+		func(s *CircuitBreakerSettings) { s.FailureRatio = 1.5 },
+		func(s *CircuitBreakerSettings) { s.MinRequests = 0 },
+		func(s *CircuitBreakerSettings) { s.Window = 0 },
+		func(s *CircuitBreakerSettings) { s.OpenDuration = 0 },
+	} {
+		s := valid
+		mutate(&s)
+		assert.Equal(t, errInvalidCircuitBreakerSettings, s.validate())
+	}
+}
+
+func TestCircuitBreaker_Opens(t *testing.T) {
+	cb, now := newTestCircuitBreaker()
+	failure := errors.New("unavailable")
+
+	// Below the minimum number of requests the circuit stays closed.
+	for i := 0; i < 3; i++ {
+		assert.True(t, cb.allow())
+		cb.record(failure)
+	}
+	assert.Equal(t, circuitClosed, cb.state)
+
+	// Permanent errors are not failures.
+	assert.True(t, cb.allow())
+	cb.record(consumererror.Permanent(failure))
+	assert.True(t, cb.allow())
+	cb.record(nil)
+	assert.Equal(t, circuitClosed, cb.state)
+
+	// 4 failures out of 6 requests.
+	assert.True(t, cb.allow())
+	cb.record(failure)
+	assert.Equal(t, circuitOpen, cb.state)
+	assert.False(t, cb.allow())
+
+	*now = now.Add(9 * time.Second)
+	assert.False(t, cb.allow())
+}
+
+func TestCircuitBreaker_Window(t *testing.T) {
+	cb, now := newTestCircuitBreaker()
+	failure := errors.New("unavailable")
+
+	for i := 0; i < 3; i++ {
+		cb.record(failure)
+	}
+	// The failures of the previous window are forgotten.
+	*now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		cb.record(failure)
+	}
+	assert.Equal(t, circuitClosed, cb.state)
+	cb.record(failure)
+	assert.Equal(t, circuitOpen, cb.state)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	cb, now := newTestCircuitBreaker()
+	failure := errors.New("unavailable")
+	for i := 0; i < 4; i++ {
+		cb.record(failure)
+	}
+	assert.Equal(t, circuitOpen, cb.state)
+
+	// A failed probe opens the circuit again.
+	*now = now.Add(10 * time.Second)
+	assert.True(t, cb.allow())
+	assert.Equal(t, circuitHalfOpen, cb.state)
+	assert.False(t, cb.allow(), "only the probe is let through")
+	cb.record(failure)
+	assert.Equal(t, circuitOpen, cb.state)
+	assert.False(t, cb.allow())
+
+	// A successful probe closes the circuit.
+	*now = now.Add(10 * time.Second)
+	assert.True(t, cb.allow())
+	cb.record(nil)
+	assert.Equal(t, circuitClosed, cb.state)
+	assert.True(t, cb.allow())
+	assert.True(t, cb.allow())
+
+	// The failures before the circuit opened are forgotten.
+	for i := 0; i < 3; i++ {
+		cb.record(failure)
+	}
+	assert.Equal(t, circuitClosed, cb.state)
+}
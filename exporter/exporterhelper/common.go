@@ -15,6 +15,8 @@
 package exporterhelper
 
 import (
+	"time"
+
 	"go.opencensus.io/trace"
 )
 
@@ -31,9 +33,14 @@ type ExporterOptions struct {
 	// if a request is retried we should not record metrics otherwise number of
 	// spans received + dropped will be different than the number of received spans
 	// in the receiver.
-	recordMetrics bool
-	spanName      string
-	shutdown      Shutdown
+	recordMetrics   bool
+	spanName        string
+	shutdown        Shutdown
+	shutdownTimeout time.Duration
+	circuitBreaker  CircuitBreakerSettings
+	sortSpans       bool
+	rawFormat       string
+	pushRawData     PushRawTraceData
 }
 
 // ExporterOption apply changes to ExporterOptions.
@@ -61,6 +68,42 @@ func WithShutdown(shutdown Shutdown) ExporterOption {
 	}
 }
 
+// WithShutdownTimeout sets the time Shutdown waits for the data being exported
+// before calling the shutdown function, DefaultShutdownTimeout by default. The
+// data consumed after Shutdown is rejected with ErrExporterShutdown.
+func WithShutdownTimeout(timeout time.Duration) ExporterOption {
+	return func(o *ExporterOptions) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithCircuitBreaker makes new Exporter to fail fast, without pushing the
+// data, while the destination keeps failing. See CircuitBreakerSettings.
+func WithCircuitBreaker(settings CircuitBreakerSettings) ExporterOption {
+	return func(o *ExporterOptions) {
+		o.circuitBreaker = settings
+	}
+}
+
+// WithSortedSpans makes new TraceExporter to push the spans of each batch
+// grouped by trace and ordered by start time, see SortSpans. Only supported by
+// trace exporters.
+func WithSortedSpans(sortSpans bool) ExporterOption {
+	return func(o *ExporterOptions) {
+		o.sortSpans = sortSpans
+	}
+}
+
+// WithRawTraceData makes new TraceExporter to also consume raw trace data in the
+// given format, see consumer.RawTraceConsumer. The raw data is pushed with the
+// given function. Only supported by trace exporters.
+func WithRawTraceData(format string, pushRawTraceData PushRawTraceData) ExporterOption {
+	return func(o *ExporterOptions) {
+		o.rawFormat = format
+		o.pushRawData = pushRawTraceData
+	}
+}
+
 // Construct the ExporterOptions from multiple ExporterOption.
 func newExporterOptions(options ...ExporterOption) ExporterOptions {
 	var opts ExporterOptions
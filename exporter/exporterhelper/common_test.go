@@ -49,3 +49,10 @@ func checkRecordMetrics(t *testing.T, opts ExporterOptions, recordMetrics bool)
 func checkSpanName(t *testing.T, opts ExporterOptions, spanName string) {
 	assert.Equalf(t, opts.spanName, spanName, "Wrong spanName Want: %s Got: %s", opts.spanName, spanName)
 }
+
+func TestWithCircuitBreaker(t *testing.T) {
+	assert.Equal(t, CircuitBreakerSettings{}, newExporterOptions().circuitBreaker)
+	settings := NewDefaultCircuitBreakerSettings()
+	settings.Enabled = true
+	assert.Equal(t, settings, newExporterOptions(WithCircuitBreaker(settings)).circuitBreaker)
+}
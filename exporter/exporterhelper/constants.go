@@ -32,4 +32,5 @@ const (
 	numReceivedTimeSeriesAttribute = "num_received_timeseries"
 	numDroppedSpansAttribute       = "num_dropped_spans"
 	numReceivedSpansAttribute      = "num_received_spans"
+	numRawBytesAttribute           = "num_raw_bytes"
 )
@@ -0,0 +1,40 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"strings"
+)
+
+// userAgentHeader is the HTTP header carrying the user agent.
+const userAgentHeader = "User-Agent"
+
+// HeadersWithUserAgent returns the headers with the User-Agent header set to
+// userAgent, replacing any User-Agent entry of the headers regardless of its
+// case. If userAgent is empty the headers are returned unchanged, otherwise a
+// copy is returned.
+func HeadersWithUserAgent(headers map[string]string, userAgent string) map[string]string {
+	if userAgent == "" {
+		return headers
+	}
+	merged := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		if !strings.EqualFold(k, userAgentHeader) {
+			merged[k] = v
+		}
+	}
+	merged[userAgentHeader] = userAgent
+	return merged
+}
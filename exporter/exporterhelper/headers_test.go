@@ -0,0 +1,37 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadersWithUserAgent(t *testing.T) {
+	headers := map[string]string{"user-agent": "library/1.0", "X-Scope": "tenant"}
+
+	assert.Equal(t, headers, HeadersWithUserAgent(headers, ""))
+	assert.Nil(t, HeadersWithUserAgent(nil, ""))
+
+	assert.Equal(t,
+		map[string]string{"User-Agent": "custom/2.0", "X-Scope": "tenant"},
+		HeadersWithUserAgent(headers, "custom/2.0"))
+	assert.Equal(t,
+		map[string]string{"User-Agent": "custom/2.0"},
+		HeadersWithUserAgent(nil, "custom/2.0"))
+	// The original headers are not modified.
+	assert.Equal(t, "library/1.0", headers["user-agent"])
+}
@@ -32,6 +32,7 @@ type metricsExporter struct {
 	exporterName    string
 	pushMetricsData PushMetricsData
 	shutdown        Shutdown
+	guard           *ShutdownGuard
 }
 
 var _ (exporter.MetricsExporter) = (*metricsExporter)(nil)
@@ -41,14 +42,19 @@ func (me *metricsExporter) Name() string {
 }
 
 func (me *metricsExporter) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	if !me.guard.Enter() {
+		return ErrExporterShutdown
+	}
+	defer me.guard.Exit()
 	exporterCtx := observability.ContextWithExporterName(ctx, me.exporterName)
 	_, err := me.pushMetricsData(exporterCtx, md)
 	return err
 }
 
-// Shutdown stops the exporter and is invoked during shutdown.
+// Shutdown stops the exporter and is invoked during shutdown. It waits for the
+// data being exported, it is safe to call concurrently and more than once.
 func (me *metricsExporter) Shutdown() error {
-	return me.shutdown()
+	return me.guard.Shutdown(me.shutdown)
 }
 
 // NewMetricsExporter creates an MetricsExporter that can record metrics and can wrap every request with a Span.
@@ -64,6 +70,13 @@ func NewMetricsExporter(exporterName string, pushMetricsData PushMetricsData, op
 	}
 
 	opts := newExporterOptions(options...)
+	if err := opts.circuitBreaker.validate(); err != nil {
+		return nil, err
+	}
+	if opts.circuitBreaker.Enabled {
+		pushMetricsData = pushMetricsDataWithCircuitBreaker(pushMetricsData, newCircuitBreaker(opts.circuitBreaker))
+	}
+
 	if opts.recordMetrics {
 		pushMetricsData = pushMetricsDataWithMetrics(pushMetricsData)
 	}
@@ -81,6 +94,7 @@ func NewMetricsExporter(exporterName string, pushMetricsData PushMetricsData, op
 		exporterName:    exporterName,
 		pushMetricsData: pushMetricsData,
 		shutdown:        opts.shutdown,
+		guard:           &ShutdownGuard{Timeout: opts.shutdownTimeout},
 	}, nil
 }
 
@@ -122,3 +136,14 @@ func NumTimeSeries(md consumerdata.MetricsData) int {
 	}
 	return receivedTimeSeries
 }
+
+func pushMetricsDataWithCircuitBreaker(next PushMetricsData, cb *circuitBreaker) PushMetricsData {
+	return func(ctx context.Context, md consumerdata.MetricsData) (int, error) {
+		if !cb.allow() {
+			return NumTimeSeries(md), ErrCircuitBreakerOpen
+		}
+		droppedTimeSeries, err := next(ctx, md)
+		cb.record(err)
+		return droppedTimeSeries, err
+	}
+}
@@ -209,3 +209,23 @@ func checkWrapSpanForMetricsExporter(t *testing.T, me exporter.MetricsExporter,
 		require.Equalf(t, int64(droppedSpans), sd.Attributes[numDroppedTimeSeriesAttribute], "SpanData %v", sd)
 	}
 }
+
+func TestMetricsExporter_WithCircuitBreaker(t *testing.T) {
+	settings := NewDefaultCircuitBreakerSettings()
+	settings.Enabled = true
+	settings.MinRequests = 1
+
+	want := errors.New("my_error")
+	me, err := NewMetricsExporter(fakeMetricsExporterName, newPushMetricsData(0, want), WithCircuitBreaker(settings))
+	require.Nil(t, err)
+	require.NotNil(t, me)
+
+	md := consumerdata.MetricsData{}
+	assert.Equal(t, want, me.ConsumeMetricsData(context.Background(), md))
+	assert.Equal(t, ErrCircuitBreakerOpen, me.ConsumeMetricsData(context.Background(), md))
+
+	settings.Window = 0
+	me, err = NewMetricsExporter(fakeMetricsExporterName, newPushMetricsData(0, nil), WithCircuitBreaker(settings))
+	assert.Nil(t, me)
+	assert.Equal(t, errInvalidCircuitBreakerSettings, err)
+}
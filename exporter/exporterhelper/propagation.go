@@ -0,0 +1,56 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"net/http"
+
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcTraceBinHeader is the gRPC metadata carrying the binary trace context
+// of OpenCensus.
+const grpcTraceBinHeader = "grpc-trace-bin"
+
+// InjectHTTPTraceContext sets the trace context of the span of ctx, e.g. the
+// span of the exporter created with WithSpanName, on the headers of the
+// request in the W3C Trace Context format, so that the requests to the
+// backend are traced as children of the exporter span. Nothing is set if ctx
+// has no span.
+func InjectHTTPTraceContext(ctx context.Context, req *http.Request) {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return
+	}
+	(&tracecontext.HTTPFormat{}).SpanContextToRequest(span.SpanContext(), req)
+}
+
+// AppendGRPCTraceContext returns the metadata with the trace context of the
+// span of ctx in the grpc-trace-bin entry used by OpenCensus, see
+// InjectHTTPTraceContext. The metadata is returned unchanged if ctx has no
+// span, otherwise a copy is returned.
+func AppendGRPCTraceContext(ctx context.Context, md metadata.MD) metadata.MD {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return md
+	}
+	md = md.Copy()
+	md.Set(grpcTraceBinHeader, string(propagation.Binary(span.SpanContext())))
+	return md
+}
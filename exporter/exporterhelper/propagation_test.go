@@ -0,0 +1,58 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestInjectHTTPTraceContext(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/api/traces", nil)
+	require.NoError(t, err)
+	InjectHTTPTraceContext(context.Background(), req)
+	assert.Empty(t, req.Header)
+
+	ctx, span := trace.StartSpan(context.Background(), fakeSpanName, trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+	InjectHTTPTraceContext(ctx, req)
+	got, ok := (&tracecontext.HTTPFormat{}).SpanContextFromRequest(req)
+	require.True(t, ok)
+	assert.Equal(t, span.SpanContext(), got)
+}
+
+func TestAppendGRPCTraceContext(t *testing.T) {
+	md := metadata.Pairs("x-scope-orgid", "tenant")
+	assert.Equal(t, md, AppendGRPCTraceContext(context.Background(), md))
+
+	ctx, span := trace.StartSpan(context.Background(), fakeSpanName, trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+	got := AppendGRPCTraceContext(ctx, md)
+	assert.Equal(t, []string{"tenant"}, got.Get("x-scope-orgid"))
+	require.Len(t, got.Get(grpcTraceBinHeader), 1)
+	sc, ok := propagation.FromBinary([]byte(got.Get(grpcTraceBinHeader)[0]))
+	require.True(t, ok)
+	assert.Equal(t, span.SpanContext(), sc)
+	// The metadata shared by the requests is not modified.
+	assert.Empty(t, md.Get(grpcTraceBinHeader))
+}
@@ -0,0 +1,115 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+// DefaultShutdownTimeout is the time Shutdown waits for the data being
+// exported before stopping the exporter anyway.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// ErrExporterShutdown is returned, without pushing the data, once the exporter
+// is shut down. It is a permanent error, see consumererror.Permanent.
+var ErrExporterShutdown = consumererror.Permanent(errors.New("exporter is shut down"))
+
+// ShutdownGuard makes the shutdown of an exporter safe to call concurrently
+// with the calls consuming data: Shutdown rejects the new calls, waits for the
+// calls in flight, bounded by a timeout, and only then stops the exporter so
+// that the data being exported is flushed. The zero value waits for
+// DefaultShutdownTimeout.
+type ShutdownGuard struct {
+	// Timeout is the time Shutdown waits for the calls in flight, zero for
+	// DefaultShutdownTimeout.
+	Timeout time.Duration
+
+	mu       sync.RWMutex
+	stopped  bool
+	inFlight sync.WaitGroup
+
+	once sync.Once
+	err  error
+}
+
+// Enter registers a call consuming data, it returns false if the exporter is
+// shut down and the data must not be pushed. Exit must be called once the call
+// returns if Enter returned true.
+func (g *ShutdownGuard) Enter() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.stopped {
+		return false
+	}
+	g.inFlight.Add(1)
+	return true
+}
+
+// Exit unregisters a call registered by Enter.
+func (g *ShutdownGuard) Exit() {
+	g.inFlight.Done()
+}
+
+// Shutdown rejects the new calls, waits for the calls in flight and then calls
+// shutdown. The calls in flight after the timeout are reported as an error,
+// shutdown is called anyway. Only the first call shuts down the exporter, the
+// others wait for it and return the same error.
+func (g *ShutdownGuard) Shutdown(shutdown Shutdown) error {
+	g.once.Do(func() {
+		g.mu.Lock()
+		g.stopped = true
+		g.mu.Unlock()
+
+		timeout := g.Timeout
+		if timeout <= 0 {
+			timeout = DefaultShutdownTimeout
+		}
+		var errs []error
+		if !WaitTimeout(&g.inFlight, timeout) {
+			errs = append(errs, fmt.Errorf("data still being exported after %v, shutting down anyway", timeout))
+		}
+		if shutdown != nil {
+			if err := shutdown(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		g.err = oterr.CombineErrors(errs)
+	})
+	return g.err
+}
+
+// WaitTimeout waits for wg up to timeout, it returns false if the timeout
+// elapsed first.
+func WaitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
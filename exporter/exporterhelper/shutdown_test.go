@@ -0,0 +1,132 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+func TestShutdownGuard_WaitsForInFlight(t *testing.T) {
+	g := &ShutdownGuard{Timeout: time.Minute}
+	require.True(t, g.Enter())
+
+	var shutdownCalled int32
+	done := make(chan error)
+	go func() {
+		done <- g.Shutdown(func() error {
+			atomic.StoreInt32(&shutdownCalled, 1)
+			return nil
+		})
+	}()
+
+	// The new calls are rejected while waiting for the call in flight.
+	for g.Enter() {
+		g.Exit()
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&shutdownCalled))
+
+	g.Exit()
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&shutdownCalled))
+}
+
+func TestShutdownGuard_Timeout(t *testing.T) {
+	g := &ShutdownGuard{Timeout: 10 * time.Millisecond}
+	require.True(t, g.Enter())
+	defer g.Exit()
+
+	shutdownCalled := false
+	err := g.Shutdown(func() error { shutdownCalled = true; return nil })
+	assert.Error(t, err)
+	assert.True(t, shutdownCalled)
+}
+
+func TestShutdownGuard_Once(t *testing.T) {
+	g := &ShutdownGuard{}
+	var calls int32
+	shutdown := func() error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, g.Shutdown(shutdown))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestTraceExporter_ShutdownConcurrentWithConsume(t *testing.T) {
+	var pushed, stopped int32
+	push := func(ctx context.Context, td consumerdata.TraceData) (int, error) {
+		time.Sleep(time.Millisecond)
+		// The data is never pushed once the exporter is stopped.
+		assert.Equal(t, int32(0), atomic.LoadInt32(&stopped))
+		atomic.AddInt32(&pushed, 1)
+		return 0, nil
+	}
+	shutdown := func() error {
+		atomic.StoreInt32(&stopped, 1)
+		return nil
+	}
+	te, err := NewTraceExporter(fakeTraceExporterName, push, WithShutdown(shutdown), WithShutdownTimeout(time.Minute))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var rejected int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := te.ConsumeTraceData(context.Background(), consumerdata.TraceData{}); err != nil {
+					assert.Equal(t, ErrExporterShutdown, err)
+					assert.True(t, consumererror.IsPermanent(err))
+					atomic.AddInt32(&rejected, 1)
+				}
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	assert.NoError(t, te.Shutdown())
+	wg.Wait()
+
+	assert.Equal(t, int32(200), atomic.LoadInt32(&pushed)+atomic.LoadInt32(&rejected))
+	assert.NoError(t, te.Shutdown())
+}
+
+func TestMetricsExporter_ConsumeAfterShutdown(t *testing.T) {
+	me, err := NewMetricsExporter(fakeMetricsExporterName, newPushMetricsData(0, nil))
+	require.NoError(t, err)
+	require.NoError(t, me.Shutdown())
+
+	assert.Equal(t, ErrExporterShutdown, me.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{}))
+}
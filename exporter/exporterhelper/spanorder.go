@@ -0,0 +1,71 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// SortSpans returns the spans grouped by trace, the traces in the order of
+// their earliest span and the spans of each trace by start time. The spans
+// without start time come first. The spans are sorted in a new slice, the
+// given one is left unchanged since it may be shared with other consumers.
+func SortSpans(spans []*tracepb.Span) []*tracepb.Span {
+	sorted := make([]*tracepb.Span, len(spans))
+	copy(sorted, spans)
+
+	traceStart := make(map[string]time.Time)
+	for _, span := range sorted {
+		start := spanStart(span)
+		if earliest, ok := traceStart[string(span.GetTraceId())]; !ok || start.Before(earliest) {
+			traceStart[string(span.GetTraceId())] = start
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].GetTraceId(), sorted[j].GetTraceId()
+		if !bytes.Equal(ti, tj) {
+			si, sj := traceStart[string(ti)], traceStart[string(tj)]
+			if !si.Equal(sj) {
+				return si.Before(sj)
+			}
+			return bytes.Compare(ti, tj) < 0
+		}
+		return spanStart(sorted[i]).Before(spanStart(sorted[j]))
+	})
+	return sorted
+}
+
+func spanStart(span *tracepb.Span) time.Time {
+	ts := span.GetStartTime()
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos))
+}
+
+func pushTraceDataWithSortedSpans(next PushTraceData) PushTraceData {
+	return func(ctx context.Context, td consumerdata.TraceData) (int, error) {
+		td.Spans = SortSpans(td.Spans)
+		return next(ctx, td)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+func testSpan(traceID byte, name string, start int64) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId: []byte{traceID},
+		Name:    &tracepb.TruncatableString{Value: name},
+	}
+	if start > 0 {
+		span.StartTime = &timestamp.Timestamp{Seconds: start}
+	}
+	return span
+}
+
+func spanNames(spans []*tracepb.Span) []string {
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name.Value)
+	}
+	return names
+}
+
+func TestSortSpans(t *testing.T) {
+	spans := []*tracepb.Span{
+		testSpan(2, "b2", 30),
+		testSpan(1, "a2", 20),
+		testSpan(2, "b1", 10),
+		testSpan(3, "c1", 10),
+		testSpan(1, "a1", 15),
+		testSpan(1, "a0", 0),
+		testSpan(3, "c2", 10),
+	}
+	sorted := SortSpans(spans)
+
+	// The traces are ordered by their earliest span, the spans without start
+	// time first, and by ID when they start at the same time.
+	assert.Equal(t, []string{"a0", "a1", "a2", "b1", "b2", "c1", "c2"}, spanNames(sorted))
+	// The given slice is unchanged.
+	assert.Equal(t, []string{"b2", "a2", "b1", "c1", "a1", "a0", "c2"}, spanNames(spans))
+
+	assert.Empty(t, SortSpans(nil))
+}
+
+func TestTraceExporter_WithSortedSpans(t *testing.T) {
+	var pushed []*tracepb.Span
+	push := func(ctx context.Context, td consumerdata.TraceData) (int, error) {
+		pushed = td.Spans
+		return 0, nil
+	}
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{
+		testSpan(1, "a2", 20),
+		testSpan(1, "a1", 10),
+	}}
+
+	te, err := NewTraceExporter(fakeTraceExporterName, push)
+	require.NoError(t, err)
+	require.NoError(t, te.ConsumeTraceData(context.Background(), td))
+	assert.Equal(t, []string{"a2", "a1"}, spanNames(pushed))
+
+	te, err = NewTraceExporter(fakeTraceExporterName, push, WithSortedSpans(true))
+	require.NoError(t, err)
+	require.NoError(t, te.ConsumeTraceData(context.Background(), td))
+	assert.Equal(t, []string{"a1", "a2"}, spanNames(pushed))
+	assert.Equal(t, []string{"a2", "a1"}, spanNames(td.Spans))
+}
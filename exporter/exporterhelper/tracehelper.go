@@ -16,10 +16,13 @@ package exporterhelper
 
 import (
 	"context"
+	"fmt"
 
 	"go.opencensus.io/trace"
 
+	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 )
@@ -28,27 +31,56 @@ import (
 // the number of dropped spans.
 type PushTraceData func(ctx context.Context, td consumerdata.TraceData) (droppedSpans int, err error)
 
+// PushRawTraceData is a helper function that is similar to ConsumeRawTraceData, the
+// number of spans of raw trace data is unknown.
+type PushRawTraceData func(ctx context.Context, rtd consumerdata.RawTraceData) error
+
 type traceExporter struct {
-	exporterName  string
-	pushTraceData PushTraceData
-	shutdown      Shutdown
+	exporterName     string
+	pushTraceData    PushTraceData
+	rawFormat        string
+	pushRawTraceData PushRawTraceData
+	shutdown         Shutdown
+	guard            *ShutdownGuard
 }
 
 var _ (exporter.TraceExporter) = (*traceExporter)(nil)
+var _ (consumer.RawTraceConsumer) = (*traceExporter)(nil)
 
 func (te *traceExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	if !te.guard.Enter() {
+		return ErrExporterShutdown
+	}
+	defer te.guard.Exit()
 	exporterCtx := observability.ContextWithExporterName(ctx, te.exporterName)
 	_, err := te.pushTraceData(exporterCtx, td)
 	return err
 }
 
+func (te *traceExporter) AcceptsRawTraceFormat(format string) bool {
+	return te.pushRawTraceData != nil && format == te.rawFormat
+}
+
+func (te *traceExporter) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error {
+	if !te.AcceptsRawTraceFormat(rtd.Format) {
+		return consumererror.Permanent(fmt.Errorf("raw trace data format %q is not supported", rtd.Format))
+	}
+	if !te.guard.Enter() {
+		return ErrExporterShutdown
+	}
+	defer te.guard.Exit()
+	exporterCtx := observability.ContextWithExporterName(ctx, te.exporterName)
+	return te.pushRawTraceData(exporterCtx, rtd)
+}
+
 func (te *traceExporter) Name() string {
 	return te.exporterName
 }
 
-// Shutdown stops the exporter and is invoked during shutdown.
+// Shutdown stops the exporter and is invoked during shutdown. It waits for the
+// data being exported, it is safe to call concurrently and more than once.
 func (te *traceExporter) Shutdown() error {
-	return te.shutdown()
+	return te.guard.Shutdown(te.shutdown)
 }
 
 // NewTraceExporter creates an TraceExporter that can record metrics and can wrap every request with a Span.
@@ -64,12 +96,33 @@ func NewTraceExporter(exporterName string, pushTraceData PushTraceData, options
 	}
 
 	opts := newExporterOptions(options...)
+	if err := opts.circuitBreaker.validate(); err != nil {
+		return nil, err
+	}
+	if opts.sortSpans {
+		pushTraceData = pushTraceDataWithSortedSpans(pushTraceData)
+	}
+
+	pushRawTraceData := opts.pushRawData
+	if opts.circuitBreaker.Enabled {
+		// The raw trace data goes to the same destination, it shares the
+		// circuit breaker.
+		cb := newCircuitBreaker(opts.circuitBreaker)
+		pushTraceData = pushTraceDataWithCircuitBreaker(pushTraceData, cb)
+		if pushRawTraceData != nil {
+			pushRawTraceData = pushRawTraceDataWithCircuitBreaker(pushRawTraceData, cb)
+		}
+	}
+
 	if opts.recordMetrics {
 		pushTraceData = pushTraceDataWithMetrics(pushTraceData)
 	}
 
 	if opts.spanName != "" {
 		pushTraceData = pushTraceDataWithSpan(pushTraceData, opts.spanName)
+		if pushRawTraceData != nil {
+			pushRawTraceData = pushRawTraceDataWithSpan(pushRawTraceData, opts.spanName)
+		}
 	}
 
 	// The default shutdown function returns nil.
@@ -80,9 +133,12 @@ func NewTraceExporter(exporterName string, pushTraceData PushTraceData, options
 	}
 
 	return &traceExporter{
-		exporterName:  exporterName,
-		pushTraceData: pushTraceData,
-		shutdown:      opts.shutdown,
+		exporterName:     exporterName,
+		pushTraceData:    pushTraceData,
+		rawFormat:        opts.rawFormat,
+		pushRawTraceData: pushRawTraceData,
+		shutdown:         opts.shutdown,
+		guard:            &ShutdownGuard{Timeout: opts.shutdownTimeout},
 	}, nil
 }
 
@@ -114,3 +170,41 @@ func pushTraceDataWithSpan(next PushTraceData, spanName string) PushTraceData {
 		return droppedSpans, err
 	}
 }
+
+func pushTraceDataWithCircuitBreaker(next PushTraceData, cb *circuitBreaker) PushTraceData {
+	return func(ctx context.Context, td consumerdata.TraceData) (int, error) {
+		if !cb.allow() {
+			return len(td.Spans), ErrCircuitBreakerOpen
+		}
+		droppedSpans, err := next(ctx, td)
+		cb.record(err)
+		return droppedSpans, err
+	}
+}
+
+func pushRawTraceDataWithSpan(next PushRawTraceData, spanName string) PushRawTraceData {
+	return func(ctx context.Context, rtd consumerdata.RawTraceData) error {
+		ctx, span := trace.StartSpan(ctx, spanName)
+		defer span.End()
+		// Call next stage.
+		err := next(ctx, rtd)
+		if span.IsRecordingEvents() {
+			span.AddAttributes(trace.Int64Attribute(numRawBytesAttribute, int64(len(rtd.Payload))))
+			if err != nil {
+				span.SetStatus(errToStatus(err))
+			}
+		}
+		return err
+	}
+}
+
+func pushRawTraceDataWithCircuitBreaker(next PushRawTraceData, cb *circuitBreaker) PushRawTraceData {
+	return func(ctx context.Context, rtd consumerdata.RawTraceData) error {
+		if !cb.allow() {
+			return ErrCircuitBreakerOpen
+		}
+		err := next(ctx, rtd)
+		cb.record(err)
+		return err
+	}
+}
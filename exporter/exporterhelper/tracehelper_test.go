@@ -24,7 +24,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/trace"
 
+	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
@@ -219,3 +221,74 @@ func (tote *testOCTraceExporter) ExportSpan(sd *trace.SpanData) {
 
 	tote.spanData = append(tote.spanData, sd)
 }
+
+func TestTraceExporter_WithCircuitBreaker(t *testing.T) {
+	settings := NewDefaultCircuitBreakerSettings()
+	settings.Enabled = true
+	settings.MinRequests = 1
+
+	want := errors.New("my_error")
+	te, err := NewTraceExporter(fakeTraceExporterName, newPushTraceData(0, want), WithCircuitBreaker(settings))
+	require.Nil(t, err)
+	require.NotNil(t, te)
+
+	td := consumerdata.TraceData{Spans: make([]*tracepb.Span, 3)}
+	assert.Equal(t, want, te.ConsumeTraceData(context.Background(), td))
+	assert.Equal(t, ErrCircuitBreakerOpen, te.ConsumeTraceData(context.Background(), td))
+
+	settings.FailureRatio = 0
+	te, err = NewTraceExporter(fakeTraceExporterName, newPushTraceData(0, nil), WithCircuitBreaker(settings))
+	assert.Nil(t, te)
+	assert.Equal(t, errInvalidCircuitBreakerSettings, err)
+}
+
+func TestTraceExporter_WithRawTraceData(t *testing.T) {
+	var got []consumerdata.RawTraceData
+	pushRaw := func(ctx context.Context, rtd consumerdata.RawTraceData) error {
+		got = append(got, rtd)
+		return nil
+	}
+	te, err := NewTraceExporter(fakeTraceExporterName, newPushTraceData(0, nil), WithRawTraceData("raw", pushRaw))
+	require.Nil(t, err)
+	rte, ok := te.(consumer.RawTraceConsumer)
+	require.True(t, ok)
+
+	assert.True(t, rte.AcceptsRawTraceFormat("raw"))
+	assert.False(t, rte.AcceptsRawTraceFormat("other"))
+
+	rtd := consumerdata.RawTraceData{Format: "raw", Payload: []byte{1, 2, 3}}
+	require.Nil(t, rte.ConsumeRawTraceData(context.Background(), rtd))
+	assert.Equal(t, []consumerdata.RawTraceData{rtd}, got)
+
+	err = rte.ConsumeRawTraceData(context.Background(), consumerdata.RawTraceData{Format: "other"})
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Len(t, got, 1)
+
+	// Without the option no raw format is accepted.
+	te, err = NewTraceExporter(fakeTraceExporterName, newPushTraceData(0, nil))
+	require.Nil(t, err)
+	assert.False(t, te.(consumer.RawTraceConsumer).AcceptsRawTraceFormat("raw"))
+}
+
+func TestTraceExporter_WithRawTraceData_CircuitBreaker(t *testing.T) {
+	settings := NewDefaultCircuitBreakerSettings()
+	settings.Enabled = true
+	settings.MinRequests = 1
+
+	want := errors.New("my_error")
+	pushRaw := func(ctx context.Context, rtd consumerdata.RawTraceData) error {
+		return want
+	}
+	te, err := NewTraceExporter(
+		fakeTraceExporterName,
+		newPushTraceData(0, nil),
+		WithRawTraceData("raw", pushRaw),
+		WithCircuitBreaker(settings))
+	require.Nil(t, err)
+
+	rtd := consumerdata.RawTraceData{Format: "raw", Payload: []byte{1, 2, 3}}
+	assert.Equal(t, want, te.(consumer.RawTraceConsumer).ConsumeRawTraceData(context.Background(), rtd))
+	// The circuit opened by the raw data is shared with the trace data.
+	td := consumerdata.TraceData{Spans: make([]*tracepb.Span, 3)}
+	assert.Equal(t, ErrCircuitBreakerOpen, te.ConsumeTraceData(context.Background(), td))
+}
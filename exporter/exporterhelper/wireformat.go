@@ -0,0 +1,81 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WireFormats are the versions of the wire format of a protocol that the
+// exporters of the protocol can send. The format is pinned by the "format"
+// setting of the exporters, the default is used if it is empty.
+//
+// The default of a protocol must not change once released so that upgrading
+// the collector doesn't change the format sent with the existing
+// configurations, the new versions are added as opt-in formats.
+type WireFormats struct {
+	// Protocol names the protocol in the errors.
+	Protocol string
+
+	// Default is the format sent when none is configured.
+	Default string
+
+	// Supported are all the formats the exporters can send, including the
+	// default.
+	Supported []string
+}
+
+// The wire formats of the protocols of the exporters.
+var (
+	// ZipkinWireFormats are the formats of the Zipkin exporter, "v2-json"
+	// for the Zipkin v2 API, "v1-json" and "v1-thrift" for the Zipkin v1 API.
+	ZipkinWireFormats = WireFormats{
+		Protocol:  "zipkin",
+		Default:   "v2-json",
+		Supported: []string{"v2-json", "v1-json", "v1-thrift"},
+	}
+
+	// JaegerGRPCWireFormats are the formats of the Jaeger gRPC exporter, the
+	// jaeger-proto batches of the api_v2 CollectorService.
+	JaegerGRPCWireFormats = WireFormats{
+		Protocol:  "jaeger-grpc",
+		Default:   "proto",
+		Supported: []string{"proto"},
+	}
+
+	// JaegerThriftHTTPWireFormats are the formats of the Jaeger Thrift HTTP
+	// exporter, the Thrift binary batches of the /api/traces endpoint.
+	JaegerThriftHTTPWireFormats = WireFormats{
+		Protocol:  "jaeger-thrift-http",
+		Default:   "thrift",
+		Supported: []string{"thrift"},
+	}
+)
+
+// Resolve returns the format sent for the configured format, the default if
+// format is empty. It returns an error if the format is not supported.
+func (wf WireFormats) Resolve(format string) (string, error) {
+	if format == "" {
+		return wf.Default, nil
+	}
+	for _, supported := range wf.Supported {
+		if format == supported {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported %s format %q, must be one of: %s",
+		wf.Protocol, format, strings.Join(wf.Supported, ", "))
+}
@@ -0,0 +1,46 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireFormatsResolve(t *testing.T) {
+	format, err := ZipkinWireFormats.Resolve("")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-json", format)
+
+	format, err = ZipkinWireFormats.Resolve("v1-thrift")
+	require.NoError(t, err)
+	assert.Equal(t, "v1-thrift", format)
+
+	_, err = ZipkinWireFormats.Resolve("v3-json")
+	assert.EqualError(t, err, `unsupported zipkin format "v3-json", must be one of: v2-json, v1-json, v1-thrift`)
+}
+
+func TestWireFormatsDefaults(t *testing.T) {
+	// The defaults are pinned, changing them would change the format sent
+	// with the existing configurations.
+	for _, wf := range []WireFormats{ZipkinWireFormats, JaegerGRPCWireFormats, JaegerThriftHTTPWireFormats} {
+		assert.Contains(t, wf.Supported, wf.Default, wf.Protocol)
+	}
+	assert.Equal(t, "v2-json", ZipkinWireFormats.Default)
+	assert.Equal(t, "proto", JaegerGRPCWireFormats.Default)
+	assert.Equal(t, "thrift", JaegerThriftHTTPWireFormats.Default)
+}
@@ -17,6 +17,7 @@ package exporter
 import (
 	"fmt"
 
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
@@ -37,6 +38,47 @@ type Factory interface {
 	CreateMetricsExporter(logger *zap.Logger, cfg configmodels.Exporter) (MetricsExporter, error)
 }
 
+// CustomUnmarshalerFactory is implemented by the factories whose configuration
+// requires custom unmarshaling. This is typically used if viper.Unmarshal() is not
+// sufficient to unmarshal correctly, e.g. for polymorphic fields.
+type CustomUnmarshalerFactory interface {
+	// CustomUnmarshaler returns a custom unmarshaler for the configuration or nil if
+	// there is no need for custom unmarshaling.
+	CustomUnmarshaler() CustomUnmarshaler
+}
+
+// CustomUnmarshaler is a function that un-marshals a viper data into a config struct
+// in a custom way.
+type CustomUnmarshaler func(v *viper.Viper, viperKey string, intoCfg interface{}) error
+
+// WrapperFactory is implemented by the factories of exporters that wrap other
+// exporters of the configuration, e.g. to duplicate the data sent to them. The
+// wrapped exporters are built before the wrapper and must support the data
+// types of the pipelines of the wrapper. Wrapper exporters can't be wrapped.
+type WrapperFactory interface {
+	Factory
+
+	// WrappedExporters returns the full names of the exporters wrapped by the
+	// exporter created with this config.
+	WrappedExporters(cfg configmodels.Exporter) []string
+
+	// CreateWrapperTraceExporter creates a trace exporter based on this config,
+	// wrapping the given trace exporters keyed by their full names.
+	CreateWrapperTraceExporter(
+		logger *zap.Logger,
+		cfg configmodels.Exporter,
+		wrapped map[string]TraceExporter,
+	) (TraceExporter, error)
+
+	// CreateWrapperMetricsExporter creates a metrics exporter based on this
+	// config, wrapping the given metrics exporters keyed by their full names.
+	CreateWrapperMetricsExporter(
+		logger *zap.Logger,
+		cfg configmodels.Exporter,
+		wrapped map[string]MetricsExporter,
+	) (MetricsExporter, error)
+}
+
 // Build takes a list of exporter factories and returns a map of type map[string]Factory
 // with factory type as keys. It returns a non-nil error when more than one factories
 // have the same type.
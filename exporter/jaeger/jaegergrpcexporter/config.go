@@ -16,10 +16,40 @@ package jaegergrpcexporter
 
 import (
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 // Config defines configuration for Jaeger gRPC exporter.
 type Config struct {
 	configmodels.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
 	Endpoint                      string                   `mapstructure:"endpoint"`
+
+	// Headers are sent as gRPC metadata with each request.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// UserAgent, if not empty, is prepended to the gRPC user agent.
+	UserAgent string `mapstructure:"user-agent"`
+
+	// TagMapping controls which node attributes and resource labels become
+	// process tags or span tags.
+	TagMapping jaegertranslator.TagMapping `mapstructure:"tag-mapping"`
+
+	// AttributeIndexing selects the span attributes sent as span tags, the
+	// payload attributes are sent as a span log instead.
+	AttributeIndexing attributeindex.Settings `mapstructure:"attribute-indexing"`
+
+	// Format is the format of the spans sent, "proto" for the jaeger-proto batches of the api_v2
+	// CollectorService. The default is
+	// pinned, the new versions of the format are opt-in.
+	Format string `mapstructure:"format"`
+
+	// CircuitBreaker makes the exporter fail fast, without sending the data,
+	// while the destination keeps failing.
+	CircuitBreaker exporterhelper.CircuitBreakerSettings `mapstructure:"circuit-breaker"`
+
+	// PropagateTraceContext sends the trace context of the exporter span
+	// with the requests, so that the backends can trace them as its children.
+	PropagateTraceContext bool `mapstructure:"propagate-trace-context"`
 }
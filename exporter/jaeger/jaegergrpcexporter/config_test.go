@@ -17,12 +17,16 @@ package jaegergrpcexporter
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -46,6 +50,28 @@ func TestLoadConfig(t *testing.T) {
 	e1 := cfg.Exporters["jaeger-grpc/2"]
 	assert.Equal(t, "jaeger-grpc/2", e1.(*Config).Name())
 	assert.Equal(t, "a.new.target:1234", e1.(*Config).Endpoint)
+	assert.Equal(t, map[string]string{"x-scope-orgid": "tenant"}, e1.(*Config).Headers)
+	assert.Equal(t, "custom-agent/1.0", e1.(*Config).UserAgent)
+	assert.Equal(t, "proto", e1.(*Config).Format)
+	assert.Equal(t,
+		jaegertranslator.TagMapping{
+			IncludeResourceLabels: true,
+			SpanTags:              []string{"k8s.pod.name"},
+			Rename:                map[string]string{"k8s.pod.name": "pod"},
+		},
+		e1.(*Config).TagMapping)
+	assert.Equal(t,
+		attributeindex.Settings{Payload: []string{"http.request.body", "db.statement"}},
+		e1.(*Config).AttributeIndexing)
+	assert.Equal(t,
+		exporterhelper.CircuitBreakerSettings{
+			Enabled:      true,
+			FailureRatio: 0.8,
+			MinRequests:  20,
+			Window:       time.Minute,
+			OpenDuration: 10 * time.Second,
+		},
+		e1.(*Config).CircuitBreaker)
 	_, err = factory.CreateTraceExporter(zap.NewNop(), e1)
 	require.NoError(t, err)
 }
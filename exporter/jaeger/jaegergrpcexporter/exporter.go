@@ -19,33 +19,71 @@ import (
 
 	jaegerproto "github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	"github.com/open-telemetry/opentelemetry-service/internal/jaegerrelay"
 	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 // New returns a new Jaeger gRPC exporter.
 // The exporter name is the name to be used in the observability of the exporter.
 // The collectorEndpoint should be of the form "hostname:14250" (a gRPC target).
-func New(exporterName, collectorEndpoint string) (exporter.TraceExporter, error) {
-	client, err := grpc.Dial(collectorEndpoint, grpc.WithInsecure())
+// The headers are sent as gRPC metadata with each request.
+// The userAgent, if not empty, is prepended to the gRPC user agent.
+// The tagMapping is applied to the trace data before it is translated.
+// The attributeIndexing selects the span attributes sent as span tags, the
+// payload attributes are sent as a span log at the start of the span.
+// If both are the defaults the exporter also relays, without decoding them, the
+// requests received with the Jaeger gRPC protocol, see consumer.RawTraceConsumer.
+// The circuitBreaker defines the circuit breaker of the exporter.
+// The propagateTraceContext parameter sends the trace context of the exporter
+// span with the requests.
+func New(
+	exporterName string,
+	collectorEndpoint string,
+	headers map[string]string,
+	userAgent string,
+	tagMapping jaegertranslator.TagMapping,
+	attributeIndexing attributeindex.Settings,
+	circuitBreaker exporterhelper.CircuitBreakerSettings,
+	propagateTraceContext bool,
+) (exporter.TraceExporter, error) {
+
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if userAgent != "" {
+		dialOpts = append(dialOpts, grpc.WithUserAgent(userAgent))
+	}
+	client, err := grpc.Dial(collectorEndpoint, dialOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	collectorServiceClient := jaegerproto.NewCollectorServiceClient(client)
 	s := &protoGRPCSender{
-		client: collectorServiceClient,
+		conn:       client,
+		client:     collectorServiceClient,
+		metadata:   metadata.New(headers),
+		tagMapping: tagMapping,
+		indexing:   attributeindex.NewClassifier(attributeIndexing),
+		propagate:  propagateTraceContext,
 	}
 
-	exp, err := exporterhelper.NewTraceExporter(
-		exporterName,
-		s.pushTraceData,
-		exporterhelper.WithSpanName("otelsvc.exporter."+exporterName+".ConsumeTraceData"),
-		exporterhelper.WithRecordMetrics(true))
+	opts := []exporterhelper.ExporterOption{
+		exporterhelper.WithSpanName("otelsvc.exporter." + exporterName + ".ConsumeTraceData"),
+		exporterhelper.WithRecordMetrics(true),
+		exporterhelper.WithCircuitBreaker(circuitBreaker),
+	}
+	if tagMapping.IsDefault() && s.indexing == nil {
+		// The relayed requests are not translated, relaying them would
+		// bypass a tag mapping or the attribute indexing.
+		opts = append(opts, exporterhelper.WithRawTraceData(jaegerrelay.Format, s.pushRawTraceData))
+	}
+	exp, err := exporterhelper.NewTraceExporter(exporterName, s.pushTraceData, opts...)
 
 	return exp, err
 }
@@ -53,7 +91,12 @@ func New(exporterName, collectorEndpoint string) (exporter.TraceExporter, error)
 // protoGRPCSender forwards spans encoded in the jaeger proto
 // format, to a grpc server.
 type protoGRPCSender struct {
-	client jaegerproto.CollectorServiceClient
+	conn       *grpc.ClientConn
+	client     jaegerproto.CollectorServiceClient
+	metadata   metadata.MD
+	tagMapping jaegertranslator.TagMapping
+	indexing   *attributeindex.Classifier
+	propagate  bool
 }
 
 func (s *protoGRPCSender) pushTraceData(
@@ -61,13 +104,13 @@ func (s *protoGRPCSender) pushTraceData(
 	td consumerdata.TraceData,
 ) (droppedSpans int, err error) {
 
-	protoBatch, err := jaegertranslator.OCProtoToJaegerProto(td)
+	protoBatch, err := jaegertranslator.OCProtoToJaegerProto(s.indexing.PayloadAsTimeEvent(s.tagMapping.Apply(td)))
 	if err != nil {
 		return len(td.Spans), consumererror.Permanent(err)
 	}
 
 	_, err = s.client.PostSpans(
-		context.Background(),
+		s.outgoingContext(ctx),
 		&jaegerproto.PostSpansRequest{Batch: *protoBatch})
 
 	if err != nil {
@@ -76,3 +119,28 @@ func (s *protoGRPCSender) pushTraceData(
 
 	return droppedSpans, err
 }
+
+// pushRawTraceData relays a serialized PostSpans request, as received by the
+// Jaeger receiver, without decoding it.
+func (s *protoGRPCSender) pushRawTraceData(
+	ctx context.Context,
+	rtd consumerdata.RawTraceData,
+) error {
+	return s.conn.Invoke(
+		s.outgoingContext(ctx),
+		jaegerrelay.PostSpansMethod,
+		jaegerrelay.Message(rtd.Payload),
+		&jaegerproto.PostSpansResponse{},
+		grpc.ForceCodec(jaegerrelay.Codec{}))
+}
+
+// outgoingContext returns the context of the requests, with the metadata of
+// the exporter and the trace context of the span of ctx if it is propagated.
+// The requests don't inherit the cancellation of ctx.
+func (s *protoGRPCSender) outgoingContext(ctx context.Context) context.Context {
+	md := s.metadata
+	if s.propagate {
+		md = exporterhelper.AppendGRPCTraceContext(ctx, md)
+	}
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
@@ -16,11 +16,27 @@ package jaegergrpcexporter
 
 import (
 	"context"
+	"net"
+	"strings"
 	"testing"
 
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"github.com/jaegertracing/jaeger/model"
+	jaegerproto "github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
+	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	"github.com/open-telemetry/opentelemetry-service/internal/jaegerrelay"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 func TestNew(t *testing.T) {
@@ -50,7 +66,7 @@ func TestNew(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := New(tt.args.exporterName, tt.args.collectorEndpoint)
+			got, err := New(tt.args.exporterName, tt.args.collectorEndpoint, nil, "", jaegertranslator.TagMapping{}, attributeindex.Settings{}, exporterhelper.CircuitBreakerSettings{}, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -65,3 +81,201 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+type mockCollector struct {
+	md  chan metadata.MD
+	req chan *jaegerproto.PostSpansRequest
+}
+
+func (mc *mockCollector) PostSpans(ctx context.Context, req *jaegerproto.PostSpansRequest) (*jaegerproto.PostSpansResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	mc.md <- md
+	if mc.req != nil {
+		mc.req <- req
+	}
+	return &jaegerproto.PostSpansResponse{}, nil
+}
+
+func TestExporter_HeadersAndUserAgent(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	collector := &mockCollector{md: make(chan metadata.MD, 1)}
+	jaegerproto.RegisterCollectorServiceServer(srv, collector)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	exp, err := New(
+		typeStr,
+		ln.Addr().String(),
+		map[string]string{"x-scope-orgid": "tenant"},
+		"custom-agent/1.0",
+		jaegertranslator.TagMapping{},
+		attributeindex.Settings{},
+		exporterhelper.CircuitBreakerSettings{},
+		false)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+	}))
+
+	md := <-collector.md
+	assert.Equal(t, []string{"tenant"}, md.Get("x-scope-orgid"))
+	require.Len(t, md.Get("user-agent"), 1)
+	assert.True(t, strings.HasPrefix(md.Get("user-agent")[0], "custom-agent/1.0 "))
+}
+
+func TestExporter_RawTraceData(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	collector := &mockCollector{
+		md:  make(chan metadata.MD, 1),
+		req: make(chan *jaegerproto.PostSpansRequest, 1),
+	}
+	jaegerproto.RegisterCollectorServiceServer(srv, collector)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	exp, err := New(
+		typeStr,
+		ln.Addr().String(),
+		map[string]string{"x-scope-orgid": "tenant"},
+		"",
+		jaegertranslator.TagMapping{},
+		attributeindex.Settings{},
+		exporterhelper.CircuitBreakerSettings{},
+		false)
+	require.NoError(t, err)
+	rexp, ok := exp.(consumer.RawTraceConsumer)
+	require.True(t, ok)
+	require.True(t, rexp.AcceptsRawTraceFormat(jaegerrelay.Format))
+
+	payload, err := proto.Marshal(&jaegerproto.PostSpansRequest{
+		Batch: model.Batch{
+			Process: &model.Process{ServiceName: "frontend"},
+			Spans:   []*model.Span{{OperationName: "GET /"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, rexp.ConsumeRawTraceData(context.Background(), consumerdata.RawTraceData{
+		Format:  jaegerrelay.Format,
+		Payload: payload,
+	}))
+
+	md := <-collector.md
+	assert.Equal(t, []string{"tenant"}, md.Get("x-scope-orgid"))
+	req := <-collector.req
+	assert.Equal(t, "frontend", req.Batch.Process.ServiceName)
+	require.Len(t, req.Batch.Spans, 1)
+	assert.Equal(t, "GET /", req.Batch.Spans[0].OperationName)
+
+	// Relaying the requests would bypass the tag mapping.
+	exp, err = New(
+		typeStr,
+		ln.Addr().String(),
+		nil,
+		"",
+		jaegertranslator.TagMapping{IncludeResourceLabels: true},
+		attributeindex.Settings{},
+		exporterhelper.CircuitBreakerSettings{},
+		false)
+	require.NoError(t, err)
+	assert.False(t, exp.(consumer.RawTraceConsumer).AcceptsRawTraceFormat(jaegerrelay.Format))
+
+	// Or the attribute indexing.
+	exp, err = New(
+		typeStr,
+		ln.Addr().String(),
+		nil,
+		"",
+		jaegertranslator.TagMapping{},
+		attributeindex.Settings{Payload: []string{"http.request.body"}},
+		exporterhelper.CircuitBreakerSettings{},
+		false)
+	require.NoError(t, err)
+	assert.False(t, exp.(consumer.RawTraceConsumer).AcceptsRawTraceFormat(jaegerrelay.Format))
+}
+
+func TestExporter_AttributeIndexing(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	collector := &mockCollector{
+		md:  make(chan metadata.MD, 1),
+		req: make(chan *jaegerproto.PostSpansRequest, 1),
+	}
+	jaegerproto.RegisterCollectorServiceServer(srv, collector)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	exp, err := New(
+		typeStr,
+		ln.Addr().String(),
+		nil,
+		"",
+		jaegertranslator.TagMapping{},
+		attributeindex.Settings{Payload: []string{"http.request.*"}},
+		exporterhelper.CircuitBreakerSettings{},
+		false)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+		Spans: []*tracepb.Span{{
+			TraceId: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			SpanId:  []byte{0, 0, 0, 0, 0, 0, 0, 1},
+			Name:    &tracepb.TruncatableString{Value: "GET /"},
+			Attributes: &tracepb.Span_Attributes{
+				AttributeMap: map[string]*tracepb.AttributeValue{
+					"http.method": {
+						Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "GET"}},
+					},
+					"http.request.body": {
+						Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "{}"}},
+					},
+				},
+			},
+		}},
+	}))
+
+	req := <-collector.req
+	require.Len(t, req.Batch.Spans, 1)
+	span := req.Batch.Spans[0]
+	var tags []string
+	for _, tag := range span.Tags {
+		tags = append(tags, tag.Key)
+	}
+	assert.Contains(t, tags, "http.method")
+	assert.NotContains(t, tags, "http.request.body")
+	require.Len(t, span.Logs, 1)
+	require.Len(t, span.Logs[0].Fields, 1)
+	assert.Equal(t, "http.request.body", span.Logs[0].Fields[0].Key)
+	assert.Equal(t, "{}", span.Logs[0].Fields[0].VStr)
+}
+
+func TestExporter_IPv6(t *testing.T) {
+	ln, err := net.Listen("tcp", testutils.GetAvailableLocalIPv6Address(t))
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	collector := &mockCollector{md: make(chan metadata.MD, 1)}
+	jaegerproto.RegisterCollectorServiceServer(srv, collector)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	exp, err := New(
+		typeStr,
+		ln.Addr().String(),
+		map[string]string{"x-scope-orgid": "tenant"},
+		"",
+		jaegertranslator.TagMapping{},
+		attributeindex.Settings{},
+		exporterhelper.CircuitBreakerSettings{},
+		false)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+	}))
+
+	md := <-collector.md
+	assert.Equal(t, []string{"tenant"}, md.Get("x-scope-orgid"))
+}
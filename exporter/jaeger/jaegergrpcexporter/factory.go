@@ -22,6 +22,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 )
 
 const (
@@ -45,6 +46,8 @@ func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
+		Format:         exporterhelper.JaegerGRPCWireFormats.Default,
+		CircuitBreaker: exporterhelper.NewDefaultCircuitBreakerSettings(),
 	}
 }
 
@@ -63,7 +66,19 @@ func (f *Factory) CreateTraceExporter(
 		return nil, err
 	}
 
-	exp, err := New(expCfg.Name(), expCfg.Endpoint)
+	if _, err := exporterhelper.JaegerGRPCWireFormats.Resolve(expCfg.Format); err != nil {
+		return nil, fmt.Errorf("%q config has an invalid \"format\": %v", expCfg.Name(), err)
+	}
+
+	exp, err := New(
+		expCfg.Name(),
+		expCfg.Endpoint,
+		expCfg.Headers,
+		expCfg.UserAgent,
+		expCfg.TagMapping,
+		expCfg.AttributeIndexing,
+		expCfg.CircuitBreaker,
+		expCfg.PropagateTraceContext)
 	if err != nil {
 		return nil, err
 	}
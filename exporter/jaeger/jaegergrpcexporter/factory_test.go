@@ -61,3 +61,15 @@ func TestCreateInstanceViaFactory(t *testing.T) {
 
 	assert.NoError(t, exp.Shutdown())
 }
+
+func TestCreateTraceExporter_Format(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "some.target.org:12345"
+	assert.Equal(t, "proto", cfg.Format)
+
+	cfg.Format = "thrift"
+	exp, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	assert.EqualError(t, err, `"jaeger-grpc" config has an invalid "format": unsupported jaeger-grpc format "thrift", must be one of: proto`)
+	assert.Nil(t, exp)
+}
@@ -25,6 +25,9 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -58,7 +61,16 @@ func TestLoadConfig(t *testing.T) {
 			"added-entry": "added value",
 			"dot.test":    "test",
 		},
-		Timeout: 2 * time.Second,
+		UserAgent: "custom-agent/1.0",
+		Timeout:   2 * time.Second,
+		TagMapping: jaegertranslator.TagMapping{
+			Rename: map[string]string{"host.name": "hostname"},
+		},
+		AttributeIndexing: attributeindex.Settings{
+			Index: []string{"http.*", "error"},
+		},
+		Format:         "thrift",
+		CircuitBreaker: exporterhelper.NewDefaultCircuitBreakerSettings(),
 	}
 	assert.Equal(t, &expectedCfg, e1)
 
@@ -29,6 +29,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
 	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
@@ -43,11 +44,21 @@ const defaultHTTPTimeout = time.Second * 5
 // collector.
 // The timeout is used to set the timeout for the HTTP requests, if the
 // value is equal or smaller than zero the default of 5 seconds is used.
+// The tagMapping is applied to the trace data before it is translated.
+// The attributeIndexing selects the span attributes sent as span tags, the
+// payload attributes are sent as a span log at the start of the span.
+// The circuitBreaker defines the circuit breaker of the exporter.
+// The propagateTraceContext parameter sends the trace context of the exporter
+// span with the requests.
 func New(
 	exporterName string,
 	httpAddress string,
 	headers map[string]string,
 	timeout time.Duration,
+	tagMapping jaegertranslator.TagMapping,
+	attributeIndexing attributeindex.Settings,
+	circuitBreaker exporterhelper.CircuitBreakerSettings,
+	propagateTraceContext bool,
 ) (exporter.TraceExporter, error) {
 
 	clientTimeout := defaultHTTPTimeout
@@ -55,16 +66,20 @@ func New(
 		clientTimeout = timeout
 	}
 	s := &jaegerThriftHTTPSender{
-		url:     httpAddress,
-		headers: headers,
-		client:  &http.Client{Timeout: clientTimeout},
+		url:        httpAddress,
+		headers:    headers,
+		client:     &http.Client{Timeout: clientTimeout},
+		tagMapping: tagMapping,
+		indexing:   attributeindex.NewClassifier(attributeIndexing),
+		propagate:  propagateTraceContext,
 	}
 
 	exp, err := exporterhelper.NewTraceExporter(
 		exporterName,
 		s.pushTraceData,
 		exporterhelper.WithSpanName("otelsvc.exporter."+exporterName+".ConsumeTraceData"),
-		exporterhelper.WithRecordMetrics(true))
+		exporterhelper.WithRecordMetrics(true),
+		exporterhelper.WithCircuitBreaker(circuitBreaker))
 
 	return exp, err
 }
@@ -72,9 +87,12 @@ func New(
 // jaegerThriftHTTPSender forwards spans encoded in the jaeger thrift
 // format to a http server.
 type jaegerThriftHTTPSender struct {
-	url     string
-	headers map[string]string
-	client  *http.Client
+	url        string
+	headers    map[string]string
+	client     *http.Client
+	tagMapping jaegertranslator.TagMapping
+	indexing   *attributeindex.Classifier
+	propagate  bool
 }
 
 func (s *jaegerThriftHTTPSender) pushTraceData(
@@ -82,7 +100,7 @@ func (s *jaegerThriftHTTPSender) pushTraceData(
 	td consumerdata.TraceData,
 ) (droppedSpans int, err error) {
 
-	tBatch, err := jaegertranslator.OCProtoToJaegerThrift(td)
+	tBatch, err := jaegertranslator.OCProtoToJaegerThrift(s.indexing.PayloadAsTimeEvent(s.tagMapping.Apply(td)))
 	if err != nil {
 		return len(td.Spans), consumererror.Permanent(err)
 	}
@@ -104,6 +122,10 @@ func (s *jaegerThriftHTTPSender) pushTraceData(
 		}
 	}
 
+	if s.propagate {
+		exporterhelper.InjectHTTPTraceContext(ctx, req)
+	}
+
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return len(td.Spans), err
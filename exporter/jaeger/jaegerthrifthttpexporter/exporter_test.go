@@ -22,6 +22,9 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/attributeindex"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 func TestNew(t *testing.T) {
@@ -57,7 +60,7 @@ func TestNew(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := New(tt.args.exporterName, tt.args.httpAddress, tt.args.headers, tt.args.timeout)
+			got, err := New(tt.args.exporterName, tt.args.httpAddress, tt.args.headers, tt.args.timeout, jaegertranslator.TagMapping{}, attributeindex.Settings{}, exporterhelper.CircuitBreakerSettings{}, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -23,6 +23,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 )
 
 const (
@@ -46,7 +47,9 @@ func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
-		Timeout: defaultHTTPTimeout,
+		Timeout:        defaultHTTPTimeout,
+		Format:         exporterhelper.JaegerThriftHTTPWireFormats.Default,
+		CircuitBreaker: exporterhelper.NewDefaultCircuitBreakerSettings(),
 	}
 }
 
@@ -74,11 +77,19 @@ func (f *Factory) CreateTraceExporter(
 		return nil, err
 	}
 
+	if _, err := exporterhelper.JaegerThriftHTTPWireFormats.Resolve(expCfg.Format); err != nil {
+		return nil, fmt.Errorf("%q config has an invalid \"format\": %v", expCfg.Name(), err)
+	}
+
 	exp, err := New(
 		expCfg.Name(),
 		expCfg.URL,
-		expCfg.Headers,
-		expCfg.Timeout)
+		exporterhelper.HeadersWithUserAgent(expCfg.Headers, expCfg.UserAgent),
+		expCfg.Timeout,
+		expCfg.TagMapping,
+		expCfg.AttributeIndexing,
+		expCfg.CircuitBreaker,
+		expCfg.PropagateTraceContext)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +102,19 @@ func TestFactory_CreateTraceExporter(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unsupported_format",
+			config: &Config{
+				ExporterSettings: configmodels.ExporterSettings{
+					TypeVal: typeStr,
+					NameVal: typeStr,
+				},
+				URL:     "http://some.other.location/api/traces",
+				Timeout: 2 * time.Second,
+				Format:  "proto",
+			},
+			wantErr: true,
+		},
 		{
 			name: "create_instance",
 			config: &Config{
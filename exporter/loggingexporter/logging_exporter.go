@@ -16,8 +16,13 @@ package loggingexporter
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
@@ -47,7 +52,9 @@ func NewMetricsExporter(exporterName string, logger *zap.Logger) (exporter.Metri
 		exporterName,
 		func(ctx context.Context, md consumerdata.MetricsData) (int, error) {
 			logger.Info(exporterName, zap.Int("#metrics", len(md.Metrics)))
-			// TODO: Add ability to record the received data
+			if logger.Core().Enabled(zapcore.DebugLevel) {
+				logMetricsDetail(logger, exporterName, md)
+			}
 			return 0, nil
 		},
 		exporterhelper.WithSpanName(exporterName+".ConsumeMetricsData"),
@@ -55,3 +62,87 @@ func NewMetricsExporter(exporterName string, logger *zap.Logger) (exporter.Metri
 		exporterhelper.WithShutdown(logger.Sync),
 	)
 }
+
+// logMetricsDetail logs the descriptor of each metric and a message per point
+// with its label values and value, to debug the conversions of the receivers
+// and processors.
+func logMetricsDetail(logger *zap.Logger, exporterName string, md consumerdata.MetricsData) {
+	for _, metric := range md.Metrics {
+		desc := metric.GetMetricDescriptor()
+		if desc == nil {
+			continue
+		}
+		labelKeys := make([]string, 0, len(desc.LabelKeys))
+		for _, key := range desc.LabelKeys {
+			labelKeys = append(labelKeys, key.GetKey())
+		}
+		logger.Debug(exporterName+" metric",
+			zap.String("name", desc.Name),
+			zap.String("type", desc.Type.String()),
+			zap.String("unit", desc.Unit),
+			zap.Strings("label-keys", labelKeys),
+			zap.Int("#timeseries", len(metric.Timeseries)))
+
+		for _, ts := range metric.Timeseries {
+			labelValues := make([]string, 0, len(ts.LabelValues))
+			for _, value := range ts.LabelValues {
+				if !value.GetHasValue() {
+					labelValues = append(labelValues, "<unset>")
+					continue
+				}
+				labelValues = append(labelValues, value.GetValue())
+			}
+			for _, point := range ts.Points {
+				fields := []zap.Field{
+					zap.String("name", desc.Name),
+					zap.Strings("label-values", labelValues),
+					zap.Time("start-time", timestampToTime(ts.StartTimestamp)),
+					zap.Time("time", timestampToTime(point.Timestamp)),
+				}
+				fields = append(fields, pointValueFields(point)...)
+				logger.Debug(exporterName+" point", fields...)
+			}
+		}
+	}
+}
+
+func pointValueFields(point *metricspb.Point) []zap.Field {
+	switch value := point.Value.(type) {
+	case *metricspb.Point_Int64Value:
+		return []zap.Field{zap.Int64("value", value.Int64Value)}
+	case *metricspb.Point_DoubleValue:
+		return []zap.Field{zap.Float64("value", value.DoubleValue)}
+	case *metricspb.Point_DistributionValue:
+		dist := value.DistributionValue
+		bucketCounts := make([]int64, 0, len(dist.Buckets))
+		for _, bucket := range dist.Buckets {
+			bucketCounts = append(bucketCounts, bucket.GetCount())
+		}
+		return []zap.Field{
+			zap.Int64("count", dist.Count),
+			zap.Float64("sum", dist.Sum),
+			zap.Float64s("bounds", dist.GetBucketOptions().GetExplicit().GetBounds()),
+			zap.Int64s("bucket-counts", bucketCounts),
+		}
+	case *metricspb.Point_SummaryValue:
+		summary := value.SummaryValue
+		percentiles := make([]string, 0, len(summary.GetSnapshot().GetPercentileValues()))
+		for _, p := range summary.GetSnapshot().GetPercentileValues() {
+			percentiles = append(percentiles, fmt.Sprintf("p%g=%g", p.Percentile, p.Value))
+		}
+		return []zap.Field{
+			zap.Int64("count", summary.GetCount().GetValue()),
+			zap.Float64("sum", summary.GetSum().GetValue()),
+			zap.Strings("percentiles", percentiles),
+		}
+	default:
+		return []zap.Field{zap.String("value", "<unset>")}
+	}
+}
+
+func timestampToTime(ts *timestamp.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+}
@@ -16,13 +16,17 @@ package loggingexporter
 import (
 	"context"
 	"testing"
+	"time"
 
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
 )
 
 func TestLoggingTraceExporterNoErrors(t *testing.T) {
@@ -60,3 +64,67 @@ func TestLoggingMetricsExporterNoErrors(t *testing.T) {
 	}
 	assert.NoError(t, lme.Shutdown())
 }
+
+func TestLoggingMetricsExporterDetail(t *testing.T) {
+	const exporterName = "test_metrics_exporter"
+	core, logs := observer.New(zapcore.DebugLevel)
+	lme, err := NewMetricsExporter(exporterName, zap.New(core))
+	assert.NoError(t, err)
+
+	now := time.Unix(1000, 0).UTC()
+	md := consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "requests",
+					Unit:      "1",
+					Type:      metricspb.MetricDescriptor_CUMULATIVE_INT64,
+					LabelKeys: []*metricspb.LabelKey{{Key: "method"}, {Key: "code"}},
+				},
+				Timeseries: []*metricspb.TimeSeries{{
+					StartTimestamp: internal.TimeToTimestamp(now),
+					LabelValues:    []*metricspb.LabelValue{{Value: "GET", HasValue: true}, {}},
+					Points: []*metricspb.Point{{
+						Timestamp: internal.TimeToTimestamp(now.Add(time.Minute)),
+						Value:     &metricspb.Point_Int64Value{Int64Value: 42},
+					}},
+				}},
+			},
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name: "latency",
+					Unit: "ms",
+					Type: metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION,
+				},
+				Timeseries: []*metricspb.TimeSeries{{
+					Points: []*metricspb.Point{{
+						Value: &metricspb.Point_DistributionValue{DistributionValue: &metricspb.DistributionValue{
+							Count: 3,
+							Sum:   30,
+							BucketOptions: &metricspb.DistributionValue_BucketOptions{
+								Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+									Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: []float64{10}},
+								},
+							},
+							Buckets: []*metricspb.DistributionValue_Bucket{{Count: 1}, {Count: 2}},
+						}},
+					}},
+				}},
+			},
+		},
+	}
+	assert.NoError(t, lme.ConsumeMetricsData(context.Background(), md))
+
+	metrics := logs.FilterMessage(exporterName + " metric").All()
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, "CUMULATIVE_INT64", metrics[0].ContextMap()["type"])
+	assert.Equal(t, []interface{}{"method", "code"}, metrics[0].ContextMap()["label-keys"])
+
+	points := logs.FilterMessage(exporterName + " point").All()
+	assert.Len(t, points, 2)
+	assert.Equal(t, []interface{}{"GET", "<unset>"}, points[0].ContextMap()["label-values"])
+	assert.Equal(t, int64(42), points[0].ContextMap()["value"])
+	assert.Equal(t, now, points[0].ContextMap()["start-time"])
+	assert.Equal(t, []interface{}{float64(10)}, points[1].ContextMap()["bounds"])
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, points[1].ContextMap()["bucket-counts"])
+}
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 )
 
 // Config defines configuration for OpenCensus exporter.
@@ -30,12 +31,15 @@ type Config struct {
 	Endpoint string `mapstructure:"endpoint"`
 
 	// The compression key for supported compression types within
-	// collector. Currently the only supported mode is `gzip`.
+	// collector. Supported modes are `gzip`, `snappy` and `zstd`.
 	Compression string `mapstructure:"compression"`
 
 	// The headers associated with gRPC requests.
 	Headers map[string]string `mapstructure:"headers"`
 
+	// UserAgent, if not empty, is prepended to the gRPC user agent.
+	UserAgent string `mapstructure:"user-agent"`
+
 	// The number of workers that send the gRPC requests.
 	NumWorkers int `mapstructure:"num-workers"`
 
@@ -53,4 +57,14 @@ type Config struct {
 	// The keepalive parameters for client gRPC. See grpc.WithKeepaliveParams
 	// (https://godoc.org/google.golang.org/grpc#WithKeepaliveParams).
 	KeepaliveParameters *KeepaliveConfig `mapstructure:"keepalive,omitempty"`
+
+	// CircuitBreaker makes the exporter fail fast, without sending the data,
+	// while the destination keeps failing.
+	CircuitBreaker exporterhelper.CircuitBreakerSettings `mapstructure:"circuit-breaker"`
+
+	// ReplayBufferSize is the number of the last batches sent by each worker
+	// that are sent again after a failure of the stream, as the batches sent
+	// shortly before a stream reset are lost without error. Zero disables the
+	// replay, otherwise batches may be received twice.
+	ReplayBufferSize int `mapstructure:"replay-buffer-size"`
 }
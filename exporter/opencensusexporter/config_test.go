@@ -17,12 +17,14 @@ package opencensusexporter
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -51,6 +53,7 @@ func TestLoadConfig(t *testing.T) {
 				"header1":                "234",
 				"another":                "somevalue",
 			},
+			UserAgent:         "custom-agent/1.0",
 			Endpoint:          "1.2.3.4:1234",
 			Compression:       "on",
 			NumWorkers:        123,
@@ -62,5 +65,13 @@ func TestLoadConfig(t *testing.T) {
 				PermitWithoutStream: true,
 				Timeout:             30,
 			},
+			CircuitBreaker: exporterhelper.CircuitBreakerSettings{
+				Enabled:      true,
+				FailureRatio: 0.9,
+				MinRequests:  10,
+				Window:       30 * time.Second,
+				OpenDuration: time.Minute,
+			},
+			ReplayBufferSize: 16,
 		})
 }
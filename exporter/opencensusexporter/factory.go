@@ -52,7 +52,8 @@ func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
-		Headers: map[string]string{},
+		Headers:        map[string]string{},
+		CircuitBreaker: exporterhelper.NewDefaultCircuitBreakerSettings(),
 	}
 }
 
@@ -72,6 +73,7 @@ func (f *Factory) CreateTraceExporter(logger *zap.Logger, config configmodels.Ex
 		oce.PushTraceData,
 		exporterhelper.WithSpanName("ocservice.exporter.OpenCensus.ConsumeTraceData"),
 		exporterhelper.WithRecordMetrics(true),
+		exporterhelper.WithCircuitBreaker(ocac.CircuitBreaker),
 		exporterhelper.WithShutdown(oce.Shutdown))
 	if err != nil {
 		return nil, err
@@ -87,15 +89,23 @@ func (f *Factory) createOCAgentExporter(logger *zap.Logger, ocac *Config, opts [
 		numWorkers = ocac.NumWorkers
 	}
 
-	exportersChan := make(chan *ocagent.Exporter, numWorkers)
+	oce := &ocagentExporter{
+		workers: make(chan *worker, numWorkers),
+		stopped: make(chan struct{}),
+	}
 	for exporterIndex := 0; exporterIndex < numWorkers; exporterIndex++ {
 		exporter, serr := ocagent.NewExporter(opts...)
 		if serr != nil {
 			return nil, fmt.Errorf("cannot configure OpenCensus exporter: %v", serr)
 		}
-		exportersChan <- exporter
+		w := &worker{
+			exporter: exporter,
+			logger:   logger.With(zap.String("exporter", ocac.Name())),
+			replay:   newReplayBuffer(ocac.ReplayBufferSize),
+		}
+		oce.all = append(oce.all, w)
+		oce.workers <- w
 	}
-	oce := &ocagentExporter{exporters: exportersChan}
 	return oce, nil
 }
 
@@ -144,6 +154,9 @@ func (f *Factory) OCAgentOptions(logger *zap.Logger, ocac *Config) ([]ocagent.Ex
 	if len(ocac.Headers) > 0 {
 		opts = append(opts, ocagent.WithHeaders(ocac.Headers))
 	}
+	if ocac.UserAgent != "" {
+		opts = append(opts, ocagent.WithGRPCDialOption(grpc.WithUserAgent(ocac.UserAgent)))
+	}
 	if ocac.ReconnectionDelay > 0 {
 		opts = append(opts, ocagent.WithReconnectionPeriod(ocac.ReconnectionDelay))
 	}
@@ -175,6 +188,7 @@ func (f *Factory) CreateMetricsExporter(logger *zap.Logger, config configmodels.
 		oce.PushMetricsData,
 		exporterhelper.WithSpanName("ocservice.exporter.OpenCensus.ConsumeMetricsData"),
 		exporterhelper.WithRecordMetrics(true),
+		exporterhelper.WithCircuitBreaker(ocac.CircuitBreaker),
 		exporterhelper.WithShutdown(oce.Shutdown))
 
 	if err != nil {
@@ -104,12 +104,26 @@ func TestCreateTraceExporter(t *testing.T) {
 			},
 		},
 		{
-			name: "Compression",
+			name: "GzipCompression",
 			config: Config{
 				Endpoint:    rcvCfg.Endpoint,
 				Compression: compression.Gzip,
 			},
 		},
+		{
+			name: "SnappyCompression",
+			config: Config{
+				Endpoint:    rcvCfg.Endpoint,
+				Compression: compression.Snappy,
+			},
+		},
+		{
+			name: "ZstdCompression",
+			config: Config{
+				Endpoint:    rcvCfg.Endpoint,
+				Compression: compression.Zstd,
+			},
+		},
 		{
 			name: "Headers",
 			config: Config{
@@ -20,9 +20,9 @@ import (
 	"sync"
 	"time"
 
-	"contrib.go.opencensus.io/exporter/ocagent"
 	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
 	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
@@ -37,8 +37,106 @@ type KeepaliveConfig struct {
 	PermitWithoutStream bool          `mapstructure:"permit-without-stream,omitempty"`
 }
 
+// ocExporter is the part of ocagent.Exporter used to send the batches.
+type ocExporter interface {
+	ExportTraceServiceRequest(batch *agenttracepb.ExportTraceServiceRequest) error
+	ExportMetricsServiceRequest(batch *agentmetricspb.ExportMetricsServiceRequest) error
+	Stop() error
+}
+
+// worker is an OpenCensus exporter with the last batches sent on its stream.
+// The stream doesn't acknowledge the batches: the batches sent shortly before
+// the stream resets are lost without error, so once a send fails the recent
+// batches are sent again before the next batch.
+type worker struct {
+	exporter ocExporter
+	logger   *zap.Logger
+	// replay holds the last batches sent, it is nil if the replay is disabled.
+	replay *replayBuffer
+	// failed is set when a send fails, until the replay succeeds.
+	failed bool
+}
+
+func (w *worker) send(batch interface{}) error {
+	if w.failed && w.replay.len() > 0 {
+		batches := w.replay.batches()
+		for _, b := range batches {
+			if err := w.export(b); err != nil {
+				return err
+			}
+		}
+		w.logger.Info("Sent again the batches preceding a stream failure", zap.Int("batches", len(batches)))
+	}
+	w.failed = false
+
+	if err := w.export(batch); err != nil {
+		w.failed = true
+		return err
+	}
+	w.replay.add(batch)
+	return nil
+}
+
+func (w *worker) export(batch interface{}) error {
+	switch b := batch.(type) {
+	case *agenttracepb.ExportTraceServiceRequest:
+		return w.exporter.ExportTraceServiceRequest(b)
+	case *agentmetricspb.ExportMetricsServiceRequest:
+		return w.exporter.ExportMetricsServiceRequest(b)
+	}
+	return fmt.Errorf("unexpected batch type %T", batch)
+}
+
+// replayBuffer holds the last batches sent, up to its size.
+type replayBuffer struct {
+	ring  []interface{}
+	next  int
+	count int
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	if size <= 0 {
+		return nil
+	}
+	return &replayBuffer{ring: make([]interface{}, size)}
+}
+
+func (rb *replayBuffer) len() int {
+	if rb == nil {
+		return 0
+	}
+	return rb.count
+}
+
+// add stores a batch, evicting the oldest one if the buffer is full.
+func (rb *replayBuffer) add(batch interface{}) {
+	if rb == nil {
+		return
+	}
+	rb.ring[rb.next] = batch
+	rb.next = (rb.next + 1) % len(rb.ring)
+	if rb.count < len(rb.ring) {
+		rb.count++
+	}
+}
+
+// batches returns the stored batches, the oldest first.
+func (rb *replayBuffer) batches() []interface{} {
+	batches := make([]interface{}, 0, rb.count)
+	for i := 0; i < rb.count; i++ {
+		batches = append(batches, rb.ring[(rb.next-rb.count+i+len(rb.ring))%len(rb.ring)])
+	}
+	return batches
+}
+
 type ocagentExporter struct {
-	exporters chan *ocagent.Exporter
+	// all are the workers, workers are the ones not in use.
+	all     []*worker
+	workers chan *worker
+
+	stopped  chan struct{}
+	stopOnce sync.Once
+	stopErr  error
 }
 
 type ocExporterErrorCode int
@@ -67,38 +165,57 @@ const (
 	errAlreadyStopped
 )
 
+// Shutdown flushes and stops all the OpenCensus exporters, including the ones
+// still in use by a push, and makes the new pushes fail.
 func (oce *ocagentExporter) Shutdown() error {
-	wg := &sync.WaitGroup{}
-	var errors []error
-	var errorsMu sync.Mutex
-	visitedCnt := 0
-	for currExporter := range oce.exporters {
-		wg.Add(1)
-		go func(exporter *ocagent.Exporter) {
-			defer wg.Done()
-			err := exporter.Stop()
-			if err != nil {
-				errorsMu.Lock()
-				errors = append(errors, err)
-				errorsMu.Unlock()
-			}
-		}(currExporter)
-		visitedCnt++
-		if visitedCnt == cap(oce.exporters) {
-			// Visited and started Stop on all exporters, just wait for the stop to finish.
-			break
+	oce.stopOnce.Do(func() {
+		close(oce.stopped)
+		wg := &sync.WaitGroup{}
+		var errors []error
+		var errorsMu sync.Mutex
+		for _, w := range oce.all {
+			wg.Add(1)
+			go func(exporter ocExporter) {
+				defer wg.Done()
+				err := exporter.Stop()
+				if err != nil {
+					errorsMu.Lock()
+					errors = append(errors, err)
+					errorsMu.Unlock()
+				}
+			}(w.exporter)
 		}
-	}
+		wg.Wait()
+		oce.stopErr = oterr.CombineErrors(errors)
+	})
+	return oce.stopErr
+}
 
-	wg.Wait()
-	close(oce.exporters)
+// acquire returns the first available worker, false if the exporters are
+// stopped.
+func (oce *ocagentExporter) acquire() (*worker, bool) {
+	select {
+	case <-oce.stopped:
+		return nil, false
+	default:
+	}
+	select {
+	case w := <-oce.workers:
+		return w, true
+	case <-oce.stopped:
+		return nil, false
+	}
+}
 
-	return oterr.CombineErrors(errors)
+// release makes the worker available again. The channel is never closed and
+// has room for all the workers, so release never blocks.
+func (oce *ocagentExporter) release(w *worker) {
+	oce.workers <- w
 }
 
 func (oce *ocagentExporter) PushTraceData(ctx context.Context, td consumerdata.TraceData) (int, error) {
-	// Get first available exporter.
-	exporter, ok := <-oce.exporters
+	// Get first available worker.
+	w, ok := oce.acquire()
 	if !ok {
 		err := &ocExporterError{
 			code: errAlreadyStopped,
@@ -107,14 +224,14 @@ func (oce *ocagentExporter) PushTraceData(ctx context.Context, td consumerdata.T
 		return len(td.Spans), err
 	}
 
-	err := exporter.ExportTraceServiceRequest(
+	err := w.send(
 		&agenttracepb.ExportTraceServiceRequest{
 			Spans:    td.Spans,
 			Resource: td.Resource,
 			Node:     td.Node,
 		},
 	)
-	oce.exporters <- exporter
+	oce.release(w)
 	if err != nil {
 		return len(td.Spans), err
 	}
@@ -122,8 +239,8 @@ func (oce *ocagentExporter) PushTraceData(ctx context.Context, td consumerdata.T
 }
 
 func (oce *ocagentExporter) PushMetricsData(ctx context.Context, md consumerdata.MetricsData) (int, error) {
-	// Get first available exporter.
-	exporter, ok := <-oce.exporters
+	// Get first available worker.
+	w, ok := oce.acquire()
 	if !ok {
 		err := &ocExporterError{
 			code: errAlreadyStopped,
@@ -137,8 +254,8 @@ func (oce *ocagentExporter) PushMetricsData(ctx context.Context, md consumerdata
 		Resource: md.Resource,
 		Node:     md.Node,
 	}
-	err := exporter.ExportMetricsServiceRequest(req)
-	oce.exporters <- exporter
+	err := w.send(req)
+	oce.release(w)
 	if err != nil {
 		return exporterhelper.NumTimeSeries(md), err
 	}
@@ -0,0 +1,128 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opencensusexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+)
+
+func TestShutdownWithExporterInUse(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+	opts, err := factory.OCAgentOptions(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	oce, err := factory.createOCAgentExporter(zap.NewNop(), cfg, opts)
+	require.NoError(t, err)
+
+	exporter, ok := oce.acquire()
+	require.True(t, ok)
+
+	// All the exporters are stopped, including the one in use.
+	_ = oce.Shutdown()
+	assert.Len(t, oce.all, defaultNumWorkers)
+
+	// Returning the exporter after shutdown doesn't panic and it isn't used
+	// anymore.
+	oce.release(exporter)
+	_, ok = oce.acquire()
+	assert.False(t, ok)
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{{}}}
+	dropped, err := oce.PushTraceData(context.Background(), td)
+	assert.Error(t, err)
+	assert.Equal(t, 1, dropped)
+
+	// Shutting down again is a no-op.
+	_ = oce.Shutdown()
+}
+
+// fakeOCExporter records the names of the spans sent, the sends fail while it
+// is broken.
+type fakeOCExporter struct {
+	broken bool
+	sent   []string
+}
+
+func (fe *fakeOCExporter) ExportTraceServiceRequest(batch *agenttracepb.ExportTraceServiceRequest) error {
+	if fe.broken {
+		return errors.New("stream reset")
+	}
+	fe.sent = append(fe.sent, batch.Spans[0].Name.Value)
+	return nil
+}
+
+func (fe *fakeOCExporter) ExportMetricsServiceRequest(batch *agentmetricspb.ExportMetricsServiceRequest) error {
+	return nil
+}
+
+func (fe *fakeOCExporter) Stop() error {
+	return nil
+}
+
+func testBatch(name string) *agenttracepb.ExportTraceServiceRequest {
+	return &agenttracepb.ExportTraceServiceRequest{
+		Spans: []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: name}}},
+	}
+}
+
+func TestWorker_Replay(t *testing.T) {
+	exporter := &fakeOCExporter{}
+	w := &worker{exporter: exporter, logger: zap.NewNop(), replay: newReplayBuffer(2)}
+
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, w.send(testBatch(name)))
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, exporter.sent)
+
+	// The last batches are sent again once the stream is back, before the
+	// next batch.
+	exporter.broken = true
+	assert.Error(t, w.send(testBatch("d")))
+	assert.Error(t, w.send(testBatch("d")))
+	exporter.broken = false
+	exporter.sent = nil
+	require.NoError(t, w.send(testBatch("d")))
+	assert.Equal(t, []string{"b", "c", "d"}, exporter.sent)
+
+	// Without failure the batches are sent once.
+	exporter.sent = nil
+	require.NoError(t, w.send(testBatch("e")))
+	assert.Equal(t, []string{"e"}, exporter.sent)
+}
+
+func TestWorker_ReplayDisabled(t *testing.T) {
+	exporter := &fakeOCExporter{}
+	w := &worker{exporter: exporter, logger: zap.NewNop(), replay: newReplayBuffer(0)}
+
+	require.NoError(t, w.send(testBatch("a")))
+	exporter.broken = true
+	assert.Error(t, w.send(testBatch("b")))
+	exporter.broken = false
+	require.NoError(t, w.send(testBatch("b")))
+	assert.Equal(t, []string{"a", "b"}, exporter.sent)
+}
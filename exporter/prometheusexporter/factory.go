@@ -82,8 +82,9 @@ func (f *Factory) CreateMetricsExporter(logger *zap.Logger, cfg configmodels.Exp
 
 	// The Prometheus metrics exporter has to run on the provided address
 	// as a server that'll be scraped by Prometheus.
+	om := newOpenMetricsHandler(pcfg.Namespace, pcfg.ConstLabels, pe)
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", pe)
+	mux.Handle("/metrics", om)
 
 	srv := &http.Server{Handler: mux}
 	go func() {
@@ -91,9 +92,10 @@ func (f *Factory) CreateMetricsExporter(logger *zap.Logger, cfg configmodels.Exp
 	}()
 
 	pexp := &prometheusExporter{
-		name:     cfg.Name(),
-		exporter: pe,
-		shutdown: ln.Close,
+		name:        cfg.Name(),
+		exporter:    pe,
+		openMetrics: om,
+		shutdown:    ln.Close,
 	}
 
 	return pexp, nil
@@ -0,0 +1,375 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusexporter
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	prometheus_golang "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	openMetricsMediaType   = "application/openmetrics-text"
+	openMetricsContentType = openMetricsMediaType + "; version=1.0.0; charset=utf-8"
+
+	// maxExemplarLabelsLength is the maximum number of characters of the
+	// names and values of the labels of an exemplar, the exemplars exceeding
+	// it are not exposed.
+	maxExemplarLabelsLength = 128
+)
+
+// openMetricsHandler serves the metrics in the OpenMetrics text format, with
+// the _created series and the exemplars of the histogram buckets, to the
+// scrapers accepting it. The other scrapers get the Prometheus text format
+// served by the Prometheus exporter.
+type openMetricsHandler struct {
+	namespace   string
+	constLabels prometheus_golang.Labels
+	prometheus  http.Handler
+
+	mu sync.Mutex
+	// metrics are the last exported metrics, by name and label keys like in
+	// the Prometheus exporter.
+	metrics map[string]*metricspb.Metric
+}
+
+func newOpenMetricsHandler(namespace string, constLabels prometheus_golang.Labels, prometheus http.Handler) *openMetricsHandler {
+	return &openMetricsHandler{
+		namespace:   namespace,
+		constLabels: constLabels,
+		prometheus:  prometheus,
+		metrics:     make(map[string]*metricspb.Metric),
+	}
+}
+
+// record keeps the metric, replacing the previous one with the same name and
+// label keys.
+func (h *openMetricsHandler) record(metric *metricspb.Metric) {
+	if metric == nil || len(metric.Timeseries) == 0 {
+		return
+	}
+	signature := h.metricName(metric)
+	for _, key := range metric.GetMetricDescriptor().GetLabelKeys() {
+		signature += "-" + key.GetKey()
+	}
+	h.mu.Lock()
+	h.metrics[signature] = metric
+	h.mu.Unlock()
+}
+
+func (h *openMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !acceptsOpenMetrics(r.Header.Get("Accept")) {
+		h.prometheus.ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", openMetricsContentType)
+	bw := bufio.NewWriter(w)
+	h.write(bw)
+	_ = bw.Flush()
+}
+
+// acceptsOpenMetrics returns true if the Accept header lists the OpenMetrics
+// media type with a non-zero quality.
+func acceptsOpenMetrics(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != openMetricsMediaType {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if quality, err := strconv.ParseFloat(q, 64); err == nil && quality <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// write writes the metric families sorted by name, terminated by the EOF
+// marker required by OpenMetrics.
+func (h *openMetricsHandler) write(w *bufio.Writer) {
+	h.mu.Lock()
+	metrics := make([]*metricspb.Metric, 0, len(h.metrics))
+	for _, metric := range h.metrics {
+		metrics = append(metrics, metric)
+	}
+	h.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return h.metricName(metrics[i]) < h.metricName(metrics[j])
+	})
+
+	for _, metric := range metrics {
+		h.writeFamily(w, metric)
+	}
+	w.WriteString("# EOF\n")
+}
+
+func (h *openMetricsHandler) metricName(metric *metricspb.Metric) string {
+	name := sanitize(metric.GetMetricDescriptor().GetName())
+	if h.namespace != "" {
+		return h.namespace + "_" + name
+	}
+	return name
+}
+
+// openMetricsType returns the OpenMetrics type of the metric.
+func openMetricsType(metric *metricspb.Metric) string {
+	switch metric.GetMetricDescriptor().GetType() {
+	case metricspb.MetricDescriptor_GAUGE_INT64, metricspb.MetricDescriptor_GAUGE_DOUBLE:
+		return "gauge"
+	case metricspb.MetricDescriptor_CUMULATIVE_INT64, metricspb.MetricDescriptor_CUMULATIVE_DOUBLE:
+		return "counter"
+	case metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION:
+		return "histogram"
+	case metricspb.MetricDescriptor_GAUGE_DISTRIBUTION:
+		return "gaugehistogram"
+	case metricspb.MetricDescriptor_SUMMARY:
+		return "summary"
+	default:
+		return "unknown"
+	}
+}
+
+func (h *openMetricsHandler) writeFamily(w *bufio.Writer, metric *metricspb.Metric) {
+	name := h.metricName(metric)
+	metricType := openMetricsType(metric)
+	if metricType == "counter" {
+		// The samples of the counters are suffixed with _total, which isn't
+		// part of the name of the family.
+		name = strings.TrimSuffix(name, "_total")
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+	if desc := metric.GetMetricDescriptor().GetDescription(); desc != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(desc))
+	}
+
+	labelKeys := metric.GetMetricDescriptor().GetLabelKeys()
+	for _, ts := range metric.Timeseries {
+		if ts == nil || len(ts.Points) == 0 {
+			continue
+		}
+		labels := h.labels(labelKeys, ts.LabelValues)
+		// Only the last point is exposed, a series has a single sample.
+		point := ts.Points[len(ts.Points)-1]
+		switch metricType {
+		case "counter":
+			writeSample(w, name+"_total", labels, pointValue(point))
+			writeCreated(w, name, labels, ts.StartTimestamp)
+		case "histogram", "gaugehistogram":
+			writeHistogram(w, name, metricType, labels, point.GetDistributionValue())
+			if metricType == "histogram" {
+				writeCreated(w, name, labels, ts.StartTimestamp)
+			}
+		case "summary":
+			writeSummary(w, name, labels, point.GetSummaryValue())
+			writeCreated(w, name, labels, ts.StartTimestamp)
+		default:
+			writeSample(w, name, labels, pointValue(point))
+		}
+	}
+}
+
+// labels returns the labels of a series, sorted by name, with the constant
+// labels. The labels without value are omitted.
+func (h *openMetricsHandler) labels(keys []*metricspb.LabelKey, values []*metricspb.LabelValue) []string {
+	labels := make([]string, 0, len(keys)+len(h.constLabels))
+	for i, key := range keys {
+		if i >= len(values) || values[i].GetValue() == "" {
+			continue
+		}
+		labels = append(labels, formatLabel(sanitize(key.GetKey()), values[i].GetValue()))
+	}
+	for key, value := range h.constLabels {
+		labels = append(labels, formatLabel(key, value))
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func formatLabel(key, value string) string {
+	return key + "=\"" + escapeLabelValue(value) + "\""
+}
+
+func writeSample(w *bufio.Writer, name string, labels []string, value string) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteString("{" + strings.Join(labels, ",") + "}")
+	}
+	w.WriteString(" " + value + "\n")
+}
+
+func writeCreated(w *bufio.Writer, name string, labels []string, start *timestamp.Timestamp) {
+	if start == nil {
+		return
+	}
+	writeSample(w, name+"_created", labels, formatTimestamp(start))
+}
+
+func writeHistogram(w *bufio.Writer, name, metricType string, labels []string, dist *metricspb.DistributionValue) {
+	if dist == nil {
+		return
+	}
+	bounds := dist.GetBucketOptions().GetExplicit().GetBounds()
+	var cumulative int64
+	for i, bound := range bounds {
+		var bucket *metricspb.DistributionValue_Bucket
+		if i < len(dist.Buckets) {
+			bucket = dist.Buckets[i]
+		}
+		cumulative += bucket.GetCount()
+		writeBucket(w, name, labels, formatFloat(bound), cumulative, bucket.GetExemplar())
+	}
+	var overflow *metricspb.DistributionValue_Bucket
+	if len(bounds) < len(dist.Buckets) {
+		overflow = dist.Buckets[len(bounds)]
+	}
+	writeBucket(w, name, labels, "+Inf", dist.Count, overflow.GetExemplar())
+
+	countSuffix, sumSuffix := "_count", "_sum"
+	if metricType == "gaugehistogram" {
+		countSuffix, sumSuffix = "_gcount", "_gsum"
+	}
+	writeSample(w, name+countSuffix, labels, strconv.FormatInt(dist.Count, 10))
+	writeSample(w, name+sumSuffix, labels, formatFloat(dist.Sum))
+}
+
+func writeBucket(w *bufio.Writer, name string, labels []string, le string, count int64, exemplar *metricspb.DistributionValue_Exemplar) {
+	bucketLabels := append(append(make([]string, 0, len(labels)+1), labels...), formatLabel("le", le))
+	w.WriteString(name + "_bucket{" + strings.Join(bucketLabels, ",") + "} " + strconv.FormatInt(count, 10))
+	if exemplar != nil {
+		if exemplarLabels, ok := formatExemplarLabels(exemplar.Attachments); ok {
+			w.WriteString(" # {" + exemplarLabels + "} " + formatFloat(exemplar.Value))
+			if exemplar.Timestamp != nil {
+				w.WriteString(" " + formatTimestamp(exemplar.Timestamp))
+			}
+		}
+	}
+	w.WriteString("\n")
+}
+
+// formatExemplarLabels returns the labels of an exemplar from its
+// attachments, it returns false if they exceed the length allowed by
+// OpenMetrics.
+func formatExemplarLabels(attachments map[string]string) (string, bool) {
+	keys := make([]string, 0, len(attachments))
+	length := 0
+	for key, value := range attachments {
+		keys = append(keys, key)
+		length += len([]rune(sanitize(key))) + len([]rune(value))
+	}
+	if length > maxExemplarLabelsLength {
+		return "", false
+	}
+	sort.Strings(keys)
+	labels := make([]string, 0, len(keys))
+	for _, key := range keys {
+		labels = append(labels, formatLabel(sanitize(key), attachments[key]))
+	}
+	return strings.Join(labels, ","), true
+}
+
+func writeSummary(w *bufio.Writer, name string, labels []string, summary *metricspb.SummaryValue) {
+	if summary == nil {
+		return
+	}
+	for _, p := range summary.GetSnapshot().GetPercentileValues() {
+		quantileLabels := append(append(make([]string, 0, len(labels)+1), labels...), formatLabel("quantile", formatFloat(p.Percentile/100)))
+		writeSample(w, name, quantileLabels, formatFloat(p.Value))
+	}
+	if summary.Count != nil {
+		writeSample(w, name+"_count", labels, strconv.FormatInt(summary.Count.Value, 10))
+	}
+	if summary.Sum != nil {
+		writeSample(w, name+"_sum", labels, formatFloat(summary.Sum.Value))
+	}
+}
+
+func pointValue(point *metricspb.Point) string {
+	switch value := point.Value.(type) {
+	case *metricspb.Point_Int64Value:
+		return strconv.FormatInt(value.Int64Value, 10)
+	case *metricspb.Point_DoubleValue:
+		return formatFloat(value.DoubleValue)
+	}
+	return "NaN"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// formatTimestamp formats the timestamp in seconds since the epoch, as
+// required by OpenMetrics.
+func formatTimestamp(ts *timestamp.Timestamp) string {
+	return strconv.FormatFloat(float64(ts.Seconds)+float64(ts.Nanos)/1e9, 'f', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+	labelValueEscaper = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\"", "\\\"")
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// sanitize replaces the characters other than letters and digits by
+// underscores, like the Prometheus exporter does for the names of the metrics
+// and labels.
+func sanitize(s string) string {
+	if s == "" {
+		return s
+	}
+	if len(s) > 100 {
+		s = s[:100]
+	}
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+	if unicode.IsDigit(rune(s[0])) {
+		s = "key_" + s
+	}
+	if s[0] == '_' {
+		s = "key" + s
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+)
+
+func TestAcceptsOpenMetrics(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: ""},
+		{accept: "text/plain;version=0.0.4"},
+		{accept: "application/openmetrics-text", want: true},
+		{accept: "application/openmetrics-text;version=0.0.1;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1", want: true},
+		{accept: "text/plain, application/openmetrics-text; q=0"},
+		{accept: "invalid;;, application/openmetrics-text", want: true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, acceptsOpenMetrics(tt.accept), "accept %q", tt.accept)
+	}
+}
+
+func TestPrometheusExporter_OpenMetrics(t *testing.T) {
+	config := &Config{
+		Namespace:   "test",
+		ConstLabels: map[string]string{"foo": "bar"},
+		Endpoint:    testutils.GetAvailableLocalAddress(t),
+	}
+
+	factory := Factory{}
+	consumer, err := factory.CreateMetricsExporter(zap.NewNop(), config)
+	require.NoError(t, err)
+	defer consumer.Shutdown()
+
+	start := &timestamp.Timestamp{Seconds: 1543160298, Nanos: 500000000}
+	requests := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:        "requests_total",
+			Description: "Number of requests",
+			Type:        metricspb.MetricDescriptor_CUMULATIVE_INT64,
+			LabelKeys:   []*metricspb.LabelKey{{Key: "http.method"}},
+		},
+		Timeseries: []*metricspb.TimeSeries{{
+			StartTimestamp: start,
+			LabelValues:    []*metricspb.LabelValue{{Value: "GET", HasValue: true}},
+			Points:         []*metricspb.Point{{Value: &metricspb.Point_Int64Value{Int64Value: 99}}},
+		}},
+	}
+	latency := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name: "latency",
+			Type: metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION,
+		},
+		Timeseries: []*metricspb.TimeSeries{{
+			StartTimestamp: start,
+			Points: []*metricspb.Point{{
+				Value: &metricspb.Point_DistributionValue{DistributionValue: &metricspb.DistributionValue{
+					Count: 5,
+					Sum:   12.5,
+					BucketOptions: &metricspb.DistributionValue_BucketOptions{
+						Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+							Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: []float64{1, 5}},
+						},
+					},
+					Buckets: []*metricspb.DistributionValue_Bucket{
+						{Count: 2},
+						{Count: 2, Exemplar: &metricspb.DistributionValue_Exemplar{
+							Value:       3.5,
+							Timestamp:   &timestamp.Timestamp{Seconds: 1543160299},
+							Attachments: map[string]string{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"},
+						}},
+						{Count: 1},
+					},
+				}},
+			}},
+		}},
+	}
+	require.NoError(t, consumer.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{requests, latency},
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+config.Endpoint+"/metrics", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/openmetrics-text;version=0.0.1,text/plain;q=0.5")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	blob, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, openMetricsContentType, res.Header.Get("Content-Type"))
+	want := `# TYPE test_latency histogram
+test_latency_bucket{foo="bar",le="1"} 2
+test_latency_bucket{foo="bar",le="5"} 4 # {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"} 3.5 1543160299
+test_latency_bucket{foo="bar",le="+Inf"} 5
+test_latency_count{foo="bar"} 5
+test_latency_sum{foo="bar"} 12.5
+test_latency_created{foo="bar"} 1543160298.5
+# TYPE test_requests counter
+# HELP test_requests Number of requests
+test_requests_total{foo="bar",http_method="GET"} 99
+test_requests_created{foo="bar",http_method="GET"} 1543160298.5
+# EOF
+`
+	assert.Equal(t, want, string(blob))
+
+	// The other scrapers still get the Prometheus text format.
+	res, err = http.Get("http://" + config.Endpoint + "/metrics")
+	require.NoError(t, err)
+	blob, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.NotContains(t, string(blob), "# EOF")
+	assert.Contains(t, string(blob), `test_requests_total{foo="bar",http_method="GET"} 99`)
+}
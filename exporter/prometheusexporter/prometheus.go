@@ -82,8 +82,9 @@ func PrometheusExportersFromViper(v *viper.Viper) (tps []consumer.TraceConsumer,
 
 	// The Prometheus metrics exporter has to run on the provided address
 	// as a server that'll be scraped by Prometheus.
+	om := newOpenMetricsHandler(pcfg.Namespace, pcfg.ConstLabels, pe)
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", pe)
+	mux.Handle("/metrics", om)
 
 	srv := &http.Server{Handler: mux}
 	go func() {
@@ -91,7 +92,7 @@ func PrometheusExportersFromViper(v *viper.Viper) (tps []consumer.TraceConsumer,
 	}()
 
 	doneFns = append(doneFns, ln.Close)
-	pexp := &prometheusExporter{exporter: pe}
+	pexp := &prometheusExporter{exporter: pe, openMetrics: om}
 	mps = append(mps, pexp)
 
 	return
@@ -100,7 +101,10 @@ func PrometheusExportersFromViper(v *viper.Viper) (tps []consumer.TraceConsumer,
 type prometheusExporter struct {
 	name     string
 	exporter *prometheus.Exporter
-	shutdown exporterhelper.Shutdown
+	// openMetrics serves the metrics to the scrapers accepting the
+	// OpenMetrics format.
+	openMetrics *openMetricsHandler
+	shutdown    exporterhelper.Shutdown
 }
 
 var _ consumer.MetricsConsumer = (*prometheusExporter)(nil)
@@ -108,6 +112,7 @@ var _ consumer.MetricsConsumer = (*prometheusExporter)(nil)
 func (pe *prometheusExporter) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
 	for _, metric := range md.Metrics {
 		_ = pe.exporter.ExportMetric(ctx, md.Node, md.Resource, metric)
+		pe.openMetrics.record(metric)
 	}
 	return nil
 }
@@ -0,0 +1,78 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Exporters[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters["sapm"]
+
+	// URL doesn't have a default value so set it directly.
+	defaultCfg := factory.CreateDefaultConfig().(*Config)
+	defaultCfg.URL = "http://some.location:7276/v2/trace"
+	assert.Equal(t, defaultCfg, e0)
+
+	expectedName := "sapm/2"
+
+	e1 := cfg.Exporters[expectedName]
+	expectedCfg := Config{
+		ExporterSettings: configmodels.ExporterSettings{
+			TypeVal: typeStr,
+			NameVal: expectedName,
+		},
+		URL:         "https://ingest.us0.signalfx.com/v2/trace",
+		AccessToken: "abc123",
+		Headers: map[string]string{
+			"added-entry": "added value",
+			"dot.test":    "test",
+		},
+		UserAgent: "custom-agent/1.0",
+		Timeout:   2 * time.Second,
+		CircuitBreaker: exporterhelper.CircuitBreakerSettings{
+			Enabled:      true,
+			FailureRatio: 0.5,
+			MinRequests:  5,
+			Window:       30 * time.Second,
+			OpenDuration: 30 * time.Second,
+		},
+		SortSpans: true,
+	}
+	assert.Equal(t, &expectedCfg, e1)
+
+	_, err = factory.CreateTraceExporter(zap.NewNop(), e1)
+	require.NoError(t, err)
+}
@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sapmexporter implements an exporter that sends trace data with the
+// SignalFx APM protocol (SAPM), to SignalFx or to any SAPM receiver.
+package sapmexporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/sapm"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
+)
+
+// Default timeout for http request in seconds
+const defaultHTTPTimeout = time.Second * 5
+
+// New returns a new SAPM exporter.
+// The exporterName is the name to be used in the observability of the exporter.
+// The url should be the URL of the SAPM endpoint, typically something like:
+// http://hostname:7276/v2/trace.
+// The accessToken, if not empty, is sent in the X-SF-Token header.
+// The headers parameter is used to add entries to the POST message sent to
+// the endpoint.
+// The timeout is used to set the timeout for the HTTP requests, if the
+// value is equal or smaller than zero the default of 5 seconds is used.
+// The circuitBreaker defines the circuit breaker of the exporter.
+// The sortSpans parameter sends the spans grouped by trace and ordered by
+// start time.
+// The propagateTraceContext parameter sends the trace context of the exporter
+// span with the requests.
+func New(
+	exporterName string,
+	url string,
+	accessToken string,
+	headers map[string]string,
+	timeout time.Duration,
+	circuitBreaker exporterhelper.CircuitBreakerSettings,
+	sortSpans bool,
+	propagateTraceContext bool,
+) (exporter.TraceExporter, error) {
+
+	clientTimeout := defaultHTTPTimeout
+	if timeout > 0 {
+		clientTimeout = timeout
+	}
+	s := &sapmSender{
+		url:         url,
+		accessToken: accessToken,
+		headers:     headers,
+		client:      &http.Client{Timeout: clientTimeout},
+		propagate:   propagateTraceContext,
+	}
+
+	exp, err := exporterhelper.NewTraceExporter(
+		exporterName,
+		s.pushTraceData,
+		exporterhelper.WithSpanName("otelsvc.exporter."+exporterName+".ConsumeTraceData"),
+		exporterhelper.WithRecordMetrics(true),
+		exporterhelper.WithCircuitBreaker(circuitBreaker),
+		exporterhelper.WithSortedSpans(sortSpans))
+
+	return exp, err
+}
+
+// sapmSender forwards spans as gzip compressed SAPM requests to a http
+// server.
+type sapmSender struct {
+	url         string
+	accessToken string
+	headers     map[string]string
+	client      *http.Client
+	propagate   bool
+}
+
+func (s *sapmSender) pushTraceData(
+	ctx context.Context,
+	td consumerdata.TraceData,
+) (droppedSpans int, err error) {
+
+	batch, err := jaegertranslator.OCProtoToJaegerProto(td)
+	if err != nil {
+		return len(td.Spans), consumererror.Permanent(err)
+	}
+
+	req, err := sapm.NewHTTPRequest(s.url, &sapm.PostSpansRequest{
+		Batches: []*model.Batch{batch},
+	})
+	if err != nil {
+		return len(td.Spans), consumererror.Permanent(err)
+	}
+
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if s.accessToken != "" {
+		req.Header.Set(sapm.AccessTokenHeader, s.accessToken)
+	}
+
+	if s.propagate {
+		exporterhelper.InjectHTTPTraceContext(ctx, req)
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return len(td.Spans), err
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf(
+			"HTTP %d %q",
+			resp.StatusCode,
+			http.StatusText(resp.StatusCode))
+		return len(td.Spans), err
+	}
+
+	return 0, nil
+}
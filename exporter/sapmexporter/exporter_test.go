@@ -0,0 +1,144 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/sapm"
+)
+
+func testTraceData() consumerdata.TraceData {
+	return consumerdata.TraceData{
+		Node: &commonpb.Node{
+			ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+		},
+		Spans: []*tracepb.Span{
+			{
+				TraceId: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+				SpanId:  []byte{0, 0, 0, 0, 0, 0, 0, 2},
+				Name:    &tracepb.TruncatableString{Value: "a"},
+			},
+		},
+	}
+}
+
+func TestExporter_PushTraceData(t *testing.T) {
+	var gotReq *sapm.PostSpansRequest
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		gotHeader = r.Header
+		gotReq, err = sapm.ReadRequest(r, decompression.DefaultSettings())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	exp, err := New(typeStr, srv.URL+sapm.TracePath, "abc123", map[string]string{"added-entry": "added value"}, time.Second, exporterhelper.CircuitBreakerSettings{}, false, false)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+
+	assert.Equal(t, "abc123", gotHeader.Get(sapm.AccessTokenHeader))
+	assert.Equal(t, "added value", gotHeader.Get("added-entry"))
+	assert.Equal(t, sapm.GzipEncoding, gotHeader.Get("Content-Encoding"))
+	require.NotNil(t, gotReq)
+	require.Len(t, gotReq.Batches, 1)
+	assert.Equal(t, "frontend", gotReq.Batches[0].Process.ServiceName)
+	require.Len(t, gotReq.Batches[0].Spans, 1)
+	assert.Equal(t, "a", gotReq.Batches[0].Spans[0].OperationName)
+}
+
+func TestExporter_NoAccessToken(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer srv.Close()
+
+	exp, err := New(typeStr, srv.URL+sapm.TracePath, "", nil, time.Second, exporterhelper.CircuitBreakerSettings{}, false, false)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+	_, ok := gotHeader[sapm.AccessTokenHeader]
+	assert.False(t, ok)
+}
+
+func TestExporter_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	exp, err := New(typeStr, srv.URL+sapm.TracePath, "wrong", nil, time.Second, exporterhelper.CircuitBreakerSettings{}, false, false)
+	require.NoError(t, err)
+	assert.Error(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+}
+
+func TestNew_EmptyExporterName(t *testing.T) {
+	_, err := New("", "http://a.test.dom:7276/v2/trace", "", nil, 0, exporterhelper.CircuitBreakerSettings{}, false, false)
+	assert.Error(t, err)
+}
+
+func TestExporter_UserAgent(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer srv.Close()
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.URL = srv.URL + sapm.TracePath
+	cfg.Headers = map[string]string{"user-agent": "replaced", "added-entry": "added value"}
+	cfg.UserAgent = "custom-agent/1.0"
+	exp, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+
+	assert.Equal(t, "custom-agent/1.0", gotHeader.Get("User-Agent"))
+	assert.Equal(t, "added value", gotHeader.Get("added-entry"))
+}
+
+func TestExporter_PropagateTraceContext(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer srv.Close()
+
+	exp, err := New(typeStr, srv.URL+sapm.TracePath, "", nil, time.Second, exporterhelper.CircuitBreakerSettings{}, false, true)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+	assert.NotEmpty(t, gotHeader.Get("traceparent"))
+
+	exp, err = New(typeStr, srv.URL+sapm.TracePath, "", nil, time.Second, exporterhelper.CircuitBreakerSettings{}, false, false)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeTraceData(context.Background(), testTraceData()))
+	assert.Empty(t, gotHeader.Get("traceparent"))
+}
@@ -0,0 +1,55 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the store-and-forward exporter.
+type Config struct {
+	configmodels.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	// Exporter is the full name of the exporter the stored batches are
+	// forwarded to.
+	Exporter string `mapstructure:"exporter"`
+
+	// Directory is the directory where the batches are stored, it must not be
+	// shared with other store-and-forward exporters.
+	Directory string `mapstructure:"directory"`
+
+	// SegmentSize is the size in bytes above which a new segment file is
+	// started.
+	SegmentSize int64 `mapstructure:"segment-size"`
+
+	// MaxSize is the maximum size in bytes of the stored batches of each data
+	// type. Above it the oldest segments are deleted, even if not forwarded.
+	MaxSize int64 `mapstructure:"max-size"`
+
+	// MaxAge is the maximum age of the stored batches, the older segments are
+	// deleted even if not forwarded. Zero means no limit.
+	MaxAge time.Duration `mapstructure:"max-age"`
+
+	// RetryInterval is the delay before forwarding again a batch the exporter
+	// failed to send.
+	RetryInterval time.Duration `mapstructure:"retry-interval"`
+
+	// CapacityWarningRatio is the ratio of the maximum size, and of the
+	// maximum age, above which a warning is logged as batches are about to be
+	// dropped by the retention. Zero disables the warning.
+	CapacityWarningRatio float64 `mapstructure:"capacity-warning-ratio"`
+}
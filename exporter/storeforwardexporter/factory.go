@@ -0,0 +1,140 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "store-and-forward"
+
+	defaultSegmentSize   = 8 << 20
+	defaultMaxSize       = 1 << 30
+	defaultRetryInterval = 5 * time.Second
+
+	defaultCapacityWarningRatio = 0.8
+)
+
+var errNotWrapped = errors.New("store-and-forward exporter must be created with the exporter it wraps")
+
+// Factory is the factory for the store-and-forward exporter.
+type Factory struct {
+}
+
+var _ exporter.WrapperFactory = (*Factory)(nil)
+
+// Type gets the type of the Exporter config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for exporter.
+func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
+	return &Config{
+		ExporterSettings: configmodels.ExporterSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		SegmentSize:   defaultSegmentSize,
+		MaxSize:       defaultMaxSize,
+		RetryInterval: defaultRetryInterval,
+
+		CapacityWarningRatio: defaultCapacityWarningRatio,
+	}
+}
+
+// WrappedExporters returns the full name of the exporter the batches are
+// forwarded to.
+func (f *Factory) WrappedExporters(cfg configmodels.Exporter) []string {
+	expCfg := cfg.(*Config)
+	if expCfg.Exporter == "" {
+		return nil
+	}
+	return []string{expCfg.Exporter}
+}
+
+// CreateTraceExporter always fails, the store-and-forward exporter needs the
+// exporter it wraps, see CreateWrapperTraceExporter.
+func (f *Factory) CreateTraceExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+) (exporter.TraceExporter, error) {
+	return nil, errNotWrapped
+}
+
+// CreateMetricsExporter always fails, the store-and-forward exporter needs the
+// exporter it wraps, see CreateWrapperMetricsExporter.
+func (f *Factory) CreateMetricsExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+) (exporter.MetricsExporter, error) {
+	return nil, errNotWrapped
+}
+
+// CreateWrapperTraceExporter creates a trace exporter storing the batches on
+// disk and forwarding them to the wrapped exporter.
+func (f *Factory) CreateWrapperTraceExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+	wrapped map[string]exporter.TraceExporter,
+) (exporter.TraceExporter, error) {
+	expCfg := config.(*Config)
+	if err := validate(expCfg); err != nil {
+		return nil, err
+	}
+	return NewTraceExporter(logger, expCfg, wrapped[expCfg.Exporter])
+}
+
+// CreateWrapperMetricsExporter creates a metrics exporter storing the batches
+// on disk and forwarding them to the wrapped exporter.
+func (f *Factory) CreateWrapperMetricsExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+	wrapped map[string]exporter.MetricsExporter,
+) (exporter.MetricsExporter, error) {
+	expCfg := config.(*Config)
+	if err := validate(expCfg); err != nil {
+		return nil, err
+	}
+	return NewMetricsExporter(logger, expCfg, wrapped[expCfg.Exporter])
+}
+
+func validate(cfg *Config) error {
+	if cfg.Exporter == "" {
+		return fmt.Errorf("%q config requires an \"exporter\"", cfg.Name())
+	}
+	if cfg.Directory == "" {
+		return fmt.Errorf("%q config requires a \"directory\"", cfg.Name())
+	}
+	if cfg.SegmentSize <= 0 || cfg.MaxSize <= 0 {
+		return fmt.Errorf("%q config requires positive values for \"segment-size\" and \"max-size\"", cfg.Name())
+	}
+	if cfg.MaxAge < 0 || cfg.RetryInterval <= 0 {
+		return fmt.Errorf("%q config requires a non-negative \"max-age\" and a positive \"retry-interval\"", cfg.Name())
+	}
+	if cfg.CapacityWarningRatio < 0 || cfg.CapacityWarningRatio > 1 {
+		return fmt.Errorf("%q config requires a \"capacity-warning-ratio\" between 0 and 1", cfg.Name())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.Empty(t, factory.WrappedExporters(cfg))
+}
+
+func TestCreateNotWrapped(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+
+	_, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	_, err = factory.CreateMetricsExporter(zap.NewNop(), cfg)
+	assert.Error(t, err)
+}
+
+func TestCreateWrapperExporters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store-and-forward")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Exporter = "backend"
+	cfg.Directory = dir
+
+	te, err := factory.CreateWrapperTraceExporter(zap.NewNop(), cfg, map[string]exporter.TraceExporter{
+		"backend": exportertest.NewNopTraceExporter(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, typeStr, te.Name())
+	assert.NoError(t, te.Shutdown())
+
+	me, err := factory.CreateWrapperMetricsExporter(zap.NewNop(), cfg, map[string]exporter.MetricsExporter{
+		"backend": exportertest.NewNopMetricsExporter(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, typeStr, me.Name())
+	assert.NoError(t, me.Shutdown())
+}
+
+func TestCreateWrapperInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{"no_exporter", func(cfg *Config) { cfg.Exporter = "" }},
+		{"no_directory", func(cfg *Config) { cfg.Directory = "" }},
+		{"no_segment_size", func(cfg *Config) { cfg.SegmentSize = 0 }},
+		{"no_max_size", func(cfg *Config) { cfg.MaxSize = 0 }},
+		{"negative_max_age", func(cfg *Config) { cfg.MaxAge = -1 }},
+		{"no_retry_interval", func(cfg *Config) { cfg.RetryInterval = 0 }},
+		{"capacity_warning_ratio_above_one", func(cfg *Config) { cfg.CapacityWarningRatio = 1.5 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Exporter = "backend"
+			cfg.Directory = "unused"
+			tt.modify(cfg)
+
+			wrapped := map[string]exporter.TraceExporter{
+				"backend": exportertest.NewNopTraceExporter(),
+			}
+			_, err := factory.CreateWrapperTraceExporter(zap.NewNop(), cfg, wrapped)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+)
+
+var (
+	tagDataTypeKey, _ = tag.NewKey("data_type")
+
+	statPendingBytes   = stats.Int64("store_forward_pending_bytes", "Number of bytes of the stored batches not forwarded yet", stats.UnitBytes)
+	statDiskUsageBytes = stats.Int64("store_forward_disk_usage", "Number of bytes of the segment files", stats.UnitBytes)
+	statOldestBatchAge = stats.Int64("store_forward_oldest_batch_age", "Age (in milliseconds) of the oldest segment holding batches not forwarded yet", stats.UnitMilliseconds)
+	statAppendFailures = stats.Int64("store_forward_append_failures", "Number of batches that could not be stored", stats.UnitDimensionless)
+	statDroppedBatches = stats.Int64("store_forward_dropped_batches", "Number of stored batches dropped because they can't be forwarded", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to the store-and-forward
+// exporter.
+func MetricViews(level telemetry.Level) []*view.View {
+	if level == telemetry.None {
+		return nil
+	}
+
+	tagKeys := []tag.Key{observability.TagKeyExporter, tagDataTypeKey}
+
+	pendingBytesView := &view.View{
+		Name:        statPendingBytes.Name(),
+		Measure:     statPendingBytes,
+		Description: statPendingBytes.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.LastValue(),
+	}
+	diskUsageView := &view.View{
+		Name:        statDiskUsageBytes.Name(),
+		Measure:     statDiskUsageBytes,
+		Description: statDiskUsageBytes.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.LastValue(),
+	}
+	oldestBatchAgeView := &view.View{
+		Name:        statOldestBatchAge.Name(),
+		Measure:     statOldestBatchAge,
+		Description: statOldestBatchAge.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.LastValue(),
+	}
+	appendFailuresView := &view.View{
+		Name:        statAppendFailures.Name(),
+		Measure:     statAppendFailures,
+		Description: statAppendFailures.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.Sum(),
+	}
+	droppedBatchesView := &view.View{
+		Name:        statDroppedBatches.Name(),
+		Measure:     statDroppedBatches,
+		Description: statDroppedBatches.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{pendingBytesView, diskUsageView, oldestBatchAgeView, appendFailuresView, droppedBatchesView}
+}
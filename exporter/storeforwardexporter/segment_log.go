@@ -0,0 +1,436 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	segmentExt     = ".seg"
+	cursorFile     = "cursor"
+	recordHeaderSz = 8
+)
+
+var errRecordTooLarge = errors.New("record larger than the maximum size of the stored data")
+
+// position identifies a record by its segment and its offset in the segment.
+type position struct {
+	segment uint64
+	offset  int64
+}
+
+type segment struct {
+	id      uint64
+	size    int64
+	modTime time.Time
+}
+
+// segmentLog stores records in segment files of a directory and keeps the
+// position of the next record to forward in the cursor file, so that the
+// records not forwarded survive restarts. Records are framed by their length
+// and CRC-32, a record truncated by a crash ends its segment.
+//
+// Records are appended to the last segment, a new one being started on open
+// and when the last one exceeds the segment size. The forwarded segments are
+// deleted, as well as the oldest ones when the retention limits are exceeded.
+type segmentLog struct {
+	logger      *zap.Logger
+	dir         string
+	segmentSize int64
+	maxSize     int64
+	maxAge      time.Duration
+	now         func() time.Time
+
+	// notify is signaled when a record is appended.
+	notify chan struct{}
+
+	mu       sync.Mutex
+	segments []*segment
+	active   *os.File
+	cursor   position
+	closed   bool
+
+	// reader is the segment file being read, only used by next.
+	reader   *os.File
+	readerID uint64
+}
+
+func openSegmentLog(logger *zap.Logger, dir string, segmentSize, maxSize int64, maxAge time.Duration) (*segmentLog, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	l := &segmentLog{
+		logger:      logger,
+		dir:         dir,
+		segmentSize: segmentSize,
+		maxSize:     maxSize,
+		maxAge:      maxAge,
+		now:         time.Now,
+		notify:      make(chan struct{}, 1),
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		l.segments = append(l.segments, &segment{id: id, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i].id < l.segments[j].id })
+
+	if l.cursor, err = l.readCursor(); err != nil {
+		return nil, err
+	}
+	// Delete the segments forwarded before a crash.
+	for len(l.segments) > 0 && l.segments[0].id < l.cursor.segment {
+		l.removeSegment(0)
+	}
+	if len(l.segments) > 0 && l.cursor.segment != l.segments[0].id {
+		l.cursor = position{segment: l.segments[0].id}
+	}
+
+	next := uint64(1)
+	if len(l.segments) > 0 {
+		next = l.segments[len(l.segments)-1].id + 1
+	}
+	if len(l.segments) == 0 {
+		l.cursor = position{segment: next}
+	}
+	if err := l.startSegment(next); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *segmentLog) segmentPath(id uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+func (l *segmentLog) readCursor() (position, error) {
+	b, err := ioutil.ReadFile(filepath.Join(l.dir, cursorFile))
+	if os.IsNotExist(err) {
+		return position{}, nil
+	}
+	if err != nil {
+		return position{}, err
+	}
+	if len(b) != 16 {
+		l.logger.Warn("Ignoring invalid cursor file", zap.String("directory", l.dir))
+		return position{}, nil
+	}
+	return position{
+		segment: binary.BigEndian.Uint64(b[:8]),
+		offset:  int64(binary.BigEndian.Uint64(b[8:])),
+	}, nil
+}
+
+// writeCursor must be called with the lock held.
+func (l *segmentLog) writeCursor() error {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], l.cursor.segment)
+	binary.BigEndian.PutUint64(b[8:], uint64(l.cursor.offset))
+	tmp := filepath.Join(l.dir, cursorFile+".tmp")
+	if err := ioutil.WriteFile(tmp, b[:], 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(l.dir, cursorFile))
+}
+
+// startSegment must be called with the lock held.
+func (l *segmentLog) startSegment(id uint64) error {
+	f, err := os.OpenFile(l.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if l.active != nil {
+		l.active.Close()
+	}
+	l.active = f
+	l.segments = append(l.segments, &segment{id: id, modTime: l.now()})
+	return nil
+}
+
+// append stores a record at the end of the log.
+func (l *segmentLog) append(payload []byte) error {
+	size := int64(recordHeaderSz + len(payload))
+	if size > l.maxSize {
+		return errRecordTooLarge
+	}
+	record := make([]byte, size)
+	binary.BigEndian.PutUint32(record[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSz:], payload)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("store-and-forward log is closed")
+	}
+	last := l.segments[len(l.segments)-1]
+	if last.size > 0 && last.size+size > l.segmentSize {
+		if err := l.startSegment(last.id + 1); err != nil {
+			return err
+		}
+		last = l.segments[len(l.segments)-1]
+	}
+	n, err := l.active.Write(record)
+	last.size += int64(n)
+	last.modTime = l.now()
+	if err != nil {
+		// A partial record ends the segment, start a new one for the next
+		// records.
+		if n > 0 {
+			if serr := l.startSegment(last.id + 1); serr != nil {
+				l.logger.Warn("Failed to start a new segment", zap.String("directory", l.dir), zap.Error(serr))
+			}
+		}
+		return err
+	}
+	l.enforceRetention()
+
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// enforceRetention deletes the oldest segments while the retention limits
+// are exceeded, the segment being written is never deleted. It must be called
+// with the lock held.
+func (l *segmentLog) enforceRetention() {
+	var total int64
+	for _, s := range l.segments {
+		total += s.size
+	}
+	now := l.now()
+	for len(l.segments) > 1 {
+		oldest := l.segments[0]
+		if total <= l.maxSize && (l.maxAge <= 0 || now.Sub(oldest.modTime) <= l.maxAge) {
+			return
+		}
+		if l.cursor.segment <= oldest.id {
+			l.logger.Warn("Retention limits exceeded, dropping batches not forwarded",
+				zap.String("directory", l.dir), zap.Int64("bytes", oldest.size-l.cursor.offset))
+			l.cursor = position{segment: l.segments[1].id}
+			if err := l.writeCursor(); err != nil {
+				l.logger.Warn("Failed to write the cursor", zap.String("directory", l.dir), zap.Error(err))
+			}
+		}
+		total -= oldest.size
+		l.removeSegment(0)
+	}
+}
+
+// removeSegment deletes the i-th segment, it must be called with the lock
+// held.
+func (l *segmentLog) removeSegment(i int) {
+	if err := os.Remove(l.segmentPath(l.segments[i].id)); err != nil && !os.IsNotExist(err) {
+		l.logger.Warn("Failed to delete a segment", zap.String("directory", l.dir), zap.Error(err))
+	}
+	l.segments = append(l.segments[:i], l.segments[i+1:]...)
+}
+
+// next returns the record at the cursor and the position following it, or a
+// nil record if all the records were read. It must only be called by the
+// forwarder.
+func (l *segmentLog) next() ([]byte, position, position, error) {
+	for {
+		l.mu.Lock()
+		i := l.segmentIndex(l.cursor.segment)
+		from := l.cursor
+		size := l.segments[i].size
+		sealed := i < len(l.segments)-1
+		l.mu.Unlock()
+
+		if from.offset >= size {
+			if !sealed {
+				return nil, from, from, nil
+			}
+			l.skipSegment(from)
+			continue
+		}
+
+		record, err := l.read(from, size)
+		if err == nil {
+			return record, from, position{segment: from.segment, offset: from.offset + int64(recordHeaderSz+len(record))}, nil
+		}
+		if os.IsNotExist(err) {
+			if !sealed {
+				return nil, from, from, err
+			}
+			l.skipSegment(from)
+			continue
+		}
+		// The rest of the segment is unreadable, skip it.
+		l.logger.Warn("Skipping the corrupted end of a segment",
+			zap.String("directory", l.dir), zap.Uint64("segment", from.segment), zap.Error(err))
+		l.ack(from, position{segment: from.segment, offset: size})
+	}
+}
+
+func (l *segmentLog) read(at position, size int64) ([]byte, error) {
+	if l.reader == nil || l.readerID != at.segment {
+		if l.reader != nil {
+			l.reader.Close()
+			l.reader = nil
+		}
+		f, err := os.Open(l.segmentPath(at.segment))
+		if err != nil {
+			return nil, err
+		}
+		l.reader, l.readerID = f, at.segment
+	}
+
+	var header [recordHeaderSz]byte
+	if _, err := l.reader.ReadAt(header[:], at.offset); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[:4]))
+	if at.offset+recordHeaderSz+length > size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	record := make([]byte, length)
+	if _, err := l.reader.ReadAt(record, at.offset+recordHeaderSz); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, errors.New("checksum mismatch")
+	}
+	return record, nil
+}
+
+// ack moves the cursor past a forwarded record, unless the retention moved it
+// meanwhile.
+func (l *segmentLog) ack(from, to position) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cursor != from {
+		return
+	}
+	l.cursor = to
+	if err := l.writeCursor(); err != nil {
+		l.logger.Warn("Failed to write the cursor", zap.String("directory", l.dir), zap.Error(err))
+	}
+}
+
+// segmentIndex returns the index of the segment with the given id, or of the
+// first segment following it if it was deleted. It must be called with the
+// lock held.
+func (l *segmentLog) segmentIndex(id uint64) int {
+	for i, s := range l.segments {
+		if s.id >= id {
+			if s.id != id {
+				l.cursor = position{segment: s.id}
+			}
+			return i
+		}
+	}
+	// Only happens if the directory was modified, the segment being written
+	// has the highest id.
+	i := len(l.segments) - 1
+	l.cursor = position{segment: l.segments[i].id}
+	return i
+}
+
+// skipSegment deletes the sealed segment at the cursor, all its records having
+// been forwarded, and moves the cursor to the next segment.
+func (l *segmentLog) skipSegment(at position) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cursor != at {
+		return
+	}
+	i := l.segmentIndex(at.segment)
+	if i == len(l.segments)-1 {
+		return
+	}
+	l.removeSegment(i)
+	l.cursor = position{segment: l.segments[i].id}
+	if err := l.writeCursor(); err != nil {
+		l.logger.Warn("Failed to write the cursor", zap.String("directory", l.dir), zap.Error(err))
+	}
+}
+
+// pending returns the number of bytes of the records not forwarded yet.
+func (l *segmentLog) pending() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var total int64
+	for _, s := range l.segments {
+		if s.id == l.cursor.segment {
+			total += s.size - l.cursor.offset
+		} else if s.id > l.cursor.segment {
+			total += s.size
+		}
+	}
+	return total
+}
+
+// usage returns the number of bytes of the segment files, the number of bytes
+// of the records not forwarded yet and the last write time of the oldest
+// segment holding records not forwarded, zero if all were forwarded. The
+// retention age limit applies to that time.
+func (l *segmentLog) usage() (diskBytes, pendingBytes int64, oldest time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.segments {
+		diskBytes += s.size
+		var pending int64
+		if s.id == l.cursor.segment {
+			pending = s.size - l.cursor.offset
+		} else if s.id > l.cursor.segment {
+			pending = s.size
+		}
+		if pending > 0 && oldest.IsZero() {
+			oldest = s.modTime
+		}
+		pendingBytes += pending
+	}
+	return diskBytes, pendingBytes, oldest
+}
+
+func (l *segmentLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	if l.reader != nil {
+		l.reader.Close()
+	}
+	return l.active.Close()
+}
@@ -0,0 +1,186 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func openTestLog(t *testing.T, dir string, segmentSize, maxSize int64, maxAge time.Duration) *segmentLog {
+	l, err := openSegmentLog(zap.NewNop(), dir, segmentSize, maxSize, maxAge)
+	require.NoError(t, err)
+	return l
+}
+
+// readAll reads and acknowledges all the records.
+func readAll(t *testing.T, l *segmentLog) []string {
+	var records []string
+	for {
+		record, from, to, err := l.next()
+		require.NoError(t, err)
+		if record == nil {
+			return records
+		}
+		records = append(records, string(record))
+		l.ack(from, to)
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	return files
+}
+
+func TestSegmentLog_Rotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Every record of 10 bytes starts a new segment.
+	l := openTestLog(t, dir, 15, 1000, 0)
+	for _, r := range []string{"aa", "bb", "cc"} {
+		require.NoError(t, l.append([]byte(r)))
+	}
+	assert.Len(t, segmentFiles(t, dir), 3)
+	assert.Equal(t, int64(30), l.pending())
+
+	assert.Equal(t, []string{"aa", "bb", "cc"}, readAll(t, l))
+	assert.Equal(t, int64(0), l.pending())
+	// The forwarded segments are deleted, except the one being written.
+	assert.Len(t, segmentFiles(t, dir), 1)
+	require.NoError(t, l.close())
+}
+
+func TestSegmentLog_RetentionSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := openTestLog(t, dir, 15, 25, 0)
+	for _, r := range []string{"aa", "bb", "cc", "dd"} {
+		require.NoError(t, l.append([]byte(r)))
+	}
+	assert.Equal(t, errRecordTooLarge, l.append(make([]byte, 20)))
+
+	// The oldest segments are dropped to stay under the maximum size.
+	assert.Equal(t, []string{"cc", "dd"}, readAll(t, l))
+	require.NoError(t, l.close())
+}
+
+func TestSegmentLog_RetentionAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Unix(1000, 0)
+	l := openTestLog(t, dir, 15, 1000, time.Hour)
+	l.now = func() time.Time { return now }
+	require.NoError(t, l.append([]byte("aa")))
+	require.NoError(t, l.append([]byte("bb")))
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, l.append([]byte("cc")))
+
+	assert.Equal(t, []string{"cc"}, readAll(t, l))
+	require.NoError(t, l.close())
+}
+
+func TestSegmentLog_Reopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := openTestLog(t, dir, 1000, 1000, 0)
+	for _, r := range []string{"aa", "bb", "cc"} {
+		require.NoError(t, l.append([]byte(r)))
+	}
+	record, from, to, err := l.next()
+	require.NoError(t, err)
+	assert.Equal(t, "aa", string(record))
+	l.ack(from, to)
+	require.NoError(t, l.close())
+
+	l = openTestLog(t, dir, 1000, 1000, 0)
+	require.NoError(t, l.append([]byte("dd")))
+	assert.Equal(t, []string{"bb", "cc", "dd"}, readAll(t, l))
+	require.NoError(t, l.close())
+}
+
+func TestSegmentLog_Corrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := openTestLog(t, dir, 1000, 1000, 0)
+	require.NoError(t, l.append([]byte("aa")))
+	require.NoError(t, l.append([]byte("bb")))
+	require.NoError(t, l.close())
+
+	// Corrupt the second record, e.g. by a crash while writing it.
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+	b, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	b[len(b)-1] = 'x'
+	require.NoError(t, ioutil.WriteFile(files[0], b, 0600))
+
+	l = openTestLog(t, dir, 1000, 1000, 0)
+	require.NoError(t, l.append([]byte("cc")))
+	assert.Equal(t, []string{"aa", "cc"}, readAll(t, l))
+	require.NoError(t, l.close())
+	assert.Error(t, l.append([]byte("dd")))
+}
+
+func TestSegmentLog_Usage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment-log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Unix(1000, 0)
+	l := openTestLog(t, dir, 15, 1000, 0)
+	l.now = func() time.Time { return now }
+	for _, r := range []string{"aa", "bb", "cc"} {
+		require.NoError(t, l.append([]byte(r)))
+		now = now.Add(time.Minute)
+	}
+	diskBytes, pendingBytes, oldest := l.usage()
+	assert.Equal(t, int64(30), diskBytes)
+	assert.Equal(t, int64(30), pendingBytes)
+	assert.Equal(t, time.Unix(1000, 0), oldest)
+
+	record, from, to, err := l.next()
+	require.NoError(t, err)
+	assert.Equal(t, "aa", string(record))
+	l.ack(from, to)
+	diskBytes, pendingBytes, oldest = l.usage()
+	assert.Equal(t, int64(30), diskBytes)
+	assert.Equal(t, int64(20), pendingBytes)
+	assert.Equal(t, time.Unix(1060, 0), oldest)
+
+	readAll(t, l)
+	diskBytes, pendingBytes, oldest = l.usage()
+	assert.Equal(t, int64(10), diskBytes)
+	assert.Equal(t, int64(0), pendingBytes)
+	assert.True(t, oldest.IsZero())
+	require.NoError(t, l.close())
+}
@@ -0,0 +1,346 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+)
+
+// reportInterval is the interval at which the usage of the stored batches is
+// recorded and compared to the retention limits.
+const reportInterval = 10 * time.Second
+
+var errInvalidRecord = errors.New("invalid stored batch")
+
+// forwarder stores the batches in a segment log and forwards them in order in
+// the background, retrying each batch until the wrapped exporter accepts it.
+// Batches are forwarded at least once: a batch being forwarded on shutdown is
+// forwarded again after restart.
+type forwarder struct {
+	name          string
+	logger        *zap.Logger
+	log           *segmentLog
+	retryInterval time.Duration
+	// push decodes a stored batch and sends it to the wrapped exporter. It
+	// returns errInvalidRecord if the batch can't be decoded.
+	push func(ctx context.Context, record []byte) error
+
+	// statsCtx holds the tags of the recorded metrics.
+	statsCtx             context.Context
+	maxSize              int64
+	maxAge               time.Duration
+	capacityWarningRatio float64
+	// aboveWarning is true while the usage is above the warning threshold,
+	// it is only used by report.
+	aboveWarning bool
+
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	shutdownOnce sync.Once
+}
+
+func newForwarder(
+	logger *zap.Logger,
+	cfg *Config,
+	dataType string,
+	push func(ctx context.Context, record []byte) error,
+) (*forwarder, error) {
+	log, err := openSegmentLog(logger, filepath.Join(cfg.Directory, dataType), cfg.SegmentSize, cfg.MaxSize, cfg.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+	statsCtx, _ := tag.New(context.Background(),
+		tag.Upsert(observability.TagKeyExporter, cfg.Name()),
+		tag.Upsert(tagDataTypeKey, dataType))
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &forwarder{
+		name:                 cfg.Name(),
+		logger:               logger,
+		log:                  log,
+		retryInterval:        cfg.RetryInterval,
+		push:                 push,
+		statsCtx:             statsCtx,
+		maxSize:              cfg.MaxSize,
+		maxAge:               cfg.MaxAge,
+		capacityWarningRatio: cfg.CapacityWarningRatio,
+		ctx:                  ctx,
+		cancel:               cancel,
+	}
+	f.wg.Add(2)
+	go f.run()
+	go f.reportLoop()
+	return f, nil
+}
+
+// store appends a batch to the segment log.
+func (f *forwarder) store(record []byte) error {
+	err := f.log.append(record)
+	if err != nil {
+		stats.Record(f.statsCtx, statAppendFailures.M(1))
+	}
+	return err
+}
+
+func (f *forwarder) reportLoop() {
+	defer f.wg.Done()
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			f.report(now)
+		case <-f.ctx.Done():
+			return
+		}
+	}
+}
+
+// report records the usage of the stored batches and logs a warning when it
+// crosses the capacity warning threshold of the retention limits.
+func (f *forwarder) report(now time.Time) {
+	diskBytes, pendingBytes, oldest := f.log.usage()
+	var oldestAge time.Duration
+	if !oldest.IsZero() {
+		oldestAge = now.Sub(oldest)
+	}
+	stats.Record(f.statsCtx,
+		statPendingBytes.M(pendingBytes),
+		statDiskUsageBytes.M(diskBytes),
+		statOldestBatchAge.M(int64(oldestAge/time.Millisecond)))
+
+	if f.capacityWarningRatio <= 0 {
+		return
+	}
+	above := float64(diskBytes) >= f.capacityWarningRatio*float64(f.maxSize) ||
+		(f.maxAge > 0 && float64(oldestAge) >= f.capacityWarningRatio*float64(f.maxAge))
+	switch {
+	case above && !f.aboveWarning:
+		f.aboveWarning = true
+		f.logger.Warn("Stored batches are close to the retention limits, batches not forwarded will be dropped",
+			zap.String("exporter", f.name),
+			zap.Int64("disk-usage", diskBytes),
+			zap.Int64("max-size", f.maxSize),
+			zap.Int64("pending-bytes", pendingBytes),
+			zap.Duration("oldest-batch-age", oldestAge),
+			zap.Duration("max-age", f.maxAge))
+	case !above && f.aboveWarning:
+		f.aboveWarning = false
+		f.logger.Info("Stored batches are back below the retention warning threshold",
+			zap.String("exporter", f.name),
+			zap.Int64("disk-usage", diskBytes),
+			zap.Duration("oldest-batch-age", oldestAge))
+	}
+}
+
+func (f *forwarder) run() {
+	defer f.wg.Done()
+	for {
+		record, from, to, err := f.log.next()
+		if err != nil {
+			f.logger.Warn("Failed to read the stored batches", zap.String("exporter", f.name), zap.Error(err))
+			if !f.wait() {
+				return
+			}
+			continue
+		}
+		if record == nil {
+			select {
+			case <-f.log.notify:
+				continue
+			case <-f.ctx.Done():
+				return
+			}
+		}
+
+		for {
+			err := f.push(f.ctx, record)
+			if err == nil {
+				break
+			}
+			if err == errInvalidRecord {
+				f.logger.Warn("Dropping a stored batch that can't be decoded", zap.String("exporter", f.name))
+				stats.Record(f.statsCtx, statDroppedBatches.M(1))
+				break
+			}
+			// Retrying the batches rejected permanently would block the next
+			// ones until the retention drops them.
+			if consumererror.IsPermanent(err) {
+				f.logger.Warn("Dropping a stored batch rejected by the exporter",
+					zap.String("exporter", f.name), zap.Error(err))
+				stats.Record(f.statsCtx, statDroppedBatches.M(1))
+				break
+			}
+			if f.ctx.Err() != nil {
+				return
+			}
+			f.logger.Debug("Failed to forward a stored batch, retrying",
+				zap.String("exporter", f.name), zap.Int64("pending-bytes", f.log.pending()), zap.Error(err))
+			if !f.wait() {
+				return
+			}
+		}
+		f.log.ack(from, to)
+	}
+}
+
+// wait waits for the retry interval, it returns false on shutdown.
+func (f *forwarder) wait() bool {
+	timer := time.NewTimer(f.retryInterval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-f.ctx.Done():
+		return false
+	}
+}
+
+func (f *forwarder) Name() string {
+	return f.name
+}
+
+// Shutdown stops forwarding, cancelling the batch being forwarded. The
+// batches not forwarded stay stored. The wrapped exporter is shut down on its
+// own.
+func (f *forwarder) Shutdown() error {
+	var err error
+	f.shutdownOnce.Do(func() {
+		f.cancel()
+		f.wg.Wait()
+		err = f.log.close()
+	})
+	return err
+}
+
+type traceExporter struct {
+	*forwarder
+}
+
+var _ exporter.TraceExporter = (*traceExporter)(nil)
+
+// NewTraceExporter creates a trace exporter storing the batches on disk and
+// forwarding them to next.
+func NewTraceExporter(logger *zap.Logger, cfg *Config, next exporter.TraceExporter) (exporter.TraceExporter, error) {
+	if next == nil {
+		return nil, errNotWrapped
+	}
+	push := func(ctx context.Context, record []byte) error {
+		td, err := decodeTraceData(record)
+		if err != nil {
+			return errInvalidRecord
+		}
+		return next.ConsumeTraceData(ctx, td)
+	}
+	f, err := newForwarder(logger, cfg, "traces", push)
+	if err != nil {
+		return nil, err
+	}
+	return &traceExporter{forwarder: f}, nil
+}
+
+// ConsumeTraceData stores the batch, it returns once the batch is written to
+// the segment file.
+func (te *traceExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	record, err := encodeTraceData(td)
+	if err != nil {
+		return err
+	}
+	return te.store(record)
+}
+
+// encodeTraceData encodes the batch as its source format, prefixed by its
+// length, followed by the batch as an OpenCensus export request.
+func encodeTraceData(td consumerdata.TraceData) ([]byte, error) {
+	b, err := proto.Marshal(&agenttracepb.ExportTraceServiceRequest{Node: td.Node, Resource: td.Resource, Spans: td.Spans})
+	if err != nil {
+		return nil, err
+	}
+	record := make([]byte, binary.MaxVarintLen64+len(td.SourceFormat)+len(b))
+	n := binary.PutUvarint(record, uint64(len(td.SourceFormat)))
+	n += copy(record[n:], td.SourceFormat)
+	n += copy(record[n:], b)
+	return record[:n], nil
+}
+
+func decodeTraceData(record []byte) (consumerdata.TraceData, error) {
+	length, n := binary.Uvarint(record)
+	if n <= 0 || uint64(len(record)-n) < length {
+		return consumerdata.TraceData{}, errInvalidRecord
+	}
+	var req agenttracepb.ExportTraceServiceRequest
+	if err := proto.Unmarshal(record[n+int(length):], &req); err != nil {
+		return consumerdata.TraceData{}, err
+	}
+	return consumerdata.TraceData{
+		Node:         req.Node,
+		Resource:     req.Resource,
+		Spans:        req.Spans,
+		SourceFormat: string(record[n : n+int(length)]),
+	}, nil
+}
+
+type metricsExporter struct {
+	*forwarder
+}
+
+var _ exporter.MetricsExporter = (*metricsExporter)(nil)
+
+// NewMetricsExporter creates a metrics exporter storing the batches on disk
+// and forwarding them to next.
+func NewMetricsExporter(logger *zap.Logger, cfg *Config, next exporter.MetricsExporter) (exporter.MetricsExporter, error) {
+	if next == nil {
+		return nil, errNotWrapped
+	}
+	push := func(ctx context.Context, record []byte) error {
+		var req agentmetricspb.ExportMetricsServiceRequest
+		if err := proto.Unmarshal(record, &req); err != nil {
+			return errInvalidRecord
+		}
+		return next.ConsumeMetricsData(ctx, consumerdata.MetricsData{Node: req.Node, Resource: req.Resource, Metrics: req.Metrics})
+	}
+	f, err := newForwarder(logger, cfg, "metrics", push)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsExporter{forwarder: f}, nil
+}
+
+// ConsumeMetricsData stores the batch, it returns once the batch is written to
+// the segment file.
+func (me *metricsExporter) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	record, err := proto.Marshal(&agentmetricspb.ExportMetricsServiceRequest{Node: md.Node, Resource: md.Resource, Metrics: md.Metrics})
+	if err != nil {
+		return err
+	}
+	return me.store(record)
+}
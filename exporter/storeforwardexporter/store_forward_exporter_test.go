@@ -0,0 +1,279 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storeforwardexporter
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+func testConfig(t *testing.T) (*Config, func()) {
+	dir, err := ioutil.TempDir("", "store-and-forward")
+	require.NoError(t, err)
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Exporter = "backend"
+	cfg.Directory = dir
+	cfg.RetryInterval = time.Millisecond
+	return cfg, func() { os.RemoveAll(dir) }
+}
+
+func testTraceData(name string) consumerdata.TraceData {
+	return consumerdata.TraceData{
+		Node:         &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans:        []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: name}}},
+		SourceFormat: "jaeger",
+	}
+}
+
+// waitFor polls cond until it returns true or a second elapsed.
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 100 && !cond(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, cond(), "condition not met in time")
+}
+
+// flakyTraceExporter fails until it is repaired.
+type flakyTraceExporter struct {
+	exportertest.SinkTraceExporter
+	mu       sync.Mutex
+	failures int
+	broken   bool
+}
+
+func (fe *flakyTraceExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	fe.mu.Lock()
+	broken := fe.broken
+	if broken {
+		fe.failures++
+	}
+	fe.mu.Unlock()
+	if broken {
+		return errors.New("link down")
+	}
+	return fe.SinkTraceExporter.ConsumeTraceData(ctx, td)
+}
+
+func (fe *flakyTraceExporter) setBroken(broken bool) {
+	fe.mu.Lock()
+	fe.broken = broken
+	fe.mu.Unlock()
+}
+
+func (fe *flakyTraceExporter) numFailures() int {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	return fe.failures
+}
+
+func TestTraceExporter_Forward(t *testing.T) {
+	cfg, cleanup := testConfig(t)
+	defer cleanup()
+
+	next := &flakyTraceExporter{broken: true}
+	te, err := NewTraceExporter(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData(name)))
+	}
+	waitFor(t, func() bool { return next.numFailures() >= 3 })
+	assert.Len(t, next.AllTraces(), 0)
+
+	next.setBroken(false)
+	waitFor(t, func() bool { return len(next.AllTraces()) == 3 })
+	for i, name := range []string{"a", "b", "c"} {
+		assert.True(t, proto.Equal(testTraceData(name).Spans[0], next.AllTraces()[i].Spans[0]))
+		assert.Equal(t, "jaeger", next.AllTraces()[i].SourceFormat)
+		assert.Equal(t, "svc", next.AllTraces()[i].Node.ServiceInfo.Name)
+	}
+	require.NoError(t, te.Shutdown())
+}
+
+// rejectingTraceExporter rejects permanently the batches with a span of the
+// given name.
+type rejectingTraceExporter struct {
+	exportertest.SinkTraceExporter
+	reject string
+}
+
+func (re *rejectingTraceExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	if td.Spans[0].Name.Value == re.reject {
+		return consumererror.Permanent(errors.New("invalid batch"))
+	}
+	return re.SinkTraceExporter.ConsumeTraceData(ctx, td)
+}
+
+func TestTraceExporter_PermanentError(t *testing.T) {
+	cfg, cleanup := testConfig(t)
+	defer cleanup()
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	next := &rejectingTraceExporter{reject: "b"}
+	te, err := NewTraceExporter(zap.New(core), cfg, next)
+	require.NoError(t, err)
+
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData(name)))
+	}
+	// The rejected batch is dropped, the next ones are still forwarded.
+	waitFor(t, func() bool { return len(next.AllTraces()) == 2 })
+	require.NoError(t, te.Shutdown())
+	assert.Equal(t, "a", next.AllTraces()[0].Spans[0].Name.Value)
+	assert.Equal(t, "c", next.AllTraces()[1].Spans[0].Name.Value)
+	assert.Equal(t, 1, logs.FilterMessage("Dropping a stored batch rejected by the exporter").Len())
+
+	rows, err := view.RetrieveData(statDroppedBatches.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, float64(1), rows[0].Data.(*view.SumData).Value)
+}
+
+func TestTraceExporter_Restart(t *testing.T) {
+	cfg, cleanup := testConfig(t)
+	defer cleanup()
+
+	next := &flakyTraceExporter{broken: true}
+	te, err := NewTraceExporter(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+	require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData("a")))
+	require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData("b")))
+	require.NoError(t, te.Shutdown())
+	assert.Len(t, next.AllTraces(), 0)
+
+	// The batches stored before the restart are forwarded first.
+	sink := new(exportertest.SinkTraceExporter)
+	te, err = NewTraceExporter(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData("c")))
+	waitFor(t, func() bool { return len(sink.AllTraces()) == 3 })
+	require.NoError(t, te.Shutdown())
+	for i, name := range []string{"a", "b", "c"} {
+		assert.Equal(t, name, sink.AllTraces()[i].Spans[0].Name.Value)
+	}
+
+	// The forwarded batches are not forwarded again.
+	sink = new(exportertest.SinkTraceExporter)
+	te, err = NewTraceExporter(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData("d")))
+	waitFor(t, func() bool { return len(sink.AllTraces()) == 1 })
+	require.NoError(t, te.Shutdown())
+	assert.Equal(t, "d", sink.AllTraces()[0].Spans[0].Name.Value)
+}
+
+func TestTraceExporter_CapacityWarning(t *testing.T) {
+	cfg, cleanup := testConfig(t)
+	defer cleanup()
+	cfg.MaxSize = 1000
+	cfg.MaxAge = time.Hour
+	cfg.CapacityWarningRatio = 0.5
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	next := &flakyTraceExporter{broken: true}
+	te, err := NewTraceExporter(zap.New(core), cfg, next)
+	require.NoError(t, err)
+	defer te.Shutdown()
+	f := te.(*traceExporter).forwarder
+
+	require.NoError(t, te.ConsumeTraceData(context.Background(), testTraceData("a")))
+	f.report(time.Now())
+	assert.Equal(t, 0, logs.FilterMessageSnippet("retention limits").Len())
+
+	// The oldest batch not forwarded is close to the maximum age.
+	f.report(time.Now().Add(45 * time.Minute))
+	f.report(time.Now().Add(45 * time.Minute))
+	warnings := logs.FilterMessageSnippet("retention limits").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, cfg.Name(), warnings[0].ContextMap()["exporter"])
+	assert.True(t, warnings[0].ContextMap()["oldest-batch-age"].(time.Duration) >= 45*time.Minute)
+
+	next.setBroken(false)
+	waitFor(t, func() bool { return len(next.AllTraces()) == 1 })
+	waitFor(t, func() bool {
+		_, pendingBytes, _ := f.log.usage()
+		return pendingBytes == 0
+	})
+	f.report(time.Now().Add(45 * time.Minute))
+	assert.Equal(t, 1, logs.FilterMessageSnippet("back below").Len())
+}
+
+func TestMetricsExporter_Forward(t *testing.T) {
+	cfg, cleanup := testConfig(t)
+	defer cleanup()
+
+	sink := new(exportertest.SinkMetricsExporter)
+	me, err := NewMetricsExporter(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	md := consumerdata.MetricsData{
+		Node:    &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Metrics: []*metricspb.Metric{{MetricDescriptor: &metricspb.MetricDescriptor{Name: "m"}}},
+	}
+	require.NoError(t, me.ConsumeMetricsData(context.Background(), md))
+	waitFor(t, func() bool { return len(sink.AllMetrics()) == 1 })
+	require.NoError(t, me.Shutdown())
+
+	got := sink.AllMetrics()[0]
+	assert.True(t, proto.Equal(md.Node, got.Node))
+	assert.Equal(t, "m", got.Metrics[0].MetricDescriptor.Name)
+}
+
+func TestNewExporter_NotWrapped(t *testing.T) {
+	cfg, cleanup := testConfig(t)
+	defer cleanup()
+
+	_, err := NewTraceExporter(zap.NewNop(), cfg, nil)
+	assert.Equal(t, errNotWrapped, err)
+	_, err = NewMetricsExporter(zap.NewNop(), cfg, nil)
+	assert.Equal(t, errNotWrapped, err)
+}
+
+func TestTraceDataEncoding(t *testing.T) {
+	td := testTraceData("a")
+	record, err := encodeTraceData(td)
+	require.NoError(t, err)
+	got, err := decodeTraceData(record)
+	require.NoError(t, err)
+	assert.Equal(t, td.SourceFormat, got.SourceFormat)
+	assert.True(t, proto.Equal(td.Spans[0], got.Spans[0]))
+
+	_, err = decodeTraceData([]byte{10, 'a'})
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teeexporter
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the tee exporter.
+type Config struct {
+	configmodels.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	// Primary is the full name of the exporter receiving all the batches, its
+	// result is the result of the tee exporter.
+	Primary string `mapstructure:"primary"`
+
+	// Shadow is the full name of the exporter receiving a copy of a percentage
+	// of the batches, e.g. to evaluate a new backend. Its results are ignored.
+	Shadow string `mapstructure:"shadow"`
+
+	// ShadowPercentage is the percentage of the batches copied to the shadow
+	// exporter, between 0 and 100.
+	ShadowPercentage float32 `mapstructure:"shadow-percentage"`
+
+	// MaxInFlight is the maximum number of batches being sent to the shadow
+	// exporter, the batches above it are not copied.
+	MaxInFlight int `mapstructure:"max-in-flight"`
+}
@@ -0,0 +1,132 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teeexporter
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "tee"
+
+	defaultShadowPercentage = 100
+	defaultMaxInFlight      = 10
+)
+
+var errNotWrapped = errors.New("tee exporter must be created with the exporters it wraps")
+
+// Factory is the factory for the tee exporter.
+type Factory struct {
+}
+
+var _ exporter.WrapperFactory = (*Factory)(nil)
+
+// Type gets the type of the Exporter config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for exporter.
+func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
+	return &Config{
+		ExporterSettings: configmodels.ExporterSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		ShadowPercentage: defaultShadowPercentage,
+		MaxInFlight:      defaultMaxInFlight,
+	}
+}
+
+// WrappedExporters returns the full names of the primary and shadow exporters.
+func (f *Factory) WrappedExporters(cfg configmodels.Exporter) []string {
+	expCfg := cfg.(*Config)
+	var names []string
+	for _, name := range []string{expCfg.Primary, expCfg.Shadow} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CreateTraceExporter always fails, the tee exporter needs the exporters it
+// wraps, see CreateWrapperTraceExporter.
+func (f *Factory) CreateTraceExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+) (exporter.TraceExporter, error) {
+	return nil, errNotWrapped
+}
+
+// CreateMetricsExporter always fails, the tee exporter needs the exporters it
+// wraps, see CreateWrapperMetricsExporter.
+func (f *Factory) CreateMetricsExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+) (exporter.MetricsExporter, error) {
+	return nil, errNotWrapped
+}
+
+// CreateWrapperTraceExporter creates a trace exporter sending the batches to the
+// primary exporter and copying a percentage of them to the shadow exporter.
+func (f *Factory) CreateWrapperTraceExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+	wrapped map[string]exporter.TraceExporter,
+) (exporter.TraceExporter, error) {
+	expCfg := config.(*Config)
+	if err := validate(expCfg); err != nil {
+		return nil, err
+	}
+	return NewTraceExporter(logger, expCfg, wrapped[expCfg.Primary], wrapped[expCfg.Shadow])
+}
+
+// CreateWrapperMetricsExporter creates a metrics exporter sending the batches to
+// the primary exporter and copying a percentage of them to the shadow exporter.
+func (f *Factory) CreateWrapperMetricsExporter(
+	logger *zap.Logger,
+	config configmodels.Exporter,
+	wrapped map[string]exporter.MetricsExporter,
+) (exporter.MetricsExporter, error) {
+	expCfg := config.(*Config)
+	if err := validate(expCfg); err != nil {
+		return nil, err
+	}
+	return NewMetricsExporter(logger, expCfg, wrapped[expCfg.Primary], wrapped[expCfg.Shadow])
+}
+
+func validate(cfg *Config) error {
+	if cfg.Primary == "" || cfg.Shadow == "" {
+		return fmt.Errorf("%q config requires a \"primary\" and a \"shadow\" exporter", cfg.Name())
+	}
+	if cfg.Primary == cfg.Shadow {
+		return fmt.Errorf("%q config requires different \"primary\" and \"shadow\" exporters", cfg.Name())
+	}
+	if cfg.ShadowPercentage < 0 || cfg.ShadowPercentage > 100 {
+		return fmt.Errorf("%q config requires a \"shadow-percentage\" between 0 and 100", cfg.Name())
+	}
+	if cfg.MaxInFlight <= 0 {
+		return fmt.Errorf("%q config requires a positive value for \"max-in-flight\"", cfg.Name())
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teeexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.Empty(t, factory.WrappedExporters(cfg))
+}
+
+func TestCreateNotWrapped(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+
+	_, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	_, err = factory.CreateMetricsExporter(zap.NewNop(), cfg)
+	assert.Error(t, err)
+}
+
+func TestCreateWrapperExporters(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Primary = "primary"
+	cfg.Shadow = "shadow"
+
+	te, err := factory.CreateWrapperTraceExporter(zap.NewNop(), cfg, map[string]exporter.TraceExporter{
+		"primary": exportertest.NewNopTraceExporter(),
+		"shadow":  exportertest.NewNopTraceExporter(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, typeStr, te.Name())
+
+	me, err := factory.CreateWrapperMetricsExporter(zap.NewNop(), cfg, map[string]exporter.MetricsExporter{
+		"primary": exportertest.NewNopMetricsExporter(),
+		"shadow":  exportertest.NewNopMetricsExporter(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, typeStr, me.Name())
+}
+
+func TestCreateWrapperInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{"no_primary", func(cfg *Config) { cfg.Primary = "" }},
+		{"no_shadow", func(cfg *Config) { cfg.Shadow = "" }},
+		{"same_exporter", func(cfg *Config) { cfg.Shadow = cfg.Primary }},
+		{"negative_percentage", func(cfg *Config) { cfg.ShadowPercentage = -1 }},
+		{"large_percentage", func(cfg *Config) { cfg.ShadowPercentage = 101 }},
+		{"no_max_in_flight", func(cfg *Config) { cfg.MaxInFlight = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Primary = "primary"
+			cfg.Shadow = "shadow"
+			tt.modify(cfg)
+
+			wrapped := map[string]exporter.TraceExporter{
+				"primary": exportertest.NewNopTraceExporter(),
+				"shadow":  exportertest.NewNopTraceExporter(),
+			}
+			_, err := factory.CreateWrapperTraceExporter(zap.NewNop(), cfg, wrapped)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teeexporter
+
+import (
+	"context"
+	"sync"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+)
+
+// tee copies a percentage of the batches to the shadow exporter. The copies are
+// sent asynchronously once the primary exporter returns, their results are only
+// logged.
+type tee struct {
+	name       string
+	logger     *zap.Logger
+	percentage float32
+
+	mu sync.Mutex
+	// credit accumulates the percentage of every batch, a batch is copied once
+	// it reaches 100.
+	credit float32
+
+	inFlight chan struct{}
+	// guard tracks the copies in flight, see Shutdown.
+	guard exporterhelper.ShutdownGuard
+}
+
+func newTee(logger *zap.Logger, cfg *Config) *tee {
+	return &tee{
+		name:       cfg.Name(),
+		logger:     logger,
+		percentage: cfg.ShadowPercentage,
+		inFlight:   make(chan struct{}, cfg.MaxInFlight),
+	}
+}
+
+// sampled returns true if the current batch must be copied to the shadow
+// exporter. Exactly the configured percentage of the batches are copied.
+func (t *tee) sampled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.credit += t.percentage
+	if t.credit < 100 {
+		return false
+	}
+	t.credit -= 100
+	return true
+}
+
+// copyToShadow calls push in the background, with the tags but not the
+// deadline or cancellation of the given context. The copy is dropped if too
+// many copies are in flight or once the tee is shut down. push must not use
+// the batch passed to the tee, which is modified in place by the processors
+// of the other pipelines once the tee returns, but a copy of it.
+func (t *tee) copyToShadow(ctx context.Context, push func(ctx context.Context) error) {
+	if !t.guard.Enter() {
+		return
+	}
+	select {
+	case t.inFlight <- struct{}{}:
+	default:
+		t.guard.Exit()
+		t.logger.Debug("Too many batches in flight, not copied to the shadow exporter",
+			zap.String("exporter", t.name))
+		return
+	}
+
+	shadowCtx := tag.NewContext(context.Background(), tag.FromContext(ctx))
+	go func() {
+		defer func() {
+			<-t.inFlight
+			t.guard.Exit()
+		}()
+		if err := push(shadowCtx); err != nil {
+			t.logger.Debug("Shadow exporter failed", zap.String("exporter", t.name), zap.Error(err))
+		}
+	}()
+}
+
+func (t *tee) Name() string {
+	return t.name
+}
+
+// Shutdown waits for the copies in flight, bounded by
+// exporterhelper.DefaultShutdownTimeout. The wrapped exporters are shut down on
+// their own, after the tee.
+func (t *tee) Shutdown() error {
+	return t.guard.Shutdown(nil)
+}
+
+type traceExporter struct {
+	*tee
+	primary exporter.TraceExporter
+	shadow  exporter.TraceExporter
+}
+
+var _ exporter.TraceExporter = (*traceExporter)(nil)
+
+// NewTraceExporter creates a trace exporter sending all the batches to primary
+// and copying a percentage of them to shadow.
+func NewTraceExporter(logger *zap.Logger, cfg *Config, primary, shadow exporter.TraceExporter) (exporter.TraceExporter, error) {
+	if primary == nil || shadow == nil {
+		return nil, errNotWrapped
+	}
+	return &traceExporter{
+		tee:     newTee(logger, cfg),
+		primary: primary,
+		shadow:  shadow,
+	}, nil
+}
+
+func (te *traceExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	err := te.primary.ConsumeTraceData(ctx, td)
+	if te.sampled() {
+		shadowTD := cloneTraceData(td)
+		te.copyToShadow(ctx, func(ctx context.Context) error {
+			return te.shadow.ConsumeTraceData(ctx, shadowTD)
+		})
+	}
+	return err
+}
+
+type metricsExporter struct {
+	*tee
+	primary exporter.MetricsExporter
+	shadow  exporter.MetricsExporter
+}
+
+var _ exporter.MetricsExporter = (*metricsExporter)(nil)
+
+// NewMetricsExporter creates a metrics exporter sending all the batches to
+// primary and copying a percentage of them to shadow.
+func NewMetricsExporter(logger *zap.Logger, cfg *Config, primary, shadow exporter.MetricsExporter) (exporter.MetricsExporter, error) {
+	if primary == nil || shadow == nil {
+		return nil, errNotWrapped
+	}
+	return &metricsExporter{
+		tee:     newTee(logger, cfg),
+		primary: primary,
+		shadow:  shadow,
+	}, nil
+}
+
+func (me *metricsExporter) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	err := me.primary.ConsumeMetricsData(ctx, md)
+	if me.sampled() {
+		shadowMD := cloneMetricsData(md)
+		me.copyToShadow(ctx, func(ctx context.Context) error {
+			return me.shadow.ConsumeMetricsData(ctx, shadowMD)
+		})
+	}
+	return err
+}
+
+func cloneTraceData(td consumerdata.TraceData) consumerdata.TraceData {
+	clone := consumerdata.TraceData{
+		Node:         proto.Clone(td.Node).(*commonpb.Node),
+		Resource:     proto.Clone(td.Resource).(*resourcepb.Resource),
+		Spans:        make([]*tracepb.Span, len(td.Spans)),
+		SourceFormat: td.SourceFormat,
+	}
+	for i, span := range td.Spans {
+		clone.Spans[i] = proto.Clone(span).(*tracepb.Span)
+	}
+	return clone
+}
+
+func cloneMetricsData(md consumerdata.MetricsData) consumerdata.MetricsData {
+	clone := consumerdata.MetricsData{
+		Node:     proto.Clone(md.Node).(*commonpb.Node),
+		Resource: proto.Clone(md.Resource).(*resourcepb.Resource),
+		Metrics:  make([]*metricspb.Metric, len(md.Metrics)),
+	}
+	for i, metric := range md.Metrics {
+		clone.Metrics[i] = proto.Clone(metric).(*metricspb.Metric)
+	}
+	return clone
+}
@@ -0,0 +1,206 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teeexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func testConfig(percentage float32, maxInFlight int) *Config {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Primary = "primary"
+	cfg.Shadow = "shadow"
+	cfg.ShadowPercentage = percentage
+	cfg.MaxInFlight = maxInFlight
+	return cfg
+}
+
+func TestTraceExporter_Percentage(t *testing.T) {
+	tests := []struct {
+		percentage float32
+		want       int
+	}{
+		{0, 0},
+		{25, 2},
+		{50, 4},
+		{100, 8},
+	}
+	for _, tt := range tests {
+		primary := new(exportertest.SinkTraceExporter)
+		shadow := new(exportertest.SinkTraceExporter)
+		te, err := NewTraceExporter(zap.NewNop(), testConfig(tt.percentage, 10), primary, shadow)
+		require.NoError(t, err)
+
+		for i := 0; i < 8; i++ {
+			require.NoError(t, te.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+		}
+		require.NoError(t, te.Shutdown())
+
+		assert.Len(t, primary.AllTraces(), 8)
+		assert.Len(t, shadow.AllTraces(), tt.want, "percentage %v", tt.percentage)
+	}
+}
+
+func TestTraceExporter_ShadowResultIgnored(t *testing.T) {
+	primary := new(exportertest.SinkTraceExporter)
+	shadow := exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("shadow failure")))
+	te, err := NewTraceExporter(zap.NewNop(), testConfig(100, 10), primary, shadow)
+	require.NoError(t, err)
+
+	assert.NoError(t, te.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	require.NoError(t, te.Shutdown())
+	assert.Len(t, primary.AllTraces(), 1)
+
+	// The errors of the primary exporter are returned.
+	primaryErr := errors.New("primary failure")
+	te, err = NewTraceExporter(
+		zap.NewNop(),
+		testConfig(100, 10),
+		exportertest.NewNopTraceExporter(exportertest.WithReturnError(primaryErr)),
+		new(exportertest.SinkTraceExporter))
+	require.NoError(t, err)
+	assert.Equal(t, primaryErr, te.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	require.NoError(t, te.Shutdown())
+}
+
+func TestTraceExporter_NoCopyAfterShutdown(t *testing.T) {
+	primary := new(exportertest.SinkTraceExporter)
+	shadow := new(exportertest.SinkTraceExporter)
+	te, err := NewTraceExporter(zap.NewNop(), testConfig(100, 10), primary, shadow)
+	require.NoError(t, err)
+	require.NoError(t, te.Shutdown())
+
+	// The primary exporter rejects the batches on its own once shut down.
+	assert.NoError(t, te.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	assert.Len(t, primary.AllTraces(), 1)
+	assert.Len(t, shadow.AllTraces(), 0)
+	require.NoError(t, te.Shutdown())
+}
+
+// blockingTraceExporter blocks until released and records whether the context
+// was done.
+type blockingTraceExporter struct {
+	exporter.TraceExporter
+	release chan struct{}
+	ctxErrs chan error
+}
+
+func (bte *blockingTraceExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	<-bte.release
+	bte.ctxErrs <- ctx.Err()
+	return nil
+}
+
+func TestTraceExporter_MaxInFlight(t *testing.T) {
+	shadow := &blockingTraceExporter{
+		release: make(chan struct{}),
+		ctxErrs: make(chan error, 10),
+	}
+	te, err := NewTraceExporter(zap.NewNop(), testConfig(100, 1), new(exportertest.SinkTraceExporter), shadow)
+	require.NoError(t, err)
+
+	// The primary path doesn't wait for the shadow exporter, and the copies
+	// above the in flight limit are dropped.
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		require.NoError(t, te.ConsumeTraceData(ctx, consumerdata.TraceData{}))
+	}
+	cancel()
+	close(shadow.release)
+	require.NoError(t, te.Shutdown())
+
+	require.Len(t, shadow.ctxErrs, 1)
+	assert.NoError(t, <-shadow.ctxErrs, "the shadow context must not be canceled with the request")
+}
+
+// spanNamesExporter records the names of the spans as it reads them.
+type spanNamesExporter struct {
+	exporter.TraceExporter
+	names chan string
+}
+
+func (sne *spanNamesExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	for _, span := range td.Spans {
+		sne.names <- span.Name.Value
+	}
+	return nil
+}
+
+func TestTraceExporter_ShadowCopiesBatch(t *testing.T) {
+	shadow := &spanNamesExporter{names: make(chan string, 1)}
+	te, err := NewTraceExporter(zap.NewNop(), testConfig(100, 10), new(exportertest.SinkTraceExporter), shadow)
+	require.NoError(t, err)
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: "checkout"}}}}
+	require.NoError(t, te.ConsumeTraceData(context.Background(), td))
+	// The processors of the other pipelines sharing the batch modify it in
+	// place once the tee returns, while the copy is in flight.
+	td.Spans[0].Name.Value = "renamed"
+	require.NoError(t, te.Shutdown())
+
+	require.Len(t, shadow.names, 1)
+	assert.Equal(t, "checkout", <-shadow.names)
+}
+
+func TestMetricsExporter_ShadowCopiesBatch(t *testing.T) {
+	shadow := new(exportertest.SinkMetricsExporter)
+	me, err := NewMetricsExporter(zap.NewNop(), testConfig(100, 10), new(exportertest.SinkMetricsExporter), shadow)
+	require.NoError(t, err)
+
+	md := consumerdata.MetricsData{Metrics: []*metricspb.Metric{{
+		MetricDescriptor: &metricspb.MetricDescriptor{Name: "requests"},
+	}}}
+	require.NoError(t, me.ConsumeMetricsData(context.Background(), md))
+	md.Metrics[0].MetricDescriptor.Name = "renamed"
+	require.NoError(t, me.Shutdown())
+
+	got := shadow.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, "requests", got[0].Metrics[0].MetricDescriptor.Name)
+}
+
+func TestMetricsExporter_Percentage(t *testing.T) {
+	primary := new(exportertest.SinkMetricsExporter)
+	shadow := new(exportertest.SinkMetricsExporter)
+	me, err := NewMetricsExporter(zap.NewNop(), testConfig(10, 10), primary, shadow)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, me.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{}))
+	}
+	require.NoError(t, me.Shutdown())
+
+	assert.Len(t, primary.AllMetrics(), 20)
+	assert.Len(t, shadow.AllMetrics(), 2)
+}
+
+func TestNewExporter_NilWrapped(t *testing.T) {
+	_, err := NewTraceExporter(zap.NewNop(), testConfig(100, 10), nil, new(exportertest.SinkTraceExporter))
+	assert.Error(t, err)
+	_, err = NewMetricsExporter(zap.NewNop(), testConfig(100, 10), new(exportertest.SinkMetricsExporter), nil)
+	assert.Error(t, err)
+}
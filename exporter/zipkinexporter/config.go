@@ -25,4 +25,17 @@ type Config struct {
 	// The URL to send the Zipkin trace data to (e.g.:
 	// http://some.url:9411/api/v2/spans).
 	URL string `mapstructure:"url"`
+
+	// Headers are a set of headers to be added to the HTTP request sending
+	// trace data.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// UserAgent, if not empty, replaces the User-Agent header of the HTTP
+	// requests.
+	UserAgent string `mapstructure:"user-agent"`
+
+	// Format is the format of the spans sent: "v2-json" for the Zipkin v2 API,
+	// "v1-json" or "v1-thrift" for the Zipkin v1 API, e.g.
+	// http://some.url:9411/api/v1/spans.
+	Format string `mapstructure:"format"`
 }
@@ -46,6 +46,14 @@ func TestLoadConfig(t *testing.T) {
 	e1 := cfg.Exporters["zipkin/2"]
 	assert.Equal(t, "zipkin/2", e1.(*Config).Name())
 	assert.Equal(t, "https://somedest:1234/api/v2/spans", e1.(*Config).URL)
+	assert.Equal(t, map[string]string{"added-entry": "added value"}, e1.(*Config).Headers)
+	assert.Equal(t, "custom-agent/1.0", e1.(*Config).UserAgent)
 	_, err = factory.CreateTraceExporter(zap.NewNop(), e1)
 	require.NoError(t, err)
+
+	e2 := cfg.Exporters["zipkin/v1"]
+	assert.Equal(t, "http://legacy:9411/api/v1/spans", e2.(*Config).URL)
+	assert.Equal(t, "v1-thrift", e2.(*Config).Format)
+	_, err = factory.CreateTraceExporter(zap.NewNop(), e2)
+	require.NoError(t, err)
 }
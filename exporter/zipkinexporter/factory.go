@@ -16,12 +16,14 @@ package zipkinexporter
 
 import (
 	"errors"
+	"fmt"
 
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 )
 
 const (
@@ -45,6 +47,7 @@ func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
+		Format: exporterhelper.ZipkinWireFormats.Default,
 	}
 }
 
@@ -56,10 +59,20 @@ func (f *Factory) CreateTraceExporter(logger *zap.Logger, config configmodels.Ex
 		// TODO https://github.com/open-telemetry/opentelemetry-service/issues/215
 		return nil, errors.New("exporter config requires a non-empty 'url'")
 	}
+	format, err := exporterhelper.ZipkinWireFormats.Resolve(cfg.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%q config has an invalid \"format\": %v", cfg.Name(), err)
+	}
+	serializer := serializerForFormat(format)
 	// <missing service name> is used if the zipkin span is not carrying the name of the service, which shouldn't happen
 	// in normal circumstances. It happens only due to (bad) conversions between formats. The current value is a
 	// clear indication that somehow the name of the service was lost in translation.
-	ze, err := newZipkinExporter(cfg.URL, "<missing service name>", 0)
+	ze, err := newZipkinExporter(
+		cfg.URL,
+		"<missing service name>",
+		0,
+		exporterhelper.HeadersWithUserAgent(cfg.Headers, cfg.UserAgent),
+		serializer)
 	if err != nil {
 		return nil, err
 	}
@@ -15,12 +15,18 @@
 package zipkinexporter
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -59,3 +65,78 @@ func TestCreateInstanceViaFactory(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, ze)
 }
+
+func TestCreateTraceExporter_HeadersAndUserAgent(t *testing.T) {
+	reqHeaders := make(chan http.Header, 1)
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case reqHeaders <- r.Header:
+		default:
+		}
+	}))
+	defer cst.Close()
+
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.URL = cst.URL
+	cfg.Headers = map[string]string{"added-entry": "added value"}
+	cfg.UserAgent = "custom-agent/1.0"
+
+	ze, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, ze)
+
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{
+			{
+				TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				Name:    &tracepb.TruncatableString{Value: "span"},
+			},
+		},
+	}
+	require.NoError(t, ze.ConsumeTraceData(context.Background(), td))
+	// Shutdown flushes the pending spans.
+	require.NoError(t, ze.Shutdown())
+
+	got := <-reqHeaders
+	assert.Equal(t, "added value", got.Get("added-entry"))
+	assert.Equal(t, "custom-agent/1.0", got.Get("User-Agent"))
+}
+
+func TestCreateTraceExporter_Format(t *testing.T) {
+	reqs := make(chan *http.Request, 1)
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case reqs <- r:
+		default:
+		}
+	}))
+	defer cst.Close()
+
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.URL = cst.URL + "/api/v1/spans"
+	cfg.Format = "v1-soap"
+	_, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	assert.Error(t, err)
+
+	cfg.Format = "v1-thrift"
+	ze, err := factory.CreateTraceExporter(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{
+			{
+				TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				Name:    &tracepb.TruncatableString{Value: "span"},
+			},
+		},
+	}
+	require.NoError(t, ze.ConsumeTraceData(context.Background(), td))
+	require.NoError(t, ze.Shutdown())
+
+	got := <-reqs
+	assert.Equal(t, "/api/v1/spans", got.URL.Path)
+	assert.Equal(t, "application/x-thrift", got.Header.Get("Content-Type"))
+}
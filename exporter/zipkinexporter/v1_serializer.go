@@ -0,0 +1,313 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkinexporter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	zipkinreporter "github.com/openzipkin/zipkin-go/reporter"
+)
+
+// The formats of the spans sent by the exporter.
+const (
+	formatV2JSON   = "v2-json"
+	formatV1JSON   = "v1-json"
+	formatV1Thrift = "v1-thrift"
+)
+
+// serializerForFormat returns the serializer of the spans for the given
+// format, nil for an unknown format.
+func serializerForFormat(format string) zipkinreporter.SpanSerializer {
+	switch format {
+	case "", formatV2JSON:
+		return zipkinreporter.JSONSerializer{}
+	case formatV1JSON:
+		return v1JSONSerializer{}
+	case formatV1Thrift:
+		return v1ThriftSerializer{}
+	}
+	return nil
+}
+
+// v1ThriftSerializer serializes the spans as a Thrift list of Zipkin v1 spans,
+// the format of the /api/v1/spans endpoint with the application/x-thrift
+// content type.
+type v1ThriftSerializer struct{}
+
+var _ zipkinreporter.SpanSerializer = v1ThriftSerializer{}
+
+func (v1ThriftSerializer) Serialize(spans []*zipkinmodel.SpanModel) ([]byte, error) {
+	buffer := thrift.NewTMemoryBuffer()
+	protocol := thrift.NewTBinaryProtocolTransport(buffer)
+	if err := protocol.WriteListBegin(thrift.STRUCT, len(spans)); err != nil {
+		return nil, err
+	}
+	for _, span := range spans {
+		if err := toV1Span(span).Write(protocol); err != nil {
+			return nil, err
+		}
+	}
+	if err := protocol.WriteListEnd(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (v1ThriftSerializer) ContentType() string {
+	return "application/x-thrift"
+}
+
+// v1JSONSerializer serializes the spans as a JSON list of Zipkin v1 spans, the
+// format of the /api/v1/spans endpoint with the application/json content type.
+type v1JSONSerializer struct{}
+
+var _ zipkinreporter.SpanSerializer = v1JSONSerializer{}
+
+func (v1JSONSerializer) Serialize(spans []*zipkinmodel.SpanModel) ([]byte, error) {
+	jSpans := make([]*v1JSONSpan, 0, len(spans))
+	for _, span := range spans {
+		jSpans = append(jSpans, toV1JSONSpan(span))
+	}
+	return json.Marshal(jSpans)
+}
+
+func (v1JSONSerializer) ContentType() string {
+	return "application/json"
+}
+
+// v1JSONSpan is the Zipkin v1 JSON span as defined at
+// https://zipkin.io/zipkin-api/zipkin-api.yaml
+type v1JSONSpan struct {
+	TraceID           string                    `json:"traceId"`
+	Name              string                    `json:"name"`
+	ParentID          string                    `json:"parentId,omitempty"`
+	ID                string                    `json:"id"`
+	Timestamp         int64                     `json:"timestamp,omitempty"`
+	Duration          int64                     `json:"duration,omitempty"`
+	Debug             bool                      `json:"debug,omitempty"`
+	Annotations       []*v1JSONAnnotation       `json:"annotations,omitempty"`
+	BinaryAnnotations []*v1JSONBinaryAnnotation `json:"binaryAnnotations,omitempty"`
+}
+
+type v1JSONEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+	Port        uint16 `json:"port,omitempty"`
+}
+
+type v1JSONAnnotation struct {
+	Timestamp int64           `json:"timestamp"`
+	Value     string          `json:"value"`
+	Endpoint  *v1JSONEndpoint `json:"endpoint,omitempty"`
+}
+
+type v1JSONBinaryAnnotation struct {
+	Key string `json:"key"`
+	// Value is a string, or true for the address annotations.
+	Value    interface{}     `json:"value"`
+	Endpoint *v1JSONEndpoint `json:"endpoint,omitempty"`
+}
+
+func toV1JSONSpan(span *zipkinmodel.SpanModel) *v1JSONSpan {
+	tSpan := toV1Span(span)
+	jSpan := &v1JSONSpan{
+		TraceID: span.TraceID.String(),
+		Name:    tSpan.Name,
+		ID:      span.ID.String(),
+		Debug:   tSpan.Debug,
+	}
+	if span.ParentID != nil {
+		jSpan.ParentID = span.ParentID.String()
+	}
+	if tSpan.Timestamp != nil {
+		jSpan.Timestamp = *tSpan.Timestamp
+	}
+	if tSpan.Duration != nil {
+		jSpan.Duration = *tSpan.Duration
+	}
+	for _, a := range tSpan.Annotations {
+		jSpan.Annotations = append(jSpan.Annotations, &v1JSONAnnotation{
+			Timestamp: a.Timestamp,
+			Value:     a.Value,
+			Endpoint:  toV1JSONEndpoint(a.Host),
+		})
+	}
+	for _, ba := range tSpan.BinaryAnnotations {
+		var value interface{} = string(ba.Value)
+		if ba.AnnotationType == zipkincore.AnnotationType_BOOL {
+			value = len(ba.Value) == 1 && ba.Value[0] == 1
+		}
+		jSpan.BinaryAnnotations = append(jSpan.BinaryAnnotations, &v1JSONBinaryAnnotation{
+			Key:      ba.Key,
+			Value:    value,
+			Endpoint: toV1JSONEndpoint(ba.Host),
+		})
+	}
+	return jSpan
+}
+
+func toV1JSONEndpoint(ep *zipkincore.Endpoint) *v1JSONEndpoint {
+	if ep == nil {
+		return nil
+	}
+	jEndpoint := &v1JSONEndpoint{
+		ServiceName: ep.ServiceName,
+		Port:        uint16(ep.Port),
+	}
+	if ep.Ipv4 != 0 {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, uint32(ep.Ipv4))
+		jEndpoint.IPv4 = ip.String()
+	}
+	if len(ep.Ipv6) == net.IPv6len {
+		jEndpoint.IPv6 = net.IP(ep.Ipv6).String()
+	}
+	return jEndpoint
+}
+
+// toV1Span converts a Zipkin v2 span to a v1 span. The span kind is
+// reconstructed as the core annotations at the start and end of the span, e.g.
+// "cs" and "cr" for a client span, and the remote endpoint as the address
+// binary annotation, e.g. "sa" for a client span. The tags are converted to
+// string binary annotations of the local endpoint.
+//
+// The v1 timestamp and duration of a shared span are left to the span of the
+// other side, as for the spans reported by the v1 instrumentations.
+func toV1Span(span *zipkinmodel.SpanModel) *zipkincore.Span {
+	local := toV1Endpoint(span.LocalEndpoint)
+	tSpan := &zipkincore.Span{
+		TraceID: int64(span.TraceID.Low),
+		Name:    span.Name,
+		ID:      int64(span.ID),
+		Debug:   span.Debug,
+	}
+	if span.TraceID.High != 0 {
+		high := int64(span.TraceID.High)
+		tSpan.TraceIDHigh = &high
+	}
+	if span.ParentID != nil {
+		parentID := int64(*span.ParentID)
+		tSpan.ParentID = &parentID
+	}
+
+	start := toEpochMicroseconds(span.Timestamp)
+	duration := int64(span.Duration / time.Microsecond)
+	if !span.Shared && start != 0 {
+		tSpan.Timestamp = &start
+		if duration > 0 {
+			tSpan.Duration = &duration
+		}
+	}
+
+	var begin, end, addr string
+	switch span.Kind {
+	case zipkinmodel.Client:
+		begin, end, addr = zipkincore.CLIENT_SEND, zipkincore.CLIENT_RECV, zipkincore.SERVER_ADDR
+	case zipkinmodel.Server:
+		begin, end, addr = zipkincore.SERVER_RECV, zipkincore.SERVER_SEND, zipkincore.CLIENT_ADDR
+	case zipkinmodel.Producer:
+		begin, end, addr = zipkincore.MESSAGE_SEND, zipkincore.WIRE_SEND, zipkincore.MESSAGE_ADDR
+	case zipkinmodel.Consumer:
+		// The consumer span starts when the message is received from the
+		// wire if it has a duration.
+		begin, addr = zipkincore.MESSAGE_RECV, zipkincore.MESSAGE_ADDR
+		if duration > 0 {
+			begin, end = zipkincore.WIRE_RECV, zipkincore.MESSAGE_RECV
+		}
+	default:
+		addr = zipkincore.SERVER_ADDR
+	}
+	if begin != "" && start != 0 {
+		tSpan.Annotations = append(tSpan.Annotations, &zipkincore.Annotation{Timestamp: start, Value: begin, Host: local})
+		if duration > 0 {
+			tSpan.Annotations = append(tSpan.Annotations, &zipkincore.Annotation{Timestamp: start + duration, Value: end, Host: local})
+		}
+	}
+	for _, a := range span.Annotations {
+		tSpan.Annotations = append(tSpan.Annotations, &zipkincore.Annotation{
+			Timestamp: toEpochMicroseconds(a.Timestamp),
+			Value:     a.Value,
+			Host:      local,
+		})
+	}
+	sort.SliceStable(tSpan.Annotations, func(i, j int) bool {
+		return tSpan.Annotations[i].Timestamp < tSpan.Annotations[j].Timestamp
+	})
+
+	keys := make([]string, 0, len(span.Tags))
+	for key := range span.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tSpan.BinaryAnnotations = append(tSpan.BinaryAnnotations, &zipkincore.BinaryAnnotation{
+			Key:            key,
+			Value:          []byte(span.Tags[key]),
+			AnnotationType: zipkincore.AnnotationType_STRING,
+			Host:           local,
+		})
+	}
+	if remote := toV1Endpoint(span.RemoteEndpoint); remote != nil {
+		tSpan.BinaryAnnotations = append(tSpan.BinaryAnnotations, &zipkincore.BinaryAnnotation{
+			Key:            addr,
+			Value:          []byte{1},
+			AnnotationType: zipkincore.AnnotationType_BOOL,
+			Host:           remote,
+		})
+	}
+	// A local span is identified by its local component annotation, carrying
+	// the local endpoint.
+	if begin == "" && local != nil && len(tSpan.Annotations) == 0 && len(tSpan.BinaryAnnotations) == 0 {
+		tSpan.BinaryAnnotations = append(tSpan.BinaryAnnotations, &zipkincore.BinaryAnnotation{
+			Key:            zipkincore.LOCAL_COMPONENT,
+			Value:          []byte{},
+			AnnotationType: zipkincore.AnnotationType_STRING,
+			Host:           local,
+		})
+	}
+	return tSpan
+}
+
+func toV1Endpoint(ep *zipkinmodel.Endpoint) *zipkincore.Endpoint {
+	if ep == nil {
+		return nil
+	}
+	tEndpoint := &zipkincore.Endpoint{
+		ServiceName: ep.ServiceName,
+		Port:        int16(ep.Port),
+	}
+	if ipv4 := ep.IPv4.To4(); ipv4 != nil {
+		tEndpoint.Ipv4 = int32(binary.BigEndian.Uint32(ipv4))
+	}
+	if ipv6 := ep.IPv6.To16(); ipv6 != nil {
+		tEndpoint.Ipv6 = ipv6
+	}
+	return tEndpoint
+}
+
+func toEpochMicroseconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Microsecond)
+}
@@ -0,0 +1,179 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkinexporter
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	zipkintranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/zipkin"
+)
+
+func testV2Spans() []*zipkinmodel.SpanModel {
+	start := time.Unix(1500000000, 0)
+	parentID := zipkinmodel.ID(1)
+	return []*zipkinmodel.SpanModel{
+		{
+			SpanContext: zipkinmodel.SpanContext{
+				TraceID:  zipkinmodel.TraceID{High: 1, Low: 2},
+				ID:       zipkinmodel.ID(3),
+				ParentID: &parentID,
+			},
+			Kind:           zipkinmodel.Client,
+			Name:           "get",
+			Timestamp:      start,
+			Duration:       10 * time.Millisecond,
+			LocalEndpoint:  &zipkinmodel.Endpoint{ServiceName: "frontend", IPv4: net.ParseIP("10.0.0.1"), Port: 8080},
+			RemoteEndpoint: &zipkinmodel.Endpoint{ServiceName: "backend"},
+			Annotations:    []zipkinmodel.Annotation{{Timestamp: start.Add(time.Millisecond), Value: "retry"}},
+			Tags:           map[string]string{"http.path": "/api"},
+		},
+		{
+			SpanContext: zipkinmodel.SpanContext{
+				TraceID: zipkinmodel.TraceID{High: 1, Low: 2},
+				ID:      zipkinmodel.ID(4),
+			},
+			Kind:          zipkinmodel.Server,
+			Name:          "get",
+			Timestamp:     start.Add(time.Millisecond),
+			Duration:      5 * time.Millisecond,
+			Shared:        true,
+			LocalEndpoint: &zipkinmodel.Endpoint{ServiceName: "backend"},
+		},
+	}
+}
+
+func TestToV1Span(t *testing.T) {
+	spans := testV2Spans()
+	start := spans[0].Timestamp.UnixNano() / 1000
+
+	client := toV1Span(spans[0])
+	require.NotNil(t, client.Timestamp)
+	assert.Equal(t, start, *client.Timestamp)
+	assert.Equal(t, int64(10000), *client.Duration)
+	assert.Equal(t, int64(1), *client.TraceIDHigh)
+	var values []string
+	for _, a := range client.Annotations {
+		values = append(values, a.Value)
+		assert.Equal(t, "frontend", a.Host.ServiceName)
+	}
+	assert.Equal(t, []string{"cs", "retry", "cr"}, values)
+	assert.Equal(t, start+10000, client.Annotations[2].Timestamp)
+	require.Len(t, client.BinaryAnnotations, 2)
+	assert.Equal(t, "http.path", client.BinaryAnnotations[0].Key)
+	assert.Equal(t, "sa", client.BinaryAnnotations[1].Key)
+	assert.Equal(t, zipkincore.AnnotationType_BOOL, client.BinaryAnnotations[1].AnnotationType)
+	assert.Equal(t, "backend", client.BinaryAnnotations[1].Host.ServiceName)
+
+	// The timestamp and duration of the shared server span are the client's.
+	server := toV1Span(spans[1])
+	assert.Nil(t, server.Timestamp)
+	assert.Nil(t, server.Duration)
+	require.Len(t, server.Annotations, 2)
+	assert.Equal(t, "sr", server.Annotations[0].Value)
+	assert.Equal(t, "ss", server.Annotations[1].Value)
+}
+
+func TestV1JSONSerializer(t *testing.T) {
+	blob, err := v1JSONSerializer{}.Serialize(testV2Spans())
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"traceId": "00000000000000010000000000000002",
+			"parentId": "0000000000000001",
+			"id": "0000000000000003",
+			"name": "get",
+			"timestamp": 1500000000000000,
+			"duration": 10000,
+			"annotations": [
+				{"timestamp": 1500000000000000, "value": "cs", "endpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1", "port": 8080}},
+				{"timestamp": 1500000000001000, "value": "retry", "endpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1", "port": 8080}},
+				{"timestamp": 1500000000010000, "value": "cr", "endpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1", "port": 8080}}
+			],
+			"binaryAnnotations": [
+				{"key": "http.path", "value": "/api", "endpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1", "port": 8080}},
+				{"key": "sa", "value": true, "endpoint": {"serviceName": "backend"}}
+			]
+		},
+		{
+			"traceId": "00000000000000010000000000000002",
+			"id": "0000000000000004",
+			"name": "get",
+			"annotations": [
+				{"timestamp": 1500000000001000, "value": "sr", "endpoint": {"serviceName": "backend"}},
+				{"timestamp": 1500000000006000, "value": "ss", "endpoint": {"serviceName": "backend"}}
+			]
+		}
+	]`, string(blob))
+	assert.Equal(t, "application/json", v1JSONSerializer{}.ContentType())
+}
+
+func TestV1ThriftSerializer_RoundTrip(t *testing.T) {
+	blob, err := v1ThriftSerializer{}.Serialize(testV2Spans())
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-thrift", v1ThriftSerializer{}.ContentType())
+
+	buffer := thrift.NewTMemoryBuffer()
+	buffer.Write(blob)
+	protocol := thrift.NewTBinaryProtocolTransport(buffer)
+	_, size, err := protocol.ReadListBegin()
+	require.NoError(t, err)
+	var zSpans []*zipkincore.Span
+	for i := 0; i < size; i++ {
+		zSpan := &zipkincore.Span{}
+		require.NoError(t, zSpan.Read(protocol))
+		zSpans = append(zSpans, zSpan)
+	}
+	tds, err := zipkintranslator.V1ThriftBatchToOCProto(zSpans)
+	require.NoError(t, err)
+
+	spans := make(map[string]*tracepb.Span)
+	for _, td := range tds {
+		for _, span := range td.Spans {
+			spans[td.Node.ServiceInfo.Name] = span
+		}
+	}
+	require.Len(t, spans, 2)
+
+	client := spans["frontend"]
+	require.NotNil(t, client)
+	assert.Equal(t, tracepb.Span_CLIENT, client.Kind)
+	assert.Equal(t, "get", client.Name.Value)
+	assert.Equal(t, int64(1500000000), client.StartTime.Seconds)
+	assert.Equal(t, int32(10*time.Millisecond), client.EndTime.Nanos)
+	assert.Equal(t, "/api", client.Attributes.AttributeMap["http.path"].GetStringValue().GetValue())
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}, client.TraceId)
+
+	server := spans["backend"]
+	require.NotNil(t, server)
+	assert.Equal(t, tracepb.Span_SERVER, server.Kind)
+	assert.Equal(t, int32(time.Millisecond), server.StartTime.Nanos)
+}
+
+func TestSerializerForFormat_WireFormats(t *testing.T) {
+	// Every format the exporter accepts has a serializer.
+	for _, format := range exporterhelper.ZipkinWireFormats.Supported {
+		assert.NotNil(t, serializerForFormat(format), format)
+	}
+}
@@ -18,11 +18,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	zipkinmodel "github.com/openzipkin/zipkin-go/model"
 	zipkinreporter "github.com/openzipkin/zipkin-go/reporter"
 	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
@@ -32,6 +34,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 	spandatatranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/spandata"
@@ -56,6 +59,9 @@ type zipkinExporter struct {
 	defaultServiceName string
 
 	reporter zipkinreporter.Reporter
+	// stopped is set once the reporter is closed, the reporter blocks forever
+	// on the spans sent afterwards.
+	stopped bool
 }
 
 // Default values for Zipkin endpoint.
@@ -99,7 +105,7 @@ func ZipkinExportersFromViper(v *viper.Viper) (tps []consumer.TraceConsumer, mps
 	if zc.UploadPeriod != nil && *zc.UploadPeriod > 0 {
 		uploadPeriod = *zc.UploadPeriod
 	}
-	zle, err := newZipkinExporter(endpoint, serviceName, uploadPeriod)
+	zle, err := newZipkinExporter(endpoint, serviceName, uploadPeriod, nil, nil)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("cannot configure Zipkin exporter: %v", err)
 	}
@@ -108,11 +114,26 @@ func ZipkinExportersFromViper(v *viper.Viper) (tps []consumer.TraceConsumer, mps
 	return
 }
 
-func newZipkinExporter(finalEndpointURI, defaultServiceName string, uploadPeriod time.Duration) (*zipkinExporter, error) {
+func newZipkinExporter(
+	finalEndpointURI, defaultServiceName string,
+	uploadPeriod time.Duration,
+	headers map[string]string,
+	serializer zipkinreporter.SpanSerializer,
+) (*zipkinExporter, error) {
 	var opts []zipkinhttp.ReporterOption
+	if serializer != nil {
+		opts = append(opts, zipkinhttp.Serializer(serializer))
+	}
 	if uploadPeriod > 0 {
 		opts = append(opts, zipkinhttp.BatchInterval(uploadPeriod))
 	}
+	if len(headers) > 0 {
+		opts = append(opts, zipkinhttp.RequestCallback(func(req *http.Request) {
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+		}))
+	}
 	reporter := zipkinhttp.NewReporter(finalEndpointURI, opts...)
 	zle := &zipkinExporter{
 		defaultServiceName: defaultServiceName,
@@ -181,10 +202,16 @@ func (ze *zipkinExporter) Name() string {
 	return ze.defaultServiceName
 }
 
+// Shutdown closes the reporter, sending the spans already reported. The spans
+// consumed afterwards are rejected with exporterhelper.ErrExporterShutdown.
 func (ze *zipkinExporter) Shutdown() error {
 	ze.mu.Lock()
 	defer ze.mu.Unlock()
 
+	if ze.stopped {
+		return nil
+	}
+	ze.stopped = true
 	return ze.reporter.Close()
 }
 
@@ -207,9 +234,14 @@ func (ze *zipkinExporter) ConsumeTraceData(ctx context.Context, td consumerdata.
 			return consumererror.Permanent(err)
 		}
 		zs := ze.zipkinSpan(td.Node, sd)
+		addZipkinTagsFromOCSpan(&zs, span)
 		// ze.reporter can get closed in the midst of a Send
 		// so avoid a read/write during that mutation.
 		ze.mu.Lock()
+		if ze.stopped {
+			ze.mu.Unlock()
+			return exporterhelper.ErrExporterShutdown
+		}
 		ze.reporter.Send(zs)
 		ze.mu.Unlock()
 		goodSpans++
@@ -312,6 +344,26 @@ const (
 	isRemoteEndpoint zipkinDirection = false
 )
 
+// addZipkinTagsFromOCSpan adds the OC span fields without a Zipkin equivalent
+// as tags, unless already set by the span attributes.
+func addZipkinTagsFromOCSpan(zs *zipkinmodel.SpanModel, span *tracepb.Span) {
+	setTag := func(key, value string) {
+		if tracetranslator.OCAttributeKeyExist(span.Attributes, key) {
+			return
+		}
+		if zs.Tags == nil {
+			zs.Tags = make(map[string]string, 2)
+		}
+		zs.Tags[key] = value
+	}
+	if span.SameProcessAsParentSpan != nil {
+		setTag(tracetranslator.TagSameProcessAsParentSpan, strconv.FormatBool(span.SameProcessAsParentSpan.Value))
+	}
+	if span.ChildSpanCount != nil {
+		setTag(tracetranslator.TagSpanChildCount, strconv.FormatUint(uint64(span.ChildSpanCount.Value), 10))
+	}
+}
+
 const zipkinRemoteEndpointKey = "zipkin.remoteEndpoint.serviceName"
 
 func (ze *zipkinExporter) zipkinSpan(node *commonpb.Node, s *trace.SpanData) (zc zipkinmodel.SpanModel) {
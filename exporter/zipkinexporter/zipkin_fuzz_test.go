@@ -0,0 +1,89 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package zipkinexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/receiver/zipkinreceiver"
+)
+
+// FuzzZipkinRoundTrip receives the input as a Zipkin V2 JSON upload, and
+// exports the received spans back to Zipkin. The translations must not panic,
+// and the exported spans must be valid Zipkin spans.
+func FuzzZipkinRoundTrip(f *testing.F) {
+	f.Add([]byte(zipkinSpansJSONJavaLibrary))
+	blob, err := ioutil.ReadFile("../../receiver/zipkinreceiver/testdata/sample1.json")
+	if err != nil {
+		f.Fatalf("Failed to read sample JSON file: %v", err)
+	}
+	f.Add(blob)
+
+	f.Fuzz(func(t *testing.T, blob []byte) {
+		mzr := newMockZipkinReporter("")
+		ze := &zipkinExporter{defaultServiceName: "fuzz", reporter: mzr}
+		sink := &countingTraceConsumer{next: ze}
+		zr, err := zipkinreceiver.New(":0", sink)
+		if err != nil {
+			t.Fatalf("Failed to create a new Zipkin receiver: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(blob))
+		zr.ServeHTTP(httptest.NewRecorder(), req)
+		if sink.err != nil {
+			return
+		}
+		if len(mzr.batch) != sink.spans {
+			t.Fatalf("Different number of spans exported (want %d, got %d)", sink.spans, len(mzr.batch))
+		}
+
+		exported, err := json.Marshal(mzr.batch)
+		if err != nil {
+			return
+		}
+		var zSpans []*zipkinmodel.SpanModel
+		if err := json.Unmarshal(exported, &zSpans); err != nil {
+			t.Fatalf("Failed to decode the exported spans: %v", err)
+		}
+	})
+}
+
+// countingTraceConsumer counts the spans consumed successfully by next.
+type countingTraceConsumer struct {
+	next  *zipkinExporter
+	spans int
+	err   error
+}
+
+func (ctc *countingTraceConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	if err := ctc.next.ConsumeTraceData(ctx, td); err != nil {
+		ctc.err = err
+		return err
+	}
+	ctc.spans += len(td.Spans)
+	return nil
+}
@@ -16,6 +16,7 @@ package zipkinexporter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,12 +26,18 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	zipkinmodel "github.com/openzipkin/zipkin-go/model"
 	zipkinreporter "github.com/openzipkin/zipkin-go/reporter"
 
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
 	"github.com/open-telemetry/opentelemetry-service/internal/config/viperutils"
 	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
 	"github.com/open-telemetry/opentelemetry-service/processor"
@@ -352,3 +359,89 @@ const zipkinSpansJSONJavaLibrary = `
   "duration": 207000
 }]
 `
+
+func TestZipkinExporter_ShutdownFlushes(t *testing.T) {
+	var mu sync.Mutex
+	buf := new(bytes.Buffer)
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		io.Copy(buf, r.Body)
+		mu.Unlock()
+		r.Body.Close()
+	}))
+	defer cst.Close()
+
+	// The upload period is long enough for the spans to be sent on shutdown only.
+	ze, err := newZipkinExporter(cst.URL, "test", time.Hour, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create the exporter: %v", err)
+	}
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{{
+			TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			Name:    &tracepb.TruncatableString{Value: "tail"},
+		}},
+	}
+	if err := ze.ConsumeTraceData(context.Background(), td); err != nil {
+		t.Fatalf("Failed to consume the spans: %v", err)
+	}
+	if err := ze.Shutdown(); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	mu.Lock()
+	sent := buf.String()
+	mu.Unlock()
+	if !strings.Contains(sent, `"name":"tail"`) {
+		t.Errorf("Spans not sent on shutdown, got %q", sent)
+	}
+
+	// The spans consumed after shutdown are rejected instead of blocking.
+	if err := ze.ConsumeTraceData(context.Background(), td); err != exporterhelper.ErrExporterShutdown {
+		t.Errorf("ConsumeTraceData after shutdown: Got %v Want %v", err, exporterhelper.ErrExporterShutdown)
+	}
+	if err := ze.Shutdown(); err != nil {
+		t.Errorf("Second shutdown: %v", err)
+	}
+}
+
+func TestZipkinExporter_OCSpanFieldsAsTags(t *testing.T) {
+	var mu sync.Mutex
+	buf := new(bytes.Buffer)
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		io.Copy(buf, r.Body)
+		mu.Unlock()
+		r.Body.Close()
+	}))
+	defer cst.Close()
+
+	ze, err := newZipkinExporter(cst.URL, "test", time.Hour, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create the exporter: %v", err)
+	}
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{{
+			TraceId:                 []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanId:                  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			Name:                    &tracepb.TruncatableString{Value: "span"},
+			SameProcessAsParentSpan: &wrappers.BoolValue{Value: false},
+			ChildSpanCount:          &wrappers.UInt32Value{Value: 3},
+		}},
+	}
+	if err := ze.ConsumeTraceData(context.Background(), td); err != nil {
+		t.Fatalf("Failed to consume the spans: %v", err)
+	}
+	if err := ze.Shutdown(); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+
+	mu.Lock()
+	sent := buf.String()
+	mu.Unlock()
+	for _, want := range []string{`"oc.sameprocessasparentspan":"false"`, `"oc.span.childcount":"3"`} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("Tag %s not sent, got %q", want, sent)
+		}
+	}
+}
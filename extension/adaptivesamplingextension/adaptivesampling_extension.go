@@ -0,0 +1,82 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adaptivesamplingextension adjusts the sampling probabilities of the
+// services and operations so that the spans sampled by the adaptive-sampler
+// processors, or by the SDKs getting their sampling strategies from the Jaeger
+// receiver, stay within a spans per second budget.
+package adaptivesamplingextension
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/adaptivesampling"
+)
+
+type adaptiveSamplingExtension struct {
+	logger     *zap.Logger
+	config     Config
+	controller *adaptivesampling.Controller
+	done       chan struct{}
+}
+
+var _ extension.ServiceExtension = (*adaptiveSamplingExtension)(nil)
+
+func newAdaptiveSamplingExtension(logger *zap.Logger, config Config) *adaptiveSamplingExtension {
+	return &adaptiveSamplingExtension{
+		logger: logger,
+		config: config,
+		controller: adaptivesampling.NewController(
+			config.TargetSpansPerSecond,
+			config.MinSamplingProbability,
+			config.DefaultSamplingProbability),
+	}
+}
+
+func (ase *adaptiveSamplingExtension) Start(host extension.Host) error {
+	adaptivesampling.Register(ase.config.Name(), ase.controller)
+
+	ase.done = make(chan struct{})
+	go ase.adjust(ase.done)
+
+	ase.logger.Info("Adjusting sampling probabilities",
+		zap.Float64("target-spans-per-second", ase.config.TargetSpansPerSecond),
+		zap.Duration("adjustment-interval", ase.config.AdjustmentInterval))
+	return nil
+}
+
+func (ase *adaptiveSamplingExtension) Shutdown() error {
+	adaptivesampling.Unregister(ase.config.Name())
+	if ase.done != nil {
+		close(ase.done)
+		ase.done = nil
+	}
+	return nil
+}
+
+func (ase *adaptiveSamplingExtension) adjust(done <-chan struct{}) {
+	ticker := time.NewTicker(ase.config.AdjustmentInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			ase.controller.Adjust(now)
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplingextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestAdaptiveSamplingExtension(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.TargetSpansPerSecond = 1
+	cfg.AdjustmentInterval = 10 * time.Millisecond
+	cfg.DefaultSamplingProbability = 1
+
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	defer ext.Shutdown()
+
+	controller := adaptivesampling.Lookup(cfg.Name())
+	require.NotNil(t, controller)
+
+	// The probabilities are adjusted periodically.
+	controller.Record("frontend", "GET /", 1000000)
+	deadline := time.Now().Add(time.Second)
+	for controller.Probability("frontend", "GET /") == 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.True(t, controller.Probability("frontend", "GET /") < 1)
+
+	require.NoError(t, ext.Shutdown())
+	assert.Nil(t, adaptivesampling.Lookup(cfg.Name()))
+}
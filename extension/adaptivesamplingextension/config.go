@@ -0,0 +1,42 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplingextension
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the adaptive sampling extension.
+type Config struct {
+	configmodels.ExtensionSettings `mapstructure:",squash"`
+
+	// TargetSpansPerSecond is the number of sampled spans per second shared
+	// between all the services and operations.
+	TargetSpansPerSecond float64 `mapstructure:"target-spans-per-second"`
+
+	// AdjustmentInterval is the interval at which the sampling probabilities
+	// are computed.
+	AdjustmentInterval time.Duration `mapstructure:"adjustment-interval"`
+
+	// MinSamplingProbability is the lowest sampling probability of an
+	// operation, even if the budget is exceeded.
+	MinSamplingProbability float64 `mapstructure:"min-sampling-probability"`
+
+	// DefaultSamplingProbability is the sampling probability of the
+	// operations not seen since the previous adjustment.
+	DefaultSamplingProbability float64 `mapstructure:"default-sampling-probability"`
+}
@@ -0,0 +1,280 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backendhealthextension periodically probes the backends the
+// exporters send the data to, and exposes their health in the health check
+// of the service and as metrics.
+package backendhealthextension
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/backendhealth"
+)
+
+// Ways of probing the backends.
+const (
+	probeTCP  = "tcp"
+	probeGRPC = "grpc"
+	probeHTTP = "http"
+)
+
+// prober probes a backend, it returns nil if the backend is healthy.
+type prober func(ctx context.Context, b BackendSettings) error
+
+var probers = map[string]prober{
+	probeTCP:  probeTCPConnect,
+	probeGRPC: probeGRPCHealth,
+	probeHTTP: probeHTTPHead,
+}
+
+// backend is a probed backend.
+type backend struct {
+	settings BackendSettings
+	probe    prober
+	ctx      context.Context
+
+	mu     sync.Mutex
+	health backendhealth.Backend
+}
+
+type backendHealthExtension struct {
+	logger   *zap.Logger
+	config   Config
+	backends []*backend
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ extension.ServiceExtension = (*backendHealthExtension)(nil)
+
+func newBackendHealthExtension(logger *zap.Logger, config Config) (*backendHealthExtension, error) {
+	bhe := &backendHealthExtension{
+		logger: logger,
+		config: config,
+	}
+	names := make(map[string]bool)
+	for i, settings := range config.Backends {
+		if settings.Endpoint == "" {
+			return nil, fmt.Errorf("%q config requires an \"endpoint\" for the %d-th backend", config.Name(), i)
+		}
+		if settings.Name == "" {
+			settings.Name = settings.Endpoint
+		}
+		if names[settings.Name] {
+			return nil, fmt.Errorf("%q config has duplicate backend %q", config.Name(), settings.Name)
+		}
+		names[settings.Name] = true
+		if settings.Probe == "" {
+			settings.Probe = probeTCP
+		}
+		settings.Probe = strings.ToLower(settings.Probe)
+		probe, ok := probers[settings.Probe]
+		if !ok {
+			return nil, fmt.Errorf("%q config has unsupported probe %q for backend %q", config.Name(), settings.Probe, settings.Name)
+		}
+
+		ctx, _ := tag.New(context.Background(), tag.Upsert(tagBackendKey, settings.Name))
+		bhe.backends = append(bhe.backends, &backend{
+			settings: settings,
+			probe:    probe,
+			ctx:      ctx,
+			health: backendhealth.Backend{
+				Name:     settings.Name,
+				Endpoint: settings.Endpoint,
+				Probe:    settings.Probe,
+				Error:    "not probed yet",
+			},
+		})
+	}
+	return bhe, nil
+}
+
+func (bhe *backendHealthExtension) Start(host extension.Host) error {
+	backendhealth.Register(bhe.config.Name(), bhe.health)
+
+	bhe.done = make(chan struct{})
+	bhe.wg.Add(1)
+	go bhe.probeLoop(bhe.done)
+
+	bhe.logger.Info("Probing the backends",
+		zap.Int("backends", len(bhe.backends)),
+		zap.Duration("interval", bhe.config.Interval))
+	return nil
+}
+
+func (bhe *backendHealthExtension) Shutdown() error {
+	backendhealth.Unregister(bhe.config.Name())
+	if bhe.done != nil {
+		close(bhe.done)
+		bhe.wg.Wait()
+		bhe.done = nil
+	}
+	return nil
+}
+
+// health returns the health of the backends as of their last probe.
+func (bhe *backendHealthExtension) health() []backendhealth.Backend {
+	health := make([]backendhealth.Backend, 0, len(bhe.backends))
+	for _, b := range bhe.backends {
+		b.mu.Lock()
+		health = append(health, b.health)
+		b.mu.Unlock()
+	}
+	return health
+}
+
+func (bhe *backendHealthExtension) probeLoop(done <-chan struct{}) {
+	defer bhe.wg.Done()
+	ticker := time.NewTicker(bhe.config.Interval)
+	defer ticker.Stop()
+	for {
+		bhe.probeAll()
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// probeAll probes the backends concurrently and waits for the probes.
+func (bhe *backendHealthExtension) probeAll() {
+	var wg sync.WaitGroup
+	for _, b := range bhe.backends {
+		wg.Add(1)
+		go func(b *backend) {
+			defer wg.Done()
+			bhe.probeBackend(b)
+		}(b)
+	}
+	wg.Wait()
+}
+
+// probeBackend probes the backend, records the result and logs the changes
+// of health.
+func (bhe *backendHealthExtension) probeBackend(b *backend) {
+	ctx, cancel := context.WithTimeout(context.Background(), bhe.config.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := b.probe(ctx, b.settings)
+	latency := time.Since(start)
+
+	b.mu.Lock()
+	wasHealthy := b.health.Healthy
+	firstProbe := b.health.LastProbe.IsZero()
+	b.health.Healthy = err == nil
+	b.health.Error = ""
+	if err != nil {
+		b.health.Error = err.Error()
+	}
+	b.health.LastProbe = start
+	b.health.LatencyMillis = int64(latency / time.Millisecond)
+	b.mu.Unlock()
+
+	healthy := int64(0)
+	if err == nil {
+		healthy = 1
+	}
+	stats.Record(b.ctx, statBackendHealthy.M(healthy), statProbeLatency.M(int64(latency/time.Millisecond)))
+
+	switch {
+	case err != nil && (wasHealthy || firstProbe):
+		bhe.logger.Warn("Backend is unhealthy",
+			zap.String("backend", b.settings.Name),
+			zap.String("endpoint", b.settings.Endpoint),
+			zap.Error(err))
+	case err == nil && !wasHealthy:
+		bhe.logger.Info("Backend is healthy",
+			zap.String("backend", b.settings.Name),
+			zap.String("endpoint", b.settings.Endpoint))
+	}
+}
+
+// probeTCPConnect checks that a TCP connection can be established to the
+// backend.
+func probeTCPConnect(ctx context.Context, b BackendSettings) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", b.Endpoint)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeGRPCHealth checks the status of the backend with the gRPC health
+// checking protocol. The backends not implementing it are considered
+// healthy once they answer.
+func probeGRPCHealth(ctx context.Context, b BackendSettings) error {
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if b.Secure {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	conn, err := grpc.DialContext(ctx, b.Endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// probeHTTPHead sends a HEAD request to the backend, which is healthy unless
+// the status is a server error. The endpoints accepting the data often don't
+// support HEAD, answering with a client error is enough.
+func probeHTTPHead(ctx context.Context, b BackendSettings) error {
+	req, err := http.NewRequest(http.MethodHead, b.Endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return nil
+}
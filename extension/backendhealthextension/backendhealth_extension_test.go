@@ -0,0 +1,114 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendhealthextension
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/backendhealth"
+)
+
+func TestBackendHealthExtension(t *testing.T) {
+	healthyHTTP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer healthyHTTP.Close()
+	unhealthyHTTP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthyHTTP.Close()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// Get a port nothing listens on.
+	closed, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	cfg := Config{
+		ExtensionSettings: configmodels.ExtensionSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		Interval: time.Hour,
+		Timeout:  time.Second,
+		Backends: []BackendSettings{
+			{Name: "http-ok", Endpoint: healthyHTTP.URL, Probe: "http"},
+			{Name: "http-unavailable", Endpoint: unhealthyHTTP.URL, Probe: "http"},
+			{Name: "tcp-ok", Endpoint: ln.Addr().String()},
+			{Name: "tcp-closed", Endpoint: closedAddr, Probe: "TCP"},
+		},
+	}
+	bhe, err := newBackendHealthExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	for _, b := range bhe.health() {
+		assert.False(t, b.Healthy)
+		assert.Equal(t, "not probed yet", b.Error)
+	}
+
+	bhe.probeAll()
+	healthy := make(map[string]bool)
+	for _, b := range bhe.health() {
+		healthy[b.Name] = b.Healthy
+		assert.False(t, b.LastProbe.IsZero())
+		assert.Equal(t, b.Healthy, b.Error == "", b.Name)
+	}
+	assert.Equal(t, map[string]bool{
+		"http-ok":          true,
+		"http-unavailable": false,
+		"tcp-ok":           true,
+		"tcp-closed":       false,
+	}, healthy)
+
+	require.NoError(t, bhe.Start(nil))
+	assert.Len(t, backendhealth.Backends(), 4)
+	require.NoError(t, bhe.Shutdown())
+	assert.Len(t, backendhealth.Backends(), 0)
+}
+
+func TestProbeGRPCHealth(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	b := BackendSettings{Endpoint: ln.Addr().String(), Probe: probeGRPC}
+	assert.NoError(t, probeGRPCHealth(ctx, b))
+
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	assert.Error(t, probeGRPCHealth(ctx, b))
+}
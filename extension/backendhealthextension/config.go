@@ -0,0 +1,54 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendhealthextension
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// BackendSettings defines a backend to probe.
+type BackendSettings struct {
+	// Name identifies the backend in the health check and the metrics. It is
+	// the endpoint if empty.
+	Name string `mapstructure:"name"`
+
+	// Endpoint is the address of the backend, host:port for the tcp and grpc
+	// probes and a URL for the http probe.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Probe is the way the backend is probed, one of "tcp", "grpc" and
+	// "http". Default is "tcp".
+	Probe string `mapstructure:"probe"`
+
+	// Secure uses TLS for the grpc probe, the http probe uses TLS for https
+	// URLs.
+	Secure bool `mapstructure:"secure"`
+}
+
+// Config defines configuration for the backend health extension.
+type Config struct {
+	configmodels.ExtensionSettings `mapstructure:",squash"`
+
+	// Interval is the interval at which the backends are probed.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout is the timeout of each probe.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Backends are the backends to probe.
+	Backends []BackendSettings `mapstructure:"backends"`
+}
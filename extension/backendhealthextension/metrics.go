@@ -0,0 +1,57 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendhealthextension
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+var (
+	tagBackendKey, _ = tag.NewKey("backend")
+
+	statBackendHealthy = stats.Int64("backend_healthy", "Whether the backend was healthy at its last probe (1) or not (0)", stats.UnitDimensionless)
+	statProbeLatency   = stats.Int64("backend_probe_latency", "Duration (in milliseconds) of the last probe of the backend", stats.UnitMilliseconds)
+)
+
+// MetricViews returns the metrics views related to the backend health
+// extension.
+func MetricViews(level telemetry.Level) []*view.View {
+	if level == telemetry.None {
+		return nil
+	}
+
+	tagKeys := []tag.Key{tagBackendKey}
+
+	backendHealthyView := &view.View{
+		Name:        statBackendHealthy.Name(),
+		Measure:     statBackendHealthy,
+		Description: statBackendHealthy.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.LastValue(),
+	}
+	probeLatencyView := &view.View{
+		Name:        statProbeLatency.Name(),
+		Measure:     statProbeLatency,
+		Description: statProbeLatency.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.LastValue(),
+	}
+
+	return []*view.View{backendHealthyView, probeLatencyView}
+}
@@ -0,0 +1,74 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bearertokenauthextension authenticates the requests of the
+// receivers with a list of static bearer tokens. The receivers naming the
+// extension in their auth setting only accept the requests carrying one of
+// the tokens, see the auth package.
+package bearertokenauthextension
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+)
+
+type bearerTokenAuthExtension struct {
+	logger *zap.Logger
+	name   string
+	tokens [][]byte
+}
+
+var _ extension.ServiceExtension = (*bearerTokenAuthExtension)(nil)
+var _ auth.Validator = (*bearerTokenAuthExtension)(nil)
+
+func newBearerTokenAuthExtension(logger *zap.Logger, name string, tokens []string) *bearerTokenAuthExtension {
+	btae := &bearerTokenAuthExtension{
+		logger: logger,
+		name:   name,
+		tokens: make([][]byte, len(tokens)),
+	}
+	for i, token := range tokens {
+		btae.tokens[i] = []byte(token)
+	}
+	return btae
+}
+
+func (btae *bearerTokenAuthExtension) Start(host extension.Host) error {
+	auth.Register(btae.name, btae)
+	btae.logger.Info("Started bearer token authentication", zap.Int("tokens", len(btae.tokens)))
+	return nil
+}
+
+func (btae *bearerTokenAuthExtension) Shutdown() error {
+	auth.Unregister(btae.name)
+	return nil
+}
+
+// Validate accepts the configured tokens, they are compared in constant time
+// to not leak them through the response time.
+func (btae *bearerTokenAuthExtension) Validate(ctx context.Context, token string) (context.Context, error) {
+	valid := 0
+	for _, t := range btae.tokens {
+		valid |= subtle.ConstantTimeCompare(t, []byte(token))
+	}
+	if valid != 1 {
+		return nil, auth.ErrInvalidToken
+	}
+	return ctx, nil
+}
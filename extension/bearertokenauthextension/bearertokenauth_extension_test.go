@@ -0,0 +1,49 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bearertokenauthextension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestBearerTokenAuthExtension(t *testing.T) {
+	ext := newBearerTokenAuthExtension(zap.NewNop(), "bearer-token-auth/test", []string{"token-1", "token-2"})
+	require.Nil(t, auth.Lookup("bearer-token-auth/test"))
+
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	v := auth.Lookup("bearer-token-auth/test")
+	require.NotNil(t, v)
+
+	for _, token := range []string{"token-1", "token-2"} {
+		ctx, err := v.Validate(context.Background(), token)
+		assert.NoError(t, err)
+		assert.NotNil(t, ctx)
+	}
+	for _, token := range []string{"token-3", "token", ""} {
+		_, err := v.Validate(context.Background(), token)
+		assert.Equal(t, auth.ErrInvalidToken, err)
+	}
+
+	require.NoError(t, ext.Shutdown())
+	assert.Nil(t, auth.Lookup("bearer-token-auth/test"))
+}
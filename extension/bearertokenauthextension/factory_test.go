@@ -0,0 +1,47 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bearertokenauthextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := Factory{}
+	assert.Equal(t, typeStr, factory.Type())
+}
+
+func TestFactory_CreateExtension(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, ext)
+
+	cfg.Tokens = []string{"token", ""}
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, ext)
+
+	cfg.Tokens = []string{"token"}
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, ext)
+}
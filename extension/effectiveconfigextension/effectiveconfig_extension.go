@@ -0,0 +1,127 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package effectiveconfigextension serves over HTTP the configuration the
+// service runs with, after the defaults of the components were applied, with
+// the values of the settings holding secrets redacted.
+package effectiveconfigextension
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/effectiveconfig"
+)
+
+type effectiveConfigExtension struct {
+	logger *zap.Logger
+	config Config
+	server *http.Server
+
+	mu        sync.Mutex
+	effective map[string]interface{}
+}
+
+var _ extension.ServiceExtension = (*effectiveConfigExtension)(nil)
+var _ extension.ConfigWatcher = (*effectiveConfigExtension)(nil)
+
+func newEffectiveConfigExtension(logger *zap.Logger, config Config) *effectiveConfigExtension {
+	return &effectiveConfigExtension{
+		logger: logger,
+		config: config,
+	}
+}
+
+func (ece *effectiveConfigExtension) ConfigLoaded(cfg *configmodels.Config) error {
+	effective := effectiveconfig.Map(cfg)
+	ece.mu.Lock()
+	ece.effective = effective
+	ece.mu.Unlock()
+	return nil
+}
+
+func (ece *effectiveConfigExtension) Start(host extension.Host) error {
+	ln, err := net.Listen("tcp", ece.config.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	ece.server = &http.Server{Handler: http.HandlerFunc(ece.handleConfig)}
+	go func() {
+		if err := ece.server.Serve(ln); err != http.ErrServerClosed {
+			host.ReportFatalError(err)
+		}
+	}()
+
+	ece.logger.Info("Serving the effective configuration", zap.String("endpoint", ece.config.Endpoint))
+	return nil
+}
+
+func (ece *effectiveConfigExtension) Shutdown() error {
+	if ece.server == nil {
+		return nil
+	}
+	return ece.server.Close()
+}
+
+// handleConfig serves the effective configuration as YAML, or as JSON if the
+// "format" query parameter is "json".
+func (ece *effectiveConfigExtension) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	ece.mu.Lock()
+	effective := ece.effective
+	ece.mu.Unlock()
+	if effective == nil {
+		http.Error(w, "the configuration is not loaded", http.StatusServiceUnavailable)
+		return
+	}
+
+	var (
+		out         []byte
+		err         error
+		contentType string
+	)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "yaml":
+		out, err = yaml.Marshal(effective)
+		contentType = "application/x-yaml"
+	case "json":
+		out, err = json.MarshalIndent(effective, "", "  ")
+		contentType = "application/json"
+	default:
+		http.Error(w, "unsupported format "+format, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(out)
+}
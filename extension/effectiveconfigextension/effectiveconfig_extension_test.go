@@ -0,0 +1,95 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effectiveconfigextension
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/effectiveconfig"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func get(t *testing.T, url string) (*http.Response, []byte) {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestEffectiveConfigExtension(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	defer ext.Shutdown()
+
+	baseURL := "http://" + cfg.Endpoint
+	resp, _ := get(t, baseURL+"/")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	serviceCfg := &configmodels.Config{
+		Receivers: configmodels.Receivers{
+			"jaeger": &configmodels.ReceiverSettings{TypeVal: "jaeger", NameVal: "jaeger", Endpoint: "localhost:14250"},
+		},
+		Extensions: configmodels.Extensions{"effective-config": cfg},
+		Service:    configmodels.Service{Extensions: []string{"effective-config"}},
+	}
+	require.NoError(t, ext.(extension.ConfigWatcher).ConfigLoaded(serviceCfg))
+
+	resp, body := get(t, baseURL+"/")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-yaml", resp.Header.Get("Content-Type"))
+	want, err := effectiveconfig.YAML(serviceCfg)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(body))
+	var decodedYAML map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(body, &decodedYAML))
+
+	resp, body = get(t, baseURL+"/?format=json")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var decodedJSON map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &decodedJSON))
+	assert.Equal(t, "localhost:14250",
+		decodedJSON["receivers"].(map[string]interface{})["jaeger"].(map[string]interface{})["endpoint"])
+
+	resp, _ = get(t, baseURL+"/?format=xml")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = get(t, baseURL+"/other")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Post(baseURL+"/", "text/plain", strings.NewReader(""))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
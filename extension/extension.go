@@ -17,6 +17,10 @@
 // to the service, examples: health check endpoint, z-pages, etc.
 package extension
 
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
 // Host represents the entity where the extension is being hosted.
 // It is used to allow communication between the extension and its host.
 type Host interface {
@@ -56,3 +60,13 @@ type PipelineWatcher interface {
 	// appropriate action before that happens.
 	NotReady() error
 }
+
+// ConfigWatcher is an extra interface for ServiceExtension hosted by the OpenTelemetry
+// Service that is to be implemented by extensions interested in the configuration
+// the service runs with, e.g.: to expose it to operators.
+type ConfigWatcher interface {
+	// ConfigLoaded notifies the ServiceExtension of the configuration loaded by the
+	// service, after the defaults of the components were applied. This is sent before
+	// the extensions are started. The configuration must not be modified.
+	ConfigLoaded(cfg *configmodels.Config) error
+}
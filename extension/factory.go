@@ -17,6 +17,7 @@ package extension
 import (
 	"fmt"
 
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
@@ -34,6 +35,19 @@ type Factory interface {
 	CreateExtension(logger *zap.Logger, cfg configmodels.Extension) (ServiceExtension, error)
 }
 
+// CustomUnmarshalerFactory is implemented by the factories whose configuration
+// requires custom unmarshaling. This is typically used if viper.Unmarshal() is not
+// sufficient to unmarshal correctly, e.g. for polymorphic fields.
+type CustomUnmarshalerFactory interface {
+	// CustomUnmarshaler returns a custom unmarshaler for the configuration or nil if
+	// there is no need for custom unmarshaling.
+	CustomUnmarshaler() CustomUnmarshaler
+}
+
+// CustomUnmarshaler is a function that un-marshals a viper data into a config struct
+// in a custom way.
+type CustomUnmarshaler func(v *viper.Viper, viperKey string, intoCfg interface{}) error
+
 // Build takes a list of extension factories and returns a map of type map[string]Factory
 // with factory type as keys. It returns a non-nil error when more than one factories
 // have the same type.
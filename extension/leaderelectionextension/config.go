@@ -0,0 +1,49 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the leader election extension.
+type Config struct {
+	configmodels.ExtensionSettings `mapstructure:",squash"`
+
+	// LeaseName is the name of the Kubernetes Lease shared by the replicas.
+	LeaseName string `mapstructure:"lease-name"`
+
+	// LeaseNamespace is the namespace of the lease, by default the namespace
+	// of the service account of the pod.
+	LeaseNamespace string `mapstructure:"lease-namespace"`
+
+	// Identity of the replica in the lease, by default the host name, i.e.
+	// the pod name.
+	Identity string `mapstructure:"identity"`
+
+	// LeaseDuration is how long the followers wait before taking over a lease
+	// that is not renewed.
+	LeaseDuration time.Duration `mapstructure:"lease-duration"`
+
+	// RenewDeadline is how long the leader tries to renew the lease before
+	// giving up the leadership.
+	RenewDeadline time.Duration `mapstructure:"renew-deadline"`
+
+	// RetryPeriod is the interval between the attempts to acquire or renew
+	// the lease.
+	RetryPeriod time.Duration `mapstructure:"retry-period"`
+}
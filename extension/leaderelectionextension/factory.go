@@ -0,0 +1,110 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+	"github.com/open-telemetry/opentelemetry-service/internal/leaderelection"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "leader-election"
+
+	defaultLeaseName     = "otelsvc"
+	defaultNamespace     = "default"
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+// Factory is the factory for the leader election extension.
+type Factory struct {
+}
+
+var _ extension.Factory = (*Factory)(nil)
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the extension.
+func (f *Factory) CreateDefaultConfig() configmodels.Extension {
+	return &Config{
+		ExtensionSettings: configmodels.ExtensionSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		LeaseName:     defaultLeaseName,
+		LeaseDuration: defaultLeaseDuration,
+		RenewDeadline: defaultRenewDeadline,
+		RetryPeriod:   defaultRetryPeriod,
+	}
+}
+
+// CreateExtension creates the extension based on this config.
+func (f *Factory) CreateExtension(
+	logger *zap.Logger,
+	cfg configmodels.Extension,
+) (extension.ServiceExtension, error) {
+	eCfg := cfg.(*Config)
+	settings := leaderelection.LeaseSettings{
+		Namespace:     eCfg.LeaseNamespace,
+		Name:          eCfg.LeaseName,
+		Identity:      eCfg.Identity,
+		LeaseDuration: eCfg.LeaseDuration,
+		RenewDeadline: eCfg.RenewDeadline,
+		RetryPeriod:   eCfg.RetryPeriod,
+	}
+	if settings.Namespace == "" {
+		settings.Namespace = serviceAccountNamespace()
+	}
+	if settings.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("%q config requires an \"identity\": %v", eCfg.Name(), err)
+		}
+		settings.Identity = hostname
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config of %q: %v", eCfg.Name(), err)
+	}
+	return newLeaderElectionExtension(logger, eCfg.Name(), settings), nil
+}
+
+// serviceAccountNamespace returns the namespace of the service account of the
+// pod, or the default namespace if it can't be read.
+func serviceAccountNamespace() string {
+	b, err := ioutil.ReadFile(filepath.Join(k8sapi.ServiceAccountDir, "namespace"))
+	if err != nil {
+		return defaultNamespace
+	}
+	if ns := strings.TrimSpace(string(b)); ns != "" {
+		return ns
+	}
+	return defaultNamespace
+}
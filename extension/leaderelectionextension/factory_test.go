@@ -0,0 +1,89 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := Factory{}
+	assert.Equal(t, typeStr, factory.Type())
+}
+
+func TestFactory_CreateExtension(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, ext)
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	settings := ext.(*leaderElectionExtension).settings
+	assert.Equal(t, hostname, settings.Identity)
+	assert.Equal(t, defaultLeaseName, settings.Name)
+
+	cfg.LeaseName = ""
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, ext)
+
+	cfg = factory.CreateDefaultConfig().(*Config)
+	cfg.RenewDeadline = cfg.LeaseDuration
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, ext)
+
+	cfg = factory.CreateDefaultConfig().(*Config)
+	cfg.RetryPeriod = 0
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, ext)
+}
+
+func TestFactory_Namespace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "serviceaccount")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(orig string) { k8sapi.ServiceAccountDir = orig }(k8sapi.ServiceAccountDir)
+	k8sapi.ServiceAccountDir = dir
+
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, defaultNamespace, ext.(*leaderElectionExtension).settings.Namespace)
+
+	// The namespace of the service account is the default one.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "namespace"), []byte("monitoring\n"), 0600))
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "monitoring", ext.(*leaderElectionExtension).settings.Namespace)
+
+	cfg.LeaseNamespace = "otel"
+	ext, err = factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "otel", ext.(*leaderElectionExtension).settings.Namespace)
+}
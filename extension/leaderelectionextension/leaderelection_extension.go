@@ -0,0 +1,75 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leaderelectionextension elects a leader among the replicas of the
+// service with a Kubernetes Lease. Singleton receivers consult the elected
+// leader, see the leaderelection package, so that running multiple replicas
+// doesn't scrape or report the same data multiple times.
+package leaderelectionextension
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+	"github.com/open-telemetry/opentelemetry-service/internal/leaderelection"
+)
+
+type leaderElectionExtension struct {
+	logger   *zap.Logger
+	name     string
+	settings leaderelection.LeaseSettings
+	// apiServer returns the configuration of the API server, the in-cluster
+	// one outside of the tests.
+	apiServer func() (*k8sapi.APIServer, error)
+	elector   *leaderelection.LeaseElector
+}
+
+var _ extension.ServiceExtension = (*leaderElectionExtension)(nil)
+
+func newLeaderElectionExtension(logger *zap.Logger, name string, settings leaderelection.LeaseSettings) *leaderElectionExtension {
+	return &leaderElectionExtension{
+		logger:    logger,
+		name:      name,
+		settings:  settings,
+		apiServer: k8sapi.InCluster,
+	}
+}
+
+func (lee *leaderElectionExtension) Start(host extension.Host) error {
+	apiServer, err := lee.apiServer()
+	if err != nil {
+		return err
+	}
+	lee.elector, err = leaderelection.NewLeaseElector(
+		lee.logger, apiServer.URL, apiServer.Client, apiServer.Token, lee.settings)
+	if err != nil {
+		return err
+	}
+
+	leaderelection.Register(lee.name, lee.elector)
+	lee.elector.Start()
+	lee.logger.Info("Started leader election",
+		zap.String("lease", lee.settings.Namespace+"/"+lee.settings.Name),
+		zap.String("identity", lee.settings.Identity))
+	return nil
+}
+
+func (lee *leaderElectionExtension) Shutdown() error {
+	leaderelection.Unregister(lee.name)
+	if lee.elector != nil {
+		lee.elector.Stop()
+	}
+	return nil
+}
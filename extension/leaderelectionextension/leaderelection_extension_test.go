@@ -0,0 +1,123 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/k8sapi"
+	"github.com/open-telemetry/opentelemetry-service/internal/leaderelection"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+// leaseAPI is a minimal Kubernetes API server storing a single lease.
+type leaseAPI struct {
+	mu    sync.Mutex
+	lease map[string]interface{}
+	auth  string
+}
+
+func (la *leaseAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	la.auth = r.Header.Get("Authorization")
+
+	switch r.Method {
+	case http.MethodGet:
+		if la.lease == nil {
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		body, _ := ioutil.ReadAll(r.Body)
+		la.lease = make(map[string]interface{})
+		json.Unmarshal(body, &la.lease)
+	}
+	json.NewEncoder(w).Encode(la.lease)
+}
+
+func (la *leaseAPI) holder() interface{} {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	if la.lease == nil {
+		return nil
+	}
+	return la.lease["spec"].(map[string]interface{})["holderIdentity"]
+}
+
+func (la *leaseAPI) authorization() string {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	return la.auth
+}
+
+func TestLeaderElectionExtension(t *testing.T) {
+	api := &leaseAPI{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Identity = "otelsvc-0"
+	cfg.RetryPeriod = 10 * time.Millisecond
+	cfg.RenewDeadline = 50 * time.Millisecond
+	cfg.LeaseDuration = time.Second
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	ext.(*leaderElectionExtension).apiServer = func() (*k8sapi.APIServer, error) {
+		return &k8sapi.APIServer{
+			URL:    srv.URL,
+			Client: srv.Client(),
+			Token:  func() (string, error) { return "secret", nil },
+		}, nil
+	}
+
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	elector := leaderelection.Lookup(cfg.Name())
+	require.NotNil(t, elector)
+	for i := 0; i < 100 && !elector.IsLeader(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, "otelsvc-0", api.holder())
+	assert.Equal(t, "Bearer secret", api.authorization())
+
+	require.NoError(t, ext.Shutdown())
+	assert.Nil(t, leaderelection.Lookup(cfg.Name()))
+	assert.False(t, elector.IsLeader())
+	assert.Nil(t, api.holder(), "the lease must be released on shutdown")
+}
+
+func TestLeaderElectionExtension_APIServerError(t *testing.T) {
+	factory := &Factory{}
+	ext, err := factory.CreateExtension(zap.NewNop(), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	ext.(*leaderElectionExtension).apiServer = func() (*k8sapi.APIServer, error) {
+		return nil, errors.New("no API server")
+	}
+	assert.Error(t, ext.Start(receivertest.NewMockHost()))
+	assert.NoError(t, ext.Shutdown())
+}
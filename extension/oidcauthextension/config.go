@@ -0,0 +1,47 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the OIDC authentication extension.
+type Config struct {
+	configmodels.ExtensionSettings `mapstructure:",squash"`
+
+	// IssuerURL is the URL of the OIDC issuer, its configuration is
+	// discovered at <issuer-url>/.well-known/openid-configuration and it must
+	// match the "iss" claim of the tokens.
+	IssuerURL string `mapstructure:"issuer-url"`
+
+	// Audience must be one of the "aud" claims of the tokens, usually the
+	// client ID of the producers.
+	Audience string `mapstructure:"audience"`
+
+	// GroupsClaim is the name of the claim holding the groups of the client,
+	// they are added to the context of the requests. No groups are extracted
+	// if it is empty.
+	GroupsClaim string `mapstructure:"groups-claim"`
+
+	// JWKSRefreshInterval is how long the keys of the issuer are cached. They
+	// are also refreshed when a token is signed by an unknown key.
+	JWKSRefreshInterval time.Duration `mapstructure:"jwks-refresh-interval"`
+
+	// Timeout of the requests to the issuer.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
@@ -0,0 +1,81 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "oidc-auth"
+
+	defaultJWKSRefreshInterval = time.Hour
+	defaultTimeout             = 10 * time.Second
+)
+
+// Factory is the factory for the OIDC authentication extension.
+type Factory struct {
+}
+
+var _ extension.Factory = (*Factory)(nil)
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the extension.
+func (f *Factory) CreateDefaultConfig() configmodels.Extension {
+	return &Config{
+		ExtensionSettings: configmodels.ExtensionSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		JWKSRefreshInterval: defaultJWKSRefreshInterval,
+		Timeout:             defaultTimeout,
+	}
+}
+
+// CreateExtension creates the extension based on this config.
+func (f *Factory) CreateExtension(
+	logger *zap.Logger,
+	cfg configmodels.Extension,
+) (extension.ServiceExtension, error) {
+	eCfg := cfg.(*Config)
+	if eCfg.IssuerURL == "" {
+		return nil, fmt.Errorf("%q config requires a non-empty \"issuer-url\"", eCfg.Name())
+	}
+	if u, err := url.ParseRequestURI(eCfg.IssuerURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("%q config has an invalid \"issuer-url\": %q", eCfg.Name(), eCfg.IssuerURL)
+	}
+	if eCfg.Audience == "" {
+		return nil, fmt.Errorf("%q config requires a non-empty \"audience\"", eCfg.Name())
+	}
+	if eCfg.JWKSRefreshInterval <= 0 {
+		return nil, fmt.Errorf("%q config requires a positive \"jwks-refresh-interval\"", eCfg.Name())
+	}
+	if eCfg.Timeout <= 0 {
+		return nil, fmt.Errorf("%q config requires a positive \"timeout\"", eCfg.Name())
+	}
+	return newOIDCAuthExtension(logger, eCfg), nil
+}
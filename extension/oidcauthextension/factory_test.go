@@ -0,0 +1,63 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := Factory{}
+	assert.Equal(t, typeStr, factory.Type())
+}
+
+func TestFactory_CreateExtension(t *testing.T) {
+	factory := Factory{}
+	newConfig := func() *Config {
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.IssuerURL = "https://accounts.example.com"
+		cfg.Audience = "otelsvc"
+		return cfg
+	}
+
+	ext, err := factory.CreateExtension(zap.NewNop(), newConfig())
+	require.NoError(t, err)
+	require.NotNil(t, ext)
+
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{"missing issuer", func(cfg *Config) { cfg.IssuerURL = "" }},
+		{"relative issuer", func(cfg *Config) { cfg.IssuerURL = "accounts.example.com" }},
+		{"non http issuer", func(cfg *Config) { cfg.IssuerURL = "ftp://accounts.example.com" }},
+		{"missing audience", func(cfg *Config) { cfg.Audience = "" }},
+		{"zero refresh interval", func(cfg *Config) { cfg.JWKSRefreshInterval = 0 }},
+		{"negative timeout", func(cfg *Config) { cfg.Timeout = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			tt.modify(cfg)
+			ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+			assert.Error(t, err)
+			assert.Nil(t, ext)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssuer is an OIDC provider serving its discovery document and keys.
+type fakeIssuer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	keys        []jsonWebKey
+	jwksQueries int
+	unavailable bool
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	fi := &fakeIssuer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		if fi.isUnavailable() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   fi.URL,
+			"jwks_uri": fi.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		fi.mu.Lock()
+		defer fi.mu.Unlock()
+		fi.jwksQueries++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": fi.keys})
+	})
+	fi.Server = httptest.NewServer(mux)
+	return fi
+}
+
+func (fi *fakeIssuer) isUnavailable() bool {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.unavailable
+}
+
+func (fi *fakeIssuer) setUnavailable(unavailable bool) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.unavailable = unavailable
+}
+
+func (fi *fakeIssuer) queries() int {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.jwksQueries
+}
+
+// addRSAKey publishes a new RSA key and returns its private key.
+func (fi *fakeIssuer) addRSAKey(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.keys = append(fi.keys, jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   encodeBigInt(key.N),
+		E:   encodeBigInt(big.NewInt(int64(key.E))),
+	})
+	return key
+}
+
+// addECKey publishes a new P-256 key and returns its private key.
+func (fi *fakeIssuer) addECKey(t *testing.T, kid string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.keys = append(fi.keys, jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   encodeBigInt(key.X),
+		Y:   encodeBigInt(key.Y),
+	})
+	return key
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
@@ -0,0 +1,225 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// minRefreshInterval limits the refreshes triggered by tokens signed by
+	// unknown keys, so such tokens can't be used to flood the issuer.
+	minRefreshInterval = 10 * time.Second
+)
+
+var errUnknownKey = errors.New("the token is signed by an unknown key")
+
+// keySet caches the signing keys of the issuer, fetched from the JWKS
+// endpoint advertised by its discovery document.
+type keySet struct {
+	issuerURL       string
+	client          *http.Client
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+	lastAttempt time.Time
+}
+
+func newKeySet(issuerURL string, client *http.Client, refreshInterval time.Duration) *keySet {
+	return &keySet{
+		issuerURL:       issuerURL,
+		client:          client,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// key returns the key with the given ID. The keys are refreshed when they
+// are older than the refresh interval, or when the key is unknown, with at
+// most one attempt per minRefreshInterval.
+func (ks *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, ok := ks.keys[kid]
+	now := ks.now()
+	if ok && now.Sub(ks.lastRefresh) < ks.refreshInterval {
+		return key, nil
+	}
+	if now.Sub(ks.lastAttempt) < minRefreshInterval {
+		if ok {
+			return key, nil
+		}
+		return nil, errUnknownKey
+	}
+
+	ks.lastAttempt = now
+	keys, err := ks.fetch(ctx)
+	if err != nil {
+		if ok {
+			// Keep using the cached key while the issuer is unavailable.
+			return key, nil
+		}
+		return nil, err
+	}
+	ks.keys = keys
+	ks.lastRefresh = now
+
+	if key, ok = ks.keys[kid]; !ok {
+		return nil, errUnknownKey
+	}
+	return key, nil
+}
+
+// fetch gets the keys of the issuer, the keys that are not used for
+// signatures or that have an unsupported type are skipped.
+func (ks *keySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := ks.get(ctx, strings.TrimSuffix(ks.issuerURL, "/")+discoveryPath, &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != ks.issuerURL {
+		return nil, fmt.Errorf("the discovered issuer %q doesn't match %q", discovery.Issuer, ks.issuerURL)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("the discovery document has no jwks_uri")
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := ks.get(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (ks *keySet) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ks.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s failed with status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s returned an invalid document: %v", url, err)
+	}
+	return nil
+}
+
+// jsonWebKey is a public key of a JWKS, see RFC 7517 and RFC 7518.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("the RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("the EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_Refresh(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.Close()
+	issuer.addRSAKey(t, "key-1")
+
+	now := time.Unix(1000, 0)
+	ks := newKeySet(issuer.URL, http.DefaultClient, time.Hour)
+	ks.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := ks.key(ctx, "key-1")
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+	assert.Equal(t, 1, issuer.queries())
+
+	// The cached keys are used until the refresh interval.
+	_, err = ks.key(ctx, "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, issuer.queries())
+
+	// An unknown key triggers a refresh, at most once per minRefreshInterval.
+	issuer.addECKey(t, "key-2")
+	_, err = ks.key(ctx, "key-2")
+	assert.Equal(t, errUnknownKey, err)
+	assert.Equal(t, 1, issuer.queries())
+	now = now.Add(minRefreshInterval)
+	key, err = ks.key(ctx, "key-2")
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+	assert.Equal(t, 2, issuer.queries())
+	_, err = ks.key(ctx, "key-3")
+	assert.Equal(t, errUnknownKey, err)
+	assert.Equal(t, 2, issuer.queries())
+
+	// The cached keys are kept while the issuer is unavailable.
+	issuer.setUnavailable(true)
+	now = now.Add(time.Hour)
+	_, err = ks.key(ctx, "key-1")
+	require.NoError(t, err)
+	now = now.Add(minRefreshInterval)
+	_, err = ks.key(ctx, "key-3")
+	assert.Error(t, err)
+	assert.NotEqual(t, errUnknownKey, err)
+}
+
+func TestKeySet_IssuerMismatch(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.Close()
+	issuer.addRSAKey(t, "key-1")
+
+	ks := newKeySet(issuer.URL+"/other", http.DefaultClient, time.Hour)
+	_, err := ks.key(context.Background(), "key-1")
+	assert.Error(t, err)
+	assert.Equal(t, 0, issuer.queries())
+}
+
+func TestJSONWebKey_PublicKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		jwk     jsonWebKey
+		wantErr bool
+	}{
+		{"rsa", jsonWebKey{Kty: "RSA", N: "AQAB", E: "AQAB"}, false},
+		{"rsa without modulus", jsonWebKey{Kty: "RSA", E: "AQAB"}, true},
+		{"rsa invalid encoding", jsonWebKey{Kty: "RSA", N: "!!", E: "AQAB"}, true},
+		{"ec unsupported curve", jsonWebKey{Kty: "EC", Crv: "P-192", X: "AQAB", Y: "AQAB"}, true},
+		{"ec point not on curve", jsonWebKey{Kty: "EC", Crv: "P-256", X: "AQAB", Y: "AQAB"}, true},
+		{"symmetric", jsonWebKey{Kty: "oct"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.jwk.publicKey()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, key)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, key)
+			}
+		})
+	}
+}
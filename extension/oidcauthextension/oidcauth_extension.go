@@ -0,0 +1,153 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidcauthextension authenticates the requests of the receivers with
+// the JSON Web Tokens issued by an OpenID Connect provider. The receivers
+// naming the extension in their auth setting only accept the requests
+// carrying a token signed by the issuer for the configured audience, see the
+// auth package.
+package oidcauthextension
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+)
+
+// signingMethods are the accepted signature algorithms, the asymmetric ones
+// OIDC providers sign their tokens with.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+type oidcAuthExtension struct {
+	logger      *zap.Logger
+	name        string
+	issuerURL   string
+	audience    string
+	groupsClaim string
+	keys        *keySet
+	parser      *jwt.Parser
+}
+
+var _ extension.ServiceExtension = (*oidcAuthExtension)(nil)
+var _ auth.Validator = (*oidcAuthExtension)(nil)
+
+func newOIDCAuthExtension(logger *zap.Logger, cfg *Config) *oidcAuthExtension {
+	return &oidcAuthExtension{
+		logger:      logger,
+		name:        cfg.Name(),
+		issuerURL:   cfg.IssuerURL,
+		audience:    cfg.Audience,
+		groupsClaim: cfg.GroupsClaim,
+		keys:        newKeySet(cfg.IssuerURL, &http.Client{Timeout: cfg.Timeout}, cfg.JWKSRefreshInterval),
+		parser:      &jwt.Parser{ValidMethods: signingMethods},
+	}
+}
+
+// Start registers the validator, the keys of the issuer are fetched with the
+// first token so the service starts while the issuer is unavailable.
+func (oae *oidcAuthExtension) Start(host extension.Host) error {
+	auth.Register(oae.name, oae)
+	oae.logger.Info("Started OIDC authentication",
+		zap.String("issuer", oae.issuerURL), zap.String("audience", oae.audience))
+	return nil
+}
+
+func (oae *oidcAuthExtension) Shutdown() error {
+	auth.Unregister(oae.name)
+	return nil
+}
+
+// Validate verifies the signature of the token with the keys of the issuer
+// and checks its issuer, audience and validity period. The groups of the
+// client, if any, are added to the returned context.
+func (oae *oidcAuthExtension) Validate(ctx context.Context, token string) (context.Context, error) {
+	claims := jwt.MapClaims{}
+	_, err := oae.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return oae.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", auth.ErrInvalidToken, err)
+	}
+
+	// The parser checked the validity period, the expiration is required.
+	switch {
+	case !claims.VerifyExpiresAt(time.Now().Unix(), true):
+		return nil, fmt.Errorf("%v: the token is expired", auth.ErrInvalidToken)
+	case !claims.VerifyIssuer(oae.issuerURL, true):
+		return nil, fmt.Errorf("%v: unexpected issuer %v", auth.ErrInvalidToken, claims["iss"])
+	case !hasAudience(claims, oae.audience):
+		return nil, fmt.Errorf("%v: unexpected audience %v", auth.ErrInvalidToken, claims["aud"])
+	}
+
+	if oae.groupsClaim != "" {
+		groups, err := stringsClaim(claims, oae.groupsClaim)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", auth.ErrInvalidToken, err)
+		}
+		if len(groups) > 0 {
+			ctx = auth.NewContextWithGroups(ctx, groups)
+		}
+	}
+	return ctx, nil
+}
+
+// hasAudience reports whether the audience is the "aud" claim or one of its
+// values, since the claim may be a string or an array of strings.
+func hasAudience(claims jwt.MapClaims, audience string) bool {
+	auds, err := stringsClaim(claims, "aud")
+	if err != nil {
+		return false
+	}
+	for _, aud := range auds {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// stringsClaim returns the values of a claim that is a string or an array of
+// strings, and nil if it is absent.
+func stringsClaim(claims jwt.MapClaims, name string) ([]string, error) {
+	switch v := claims[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("the %q claim is not an array of strings", name)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("the %q claim is not a string or an array of strings", name)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcauthextension
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestOIDCAuthExtension(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.Close()
+	rsaKey := issuer.addRSAKey(t, "rsa")
+	ecKey := issuer.addECKey(t, "ec")
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.SetName("oidc-auth/test")
+	cfg.IssuerURL = issuer.URL
+	cfg.Audience = "otelsvc"
+	cfg.GroupsClaim = "groups"
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	v := auth.Lookup("oidc-auth/test")
+	require.NotNil(t, v)
+	defer func() {
+		require.NoError(t, ext.Shutdown())
+		assert.Nil(t, auth.Lookup("oidc-auth/test"))
+	}()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    issuer.URL,
+			"aud":    "otelsvc",
+			"sub":    "producer",
+			"exp":    exp,
+			"groups": []string{"producers", "team-a"},
+		}
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantGroups []string
+		wantErr    bool
+	}{
+		{
+			name:       "rsa",
+			token:      signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, validClaims()),
+			wantGroups: []string{"producers", "team-a"},
+		},
+		{
+			name:       "ec",
+			token:      signToken(t, jwt.SigningMethodES256, "ec", ecKey, validClaims()),
+			wantGroups: []string{"producers", "team-a"},
+		},
+		{
+			name: "audience array",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["aud"] = []string{"other", "otelsvc"}
+				return c
+			}()),
+			wantGroups: []string{"producers", "team-a"},
+		},
+		{
+			name: "single group",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["groups"] = "producers"
+				return c
+			}()),
+			wantGroups: []string{"producers"},
+		},
+		{
+			name: "no groups",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				delete(c, "groups")
+				return c
+			}()),
+		},
+		{
+			name: "invalid groups",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["groups"] = 42
+				return c
+			}()),
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["aud"] = "other"
+				return c
+			}()),
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["iss"] = "https://other.example.com"
+				return c
+			}()),
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["exp"] = time.Now().Add(-time.Minute).Unix()
+				return c
+			}()),
+			wantErr: true,
+		},
+		{
+			name: "no expiration",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				delete(c, "exp")
+				return c
+			}()),
+			wantErr: true,
+		},
+		{
+			name: "not yet valid",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, func() jwt.MapClaims {
+				c := validClaims()
+				c["nbf"] = time.Now().Add(time.Hour).Unix()
+				return c
+			}()),
+			wantErr: true,
+		},
+		{
+			name:    "signed by another key",
+			token:   signToken(t, jwt.SigningMethodRS256, "rsa", otherRSAKey, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			token:   signToken(t, jwt.SigningMethodRS256, "unknown", rsaKey, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "symmetric algorithm",
+			token:   signToken(t, jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "not a jwt",
+			token:   "secret",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := v.Validate(context.Background(), tt.token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGroups, auth.GroupsFromContext(ctx))
+		})
+	}
+}
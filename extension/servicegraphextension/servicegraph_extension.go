@@ -0,0 +1,99 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package servicegraphextension serves, as JSON over HTTP, the service
+// dependency graph built by the service-graph processors from the spans
+// passing through the pipelines.
+package servicegraphextension
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/servicegraph"
+)
+
+// graphPath is the path on which the graph is served.
+const graphPath = "/servicegraph"
+
+type serviceGraphExtension struct {
+	logger *zap.Logger
+	config Config
+	graph  *servicegraph.Graph
+	server *http.Server
+}
+
+var _ extension.ServiceExtension = (*serviceGraphExtension)(nil)
+var _ http.Handler = (*serviceGraphExtension)(nil)
+
+func newServiceGraphExtension(logger *zap.Logger, config Config) *serviceGraphExtension {
+	return &serviceGraphExtension{
+		logger: logger,
+		config: config,
+		graph:  servicegraph.NewGraph(config.MaxSpans),
+	}
+}
+
+func (sge *serviceGraphExtension) Start(host extension.Host) error {
+	ln, err := net.Listen("tcp", sge.config.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	servicegraph.Register(sge.config.Name(), sge.graph)
+
+	mux := http.NewServeMux()
+	mux.Handle(graphPath, sge)
+	sge.server = &http.Server{Handler: mux}
+	go func() {
+		if err := sge.server.Serve(ln); err != http.ErrServerClosed {
+			host.ReportFatalError(err)
+		}
+	}()
+
+	sge.logger.Info("Serving the service graph",
+		zap.String("endpoint", sge.config.Endpoint), zap.String("path", graphPath))
+	return nil
+}
+
+func (sge *serviceGraphExtension) Shutdown() error {
+	servicegraph.Unregister(sge.config.Name())
+	if sge.server == nil {
+		return nil
+	}
+	return sge.server.Close()
+}
+
+// ServeHTTP returns the graph on GET and resets it on DELETE.
+func (sge *serviceGraphExtension) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		body, err := json.Marshal(sge.graph.Snapshot())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		sge.graph.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "only GET and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
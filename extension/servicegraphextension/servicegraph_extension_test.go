@@ -0,0 +1,78 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicegraphextension
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/servicegraph"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestServiceGraphExtension(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	defer ext.Shutdown()
+
+	graph := servicegraph.Lookup(cfg.Name())
+	require.NotNil(t, graph)
+	traceID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	graph.Record(&commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}}, []*tracepb.Span{
+		{TraceId: traceID, SpanId: []byte{1, 1, 1, 1, 1, 1, 1, 1}},
+	})
+	graph.Record(&commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "backend"}}, []*tracepb.Span{
+		{TraceId: traceID, SpanId: []byte{2, 2, 2, 2, 2, 2, 2, 2}, ParentSpanId: []byte{1, 1, 1, 1, 1, 1, 1, 1}},
+	})
+
+	url := "http://" + cfg.Endpoint + graphPath
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var snapshot servicegraph.Snapshot
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+	assert.Len(t, snapshot.Nodes, 2)
+	require.Len(t, snapshot.Edges, 1)
+	assert.Equal(t, "frontend", snapshot.Edges[0].Source)
+	assert.Equal(t, "backend", snapshot.Edges[0].Target)
+	assert.Equal(t, int64(1), snapshot.Edges[0].Calls)
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, graph.Snapshot().Nodes)
+
+	require.NoError(t, ext.Shutdown())
+	assert.Nil(t, servicegraph.Lookup(cfg.Name()))
+}
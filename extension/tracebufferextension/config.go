@@ -0,0 +1,35 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracebufferextension
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the trace buffer extension.
+type Config struct {
+	configmodels.ExtensionSettings `mapstructure:",squash"`
+
+	// Endpoint is the address on which the buffered traces are served over
+	// HTTP.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// MaxTraces is the number of most recent traces kept in the buffer.
+	MaxTraces int `mapstructure:"max-traces"`
+
+	// MaxSpansPerTrace is the maximum number of spans kept for each trace,
+	// further spans of the trace are dropped.
+	MaxSpansPerTrace int `mapstructure:"max-spans-per-trace"`
+}
@@ -0,0 +1,301 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracebufferextension serves over HTTP the most recent traces
+// recorded by the trace-buffer processors, both as JSON and as simple HTML
+// pages, so that traces can be inspected without running a tracing backend.
+package tracebufferextension
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/tracebuffer"
+)
+
+const (
+	// apiTracesPath is the path on which the trace summaries are served as
+	// JSON, a single trace is served under apiTracesPath/<trace-id>.
+	apiTracesPath = "/api/traces"
+	// tracesPath is the path under which a single trace is served as HTML.
+	tracesPath = "/traces"
+
+	traceIDLength = 32
+)
+
+type traceBufferExtension struct {
+	logger *zap.Logger
+	config Config
+	buffer *tracebuffer.Buffer
+	server *http.Server
+}
+
+var _ extension.ServiceExtension = (*traceBufferExtension)(nil)
+
+func newTraceBufferExtension(logger *zap.Logger, config Config) *traceBufferExtension {
+	return &traceBufferExtension{
+		logger: logger,
+		config: config,
+		buffer: tracebuffer.NewBuffer(config.MaxTraces, config.MaxSpansPerTrace),
+	}
+}
+
+func (tbe *traceBufferExtension) Start(host extension.Host) error {
+	ln, err := net.Listen("tcp", tbe.config.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	tracebuffer.Register(tbe.config.Name(), tbe.buffer)
+
+	tbe.server = &http.Server{Handler: tbe.handler()}
+	go func() {
+		if err := tbe.server.Serve(ln); err != http.ErrServerClosed {
+			host.ReportFatalError(err)
+		}
+	}()
+
+	tbe.logger.Info("Serving the trace buffer", zap.String("endpoint", tbe.config.Endpoint))
+	return nil
+}
+
+func (tbe *traceBufferExtension) Shutdown() error {
+	tracebuffer.Unregister(tbe.config.Name())
+	if tbe.server == nil {
+		return nil
+	}
+	return tbe.server.Close()
+}
+
+func (tbe *traceBufferExtension) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiTracesPath, tbe.handleAPIList)
+	mux.HandleFunc(apiTracesPath+"/", tbe.handleAPITrace)
+	mux.HandleFunc(tracesPath+"/", tbe.handleTrace)
+	mux.HandleFunc("/", tbe.handleList)
+	return allowGet(mux)
+}
+
+// allowGet rejects the requests with a method other than GET.
+func allowGet(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (tbe *traceBufferExtension) handleAPIList(w http.ResponseWriter, r *http.Request) {
+	summaries, err := tbe.list(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, summaries)
+}
+
+func (tbe *traceBufferExtension) handleAPITrace(w http.ResponseWriter, r *http.Request) {
+	trace, status := tbe.get(strings.TrimPrefix(r.URL.Path, apiTracesPath+"/"))
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	writeJSON(w, trace)
+}
+
+func (tbe *traceBufferExtension) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	// The lookup form submits the trace ID as a query parameter.
+	if traceID := strings.TrimSpace(r.URL.Query().Get("trace")); traceID != "" {
+		http.Redirect(w, r, tracesPath+"/"+traceID, http.StatusFound)
+		return
+	}
+	summaries, err := tbe.list(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeHTML(w, tbe.logger, listTemplate, struct {
+		Service   string
+		Summaries []tracebuffer.Summary
+	}{r.URL.Query().Get("service"), summaries})
+}
+
+func (tbe *traceBufferExtension) handleTrace(w http.ResponseWriter, r *http.Request) {
+	trace, status := tbe.get(strings.TrimPrefix(r.URL.Path, tracesPath+"/"))
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	writeHTML(w, tbe.logger, traceTemplate, newTraceView(trace))
+}
+
+// list returns the summaries selected by the "service" and "limit" query
+// parameters of the request.
+func (tbe *traceBufferExtension) list(r *http.Request) ([]tracebuffer.Summary, error) {
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			return nil, err
+		}
+	}
+	return tbe.buffer.List(query.Get("service"), limit), nil
+}
+
+// get returns the trace with the given hex encoded ID and the HTTP status of
+// the lookup.
+func (tbe *traceBufferExtension) get(traceID string) (tracebuffer.Trace, int) {
+	traceID = strings.ToLower(traceID)
+	if _, err := hex.DecodeString(traceID); err != nil || len(traceID) != traceIDLength {
+		return tracebuffer.Trace{}, http.StatusBadRequest
+	}
+	trace, ok := tbe.buffer.Get(traceID)
+	if !ok {
+		return tracebuffer.Trace{}, http.StatusNotFound
+	}
+	return trace, http.StatusOK
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func writeHTML(w http.ResponseWriter, logger *zap.Logger, tmpl *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		logger.Warn("Failed to render the trace buffer page", zap.Error(err))
+	}
+}
+
+// spanView is a span as displayed in the trace page.
+type spanView struct {
+	tracebuffer.Span
+	// Depth is the number of ancestors of the span present in the trace.
+	Depth int
+	// Offset is the time between the start of the trace and of the span.
+	Offset time.Duration
+}
+
+type traceView struct {
+	tracebuffer.Trace
+	Views []spanView
+}
+
+func newTraceView(trace tracebuffer.Trace) traceView {
+	parents := make(map[string]string, len(trace.Spans))
+	for _, span := range trace.Spans {
+		parents[span.SpanID] = span.ParentSpanID
+	}
+	view := traceView{Trace: trace, Views: make([]spanView, 0, len(trace.Spans))}
+	for _, span := range trace.Spans {
+		depth := 0
+		// Bound the walk so that a cycle in malformed data does not loop.
+		for parent := span.ParentSpanID; parent != "" && depth < len(trace.Spans); depth++ {
+			next, ok := parents[parent]
+			if !ok {
+				break
+			}
+			parent = next
+		}
+		view.Views = append(view.Views, spanView{
+			Span:   span,
+			Depth:  depth,
+			Offset: span.Start.Sub(trace.Spans[0].Start),
+		})
+	}
+	return view
+}
+
+var funcs = template.FuncMap{
+	"join":   strings.Join,
+	"indent": func(depth int) int { return depth * 16 },
+}
+
+const style = `<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.2em 0.8em; border-bottom: 1px solid #ddd; vertical-align: top; }
+.error { color: #b00; }
+</style>`
+
+var listTemplate = template.Must(template.New("list").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Traces</title>` + style + `</head>
+<body>
+<h1>Traces</h1>
+<form action="/" method="get">
+<input name="trace" placeholder="Trace ID" size="34"> <button>Find</button>
+<input name="service" placeholder="Service" value="{{.Service}}"> <button>Filter</button>
+</form>
+<table>
+<tr><th>Trace ID</th><th>Root</th><th>Services</th><th>Spans</th><th>Errors</th><th>Start</th><th>Duration</th></tr>
+{{range .Summaries}}<tr>
+<td><a href="/traces/{{.TraceID}}">{{.TraceID}}</a></td>
+<td>{{.Root}}</td>
+<td>{{join .Services ", "}}</td>
+<td>{{.Spans}}</td>
+<td{{if .Errors}} class="error"{{end}}>{{.Errors}}</td>
+<td>{{.Start.Format "2006-01-02 15:04:05.000"}}</td>
+<td>{{.Duration}}</td>
+</tr>
+{{else}}<tr><td colspan="7">No traces</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var traceTemplate = template.Must(template.New("trace").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Trace {{.TraceID}}</title>` + style + `</head>
+<body>
+<p><a href="/">All traces</a></p>
+<h1>Trace {{.TraceID}}</h1>
+{{if .DroppedSpans}}<p class="error">{{.DroppedSpans}} spans were dropped.</p>{{end}}
+<table>
+<tr><th>Span</th><th>Service</th><th>Kind</th><th>Offset</th><th>Duration</th><th>Status</th><th>Attributes</th></tr>
+{{range .Views}}<tr>
+<td style="padding-left: {{indent .Depth}}px">{{.Name}} <small>{{.SpanID}}</small></td>
+<td>{{.Service}}</td>
+<td>{{.Kind}}</td>
+<td>{{.Offset}}</td>
+<td>{{.Duration}}</td>
+<td{{if .StatusCode}} class="error"{{end}}>{{.StatusCode}}</td>
+<td>{{range $k, $v := .Attributes}}{{$k}}={{$v}}<br>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,138 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracebufferextension
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/internal/tracebuffer"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+const testTraceID = "0102030405060708090a0b0c0d0e0f10"
+
+func get(t *testing.T, url string) (*http.Response, []byte) {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestTraceBufferExtension(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+	defer ext.Shutdown()
+
+	buffer := tracebuffer.Lookup(cfg.Name())
+	require.NotNil(t, buffer)
+	traceID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	buffer.Add(&commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}}, []*tracepb.Span{
+		{
+			TraceId:   traceID,
+			SpanId:    []byte{1, 1, 1, 1, 1, 1, 1, 1},
+			Name:      &tracepb.TruncatableString{Value: "GET /<index>"},
+			StartTime: &timestamp.Timestamp{Seconds: 10},
+			EndTime:   &timestamp.Timestamp{Seconds: 12},
+		},
+	})
+	buffer.Add(&commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "backend"}}, []*tracepb.Span{
+		{
+			TraceId:      traceID,
+			SpanId:       []byte{2, 2, 2, 2, 2, 2, 2, 2},
+			ParentSpanId: []byte{1, 1, 1, 1, 1, 1, 1, 1},
+			Name:         &tracepb.TruncatableString{Value: "query"},
+			StartTime:    &timestamp.Timestamp{Seconds: 11},
+			EndTime:      &timestamp.Timestamp{Seconds: 12},
+		},
+	})
+
+	baseURL := "http://" + cfg.Endpoint
+
+	resp, body := get(t, baseURL+apiTracesPath)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var summaries []tracebuffer.Summary
+	require.NoError(t, json.Unmarshal(body, &summaries))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, testTraceID, summaries[0].TraceID)
+	assert.Equal(t, []string{"backend", "frontend"}, summaries[0].Services)
+
+	resp, body = get(t, baseURL+apiTracesPath+"?service=unknown")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "[]", string(body))
+
+	resp, _ = get(t, baseURL+apiTracesPath+"?limit=abc")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Trace IDs are case insensitive.
+	resp, body = get(t, baseURL+apiTracesPath+"/0102030405060708090A0B0C0D0E0F10")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var trace tracebuffer.Trace
+	require.NoError(t, json.Unmarshal(body, &trace))
+	assert.Equal(t, testTraceID, trace.TraceID)
+	require.Len(t, trace.Spans, 2)
+	assert.Equal(t, "GET /<index>", trace.Spans[0].Name)
+	assert.Equal(t, "query", trace.Spans[1].Name)
+
+	resp, _ = get(t, baseURL+apiTracesPath+"/00000000000000000000000000000001")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = get(t, baseURL+apiTracesPath+"/not-a-trace-id")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, body = get(t, baseURL+"/")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `<a href="/traces/`+testTraceID+`">`)
+	// Names are escaped.
+	assert.Contains(t, string(body), "GET /&lt;index&gt;")
+
+	// The lookup form redirects to the trace page.
+	resp, body = get(t, baseURL+"/?trace="+testTraceID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, tracesPath+"/"+testTraceID, resp.Request.URL.Path)
+	assert.Contains(t, string(body), "Trace "+testTraceID)
+	assert.Contains(t, string(body), `<td style="padding-left: 16px">query`)
+
+	resp, _ = get(t, baseURL+tracesPath+"/00000000000000000000000000000001")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = get(t, baseURL+"/unknown")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Post(baseURL+apiTracesPath, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	require.NoError(t, ext.Shutdown())
+	assert.Nil(t, tracebuffer.Lookup(cfg.Name()))
+}
@@ -0,0 +1,36 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usagereportingextension
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the usage reporting extension.
+type Config struct {
+	configmodels.ExtensionSettings `mapstructure:",squash"`
+
+	// Endpoint is the URL the usage reports are posted to. It has no default,
+	// nothing is reported unless it is configured.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Interval is the interval at which the usage is reported.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout is the timeout of the requests posting the reports.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
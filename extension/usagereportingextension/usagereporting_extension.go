@@ -0,0 +1,275 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usagereportingextension periodically reports the usage of the
+// service to an endpoint chosen by the operator: which types of components
+// and which of their settings are in use, and the volume of data received.
+// The reports are anonymous, they don't include the names of the components
+// nor the values of their settings.
+package usagereportingextension
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/effectiveconfig"
+	"github.com/open-telemetry/opentelemetry-service/internal/version"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+)
+
+// report is the usage report posted to the endpoint, as JSON.
+type report struct {
+	// InstanceID identifies the reports of the same process, it is random
+	// and changes on every restart.
+	InstanceID    string `json:"instance-id"`
+	Version       string `json:"version"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	UptimeSeconds int64  `json:"uptime-seconds"`
+	// Components are the usages of the types of components, by kind
+	// (receivers, processors, exporters and extensions) and type.
+	Components map[string]map[string]*componentUsage `json:"components"`
+	// Pipelines are the numbers of pipelines by data type.
+	Pipelines map[string]int `json:"pipelines"`
+	// ReceivedSpans and ReceivedTimeSeries are the numbers of spans and time
+	// series received since the start, if the telemetry of the service
+	// records them.
+	ReceivedSpans      int64 `json:"received-spans"`
+	ReceivedTimeSeries int64 `json:"received-timeseries"`
+}
+
+// componentUsage is the usage of a type of component.
+type componentUsage struct {
+	// Count is the number of enabled components of the type.
+	Count int `json:"count"`
+	// Settings are the sorted names of the top-level settings set to a
+	// non-zero value by any of the components.
+	Settings []string `json:"settings"`
+}
+
+type usageReportingExtension struct {
+	logger     *zap.Logger
+	config     Config
+	client     *http.Client
+	instanceID string
+	start      time.Time
+
+	mu         sync.Mutex
+	components map[string]map[string]*componentUsage
+	pipelines  map[string]int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ extension.ServiceExtension = (*usageReportingExtension)(nil)
+var _ extension.ConfigWatcher = (*usageReportingExtension)(nil)
+
+func newUsageReportingExtension(logger *zap.Logger, config Config) (*usageReportingExtension, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &usageReportingExtension{
+		logger:     logger,
+		config:     config,
+		client:     &http.Client{Timeout: config.Timeout},
+		instanceID: hex.EncodeToString(id),
+	}, nil
+}
+
+func (ure *usageReportingExtension) ConfigLoaded(cfg *configmodels.Config) error {
+	effective := effectiveconfig.Map(cfg)
+	components := map[string]map[string]*componentUsage{
+		"receivers":  {},
+		"processors": {},
+		"exporters":  {},
+		"extensions": {},
+	}
+	for name, r := range cfg.Receivers {
+		addComponent(components["receivers"], r.Type(), r.IsEnabled(), effective["receivers"], name)
+	}
+	for name, p := range cfg.Processors {
+		addComponent(components["processors"], p.Type(), p.IsEnabled(), effective["processors"], name)
+	}
+	for name, e := range cfg.Exporters {
+		addComponent(components["exporters"], e.Type(), e.IsEnabled(), effective["exporters"], name)
+	}
+	for name, e := range cfg.Extensions {
+		addComponent(components["extensions"], e.Type(), e.IsEnabled(), effective["extensions"], name)
+	}
+
+	pipelines := make(map[string]int)
+	for _, p := range cfg.Pipelines {
+		pipelines[p.InputType.GetString()]++
+	}
+
+	ure.mu.Lock()
+	ure.components = components
+	ure.pipelines = pipelines
+	ure.mu.Unlock()
+	return nil
+}
+
+// addComponent adds the component named name to the usages of its type, with
+// the settings set in its effective configuration from section.
+func addComponent(usages map[string]*componentUsage, typeStr string, enabled bool, section interface{}, name string) {
+	if !enabled {
+		return
+	}
+	usage := usages[typeStr]
+	if usage == nil {
+		usage = &componentUsage{Settings: []string{}}
+		usages[typeStr] = usage
+	}
+	usage.Count++
+
+	settings, _ := section.(map[string]interface{})[name].(map[string]interface{})
+	for key, value := range settings {
+		if isSet(value) && !contains(usage.Settings, key) {
+			usage.Settings = append(usage.Settings, key)
+		}
+	}
+	sort.Strings(usage.Settings)
+}
+
+// isSet reports if the value of a setting in the effective configuration is
+// neither nil, the zero value of its type nor empty.
+func isSet(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() > 0
+	default:
+		return !reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (ure *usageReportingExtension) Start(host extension.Host) error {
+	ure.start = time.Now()
+	ure.done = make(chan struct{})
+	ure.wg.Add(1)
+	go ure.reportLoop(ure.done)
+
+	ure.logger.Info("Reporting the usage of the service",
+		zap.String("endpoint", ure.config.Endpoint),
+		zap.Duration("interval", ure.config.Interval))
+	return nil
+}
+
+func (ure *usageReportingExtension) Shutdown() error {
+	if ure.done != nil {
+		close(ure.done)
+		ure.wg.Wait()
+		ure.done = nil
+	}
+	return nil
+}
+
+func (ure *usageReportingExtension) reportLoop(done <-chan struct{}) {
+	defer ure.wg.Done()
+	ticker := time.NewTicker(ure.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			// The usage is only informative, the failures are not fatal.
+			if err := ure.send(ure.report(now)); err != nil {
+				ure.logger.Warn("Failed to report the usage of the service", zap.Error(err))
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// report returns the usage report at the given time.
+func (ure *usageReportingExtension) report(now time.Time) *report {
+	ure.mu.Lock()
+	components := ure.components
+	pipelines := ure.pipelines
+	ure.mu.Unlock()
+
+	return &report{
+		InstanceID:         ure.instanceID,
+		Version:            version.Version,
+		OS:                 runtime.GOOS,
+		Arch:               runtime.GOARCH,
+		UptimeSeconds:      int64(now.Sub(ure.start) / time.Second),
+		Components:         components,
+		Pipelines:          pipelines,
+		ReceivedSpans:      viewTotal(observability.ViewReceiverReceivedSpans),
+		ReceivedTimeSeries: viewTotal(observability.ViewReceiverReceivedTimeSeries),
+	}
+}
+
+// viewTotal returns the sum of the rows of the view, or 0 if the view is not
+// registered by the telemetry of the service.
+func viewTotal(v *view.View) int64 {
+	rows, err := view.RetrieveData(v.Name)
+	if err != nil {
+		return 0
+	}
+	var total float64
+	for _, row := range rows {
+		if sum, ok := row.Data.(*view.SumData); ok {
+			total += sum.Value
+		}
+	}
+	return int64(total)
+}
+
+// send posts the report to the endpoint.
+func (ure *usageReportingExtension) send(r *report) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	resp, err := ure.client.Post(ure.config.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	ure.logger.Debug("Reported the usage of the service")
+	return nil
+}
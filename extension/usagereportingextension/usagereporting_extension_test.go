@@ -0,0 +1,140 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usagereportingextension
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+type testReceiverConfig struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+	Relay                         bool              `mapstructure:"relay"`
+	Headers                       map[string]string `mapstructure:"headers"`
+}
+
+func testConfig() *configmodels.Config {
+	return &configmodels.Config{
+		Receivers: configmodels.Receivers{
+			"jaeger": &testReceiverConfig{
+				ReceiverSettings: configmodels.ReceiverSettings{TypeVal: "jaeger", NameVal: "jaeger", Endpoint: "localhost:14250"},
+			},
+			"jaeger/relay": &testReceiverConfig{
+				ReceiverSettings: configmodels.ReceiverSettings{TypeVal: "jaeger", NameVal: "jaeger/relay"},
+				Relay:            true,
+			},
+			"jaeger/disabled": &testReceiverConfig{
+				ReceiverSettings: configmodels.ReceiverSettings{TypeVal: "jaeger", NameVal: "jaeger/disabled", Disabled: true},
+				Headers:          map[string]string{"X-Tenant": "a"},
+			},
+		},
+		Exporters: configmodels.Exporters{
+			"logging": &configmodels.ExporterSettings{TypeVal: "logging", NameVal: "logging"},
+		},
+		Pipelines: configmodels.Pipelines{
+			"traces":   &configmodels.Pipeline{InputType: configmodels.TracesDataType},
+			"traces/2": &configmodels.Pipeline{InputType: configmodels.TracesDataType},
+			"metrics":  &configmodels.Pipeline{InputType: configmodels.MetricsDataType},
+		},
+	}
+}
+
+func TestUsageReportingExtension_Report(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "http://localhost/"
+	ext, err := newUsageReportingExtension(zap.NewNop(), *cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.ConfigLoaded(testConfig()))
+
+	ext.start = time.Unix(1000, 0)
+	r := ext.report(time.Unix(1060, 0))
+	assert.Len(t, r.InstanceID, 32)
+	assert.EqualValues(t, 60, r.UptimeSeconds)
+	assert.Equal(t, map[string]map[string]*componentUsage{
+		"receivers": {
+			"jaeger": {Count: 2, Settings: []string{"endpoint", "relay"}},
+		},
+		"processors": {},
+		"exporters": {
+			"logging": {Count: 1, Settings: []string{}},
+		},
+		"extensions": {},
+	}, r.Components)
+	assert.Equal(t, map[string]int{"traces": 2, "metrics": 1}, r.Pipelines)
+
+	// The names of the components never leak into the report.
+	out, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "jaeger/relay")
+	assert.NotContains(t, string(out), "localhost")
+}
+
+func TestUsageReportingExtension_Send(t *testing.T) {
+	reports := make(chan *report, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var rep report
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&rep))
+		select {
+		case reports <- &rep:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.Interval = 10 * time.Millisecond
+	ext, err := factory.CreateExtension(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.(*usageReportingExtension).ConfigLoaded(testConfig()))
+	require.NoError(t, ext.Start(receivertest.NewMockHost()))
+
+	select {
+	case rep := <-reports:
+		assert.Equal(t, 2, rep.Components["receivers"]["jaeger"].Count)
+		assert.Equal(t, 1, rep.Pipelines["metrics"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report received")
+	}
+	assert.NoError(t, ext.Shutdown())
+}
+
+func TestUsageReportingExtension_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	ext, err := newUsageReportingExtension(zap.NewNop(), *cfg)
+	require.NoError(t, err)
+	assert.Error(t, ext.send(ext.report(time.Now())))
+}
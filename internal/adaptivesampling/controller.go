@@ -0,0 +1,156 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adaptivesampling computes sampling probabilities per service and
+// operation so that the sampled spans stay within a spans per second budget.
+// The probabilities are computed by an adaptive-sampling extension, applied by
+// the adaptive-sampler processors and published to the Jaeger SDKs by the
+// Jaeger receiver, so that the spans can be sampled at the source.
+package adaptivesampling
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Controller adjusts the sampling probability of each operation from the
+// number of sampled spans recorded since the previous adjustment. The budget
+// is shared between the operations: the operations needing less than their
+// share are sampled at 100% and the rest of the budget is shared between the
+// other operations.
+type Controller struct {
+	targetSpansPerSecond float64
+	minProbability       float64
+	defaultProbability   float64
+
+	mu             sync.Mutex
+	operations     map[operationKey]*operation
+	lastAdjustment time.Time
+}
+
+type operationKey struct {
+	service   string
+	operation string
+}
+
+type operation struct {
+	probability float64
+	// spans is the number of sampled spans recorded since the last
+	// adjustment.
+	spans int64
+}
+
+// NewController creates a Controller targeting the given number of sampled
+// spans per second. The probabilities are never lower than minProbability,
+// the operations not seen since the previous adjustment are sampled with the
+// defaultProbability.
+func NewController(targetSpansPerSecond, minProbability, defaultProbability float64) *Controller {
+	return &Controller{
+		targetSpansPerSecond: targetSpansPerSecond,
+		minProbability:       minProbability,
+		defaultProbability:   defaultProbability,
+		operations:           make(map[operationKey]*operation),
+		lastAdjustment:       time.Now(),
+	}
+}
+
+// Probability returns the sampling probability of the operation of the
+// service.
+func (c *Controller) Probability(service, operation string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if op, ok := c.operations[operationKey{service, operation}]; ok {
+		return op.probability
+	}
+	return c.defaultProbability
+}
+
+// Record counts sampled spans of the operation of the service, i.e. spans
+// kept with the probability returned by Probability.
+func (c *Controller) Record(service, operationName string, spans int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := operationKey{service, operationName}
+	op, ok := c.operations[key]
+	if !ok {
+		op = &operation{probability: c.defaultProbability}
+		c.operations[key] = op
+	}
+	op.spans += int64(spans)
+}
+
+// Adjust computes the probabilities from the spans recorded since the last
+// adjustment. The operations without spans are forgotten.
+func (c *Controller) Adjust(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := now.Sub(c.lastAdjustment).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	c.lastAdjustment = now
+
+	// The rate of spans before sampling is estimated from the rate of
+	// sampled spans and the probability they were sampled with.
+	type estimate struct {
+		op   *operation
+		rate float64
+	}
+	estimates := make([]estimate, 0, len(c.operations))
+	for key, op := range c.operations {
+		if op.spans == 0 {
+			delete(c.operations, key)
+			continue
+		}
+		estimates = append(estimates, estimate{op, float64(op.spans) / elapsed / op.probability})
+		op.spans = 0
+	}
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].rate < estimates[j].rate
+	})
+
+	budget := c.targetSpansPerSecond
+	for i, e := range estimates {
+		share := budget / float64(len(estimates)-i)
+		probability := 1.0
+		if e.rate > share {
+			probability = share / e.rate
+		}
+		if probability < c.minProbability {
+			probability = c.minProbability
+		}
+		e.op.probability = probability
+		budget -= e.rate * probability
+		if budget < 0 {
+			budget = 0
+		}
+	}
+}
+
+// Strategies returns the default probability and the probabilities of the
+// operations of the service, the operations sampled with the default
+// probability are omitted.
+func (c *Controller) Strategies(service string) (defaultProbability float64, operations map[string]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	operations = make(map[string]float64)
+	for key, op := range c.operations {
+		if key.service == service && op.probability != c.defaultProbability {
+			operations[key.operation] = op.probability
+		}
+	}
+	return c.defaultProbability, operations
+}
@@ -0,0 +1,71 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestController_Adjust(t *testing.T) {
+	c := NewController(100, 0.01, 0.5)
+	start := c.lastAdjustment
+
+	assert.Equal(t, 0.5, c.Probability("frontend", "GET /"))
+
+	// 10 seconds at 50%: 20 spans per second before sampling for the first
+	// operation, 400 for the second one, 4000 for the third one.
+	c.Record("frontend", "GET /", 100)
+	c.Record("frontend", "GET /cart", 2000)
+	c.Record("backend", "SELECT", 20000)
+	c.Adjust(start.Add(10 * time.Second))
+
+	// The first operation takes less than its share, the two others share
+	// the rest of the budget equally: 40 spans per second.
+	assert.Equal(t, 1.0, c.Probability("frontend", "GET /"))
+	assert.InDelta(t, 0.1, c.Probability("frontend", "GET /cart"), 1e-9)
+	assert.InDelta(t, 0.01, c.Probability("backend", "SELECT"), 1e-9)
+
+	defaultProbability, operations := c.Strategies("frontend")
+	assert.Equal(t, 0.5, defaultProbability)
+	assert.Len(t, operations, 2)
+	assert.Equal(t, 1.0, operations["GET /"])
+
+	// The operations without spans are forgotten.
+	c.Record("frontend", "GET /", 10)
+	c.Adjust(start.Add(20 * time.Second))
+	assert.Equal(t, 1.0, c.Probability("frontend", "GET /"))
+	assert.Equal(t, 0.5, c.Probability("frontend", "GET /cart"))
+	_, operations = c.Strategies("backend")
+	assert.Empty(t, operations)
+}
+
+func TestController_AdjustWithoutElapsedTime(t *testing.T) {
+	c := NewController(1, 0, 1)
+	c.Record("frontend", "GET /", 1000)
+	c.Adjust(c.lastAdjustment)
+	assert.Equal(t, 1.0, c.Probability("frontend", "GET /"))
+}
+
+func TestRegistry(t *testing.T) {
+	assert.Nil(t, Lookup("adaptive-sampling/test"))
+	c := NewController(1, 0, 1)
+	Register("adaptive-sampling/test", c)
+	assert.Equal(t, c, Lookup("adaptive-sampling/test"))
+	Unregister("adaptive-sampling/test")
+	assert.Nil(t, Lookup("adaptive-sampling/test"))
+}
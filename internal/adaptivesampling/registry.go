@@ -0,0 +1,41 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesampling
+
+import "github.com/open-telemetry/opentelemetry-service/internal/registry"
+
+// controllers links the controllers of the adaptive-sampling extensions with
+// the processors and receivers using them, since extensions are not part of
+// the data pipelines.
+var controllers = registry.New()
+
+// Register makes c the controller of the adaptive-sampling extension named
+// name.
+func Register(name string, c *Controller) {
+	controllers.Register(name, c)
+}
+
+// Unregister removes the controller of the adaptive-sampling extension named
+// name.
+func Unregister(name string) {
+	controllers.Unregister(name)
+}
+
+// Lookup returns the controller of the adaptive-sampling extension named name
+// or nil if the extension is not started.
+func Lookup(name string) *Controller {
+	c, _ := controllers.Lookup(name).(*Controller)
+	return c
+}
@@ -0,0 +1,219 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission limits the number of requests processed concurrently by
+// the receivers, per receiver and globally, so that bursts are throttled
+// instead of exhausting the memory. The requests exceeding a limit are
+// rejected right away with a throttling response, for the clients to retry
+// later.
+package admission
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"sync"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/observability"
+)
+
+const (
+	receiversMaxInFlightCfg = "receivers-max-in-flight"
+
+	// The limits recorded in the metrics of the throttled requests.
+	limitReceiver = "receiver"
+	limitGlobal   = "global"
+
+	// retryAfterSeconds is the delay suggested to the HTTP clients.
+	retryAfterSeconds = "1"
+)
+
+// ErrThrottled is the gRPC error of the throttled requests.
+var ErrThrottled = status.Error(codes.ResourceExhausted, "too many requests in flight, retry later")
+
+// AddFlags adds the command-line flag of the global limit to the given flag
+// set.
+func AddFlags(flags *flag.FlagSet) {
+	flags.Uint(
+		receiversMaxInFlightCfg,
+		0,
+		"Maximum number of requests processed concurrently by all the receivers, the requests are not limited globally if 0 is specified.")
+}
+
+// SetupFromViper sets the global limit according to the configuration in the
+// given viper.
+func SetupFromViper(v *viper.Viper) {
+	SetGlobalLimit(v.GetInt(receiversMaxInFlightCfg))
+}
+
+var (
+	mu     sync.RWMutex
+	global semaphore
+)
+
+// SetGlobalLimit sets the maximum number of requests processed concurrently by
+// all the receivers, no limit is applied if maxInFlight is not positive. The
+// requests in flight when it is called are not counted against the new limit.
+func SetGlobalLimit(maxInFlight int) {
+	mu.Lock()
+	defer mu.Unlock()
+	global = newSemaphore(maxInFlight)
+}
+
+func globalSemaphore() semaphore {
+	mu.RLock()
+	defer mu.RUnlock()
+	return global
+}
+
+// semaphore bounds the number of holders, a nil semaphore is unbounded.
+type semaphore chan struct{}
+
+func newSemaphore(size int) semaphore {
+	if size <= 0 {
+		return nil
+	}
+	return make(semaphore, size)
+}
+
+// tryAcquire acquires the semaphore if it is not full, without blocking.
+func (s semaphore) tryAcquire() bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// Controller admits the requests of a receiver while they are within the limit
+// of the receiver and the global limit.
+type Controller struct {
+	receiverName string
+	limit        semaphore
+	global       func() semaphore
+}
+
+// NewController creates the Controller of the receiver, limiting its requests
+// in flight to maxInFlight, or only to the global limit if maxInFlight is not
+// positive.
+func NewController(maxInFlight int, receiverName string) *Controller {
+	return &Controller{
+		receiverName: receiverName,
+		limit:        newSemaphore(maxInFlight),
+		global:       globalSemaphore,
+	}
+}
+
+// Admit admits a request if the limits are not reached, it returns the
+// function to call once the request is processed and whether it was admitted.
+// The rejected requests are recorded in the metrics.
+func (c *Controller) Admit(ctx context.Context) (func(), bool) {
+	if !c.limit.tryAcquire() {
+		observability.RecordThrottledRequest(observability.ContextWithReceiverName(ctx, c.receiverName), limitReceiver)
+		return nil, false
+	}
+	global := c.global()
+	if !global.tryAcquire() {
+		c.limit.release()
+		observability.RecordThrottledRequest(observability.ContextWithReceiverName(ctx, c.receiverName), limitGlobal)
+		return nil, false
+	}
+	return func() {
+		global.release()
+		c.limit.release()
+	}, true
+}
+
+// UnaryServerInterceptor returns the gRPC interceptor admitting the unary
+// calls. The calls not admitted end with the RESOURCE_EXHAUSTED code.
+func (c *Controller) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done, ok := c.Admit(ctx)
+		if !ok {
+			return nil, ErrThrottled
+		}
+		defer done()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor admitting each message
+// received on the streams, a message is processed until the next one is
+// received or the stream ends. The streams receiving a message that is not
+// admitted end with the RESOURCE_EXHAUSTED code.
+func (c *Controller) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		as := &admittedStream{ServerStream: ss, controller: c}
+		defer as.release()
+		return handler(srv, as)
+	}
+}
+
+// admittedStream is a stream admitting each received message.
+type admittedStream struct {
+	grpc.ServerStream
+	controller *Controller
+	done       func()
+}
+
+func (as *admittedStream) RecvMsg(m interface{}) error {
+	// The previous message is processed once the next one is requested.
+	as.release()
+	if err := as.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	done, ok := as.controller.Admit(as.Context())
+	if !ok {
+		return ErrThrottled
+	}
+	as.done = done
+	return nil
+}
+
+func (as *admittedStream) release() {
+	if as.done != nil {
+		as.done()
+		as.done = nil
+	}
+}
+
+// HTTPHandler wraps the handler to admit the HTTP requests. The requests not
+// admitted are answered with the 429 status.
+func (c *Controller) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done, ok := c.Admit(r.Context())
+		if !ok {
+			w.Header().Set("Retry-After", retryAfterSeconds)
+			http.Error(w, "too many requests in flight, retry later", http.StatusTooManyRequests)
+			return
+		}
+		defer done()
+		next.ServeHTTP(w, r)
+	})
+}
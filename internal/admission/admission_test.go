@@ -0,0 +1,178 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+)
+
+const receiverName = "fake_receiver"
+
+func newTestController(maxInFlight int, global semaphore) *Controller {
+	c := NewController(maxInFlight, receiverName)
+	c.global = func() semaphore { return global }
+	return c
+}
+
+func TestAdmit(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	c := newTestController(2, nil)
+	done1, ok := c.Admit(context.Background())
+	require.True(t, ok)
+	done2, ok := c.Admit(context.Background())
+	require.True(t, ok)
+	_, ok = c.Admit(context.Background())
+	assert.False(t, ok)
+
+	done1()
+	done3, ok := c.Admit(context.Background())
+	assert.True(t, ok)
+	done2()
+	done3()
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverThrottledRequests(receiverName, limitReceiver, 1))
+}
+
+func TestAdmit_Global(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	global := newSemaphore(1)
+	c1 := newTestController(0, global)
+	c2 := newTestController(5, global)
+
+	done, ok := c1.Admit(context.Background())
+	require.True(t, ok)
+	_, ok = c2.Admit(context.Background())
+	assert.False(t, ok)
+	// The receiver limit is released when the global one is exceeded.
+	assert.Len(t, c2.limit, 0)
+
+	done()
+	done, ok = c2.Admit(context.Background())
+	assert.True(t, ok)
+	done()
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverThrottledRequests(receiverName, limitGlobal, 1))
+}
+
+func TestAdmit_Unlimited(t *testing.T) {
+	c := newTestController(0, nil)
+	for i := 0; i < 100; i++ {
+		_, ok := c.Admit(context.Background())
+		require.True(t, ok)
+	}
+}
+
+func TestSetGlobalLimit(t *testing.T) {
+	defer SetGlobalLimit(0)
+
+	SetGlobalLimit(1)
+	c := NewController(0, receiverName)
+	done, ok := c.Admit(context.Background())
+	require.True(t, ok)
+	_, ok = c.Admit(context.Background())
+	assert.False(t, ok)
+	done()
+
+	SetGlobalLimit(0)
+	assert.Nil(t, globalSemaphore())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := newTestController(1, nil)
+	interceptor := c.UnaryServerInterceptor()
+
+	var resp interface{}
+	var err error
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		// A concurrent call is throttled while this one is in flight.
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "inner", nil
+		})
+		return "outer", nil
+	}
+	resp, callErr := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, callErr)
+	assert.Equal(t, "outer", resp)
+	assert.Equal(t, ErrThrottled, err)
+
+	// The call released the limit.
+	assert.Len(t, c.limit, 0)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	msgs int
+}
+
+func (fs *fakeServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (fs *fakeServerStream) RecvMsg(m interface{}) error {
+	fs.msgs++
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	c := newTestController(1, nil)
+	interceptor := c.StreamServerInterceptor()
+
+	err := interceptor(nil, &fakeServerStream{}, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		// Each message releases the previous one.
+		for i := 0; i < 3; i++ {
+			require.NoError(t, ss.RecvMsg(nil))
+			assert.Len(t, c.limit, 1)
+		}
+		// A concurrent stream is throttled.
+		other := c.StreamServerInterceptor()
+		return other(nil, &fakeServerStream{}, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+			return ss.RecvMsg(nil)
+		})
+	})
+	assert.Equal(t, ErrThrottled, err)
+	assert.Len(t, c.limit, 0)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	c := newTestController(1, nil)
+	var inner *httptest.ResponseRecorder
+	handler := c.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = httptest.NewRecorder()
+		c.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})).ServeHTTP(inner, r)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusAccepted, rw.Code)
+	assert.Equal(t, http.StatusTooManyRequests, inner.Code)
+	assert.Equal(t, "1", inner.Header().Get("Retry-After"))
+	assert.Len(t, c.limit, 0)
+}
@@ -0,0 +1,162 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attributeindex classifies the span attributes as indexed or payload
+// attributes, so the exporters of backends distinguishing searchable fields
+// from stored-only data can limit their indexing cost. Payload attributes are
+// still exported, but in a form the backend doesn't index.
+package attributeindex
+
+import (
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// Settings marks the span attributes as indexed or payload. The keys ending
+// with "*" match the attributes starting with the rest of the key, e.g.
+// "http.request.*". The zero value indexes all the attributes.
+type Settings struct {
+	// Index lists the indexed attributes. If it is not empty, the attributes
+	// not listed are payload attributes.
+	Index []string `mapstructure:"index"`
+
+	// Payload lists the payload attributes, it takes precedence over Index.
+	Payload []string `mapstructure:"payload"`
+}
+
+// Classifier tells whether the attributes are indexed. A nil Classifier,
+// returned for the zero Settings, indexes all the attributes.
+type Classifier struct {
+	index   *matcher
+	payload *matcher
+}
+
+// NewClassifier creates the Classifier for the given settings.
+func NewClassifier(settings Settings) *Classifier {
+	if len(settings.Index) == 0 && len(settings.Payload) == 0 {
+		return nil
+	}
+	return &Classifier{
+		index:   newMatcher(settings.Index),
+		payload: newMatcher(settings.Payload),
+	}
+}
+
+// IsPayload returns true if the attribute must not be indexed.
+func (c *Classifier) IsPayload(key string) bool {
+	if c == nil {
+		return false
+	}
+	if c.payload.matches(key) {
+		return true
+	}
+	return c.index != nil && !c.index.matches(key)
+}
+
+// PayloadAsTimeEvent returns the trace data with the payload attributes of
+// each span moved to an annotation at the start of the span, for the backends
+// that index the span attributes but not the attributes of the span events,
+// such as Jaeger with its tags and logs. The input is not modified.
+func (c *Classifier) PayloadAsTimeEvent(td consumerdata.TraceData) consumerdata.TraceData {
+	if c == nil {
+		return td
+	}
+
+	spans := make([]*tracepb.Span, 0, len(td.Spans))
+	for _, span := range td.Spans {
+		spans = append(spans, c.spanPayloadAsTimeEvent(span))
+	}
+	td.Spans = spans
+	return td
+}
+
+func (c *Classifier) spanPayloadAsTimeEvent(span *tracepb.Span) *tracepb.Span {
+	var indexed, payload map[string]*tracepb.AttributeValue
+	for key, value := range span.GetAttributes().GetAttributeMap() {
+		if !c.IsPayload(key) {
+			continue
+		}
+		if payload == nil {
+			payload = make(map[string]*tracepb.AttributeValue)
+			indexed = make(map[string]*tracepb.AttributeValue, len(span.Attributes.AttributeMap))
+			for k, v := range span.Attributes.AttributeMap {
+				indexed[k] = v
+			}
+		}
+		payload[key] = value
+		delete(indexed, key)
+	}
+	if payload == nil {
+		return span
+	}
+
+	newSpan := *span
+	newSpan.Attributes = &tracepb.Span_Attributes{
+		AttributeMap:           indexed,
+		DroppedAttributesCount: span.Attributes.DroppedAttributesCount,
+	}
+	timeEvents := &tracepb.Span_TimeEvents{}
+	if span.TimeEvents != nil {
+		*timeEvents = *span.TimeEvents
+	}
+	timeEvents.TimeEvent = append([]*tracepb.Span_TimeEvent{{
+		Time: span.StartTime,
+		Value: &tracepb.Span_TimeEvent_Annotation_{
+			Annotation: &tracepb.Span_TimeEvent_Annotation{
+				Attributes: &tracepb.Span_Attributes{AttributeMap: payload},
+			},
+		},
+	}}, timeEvents.TimeEvent...)
+	newSpan.TimeEvents = timeEvents
+	return &newSpan
+}
+
+// matcher matches keys exactly or, for the keys ending with "*", by prefix.
+type matcher struct {
+	keys     map[string]bool
+	prefixes []string
+}
+
+func newMatcher(keys []string) *matcher {
+	if len(keys) == 0 {
+		return nil
+	}
+	m := &matcher{keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		if strings.HasSuffix(key, "*") {
+			m.prefixes = append(m.prefixes, strings.TrimSuffix(key, "*"))
+		} else {
+			m.keys[key] = true
+		}
+	}
+	return m
+}
+
+func (m *matcher) matches(key string) bool {
+	if m == nil {
+		return false
+	}
+	if m.keys[key] {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributeindex
+
+import (
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+func TestNewClassifier_Default(t *testing.T) {
+	c := NewClassifier(Settings{})
+	assert.Nil(t, c)
+	assert.False(t, c.IsPayload("http.request.body"))
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{{}}}
+	assert.Equal(t, td, c.PayloadAsTimeEvent(td))
+}
+
+func TestClassifier_IsPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		payload  []string
+		indexed  []string
+	}{
+		{
+			name:     "payload",
+			settings: Settings{Payload: []string{"http.request.*", "db.statement"}},
+			payload:  []string{"http.request.body", "http.request.header.cookie", "db.statement"},
+			indexed:  []string{"http.method", "db.statement.hash", "http.request"},
+		},
+		{
+			name:     "index",
+			settings: Settings{Index: []string{"http.*", "error"}},
+			payload:  []string{"db.statement", "errors", "customer.id"},
+			indexed:  []string{"http.method", "http.request.body", "error"},
+		},
+		{
+			name: "payload_over_index",
+			settings: Settings{
+				Index:   []string{"http.*"},
+				Payload: []string{"http.request.body"},
+			},
+			payload: []string{"http.request.body", "db.statement"},
+			indexed: []string{"http.method"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClassifier(tt.settings)
+			for _, key := range tt.payload {
+				assert.True(t, c.IsPayload(key), key)
+			}
+			for _, key := range tt.indexed {
+				assert.False(t, c.IsPayload(key), key)
+			}
+		})
+	}
+}
+
+func TestClassifier_PayloadAsTimeEvent(t *testing.T) {
+	start := &timestamp.Timestamp{Seconds: 1571000000}
+	method := &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "GET"}},
+	}
+	body := &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "{}"}},
+	}
+	event := &tracepb.Span_TimeEvent{
+		Value: &tracepb.Span_TimeEvent_Annotation_{
+			Annotation: &tracepb.Span_TimeEvent_Annotation{
+				Description: &tracepb.TruncatableString{Value: "retry"},
+			},
+		},
+	}
+	withPayload := &tracepb.Span{
+		StartTime: start,
+		Attributes: &tracepb.Span_Attributes{
+			AttributeMap:           map[string]*tracepb.AttributeValue{"http.method": method, "http.request.body": body},
+			DroppedAttributesCount: 2,
+		},
+		TimeEvents: &tracepb.Span_TimeEvents{TimeEvent: []*tracepb.Span_TimeEvent{event}},
+	}
+	withoutPayload := &tracepb.Span{
+		Attributes: &tracepb.Span_Attributes{
+			AttributeMap: map[string]*tracepb.AttributeValue{"http.method": method},
+		},
+	}
+
+	c := NewClassifier(Settings{Payload: []string{"http.request.*"}})
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{withPayload, withoutPayload}}
+	got := c.PayloadAsTimeEvent(td)
+
+	require.Len(t, got.Spans, 2)
+	assert.True(t, withoutPayload == got.Spans[1])
+
+	span := got.Spans[0]
+	assert.Equal(t, &tracepb.Span_Attributes{
+		AttributeMap:           map[string]*tracepb.AttributeValue{"http.method": method},
+		DroppedAttributesCount: 2,
+	}, span.Attributes)
+	require.Len(t, span.TimeEvents.TimeEvent, 2)
+	assert.Equal(t, &tracepb.Span_TimeEvent{
+		Time: start,
+		Value: &tracepb.Span_TimeEvent_Annotation_{
+			Annotation: &tracepb.Span_TimeEvent_Annotation{
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{"http.request.body": body},
+				},
+			},
+		},
+	}, span.TimeEvents.TimeEvent[0])
+	assert.True(t, event == span.TimeEvents.TimeEvent[1])
+
+	// The input is not modified.
+	assert.Len(t, withPayload.Attributes.AttributeMap, 2)
+	assert.Len(t, withPayload.TimeEvents.TimeEvent, 1)
+	assert.True(t, withPayload == td.Spans[0])
+}
@@ -0,0 +1,170 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/observability"
+)
+
+// The outcomes of the authentication recorded in the metrics.
+const (
+	OutcomeSuccess      = "success"
+	OutcomeMissingToken = "missing_token"
+	OutcomeInvalidToken = "invalid_token"
+	OutcomeNoValidator  = "no_validator"
+)
+
+const (
+	authorizationKey = "authorization"
+	bearerScheme     = "bearer "
+)
+
+// Authenticator authenticates the requests of a receiver with the validator
+// registered under a name. A nil Authenticator, returned when no validator is
+// configured, accepts all the requests.
+type Authenticator struct {
+	logger       *zap.Logger
+	validator    string
+	receiverName string
+	lookup       func(name string) Validator
+}
+
+// NewAuthenticator creates the Authenticator of the receiver using the named
+// validator, it returns nil if the name is empty. The validator is looked up
+// for each request, so the extension registering it may start after the
+// receiver; the requests are rejected while it is not registered.
+func NewAuthenticator(logger *zap.Logger, validator, receiverName string) *Authenticator {
+	if validator == "" {
+		return nil
+	}
+	return &Authenticator{
+		logger:       logger,
+		validator:    validator,
+		receiverName: receiverName,
+		lookup:       Lookup,
+	}
+}
+
+// Authenticate validates the bearer token of the "authorization" value, it
+// returns the context of the request, as returned by the validator, the
+// outcome of the authentication and whether it succeeded.
+func (a *Authenticator) Authenticate(ctx context.Context, authorization string) (context.Context, string, bool) {
+	ctx, outcome := a.authenticate(ctx, authorization)
+	observability.RecordAuthOutcome(observability.ContextWithReceiverName(ctx, a.receiverName), outcome)
+	return ctx, outcome, outcome == OutcomeSuccess
+}
+
+func (a *Authenticator) authenticate(ctx context.Context, authorization string) (context.Context, string) {
+	token := bearerToken(authorization)
+	if token == "" {
+		return ctx, OutcomeMissingToken
+	}
+	v := a.lookup(a.validator)
+	if v == nil {
+		a.logger.Warn("Rejected a request, the validator is not registered",
+			zap.String("receiver", a.receiverName), zap.String("validator", a.validator))
+		return ctx, OutcomeNoValidator
+	}
+	authCtx, err := v.Validate(ctx, token)
+	if err != nil {
+		a.logger.Debug("Rejected a request with an invalid token",
+			zap.String("receiver", a.receiverName), zap.Error(err))
+		return ctx, OutcomeInvalidToken
+	}
+	return authCtx, OutcomeSuccess
+}
+
+// UnaryServerInterceptor returns the gRPC interceptor authenticating the
+// unary calls with their "authorization" metadata. The calls failing the
+// authentication end with the UNAUTHENTICATED code.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticateGRPC(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor authenticating the
+// streams with their "authorization" metadata, once when they are opened.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateGRPC(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream is a stream with the context returned by the validator.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (as *authenticatedStream) Context() context.Context {
+	return as.ctx
+}
+
+func (a *Authenticator) authenticateGRPC(ctx context.Context) (context.Context, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationKey); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	ctx, outcome, ok := a.Authenticate(ctx, authorization)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, outcome)
+	}
+	return ctx, nil
+}
+
+// HTTPHandler wraps the handler to authenticate the HTTP requests with their
+// Authorization header. The requests failing the authentication are answered
+// with the 401 status.
+func (a *Authenticator) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, outcome, ok := a.Authenticate(r.Context(), r.Header.Get(authorizationKey))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, outcome, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bearerToken returns the token of a "Bearer <token>" authorization, or an
+// empty string for other schemes.
+func bearerToken(authorization string) string {
+	if len(authorization) <= len(bearerScheme) || !strings.EqualFold(authorization[:len(bearerScheme)], bearerScheme) {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len(bearerScheme):])
+}
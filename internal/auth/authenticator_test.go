@@ -0,0 +1,184 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+)
+
+const receiverName = "fake_receiver"
+
+var staticValidator = ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+	if token != "secret" {
+		return nil, ErrInvalidToken
+	}
+	return NewContextWithGroups(ctx, []string{"producers"}), nil
+})
+
+func newTestAuthenticator(v Validator) *Authenticator {
+	a := NewAuthenticator(zap.NewNop(), "static", receiverName)
+	a.lookup = func(name string) Validator {
+		if name != "static" {
+			return nil
+		}
+		return v
+	}
+	return a
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	assert.Nil(t, NewAuthenticator(zap.NewNop(), "", receiverName))
+	assert.NotNil(t, NewAuthenticator(zap.NewNop(), "static", receiverName))
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		authorization string
+		token         string
+	}{
+		{"Bearer secret", "secret"},
+		{"bearer secret", "secret"},
+		{"BEARER  secret ", "secret"},
+		{"Bearer ", ""},
+		{"Basic dXNlcjpwYXNz", ""},
+		{"secret", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.authorization, func(t *testing.T) {
+			assert.Equal(t, tt.token, bearerToken(tt.authorization))
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	a := newTestAuthenticator(staticValidator)
+	tests := []struct {
+		authorization string
+		outcome       string
+	}{
+		{"Bearer secret", OutcomeSuccess},
+		{"Bearer wrong", OutcomeInvalidToken},
+		{"", OutcomeMissingToken},
+		{"Basic c2VjcmV0", OutcomeMissingToken},
+	}
+	for _, tt := range tests {
+		ctx, outcome, ok := a.Authenticate(context.Background(), tt.authorization)
+		assert.Equal(t, tt.outcome, outcome)
+		assert.Equal(t, tt.outcome == OutcomeSuccess, ok)
+		require.NotNil(t, ctx)
+		if ok {
+			assert.Equal(t, []string{"producers"}, GroupsFromContext(ctx))
+		} else {
+			assert.Nil(t, GroupsFromContext(ctx))
+		}
+	}
+
+	// The requests are rejected until the validator is registered.
+	a = newTestAuthenticator(nil)
+	_, outcome, ok := a.Authenticate(context.Background(), "Bearer secret")
+	assert.Equal(t, OutcomeNoValidator, outcome)
+	assert.False(t, ok)
+
+	for _, outcome := range []string{OutcomeSuccess, OutcomeInvalidToken, OutcomeNoValidator} {
+		require.NoError(t, observabilitytest.CheckValueViewReceiverAuthRequests(receiverName, outcome, 1), outcome)
+	}
+	require.NoError(t, observabilitytest.CheckValueViewReceiverAuthRequests(receiverName, OutcomeMissingToken, 2))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := newTestAuthenticator(staticValidator).UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return GroupsFromContext(ctx), nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+	resp, err := interceptor(ctx, "request", &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"producers"}, resp)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer wrong"))
+	resp, err = interceptor(ctx, "request", &grpc.UnaryServerInfo{}, handler)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err = interceptor(context.Background(), "request", &grpc.UnaryServerInfo{}, handler)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *fakeServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := newTestAuthenticator(staticValidator).StreamServerInterceptor()
+	handled := 0
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		handled++
+		assert.Equal(t, []string{"producers"}, GroupsFromContext(ss.Context()))
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+	require.NoError(t, interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler))
+	assert.Equal(t, 1, handled)
+
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, 1, handled)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	handler := newTestAuthenticator(staticValidator).HTTPHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, []string{"producers"}, GroupsFromContext(r.Context()))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+}
@@ -0,0 +1,81 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth authenticates the requests of the receivers with the bearer
+// tokens they carry. The tokens are checked by a Validator registered by an
+// extension, e.g. one validating OIDC tokens or a list of static tokens, so
+// the receivers don't depend on the authentication scheme.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/registry"
+)
+
+// ErrInvalidToken is returned by validators rejecting a token.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Validator validates the credentials of the requests.
+type Validator interface {
+	// Validate returns the context of the request if the bearer token is
+	// valid, an error otherwise. The returned context may carry what the
+	// token tells about the client, e.g. its groups with NewContextWithGroups.
+	// It is called concurrently for each request.
+	Validate(ctx context.Context, token string) (context.Context, error)
+}
+
+// ValidatorFunc is a function implementing Validator.
+type ValidatorFunc func(ctx context.Context, token string) (context.Context, error)
+
+// Validate calls f(ctx, token).
+func (f ValidatorFunc) Validate(ctx context.Context, token string) (context.Context, error) {
+	return f(ctx, token)
+}
+
+type groupsKey struct{}
+
+// NewContextWithGroups returns a copy of the context carrying the groups the
+// authenticated client belongs to.
+func NewContextWithGroups(ctx context.Context, groups []string) context.Context {
+	return context.WithValue(ctx, groupsKey{}, groups)
+}
+
+// GroupsFromContext returns the groups of the authenticated client, if any.
+func GroupsFromContext(ctx context.Context) []string {
+	groups, _ := ctx.Value(groupsKey{}).([]string)
+	return groups
+}
+
+// validators links the validators provided by the extensions with the
+// receivers using them, since extensions are not part of the data pipelines.
+var validators = registry.New()
+
+// Register makes v the validator provided by the extension named name.
+func Register(name string, v Validator) {
+	validators.Register(name, v)
+}
+
+// Unregister removes the validator of the extension named name.
+func Unregister(name string) {
+	validators.Unregister(name)
+}
+
+// Lookup returns the validator of the extension named name or nil if the
+// extension is not started.
+func Lookup(name string) Validator {
+	v, _ := validators.Lookup(name).(Validator)
+	return v
+}
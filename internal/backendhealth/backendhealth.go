@@ -0,0 +1,97 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backendhealth shares the health of the backends, as probed by the
+// extensions, with the health check of the service. It separates the service
+// being up from the service being able to deliver the data to the backends.
+package backendhealth
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/registry"
+)
+
+// Backend is the health of a backend as of its last probe.
+type Backend struct {
+	Name      string    `json:"name"`
+	Endpoint  string    `json:"endpoint"`
+	Probe     string    `json:"probe"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	LastProbe time.Time `json:"last-probe"`
+	// LatencyMillis is the duration of the last probe.
+	LatencyMillis int64 `json:"latency-ms"`
+}
+
+// Source returns the health of the backends probed by an extension.
+type Source func() []Backend
+
+var sources = registry.New()
+
+// Register makes the health of the backends returned by source, probed by the
+// extension named name, part of the health of the service.
+func Register(name string, source Source) {
+	sources.Register(name, source)
+}
+
+// Unregister removes the source of the extension named name.
+func Unregister(name string) {
+	sources.Unregister(name)
+}
+
+// Backends returns the health of the backends of all the sources, sorted by
+// name.
+func Backends() []Backend {
+	backends := []Backend{}
+	for _, source := range sources.Values() {
+		backends = append(backends, source.(Source)()...)
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].Name < backends[j].Name
+	})
+	return backends
+}
+
+// report is the body served by Handler.
+type report struct {
+	Healthy  bool      `json:"healthy"`
+	Backends []Backend `json:"backends"`
+}
+
+// Handler serves the health of the backends as JSON, with the status 200 if
+// all the backends are healthy and 503 otherwise.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rep := report{Healthy: true, Backends: Backends()}
+		for _, b := range rep.Backends {
+			if !b.Healthy {
+				rep.Healthy = false
+			}
+		}
+		body, err := json.MarshalIndent(rep, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !rep.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write(body)
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendhealth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T) (int, report) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/backends", nil))
+	var rep report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rep))
+	return rec.Code, rep
+}
+
+func TestHandler(t *testing.T) {
+	code, rep := serve(t)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, rep.Healthy)
+	assert.Empty(t, rep.Backends)
+
+	Register("a", func() []Backend {
+		return []Backend{{Name: "zipkin", Healthy: true}, {Name: "jaeger", Healthy: true}}
+	})
+	defer Unregister("a")
+	code, rep = serve(t)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, rep.Healthy)
+	assert.Equal(t, []Backend{{Name: "jaeger", Healthy: true}, {Name: "zipkin", Healthy: true}}, rep.Backends)
+
+	Register("b", func() []Backend {
+		return []Backend{{Name: "opencensus", Error: "connection refused"}}
+	})
+	code, rep = serve(t)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, rep.Healthy)
+	assert.Len(t, rep.Backends, 3)
+
+	Unregister("b")
+	code, _ = serve(t)
+	assert.Equal(t, http.StatusOK, code)
+}
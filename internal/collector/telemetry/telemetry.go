@@ -22,7 +22,10 @@ import (
 
 const (
 	// None indicates that no telemetry data should be collected.
-	None Level = iota - 1
+	None Level = iota - 2
+	// Minimal reduces basic to the cheapest views and dimensions, it is meant
+	// for small agents where the cost of internal metrics matters.
+	Minimal
 	// Basic is the default and covers the basics of the service telemetry.
 	Basic
 	// Normal adds some other indicators on top of basic.
@@ -34,6 +37,24 @@ const (
 // Level of telemetry data to be generated.
 type Level int8
 
+// String returns the lower-case name of the level, as accepted by ParseLevel.
+func (l Level) String() string {
+	switch l {
+	case None:
+		return "none"
+	case Minimal:
+		return "minimal"
+	case Basic:
+		return "basic"
+	case Normal:
+		return "normal"
+	case Detailed:
+		return "detailed"
+	default:
+		return fmt.Sprintf("Level(%d)", int8(l))
+	}
+}
+
 // ParseLevel returns the Level represented by the string. The parsing is case-insensitive
 // and it returns error if the string value is unknown.
 func ParseLevel(s string) (Level, error) {
@@ -42,6 +63,8 @@ func ParseLevel(s string) (Level, error) {
 	switch str {
 	case "none":
 		level = None
+	case "minimal":
+		level = Minimal
 	case "", "basic":
 		level = Basic
 	case "normal":
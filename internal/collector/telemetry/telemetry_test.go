@@ -0,0 +1,62 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    Level
+		wantErr bool
+	}{
+		{str: "", want: Basic},
+		{str: "NONE", want: None},
+		{str: "Minimal", want: Minimal},
+		{str: "basic", want: Basic},
+		{str: "Normal", want: Normal},
+		{str: "detailed", want: Detailed},
+		{str: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, err := ParseLevel(tt.str)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultLevelIsBasic(t *testing.T) {
+	var level Level
+	assert.Equal(t, Basic, level)
+}
+
+func TestLevelStringRoundTrip(t *testing.T) {
+	for _, level := range []Level{None, Minimal, Basic, Normal, Detailed} {
+		got, err := ParseLevel(level.String())
+		assert.NoError(t, err)
+		assert.Equal(t, level, got)
+	}
+	assert.Equal(t, "Level(7)", Level(7).String())
+}
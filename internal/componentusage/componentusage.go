@@ -0,0 +1,254 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package componentusage accounts the CPU time and the memory allocated by the
+// processors and the exporters of the pipelines.
+//
+// One batch out of every sampling rate batches entering a pipeline is
+// measured: the goroutine is locked to its thread while each component
+// consumes it, so that the CPU time of the thread is the CPU time of the
+// component. The time and the allocations of the downstream components called
+// synchronously are subtracted, and the measurements are multiplied by the
+// sampling rate to estimate the totals. The allocations are read from the
+// process-wide counter, so they also include the allocations made by other
+// goroutines meanwhile and are only meaningful relative to each other.
+package componentusage
+
+import (
+	"context"
+	"runtime"
+	"runtime/metrics"
+	"sync/atomic"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+// Kinds of the accounted components.
+const (
+	KindProcessor = "processor"
+	KindExporter  = "exporter"
+	KindConnector = "connector"
+)
+
+const allocsMetric = "/gc/heap/allocs:bytes"
+
+var (
+	mComponentCPUSeconds     = stats.Float64("otelsvc/component/cpu_seconds", "Estimated CPU time spent by the component", "s")
+	mComponentAllocatedBytes = stats.Int64("otelsvc/component/allocated_bytes", "Estimated number of bytes allocated by the component", stats.UnitBytes)
+)
+
+// TagKeyComponentKind defines the tag key for the kind of the component.
+var TagKeyComponentKind, _ = tag.NewKey("otelsvc_component_kind")
+
+// TagKeyComponent defines the tag key for the name of the component.
+var TagKeyComponent, _ = tag.NewKey("otelsvc_component")
+
+// ViewComponentCPUSeconds defines the view for the component CPU time metric.
+var ViewComponentCPUSeconds = &view.View{
+	Name:        mComponentCPUSeconds.Name(),
+	Description: mComponentCPUSeconds.Description(),
+	Measure:     mComponentCPUSeconds,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyComponentKind, TagKeyComponent},
+}
+
+// ViewComponentAllocatedBytes defines the view for the component allocated bytes metric.
+var ViewComponentAllocatedBytes = &view.View{
+	Name:        mComponentAllocatedBytes.Name(),
+	Description: mComponentAllocatedBytes.Description(),
+	Measure:     mComponentAllocatedBytes,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyComponentKind, TagKeyComponent},
+}
+
+// samplingRate is the rate set by SetSamplingRate, zero when the accounting
+// is disabled.
+var samplingRate uint64
+
+// SetSamplingRate enables the accounting of the components wrapped afterwards,
+// measuring one batch out of every rate batches. A rate of zero disables it.
+// The accounting is only supported on Linux, where the CPU time of a thread
+// can be read.
+func SetSamplingRate(rate uint64) {
+	atomic.StoreUint64(&samplingRate, rate)
+}
+
+// Enabled returns true if the components wrapped now are accounted.
+func Enabled() bool {
+	return supported && atomic.LoadUint64(&samplingRate) > 0
+}
+
+// MetricViews returns the views of the accounting metrics according to the
+// given telemetry level, none if the accounting is disabled.
+func MetricViews(level telemetry.Level) []*view.View {
+	if level == telemetry.None || !Enabled() {
+		return nil
+	}
+	return []*view.View{ViewComponentCPUSeconds, ViewComponentAllocatedBytes}
+}
+
+// WrapTraceConsumer returns a consumer accounting the usage of tc under the
+// given kind and name, or tc itself if the accounting is disabled.
+func WrapTraceConsumer(kind, name string, tc consumer.TraceConsumer) consumer.TraceConsumer {
+	if !Enabled() || tc == nil {
+		return tc
+	}
+	c := &traceConsumer{accountant: newAccountant(kind, name), next: tc}
+	if rtc, ok := tc.(consumer.RawTraceConsumer); ok {
+		return &rawTraceConsumer{traceConsumer: c, next: rtc}
+	}
+	return c
+}
+
+// WrapMetricsConsumer returns a consumer accounting the usage of mc under the
+// given kind and name, or mc itself if the accounting is disabled.
+func WrapMetricsConsumer(kind, name string, mc consumer.MetricsConsumer) consumer.MetricsConsumer {
+	if !Enabled() || mc == nil {
+		return mc
+	}
+	return &metricsConsumer{accountant: newAccountant(kind, name), next: mc}
+}
+
+type traceConsumer struct {
+	*accountant
+	next consumer.TraceConsumer
+}
+
+func (c *traceConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	return c.measure(ctx, func(ctx context.Context) error {
+		return c.next.ConsumeTraceData(ctx, td)
+	})
+}
+
+// rawTraceConsumer wraps the consumers accepting raw trace data, so that the
+// raw data is still relayed to them.
+type rawTraceConsumer struct {
+	*traceConsumer
+	next consumer.RawTraceConsumer
+}
+
+var _ consumer.RawTraceConsumer = (*rawTraceConsumer)(nil)
+
+func (c *rawTraceConsumer) AcceptsRawTraceFormat(format string) bool {
+	return c.next.AcceptsRawTraceFormat(format)
+}
+
+func (c *rawTraceConsumer) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error {
+	return c.measure(ctx, func(ctx context.Context) error {
+		return c.next.ConsumeRawTraceData(ctx, rtd)
+	})
+}
+
+type metricsConsumer struct {
+	*accountant
+	next consumer.MetricsConsumer
+}
+
+func (c *metricsConsumer) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	return c.measure(ctx, func(ctx context.Context) error {
+		return c.next.ConsumeMetricsData(ctx, md)
+	})
+}
+
+type frameKey struct{}
+
+// frame is the measurement of a batch by a component, passed to the
+// downstream components in the context. The batches are sampled when they
+// enter the first component, the downstream components measure a batch if and
+// only if it is sampled so that they all measure at the same rate.
+type frame struct {
+	sampled bool
+	// thread is the thread the component is locked to, the downstream
+	// components running on the same thread while the frame is open are
+	// called synchronously.
+	thread int
+	closed int32
+	// childCPU and childAllocs are the usage of the synchronous downstream
+	// components, in nanoseconds and bytes.
+	childCPU    int64
+	childAllocs int64
+}
+
+var unsampledFrame = &frame{}
+
+type accountant struct {
+	ctx   context.Context
+	rate  uint64
+	count uint64
+}
+
+func newAccountant(kind, name string) *accountant {
+	ctx, _ := tag.New(context.Background(),
+		tag.Upsert(TagKeyComponentKind, kind, tag.WithTTL(tag.TTLNoPropagation)),
+		tag.Upsert(TagKeyComponent, name, tag.WithTTL(tag.TTLNoPropagation)))
+	return &accountant{ctx: ctx, rate: atomic.LoadUint64(&samplingRate)}
+}
+
+// measure runs consume, measuring its usage if the batch is sampled.
+func (a *accountant) measure(ctx context.Context, consume func(context.Context) error) error {
+	parent, _ := ctx.Value(frameKey{}).(*frame)
+	if parent == nil {
+		if atomic.AddUint64(&a.count, 1)%a.rate != 0 {
+			return consume(context.WithValue(ctx, frameKey{}, unsampledFrame))
+		}
+	} else if !parent.sampled {
+		return consume(ctx)
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	f := &frame{sampled: true, thread: threadID()}
+	startCPU := threadCPUTime()
+	startAllocs := allocatedBytes()
+	err := consume(context.WithValue(ctx, frameKey{}, f))
+	cpu := int64(threadCPUTime() - startCPU)
+	allocs := int64(allocatedBytes() - startAllocs)
+	// The frame must be closed before the thread is unlocked, afterwards
+	// the goroutines of asynchronous downstream components can run on it.
+	atomic.StoreInt32(&f.closed, 1)
+
+	if parent != nil && parent.thread == f.thread && atomic.LoadInt32(&parent.closed) == 0 {
+		atomic.AddInt64(&parent.childCPU, cpu)
+		atomic.AddInt64(&parent.childAllocs, allocs)
+	}
+	cpu -= atomic.LoadInt64(&f.childCPU)
+	allocs -= atomic.LoadInt64(&f.childAllocs)
+	if cpu < 0 {
+		cpu = 0
+	}
+	if allocs < 0 {
+		allocs = 0
+	}
+
+	stats.Record(a.ctx,
+		mComponentCPUSeconds.M(float64(cpu)/1e9*float64(a.rate)),
+		mComponentAllocatedBytes.M(allocs*int64(a.rate)))
+	return err
+}
+
+func allocatedBytes() uint64 {
+	sample := []metrics.Sample{{Name: allocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
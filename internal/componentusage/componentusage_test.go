@@ -0,0 +1,127 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package componentusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+// busyConsumer spins on the CPU for a while before calling the next consumer.
+type busyConsumer struct {
+	next    consumer.TraceConsumer
+	sampled []bool
+}
+
+func (bc *busyConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	f, _ := ctx.Value(frameKey{}).(*frame)
+	bc.sampled = append(bc.sampled, f != nil && f.sampled)
+	for start := time.Now(); time.Since(start) < 20*time.Millisecond; {
+	}
+	return bc.next.ConsumeTraceData(ctx, td)
+}
+
+func TestWrapDisabled(t *testing.T) {
+	SetSamplingRate(0)
+	sink := &exportertest.SinkTraceExporter{}
+	assert.Equal(t, consumer.TraceConsumer(sink), WrapTraceConsumer(KindExporter, "sink", sink))
+	assert.Nil(t, MetricViews(telemetry.Detailed))
+}
+
+func TestWrapRawTraceConsumer(t *testing.T) {
+	SetSamplingRate(1)
+	defer SetSamplingRate(0)
+
+	_, ok := WrapTraceConsumer(KindExporter, "sink", &exportertest.SinkTraceExporter{}).(consumer.RawTraceConsumer)
+	assert.False(t, ok, "the consumers not accepting raw trace data must not be made to")
+
+	var pushed []consumerdata.RawTraceData
+	te, err := exporterhelper.NewTraceExporter("raw", func(context.Context, consumerdata.TraceData) (int, error) {
+		return 0, nil
+	}, exporterhelper.WithRawTraceData("raw", func(_ context.Context, rtd consumerdata.RawTraceData) error {
+		pushed = append(pushed, rtd)
+		return nil
+	}))
+	require.NoError(t, err)
+	rtc, ok := WrapTraceConsumer(KindExporter, "raw", te).(consumer.RawTraceConsumer)
+	require.True(t, ok)
+	assert.True(t, rtc.AcceptsRawTraceFormat("raw"))
+	assert.False(t, rtc.AcceptsRawTraceFormat("jaeger"))
+
+	rtd := consumerdata.RawTraceData{Format: "raw", Payload: []byte{1}}
+	require.NoError(t, rtc.ConsumeRawTraceData(context.Background(), rtd))
+	assert.Equal(t, []consumerdata.RawTraceData{rtd}, pushed)
+}
+
+func TestSampling(t *testing.T) {
+	if !supported {
+		t.Skip("accounting not supported on this platform")
+	}
+	SetSamplingRate(3)
+	defer SetSamplingRate(0)
+
+	tail := &busyConsumer{next: &exportertest.SinkTraceExporter{}}
+	head := &busyConsumer{next: WrapTraceConsumer(KindProcessor, "tail", tail)}
+	tc := WrapTraceConsumer(KindProcessor, "head", head)
+	for i := 0; i < 6; i++ {
+		require.NoError(t, tc.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	}
+
+	// The downstream components measure the same batches as the first one.
+	want := []bool{false, false, true, false, false, true}
+	assert.Equal(t, want, head.sampled)
+	assert.Equal(t, want, tail.sampled)
+}
+
+func TestMeasure(t *testing.T) {
+	if !supported {
+		t.Skip("accounting not supported on this platform")
+	}
+	SetSamplingRate(1)
+	defer SetSamplingRate(0)
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	exp := WrapTraceConsumer(KindExporter, "measure-exporter", &exportertest.SinkTraceExporter{})
+	tc := WrapTraceConsumer(KindProcessor, "measure-processor", &busyConsumer{next: exp})
+	require.NoError(t, tc.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+
+	rows, err := view.RetrieveData(ViewComponentCPUSeconds.Name)
+	require.NoError(t, err)
+	cpu := map[string]float64{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == TagKeyComponent {
+				cpu[tag.Value] = row.Data.(*view.SumData).Value
+			}
+		}
+	}
+	require.Len(t, cpu, 2)
+	// The time spent by the exporter is not accounted to the processor.
+	assert.True(t, cpu["measure-processor"] >= 0.01, "processor CPU time %v", cpu["measure-processor"])
+	assert.True(t, cpu["measure-exporter"] < cpu["measure-processor"], "exporter CPU time %v", cpu["measure-exporter"])
+}
@@ -20,6 +20,8 @@ package viperutils
 import (
 	"bytes"
 	"flag"
+	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -45,6 +47,61 @@ func LoadYAMLBytes(v *viper.Viper, yamlBlob []byte) error {
 	return nil
 }
 
+// IncludeKey is the top-level configuration key listing the files included by
+// a config file.
+const IncludeKey = "include"
+
+// MergeConfigFiles deep-merges the given config files into v, in order, so
+// that the settings of a file override the ones of the previous files and of
+// the configuration already in v. The maps are merged key by key, any other
+// value, including lists, is replaced.
+//
+// The files listed under IncludeKey in a file are merged before the file
+// itself, so that the file overrides them. Their paths are relative to the
+// directory of the file including them.
+func MergeConfigFiles(v *viper.Viper, files ...string) error {
+	for _, file := range files {
+		if err := mergeConfigFile(v, file, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeConfigFile merges file and its includes into v. including is the chain
+// of files including file, used to detect cycles.
+func mergeConfigFile(v *viper.Viper, file string, including []string) error {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	for _, p := range including {
+		if p == path {
+			return fmt.Errorf("config file %q is included in a cycle: %s", file, strings.Join(append(including, path), " -> "))
+		}
+	}
+
+	fv := viper.New()
+	fv.SetConfigFile(path)
+	if err := fv.ReadInConfig(); err != nil {
+		return fmt.Errorf("error loading config file %q: %v", file, err)
+	}
+	for _, include := range fv.GetStringSlice(IncludeKey) {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if err := mergeConfigFile(v, include, append(including, path)); err != nil {
+			return err
+		}
+	}
+
+	v.SetConfigFile(path)
+	if err := v.MergeInConfig(); err != nil {
+		return fmt.Errorf("error loading config file %q: %v", file, err)
+	}
+	return nil
+}
+
 // AddFlags adds the provided flags to the provided viper and cobra command.
 func AddFlags(v *viper.Viper, command *cobra.Command, addFlagsFns ...func(*flag.FlagSet)) (*viper.Viper, *cobra.Command) {
 	flagSet := new(flag.FlagSet)
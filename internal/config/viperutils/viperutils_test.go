@@ -14,4 +14,48 @@
 
 package viperutils
 
-// TODO: Add tests
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigFiles(t *testing.T) {
+	v := viper.New()
+	require.NoError(t, MergeConfigFiles(v, "testdata/env.yaml", "testdata/override.yaml"))
+
+	// Included by env.yaml.
+	assert.Equal(t, "0.0.0.0:55678", v.GetString("receivers.opencensus.endpoint"))
+	assert.Equal(t, "gzip", v.GetString("exporters.opencensus.compression"))
+	// Overridden by env.yaml.
+	assert.Equal(t, "staging-backend:55678", v.GetString("exporters.opencensus.endpoint"))
+	// Overridden by override.yaml, the lists are replaced.
+	assert.Equal(t, []string{"jaeger"}, v.GetStringSlice("pipelines.traces.receivers"))
+	assert.Equal(t, []string{"opencensus"}, v.GetStringSlice("pipelines.traces.exporters"))
+}
+
+func TestMergeConfigFilesOverridesExisting(t *testing.T) {
+	v, err := ViperFromYAMLBytes([]byte(`
+exporters:
+  opencensus:
+    endpoint: "preset:55678"
+    num-workers: 8
+`))
+	require.NoError(t, err)
+	require.NoError(t, MergeConfigFiles(v, "testdata/base.yaml"))
+
+	assert.Equal(t, "backend:55678", v.GetString("exporters.opencensus.endpoint"))
+	assert.Equal(t, 8, v.GetInt("exporters.opencensus.num-workers"))
+}
+
+func TestMergeConfigFilesErrors(t *testing.T) {
+	err := MergeConfigFiles(viper.New(), "testdata/cycle/a.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+
+	err = MergeConfigFiles(viper.New(), "testdata/base.yaml", "testdata/missing.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+}
@@ -0,0 +1,270 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package decompression decompresses the bodies of the HTTP requests of the
+// receivers according to their Content-Encoding header, within limits of the
+// decompressed size and of the compression ratio so that compression bombs
+// are rejected before they exhaust the memory.
+package decompression
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/open-telemetry/opentelemetry-service/compression"
+)
+
+const (
+	// DefaultMaxDecompressedSize is the default maximum size, in bytes, of
+	// the decompressed bodies.
+	DefaultMaxDecompressedSize = 32 << 20
+	// DefaultMaxRatio is the default maximum ratio between the decompressed
+	// and the compressed sizes of the bodies.
+	DefaultMaxRatio = 100
+
+	// minRatioCheckSize is the decompressed size from which the ratio is
+	// enforced, small bodies are legitimately very compressible.
+	minRatioCheckSize = 1 << 20
+)
+
+// ErrBodyTooLarge is returned when reading a body exceeding the limits.
+var ErrBodyTooLarge = errors.New("decompressed body exceeds the limits")
+
+var errReaderClosed = errors.New("decompressed body is closed")
+
+// Settings are the limits of the decompressed bodies.
+type Settings struct {
+	// MaxDecompressedSize is the maximum size, in bytes, of the decompressed
+	// bodies. The size is not limited if it is 0.
+	MaxDecompressedSize int64 `mapstructure:"max-decompressed-size"`
+
+	// MaxRatio is the maximum ratio between the decompressed and the
+	// compressed sizes of the bodies, enforced once more than 1MiB is
+	// decompressed. The ratio is not limited if it is 0.
+	MaxRatio int64 `mapstructure:"max-ratio"`
+}
+
+// DefaultSettings returns the default limits.
+func DefaultSettings() Settings {
+	return Settings{
+		MaxDecompressedSize: DefaultMaxDecompressedSize,
+		MaxRatio:            DefaultMaxRatio,
+	}
+}
+
+// Validate checks that the limits are not negative.
+func (s Settings) Validate() error {
+	if s.MaxDecompressedSize < 0 {
+		return errors.New("max-decompressed-size must not be negative")
+	}
+	if s.MaxRatio < 0 {
+		return errors.New("max-ratio must not be negative")
+	}
+	return nil
+}
+
+// encoding returns the Content-Encoding of r, lowercased.
+func encoding(r *http.Request) string {
+	return strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+}
+
+// IsCompressed reports whether the body of r is decompressed by NewReader.
+func IsCompressed(r *http.Request) bool {
+	switch encoding(r) {
+	case compression.Gzip, "x-gzip", "deflate", "zlib", compression.Zstd:
+		return true
+	}
+	return false
+}
+
+// NewReader returns the reader of the decompressed body of r according to its
+// Content-Encoding header: gzip, deflate, zlib or zstd. The body is returned
+// as is for the other encodings. Reading beyond the limits of the settings
+// fails with ErrBodyTooLarge. The gzip and zlib readers are pooled, they are
+// reused once the returned reader is closed.
+func NewReader(r *http.Request, settings Settings) (io.ReadCloser, error) {
+	compressed := &countingReader{r: r.Body}
+	var (
+		decompressed io.ReadCloser
+		err          error
+	)
+	switch encoding(r) {
+	case compression.Gzip, "x-gzip":
+		decompressed, err = newGzipReader(compressed)
+	case "deflate", "zlib":
+		decompressed, err = newZlibReader(compressed)
+	case compression.Zstd:
+		var dec *zstd.Decoder
+		if dec, err = zstd.NewReader(compressed, zstd.WithDecoderConcurrency(1)); err == nil {
+			decompressed = &zstdReadCloser{Decoder: dec}
+		}
+	default:
+		return r.Body, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &limitedReader{
+		r:          decompressed,
+		compressed: compressed,
+		settings:   settings,
+	}, nil
+}
+
+// ReadAll reads the decompressed body of r, see NewReader.
+func ReadAll(r *http.Request, settings Settings) ([]byte, error) {
+	body, err := NewReader(r, settings)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// StatusCode returns the HTTP status code of the requests failing to be read
+// with err: 413 if the body exceeds the limits, 400 otherwise.
+func StatusCode(err error) int {
+	if err == ErrBodyTooLarge {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// limitedReader fails with ErrBodyTooLarge once the data read from r exceeds
+// the limits of the settings.
+type limitedReader struct {
+	r          io.ReadCloser
+	compressed *countingReader
+	settings   Settings
+	n          int64
+	exceeded   bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.exceedsLimits() {
+		l.exceeded = true
+		return 0, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedReader) exceedsLimits() bool {
+	if l.settings.MaxDecompressedSize > 0 && l.n > l.settings.MaxDecompressedSize {
+		return true
+	}
+	return l.settings.MaxRatio > 0 &&
+		l.n > minRatioCheckSize &&
+		l.n > l.settings.MaxRatio*l.compressed.n
+}
+
+func (l *limitedReader) Close() error {
+	return l.r.Close()
+}
+
+var (
+	gzipReaders sync.Pool
+	zlibReaders sync.Pool
+)
+
+// pooledReader returns its decompressor to its pool on the first Close, it
+// must not be read once closed since the decompressor may be reused by another
+// request.
+type pooledReader struct {
+	io.ReadCloser
+	pool *sync.Pool
+}
+
+func (p *pooledReader) Read(b []byte) (int, error) {
+	if p.ReadCloser == nil {
+		return 0, errReaderClosed
+	}
+	return p.ReadCloser.Read(b)
+}
+
+func (p *pooledReader) Close() error {
+	if p.ReadCloser == nil {
+		return nil
+	}
+	err := p.ReadCloser.Close()
+	p.pool.Put(p.ReadCloser)
+	p.ReadCloser = nil
+	return err
+}
+
+// newGzipReader returns a gzip reader of the pool reset to read r.
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	if zr, ok := gzipReaders.Get().(*gzip.Reader); ok {
+		if err := zr.Reset(r); err != nil {
+			gzipReaders.Put(zr)
+			return nil, err
+		}
+		return &pooledReader{ReadCloser: zr, pool: &gzipReaders}, nil
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledReader{ReadCloser: zr, pool: &gzipReaders}, nil
+}
+
+// newZlibReader returns a zlib reader of the pool reset to read r.
+func newZlibReader(r io.Reader) (io.ReadCloser, error) {
+	if zr, ok := zlibReaders.Get().(io.ReadCloser); ok {
+		if err := zr.(zlib.Resetter).Reset(r, nil); err != nil {
+			zlibReaders.Put(zr)
+			return nil, err
+		}
+		return &pooledReader{ReadCloser: zr, pool: &zlibReaders}, nil
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledReader{ReadCloser: zr, pool: &zlibReaders}, nil
+}
+
+// zstdReadCloser releases the resources of the decoder on Close.
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (z *zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
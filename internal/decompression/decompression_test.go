@@ -0,0 +1,184 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decompression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate", "zlib":
+		w = zlib.NewWriter(&buf)
+	case "zstd":
+		enc, err := zstd.NewWriter(&buf)
+		require.NoError(t, err)
+		w = enc
+	default:
+		return data
+	}
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func newRequest(t *testing.T, encoding string, data []byte) *http.Request {
+	r, err := http.NewRequest(http.MethodPost, "http://localhost/", bytes.NewReader(compress(t, encoding, data)))
+	require.NoError(t, err)
+	if encoding != "" {
+		r.Header.Set("Content-Encoding", encoding)
+	}
+	return r
+}
+
+func TestReadAll(t *testing.T) {
+	data := []byte(strings.Repeat("span", 1000))
+	for _, encoding := range []string{"", "gzip", "deflate", "zlib", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			got, err := ReadAll(newRequest(t, encoding, data), DefaultSettings())
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
+
+func TestReadAll_MaxDecompressedSize(t *testing.T) {
+	data := []byte(strings.Repeat("span", 1000))
+	for _, encoding := range []string{"gzip", "zlib", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			_, err := ReadAll(newRequest(t, encoding, data), Settings{MaxDecompressedSize: 1000})
+			assert.Equal(t, ErrBodyTooLarge, err)
+
+			got, err := ReadAll(newRequest(t, encoding, data), Settings{MaxDecompressedSize: 4000})
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
+
+func TestReadAll_MaxRatio(t *testing.T) {
+	// A bomb of zeros is compressed more than a 1000 times.
+	bomb := make([]byte, 8<<20)
+	for _, encoding := range []string{"gzip", "zlib", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			_, err := ReadAll(newRequest(t, encoding, bomb), Settings{MaxRatio: 100})
+			assert.Equal(t, ErrBodyTooLarge, err)
+
+			got, err := ReadAll(newRequest(t, encoding, bomb), Settings{})
+			require.NoError(t, err)
+			assert.Equal(t, len(bomb), len(got))
+		})
+	}
+
+	// Small bodies are not subject to the ratio.
+	small := make([]byte, 64<<10)
+	got, err := ReadAll(newRequest(t, "gzip", small), Settings{MaxRatio: 2})
+	require.NoError(t, err)
+	assert.Equal(t, small, got)
+}
+
+func TestReadAll_InvalidBody(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://localhost/", strings.NewReader("not gzip"))
+	require.NoError(t, err)
+	r.Header.Set("Content-Encoding", "gzip")
+	_, err = ReadAll(r, DefaultSettings())
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, StatusCode(err))
+}
+
+func TestReadAll_PooledReaders(t *testing.T) {
+	for _, encoding := range []string{"gzip", "zlib"} {
+		t.Run(encoding, func(t *testing.T) {
+			// The readers returned to the pool are reset for the next bodies.
+			for i := 0; i < 3; i++ {
+				data := []byte(strings.Repeat(string(rune('a'+i)), 1000))
+				got, err := ReadAll(newRequest(t, encoding, data), DefaultSettings())
+				require.NoError(t, err)
+				assert.Equal(t, data, got)
+			}
+		})
+	}
+}
+
+func TestNewReader_CloseTwice(t *testing.T) {
+	for _, encoding := range []string{"gzip", "zlib"} {
+		t.Run(encoding, func(t *testing.T) {
+			data := []byte(strings.Repeat("span", 1000))
+			rc, err := NewReader(newRequest(t, encoding, data), DefaultSettings())
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			require.NoError(t, rc.Close())
+			_, err = rc.Read(make([]byte, 1))
+			assert.Error(t, err)
+
+			// The decompressor is only in the pool once: the next readers
+			// get distinct decompressors.
+			r1, err := NewReader(newRequest(t, encoding, data), DefaultSettings())
+			require.NoError(t, err)
+			r2, err := NewReader(newRequest(t, encoding, []byte("other")), DefaultSettings())
+			require.NoError(t, err)
+			got1, err := ioutil.ReadAll(r1)
+			require.NoError(t, err)
+			got2, err := ioutil.ReadAll(r2)
+			require.NoError(t, err)
+			assert.Equal(t, data, got1)
+			assert.Equal(t, []byte("other"), got2)
+			require.NoError(t, r1.Close())
+			require.NoError(t, r2.Close())
+		})
+	}
+}
+
+func TestIsCompressed(t *testing.T) {
+	for _, encoding := range []string{"gzip", "x-gzip", " GZIP ", "deflate", "zlib", "zstd"} {
+		r, err := http.NewRequest(http.MethodPost, "http://localhost/", nil)
+		require.NoError(t, err)
+		r.Header.Set("Content-Encoding", encoding)
+		assert.True(t, IsCompressed(r), encoding)
+	}
+	for _, encoding := range []string{"", "identity", "br"} {
+		r, err := http.NewRequest(http.MethodPost, "http://localhost/", nil)
+		require.NoError(t, err)
+		r.Header.Set("Content-Encoding", encoding)
+		assert.False(t, IsCompressed(r), encoding)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusRequestEntityTooLarge, StatusCode(ErrBodyTooLarge))
+}
+
+func TestSettings_Validate(t *testing.T) {
+	assert.NoError(t, DefaultSettings().Validate())
+	assert.NoError(t, Settings{}.Validate())
+	assert.Error(t, Settings{MaxDecompressedSize: -1}.Validate())
+	assert.Error(t, Settings{MaxRatio: -1}.Validate())
+}
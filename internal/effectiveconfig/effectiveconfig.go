@@ -0,0 +1,207 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package effectiveconfig renders the configuration the service runs with,
+// after the defaults of the components were applied, with the values of the
+// settings holding secrets redacted.
+package effectiveconfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/config/configsecret"
+)
+
+// RedactedValue replaces the values of the settings holding secrets.
+const RedactedValue = "<redacted>"
+
+// sensitiveKeys are the substrings identifying, case-insensitively, the keys of
+// the settings holding secrets. Header names are keys as well, e.g. the value
+// of an "Authorization" header is redacted.
+var sensitiveKeys = []string{
+	"authorization",
+	"credential",
+	"password",
+	"secret",
+	"token",
+	"api-key",
+	"apikey",
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Map returns the configuration with the same structure as the configuration
+// file, keyed by the names of the settings.
+func Map(cfg *configmodels.Config) map[string]interface{} {
+	receivers := make(map[string]interface{}, len(cfg.Receivers))
+	for name, r := range cfg.Receivers {
+		receivers[name] = value(reflect.ValueOf(r))
+	}
+	processors := make(map[string]interface{}, len(cfg.Processors))
+	for name, p := range cfg.Processors {
+		processors[name] = value(reflect.ValueOf(p))
+	}
+	exporters := make(map[string]interface{}, len(cfg.Exporters))
+	for name, e := range cfg.Exporters {
+		exporters[name] = value(reflect.ValueOf(e))
+	}
+	extensions := make(map[string]interface{}, len(cfg.Extensions))
+	for name, e := range cfg.Extensions {
+		extensions[name] = value(reflect.ValueOf(e))
+	}
+	connectors := make(map[string]interface{}, len(cfg.Connectors))
+	for name, c := range cfg.Connectors {
+		connectors[name] = value(reflect.ValueOf(c))
+	}
+	pipelines := make(map[string]interface{}, len(cfg.Pipelines))
+	for name, p := range cfg.Pipelines {
+		pipelines[name] = value(reflect.ValueOf(p))
+	}
+	return map[string]interface{}{
+		"receivers":  receivers,
+		"processors": processors,
+		"exporters":  exporters,
+		"extensions": extensions,
+		"connectors": connectors,
+		"pipelines":  pipelines,
+		"service":    value(reflect.ValueOf(cfg.Service)),
+	}
+}
+
+// YAML returns the configuration in the YAML format of the configuration file.
+func YAML(cfg *configmodels.Config) ([]byte, error) {
+	return yaml.Marshal(Map(cfg))
+}
+
+// value converts v to the generic types of a decoded configuration file:
+// maps keyed by strings, slices and scalars.
+func value(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Struct && v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		structFields(v, m)
+		return m
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			m[key] = settingValue(key, iter.Value())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = value(v.Index(i))
+		}
+		return s
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	}
+	return v.Interface()
+}
+
+// structFields adds to m the settings of the fields of the struct v, named
+// after their mapstructure tags.
+func structFields(v reflect.Value, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		tag := field.Tag.Get("mapstructure")
+		if tag == "-" {
+			continue
+		}
+		name := field.Name
+		if tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if hasOption(parts[1:], "squash") {
+				fv := v.Field(i)
+				for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+					fv = fv.Elem()
+				}
+				if fv.Kind() == reflect.Struct {
+					structFields(fv, m)
+				}
+				continue
+			}
+		}
+		m[name] = settingValue(name, v.Field(i))
+	}
+}
+
+// settingValue returns the value of the setting with the given key, redacted
+// if the key identifies a secret or the value was resolved from secrets.
+func settingValue(key string, v reflect.Value) interface{} {
+	val := value(v)
+	if s, ok := val.(string); ok && s != "" && (IsSensitive(key) || configsecret.IsSecret(s)) {
+		return RedactedValue
+	}
+	return val
+}
+
+// IsSensitive reports if the key of a setting identifies a secret.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
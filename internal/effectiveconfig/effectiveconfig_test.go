@@ -0,0 +1,151 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effectiveconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/config/configsecret"
+)
+
+type testExporterConfig struct {
+	configmodels.ExporterSettings `mapstructure:",squash"`
+	URL                           string            `mapstructure:"url"`
+	AccessToken                   string            `mapstructure:"access-token"`
+	Headers                       map[string]string `mapstructure:"headers"`
+	Timeout                       time.Duration     `mapstructure:"timeout"`
+	Retry                         *testRetryConfig  `mapstructure:"retry"`
+	NoTag                         int
+	unexported                    int
+}
+
+type testRetryConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Statuses []string `mapstructure:"statuses"`
+}
+
+func TestMap(t *testing.T) {
+	cfg := &configmodels.Config{
+		Receivers: configmodels.Receivers{
+			"jaeger": &configmodels.ReceiverSettings{
+				TypeVal:  "jaeger",
+				NameVal:  "jaeger",
+				Endpoint: "localhost:14250",
+			},
+		},
+		Exporters: configmodels.Exporters{
+			"sapm": &testExporterConfig{
+				ExporterSettings: configmodels.ExporterSettings{TypeVal: "sapm", NameVal: "sapm"},
+				URL:              "http://localhost:7276",
+				AccessToken:      "abcd",
+				Headers:          map[string]string{"Authorization": "Bearer abcd", "X-Custom": "value"},
+				Timeout:          5 * time.Second,
+				Retry:            &testRetryConfig{Enabled: true, Statuses: []string{"503"}},
+				NoTag:            1,
+				unexported:       2,
+			},
+		},
+		Pipelines: configmodels.Pipelines{
+			"traces": &configmodels.Pipeline{
+				Name:      "traces",
+				InputType: configmodels.TracesDataType,
+				Receivers: []string{"jaeger"},
+				Exporters: []string{"sapm"},
+			},
+		},
+		Service: configmodels.Service{
+			Extensions:        []string{"health-check"},
+			ProcessorOrdering: configmodels.ProcessorOrderingAuto,
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"receivers": map[string]interface{}{
+			"jaeger": map[string]interface{}{
+				"disabled": false,
+				"endpoint": "localhost:14250",
+			},
+		},
+		"processors": map[string]interface{}{},
+		"exporters": map[string]interface{}{
+			"sapm": map[string]interface{}{
+				"disabled":     false,
+				"url":          "http://localhost:7276",
+				"access-token": RedactedValue,
+				"headers": map[string]interface{}{
+					"Authorization": RedactedValue,
+					"X-Custom":      "value",
+				},
+				"timeout": "5s",
+				"retry": map[string]interface{}{
+					"enabled":  true,
+					"statuses": []interface{}{"503"},
+				},
+				"NoTag": 1,
+			},
+		},
+		"extensions": map[string]interface{}{},
+		"connectors": map[string]interface{}{},
+		"pipelines": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"receivers":  []interface{}{"jaeger"},
+				"processors": nil,
+				"exporters":  []interface{}{"sapm"},
+			},
+		},
+		"service": map[string]interface{}{
+			"extensions":         []interface{}{"health-check"},
+			"processor-ordering": configmodels.ProcessorOrderingAuto,
+		},
+	}, Map(cfg))
+
+	out, err := YAML(cfg)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out, &decoded))
+	assert.Contains(t, decoded, "exporters")
+	assert.NotContains(t, string(out), "abcd")
+}
+
+func TestIsSensitive(t *testing.T) {
+	for _, key := range []string{"access-token", "password", "client-secret", "X-SF-Token", "Authorization", "api-key"} {
+		assert.True(t, IsSensitive(key), key)
+	}
+	for _, key := range []string{"endpoint", "url", "keys", "cert-pem-file", "headers"} {
+		assert.False(t, IsSensitive(key), key)
+	}
+}
+
+func TestMapRedactsResolvedSecrets(t *testing.T) {
+	require.NoError(t, configsecret.RegisterResolver("effectiveconfig-test", configsecret.ResolverFunc(
+		func(reference string) (string, error) { return "resolved-" + reference, nil })))
+	exp := &testExporterConfig{
+		ExporterSettings: configmodels.ExporterSettings{TypeVal: "sapm", NameVal: "sapm"},
+		URL:              "http://${effectiveconfig-test:host}:7276",
+		Headers:          map[string]string{"X-Custom": "${effectiveconfig-test:header}"},
+	}
+	cfg := &configmodels.Config{Exporters: configmodels.Exporters{"sapm": exp}}
+	require.NoError(t, configsecret.Resolve(cfg))
+
+	m := Map(cfg)["exporters"].(map[string]interface{})["sapm"].(map[string]interface{})
+	assert.Equal(t, RedactedValue, m["url"])
+	assert.Equal(t, map[string]interface{}{"X-Custom": RedactedValue}, m["headers"])
+}
@@ -0,0 +1,79 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package extensionfeed implements the processors feeding the spans passing
+// through them to an extension, e.g. the service graph or the trace buffer,
+// since extensions are not part of the data pipelines. The spans are passed
+// unchanged to the next consumer.
+package extensionfeed
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// RecordFunc records the spans in the extension named extension, it returns
+// false if the extension is not running.
+type RecordFunc func(extension string, td consumerdata.TraceData) bool
+
+type traceProcessor struct {
+	logger       *zap.Logger
+	nextConsumer consumer.TraceConsumer
+	extension    string
+	record       RecordFunc
+	notRunning   string
+	warnOnce     sync.Once
+}
+
+var _ processor.TraceProcessor = (*traceProcessor)(nil)
+
+// NewTraceProcessor creates a processor recording the spans in the extension
+// named extension with record. The notRunning message is logged the first
+// time spans pass through while the extension is not running.
+func NewTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	extension string,
+	record RecordFunc,
+	notRunning string,
+) (processor.TraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &traceProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		extension:    extension,
+		record:       record,
+		notRunning:   notRunning,
+	}, nil
+}
+
+func (tp *traceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	// The extension is looked up on each call since it may be started after
+	// the pipelines are built.
+	if !tp.record(tp.extension, td) {
+		tp.warnOnce.Do(func() {
+			tp.logger.Warn(tp.notRunning, zap.String("extension", tp.extension))
+		})
+	}
+	return tp.nextConsumer.ConsumeTraceData(ctx, td)
+}
@@ -0,0 +1,89 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extensionfeed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+func TestNewTraceProcessor_NilNextConsumer(t *testing.T) {
+	tp, err := NewTraceProcessor(zap.NewNop(), nil, "test", nil, "not running")
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+	assert.Nil(t, tp)
+}
+
+func TestTraceProcessor(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	sink := &exportertest.SinkTraceExporter{}
+	running := false
+	var recorded []consumerdata.TraceData
+	record := func(extension string, td consumerdata.TraceData) bool {
+		assert.Equal(t, "test", extension)
+		if running {
+			recorded = append(recorded, td)
+		}
+		return running
+	}
+	tp, err := NewTraceProcessor(zap.New(core), sink, "test", record, "not running")
+	require.NoError(t, err)
+
+	td := consumerdata.TraceData{Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}}}
+
+	// Without the extension the spans are passed through, the warning is
+	// logged once.
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+	assert.Len(t, sink.AllTraces(), 2)
+	assert.Empty(t, recorded)
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "not running", entry.Message)
+	assert.Equal(t, "test", entry.ContextMap()["extension"])
+
+	running = true
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+	assert.Len(t, sink.AllTraces(), 3)
+	assert.Equal(t, []consumerdata.TraceData{td}, recorded)
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestTraceProcessor_NextConsumerError(t *testing.T) {
+	wantErr := errors.New("next consumer failed")
+	next := exportertest.NewNopTraceExporter(exportertest.WithReturnError(wantErr))
+	recorded := 0
+	record := func(string, consumerdata.TraceData) bool {
+		recorded++
+		return true
+	}
+	tp, err := NewTraceProcessor(zap.NewNop(), next, "test", record, "not running")
+	require.NoError(t, err)
+
+	// The spans are recorded even if the next consumer fails.
+	assert.Equal(t, wantErr, tp.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	assert.Equal(t, 1, recorded)
+}
@@ -0,0 +1,78 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jaegerrelay allows relaying the Jaeger gRPC PostSpans requests from
+// the Jaeger receiver to the Jaeger gRPC exporter without decoding them.
+package jaegerrelay
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// Format is the consumerdata.RawTraceData format of a serialized
+	// jaeger.api_v2.PostSpansRequest.
+	Format = "jaeger-proto-post-spans"
+
+	// ServiceName is the name of the Jaeger gRPC collector service.
+	ServiceName = "jaeger.api_v2.CollectorService"
+	// PostSpansMethod is the full name of the Jaeger gRPC PostSpans method.
+	PostSpansMethod = "/" + ServiceName + "/PostSpans"
+)
+
+// Message is a serialized protobuf message, it is sent and received as is
+// by the Codec.
+type Message []byte
+
+// Codec is a gRPC codec sending and receiving Message values as is, other
+// values are protobuf messages.
+type Codec struct{}
+
+// Marshal returns the wire format of v.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case Message:
+		return m, nil
+	case *Message:
+		return *m, nil
+	case proto.Message:
+		return proto.Marshal(m)
+	}
+	return nil, fmt.Errorf("failed to marshal, message is %T, want proto.Message", v)
+}
+
+// Unmarshal parses the wire format into v.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	switch m := v.(type) {
+	case *Message:
+		// The buffer is owned by gRPC, keep a copy.
+		*m = append((*m)[:0], data...)
+		return nil
+	case proto.Message:
+		return proto.Unmarshal(data, m)
+	}
+	return fmt.Errorf("failed to unmarshal, message is %T, want proto.Message", v)
+}
+
+// Name returns the name of the codec, it implements encoding.Codec.
+func (Codec) Name() string {
+	return "proto"
+}
+
+// String returns the name of the codec, it implements grpc.Codec.
+func (c Codec) String() string {
+	return c.Name()
+}
@@ -0,0 +1,58 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerrelay
+
+import (
+	"testing"
+
+	"github.com/jaegertracing/jaeger/model"
+	jaegerproto "github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	req := &jaegerproto.PostSpansRequest{
+		Batch: model.Batch{
+			Process: &model.Process{ServiceName: "frontend"},
+			Spans:   []*model.Span{{OperationName: "GET /"}},
+		},
+	}
+
+	codec := Codec{}
+	data, err := codec.Marshal(req)
+	require.NoError(t, err)
+
+	// A Message is received and sent as is.
+	var msg Message
+	require.NoError(t, codec.Unmarshal(data, &msg))
+	assert.Equal(t, Message(data), msg)
+	relayed, err := codec.Marshal(msg)
+	require.NoError(t, err)
+	assert.Equal(t, data, relayed)
+	relayed, err = codec.Marshal(&msg)
+	require.NoError(t, err)
+	assert.Equal(t, data, relayed)
+
+	got := &jaegerproto.PostSpansRequest{}
+	require.NoError(t, codec.Unmarshal(relayed, got))
+	assert.Equal(t, "frontend", got.Batch.Process.ServiceName)
+	assert.Equal(t, "GET /", got.Batch.Spans[0].OperationName)
+
+	_, err = codec.Marshal(1)
+	assert.Error(t, err)
+	assert.Error(t, codec.Unmarshal(data, new(int)))
+	assert.Equal(t, "proto", codec.String())
+}
@@ -0,0 +1,77 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sapi configures the access to the Kubernetes API server of the
+// cluster the service runs in, for the components calling a few endpoints of
+// the API directly.
+package k8sapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ServiceAccountDir is where Kubernetes mounts the credentials of the service
+// account in the pods.
+var ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// APIServer is how a component reaches the Kubernetes API server.
+type APIServer struct {
+	// URL of the API server.
+	URL    string
+	Client *http.Client
+	// Token returns the bearer token of the requests, it is called for every
+	// request since service account tokens are rotated. It may be nil.
+	Token func() (string, error)
+}
+
+// InCluster returns the configuration of the API server of the cluster the
+// pod runs in, authenticated with the service account of the pod.
+func InCluster() (*APIServer, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: " +
+			"KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined")
+	}
+
+	ca, err := ioutil.ReadFile(filepath.Join(ServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificate found in the service account CA")
+	}
+
+	tokenFile := filepath.Join(ServiceAccountDir, "token")
+	return &APIServer{
+		URL: "https://" + net.JoinHostPort(host, port),
+		Client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		Token: func() (string, error) {
+			b, err := ioutil.ReadFile(tokenFile)
+			return strings.TrimSpace(string(b)), err
+		},
+	}, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sapi
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInCluster(t *testing.T) {
+	for _, env := range []string{"KUBERNETES_SERVICE_HOST", "KUBERNETES_SERVICE_PORT"} {
+		if orig, ok := os.LookupEnv(env); ok {
+			defer os.Setenv(env, orig)
+		} else {
+			defer os.Unsetenv(env)
+		}
+	}
+
+	os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	_, err := InCluster()
+	assert.Error(t, err, "must fail outside of a cluster")
+
+	dir, err := ioutil.TempDir("", "serviceaccount")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(orig string) { ServiceAccountDir = orig }(ServiceAccountDir)
+	ServiceAccountDir = dir
+
+	os.Setenv("KUBERNETES_SERVICE_HOST", "fd00::1")
+	os.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	_, err = InCluster()
+	assert.Error(t, err, "must fail without the service account CA")
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ca.crt"), ca, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "token"), []byte("secret\n"), 0600))
+
+	apiServer, err := InCluster()
+	require.NoError(t, err)
+	assert.Equal(t, "https://[fd00::1]:443", apiServer.URL)
+	token, err := apiServer.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", token)
+
+	// The client trusts the service account CA.
+	resp, err := apiServer.Client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,298 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leaderelection elects a leader among the replicas of the service
+// with a Kubernetes Lease, so that singleton receivers run on a single replica.
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Elector tells whether the service is the leader among its replicas.
+type Elector interface {
+	// IsLeader returns true while the service holds the leadership.
+	IsLeader() bool
+}
+
+// LeaseSettings defines the lease used to elect the leader.
+type LeaseSettings struct {
+	// Namespace and Name of the lease, it is created if it doesn't exist.
+	Namespace string
+	Name      string
+
+	// Identity of the replica, it must be unique among the replicas.
+	Identity string
+
+	// LeaseDuration is how long the followers wait, since the last renewal
+	// they observed, before taking over the leadership.
+	LeaseDuration time.Duration
+
+	// RenewDeadline is how long the leader tries to renew the lease before
+	// giving up the leadership. It must be shorter than LeaseDuration.
+	RenewDeadline time.Duration
+
+	// RetryPeriod is the interval between the attempts to acquire or renew
+	// the lease.
+	RetryPeriod time.Duration
+}
+
+// Validate checks that the settings are consistent.
+func (s LeaseSettings) Validate() error {
+	switch {
+	case s.Namespace == "" || s.Name == "":
+		return errors.New("the lease namespace and name must not be empty")
+	case s.Identity == "":
+		return errors.New("the identity must not be empty")
+	case s.RetryPeriod <= 0:
+		return errors.New("the retry period must be positive")
+	case s.RenewDeadline <= s.RetryPeriod:
+		return errors.New("the renew deadline must be longer than the retry period")
+	case s.LeaseDuration <= s.RenewDeadline:
+		return errors.New("the lease duration must be longer than the renew deadline")
+	}
+	return nil
+}
+
+// LeaseElector is an Elector holding the leadership with a Kubernetes Lease.
+// The algorithm is the one of the Kubernetes client: the leader renews the
+// lease every retry period, the followers take it over once they didn't see
+// it renewed for the lease duration, measured on their own clock.
+type LeaseElector struct {
+	logger   *zap.Logger
+	client   *leaseClient
+	settings LeaseSettings
+	now      func() time.Time
+
+	leader int32
+
+	// observed is the last lease read or written and observedTime the local
+	// time it was first seen with its resource version.
+	observed     *lease
+	observedTime time.Time
+	lastRenew    time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+var _ Elector = (*LeaseElector)(nil)
+
+// NewLeaseElector creates an elector for the lease defined by the settings,
+// accessed through the Kubernetes API server at the given URL. The token
+// function returns the bearer token of the requests, it may be nil.
+func NewLeaseElector(
+	logger *zap.Logger,
+	apiServer string,
+	client *http.Client,
+	token func() (string, error),
+	settings LeaseSettings,
+) (*LeaseElector, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return &LeaseElector{
+		logger: logger,
+		client: &leaseClient{
+			apiServer: apiServer,
+			namespace: settings.Namespace,
+			name:      settings.Name,
+			client:    client,
+			token:     token,
+		},
+		settings: settings,
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}, nil
+}
+
+// IsLeader returns true while the elector holds the lease.
+func (le *LeaseElector) IsLeader() bool {
+	return atomic.LoadInt32(&le.leader) == 1
+}
+
+// Start runs the election in the background until Stop is called.
+func (le *LeaseElector) Start() {
+	go le.run()
+}
+
+// Stop ends the election and releases the lease if it is held, so another
+// replica can take over without waiting for the lease to expire.
+func (le *LeaseElector) Stop() {
+	le.stopOnce.Do(func() {
+		close(le.stopCh)
+		<-le.doneCh
+	})
+}
+
+func (le *LeaseElector) run() {
+	defer close(le.doneCh)
+
+	ticker := time.NewTicker(le.settings.RetryPeriod)
+	defer ticker.Stop()
+	for {
+		le.tick()
+		select {
+		case <-ticker.C:
+		case <-le.stopCh:
+			le.release()
+			return
+		}
+	}
+}
+
+// tick makes one attempt to acquire or renew the lease. A leader failing to
+// renew the lease keeps the leadership until the renew deadline.
+func (le *LeaseElector) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), le.settings.RetryPeriod)
+	defer cancel()
+
+	acquired, err := le.tryAcquireOrRenew(ctx)
+	switch {
+	case acquired:
+		le.lastRenew = le.now()
+		le.setLeader(true)
+	case err == nil:
+		// The lease is held by another replica.
+		le.setLeader(false)
+	default:
+		le.logger.Debug("Failed to acquire or renew the lease",
+			zap.String("lease", le.settings.Name), zap.Error(err))
+		if le.IsLeader() && le.now().Sub(le.lastRenew) >= le.settings.RenewDeadline {
+			le.setLeader(false)
+		}
+	}
+}
+
+func (le *LeaseElector) setLeader(leader bool) {
+	var v int32
+	if leader {
+		v = 1
+	}
+	if atomic.SwapInt32(&le.leader, v) == v {
+		return
+	}
+	if leader {
+		le.logger.Info("Acquired the leadership",
+			zap.String("lease", le.settings.Name), zap.String("identity", le.settings.Identity))
+	} else {
+		le.logger.Info("Lost the leadership",
+			zap.String("lease", le.settings.Name), zap.String("identity", le.settings.Identity))
+	}
+}
+
+// tryAcquireOrRenew returns true if the elector holds the lease after the
+// call, and an error if the lease couldn't be read or written.
+func (le *LeaseElector) tryAcquireOrRenew(ctx context.Context) (bool, error) {
+	now := le.now()
+	current, err := le.client.get(ctx)
+	if err == errLeaseNotFound {
+		l := &lease{Spec: le.holderSpec(now, now, 0)}
+		created, err := le.client.create(ctx, l)
+		if err == errLeaseConflict {
+			// Another replica created the lease first.
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		le.observe(created, now)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if le.observed == nil || le.observed.Metadata.ResourceVersion != current.Metadata.ResourceVersion {
+		le.observe(current, now)
+	}
+
+	holder := current.Spec.HolderIdentity
+	if holder != "" && holder != le.settings.Identity {
+		duration := time.Duration(current.Spec.LeaseDurationSeconds) * time.Second
+		if duration <= 0 {
+			duration = le.settings.LeaseDuration
+		}
+		if le.observedTime.Add(duration).After(now) {
+			return false, nil
+		}
+	}
+
+	update := *current
+	if holder == le.settings.Identity && current.Spec.AcquireTime != nil {
+		update.Spec = le.holderSpec(current.Spec.AcquireTime.Time, now, current.Spec.LeaseTransitions)
+	} else {
+		update.Spec = le.holderSpec(now, now, current.Spec.LeaseTransitions+1)
+	}
+	updated, err := le.client.update(ctx, &update)
+	if err == errLeaseConflict {
+		// Another replica updated the lease first.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	le.observe(updated, now)
+	return true, nil
+}
+
+func (le *LeaseElector) holderSpec(acquired, renewed time.Time, transitions int32) leaseSpec {
+	return leaseSpec{
+		HolderIdentity:       le.settings.Identity,
+		LeaseDurationSeconds: int32(le.settings.LeaseDuration / time.Second),
+		AcquireTime:          &microTime{acquired},
+		RenewTime:            &microTime{renewed},
+		LeaseTransitions:     transitions,
+	}
+}
+
+func (le *LeaseElector) observe(l *lease, now time.Time) {
+	le.observed = l
+	le.observedTime = now
+}
+
+// release gives up the lease if it is held, by making it expire right away.
+func (le *LeaseElector) release() {
+	if !le.IsLeader() {
+		return
+	}
+	le.setLeader(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), le.settings.RetryPeriod)
+	defer cancel()
+	current, err := le.client.get(ctx)
+	if err == nil && current.Spec.HolderIdentity == le.settings.Identity {
+		update := *current
+		now := le.now()
+		update.Spec = leaseSpec{
+			LeaseDurationSeconds: 1,
+			RenewTime:            &microTime{now},
+			AcquireTime:          &microTime{now},
+			LeaseTransitions:     current.Spec.LeaseTransitions,
+		}
+		_, err = le.client.update(ctx, &update)
+	}
+	if err != nil {
+		le.logger.Warn("Failed to release the lease", zap.String("lease", le.settings.Name), zap.Error(err))
+	}
+}
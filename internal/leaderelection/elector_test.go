@@ -0,0 +1,276 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeAPIServer stores a single lease with optimistic concurrency, as the
+// Kubernetes API server does.
+type fakeAPIServer struct {
+	mu      sync.Mutex
+	lease   *lease
+	version int
+	fail    bool
+	tokens  []string
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+	if f.fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/apis/coordination.k8s.io/v1/namespaces/otel/leases") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if f.lease == nil {
+			http.NotFound(w, r)
+			return
+		}
+	case http.MethodPost, http.MethodPut:
+		l := &lease{}
+		if err := json.NewDecoder(r.Body).Decode(l); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if (r.Method == http.MethodPost && f.lease != nil) ||
+			(r.Method == http.MethodPut && (f.lease == nil || l.Metadata.ResourceVersion != f.lease.Metadata.ResourceVersion)) {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		f.version++
+		l.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.lease = l
+	}
+	json.NewEncoder(w).Encode(f.lease)
+}
+
+func (f *fakeAPIServer) holder() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lease == nil {
+		return ""
+	}
+	return f.lease.Spec.HolderIdentity
+}
+
+func (f *fakeAPIServer) setFail(fail bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = fail
+}
+
+func testSettings(identity string) LeaseSettings {
+	return LeaseSettings{
+		Namespace:     "otel",
+		Name:          "otelsvc",
+		Identity:      identity,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}
+}
+
+// fakeClock is shared by the electors of a test.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestElector(t *testing.T, url, identity string, clock *fakeClock) *LeaseElector {
+	le, err := NewLeaseElector(zap.NewNop(), url, http.DefaultClient, func() (string, error) {
+		return "token-" + identity, nil
+	}, testSettings(identity))
+	require.NoError(t, err)
+	le.now = clock.now
+	return le
+}
+
+func TestLeaseElector_SingleLeader(t *testing.T) {
+	api := &fakeAPIServer{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	clock := &fakeClock{t: time.Unix(1571000000, 0)}
+
+	a := newTestElector(t, srv.URL, "a", clock)
+	b := newTestElector(t, srv.URL, "b", clock)
+
+	a.tick()
+	b.tick()
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	assert.Equal(t, "a", api.holder())
+	assert.Equal(t, "Bearer token-a", api.tokens[0])
+
+	// The leader keeps renewing, the follower never takes over.
+	for i := 0; i < 20; i++ {
+		clock.advance(2 * time.Second)
+		a.tick()
+		b.tick()
+		require.True(t, a.IsLeader())
+		require.False(t, b.IsLeader())
+	}
+}
+
+func TestLeaseElector_TakeOverExpiredLease(t *testing.T) {
+	api := &fakeAPIServer{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	clock := &fakeClock{t: time.Unix(1571000000, 0)}
+
+	a := newTestElector(t, srv.URL, "a", clock)
+	b := newTestElector(t, srv.URL, "b", clock)
+	a.tick()
+	b.tick()
+	require.True(t, a.IsLeader())
+
+	// The leader stops renewing the lease, e.g. because it is partitioned.
+	clock.advance(14 * time.Second)
+	b.tick()
+	assert.False(t, b.IsLeader(), "the lease is not expired yet")
+	clock.advance(2 * time.Second)
+	b.tick()
+	assert.True(t, b.IsLeader())
+	assert.Equal(t, "b", api.holder())
+	assert.Equal(t, int32(1), api.lease.Spec.LeaseTransitions)
+
+	// The previous leader sees the new holder.
+	a.tick()
+	assert.False(t, a.IsLeader())
+}
+
+func TestLeaseElector_RenewDeadline(t *testing.T) {
+	api := &fakeAPIServer{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	clock := &fakeClock{t: time.Unix(1571000000, 0)}
+
+	a := newTestElector(t, srv.URL, "a", clock)
+	a.tick()
+	require.True(t, a.IsLeader())
+
+	api.setFail(true)
+	clock.advance(8 * time.Second)
+	a.tick()
+	assert.True(t, a.IsLeader(), "the leadership is kept until the renew deadline")
+	clock.advance(2 * time.Second)
+	a.tick()
+	assert.False(t, a.IsLeader())
+
+	api.setFail(false)
+	clock.advance(2 * time.Second)
+	a.tick()
+	assert.True(t, a.IsLeader())
+}
+
+func TestLeaseElector_StartStop(t *testing.T) {
+	api := &fakeAPIServer{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	settings := testSettings("a")
+	settings.RetryPeriod = 10 * time.Millisecond
+	settings.RenewDeadline = 50 * time.Millisecond
+	settings.LeaseDuration = time.Second
+	le, err := NewLeaseElector(zap.NewNop(), srv.URL, http.DefaultClient, nil, settings)
+	require.NoError(t, err)
+
+	le.Start()
+	for i := 0; i < 100 && !le.IsLeader(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, le.IsLeader())
+	le.Stop()
+	le.Stop()
+
+	// The lease is released on stop.
+	assert.False(t, le.IsLeader())
+	assert.Equal(t, "", api.holder())
+	b := newTestElector(t, srv.URL, "b", &fakeClock{t: time.Now()})
+	b.tick()
+	assert.True(t, b.IsLeader())
+}
+
+func TestLeaseSettings_Validate(t *testing.T) {
+	assert.NoError(t, testSettings("a").Validate())
+
+	tests := []struct {
+		name   string
+		modify func(s *LeaseSettings)
+	}{
+		{"no_name", func(s *LeaseSettings) { s.Name = "" }},
+		{"no_namespace", func(s *LeaseSettings) { s.Namespace = "" }},
+		{"no_identity", func(s *LeaseSettings) { s.Identity = "" }},
+		{"no_retry_period", func(s *LeaseSettings) { s.RetryPeriod = 0 }},
+		{"short_renew_deadline", func(s *LeaseSettings) { s.RenewDeadline = s.RetryPeriod }},
+		{"short_lease_duration", func(s *LeaseSettings) { s.LeaseDuration = s.RenewDeadline }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSettings("a")
+			tt.modify(&s)
+			assert.Error(t, s.Validate())
+			_, err := NewLeaseElector(zap.NewNop(), "", http.DefaultClient, nil, s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMicroTime(t *testing.T) {
+	mt := microTime{time.Date(2019, 10, 14, 8, 30, 15, 123456789, time.UTC)}
+	b, err := json.Marshal(mt)
+	require.NoError(t, err)
+	assert.Equal(t, `"2019-10-14T08:30:15.123456Z"`, string(b))
+
+	var parsed microTime
+	require.NoError(t, json.Unmarshal(b, &parsed))
+	assert.True(t, parsed.Equal(mt.Truncate(time.Microsecond)))
+}
+
+type fakeElector bool
+
+func (fe fakeElector) IsLeader() bool { return bool(fe) }
+
+func TestRegistry(t *testing.T) {
+	assert.Nil(t, Lookup("leader-election"))
+	Register("leader-election", fakeElector(true))
+	require.NotNil(t, Lookup("leader-election"))
+	assert.True(t, Lookup("leader-election").IsLeader())
+	Unregister("leader-election")
+	assert.Nil(t, Lookup("leader-election"))
+}
@@ -0,0 +1,164 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+var (
+	errLeaseNotFound = errors.New("lease not found")
+	errLeaseConflict = errors.New("lease was modified concurrently")
+)
+
+// microTimeFormat is the format of the Kubernetes MicroTime fields.
+const microTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// microTime is a time serialized as a Kubernetes MicroTime.
+type microTime struct {
+	time.Time
+}
+
+func (t microTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(microTimeFormat))
+}
+
+func (t *microTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// lease is the subset of the coordination.k8s.io/v1 Lease object used for
+// leader election.
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string     `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int32      `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *microTime `json:"acquireTime,omitempty"`
+	RenewTime            *microTime `json:"renewTime,omitempty"`
+	LeaseTransitions     int32      `json:"leaseTransitions,omitempty"`
+}
+
+// leaseClient reads and writes a single lease through the Kubernetes API.
+type leaseClient struct {
+	apiServer string
+	namespace string
+	name      string
+	client    *http.Client
+	// token returns the bearer token of the requests, it is called for every
+	// request since service account tokens are rotated.
+	token func() (string, error)
+}
+
+func (lc *leaseClient) leasesURL() string {
+	return fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", lc.apiServer, lc.namespace)
+}
+
+func (lc *leaseClient) get(ctx context.Context) (*lease, error) {
+	return lc.do(ctx, http.MethodGet, lc.leasesURL()+"/"+lc.name, nil)
+}
+
+func (lc *leaseClient) create(ctx context.Context, l *lease) (*lease, error) {
+	return lc.do(ctx, http.MethodPost, lc.leasesURL(), l)
+}
+
+// update replaces the lease, it fails with errLeaseConflict if the lease was
+// modified since it was read.
+func (lc *leaseClient) update(ctx context.Context, l *lease) (*lease, error) {
+	return lc.do(ctx, http.MethodPut, lc.leasesURL()+"/"+lc.name, l)
+}
+
+func (lc *leaseClient) do(ctx context.Context, method, url string, body *lease) (*lease, error) {
+	var reqBody []byte
+	if body != nil {
+		body.APIVersion = "coordination.k8s.io/v1"
+		body.Kind = "Lease"
+		body.Metadata.Name = lc.name
+		body.Metadata.Namespace = lc.namespace
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if lc.token != nil {
+		token, err := lc.token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := lc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errLeaseNotFound
+	case resp.StatusCode == http.StatusConflict:
+		return nil, errLeaseConflict
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("%s %s failed with status %d: %s", method, url, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	l := &lease{}
+	if err := json.Unmarshal(respBody, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import "github.com/open-telemetry/opentelemetry-service/internal/registry"
+
+// electors links the electors run by the leader-election extensions with the
+// receivers consulting them, since extensions are not part of the data
+// pipelines.
+var electors = registry.New()
+
+// Register makes e the elector run by the leader-election extension named
+// name.
+func Register(name string, e Elector) {
+	electors.Register(name, e)
+}
+
+// Unregister removes the elector of the leader-election extension named name.
+func Unregister(name string) {
+	electors.Unregister(name)
+}
+
+// Lookup returns the elector of the leader-election extension named name or
+// nil if the extension is not started.
+func Lookup(name string) Elector {
+	e, _ := electors.Lookup(name).(Elector)
+	return e
+}
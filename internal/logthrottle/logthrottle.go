@@ -0,0 +1,84 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logthrottle limits the logs of errors repeated while a failure lasts,
+// e.g. while a back-end is unreachable: the first occurrence is logged, then
+// every Nth one with the number of occurrences so far.
+package logthrottle
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OccurrencesKey is the key of the field holding the number of occurrences of
+// a throttled log entry since the failure started.
+const OccurrencesKey = "occurrences"
+
+// Throttler counts the occurrences of the log entries identified by a key.
+type Throttler struct {
+	every int64
+
+	mu          sync.Mutex
+	occurrences map[string]int64
+}
+
+// New creates a Throttler logging the first occurrence of an entry, then one
+// occurrence out of every. Values lower than 2 log all the occurrences.
+func New(every int) *Throttler {
+	if every < 1 {
+		every = 1
+	}
+	return &Throttler{
+		every:       int64(every),
+		occurrences: make(map[string]int64),
+	}
+}
+
+// Occur records an occurrence of the entry identified by key. It returns
+// whether the occurrence must be logged and the number of occurrences since
+// the last reset.
+func (t *Throttler) Occur(key string) (bool, int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.occurrences[key]++
+	n := t.occurrences[key]
+	return (n-1)%t.every == 0, n
+}
+
+// Reset forgets the occurrences of the entry identified by key, e.g. once the
+// failure is over, so that the next occurrence is logged. It returns the
+// number of occurrences since the previous reset.
+func (t *Throttler) Reset(key string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.occurrences[key]
+	delete(t.occurrences, key)
+	return n
+}
+
+// Log records an occurrence of the entry identified by key and writes it to
+// logger if it must be logged, with the number of occurrences added to the
+// fields.
+func (t *Throttler) Log(logger *zap.Logger, level zapcore.Level, key, msg string, fields ...zap.Field) {
+	ok, n := t.Occur(key)
+	if !ok {
+		return
+	}
+	if ce := logger.Check(level, msg); ce != nil {
+		ce.Write(append(fields, zap.Int64(OccurrencesKey, n))...)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logthrottle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestThrottler_Occur(t *testing.T) {
+	th := New(3)
+	var logged []int64
+	for i := 0; i < 7; i++ {
+		if ok, n := th.Occur("send"); ok {
+			logged = append(logged, n)
+		}
+	}
+	assert.Equal(t, []int64{1, 4, 7}, logged)
+
+	// The keys are counted separately.
+	ok, n := th.Occur("drop")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), n)
+
+	assert.Equal(t, int64(7), th.Reset("send"))
+	ok, n = th.Occur("send")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, int64(0), th.Reset("unknown"))
+}
+
+func TestThrottler_LogAll(t *testing.T) {
+	for _, every := range []int{-1, 0, 1} {
+		th := New(every)
+		for i := 0; i < 3; i++ {
+			ok, _ := th.Occur("send")
+			assert.True(t, ok)
+		}
+	}
+}
+
+func TestThrottler_Log(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+	th := New(2)
+
+	for i := 0; i < 3; i++ {
+		th.Log(logger, zapcore.WarnLevel, "send", "Sender failed", zap.String("processor", "queued-retry"))
+	}
+	// Disabled levels are only counted.
+	th.Log(logger, zapcore.DebugLevel, "debug", "Debug entry")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	for i, n := range []int64{1, 3} {
+		assert.Equal(t, "Sender failed", entries[i].Message)
+		assert.Equal(t, zapcore.WarnLevel, entries[i].Level)
+		assert.Equal(t, map[string]interface{}{"processor": "queued-retry", OccurrencesKey: n}, entries[i].ContextMap())
+	}
+	assert.Equal(t, int64(1), th.Reset("debug"))
+}
@@ -0,0 +1,154 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package netacl restricts the clients of the receivers to allowed networks,
+// the connections from other networks are closed as soon as they are accepted,
+// before any request is read, or their HTTP requests are rejected where the
+// listener can't be wrapped.
+package netacl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/open-telemetry/opentelemetry-service/observability"
+)
+
+// ACL is a list of allowed networks. A nil ACL, returned when no network is
+// listed, allows all clients.
+type ACL struct {
+	nets []*net.IPNet
+	// ctx holds the name of the receiver the denied connections are recorded
+	// for.
+	ctx context.Context
+}
+
+// New creates the ACL of the receiver allowing the given networks, in CIDR
+// notation, e.g. "10.0.0.0/8". A single IP address allows only that address.
+func New(cidrs []string, receiverName string) (*ACL, error) {
+	if len(cidrs) == 0 {
+		return nil, nil
+	}
+	acl := &ACL{
+		nets: make([]*net.IPNet, 0, len(cidrs)),
+		ctx:  observability.ContextWithReceiverName(context.Background(), receiverName),
+	}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid CIDR %q", cidr)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		}
+		acl.nets = append(acl.nets, ipNet)
+	}
+	return acl, nil
+}
+
+// Allows returns whether the IP address is in one of the allowed networks.
+func (acl *ACL) Allows(ip net.IP) bool {
+	if acl == nil {
+		return true
+	}
+	for _, ipNet := range acl.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Listener wraps the listener to close the connections of the clients that
+// are not allowed, they are recorded as denied for the receiver. The
+// connections from the host itself are always allowed, e.g. the OpenCensus
+// receiver forwards its HTTP/JSON requests to its own gRPC server. The
+// listener is returned unchanged if the ACL is nil.
+func (acl *ACL) Listener(ln net.Listener) net.Listener {
+	if acl == nil {
+		return ln
+	}
+	return &listener{Listener: ln, acl: acl}
+}
+
+type listener struct {
+	net.Listener
+	acl *ACL
+}
+
+// Accept waits for the next allowed connection.
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.allows(conn) {
+			return conn, nil
+		}
+		observability.RecordDeniedConnection(l.acl.ctx)
+		_ = conn.Close()
+	}
+}
+
+func (l *listener) allows(conn net.Conn) bool {
+	return l.acl.allowsAddr(conn.RemoteAddr(), conn.LocalAddr())
+}
+
+// HTTPHandler wraps the handler to answer the requests of the clients that are
+// not allowed with the 403 status, they are recorded as denied for the
+// receiver. It is used where the listener is not created by the receiver and
+// cannot be wrapped. The handler is returned unchanged if the ACL is nil.
+func (acl *ACL) HTTPHandler(next http.Handler) http.Handler {
+	if acl == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+		if err != nil {
+			// Only the TCP clients are filtered.
+			next.ServeHTTP(w, r)
+			return
+		}
+		local, _ := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+		if !acl.allowsAddr(remote, local) {
+			observability.RecordDeniedConnection(acl.ctx)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (acl *ACL) allowsAddr(remoteAddr, localAddr net.Addr) bool {
+	remote, ok := remoteAddr.(*net.TCPAddr)
+	if !ok {
+		// Only the TCP clients are filtered.
+		return true
+	}
+	if remote.IP.IsLoopback() {
+		return true
+	}
+	if local, ok := localAddr.(*net.TCPAddr); ok && local.IP.Equal(remote.IP) {
+		return true
+	}
+	return acl.Allows(remote.IP)
+}
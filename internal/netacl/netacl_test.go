@@ -0,0 +1,152 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netacl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+)
+
+func TestNew(t *testing.T) {
+	acl, err := New(nil, "test")
+	require.NoError(t, err)
+	assert.Nil(t, acl)
+	assert.True(t, acl.Allows(net.ParseIP("1.2.3.4")))
+
+	acl, err = New([]string{"10.0.0.0/8", "192.168.1.7", "fd00::/8"}, "test")
+	require.NoError(t, err)
+	assert.True(t, acl.Allows(net.ParseIP("10.1.2.3")))
+	assert.True(t, acl.Allows(net.ParseIP("192.168.1.7")))
+	assert.True(t, acl.Allows(net.ParseIP("fd00::1")))
+	assert.False(t, acl.Allows(net.ParseIP("192.168.1.8")))
+	assert.False(t, acl.Allows(net.ParseIP("11.0.0.1")))
+	assert.False(t, acl.Allows(net.ParseIP("fe80::1")))
+
+	_, err = New([]string{"10.0.0.0/33"}, "test")
+	assert.EqualError(t, err, `invalid CIDR "10.0.0.0/33"`)
+	_, err = New([]string{"localhost"}, "test")
+	assert.EqualError(t, err, `invalid CIDR "localhost"`)
+}
+
+func TestListener(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	local := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 55678}
+	denied := &fakeConn{local: local, remote: &net.TCPAddr{IP: net.ParseIP("172.16.0.1"), Port: 1234}}
+	allowed := &fakeConn{local: local, remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 1234}}
+	loopback := &fakeConn{local: local, remote: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}}
+	self := &fakeConn{local: local, remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}}
+	fake := &fakeListener{conns: []net.Conn{denied, allowed, denied, loopback, self}}
+
+	acl, err := New([]string{"10.0.0.2/32"}, "test")
+	require.NoError(t, err)
+	ln := acl.Listener(fake)
+
+	for _, want := range []net.Conn{allowed, loopback, self} {
+		conn, err := ln.Accept()
+		require.NoError(t, err)
+		assert.Equal(t, want, conn)
+	}
+	_, err = ln.Accept()
+	assert.Equal(t, errDone, err)
+
+	assert.True(t, denied.closed)
+	assert.False(t, allowed.closed)
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDeniedConnections("test", 2))
+}
+
+func TestListener_NilACL(t *testing.T) {
+	fake := &fakeListener{}
+	var acl *ACL
+	assert.Equal(t, net.Listener(fake), acl.Listener(fake))
+}
+
+func TestHTTPHandler(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	acl, err := New([]string{"10.0.0.2/32"}, "test")
+	require.NoError(t, err)
+	handler := acl.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	local := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 14268}
+	tests := []struct {
+		remoteAddr string
+		want       int
+	}{
+		{remoteAddr: "172.16.0.1:1234", want: http.StatusForbidden},
+		{remoteAddr: "10.0.0.2:1234", want: http.StatusAccepted},
+		{remoteAddr: "127.0.0.1:1234", want: http.StatusAccepted},
+		{remoteAddr: "10.0.0.1:1234", want: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/traces", nil)
+			req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, local))
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDeniedConnections("test", 1))
+}
+
+func TestHTTPHandler_NilACL(t *testing.T) {
+	var acl *ACL
+	handler := http.RedirectHandler("/", http.StatusFound)
+	assert.Equal(t, handler, acl.HTTPHandler(handler))
+}
+
+var errDone = errors.New("no more connections")
+
+type fakeListener struct {
+	net.Listener
+	conns []net.Conn
+}
+
+func (fl *fakeListener) Accept() (net.Conn, error) {
+	if len(fl.conns) == 0 {
+		return nil, errDone
+	}
+	conn := fl.conns[0]
+	fl.conns = fl.conns[1:]
+	return conn, nil
+}
+
+type fakeConn struct {
+	net.Conn
+	local, remote net.Addr
+	closed        bool
+}
+
+func (fc *fakeConn) LocalAddr() net.Addr  { return fc.local }
+func (fc *fakeConn) RemoteAddr() net.Addr { return fc.remote }
+func (fc *fakeConn) Close() error {
+	fc.closed = true
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package panicrecovery isolates the panics of the processors and the
+// exporters of the pipelines: a panic while a component consumes a batch is
+// logged with its stack and counted, and the batch is dropped, so that a
+// single malformed batch doesn't crash the whole service.
+package panicrecovery
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/internal/componentusage"
+)
+
+const recoverPanicsCfg = "recover-panics"
+
+var mComponentPanics = stats.Int64("otelsvc/component/panics", "Number of panics recovered while the component consumed a batch", stats.UnitDimensionless)
+
+// ViewComponentPanics defines the view for the component panics metric.
+var ViewComponentPanics = &view.View{
+	Name:        mComponentPanics.Name(),
+	Description: mComponentPanics.Description(),
+	Measure:     mComponentPanics,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{componentusage.TagKeyComponentKind, componentusage.TagKeyComponent},
+}
+
+// ErrPanicked is the error returned instead of the panic of a component. It is
+// permanent, the batch must not be retried.
+var ErrPanicked = errors.New("component panicked")
+
+// enabled is 1 if the panics of the components wrapped afterwards are
+// recovered.
+var enabled int32 = 1
+
+// AddFlags adds the command-line flag enabling the recovery to the given flag
+// set.
+func AddFlags(flags *flag.FlagSet) {
+	flags.Bool(
+		recoverPanicsCfg,
+		true,
+		"Recover the panics of the processors and exporters, dropping the batch, instead of crashing the service.")
+}
+
+// SetupFromViper enables the recovery according to the configuration in the
+// given viper.
+func SetupFromViper(v *viper.Viper) {
+	SetEnabled(v.GetBool(recoverPanicsCfg))
+}
+
+// SetEnabled enables or disables the recovery of the panics of the components
+// wrapped afterwards.
+func SetEnabled(enable bool) {
+	var value int32
+	if enable {
+		value = 1
+	}
+	atomic.StoreInt32(&enabled, value)
+}
+
+// Enabled returns true if the panics of the components wrapped now are
+// recovered.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// MetricViews returns the views of the recovery metrics according to the given
+// telemetry level, none if the recovery is disabled.
+func MetricViews(level telemetry.Level) []*view.View {
+	if level == telemetry.None || !Enabled() {
+		return nil
+	}
+	return []*view.View{ViewComponentPanics}
+}
+
+// WrapTraceConsumer returns a consumer recovering the panics of tc, reported
+// under the given kind and name, or tc itself if the recovery is disabled.
+func WrapTraceConsumer(logger *zap.Logger, kind, name string, tc consumer.TraceConsumer) consumer.TraceConsumer {
+	if !Enabled() || tc == nil {
+		return tc
+	}
+	c := &traceConsumer{recoverer: newRecoverer(logger, kind, name), next: tc}
+	if rtc, ok := tc.(consumer.RawTraceConsumer); ok {
+		return &rawTraceConsumer{traceConsumer: c, next: rtc}
+	}
+	return c
+}
+
+// WrapMetricsConsumer returns a consumer recovering the panics of mc, reported
+// under the given kind and name, or mc itself if the recovery is disabled.
+func WrapMetricsConsumer(logger *zap.Logger, kind, name string, mc consumer.MetricsConsumer) consumer.MetricsConsumer {
+	if !Enabled() || mc == nil {
+		return mc
+	}
+	return &metricsConsumer{recoverer: newRecoverer(logger, kind, name), next: mc}
+}
+
+type traceConsumer struct {
+	*recoverer
+	next consumer.TraceConsumer
+}
+
+func (c *traceConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) (err error) {
+	defer c.recover(&err, zap.Int("spans", len(td.Spans)))
+	return c.next.ConsumeTraceData(ctx, td)
+}
+
+// rawTraceConsumer wraps the consumers accepting raw trace data, so that the
+// raw data is still relayed to them.
+type rawTraceConsumer struct {
+	*traceConsumer
+	next consumer.RawTraceConsumer
+}
+
+var _ consumer.RawTraceConsumer = (*rawTraceConsumer)(nil)
+
+func (c *rawTraceConsumer) AcceptsRawTraceFormat(format string) bool {
+	return c.next.AcceptsRawTraceFormat(format)
+}
+
+func (c *rawTraceConsumer) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) (err error) {
+	defer c.recover(&err, zap.String("format", rtd.Format))
+	return c.next.ConsumeRawTraceData(ctx, rtd)
+}
+
+type metricsConsumer struct {
+	*recoverer
+	next consumer.MetricsConsumer
+}
+
+func (c *metricsConsumer) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) (err error) {
+	defer c.recover(&err, zap.Int("metrics", len(md.Metrics)))
+	return c.next.ConsumeMetricsData(ctx, md)
+}
+
+type recoverer struct {
+	logger *zap.Logger
+	ctx    context.Context
+}
+
+func newRecoverer(logger *zap.Logger, kind, name string) *recoverer {
+	ctx, _ := tag.New(context.Background(),
+		tag.Upsert(componentusage.TagKeyComponentKind, kind, tag.WithTTL(tag.TTLNoPropagation)),
+		tag.Upsert(componentusage.TagKeyComponent, name, tag.WithTTL(tag.TTLNoPropagation)))
+	return &recoverer{
+		logger: logger.With(zap.String("component_kind", kind), zap.String("component", name)),
+		ctx:    ctx,
+	}
+}
+
+// recover must be deferred by the consume methods: it recovers their panic,
+// if any, and replaces their error by a permanent ErrPanicked.
+func (r *recoverer) recover(err *error, batch zap.Field) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	r.logger.Error("Component panicked, dropping the batch",
+		zap.String("panic", fmt.Sprint(p)),
+		batch,
+		zap.Stack("stack"))
+	stats.Record(r.ctx, mComponentPanics.M(1))
+	*err = consumererror.Permanent(ErrPanicked)
+}
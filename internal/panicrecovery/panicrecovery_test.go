@@ -0,0 +1,136 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package panicrecovery
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/internal/componentusage"
+)
+
+// panickingConsumer panics on the batches with a span without a name.
+type panickingConsumer struct {
+	next consumer.TraceConsumer
+}
+
+func (pc *panickingConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	for _, span := range td.Spans {
+		_ = span.Name.Value
+	}
+	return pc.next.ConsumeTraceData(ctx, td)
+}
+
+func (pc *panickingConsumer) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	_ = md.Node.Identifier
+	return nil
+}
+
+// rawConsumer accepts the raw trace data of the "raw" format and panics on an
+// empty payload.
+type rawConsumer struct {
+	exportertest.SinkTraceExporter
+	raw []consumerdata.RawTraceData
+}
+
+func (rc *rawConsumer) AcceptsRawTraceFormat(format string) bool {
+	return format == "raw"
+}
+
+func (rc *rawConsumer) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error {
+	_ = rtd.Payload[0]
+	rc.raw = append(rc.raw, rtd)
+	return nil
+}
+
+func TestWrapDisabled(t *testing.T) {
+	SetEnabled(false)
+	defer SetEnabled(true)
+	sink := &exportertest.SinkTraceExporter{}
+	assert.Equal(t, consumer.TraceConsumer(sink), WrapTraceConsumer(zap.NewNop(), componentusage.KindExporter, "sink", sink))
+	assert.Nil(t, MetricViews(telemetry.Detailed))
+}
+
+func TestRecoverTraceData(t *testing.T) {
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	core, logs := observer.New(zapcore.ErrorLevel)
+	sink := &exportertest.SinkTraceExporter{}
+	tc := WrapTraceConsumer(zap.New(core), componentusage.KindProcessor, "panicking", &panickingConsumer{next: sink})
+
+	good := consumerdata.TraceData{Spans: []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: "a"}}}}
+	bad := consumerdata.TraceData{Spans: []*tracepb.Span{{}, {}}}
+	require.NoError(t, tc.ConsumeTraceData(context.Background(), good))
+	err := tc.ConsumeTraceData(context.Background(), bad)
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	// The service keeps consuming the next batches.
+	require.NoError(t, tc.ConsumeTraceData(context.Background(), good))
+	assert.Len(t, sink.AllTraces(), 2)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "panicking", entry.ContextMap()["component"])
+	assert.Equal(t, int64(2), entry.ContextMap()["spans"])
+	assert.Contains(t, entry.ContextMap()["stack"], "ConsumeTraceData")
+
+	rows, err := view.RetrieveData(ViewComponentPanics.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, float64(1), rows[0].Data.(*view.SumData).Value)
+}
+
+func TestRecoverMetricsData(t *testing.T) {
+	mc := WrapMetricsConsumer(zap.NewNop(), componentusage.KindExporter, "panicking", &panickingConsumer{})
+	md := consumerdata.MetricsData{Node: &commonpb.Node{}, Metrics: []*metricspb.Metric{{}}}
+	require.NoError(t, mc.ConsumeMetricsData(context.Background(), md))
+	err := mc.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{})
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+func TestRecoverRawTraceData(t *testing.T) {
+	tc := WrapTraceConsumer(zap.NewNop(), componentusage.KindExporter, "sink", &exportertest.SinkTraceExporter{})
+	_, ok := tc.(consumer.RawTraceConsumer)
+	assert.False(t, ok, "the consumers not accepting raw trace data must not be made to")
+
+	rc := &rawConsumer{}
+	rtc, ok := WrapTraceConsumer(zap.NewNop(), componentusage.KindExporter, "raw", rc).(consumer.RawTraceConsumer)
+	require.True(t, ok)
+	assert.True(t, rtc.AcceptsRawTraceFormat("raw"))
+	assert.False(t, rtc.AcceptsRawTraceFormat("jaeger"))
+
+	require.NoError(t, rtc.ConsumeRawTraceData(context.Background(), consumerdata.RawTraceData{Format: "raw", Payload: []byte{1}}))
+	err := rtc.ConsumeRawTraceData(context.Background(), consumerdata.RawTraceData{Format: "raw"})
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Len(t, rc.raw, 1)
+}
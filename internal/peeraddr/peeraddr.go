@@ -0,0 +1,183 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package peeraddr adds the address of the clients sending data to the
+// receivers to the node of the received batches, e.g. so telemetry can be
+// associated with the Kubernetes pod that sent it.
+package peeraddr
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// IPAttribute is the node attribute holding the IP address of the client.
+	IPAttribute = "net.peer.ip"
+	// HostnameAttribute is the node attribute holding the hostname of the
+	// client, resolved from its IP address.
+	HostnameAttribute = "net.peer.name"
+
+	// forwardedForKey is the metadata set by the grpc-gateway with the
+	// address of the HTTP clients.
+	forwardedForKey = "x-forwarded-for"
+
+	lookupTimeout = time.Second
+	// maxCachedHostnames bounds the cache of resolved hostnames, the cache
+	// is cleared when it is full.
+	maxCachedHostnames = 4096
+)
+
+// Settings controls the node attributes added with the client address.
+type Settings struct {
+	// Enabled adds the IP address of the client as the "net.peer.ip" node
+	// attribute.
+	Enabled bool `mapstructure:"enabled"`
+
+	// ResolveHostname also adds the hostname of the client, resolved with a
+	// reverse DNS lookup, as the "net.peer.name" node attribute.
+	ResolveHostname bool `mapstructure:"resolve-hostname"`
+}
+
+// Annotator adds the client address to nodes. A nil Annotator, returned when
+// the attributes are disabled, leaves the nodes unchanged.
+type Annotator struct {
+	resolveHostname bool
+	lookupAddr      func(ctx context.Context, addr string) ([]string, error)
+
+	mu        sync.Mutex
+	hostnames map[string]string
+}
+
+// NewAnnotator creates the Annotator for the given settings.
+func NewAnnotator(settings Settings) *Annotator {
+	if !settings.Enabled {
+		return nil
+	}
+	return &Annotator{
+		resolveHostname: settings.ResolveHostname,
+		lookupAddr:      net.DefaultResolver.LookupAddr,
+		hostnames:       make(map[string]string),
+	}
+}
+
+// Node returns a copy of the node with the address attributes added, the
+// node itself is not modified since it may be shared between batches. The
+// node is returned unchanged if the IP address is unknown.
+func (a *Annotator) Node(node *commonpb.Node, ip string) *commonpb.Node {
+	if a == nil || ip == "" {
+		return node
+	}
+
+	annotated := &commonpb.Node{}
+	if node != nil {
+		annotated.Identifier = node.Identifier
+		annotated.LibraryInfo = node.LibraryInfo
+		annotated.ServiceInfo = node.ServiceInfo
+	}
+	annotated.Attributes = make(map[string]string, len(node.GetAttributes())+2)
+	for k, v := range node.GetAttributes() {
+		annotated.Attributes[k] = v
+	}
+	annotated.Attributes[IPAttribute] = ip
+	if a.resolveHostname {
+		if hostname := a.hostname(ip); hostname != "" {
+			annotated.Attributes[HostnameAttribute] = hostname
+		}
+	}
+	return annotated
+}
+
+// hostname resolves the IP address, the results, including the failures, are
+// cached to not block every request on a lookup.
+func (a *Annotator) hostname(ip string) string {
+	a.mu.Lock()
+	hostname, ok := a.hostnames[ip]
+	a.mu.Unlock()
+	if ok {
+		return hostname
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	if names, err := a.lookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		hostname = strings.TrimSuffix(names[0], ".")
+	}
+
+	a.mu.Lock()
+	if len(a.hostnames) >= maxCachedHostnames {
+		a.hostnames = make(map[string]string)
+	}
+	a.hostnames[ip] = hostname
+	a.mu.Unlock()
+	return hostname
+}
+
+// FromGRPC returns the IP address of the client of a gRPC call. The calls of
+// a local client, like the grpc-gateway serving HTTP/JSON requests, are
+// attributed to the address the client reported in the x-forwarded-for
+// metadata, if any.
+func FromGRPC(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	ip := hostOf(p.Addr.String())
+	if parsed := net.ParseIP(ip); parsed == nil || !parsed.IsLoopback() {
+		return ip
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if fwd := md.Get(forwardedForKey); len(fwd) > 0 {
+			// The gateway appends the address of its client, the previous
+			// ones were sent by the client itself and can't be trusted.
+			hops := strings.Split(fwd[len(fwd)-1], ",")
+			if last := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(last) != nil {
+				return last
+			}
+		}
+	}
+	return ip
+}
+
+// FromHTTP returns the IP address of the client of an HTTP request.
+func FromHTTP(r *http.Request) string {
+	return hostOf(r.RemoteAddr)
+}
+
+// FromHostPort returns the IP address of a host:port address, or an empty
+// string if the host is not an IP address or is unspecified.
+func FromHostPort(hostPort string) string {
+	ip := net.ParseIP(hostOf(hostPort))
+	if ip == nil || ip.IsUnspecified() {
+		return ""
+	}
+	return ip.String()
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
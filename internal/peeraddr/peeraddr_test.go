@@ -0,0 +1,100 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peeraddr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestNode(t *testing.T) {
+	assert.Nil(t, NewAnnotator(Settings{ResolveHostname: true}))
+	var disabled *Annotator
+	node := &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+		Attributes:  map[string]string{"a": "b"},
+	}
+	assert.Equal(t, node, disabled.Node(node, "10.0.0.1"))
+
+	a := NewAnnotator(Settings{Enabled: true})
+	assert.Equal(t, node, a.Node(node, ""))
+
+	got := a.Node(node, "10.0.0.1")
+	assert.Equal(t, &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+		Attributes:  map[string]string{"a": "b", IPAttribute: "10.0.0.1"},
+	}, got)
+	// The original node is shared by the batches of a stream, it must not
+	// be modified.
+	assert.Equal(t, map[string]string{"a": "b"}, node.Attributes)
+
+	assert.Equal(t, map[string]string{IPAttribute: "10.0.0.1"}, a.Node(nil, "10.0.0.1").Attributes)
+}
+
+func TestNode_ResolveHostname(t *testing.T) {
+	a := NewAnnotator(Settings{Enabled: true, ResolveHostname: true})
+	lookups := 0
+	a.lookupAddr = func(_ context.Context, addr string) ([]string, error) {
+		lookups++
+		if addr == "10.0.0.1" {
+			return []string{"pod-1.example.com."}, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	for i := 0; i < 2; i++ {
+		got := a.Node(nil, "10.0.0.1")
+		assert.Equal(t, "pod-1.example.com", got.Attributes[HostnameAttribute])
+		got = a.Node(nil, "10.0.0.2")
+		assert.Equal(t, map[string]string{IPAttribute: "10.0.0.2"}, got.Attributes)
+	}
+	// The failures are cached too.
+	assert.Equal(t, 2, lookups)
+}
+
+func TestFromGRPC(t *testing.T) {
+	assert.Equal(t, "", FromGRPC(context.Background()))
+
+	remote := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	assert.Equal(t, "10.0.0.1", FromGRPC(remote))
+
+	// The forwarded address is ignored from remote clients.
+	spoofed := metadata.NewIncomingContext(remote, metadata.Pairs(forwardedForKey, "10.0.0.9"))
+	assert.Equal(t, "10.0.0.1", FromGRPC(spoofed))
+
+	local := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}})
+	assert.Equal(t, "127.0.0.1", FromGRPC(local))
+	gateway := metadata.NewIncomingContext(local, metadata.Pairs(forwardedForKey, "10.0.0.9, 10.0.0.2"))
+	assert.Equal(t, "10.0.0.2", FromGRPC(gateway))
+}
+
+func TestFromHTTP(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", FromHTTP(&http.Request{RemoteAddr: "10.0.0.1:1234"}))
+	assert.Equal(t, "::1", FromHTTP(&http.Request{RemoteAddr: "[::1]:1234"}))
+}
+
+func TestFromHostPort(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", FromHostPort("10.0.0.1:1234"))
+	assert.Equal(t, "", FromHostPort("0.0.0.0:0"))
+	assert.Equal(t, "", FromHostPort("example.com:1234"))
+}
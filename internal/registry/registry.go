@@ -0,0 +1,65 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry links components that are not part of the same data
+// pipelines, e.g. an extension and the processors feeding it: a component
+// registers a value under its name and the others look it up by that name.
+package registry
+
+import "sync"
+
+// Registry holds values registered by name. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{values: make(map[string]interface{})}
+}
+
+// Register makes v available under the given name, replacing any value
+// previously registered with that name.
+func (r *Registry) Register(name string, v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = v
+}
+
+// Unregister removes the value registered under the given name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.values, name)
+}
+
+// Lookup returns the value registered under the given name or nil if there is
+// none.
+func (r *Registry) Lookup(name string) interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.values[name]
+}
+
+// Values returns the registered values, in no particular order.
+func (r *Registry) Values() []interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	values := make([]interface{}, 0, len(r.values))
+	for _, v := range r.values {
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,44 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := New()
+	assert.Nil(t, r.Lookup("a"))
+	assert.Empty(t, r.Values())
+
+	r.Register("a", 1)
+	r.Register("b", 2)
+	assert.Equal(t, 1, r.Lookup("a"))
+	assert.ElementsMatch(t, []interface{}{1, 2}, r.Values())
+
+	// Registering again under the same name replaces the value.
+	r.Register("a", 3)
+	assert.Equal(t, 3, r.Lookup("a"))
+
+	r.Unregister("a")
+	assert.Nil(t, r.Lookup("a"))
+	assert.ElementsMatch(t, []interface{}{2}, r.Values())
+
+	// Unregistering an unknown name is a no-op.
+	r.Unregister("unknown")
+	assert.Equal(t, 2, r.Lookup("b"))
+}
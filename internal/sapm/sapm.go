@@ -0,0 +1,162 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sapm implements the wire format of the SignalFx APM protocol (SAPM):
+// a PostSpansRequest protobuf message with Jaeger batches, sent gzip
+// compressed over HTTP.
+package sapm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jaegertracing/jaeger/model"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+)
+
+const (
+	// TracePath is the path of the SAPM trace endpoint.
+	TracePath = "/v2/trace"
+	// AccessTokenHeader is the header carrying the access token of the sender.
+	AccessTokenHeader = "X-SF-Token"
+	// ContentType is the content type of SAPM requests.
+	ContentType = "application/x-protobuf"
+	// GzipEncoding is the content encoding of compressed SAPM requests.
+	GzipEncoding = "gzip"
+)
+
+// batchesField is the field number of "repeated jaeger.api_v2.Batch batches"
+// in the PostSpansRequest message.
+const batchesField = 1
+
+var errTruncated = errors.New("sapm: truncated message")
+
+// PostSpansRequest is the message sent in the body of SAPM requests.
+type PostSpansRequest struct {
+	Batches []*model.Batch
+}
+
+// Marshal encodes the request in the protobuf wire format.
+func (r *PostSpansRequest) Marshal() ([]byte, error) {
+	buf := proto.NewBuffer(nil)
+	for _, batch := range r.Batches {
+		if batch == nil {
+			continue
+		}
+		b, err := batch.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if err := buf.EncodeVarint(uint64(batchesField<<3 | proto.WireBytes)); err != nil {
+			return nil, err
+		}
+		if err := buf.EncodeRawBytes(b); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a request in the protobuf wire format, unknown fields are
+// skipped.
+func (r *PostSpansRequest) Unmarshal(b []byte) error {
+	r.Batches = nil
+	for len(b) > 0 {
+		key, n := proto.DecodeVarint(b)
+		if n == 0 {
+			return errTruncated
+		}
+		b = b[n:]
+
+		field, wireType := key>>3, key&7
+		var size int
+		switch wireType {
+		case proto.WireVarint:
+			if _, n = proto.DecodeVarint(b); n == 0 {
+				return errTruncated
+			}
+			size = n
+		case proto.WireFixed64:
+			size = 8
+		case proto.WireFixed32:
+			size = 4
+		case proto.WireBytes:
+			l, n := proto.DecodeVarint(b)
+			if n == 0 || l > uint64(len(b)-n) {
+				return errTruncated
+			}
+			b = b[n:]
+			size = int(l)
+		default:
+			return fmt.Errorf("sapm: unsupported wire type %d", wireType)
+		}
+		if size > len(b) {
+			return errTruncated
+		}
+
+		if field == batchesField && wireType == proto.WireBytes {
+			batch := &model.Batch{}
+			if err := batch.Unmarshal(b[:size]); err != nil {
+				return err
+			}
+			r.Batches = append(r.Batches, batch)
+		}
+		b = b[size:]
+	}
+	return nil
+}
+
+// ReadRequest reads and decodes the PostSpansRequest from the body of r, the
+// decompressed body is limited by the given settings.
+func ReadRequest(r *http.Request, settings decompression.Settings) (*PostSpansRequest, error) {
+	b, err := decompression.ReadAll(r, settings)
+	if err != nil {
+		return nil, err
+	}
+	req := &PostSpansRequest{}
+	if err := req.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// NewHTTPRequest creates a gzip compressed SAPM request to the given url.
+func NewHTTPRequest(url string, req *PostSpansRequest) (*http.Request, error) {
+	b, err := req.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	if _, err := gzw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, &buf)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", ContentType)
+	httpReq.Header.Set("Content-Encoding", GzipEncoding)
+	return httpReq, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapm
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+)
+
+func testRequest() *PostSpansRequest {
+	return &PostSpansRequest{
+		Batches: []*model.Batch{
+			{
+				Process: &model.Process{ServiceName: "frontend"},
+				Spans: []*model.Span{
+					{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(2), OperationName: "a"},
+				},
+			},
+			{
+				Process: &model.Process{ServiceName: "backend"},
+				Spans: []*model.Span{
+					{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(3), OperationName: "b"},
+					{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(4), OperationName: "c"},
+				},
+			},
+		},
+	}
+}
+
+func TestPostSpansRequest_RoundTrip(t *testing.T) {
+	want := testRequest()
+	b, err := want.Marshal()
+	require.NoError(t, err)
+
+	got := &PostSpansRequest{}
+	require.NoError(t, got.Unmarshal(b))
+	assert.Equal(t, want, got)
+}
+
+func TestPostSpansRequest_SkipsUnknownFields(t *testing.T) {
+	b, err := testRequest().Marshal()
+	require.NoError(t, err)
+
+	buf := proto.NewBuffer(nil)
+	require.NoError(t, buf.EncodeVarint(2<<3|proto.WireVarint))
+	require.NoError(t, buf.EncodeVarint(150))
+	require.NoError(t, buf.EncodeVarint(3<<3|proto.WireBytes))
+	require.NoError(t, buf.EncodeStringBytes("ignored"))
+	require.NoError(t, buf.EncodeVarint(4<<3|proto.WireFixed64))
+	require.NoError(t, buf.EncodeFixed64(7))
+	require.NoError(t, buf.EncodeVarint(5<<3|proto.WireFixed32))
+	require.NoError(t, buf.EncodeFixed32(7))
+
+	got := &PostSpansRequest{}
+	require.NoError(t, got.Unmarshal(append(buf.Bytes(), b...)))
+	assert.Equal(t, testRequest(), got)
+}
+
+func TestPostSpansRequest_Truncated(t *testing.T) {
+	b, err := testRequest().Marshal()
+	require.NoError(t, err)
+
+	got := &PostSpansRequest{}
+	assert.Error(t, got.Unmarshal(b[:len(b)-1]))
+	assert.Error(t, got.Unmarshal([]byte{0x80}))
+	assert.Error(t, got.Unmarshal([]byte{1<<3 | 7}))
+}
+
+func TestHTTPRequest_RoundTrip(t *testing.T) {
+	httpReq, err := NewHTTPRequest("http://localhost"+TracePath, testRequest())
+	require.NoError(t, err)
+	assert.Equal(t, ContentType, httpReq.Header.Get("Content-Type"))
+	assert.Equal(t, GzipEncoding, httpReq.Header.Get("Content-Encoding"))
+
+	// Simulate the server side of the request.
+	srvReq := httptest.NewRequest(httpReq.Method, httpReq.URL.String(), httpReq.Body)
+	srvReq.Header = httpReq.Header
+	got, err := ReadRequest(srvReq, decompression.DefaultSettings())
+	require.NoError(t, err)
+	assert.Equal(t, testRequest(), got)
+}
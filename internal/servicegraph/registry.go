@@ -0,0 +1,39 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicegraph
+
+import "github.com/open-telemetry/opentelemetry-service/internal/registry"
+
+// graphs links the graphs served by the service-graph extensions with the
+// service-graph processors feeding them, since extensions are not part of the
+// data pipelines.
+var graphs = registry.New()
+
+// Register makes g the graph served by the service-graph extension named name.
+func Register(name string, g *Graph) {
+	graphs.Register(name, g)
+}
+
+// Unregister removes the graph of the service-graph extension named name.
+func Unregister(name string) {
+	graphs.Unregister(name)
+}
+
+// Lookup returns the graph of the service-graph extension named name or nil if
+// the extension is not started.
+func Lookup(name string) *Graph {
+	g, _ := graphs.Lookup(name).(*Graph)
+	return g
+}
@@ -0,0 +1,276 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package servicegraph maintains an in-memory service dependency graph built
+// from observed spans. A call between two services is detected when a span
+// has a parent span reported by a different service.
+package servicegraph
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+)
+
+const unknownService = "unknown-service"
+
+type edgeKey struct {
+	source string
+	target string
+}
+
+type counts struct {
+	total  int64
+	errors int64
+}
+
+func (c *counts) add(isError bool) {
+	c.total++
+	if isError {
+		c.errors++
+	}
+}
+
+// pendingChild is a span whose parent was not seen yet.
+type pendingChild struct {
+	service string
+	isError bool
+}
+
+// Graph is a service dependency graph. Parent and child spans are usually
+// reported in different batches, possibly by different instances, so the
+// service of the last maxSpans spans is remembered to match them.
+type Graph struct {
+	maxSpans int
+	now      func() time.Time
+
+	// mu protects the fields below
+	mu    sync.Mutex
+	start time.Time
+	// spans maps span keys to the service that reported the span.
+	spans map[string]string
+	// pending maps span keys to the children waiting for the span.
+	pending map[string][]pendingChild
+	// ring holds the span keys in insertion order, the oldest is evicted when
+	// the ring is full.
+	ring  []string
+	next  int
+	nodes map[string]*counts
+	edges map[edgeKey]*counts
+}
+
+// NewGraph creates a Graph remembering up to maxSpans spans to match
+// parent and child spans.
+func NewGraph(maxSpans int) *Graph {
+	if maxSpans <= 0 {
+		maxSpans = 1
+	}
+	g := &Graph{
+		maxSpans: maxSpans,
+		now:      time.Now,
+	}
+	g.Reset()
+	return g
+}
+
+// Reset removes all nodes and edges from the graph.
+func (g *Graph) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.start = g.now()
+	g.spans = make(map[string]string)
+	g.pending = make(map[string][]pendingChild)
+	g.ring = make([]string, 0, g.maxSpans)
+	g.next = 0
+	g.nodes = make(map[string]*counts)
+	g.edges = make(map[edgeKey]*counts)
+}
+
+// Record adds the spans reported by node to the graph.
+func (g *Graph) Record(node *commonpb.Node, spans []*tracepb.Span) {
+	service := node.GetServiceInfo().GetName()
+	if service == "" {
+		service = unknownService
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	nodeCounts := g.nodes[service]
+	if nodeCounts == nil {
+		nodeCounts = &counts{}
+		g.nodes[service] = nodeCounts
+	}
+
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		isError := span.GetStatus().GetCode() != 0
+		nodeCounts.add(isError)
+
+		key := spanKey(span.TraceId, span.SpanId)
+		_, seen := g.spans[key]
+		_, waiting := g.pending[key]
+		if !seen && !waiting {
+			g.remember(key)
+		}
+		g.spans[key] = service
+		for _, child := range g.pending[key] {
+			g.addEdge(service, child.service, child.isError)
+		}
+		delete(g.pending, key)
+
+		if len(span.ParentSpanId) == 0 {
+			continue
+		}
+		parentKey := spanKey(span.TraceId, span.ParentSpanId)
+		if parentService, ok := g.spans[parentKey]; ok {
+			g.addEdge(parentService, service, isError)
+			continue
+		}
+		if _, waiting := g.pending[parentKey]; !waiting {
+			g.remember(parentKey)
+		}
+		g.pending[parentKey] = append(g.pending[parentKey], pendingChild{service: service, isError: isError})
+	}
+}
+
+// remember adds the key to the ring, evicting the oldest key when full.
+func (g *Graph) remember(key string) {
+	if len(g.ring) < g.maxSpans {
+		g.ring = append(g.ring, key)
+		return
+	}
+	evicted := g.ring[g.next]
+	delete(g.spans, evicted)
+	delete(g.pending, evicted)
+	g.ring[g.next] = key
+	g.next = (g.next + 1) % g.maxSpans
+}
+
+func (g *Graph) addEdge(source, target string, isError bool) {
+	if source == target {
+		return
+	}
+	key := edgeKey{source: source, target: target}
+	edgeCounts := g.edges[key]
+	if edgeCounts == nil {
+		edgeCounts = &counts{}
+		g.edges[key] = edgeCounts
+	}
+	edgeCounts.add(isError)
+}
+
+func spanKey(traceID, spanID []byte) string {
+	return string(traceID) + string(spanID)
+}
+
+// Node is a service of the graph.
+type Node struct {
+	Service string `json:"service"`
+	// Spans is the number of spans reported by the service.
+	Spans int64 `json:"spans"`
+	// Errors is the number of spans with an error status.
+	Errors int64 `json:"errors"`
+	// SpanRate is the number of spans per second.
+	SpanRate float64 `json:"spanRate"`
+	// ErrorRate is the fraction of the spans with an error status.
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// Edge is a dependency between two services of the graph.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Calls is the number of spans of the target with a parent in the source.
+	Calls int64 `json:"calls"`
+	// Errors is the number of calls with an error status.
+	Errors int64 `json:"errors"`
+	// CallRate is the number of calls per second.
+	CallRate float64 `json:"callRate"`
+	// ErrorRate is the fraction of the calls with an error status.
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// Snapshot is the state of the graph at a given time, rates are computed
+// since the start of the graph.
+type Snapshot struct {
+	Start           time.Time `json:"start"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Nodes           []Node    `json:"nodes"`
+	Edges           []Edge    `json:"edges"`
+}
+
+// Snapshot returns the current state of the graph, sorted by service names.
+func (g *Graph) Snapshot() Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	duration := g.now().Sub(g.start).Seconds()
+	snapshot := Snapshot{
+		Start:           g.start,
+		DurationSeconds: duration,
+		Nodes:           make([]Node, 0, len(g.nodes)),
+		Edges:           make([]Edge, 0, len(g.edges)),
+	}
+	for service, c := range g.nodes {
+		snapshot.Nodes = append(snapshot.Nodes, Node{
+			Service:   service,
+			Spans:     c.total,
+			Errors:    c.errors,
+			SpanRate:  rate(c.total, duration),
+			ErrorRate: ratio(c.errors, c.total),
+		})
+	}
+	for key, c := range g.edges {
+		snapshot.Edges = append(snapshot.Edges, Edge{
+			Source:    key.source,
+			Target:    key.target,
+			Calls:     c.total,
+			Errors:    c.errors,
+			CallRate:  rate(c.total, duration),
+			ErrorRate: ratio(c.errors, c.total),
+		})
+	}
+
+	sort.Slice(snapshot.Nodes, func(i, j int) bool {
+		return snapshot.Nodes[i].Service < snapshot.Nodes[j].Service
+	})
+	sort.Slice(snapshot.Edges, func(i, j int) bool {
+		if snapshot.Edges[i].Source != snapshot.Edges[j].Source {
+			return snapshot.Edges[i].Source < snapshot.Edges[j].Source
+		}
+		return snapshot.Edges[i].Target < snapshot.Edges[j].Target
+	})
+	return snapshot
+}
+
+func rate(count int64, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(count) / seconds
+}
+
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,111 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicegraph
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTraceID = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+func node(service string) *commonpb.Node {
+	return &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: service}}
+}
+
+func span(id, parent byte, errorCode int32) *tracepb.Span {
+	s := &tracepb.Span{
+		TraceId: testTraceID,
+		SpanId:  []byte{0, 0, 0, 0, 0, 0, 0, id},
+	}
+	if parent != 0 {
+		s.ParentSpanId = []byte{0, 0, 0, 0, 0, 0, 0, parent}
+	}
+	if errorCode != 0 {
+		s.Status = &tracepb.Status{Code: errorCode}
+	}
+	return s
+}
+
+func newTestGraph(maxSpans int) (*Graph, *time.Time) {
+	g := NewGraph(maxSpans)
+	now := time.Unix(1000, 0)
+	g.now = func() time.Time { return now }
+	g.Reset()
+	return g, &now
+}
+
+func TestGraph_Edges(t *testing.T) {
+	g, now := newTestGraph(100)
+
+	g.Record(node("frontend"), []*tracepb.Span{span(1, 0, 0), span(2, 1, 0)})
+	// Parent seen before the children.
+	g.Record(node("backend"), []*tracepb.Span{span(3, 2, 0), span(4, 2, 2)})
+	// Child seen before the parent.
+	g.Record(node("db"), []*tracepb.Span{span(6, 5, 0)})
+	g.Record(node("backend"), []*tracepb.Span{span(5, 3, 0)})
+	*now = now.Add(10 * time.Second)
+
+	snapshot := g.Snapshot()
+	assert.Equal(t, 10.0, snapshot.DurationSeconds)
+	assert.Equal(t, []Node{
+		{Service: "backend", Spans: 3, Errors: 1, SpanRate: 0.3, ErrorRate: 1.0 / 3},
+		{Service: "db", Spans: 1, SpanRate: 0.1},
+		{Service: "frontend", Spans: 2, SpanRate: 0.2},
+	}, snapshot.Nodes)
+	assert.Equal(t, []Edge{
+		{Source: "backend", Target: "db", Calls: 1, CallRate: 0.1},
+		{Source: "frontend", Target: "backend", Calls: 2, Errors: 1, CallRate: 0.2, ErrorRate: 0.5},
+	}, snapshot.Edges)
+}
+
+func TestGraph_Eviction(t *testing.T) {
+	g, _ := newTestGraph(2)
+
+	g.Record(node("frontend"), []*tracepb.Span{span(1, 0, 0)})
+	g.Record(node("frontend"), []*tracepb.Span{span(2, 0, 0), span(3, 0, 0)})
+	// The parent was evicted, no edge can be created.
+	g.Record(node("backend"), []*tracepb.Span{span(4, 1, 0)})
+	assert.Empty(t, g.Snapshot().Edges)
+	assert.True(t, len(g.spans)+len(g.pending) <= 2)
+
+	g.Record(node("backend"), []*tracepb.Span{span(5, 4, 0)})
+	assert.Empty(t, g.Snapshot().Edges, "spans of the same service are not edges")
+}
+
+func TestGraph_Reset(t *testing.T) {
+	g, _ := newTestGraph(10)
+	g.Record(nil, []*tracepb.Span{span(1, 0, 0)})
+	snapshot := g.Snapshot()
+	require.Len(t, snapshot.Nodes, 1)
+	assert.Equal(t, unknownService, snapshot.Nodes[0].Service)
+
+	g.Reset()
+	assert.Empty(t, g.Snapshot().Nodes)
+}
+
+func TestRegistry(t *testing.T) {
+	g := NewGraph(10)
+	assert.Nil(t, Lookup("test"))
+	Register("test", g)
+	assert.True(t, g == Lookup("test"))
+	Unregister("test")
+	assert.Nil(t, Lookup("test"))
+}
@@ -0,0 +1,259 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spancount counts the spans matching configurable conditions and
+// reports the counts as cumulative metrics.
+package spancount
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+// ServiceLabel is the label key holding the name of the service that
+// reported the counted spans.
+const ServiceLabel = "service"
+
+// AttributeCondition matches the spans with a string attribute equal to one of
+// the listed values.
+type AttributeCondition struct {
+	// Key of the attribute.
+	Key string `mapstructure:"key"`
+	// Values is the set of attribute values matched, if empty any value of the
+	// attribute is matched.
+	Values []string `mapstructure:"values"`
+}
+
+// MetricDefinition defines a metric counting the spans matching all of its
+// conditions, a condition left empty matches all the spans.
+type MetricDefinition struct {
+	// Name of the metric.
+	Name string `mapstructure:"name"`
+	// Description of the metric.
+	Description string `mapstructure:"description"`
+	// Services matches the spans reported by one of the listed services.
+	Services []string `mapstructure:"services"`
+	// SpanNames matches the spans with one of the listed names.
+	SpanNames []string `mapstructure:"span-names"`
+	// ErrorsOnly matches the spans with a non-OK status.
+	ErrorsOnly bool `mapstructure:"errors-only"`
+	// Attributes matches the spans satisfying all of the attribute conditions.
+	Attributes []AttributeCondition `mapstructure:"attributes"`
+	// LabelAttributes are the keys of the span attributes added as labels to
+	// the metric, in addition to the service label. A span without one of
+	// the attributes has an empty value for the label.
+	LabelAttributes []string `mapstructure:"label-attributes"`
+}
+
+// Validate checks that the metric definitions can be used to create a Counter.
+func Validate(defs []MetricDefinition) error {
+	if len(defs) == 0 {
+		return errors.New("at least one metric must be defined")
+	}
+	names := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if def.Name == "" {
+			return errors.New("metrics require a non-empty name")
+		}
+		if names[def.Name] {
+			return fmt.Errorf("metric %q is defined more than once", def.Name)
+		}
+		names[def.Name] = true
+		for _, attr := range def.Attributes {
+			if attr.Key == "" {
+				return fmt.Errorf("metric %q has an attribute condition without key", def.Name)
+			}
+		}
+	}
+	return nil
+}
+
+type series struct {
+	labelValues []string
+	count       int64
+}
+
+type counterMetric struct {
+	def       MetricDefinition
+	services  map[string]bool
+	spanNames map[string]bool
+	attrs     []attributeMatcher
+	// series is keyed by the label values joined by a zero byte.
+	series map[string]*series
+}
+
+type attributeMatcher struct {
+	key    string
+	values map[string]bool
+}
+
+// Counter counts the spans matching the metric definitions.
+type Counter struct {
+	start time.Time
+
+	// mu protects the metrics series
+	mu      sync.Mutex
+	metrics []*counterMetric
+}
+
+// NewCounter creates a Counter for the given metric definitions, the counts
+// are cumulative since start.
+func NewCounter(defs []MetricDefinition, start time.Time) (*Counter, error) {
+	if err := Validate(defs); err != nil {
+		return nil, err
+	}
+	c := &Counter{start: start}
+	for _, def := range defs {
+		m := &counterMetric{
+			def:       def,
+			services:  toSet(def.Services),
+			spanNames: toSet(def.SpanNames),
+			series:    make(map[string]*series),
+		}
+		for _, attr := range def.Attributes {
+			m.attrs = append(m.attrs, attributeMatcher{key: attr.Key, values: toSet(attr.Values)})
+		}
+		c.metrics = append(c.metrics, m)
+	}
+	return c, nil
+}
+
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// Count counts the spans reported by node.
+func (c *Counter) Count(node *commonpb.Node, spans []*tracepb.Span) {
+	service := node.GetServiceInfo().GetName()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, m := range c.metrics {
+		if m.services != nil && !m.services[service] {
+			continue
+		}
+		for _, span := range spans {
+			if span == nil || !m.matches(span) {
+				continue
+			}
+			labelValues := make([]string, 0, 1+len(m.def.LabelAttributes))
+			labelValues = append(labelValues, service)
+			for _, key := range m.def.LabelAttributes {
+				labelValues = append(labelValues, stringAttribute(span, key))
+			}
+			key := strings.Join(labelValues, "\x00")
+			s := m.series[key]
+			if s == nil {
+				s = &series{labelValues: labelValues}
+				m.series[key] = s
+			}
+			s.count++
+		}
+	}
+}
+
+func (m *counterMetric) matches(span *tracepb.Span) bool {
+	if m.spanNames != nil && !m.spanNames[span.GetName().GetValue()] {
+		return false
+	}
+	if m.def.ErrorsOnly && span.GetStatus().GetCode() == 0 {
+		return false
+	}
+	for _, attr := range m.attrs {
+		value, ok := span.GetAttributes().GetAttributeMap()[attr.key]
+		if !ok {
+			return false
+		}
+		if attr.values != nil && !attr.values[value.GetStringValue().GetValue()] {
+			return false
+		}
+	}
+	return true
+}
+
+func stringAttribute(span *tracepb.Span, key string) string {
+	return span.GetAttributes().GetAttributeMap()[key].GetStringValue().GetValue()
+}
+
+// Metrics returns the counts as cumulative int64 metrics with points at the
+// given time. Metrics without any matching span are omitted.
+func (c *Counter) Metrics(now time.Time) []*metricspb.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := internal.TimeToTimestamp(c.start)
+	ts := internal.TimeToTimestamp(now)
+	metrics := make([]*metricspb.Metric, 0, len(c.metrics))
+	for _, m := range c.metrics {
+		if len(m.series) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(m.series))
+		for key := range m.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		timeseries := make([]*metricspb.TimeSeries, 0, len(keys))
+		for _, key := range keys {
+			s := m.series[key]
+			labelValues := make([]*metricspb.LabelValue, 0, len(s.labelValues))
+			for _, v := range s.labelValues {
+				labelValues = append(labelValues, &metricspb.LabelValue{Value: v, HasValue: true})
+			}
+			timeseries = append(timeseries, &metricspb.TimeSeries{
+				StartTimestamp: start,
+				LabelValues:    labelValues,
+				Points: []*metricspb.Point{
+					{Timestamp: ts, Value: &metricspb.Point_Int64Value{Int64Value: s.count}},
+				},
+			})
+		}
+
+		labelKeys := make([]*metricspb.LabelKey, 0, 1+len(m.def.LabelAttributes))
+		labelKeys = append(labelKeys, &metricspb.LabelKey{Key: ServiceLabel})
+		for _, key := range m.def.LabelAttributes {
+			labelKeys = append(labelKeys, &metricspb.LabelKey{Key: key})
+		}
+		metrics = append(metrics, &metricspb.Metric{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:        m.def.Name,
+				Description: m.def.Description,
+				Unit:        "1",
+				Type:        metricspb.MetricDescriptor_CUMULATIVE_INT64,
+				LabelKeys:   labelKeys,
+			},
+			Timeseries: timeseries,
+		})
+	}
+	return metrics
+}
@@ -0,0 +1,141 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spancount
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+func node(service string) *commonpb.Node {
+	return &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: service}}
+}
+
+func span(name string, errorCode int32, attrs map[string]string) *tracepb.Span {
+	s := &tracepb.Span{Name: &tracepb.TruncatableString{Value: name}}
+	if errorCode != 0 {
+		s.Status = &tracepb.Status{Code: errorCode}
+	}
+	if len(attrs) > 0 {
+		s.Attributes = &tracepb.Span_Attributes{AttributeMap: make(map[string]*tracepb.AttributeValue)}
+		for k, v := range attrs {
+			s.Attributes.AttributeMap[k] = &tracepb.AttributeValue{
+				Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: v}},
+			}
+		}
+	}
+	return s
+}
+
+// counts returns the value of each timeseries of the metric keyed by the
+// label values joined by commas.
+func counts(m *metricspb.Metric) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, ts := range m.Timeseries {
+		key := ""
+		for i, v := range ts.LabelValues {
+			if i > 0 {
+				key += ","
+			}
+			key += v.Value
+		}
+		counts[key] = ts.Points[0].GetInt64Value()
+	}
+	return counts
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		defs []MetricDefinition
+	}{
+		{name: "no_metrics"},
+		{name: "no_name", defs: []MetricDefinition{{}}},
+		{name: "duplicated_name", defs: []MetricDefinition{{Name: "a"}, {Name: "a"}}},
+		{name: "no_attribute_key", defs: []MetricDefinition{{Name: "a", Attributes: []AttributeCondition{{}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, Validate(tt.defs))
+			c, err := NewCounter(tt.defs, time.Now())
+			assert.Error(t, err)
+			assert.Nil(t, c)
+		})
+	}
+	assert.NoError(t, Validate([]MetricDefinition{{Name: "a"}, {Name: "b"}}))
+}
+
+func TestCounter(t *testing.T) {
+	start := time.Unix(100, 0)
+	c, err := NewCounter([]MetricDefinition{
+		{Name: "spans", Description: "All spans."},
+		{Name: "error_spans", ErrorsOnly: true},
+		{Name: "checkout_spans", Services: []string{"checkout"}, SpanNames: []string{"pay"}},
+		{
+			Name:            "get_requests",
+			Attributes:      []AttributeCondition{{Key: "http.method", Values: []string{"GET"}}, {Key: "http.route"}},
+			LabelAttributes: []string{"http.route"},
+		},
+		{Name: "unmatched", Services: []string{"unknown"}},
+	}, start)
+	require.NoError(t, err)
+
+	c.Count(node("frontend"), []*tracepb.Span{
+		span("GET /", 0, map[string]string{"http.method": "GET", "http.route": "/"}),
+		span("GET /cart", 2, map[string]string{"http.method": "GET", "http.route": "/cart"}),
+		span("POST /cart", 0, map[string]string{"http.method": "POST", "http.route": "/cart"}),
+		span("GET", 0, map[string]string{"http.method": "GET"}),
+		nil,
+	})
+	c.Count(node("checkout"), []*tracepb.Span{span("pay", 2, nil), span("refund", 0, nil)})
+	c.Count(node("frontend"), []*tracepb.Span{
+		span("GET /", 0, map[string]string{"http.method": "GET", "http.route": "/"}),
+	})
+
+	now := time.Unix(200, 0)
+	metrics := c.Metrics(now)
+	require.Len(t, metrics, 4)
+
+	assert.Equal(t, &metricspb.MetricDescriptor{
+		Name:        "spans",
+		Description: "All spans.",
+		Unit:        "1",
+		Type:        metricspb.MetricDescriptor_CUMULATIVE_INT64,
+		LabelKeys:   []*metricspb.LabelKey{{Key: ServiceLabel}},
+	}, metrics[0].MetricDescriptor)
+	assert.Equal(t, map[string]int64{"checkout": 2, "frontend": 5}, counts(metrics[0]))
+	for _, ts := range metrics[0].Timeseries {
+		assert.Equal(t, internal.TimeToTimestamp(start), ts.StartTimestamp)
+		assert.Equal(t, internal.TimeToTimestamp(now), ts.Points[0].Timestamp)
+	}
+
+	assert.Equal(t, "error_spans", metrics[1].MetricDescriptor.Name)
+	assert.Equal(t, map[string]int64{"checkout": 1, "frontend": 1}, counts(metrics[1]))
+
+	assert.Equal(t, "checkout_spans", metrics[2].MetricDescriptor.Name)
+	assert.Equal(t, map[string]int64{"checkout": 1}, counts(metrics[2]))
+
+	assert.Equal(t, "get_requests", metrics[3].MetricDescriptor.Name)
+	assert.Equal(t, []*metricspb.LabelKey{{Key: ServiceLabel}, {Key: "http.route"}}, metrics[3].MetricDescriptor.LabelKeys)
+	assert.Equal(t, map[string]int64{"frontend,/": 2, "frontend,/cart": 1}, counts(metrics[3]))
+}
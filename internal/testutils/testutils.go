@@ -45,3 +45,15 @@ func GetAvailableLocalAddress(t *testing.T) string {
 	defer ln.Close()
 	return ln.Addr().String()
 }
+
+// GetAvailableLocalIPv6Address finds an available port on the IPv6 loopback
+// interface and returns an endpoint describing it, e.g. "[::1]:37012". The
+// test is skipped if the host does not support IPv6.
+func GetAvailableLocalIPv6Address(t *testing.T) string {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 is not available on this host: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
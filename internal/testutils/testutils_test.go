@@ -14,4 +14,24 @@
 
 package testutils
 
-// TODO: Add tests
+import (
+	"net"
+	"testing"
+)
+
+func TestGetAvailableLocalIPv6Address(t *testing.T) {
+	endpoint := GetAvailableLocalIPv6Address(t)
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		t.Fatalf("invalid endpoint %q: %v", endpoint, err)
+	}
+	if host != "::1" {
+		t.Fatalf("got host %q, want \"::1\"", host)
+	}
+
+	ln, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		t.Fatalf("failed to listen on %q: %v", endpoint, err)
+	}
+	ln.Close()
+}
@@ -0,0 +1,88 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package thriftutil decodes the Thrift payloads received from the clients
+// defensively: the sizes of the containers are checked against the size of
+// the payload before the decoded structs preallocate them, so that a
+// malformed payload can't exhaust the memory.
+package thriftutil
+
+import (
+	"errors"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+var errInvalidContainerSize = thrift.NewTProtocolExceptionWithType(
+	thrift.INVALID_DATA, errors.New("container size larger than the remaining payload"))
+
+// NewBinaryProtocol returns the binary protocol reading the payload, it fails
+// on the containers holding more elements than the remaining bytes.
+func NewBinaryProtocol(b []byte) thrift.TProtocol {
+	buffer := thrift.NewTMemoryBuffer()
+	_, _ = buffer.Write(b)
+	return &boundedProtocol{
+		TProtocol: thrift.NewTBinaryProtocolTransport(buffer),
+		trans:     buffer,
+	}
+}
+
+// Deserialize decodes the binary payload into msg.
+func Deserialize(msg thrift.TStruct, b []byte) error {
+	return msg.Read(NewBinaryProtocol(b))
+}
+
+// boundedProtocol checks the sizes of the containers, each element is
+// encoded with at least one byte.
+type boundedProtocol struct {
+	thrift.TProtocol
+	trans thrift.TTransport
+}
+
+func (p *boundedProtocol) checkSize(size int) error {
+	if uint64(size) > p.trans.RemainingBytes() {
+		return errInvalidContainerSize
+	}
+	return nil
+}
+
+func (p *boundedProtocol) ReadListBegin() (thrift.TType, int, error) {
+	elemType, size, err := p.TProtocol.ReadListBegin()
+	if err == nil {
+		err = p.checkSize(size)
+	}
+	return elemType, size, err
+}
+
+func (p *boundedProtocol) ReadSetBegin() (thrift.TType, int, error) {
+	elemType, size, err := p.TProtocol.ReadSetBegin()
+	if err == nil {
+		err = p.checkSize(size)
+	}
+	return elemType, size, err
+}
+
+// Skip skips the fields through the bounded protocol, the protocol wrapped
+// would skip the containers without checking their sizes.
+func (p *boundedProtocol) Skip(fieldType thrift.TType) error {
+	return thrift.SkipDefaultDepth(p, fieldType)
+}
+
+func (p *boundedProtocol) ReadMapBegin() (thrift.TType, thrift.TType, int, error) {
+	keyType, valueType, size, err := p.TProtocol.ReadMapBegin()
+	if err == nil {
+		err = p.checkSize(size)
+	}
+	return keyType, valueType, size, err
+}
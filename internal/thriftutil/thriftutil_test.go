@@ -0,0 +1,77 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thriftutil
+
+import (
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeserialize(t *testing.T) {
+	want := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "api"},
+		Spans:   []*jaeger.Span{{TraceIdLow: 1, SpanId: 2, OperationName: "get"}},
+	}
+	b, err := thrift.NewTSerializer().Write(want)
+	require.NoError(t, err)
+
+	got := &jaeger.Batch{}
+	require.NoError(t, Deserialize(got, b))
+	assert.Equal(t, want, got)
+}
+
+func TestDeserialize_InvalidContainerSize(t *testing.T) {
+	b := []byte{
+		// Field 2, the list of spans.
+		byte(thrift.LIST), 0x00, 0x02,
+		// List of 2^31-1 structs.
+		byte(thrift.STRUCT), 0x7f, 0xff, 0xff, 0xff,
+		byte(thrift.STOP),
+	}
+	err := Deserialize(&jaeger.Batch{}, b)
+	assert.Error(t, err)
+}
+
+func TestNewBinaryProtocol_Containers(t *testing.T) {
+	buffer := thrift.NewTMemoryBuffer()
+	proto := thrift.NewTBinaryProtocolTransport(buffer)
+	require.NoError(t, proto.WriteSetBegin(thrift.I32, 1000))
+	require.NoError(t, proto.WriteMapBegin(thrift.STRING, thrift.STRING, 1000))
+	b := buffer.Bytes()
+
+	iprot := NewBinaryProtocol(b)
+	_, _, err := iprot.ReadSetBegin()
+	assert.Error(t, err)
+
+	iprot = NewBinaryProtocol(b[5:])
+	_, _, _, err = iprot.ReadMapBegin()
+	assert.Error(t, err)
+}
+
+func TestDeserialize_SkipInvalidContainerSize(t *testing.T) {
+	b := []byte{
+		// Field 9, unknown, a list.
+		byte(thrift.LIST), 0x00, 0x09,
+		// List of 2^31-1 elements without encoding.
+		byte(thrift.STOP), 0x7f, 0xff, 0xff, 0xff,
+		byte(thrift.STOP),
+	}
+	err := Deserialize(&jaeger.Batch{}, b)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tlsutil loads the TLS credentials of the listeners of the
+// receivers.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// Credentials are the files of the certificate and the key of a TLS server,
+// and optionally of the CA verifying the certificates of its clients.
+type Credentials struct {
+	// CertFile is the file path containing the TLS certificate.
+	CertFile string `mapstructure:"cert-file"`
+
+	// KeyFile is the file path containing the TLS key.
+	KeyFile string `mapstructure:"key-file"`
+
+	// ClientCAFile is the file path containing the PEM certificates of the
+	// CAs of the clients. When set the clients must present a certificate
+	// signed by one of them, i.e. mutual TLS.
+	ClientCAFile string `mapstructure:"client-ca-file"`
+}
+
+// ServerConfig loads the credentials into a TLS server configuration. It
+// returns nil if the credentials are nil, i.e. TLS is not enabled.
+func (c *Credentials) ServerConfig() (*tls.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("both cert-file and key-file must be set")
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if c.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in client-ca-file %q", c.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
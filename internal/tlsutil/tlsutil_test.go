@@ -0,0 +1,101 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSigned writes a self-signed certificate for localhost and its key
+// to dir.
+func writeSelfSigned(t *testing.T, dir string) *Credentials {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	creds := &Credentials{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	}
+	require.NoError(t, ioutil.WriteFile(creds.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(creds.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return creds
+}
+
+func TestServerConfig(t *testing.T) {
+	var creds *Credentials
+	cfg, err := creds.ServerConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	dir, err := ioutil.TempDir("", "tlsutil")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	creds = writeSelfSigned(t, dir)
+	cfg, err = creds.ServerConfig()
+	require.NoError(t, err)
+	assert.Len(t, cfg.Certificates, 1)
+}
+
+func TestServerConfig_ClientCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsutil")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	creds := writeSelfSigned(t, dir)
+	creds.ClientCAFile = creds.CertFile
+	cfg, err := creds.ServerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+	assert.NotNil(t, cfg.ClientCAs)
+
+	creds.ClientCAFile = creds.KeyFile
+	_, err = creds.ServerConfig()
+	assert.Error(t, err)
+}
+
+func TestServerConfig_Invalid(t *testing.T) {
+	_, err := (&Credentials{CertFile: "cert.pem"}).ServerConfig()
+	assert.EqualError(t, err, "both cert-file and key-file must be set")
+
+	_, err = (&Credentials{CertFile: "missing.pem", KeyFile: "missing.pem"}).ServerConfig()
+	assert.Error(t, err)
+}
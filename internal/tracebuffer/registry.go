@@ -0,0 +1,39 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracebuffer
+
+import "github.com/open-telemetry/opentelemetry-service/internal/registry"
+
+// buffers links the buffers served by the trace-buffer extensions with the
+// trace-buffer processors feeding them, since extensions are not part of the
+// data pipelines.
+var buffers = registry.New()
+
+// Register makes b the buffer served by the trace-buffer extension named name.
+func Register(name string, b *Buffer) {
+	buffers.Register(name, b)
+}
+
+// Unregister removes the buffer of the trace-buffer extension named name.
+func Unregister(name string) {
+	buffers.Unregister(name)
+}
+
+// Lookup returns the buffer of the trace-buffer extension named name or nil if
+// the extension is not started.
+func Lookup(name string) *Buffer {
+	b, _ := buffers.Lookup(name).(*Buffer)
+	return b
+}
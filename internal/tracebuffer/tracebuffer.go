@@ -0,0 +1,264 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracebuffer keeps the most recent traces passing through the
+// pipelines in memory so they can be looked up by trace ID.
+package tracebuffer
+
+import (
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// Span is a buffered span with the service that reported it.
+type Span struct {
+	TraceID      string            `json:"traceId"`
+	SpanID       string            `json:"spanId"`
+	ParentSpanID string            `json:"parentSpanId,omitempty"`
+	Service      string            `json:"service"`
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	StatusCode   int32             `json:"statusCode"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// Duration returns the duration of the span.
+func (s *Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Trace is a buffered trace.
+type Trace struct {
+	TraceID string `json:"traceId"`
+	Spans   []Span `json:"spans"`
+	// DroppedSpans is the number of spans not buffered because the trace
+	// reached the maximum number of spans.
+	DroppedSpans int `json:"droppedSpans,omitempty"`
+}
+
+// Summary describes a buffered trace without its spans.
+type Summary struct {
+	TraceID  string        `json:"traceId"`
+	Root     string        `json:"root"`
+	Services []string      `json:"services"`
+	Spans    int           `json:"spans"`
+	Errors   int           `json:"errors"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+type bufferedTrace struct {
+	spans   []Span
+	dropped int
+}
+
+// Buffer is a ring buffer of the last traces seen. When the buffer is full
+// the trace seen first is evicted.
+type Buffer struct {
+	maxTraces        int
+	maxSpansPerTrace int
+
+	// mu protects the fields below
+	mu     sync.RWMutex
+	traces map[string]*bufferedTrace
+	// ring holds the trace IDs in the order they were first seen.
+	ring []string
+	next int
+}
+
+// NewBuffer creates a Buffer holding up to maxTraces traces of up to
+// maxSpansPerTrace spans each.
+func NewBuffer(maxTraces, maxSpansPerTrace int) *Buffer {
+	if maxTraces <= 0 {
+		maxTraces = 1
+	}
+	if maxSpansPerTrace <= 0 {
+		maxSpansPerTrace = 1
+	}
+	return &Buffer{
+		maxTraces:        maxTraces,
+		maxSpansPerTrace: maxSpansPerTrace,
+		traces:           make(map[string]*bufferedTrace),
+		ring:             make([]string, 0, maxTraces),
+	}
+}
+
+// Add buffers the spans reported by node.
+func (b *Buffer) Add(node *commonpb.Node, spans []*tracepb.Span) {
+	service := node.GetServiceInfo().GetName()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, span := range spans {
+		if span == nil || len(span.TraceId) == 0 {
+			continue
+		}
+		traceID := hex.EncodeToString(span.TraceId)
+		trace := b.traces[traceID]
+		if trace == nil {
+			trace = &bufferedTrace{}
+			b.traces[traceID] = trace
+			b.remember(traceID)
+		}
+		if len(trace.spans) >= b.maxSpansPerTrace {
+			trace.dropped++
+			continue
+		}
+		trace.spans = append(trace.spans, toSpan(traceID, service, span))
+	}
+}
+
+// remember adds the trace ID to the ring, evicting the oldest trace when full.
+func (b *Buffer) remember(traceID string) {
+	if len(b.ring) < b.maxTraces {
+		b.ring = append(b.ring, traceID)
+		return
+	}
+	delete(b.traces, b.ring[b.next])
+	b.ring[b.next] = traceID
+	b.next = (b.next + 1) % b.maxTraces
+}
+
+// Get returns the trace with the given hex encoded ID, spans are sorted by
+// start time.
+func (b *Buffer) Get(traceID string) (Trace, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	trace, ok := b.traces[traceID]
+	if !ok {
+		return Trace{}, false
+	}
+	spans := make([]Span, len(trace.spans))
+	copy(spans, trace.spans)
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	return Trace{TraceID: traceID, Spans: spans, DroppedSpans: trace.dropped}, true
+}
+
+// List returns the summaries of up to limit buffered traces, most recent
+// first. If service is not empty only the traces with spans of that service
+// are returned. A limit smaller or equal to zero returns all the traces.
+func (b *Buffer) List(service string, limit int) []Summary {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	summaries := make([]Summary, 0)
+	for i := 0; i < len(b.ring); i++ {
+		// Walk the ring from the most recent trace backwards.
+		idx := (b.next - 1 - i + 2*len(b.ring)) % len(b.ring)
+		traceID := b.ring[idx]
+		summary := summarize(traceID, b.traces[traceID].spans)
+		if service != "" && !contains(summary.Services, service) {
+			continue
+		}
+		summaries = append(summaries, summary)
+		if limit > 0 && len(summaries) >= limit {
+			break
+		}
+	}
+	return summaries
+}
+
+func summarize(traceID string, spans []Span) Summary {
+	summary := Summary{TraceID: traceID, Spans: len(spans)}
+	services := make(map[string]bool)
+	var end time.Time
+	for i := range spans {
+		span := &spans[i]
+		services[span.Service] = true
+		if span.StatusCode != 0 {
+			summary.Errors++
+		}
+		if span.ParentSpanID == "" && summary.Root == "" {
+			summary.Root = span.Name
+		}
+		if summary.Start.IsZero() || span.Start.Before(summary.Start) {
+			summary.Start = span.Start
+		}
+		if span.End.After(end) {
+			end = span.End
+		}
+	}
+	if !end.IsZero() {
+		summary.Duration = end.Sub(summary.Start)
+	}
+	for service := range services {
+		summary.Services = append(summary.Services, service)
+	}
+	sort.Strings(summary.Services)
+	return summary
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toSpan(traceID, service string, span *tracepb.Span) Span {
+	s := Span{
+		TraceID:    traceID,
+		SpanID:     hex.EncodeToString(span.SpanId),
+		Service:    service,
+		Name:       span.GetName().GetValue(),
+		Kind:       span.Kind.String(),
+		StatusCode: span.GetStatus().GetCode(),
+	}
+	if len(span.ParentSpanId) > 0 {
+		s.ParentSpanID = hex.EncodeToString(span.ParentSpanId)
+	}
+	if span.StartTime != nil {
+		s.Start, _ = ptypes.Timestamp(span.StartTime)
+	}
+	if span.EndTime != nil {
+		s.End, _ = ptypes.Timestamp(span.EndTime)
+	}
+	if attrs := span.GetAttributes().GetAttributeMap(); len(attrs) > 0 {
+		s.Attributes = make(map[string]string, len(attrs))
+		for k, v := range attrs {
+			s.Attributes[k] = attributeValueString(v)
+		}
+	}
+	return s
+}
+
+func attributeValueString(v *tracepb.AttributeValue) string {
+	switch value := v.GetValue().(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return value.StringValue.GetValue()
+	case *tracepb.AttributeValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *tracepb.AttributeValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *tracepb.AttributeValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
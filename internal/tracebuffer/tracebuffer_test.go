@@ -0,0 +1,157 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracebuffer
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func node(service string) *commonpb.Node {
+	return &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: service}}
+}
+
+func traceID(id byte) []byte {
+	return []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, id}
+}
+
+func span(trace, id, parent byte, name string, start, end int64) *tracepb.Span {
+	s := &tracepb.Span{
+		TraceId:   traceID(trace),
+		SpanId:    []byte{0, 0, 0, 0, 0, 0, 0, id},
+		Name:      &tracepb.TruncatableString{Value: name},
+		StartTime: &timestamp.Timestamp{Seconds: start},
+		EndTime:   &timestamp.Timestamp{Seconds: end},
+	}
+	if parent != 0 {
+		s.ParentSpanId = []byte{0, 0, 0, 0, 0, 0, 0, parent}
+	}
+	return s
+}
+
+func TestBuffer_Get(t *testing.T) {
+	b := NewBuffer(10, 10)
+
+	child := span(1, 2, 1, "db", 11, 12)
+	child.Status = &tracepb.Status{Code: 2}
+	child.Attributes = &tracepb.Span_Attributes{
+		AttributeMap: map[string]*tracepb.AttributeValue{
+			"db.statement": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "SELECT 1"}}},
+			"db.rows":      {Value: &tracepb.AttributeValue_IntValue{IntValue: 1}},
+		},
+	}
+	// The child span is reported before its parent.
+	b.Add(node("backend"), []*tracepb.Span{child})
+	b.Add(node("frontend"), []*tracepb.Span{span(1, 1, 0, "GET /", 10, 15), nil, {}})
+
+	trace, ok := b.Get("00000000000000000000000000000001")
+	require.True(t, ok)
+	require.Len(t, trace.Spans, 2)
+	assert.Equal(t, Span{
+		TraceID: "00000000000000000000000000000001",
+		SpanID:  "0000000000000001",
+		Service: "frontend",
+		Name:    "GET /",
+		Kind:    "SPAN_KIND_UNSPECIFIED",
+		Start:   time.Unix(10, 0).UTC(),
+		End:     time.Unix(15, 0).UTC(),
+	}, trace.Spans[0])
+	assert.Equal(t, "0000000000000001", trace.Spans[1].ParentSpanID)
+	assert.Equal(t, "backend", trace.Spans[1].Service)
+	assert.Equal(t, int32(2), trace.Spans[1].StatusCode)
+	assert.Equal(t, map[string]string{"db.statement": "SELECT 1", "db.rows": "1"}, trace.Spans[1].Attributes)
+	assert.Equal(t, time.Second, trace.Spans[1].Duration())
+
+	_, ok = b.Get("00000000000000000000000000000002")
+	assert.False(t, ok)
+}
+
+func TestBuffer_List(t *testing.T) {
+	b := NewBuffer(10, 10)
+	b.Add(node("frontend"), []*tracepb.Span{span(1, 1, 0, "GET /", 10, 15)})
+	b.Add(node("backend"), []*tracepb.Span{span(1, 2, 1, "db", 11, 12), span(2, 3, 0, "cron", 20, 21)})
+
+	summaries := b.List("", 0)
+	require.Len(t, summaries, 2)
+	// Most recent first.
+	assert.Equal(t, Summary{
+		TraceID:  "00000000000000000000000000000002",
+		Root:     "cron",
+		Services: []string{"backend"},
+		Spans:    1,
+		Start:    time.Unix(20, 0).UTC(),
+		Duration: time.Second,
+	}, summaries[0])
+	assert.Equal(t, Summary{
+		TraceID:  "00000000000000000000000000000001",
+		Root:     "GET /",
+		Services: []string{"backend", "frontend"},
+		Spans:    2,
+		Start:    time.Unix(10, 0).UTC(),
+		Duration: 5 * time.Second,
+	}, summaries[1])
+
+	assert.Len(t, b.List("", 1), 1)
+	summaries = b.List("frontend", 0)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "00000000000000000000000000000001", summaries[0].TraceID)
+	assert.Empty(t, b.List("unknown", 0))
+}
+
+func TestBuffer_Eviction(t *testing.T) {
+	b := NewBuffer(2, 10)
+	for i := byte(1); i <= 3; i++ {
+		b.Add(node("frontend"), []*tracepb.Span{span(i, 1, 0, "op", 10, 11)})
+	}
+
+	_, ok := b.Get("00000000000000000000000000000001")
+	assert.False(t, ok)
+	summaries := b.List("", 0)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "00000000000000000000000000000003", summaries[0].TraceID)
+	assert.Equal(t, "00000000000000000000000000000002", summaries[1].TraceID)
+
+	// Spans of a buffered trace do not evict other traces.
+	b.Add(node("frontend"), []*tracepb.Span{span(2, 2, 1, "child", 10, 11)})
+	assert.Len(t, b.List("", 0), 2)
+}
+
+func TestBuffer_MaxSpansPerTrace(t *testing.T) {
+	b := NewBuffer(10, 2)
+	b.Add(node("frontend"), []*tracepb.Span{
+		span(1, 1, 0, "root", 10, 15),
+		span(1, 2, 1, "child", 11, 12),
+		span(1, 3, 1, "child", 12, 13),
+	})
+
+	trace, ok := b.Get("00000000000000000000000000000001")
+	require.True(t, ok)
+	assert.Len(t, trace.Spans, 2)
+	assert.Equal(t, 1, trace.DroppedSpans)
+}
+
+func TestRegistry(t *testing.T) {
+	b := NewBuffer(1, 1)
+	Register("trace-buffer/test", b)
+	assert.True(t, Lookup("trace-buffer/test") == b)
+	Unregister("trace-buffer/test")
+	assert.Nil(t, Lookup("trace-buffer/test"))
+}
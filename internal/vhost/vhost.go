@@ -0,0 +1,279 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vhost serves several virtual hosts, e.g. one per tenant, on the
+// listener of a receiver: each request is routed by the server name sent by
+// the client, in the TLS SNI extension or else in the Host header or the
+// :authority pseudo-header, to the settings of its virtual host.
+package vhost
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+)
+
+const (
+	// authorityKey is the metadata holding the :authority pseudo-header of
+	// the gRPC calls.
+	authorityKey = ":authority"
+	// forwardedHostKey is the metadata set by the grpc-gateway with the Host
+	// header of the HTTP requests.
+	forwardedHostKey = "x-forwarded-host"
+)
+
+// Settings are the settings of a virtual host.
+type Settings struct {
+	// Hosts are the server names served by the virtual host.
+	Hosts []string `mapstructure:"hosts"`
+
+	// Auth is the name of the extension validating the bearer tokens of the
+	// requests, it replaces the one of the receiver. None are authenticated
+	// if it is empty.
+	Auth string `mapstructure:"auth"`
+
+	// Attributes are added to the node of the received batches.
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+// Host is a virtual host. A nil Host leaves the nodes unchanged.
+type Host struct {
+	name          string
+	authenticator *auth.Authenticator
+	attributes    map[string]string
+}
+
+// Name returns the name of the virtual host.
+func (h *Host) Name() string {
+	if h == nil {
+		return ""
+	}
+	return h.name
+}
+
+// Node returns a copy of the node with the attributes of the virtual host
+// added, the node itself is not modified since it may be shared between
+// batches.
+func (h *Host) Node(node *commonpb.Node) *commonpb.Node {
+	if h == nil || len(h.attributes) == 0 {
+		return node
+	}
+
+	annotated := &commonpb.Node{}
+	if node != nil {
+		annotated.Identifier = node.Identifier
+		annotated.LibraryInfo = node.LibraryInfo
+		annotated.ServiceInfo = node.ServiceInfo
+	}
+	annotated.Attributes = make(map[string]string, len(node.GetAttributes())+len(h.attributes))
+	for k, v := range node.GetAttributes() {
+		annotated.Attributes[k] = v
+	}
+	for k, v := range h.attributes {
+		annotated.Attributes[k] = v
+	}
+	return annotated
+}
+
+type hostKey struct{}
+
+// NewContext returns a copy of the context holding the virtual host.
+func NewContext(ctx context.Context, h *Host) context.Context {
+	return context.WithValue(ctx, hostKey{}, h)
+}
+
+// FromContext returns the virtual host of the request, nil if it wasn't
+// routed to one.
+func FromContext(ctx context.Context) *Host {
+	h, _ := ctx.Value(hostKey{}).(*Host)
+	return h
+}
+
+// Router routes the requests of a receiver to its virtual hosts. The requests
+// for an unknown server name are served with the settings of the receiver. A
+// nil Router, returned when no virtual hosts are configured, isn't used.
+type Router struct {
+	hosts map[string]*Host
+	// fallback authenticates the requests routed to no virtual host.
+	fallback *auth.Authenticator
+}
+
+// New creates the Router of the receiver for the given virtual hosts, the
+// requests routed to none are authenticated with the defaultAuth validator.
+// It returns nil if there are no virtual hosts.
+func New(logger *zap.Logger, vhosts map[string]Settings, defaultAuth, receiverName string) (*Router, error) {
+	if len(vhosts) == 0 {
+		return nil, nil
+	}
+	r := &Router{
+		hosts:    make(map[string]*Host),
+		fallback: auth.NewAuthenticator(logger, defaultAuth, receiverName),
+	}
+	for name, settings := range vhosts {
+		if len(settings.Hosts) == 0 {
+			return nil, fmt.Errorf("virtual host %q has no hosts", name)
+		}
+		h := &Host{
+			name:          name,
+			authenticator: auth.NewAuthenticator(logger, settings.Auth, receiverName),
+			attributes:    settings.Attributes,
+		}
+		for _, host := range settings.Hosts {
+			host = normalize(host)
+			if other, ok := r.hosts[host]; ok {
+				return nil, fmt.Errorf("host %q of virtual host %q is already served by %q", host, name, other.name)
+			}
+			r.hosts[host] = h
+		}
+	}
+	return r, nil
+}
+
+// Route returns the virtual host serving the server name, nil if there is
+// none.
+func (r *Router) Route(serverName string) *Host {
+	return r.hosts[normalize(serverName)]
+}
+
+// authenticator returns the authenticator of the requests routed to the
+// virtual host, nil if they are not authenticated.
+func (r *Router) authenticator(h *Host) *auth.Authenticator {
+	if h == nil {
+		return r.fallback
+	}
+	return h.authenticator
+}
+
+// UnaryServerInterceptor returns the gRPC interceptor routing the unary calls
+// and authenticating them with the settings of their virtual host. It
+// replaces the authenticator of the receiver.
+func (r *Router) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		h := r.Route(serverNameFromGRPC(ctx))
+		ctx = NewContext(ctx, h)
+		if a := r.authenticator(h); a != nil {
+			return a.UnaryServerInterceptor()(ctx, req, info, handler)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor routing the streams
+// and authenticating them with the settings of their virtual host. It
+// replaces the authenticator of the receiver.
+func (r *Router) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		h := r.Route(serverNameFromGRPC(ss.Context()))
+		ss = &routedStream{ServerStream: ss, ctx: NewContext(ss.Context(), h)}
+		if a := r.authenticator(h); a != nil {
+			return a.StreamServerInterceptor()(srv, ss, info, handler)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// routedStream is a stream with the context holding its virtual host.
+type routedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (rs *routedStream) Context() context.Context {
+	return rs.ctx
+}
+
+// HTTPHandler wraps the handler to route the HTTP requests and authenticate
+// them with the settings of their virtual host. It replaces the authenticator
+// of the receiver.
+func (r *Router) HTTPHandler(next http.Handler) http.Handler {
+	handlers := make(map[*Host]http.Handler)
+	for _, h := range r.hosts {
+		handlers[h] = next
+		if h.authenticator != nil {
+			handlers[h] = h.authenticator.HTTPHandler(next)
+		}
+	}
+	fallback := next
+	if r.fallback != nil {
+		fallback = r.fallback.HTTPHandler(next)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h := r.Route(serverNameFromHTTP(req))
+		if h == nil {
+			fallback.ServeHTTP(w, req)
+			return
+		}
+		handlers[h].ServeHTTP(w, req.WithContext(NewContext(req.Context(), h)))
+	})
+}
+
+// serverNameFromGRPC returns the server name of a gRPC call: the TLS SNI if
+// any, else the Host header forwarded by a local grpc-gateway, else the
+// :authority pseudo-header.
+func serverNameFromGRPC(ctx context.Context) string {
+	var local bool
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && tlsInfo.State.ServerName != "" {
+			return tlsInfo.State.ServerName
+		}
+		if p.Addr != nil {
+			ip := net.ParseIP(hostOf(p.Addr.String()))
+			local = ip != nil && ip.IsLoopback()
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if local {
+		if fwd := md.Get(forwardedHostKey); len(fwd) > 0 {
+			return fwd[len(fwd)-1]
+		}
+	}
+	if authority := md.Get(authorityKey); len(authority) > 0 {
+		return authority[0]
+	}
+	return ""
+}
+
+// serverNameFromHTTP returns the server name of an HTTP request: the TLS SNI
+// if any, else the Host header.
+func serverNameFromHTTP(r *http.Request) string {
+	if r.TLS != nil && r.TLS.ServerName != "" {
+		return r.TLS.ServerName
+	}
+	return r.Host
+}
+
+// normalize returns the lower-case host name without its port.
+func normalize(host string) string {
+	return strings.ToLower(strings.TrimSuffix(hostOf(host), "."))
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,221 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vhost
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+)
+
+const receiverName = "fake_receiver"
+
+// newTestRouter creates a router with the "vhost-test" validator registered,
+// it must be unregistered at the end of the test.
+func newTestRouter(t *testing.T) *Router {
+	auth.Register("vhost-test", auth.ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, auth.ErrInvalidToken
+		}
+		return ctx, nil
+	}))
+
+	r, err := New(zap.NewNop(), map[string]Settings{
+		"tenant-a": {
+			Hosts:      []string{"a.example.com", "A.example.org:443"},
+			Attributes: map[string]string{"tenant": "a"},
+		},
+		"tenant-b": {
+			Hosts:      []string{"b.example.com"},
+			Auth:       "vhost-test",
+			Attributes: map[string]string{"tenant": "b"},
+		},
+	}, "vhost-test", receiverName)
+	require.NoError(t, err)
+	return r
+}
+
+func TestNew(t *testing.T) {
+	r, err := New(zap.NewNop(), nil, "", receiverName)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	_, err = New(zap.NewNop(), map[string]Settings{"a": {}}, "", receiverName)
+	assert.EqualError(t, err, `virtual host "a" has no hosts`)
+
+	_, err = New(zap.NewNop(), map[string]Settings{
+		"a": {Hosts: []string{"example.com"}},
+		"b": {Hosts: []string{"Example.com:4317"}},
+	}, "", receiverName)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `host "example.com" of virtual host`)
+}
+
+func TestRoute(t *testing.T) {
+	defer auth.Unregister("vhost-test")
+	r := newTestRouter(t)
+	assert.Equal(t, "tenant-a", r.Route("a.example.com").Name())
+	assert.Equal(t, "tenant-a", r.Route("A.Example.com:4317").Name())
+	assert.Equal(t, "tenant-a", r.Route("a.example.org.").Name())
+	assert.Equal(t, "tenant-b", r.Route("b.example.com").Name())
+	assert.Nil(t, r.Route("c.example.com"))
+	assert.Nil(t, r.Route(""))
+}
+
+func TestHostNode(t *testing.T) {
+	var h *Host
+	node := &commonpb.Node{Attributes: map[string]string{"k": "v"}}
+	assert.Equal(t, node, h.Node(node))
+
+	h = &Host{name: "tenant-a", attributes: map[string]string{"tenant": "a"}}
+	annotated := h.Node(node)
+	assert.Equal(t, map[string]string{"k": "v", "tenant": "a"}, annotated.Attributes)
+	assert.Equal(t, map[string]string{"k": "v"}, node.Attributes, "the node must not be modified")
+	assert.Equal(t, map[string]string{"tenant": "a"}, h.Node(nil).Attributes)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	defer auth.Unregister("vhost-test")
+	var routed *Host
+	handler := newTestRouter(t).HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed = FromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		host          string
+		sni           string
+		authorization string
+		status        int
+		vhost         string
+	}{
+		{name: "host header", host: "a.example.com:9411", status: http.StatusOK, vhost: "tenant-a"},
+		{name: "sni", host: "c.example.com", sni: "a.example.com", status: http.StatusOK, vhost: "tenant-a"},
+		{name: "vhost auth", host: "b.example.com", status: http.StatusUnauthorized},
+		{name: "vhost authenticated", host: "b.example.com", authorization: "Bearer secret", status: http.StatusOK, vhost: "tenant-b"},
+		{name: "fallback auth", host: "c.example.com", status: http.StatusUnauthorized},
+		{name: "fallback authenticated", host: "c.example.com", authorization: "Bearer secret", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routed = nil
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+			req.Host = tt.host
+			if tt.sni != "" {
+				req.TLS = &tls.ConnectionState{ServerName: tt.sni}
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.vhost, routed.Name())
+		})
+	}
+}
+
+func TestServerNameFromGRPC(t *testing.T) {
+	remote := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}}
+	local := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}}
+	secure := &peer.Peer{
+		Addr:     remote.Addr,
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{ServerName: "a.example.com"}},
+	}
+	md := metadata.Pairs(authorityKey, "b.example.com", forwardedHostKey, "c.example.com")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "sni", ctx: metadata.NewIncomingContext(peer.NewContext(context.Background(), secure), md), want: "a.example.com"},
+		{name: "authority", ctx: metadata.NewIncomingContext(peer.NewContext(context.Background(), remote), md), want: "b.example.com"},
+		{name: "gateway", ctx: metadata.NewIncomingContext(peer.NewContext(context.Background(), local), md), want: "c.example.com"},
+		{name: "none", ctx: context.Background(), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, serverNameFromGRPC(tt.ctx))
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	defer auth.Unregister("vhost-test")
+	interceptor := newTestRouter(t).UnaryServerInterceptor()
+	var routed *Host
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		routed = FromContext(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorityKey, "a.example.com"))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", routed.Name())
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorityKey, "b.example.com"))
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorityKey, "b.example.com", "authorization", "Bearer secret"))
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-b", routed.Name())
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (fss *fakeServerStream) Context() context.Context {
+	return fss.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	defer auth.Unregister("vhost-test")
+	interceptor := newTestRouter(t).StreamServerInterceptor()
+	var routed *Host
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		routed = FromContext(ss.Context())
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorityKey, "b.example.com", "authorization", "Bearer secret"))
+	require.NoError(t, interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler))
+	assert.Equal(t, "tenant-b", routed.Name())
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorityKey, "c.example.com"))
+	err := interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
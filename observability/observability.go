@@ -28,13 +28,21 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"go.opencensus.io/trace"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
 )
 
 var (
-	mReceiverReceivedSpans      = stats.Int64("otelsvc/receiver/received_spans", "Counts the number of spans received by the receiver", "1")
-	mReceiverDroppedSpans       = stats.Int64("otelsvc/receiver/dropped_spans", "Counts the number of spans dropped by the receiver", "1")
-	mReceiverReceivedTimeSeries = stats.Int64("otelsvc/receiver/received_timeseries", "Counts the number of timeseries received by the receiver", "1")
-	mReceiverDroppedTimeSeries  = stats.Int64("otelsvc/receiver/dropped_timeseries", "Counts the number of timeseries dropped by the receiver", "1")
+	mReceiverReceivedSpans         = stats.Int64("otelsvc/receiver/received_spans", "Counts the number of spans received by the receiver", "1")
+	mReceiverDroppedSpans          = stats.Int64("otelsvc/receiver/dropped_spans", "Counts the number of spans dropped by the receiver", "1")
+	mReceiverReceivedTimeSeries    = stats.Int64("otelsvc/receiver/received_timeseries", "Counts the number of timeseries received by the receiver", "1")
+	mReceiverDroppedTimeSeries     = stats.Int64("otelsvc/receiver/dropped_timeseries", "Counts the number of timeseries dropped by the receiver", "1")
+	mReceiverScrapes               = stats.Int64("otelsvc/receiver/scrapes", "Counts the number of scrapes made by the receiver", "1")
+	mReceiverFailedScrapes         = stats.Int64("otelsvc/receiver/failed_scrapes", "Counts the number of scrapes of the receiver that failed", "1")
+	mReceiverAuthRequests          = stats.Int64("otelsvc/receiver/auth_requests", "Counts the number of requests authenticated by the receiver", "1")
+	mReceiverThrottledRequests     = stats.Int64("otelsvc/receiver/throttled_requests", "Counts the number of requests rejected by the receiver because too many were in flight", "1")
+	mReceiverDeniedConnections     = stats.Int64("otelsvc/receiver/denied_connections", "Counts the number of connections closed by the receiver because the client is not in the allowed networks", "1")
+	mReceiverDecompressionFailures = stats.Int64("otelsvc/receiver/decompression_failures", "Counts the number of requests rejected by the receiver because their body failed to decompress", "1")
 
 	mExporterReceivedSpans      = stats.Int64("otelsvc/exporter/received_spans", "Counts the number of spans received by the exporter", "1")
 	mExporterDroppedSpans       = stats.Int64("otelsvc/exporter/dropped_spans", "Counts the number of spans received by the exporter", "1")
@@ -45,6 +53,14 @@ var (
 // TagKeyReceiver defines tag key for Receiver.
 var TagKeyReceiver, _ = tag.NewKey("otelsvc_receiver")
 
+// TagKeyAuthOutcome defines tag key for the outcome of the authentication of
+// a request.
+var TagKeyAuthOutcome, _ = tag.NewKey("otelsvc_auth_outcome")
+
+// TagKeyThrottleLimit defines tag key for the in-flight limit, "receiver" or
+// "global", exceeded by a throttled request.
+var TagKeyThrottleLimit, _ = tag.NewKey("otelsvc_throttle_limit")
+
 // TagKeyExporter defines tag key for Exporter.
 var TagKeyExporter, _ = tag.NewKey("otelsvc_exporter")
 
@@ -84,6 +100,60 @@ var ViewReceiverDroppedTimeSeries = &view.View{
 	TagKeys:     []tag.Key{TagKeyReceiver},
 }
 
+// ViewReceiverScrapes defines the view for the receiver scrapes metric.
+var ViewReceiverScrapes = &view.View{
+	Name:        mReceiverScrapes.Name(),
+	Description: mReceiverScrapes.Description(),
+	Measure:     mReceiverScrapes,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyReceiver},
+}
+
+// ViewReceiverFailedScrapes defines the view for the receiver failed scrapes metric.
+var ViewReceiverFailedScrapes = &view.View{
+	Name:        mReceiverFailedScrapes.Name(),
+	Description: mReceiverFailedScrapes.Description(),
+	Measure:     mReceiverFailedScrapes,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyReceiver},
+}
+
+// ViewReceiverAuthRequests defines the view for the receiver authenticated requests metric.
+var ViewReceiverAuthRequests = &view.View{
+	Name:        mReceiverAuthRequests.Name(),
+	Description: mReceiverAuthRequests.Description(),
+	Measure:     mReceiverAuthRequests,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyReceiver, TagKeyAuthOutcome},
+}
+
+// ViewReceiverThrottledRequests defines the view for the receiver throttled requests metric.
+var ViewReceiverThrottledRequests = &view.View{
+	Name:        mReceiverThrottledRequests.Name(),
+	Description: mReceiverThrottledRequests.Description(),
+	Measure:     mReceiverThrottledRequests,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyReceiver, TagKeyThrottleLimit},
+}
+
+// ViewReceiverDeniedConnections defines the view for the receiver denied connections metric.
+var ViewReceiverDeniedConnections = &view.View{
+	Name:        mReceiverDeniedConnections.Name(),
+	Description: mReceiverDeniedConnections.Description(),
+	Measure:     mReceiverDeniedConnections,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyReceiver},
+}
+
+// ViewReceiverDecompressionFailures defines the view for the receiver decompression failures metric.
+var ViewReceiverDecompressionFailures = &view.View{
+	Name:        mReceiverDecompressionFailures.Name(),
+	Description: mReceiverDecompressionFailures.Description(),
+	Measure:     mReceiverDecompressionFailures,
+	Aggregation: view.Sum(),
+	TagKeys:     []tag.Key{TagKeyReceiver},
+}
+
 // ViewExporterReceivedSpans defines the view for the exporter received spans metric.
 var ViewExporterReceivedSpans = &view.View{
 	Name:        mExporterReceivedSpans.Name(),
@@ -126,12 +196,51 @@ var AllViews = []*view.View{
 	ViewReceiverDroppedSpans,
 	ViewReceiverReceivedTimeSeries,
 	ViewReceiverDroppedTimeSeries,
+	ViewReceiverScrapes,
+	ViewReceiverFailedScrapes,
+	ViewReceiverAuthRequests,
+	ViewReceiverThrottledRequests,
+	ViewReceiverDeniedConnections,
+	ViewReceiverDecompressionFailures,
 	ViewExporterReceivedSpans,
 	ViewExporterDroppedSpans,
 	ViewExporterReceivedTimeSeries,
 	ViewExporterDroppedTimeSeries,
 }
 
+// Views returns the views for the metrics provided by the agent according to
+// the given telemetry level. At the minimal level exporter metrics are only
+// tagged with the exporter name, basic and higher levels also tag them with the
+// receiver name, which multiplies the number of series by the number of receivers.
+func Views(level telemetry.Level) []*view.View {
+	switch level {
+	case telemetry.None:
+		return nil
+	case telemetry.Minimal:
+		views := make([]*view.View, 0, len(AllViews))
+		for _, v := range AllViews {
+			if containsTagKey(v.TagKeys, TagKeyExporter) {
+				vCopy := *v
+				vCopy.TagKeys = []tag.Key{TagKeyExporter}
+				v = &vCopy
+			}
+			views = append(views, v)
+		}
+		return views
+	default:
+		return AllViews
+	}
+}
+
+func containsTagKey(keys []tag.Key, key tag.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
 // ContextWithReceiverName adds the tag "otelsvc_receiver" and the name of the receiver as the value,
 // and returns the newly created context. For receivers that can receive multiple signals it is
 // recommended to encode the signal as suffix (e.g. "oc_trace" and "oc_metrics").
@@ -152,6 +261,48 @@ func RecordMetricsForMetricsReceiver(ctxWithTraceReceiverName context.Context, r
 	stats.Record(ctxWithTraceReceiverName, mReceiverReceivedTimeSeries.M(int64(receivedTimeSeries)), mReceiverDroppedTimeSeries.M(int64(droppedTimeSeries)))
 }
 
+// RecordMetricsForScraper records a scrape of the receiver and whether it failed.
+// Use it with a context.Context generated using ContextWithReceiverName().
+func RecordMetricsForScraper(ctxWithReceiverName context.Context, failed bool) {
+	var failedScrapes int64
+	if failed {
+		failedScrapes = 1
+	}
+	stats.Record(ctxWithReceiverName, mReceiverScrapes.M(1), mReceiverFailedScrapes.M(failedScrapes))
+}
+
+// RecordAuthOutcome records the authentication of a request by the receiver
+// with its outcome, e.g. "success" or "invalid_token".
+// Use it with a context.Context generated using ContextWithReceiverName().
+func RecordAuthOutcome(ctxWithReceiverName context.Context, outcome string) {
+	_ = stats.RecordWithTags(ctxWithReceiverName,
+		[]tag.Mutator{tag.Upsert(TagKeyAuthOutcome, outcome)},
+		mReceiverAuthRequests.M(1))
+}
+
+// RecordThrottledRequest records a request rejected by the receiver with the
+// in-flight limit it exceeded, "receiver" or "global".
+// Use it with a context.Context generated using ContextWithReceiverName().
+func RecordThrottledRequest(ctxWithReceiverName context.Context, limit string) {
+	_ = stats.RecordWithTags(ctxWithReceiverName,
+		[]tag.Mutator{tag.Upsert(TagKeyThrottleLimit, limit)},
+		mReceiverThrottledRequests.M(1))
+}
+
+// RecordDeniedConnection records a connection closed by the receiver because
+// the client is not in the allowed networks.
+// Use it with a context.Context generated using ContextWithReceiverName().
+func RecordDeniedConnection(ctxWithReceiverName context.Context) {
+	stats.Record(ctxWithReceiverName, mReceiverDeniedConnections.M(1))
+}
+
+// RecordDecompressionFailure records a request rejected by the receiver
+// because its body failed to decompress.
+// Use it with a context.Context generated using ContextWithReceiverName().
+func RecordDecompressionFailure(ctxWithReceiverName context.Context) {
+	stats.Record(ctxWithReceiverName, mReceiverDecompressionFailures.M(1))
+}
+
 // ContextWithExporterName adds the tag "otelsvc_exporter" and the name of the exporter as the value,
 // and returns the newly created context. For exporters that can export multiple signals it is
 // recommended to encode the signal as suffix (e.g. "oc_trace" and "oc_metrics").
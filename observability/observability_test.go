@@ -20,8 +20,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -73,3 +75,94 @@ func TestMEtricsPieplineRecordedMetrics(t *testing.T) {
 	err = observabilitytest.CheckValueViewExporterDroppedTimeSeries(receiverName, exporterName, 23)
 	require.Nil(t, err, "When check exporter dropped timeseries")
 }
+
+func TestScraperRecordedMetrics(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	receiverCtx := observability.ContextWithReceiverName(context.Background(), receiverName)
+	observability.RecordMetricsForScraper(receiverCtx, false)
+	observability.RecordMetricsForScraper(receiverCtx, true)
+	observability.RecordMetricsForScraper(receiverCtx, false)
+
+	err := observabilitytest.CheckValueViewReceiverScrapes(receiverName, 3)
+	require.Nil(t, err, "When check receiver scrapes")
+
+	err = observabilitytest.CheckValueViewReceiverFailedScrapes(receiverName, 1)
+	require.Nil(t, err, "When check receiver failed scrapes")
+}
+
+func TestAuthRecordedMetrics(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	receiverCtx := observability.ContextWithReceiverName(context.Background(), receiverName)
+	observability.RecordAuthOutcome(receiverCtx, "success")
+	observability.RecordAuthOutcome(receiverCtx, "invalid_token")
+	observability.RecordAuthOutcome(receiverCtx, "success")
+
+	err := observabilitytest.CheckValueViewReceiverAuthRequests(receiverName, "success", 2)
+	require.Nil(t, err, "When check receiver successful auth requests")
+
+	err = observabilitytest.CheckValueViewReceiverAuthRequests(receiverName, "invalid_token", 1)
+	require.Nil(t, err, "When check receiver failed auth requests")
+}
+
+func TestThrottledRecordedMetrics(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	receiverCtx := observability.ContextWithReceiverName(context.Background(), receiverName)
+	observability.RecordThrottledRequest(receiverCtx, "receiver")
+	observability.RecordThrottledRequest(receiverCtx, "global")
+	observability.RecordThrottledRequest(receiverCtx, "receiver")
+
+	err := observabilitytest.CheckValueViewReceiverThrottledRequests(receiverName, "receiver", 2)
+	require.Nil(t, err, "When check receiver throttled requests")
+
+	err = observabilitytest.CheckValueViewReceiverThrottledRequests(receiverName, "global", 1)
+	require.Nil(t, err, "When check globally throttled requests")
+}
+
+func TestDeniedConnectionRecordedMetrics(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	receiverCtx := observability.ContextWithReceiverName(context.Background(), receiverName)
+	observability.RecordDeniedConnection(receiverCtx)
+	observability.RecordDeniedConnection(receiverCtx)
+
+	err := observabilitytest.CheckValueViewReceiverDeniedConnections(receiverName, 2)
+	require.Nil(t, err, "When check receiver denied connections")
+}
+
+func TestDecompressionFailureRecordedMetrics(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	receiverCtx := observability.ContextWithReceiverName(context.Background(), receiverName)
+	observability.RecordDecompressionFailure(receiverCtx)
+
+	err := observabilitytest.CheckValueViewReceiverDecompressionFailures(receiverName, 1)
+	require.Nil(t, err, "When check receiver decompression failures")
+}
+
+func TestViews(t *testing.T) {
+	assert.Nil(t, observability.Views(telemetry.None))
+	assert.Equal(t, observability.AllViews, observability.Views(telemetry.Basic))
+	assert.Equal(t, observability.AllViews, observability.Views(telemetry.Normal))
+	assert.Equal(t, observability.AllViews, observability.Views(telemetry.Detailed))
+
+	minimalViews := observability.Views(telemetry.Minimal)
+	require.Equal(t, len(observability.AllViews), len(minimalViews))
+	for i, v := range minimalViews {
+		assert.Equal(t, observability.AllViews[i].Name, v.Name)
+		for _, k := range v.TagKeys {
+			if k == observability.TagKeyExporter {
+				assert.Len(t, v.TagKeys, 1, "view %q", v.Name)
+			}
+		}
+	}
+	// The original views must not be modified.
+	assert.Len(t, observability.ViewExporterReceivedSpans.TagKeys, 2)
+}
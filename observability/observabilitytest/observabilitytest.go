@@ -100,6 +100,64 @@ func CheckValueViewReceiverDroppedTimeSeries(receiverName string, value int) err
 		wantsTagsForReceiverView(receiverName), int64(value))
 }
 
+// CheckValueViewReceiverScrapes checks that for the current exported value in the ViewReceiverScrapes
+// for {TagKeyReceiver: receiverName} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverScrapes(receiverName string, value int) error {
+	return checkValueForView(observability.ViewReceiverScrapes.Name,
+		wantsTagsForReceiverView(receiverName), int64(value))
+}
+
+// CheckValueViewReceiverFailedScrapes checks that for the current exported value in the ViewReceiverFailedScrapes
+// for {TagKeyReceiver: receiverName} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverFailedScrapes(receiverName string, value int) error {
+	return checkValueForView(observability.ViewReceiverFailedScrapes.Name,
+		wantsTagsForReceiverView(receiverName), int64(value))
+}
+
+// CheckValueViewReceiverAuthRequests checks that for the current exported value in the ViewReceiverAuthRequests
+// for {TagKeyReceiver: receiverName, TagKeyAuthOutcome: outcome} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverAuthRequests(receiverName string, outcome string, value int) error {
+	return checkValueForView(observability.ViewReceiverAuthRequests.Name,
+		[]tag.Tag{
+			{Key: observability.TagKeyReceiver, Value: receiverName},
+			{Key: observability.TagKeyAuthOutcome, Value: outcome},
+		}, int64(value))
+}
+
+// CheckValueViewReceiverThrottledRequests checks that for the current exported value in the ViewReceiverThrottledRequests
+// for {TagKeyReceiver: receiverName, TagKeyThrottleLimit: limit} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverThrottledRequests(receiverName string, limit string, value int) error {
+	return checkValueForView(observability.ViewReceiverThrottledRequests.Name,
+		[]tag.Tag{
+			{Key: observability.TagKeyReceiver, Value: receiverName},
+			{Key: observability.TagKeyThrottleLimit, Value: limit},
+		}, int64(value))
+}
+
+// CheckValueViewReceiverDeniedConnections checks that for the current exported value in the ViewReceiverDeniedConnections
+// for {TagKeyReceiver: receiverName} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverDeniedConnections(receiverName string, value int) error {
+	return checkValueForView(observability.ViewReceiverDeniedConnections.Name,
+		[]tag.Tag{
+			{Key: observability.TagKeyReceiver, Value: receiverName},
+		}, int64(value))
+}
+
+// CheckValueViewReceiverDecompressionFailures checks that for the current exported value in the
+// ViewReceiverDecompressionFailures for {TagKeyReceiver: receiverName} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverDecompressionFailures(receiverName string, value int) error {
+	return checkValueForView(observability.ViewReceiverDecompressionFailures.Name,
+		[]tag.Tag{
+			{Key: observability.TagKeyReceiver, Value: receiverName},
+		}, int64(value))
+}
+
 func checkValueForView(vName string, wantTags []tag.Tag, value int64) error {
 	// Make sure the tags slice is sorted by tag keys.
 	sortTags(wantTags)
@@ -0,0 +1,112 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adaptivesamplerprocessor samples the spans with the probabilities
+// of their service and operation computed by an adaptive-sampling extension,
+// and counts the sampled spans so that the extension can adjust the
+// probabilities to its spans per second budget.
+package adaptivesamplerprocessor
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type adaptiveSamplerProcessor struct {
+	logger       *zap.Logger
+	nextConsumer consumer.TraceConsumer
+	extension    string
+	sample       bool
+	hashSeed     uint32
+	warnOnce     sync.Once
+}
+
+var _ processor.TraceProcessor = (*adaptiveSamplerProcessor)(nil)
+
+func newAdaptiveSamplerProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg Config,
+) (*adaptiveSamplerProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &adaptiveSamplerProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		extension:    cfg.Extension,
+		sample:       cfg.Sample,
+		hashSeed:     cfg.HashSeed,
+	}, nil
+}
+
+func (asp *adaptiveSamplerProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	// The controller is looked up on each call since the extension may be
+	// started after the pipelines are built.
+	controller := adaptivesampling.Lookup(asp.extension)
+	if controller == nil {
+		asp.warnOnce.Do(func() {
+			asp.logger.Warn("Adaptive sampling extension is not running, spans are not sampled",
+				zap.String("extension", asp.extension))
+		})
+		return asp.nextConsumer.ConsumeTraceData(ctx, td)
+	}
+
+	service := td.Node.GetServiceInfo().GetName()
+	sampledSpans := make([]*tracepb.Span, 0, len(td.Spans))
+	counts := make(map[string]int)
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		operation := span.GetName().GetValue()
+		if asp.sample && !sampled(span.TraceId, asp.hashSeed, controller.Probability(service, operation)) {
+			continue
+		}
+		sampledSpans = append(sampledSpans, span)
+		counts[operation]++
+	}
+	for operation, count := range counts {
+		controller.Record(service, operation, count)
+	}
+
+	td.Spans = sampledSpans
+	return asp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+// sampled returns true if the trace is sampled with the given probability.
+// The decision is based on the hash of the trace ID, so that the spans of a
+// trace are sampled together by the operations with the same probability.
+func sampled(traceID []byte, seed uint32, probability float64) bool {
+	if probability >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	var seedBytes [4]byte
+	binary.LittleEndian.PutUint32(seedBytes[:], seed)
+	_, _ = h.Write(seedBytes[:])
+	_, _ = h.Write(traceID)
+	return float64(h.Sum32()) < probability*(1<<32)
+}
@@ -0,0 +1,115 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/adaptivesampling"
+)
+
+func testTraceData(spans int, operations ...string) consumerdata.TraceData {
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+	}
+	for i := 0; i < spans; i++ {
+		traceID := make([]byte, 16)
+		binary.BigEndian.PutUint64(traceID[8:], uint64(i))
+		for _, operation := range operations {
+			td.Spans = append(td.Spans, &tracepb.Span{
+				TraceId: traceID,
+				Name:    &tracepb.TruncatableString{Value: operation},
+			})
+		}
+	}
+	return td
+}
+
+func TestAdaptiveSamplerProcessor(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	asp, err := newAdaptiveSamplerProcessor(zap.NewNop(), sink, Config{
+		Extension: "adaptive-sampling/test",
+		Sample:    true,
+	})
+	require.NoError(t, err)
+
+	// Without the extension the spans are only passed through.
+	require.NoError(t, asp.ConsumeTraceData(context.Background(), testTraceData(10, "GET /")))
+	require.Len(t, sink.AllTraces(), 1)
+	assert.Len(t, sink.AllTraces()[0].Spans, 10)
+
+	controller := adaptivesampling.NewController(100, 0, 0.25)
+	adaptivesampling.Register("adaptive-sampling/test", controller)
+	defer adaptivesampling.Unregister("adaptive-sampling/test")
+
+	sink = &exportertest.SinkTraceExporter{}
+	asp.nextConsumer = sink
+	require.NoError(t, asp.ConsumeTraceData(context.Background(), testTraceData(1000, "GET /", "SELECT")))
+	require.Len(t, sink.AllTraces(), 1)
+	spans := sink.AllTraces()[0].Spans
+	assert.InDelta(t, 500, len(spans), 100)
+
+	// The operations of a trace sampled with the same probability are
+	// sampled together.
+	traces := make(map[string]int)
+	for _, span := range spans {
+		traces[string(span.TraceId)]++
+	}
+	for _, count := range traces {
+		assert.Equal(t, 2, count)
+	}
+
+	_, operations := controller.Strategies("frontend")
+	assert.Empty(t, operations)
+	assert.Equal(t, 0.25, controller.Probability("frontend", "SELECT"))
+}
+
+func TestAdaptiveSamplerProcessor_CountOnly(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	asp, err := newAdaptiveSamplerProcessor(zap.NewNop(), sink, Config{
+		Extension: "adaptive-sampling/count",
+	})
+	require.NoError(t, err)
+
+	controller := adaptivesampling.NewController(0.001, 0, 0.5)
+	adaptivesampling.Register("adaptive-sampling/count", controller)
+	defer adaptivesampling.Unregister("adaptive-sampling/count")
+
+	require.NoError(t, asp.ConsumeTraceData(context.Background(), testTraceData(100, "GET /")))
+	require.Len(t, sink.AllTraces(), 1)
+	assert.Len(t, sink.AllTraces()[0].Spans, 100)
+
+	// The spans were counted.
+	controller.Adjust(time.Now().Add(time.Second))
+	assert.True(t, controller.Probability("frontend", "GET /") < 0.5)
+}
+
+func TestSampled(t *testing.T) {
+	traceID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	assert.True(t, sampled(traceID, 0, 1))
+	assert.False(t, sampled(traceID, 0, 0))
+	assert.Equal(t, sampled(traceID, 1, 0.5), sampled(traceID, 1, 0.5))
+}
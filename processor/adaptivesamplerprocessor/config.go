@@ -0,0 +1,38 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the adaptive sampler processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Extension is the name of the adaptive-sampling extension computing the
+	// sampling probabilities.
+	Extension string `mapstructure:"extension"`
+
+	// Sample enables sampling the spans with the probabilities of their
+	// operation. It is disabled when the spans are sampled by the SDKs, with
+	// the probabilities published by the Jaeger receiver, the spans are then
+	// only counted to adjust the probabilities.
+	Sample bool `mapstructure:"sample"`
+
+	// HashSeed is the seed of the hash of the trace IDs the sampling decision
+	// is based on, see the probabilistic sampler processor.
+	HashSeed uint32 `mapstructure:"hash-seed"`
+}
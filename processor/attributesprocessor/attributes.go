@@ -16,6 +16,10 @@ package attributesprocessor
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 
@@ -42,6 +46,7 @@ type attributeAction struct {
 	// and could impact performance.
 	Action         Action
 	AttributeValue *tracepb.AttributeValue
+	HMACSecret     []byte
 }
 
 // newTraceProcessor returns a processor that modifies attributes of a span.
@@ -90,6 +95,8 @@ func (a *attributesProcessor) ConsumeTraceData(ctx context.Context, td consumerd
 				// There is no need to check if the target key exists in the attribute map
 				// because the value is to be set regardless.
 				setAttribute(action, span.Attributes.AttributeMap)
+			case TOKENIZE:
+				tokenizeAttribute(action, span.Attributes.AttributeMap)
 			}
 		}
 	}
@@ -125,3 +132,40 @@ func setAttribute(action attributeAction, attributesMap map[string]*tracepb.Attr
 		attributesMap[action.Key] = value
 	}
 }
+
+func tokenizeAttribute(action attributeAction, attributesMap map[string]*tracepb.AttributeValue) {
+	// Tokenize is only performed when the target key already exists in
+	// the attribute map.
+	value, exists := attributesMap[action.Key]
+	if !exists {
+		return
+	}
+
+	s, ok := attributeString(value)
+	if !ok {
+		return
+	}
+	mac := hmac.New(sha256.New, action.HMACSecret)
+	mac.Write([]byte(s))
+	attributesMap[action.Key] = &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: base64.RawURLEncoding.EncodeToString(mac.Sum(nil))},
+		},
+	}
+}
+
+// attributeString returns the string representation of the value that is
+// tokenized.
+func attributeString(value *tracepb.AttributeValue) (string, bool) {
+	switch v := value.GetValue().(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return v.StringValue.GetValue(), true
+	case *tracepb.AttributeValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10), true
+	case *tracepb.AttributeValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64), true
+	case *tracepb.AttributeValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue), true
+	}
+	return "", false
+}
@@ -840,3 +840,58 @@ func TestAttributes_Ordering(t *testing.T) {
 		runIndividualTestCase(t, tt, tp)
 	}
 }
+
+func TestAttributes_Tokenize(t *testing.T) {
+	tokenValue := func(token string) *tracepb.AttributeValue {
+		return &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: token}},
+		}
+	}
+	testCases := []testCase{
+		// Ensure the span contains no changes because the key doesn't exist.
+		{
+			name: "TokenizeAttributeNoExist",
+			inputAttributes: map[string]*tracepb.AttributeValue{
+				"boo": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "ghosts are scary"}}},
+			},
+			expectedAttributes: map[string]*tracepb.AttributeValue{
+				"boo": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "ghosts are scary"}}},
+			},
+		},
+		// Ensure the string value is replaced by its token.
+		{
+			name: "TokenizeString",
+			inputAttributes: map[string]*tracepb.AttributeValue{
+				"user.email": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "alice@example.com"}}},
+			},
+			expectedAttributes: map[string]*tracepb.AttributeValue{
+				"user.email": tokenValue("q5W0bFa_HhMKl6BBUcIm6JFqWwlWHt9g1PkcShhg-ZU"),
+			},
+		},
+		// Ensure the other types are tokenized as strings.
+		{
+			name: "TokenizeInt",
+			inputAttributes: map[string]*tracepb.AttributeValue{
+				"user.email": {Value: &tracepb.AttributeValue_IntValue{IntValue: 42}},
+			},
+			expectedAttributes: map[string]*tracepb.AttributeValue{
+				"user.email": tokenValue("eyU0C2Rjd7hwelXesPCRSb_ZOwdPf62_cxb5ePI2-1M"),
+			},
+		},
+	}
+
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Actions = []ActionKeyValue{
+		{Key: "user.email", Action: TOKENIZE, HMACSecret: "s3cr3t"},
+	}
+
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	require.Nil(t, err)
+	require.NotNil(t, tp)
+
+	for _, tt := range testCases {
+		runIndividualTestCase(t, tt, tp)
+	}
+}
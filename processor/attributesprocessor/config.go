@@ -25,7 +25,7 @@ type Config struct {
 	configmodels.ProcessorSettings `mapstructure:",squash"`
 
 	// Actions specifies the list of attributes to act on.
-	// The set of actions are {INSERT, UPDATE, UPSERT, DELETE}.
+	// The set of actions are {INSERT, UPDATE, UPSERT, DELETE, TOKENIZE}.
 	Actions []ActionKeyValue `mapstructure:"actions"`
 }
 
@@ -43,8 +43,13 @@ type ActionKeyValue struct {
 	// the value. If the attribute doesn't exist, no action is performed.
 	FromAttribute string `mapstructure:"from_attribute"`
 
+	// HMACSecret specifies the secret keying the HMAC of the TOKENIZE action.
+	// It should reference a secret store, e.g. ${file:/etc/otelsvc/hmac},
+	// rather than be written in the configuration.
+	HMACSecret string `mapstructure:"hmac_secret"`
+
 	// Action specifies the type of action to perform.
-	// The set of values are {INSERT, UPDATE, UPSERT, DELETE}.
+	// The set of values are {INSERT, UPDATE, UPSERT, DELETE, TOKENIZE}.
 	// Both lower case and upper case are supported.
 	// INSERT - Inserts the key/value to spans when the key does not exist.
 	//          No action is applied to spans where the key already exists.
@@ -59,11 +64,17 @@ type ActionKeyValue struct {
 	//          Either Value or FromAttribute must be set.
 	// DELETE - Deletes the attribute from the span. If the key doesn't exist,
 	//          no action is performed.
+	// TOKENIZE - Replaces the value of an existing key with a token, the
+	//          HMAC-SHA256 of the value keyed with HMACSecret. Equal values
+	//          get equal tokens, so the tokenized attributes can still be
+	//          grouped and compared. No action is applied to spans where the
+	//          key does not exist.
+	//          HMACSecret must be set.
 	// This is a required field.
 	Action Action `mapstructure:"action"`
 }
 
-// Action is the enum to capture the four types of actions to perform on an
+// Action is the enum to capture the five types of actions to perform on an
 // attribute.
 type Action string
 
@@ -84,4 +95,9 @@ const (
 	// DELETE deletes the attribute from the span. If the key doesn't exist,
 	//no action is performed.
 	DELETE Action = "delete"
+
+	// TOKENIZE replaces the value of an existing key with a deterministic
+	// token, so that the value is hidden but equal values get equal tokens.
+	// No action is applied to spans where the key does not exist.
+	TOKENIZE Action = "tokenize"
 )
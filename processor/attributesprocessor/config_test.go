@@ -83,6 +83,17 @@ func TestLoadingConifg(t *testing.T) {
 		},
 	})
 
+	pTokenize := config.Processors["attributes/tokenize"]
+	assert.Equal(t, pTokenize, &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			NameVal: "attributes/tokenize",
+			TypeVal: typeStr,
+		},
+		Actions: []ActionKeyValue{
+			{Key: "user.email", HMACSecret: "s3cr3t", Action: TOKENIZE},
+		},
+	})
+
 	p4 := config.Processors["attributes/complex"]
 	assert.Equal(t, p4, &Config{
 		ProcessorSettings: configmodels.ProcessorSettings{
@@ -13,5 +13,5 @@
 // limitations under the License.
 
 // Package attributesprocessor contains the logic to modify attributes of a span.
-// It supports insert, update, upsert and delete as actions.
+// It supports insert, update, upsert, delete and tokenize as actions.
 package attributesprocessor
@@ -140,6 +140,12 @@ func buildAttributesConfiguration(config Config) ([]attributeAction, error) {
 		case DELETE:
 			// Do nothing since `key` is the only required field for `delete` action.
 
+		case TOKENIZE:
+			if a.HMACSecret == "" {
+				return nil, fmt.Errorf("error creating \"attributes\" processor due to missing required field \"hmac_secret\" at the %d-th actions of processor %q", i, config.Name())
+			}
+			action.HMACSecret = []byte(a.HMACSecret)
+
 		default:
 			return nil, fmt.Errorf("error creating \"attributes\" processor due to unsupported action %q at the %d-th actions of processor %q", a.Action, i, config.Name())
 		}
@@ -119,6 +119,7 @@ func TestFactory_validateAttributesConfiguration(t *testing.T) {
 		{Key: "two", Value: 123, Action: "INSERT"},
 		{Key: "three", FromAttribute: "two", Action: "upDaTE"},
 		{Key: "five", FromAttribute: "two", Action: "upsert"},
+		{Key: "six", HMACSecret: "s3cr3t", Action: "Tokenize"},
 	}
 	output, err := buildAttributesConfiguration(*oCfg)
 	assert.Equal(t, []attributeAction{
@@ -128,6 +129,7 @@ func TestFactory_validateAttributesConfiguration(t *testing.T) {
 		}},
 		{Key: "three", FromAttribute: "two", Action: UPDATE},
 		{Key: "five", FromAttribute: "two", Action: UPSERT},
+		{Key: "six", Action: TOKENIZE, HMACSecret: []byte("s3cr3t")},
 	}, output)
 	assert.NoError(t, err)
 
@@ -180,6 +182,13 @@ func TestFactory_validateAttributesConfiguration_InvalidConfig(t *testing.T) {
 			},
 			errorString: "error creating \"attributes\" processor due to both fields \"value\" and \"from_attribute\" being set at the 0-th actions of processor \"attributes/error\"",
 		},
+		{
+			name: "missing hmac secret",
+			actionLists: []ActionKeyValue{
+				{Key: "MissingSecret", Action: TOKENIZE},
+			},
+			errorString: "error creating \"attributes\" processor due to missing required field \"hmac_secret\" at the 0-th actions of processor \"attributes/error\"",
+		},
 	}
 	factory := Factory{}
 	cfg := factory.CreateDefaultConfig()
@@ -0,0 +1,208 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attributetypesprocessor converts the values of span attributes to
+// declared types, so that the same attribute has the same type whatever the
+// SDK that recorded it, as required by the backends with strict typing.
+package attributetypesprocessor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// Types the attribute values can be converted to.
+const (
+	typeString = "string"
+	typeInt    = "int"
+	typeDouble = "double"
+	typeBool   = "bool"
+)
+
+// converter converts an attribute value, it returns false if the value can't
+// be converted.
+type converter func(*tracepb.AttributeValue) (*tracepb.AttributeValue, bool)
+
+var converters = map[string]converter{
+	typeString: toString,
+	typeInt:    toInt,
+	typeDouble: toDouble,
+	typeBool:   toBool,
+}
+
+type attributeTypesProcessor struct {
+	nextConsumer        consumer.TraceConsumer
+	converters          map[string]converter
+	removeUnconvertible bool
+}
+
+var _ processor.TraceProcessor = (*attributeTypesProcessor)(nil)
+
+func newAttributeTypesProcessor(nextConsumer consumer.TraceConsumer, cfg Config) (*attributeTypesProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if len(cfg.Attributes) == 0 {
+		return nil, fmt.Errorf("error creating %q processor: \"attributes\" must not be empty", cfg.Name())
+	}
+
+	ap := &attributeTypesProcessor{
+		nextConsumer:        nextConsumer,
+		converters:          make(map[string]converter, len(cfg.Attributes)),
+		removeUnconvertible: cfg.RemoveUnconvertible,
+	}
+	for i, attr := range cfg.Attributes {
+		if attr.Key == "" {
+			return nil, fmt.Errorf("error creating %q processor: missing \"key\" of the %d-th attribute", cfg.Name(), i)
+		}
+		if _, ok := ap.converters[attr.Key]; ok {
+			return nil, fmt.Errorf("error creating %q processor: duplicate attribute %q", cfg.Name(), attr.Key)
+		}
+		conv, ok := converters[strings.ToLower(attr.Type)]
+		if !ok {
+			return nil, fmt.Errorf("error creating %q processor: unsupported type %q of attribute %q", cfg.Name(), attr.Type, attr.Key)
+		}
+		ap.converters[attr.Key] = conv
+	}
+	return ap, nil
+}
+
+func (ap *attributeTypesProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	for _, span := range td.Spans {
+		if span == nil || span.Attributes == nil {
+			continue
+		}
+		attrs := span.Attributes.AttributeMap
+		for key, conv := range ap.converters {
+			value, ok := attrs[key]
+			if !ok {
+				continue
+			}
+			if converted, ok := conv(value); ok {
+				attrs[key] = converted
+			} else if ap.removeUnconvertible {
+				delete(attrs, key)
+			}
+		}
+	}
+	return ap.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+func stringValue(s string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: s}},
+	}
+}
+
+func intValue(i int64) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: i}}
+}
+
+func doubleValue(f float64) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: f}}
+}
+
+func boolValue(b bool) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: b}}
+}
+
+func toString(v *tracepb.AttributeValue) (*tracepb.AttributeValue, bool) {
+	switch val := v.Value.(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return v, true
+	case *tracepb.AttributeValue_IntValue:
+		return stringValue(strconv.FormatInt(val.IntValue, 10)), true
+	case *tracepb.AttributeValue_DoubleValue:
+		return stringValue(strconv.FormatFloat(val.DoubleValue, 'f', -1, 64)), true
+	case *tracepb.AttributeValue_BoolValue:
+		return stringValue(strconv.FormatBool(val.BoolValue)), true
+	}
+	return nil, false
+}
+
+func toInt(v *tracepb.AttributeValue) (*tracepb.AttributeValue, bool) {
+	switch val := v.Value.(type) {
+	case *tracepb.AttributeValue_StringValue:
+		i, err := strconv.ParseInt(strings.TrimSpace(val.StringValue.GetValue()), 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		return intValue(i), true
+	case *tracepb.AttributeValue_IntValue:
+		return v, true
+	case *tracepb.AttributeValue_DoubleValue:
+		// Only the doubles without fractional part are converted, e.g. the
+		// status codes recorded by the SDKs without integer types.
+		f := val.DoubleValue
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, false
+		}
+		return intValue(int64(f)), true
+	case *tracepb.AttributeValue_BoolValue:
+		if val.BoolValue {
+			return intValue(1), true
+		}
+		return intValue(0), true
+	}
+	return nil, false
+}
+
+func toDouble(v *tracepb.AttributeValue) (*tracepb.AttributeValue, bool) {
+	switch val := v.Value.(type) {
+	case *tracepb.AttributeValue_StringValue:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val.StringValue.GetValue()), 64)
+		if err != nil {
+			return nil, false
+		}
+		return doubleValue(f), true
+	case *tracepb.AttributeValue_IntValue:
+		return doubleValue(float64(val.IntValue)), true
+	case *tracepb.AttributeValue_DoubleValue:
+		return v, true
+	case *tracepb.AttributeValue_BoolValue:
+		if val.BoolValue {
+			return doubleValue(1), true
+		}
+		return doubleValue(0), true
+	}
+	return nil, false
+}
+
+func toBool(v *tracepb.AttributeValue) (*tracepb.AttributeValue, bool) {
+	switch val := v.Value.(type) {
+	case *tracepb.AttributeValue_StringValue:
+		b, err := strconv.ParseBool(strings.TrimSpace(val.StringValue.GetValue()))
+		if err != nil {
+			return nil, false
+		}
+		return boolValue(b), true
+	case *tracepb.AttributeValue_IntValue:
+		return boolValue(val.IntValue != 0), true
+	case *tracepb.AttributeValue_DoubleValue:
+		return boolValue(val.DoubleValue != 0), true
+	case *tracepb.AttributeValue_BoolValue:
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,119 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributetypesprocessor
+
+import (
+	"context"
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestConverters(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   string
+		value *tracepb.AttributeValue
+		want  *tracepb.AttributeValue
+	}{
+		{name: "string to int", typ: typeInt, value: stringValue(" 404 "), want: intValue(404)},
+		{name: "invalid string to int", typ: typeInt, value: stringValue("404.5")},
+		{name: "integral double to int", typ: typeInt, value: doubleValue(200), want: intValue(200)},
+		{name: "fractional double to int", typ: typeInt, value: doubleValue(200.5)},
+		{name: "bool to int", typ: typeInt, value: boolValue(true), want: intValue(1)},
+		{name: "int to int", typ: typeInt, value: intValue(7), want: intValue(7)},
+		{name: "string to bool", typ: typeBool, value: stringValue("TRUE"), want: boolValue(true)},
+		{name: "invalid string to bool", typ: typeBool, value: stringValue("yes")},
+		{name: "int to bool", typ: typeBool, value: intValue(0), want: boolValue(false)},
+		{name: "double to bool", typ: typeBool, value: doubleValue(0.5), want: boolValue(true)},
+		{name: "string to double", typ: typeDouble, value: stringValue("0.25"), want: doubleValue(0.25)},
+		{name: "invalid string to double", typ: typeDouble, value: stringValue("quarter")},
+		{name: "int to double", typ: typeDouble, value: intValue(3), want: doubleValue(3)},
+		{name: "bool to double", typ: typeDouble, value: boolValue(false), want: doubleValue(0)},
+		{name: "int to string", typ: typeString, value: intValue(-12), want: stringValue("-12")},
+		{name: "double to string", typ: typeString, value: doubleValue(1.5), want: stringValue("1.5")},
+		{name: "bool to string", typ: typeString, value: boolValue(true), want: stringValue("true")},
+		{name: "no value", typ: typeString, value: &tracepb.AttributeValue{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := converters[tt.typ](tt.value)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func testSpans() []*tracepb.Span {
+	return []*tracepb.Span{
+		{
+			Attributes: &tracepb.Span_Attributes{
+				AttributeMap: map[string]*tracepb.AttributeValue{
+					"http.status_code": stringValue("503"),
+					"error":            stringValue("true"),
+					"other":            stringValue("503"),
+				},
+			},
+		},
+		{
+			Attributes: &tracepb.Span_Attributes{
+				AttributeMap: map[string]*tracepb.AttributeValue{
+					"http.status_code": stringValue("unknown"),
+					"error":            intValue(0),
+				},
+			},
+		},
+		{},
+		nil,
+	}
+}
+
+func TestAttributeTypesProcessor(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+		cfg.Attributes = []AttributeType{
+			{Key: "http.status_code", Type: "int"},
+			{Key: "error", Type: "BOOL"},
+		}
+		cfg.RemoveUnconvertible = remove
+		sink := new(exportertest.SinkTraceExporter)
+		ap, err := newAttributeTypesProcessor(sink, *cfg)
+		require.NoError(t, err)
+
+		require.NoError(t, ap.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: testSpans()}))
+		spans := sink.AllTraces()[0].Spans
+
+		assert.Equal(t, map[string]*tracepb.AttributeValue{
+			"http.status_code": intValue(503),
+			"error":            boolValue(true),
+			"other":            stringValue("503"),
+		}, spans[0].Attributes.AttributeMap)
+
+		want := map[string]*tracepb.AttributeValue{
+			"http.status_code": stringValue("unknown"),
+			"error":            boolValue(false),
+		}
+		if remove {
+			delete(want, "http.status_code")
+		}
+		assert.Equal(t, want, spans[1].Attributes.AttributeMap)
+		assert.Nil(t, spans[2].Attributes)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributetypesprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// AttributeType declares the type of the values of a span attribute.
+type AttributeType struct {
+	// Key is the key of the attribute.
+	Key string `mapstructure:"key"`
+
+	// Type is the type the values of the attribute are converted to, one of
+	// "string", "int", "double" and "bool".
+	Type string `mapstructure:"type"`
+}
+
+// Config defines configuration for the attribute types processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Attributes declares the types of the span attributes. The attributes
+	// are listed rather than mapped by key since the keys usually contain
+	// dots, which are not supported in the keys of the settings.
+	Attributes []AttributeType `mapstructure:"attributes"`
+
+	// RemoveUnconvertible removes the attributes whose values can't be
+	// converted to their declared type. By default they are kept unchanged.
+	RemoveUnconvertible bool `mapstructure:"remove-unconvertible"`
+}
@@ -0,0 +1,75 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributetypesprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := Factory{}
+	assert.Equal(t, typeStr, factory.Type())
+}
+
+func TestFactory_CreateTraceProcessor(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Attributes = []AttributeType{{Key: "http.status_code", Type: "int"}}
+
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, tp)
+
+	tp, err = factory.CreateTraceProcessor(zap.NewNop(), nil, cfg)
+	assert.Error(t, err)
+	assert.Nil(t, tp)
+}
+
+func TestFactory_CreateTraceProcessor_InvalidConfig(t *testing.T) {
+	factory := Factory{}
+	tests := []struct {
+		name       string
+		attributes []AttributeType
+	}{
+		{name: "no attributes"},
+		{name: "missing key", attributes: []AttributeType{{Type: "int"}}},
+		{name: "unsupported type", attributes: []AttributeType{{Key: "a", Type: "float"}}},
+		{name: "duplicate key", attributes: []AttributeType{{Key: "a", Type: "int"}, {Key: "a", Type: "bool"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Attributes = tt.attributes
+			tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+			assert.Error(t, err)
+			assert.Nil(t, tp)
+		})
+	}
+}
+
+func TestFactory_CreateMetricsProcessor(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.Equal(t, configerror.ErrDataTypeIsNotSupported, err)
+	assert.Nil(t, mp)
+}
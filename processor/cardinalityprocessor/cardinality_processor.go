@@ -0,0 +1,169 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalityprocessor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type cardinalityProcessor struct {
+	logger        *zap.Logger
+	nextConsumer  consumer.TraceConsumer
+	keys          map[string]bool
+	maxValues     int
+	window        time.Duration
+	action        Action
+	overflowValue string
+
+	// now is replaced by the tests to control the sliding window.
+	now func() time.Time
+
+	mu sync.Mutex
+	// tracked holds, per attribute key, the time each distinct value was
+	// last seen.
+	tracked map[string]*keyValues
+}
+
+type keyValues struct {
+	lastSeen map[string]time.Time
+	// lastWarned is the time the limit was last reported for the key.
+	lastWarned time.Time
+}
+
+var _ processor.TraceProcessor = (*cardinalityProcessor)(nil)
+
+func newCardinalityProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg Config,
+) (*cardinalityProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	var keys map[string]bool
+	if len(cfg.Keys) > 0 {
+		keys = make(map[string]bool, len(cfg.Keys))
+		for _, key := range cfg.Keys {
+			keys[key] = true
+		}
+	}
+	return &cardinalityProcessor{
+		logger:        logger,
+		nextConsumer:  nextConsumer,
+		keys:          keys,
+		maxValues:     cfg.MaxValues,
+		window:        cfg.Window,
+		action:        cfg.Action,
+		overflowValue: cfg.OverflowValue,
+		now:           time.Now,
+		tracked:       make(map[string]*keyValues),
+	}, nil
+}
+
+func (cp *cardinalityProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	now := cp.now()
+	cp.mu.Lock()
+	for _, span := range td.Spans {
+		if span == nil || span.Attributes == nil {
+			continue
+		}
+		for key, value := range span.Attributes.AttributeMap {
+			if cp.keys != nil && !cp.keys[key] {
+				continue
+			}
+			if cp.admit(key, value, now) {
+				continue
+			}
+			switch cp.action {
+			case DROP:
+				delete(span.Attributes.AttributeMap, key)
+			case OVERFLOW:
+				span.Attributes.AttributeMap[key] = &tracepb.AttributeValue{
+					Value: &tracepb.AttributeValue_StringValue{
+						StringValue: &tracepb.TruncatableString{Value: cp.overflowValue},
+					},
+				}
+			}
+		}
+	}
+	cp.mu.Unlock()
+	return cp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+// admit records the value of the attribute key and reports if it is within
+// the limit of distinct values. Must be called with the mutex held.
+func (cp *cardinalityProcessor) admit(key string, value *tracepb.AttributeValue, now time.Time) bool {
+	kv := cp.tracked[key]
+	if kv == nil {
+		kv = &keyValues{lastSeen: make(map[string]time.Time)}
+		cp.tracked[key] = kv
+	}
+
+	str := attributeValueString(value)
+	if _, ok := kv.lastSeen[str]; ok {
+		kv.lastSeen[str] = now
+		return true
+	}
+	if len(kv.lastSeen) >= cp.maxValues {
+		// Only values new to the key are subject to the limit, expire the
+		// values that fell out of the window before rejecting this one.
+		expiry := now.Add(-cp.window)
+		for v, t := range kv.lastSeen {
+			if t.Before(expiry) {
+				delete(kv.lastSeen, v)
+			}
+		}
+	}
+	if len(kv.lastSeen) >= cp.maxValues {
+		if now.Sub(kv.lastWarned) >= cp.window {
+			kv.lastWarned = now
+			cp.logger.Warn("Attribute exceeded the limit of distinct values",
+				zap.String("key", key),
+				zap.Int("max-values", cp.maxValues),
+				zap.Duration("window", cp.window),
+				zap.String("action", string(cp.action)))
+		}
+		return false
+	}
+	kv.lastSeen[str] = now
+	return true
+}
+
+// attributeValueString returns a string identifying the attribute value,
+// values of different types are always different.
+func attributeValueString(value *tracepb.AttributeValue) string {
+	switch v := value.GetValue().(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return "s:" + v.StringValue.GetValue()
+	case *tracepb.AttributeValue_IntValue:
+		return "i:" + strconv.FormatInt(v.IntValue, 10)
+	case *tracepb.AttributeValue_BoolValue:
+		return "b:" + strconv.FormatBool(v.BoolValue)
+	case *tracepb.AttributeValue_DoubleValue:
+		return "d:" + strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalityprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func newSpan(attrs map[string]string) *tracepb.Span {
+	attrMap := make(map[string]*tracepb.AttributeValue, len(attrs))
+	for k, v := range attrs {
+		attrMap[k] = &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_StringValue{
+				StringValue: &tracepb.TruncatableString{Value: v},
+			},
+		}
+	}
+	return &tracepb.Span{Attributes: &tracepb.Span_Attributes{AttributeMap: attrMap}}
+}
+
+func attributeValues(span *tracepb.Span) map[string]string {
+	values := make(map[string]string)
+	for k, v := range span.Attributes.AttributeMap {
+		values[k] = v.GetStringValue().GetValue()
+	}
+	return values
+}
+
+func TestCardinalityProcessor_Overflow(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	sink := &exportertest.SinkTraceExporter{}
+	cp, err := newCardinalityProcessor(zap.New(core), sink, Config{
+		Keys:          []string{"user.id"},
+		MaxValues:     2,
+		Window:        time.Minute,
+		Action:        OVERFLOW,
+		OverflowValue: "overflow",
+	})
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	cp.now = func() time.Time { return now }
+
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{
+			newSpan(map[string]string{"user.id": "a", "http.url": "/1"}),
+			newSpan(map[string]string{"user.id": "b", "http.url": "/2"}),
+			newSpan(map[string]string{"user.id": "c", "http.url": "/3"}),
+			newSpan(map[string]string{"user.id": "a", "http.url": "/4"}),
+			newSpan(map[string]string{"user.id": "d", "http.url": "/5"}),
+		},
+	}
+	require.NoError(t, cp.ConsumeTraceData(context.Background(), td))
+	require.Len(t, sink.AllTraces(), 1)
+
+	got := sink.AllTraces()[0].Spans
+	assert.Equal(t, map[string]string{"user.id": "a", "http.url": "/1"}, attributeValues(got[0]))
+	assert.Equal(t, map[string]string{"user.id": "b", "http.url": "/2"}, attributeValues(got[1]))
+	assert.Equal(t, map[string]string{"user.id": "overflow", "http.url": "/3"}, attributeValues(got[2]))
+	assert.Equal(t, map[string]string{"user.id": "a", "http.url": "/4"}, attributeValues(got[3]))
+	assert.Equal(t, map[string]string{"user.id": "overflow", "http.url": "/5"}, attributeValues(got[4]))
+
+	// The limit is reported once per window.
+	assert.Equal(t, 1, logs.FilterField(zap.String("key", "user.id")).Len())
+}
+
+func TestCardinalityProcessor_DropAllKeys(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	cp, err := newCardinalityProcessor(zap.NewNop(), sink, Config{
+		MaxValues: 1,
+		Window:    time.Minute,
+		Action:    DROP,
+	})
+	require.NoError(t, err)
+
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{
+			newSpan(map[string]string{"user.id": "a", "http.url": "/1"}),
+			newSpan(map[string]string{"user.id": "a", "http.url": "/2"}),
+			nil,
+			{},
+		},
+	}
+	require.NoError(t, cp.ConsumeTraceData(context.Background(), td))
+
+	got := sink.AllTraces()[0].Spans
+	assert.Equal(t, map[string]string{"user.id": "a", "http.url": "/1"}, attributeValues(got[0]))
+	assert.Equal(t, map[string]string{"user.id": "a"}, attributeValues(got[1]))
+}
+
+func TestCardinalityProcessor_SlidingWindow(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	cp, err := newCardinalityProcessor(zap.NewNop(), sink, Config{
+		MaxValues:     2,
+		Window:        time.Minute,
+		Action:        OVERFLOW,
+		OverflowValue: "overflow",
+	})
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	cp.now = func() time.Time { return now }
+
+	consume := func(value string) string {
+		span := newSpan(map[string]string{"user.id": value})
+		require.NoError(t, cp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: []*tracepb.Span{span}}))
+		return attributeValues(span)["user.id"]
+	}
+
+	assert.Equal(t, "a", consume("a"))
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, "b", consume("b"))
+	assert.Equal(t, "overflow", consume("c"))
+
+	// "a" is out of the window, making room for "c".
+	now = now.Add(40 * time.Second)
+	assert.Equal(t, "c", consume("c"))
+	assert.Equal(t, "overflow", consume("a"))
+
+	// Values seen again stay within the window.
+	now = now.Add(40 * time.Second)
+	assert.Equal(t, "c", consume("c"))
+	assert.Equal(t, "a", consume("a"))
+	assert.Equal(t, "overflow", consume("b"))
+}
+
+func TestAttributeValueString(t *testing.T) {
+	values := []*tracepb.AttributeValue{
+		{Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "1"}}},
+		{Value: &tracepb.AttributeValue_IntValue{IntValue: 1}},
+		{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 1}},
+		{Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}},
+	}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		str := attributeValueString(v)
+		assert.False(t, seen[str], str)
+		seen[str] = true
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalityprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Action is the action taken on the attributes of a key exceeding the
+// cardinality limit.
+type Action string
+
+const (
+	// DROP removes the attribute from the span.
+	DROP Action = "drop"
+	// OVERFLOW replaces the value of the attribute with the overflow value.
+	OVERFLOW Action = "overflow"
+)
+
+// Config defines configuration for the cardinality processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Keys are the span attribute keys guarded by the processor. If empty all
+	// attribute keys are guarded.
+	Keys []string `mapstructure:"keys"`
+
+	// MaxValues is the maximum number of distinct values of an attribute key
+	// within the window. Values past the limit are handled by the Action.
+	MaxValues int `mapstructure:"max-values"`
+
+	// Window is the duration after which a value not seen again no longer
+	// counts for the limit.
+	Window time.Duration `mapstructure:"window"`
+
+	// Action is the action taken for values past the limit, either "drop" or
+	// "overflow".
+	Action Action `mapstructure:"action"`
+
+	// OverflowValue is the value replacing the values past the limit when the
+	// action is "overflow".
+	OverflowValue string `mapstructure:"overflow-value"`
+}
@@ -0,0 +1,92 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalityprocessor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "cardinality"
+
+	defaultMaxValues     = 100
+	defaultWindow        = 10 * time.Minute
+	defaultOverflowValue = "overflow"
+)
+
+// Factory is the factory for the cardinality processor.
+type Factory struct {
+}
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the processor.
+func (f *Factory) CreateDefaultConfig() configmodels.Processor {
+	return &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		MaxValues:     defaultMaxValues,
+		Window:        defaultWindow,
+		Action:        OVERFLOW,
+		OverflowValue: defaultOverflowValue,
+	}
+}
+
+// CreateTraceProcessor creates a trace processor based on this config.
+func (f *Factory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	oCfg := *cfg.(*Config)
+	oCfg.Action = Action(strings.ToLower(string(oCfg.Action)))
+	switch oCfg.Action {
+	case DROP, OVERFLOW:
+	default:
+		return nil, fmt.Errorf("error creating %q processor due to unsupported action %q", oCfg.Name(), oCfg.Action)
+	}
+	if oCfg.MaxValues <= 0 {
+		return nil, fmt.Errorf("error creating %q processor: \"max-values\" must be positive", oCfg.Name())
+	}
+	if oCfg.Window <= 0 {
+		return nil, fmt.Errorf("error creating %q processor: \"window\" must be positive", oCfg.Name())
+	}
+	return newCardinalityProcessor(logger, nextConsumer, oCfg)
+}
+
+// CreateMetricsProcessor creates a metrics processor based on this config.
+func (f *Factory) CreateMetricsProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Processor,
+) (processor.MetricsProcessor, error) {
+	// Cardinality processor does not support metrics.
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
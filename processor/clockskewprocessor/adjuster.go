@@ -0,0 +1,187 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+const (
+	// originalStartTimeAttribute and originalEndTimeAttribute hold the
+	// timestamps of the adjusted spans before the adjustment, if recorded.
+	originalStartTimeAttribute = "clock-skew.original-start-time"
+	originalEndTimeAttribute   = "clock-skew.original-end-time"
+)
+
+// adjuster corrects the clock skew of the spans of a trace.
+type adjuster struct {
+	threshold      time.Duration
+	recordOriginal bool
+}
+
+// spanNode is a span of the trace tree with the host that reported it.
+type spanNode struct {
+	span     *tracepb.Span
+	host     string
+	start    time.Time
+	end      time.Time
+	children []*spanNode
+}
+
+func (n *spanNode) hasTimes() bool {
+	return !n.start.IsZero() && !n.end.IsZero()
+}
+
+// hostKey identifies the host of the node: its hostname or else its service,
+// the clocks of the spans with the same host key are assumed to be in sync.
+func hostKey(node *commonpb.Node) string {
+	if host := node.GetIdentifier().GetHostName(); host != "" {
+		return host
+	}
+	return node.GetServiceInfo().GetName()
+}
+
+// adjust corrects in place the spans of the trace in the given batches and
+// returns the number of adjusted spans. The root spans are never adjusted,
+// the skew of a span reported by another host than its parent is computed
+// against the adjusted parent, the spans reported by the same host as their
+// parent inherit its skew.
+func (a *adjuster) adjust(batches []consumerdata.TraceData) int {
+	byID := make(map[string]*spanNode)
+	var nodes []*spanNode
+	for _, td := range batches {
+		host := hostKey(td.Node)
+		for _, span := range td.Spans {
+			n := &spanNode{
+				span:  span,
+				host:  host,
+				start: timestampToTime(span.StartTime),
+				end:   timestampToTime(span.EndTime),
+			}
+			byID[string(span.SpanId)] = n
+			nodes = append(nodes, n)
+		}
+	}
+
+	var roots []*spanNode
+	for _, n := range nodes {
+		parent, ok := byID[string(n.span.ParentSpanId)]
+		if len(n.span.ParentSpanId) == 0 || !ok || parent == n {
+			roots = append(roots, n)
+			continue
+		}
+		parent.children = append(parent.children, n)
+	}
+
+	adjusted := 0
+	visited := make(map[*spanNode]bool, len(nodes))
+	var walk func(parent *spanNode, skew time.Duration)
+	walk = func(parent *spanNode, skew time.Duration) {
+		visited[parent] = true
+		for _, child := range parent.children {
+			if visited[child] {
+				continue
+			}
+			childSkew := skew
+			if child.host != parent.host {
+				childSkew = a.skew(child, parent)
+			}
+			if childSkew != 0 && child.hasTimes() {
+				a.shift(child, childSkew)
+				adjusted++
+			}
+			walk(child, childSkew)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return adjusted
+}
+
+// skew returns the shift fitting the child in its parent, 0 if it already
+// fits, if the skew is below the threshold or if it can't be computed. A child
+// shorter than its parent is centered in it, assuming the network latency is
+// the same both ways, a child longer than its parent, e.g. an asynchronous
+// call, is only shifted to not start before it.
+func (a *adjuster) skew(child, parent *spanNode) time.Duration {
+	if !child.hasTimes() || !parent.hasTimes() {
+		return 0
+	}
+	parentDuration := parent.end.Sub(parent.start)
+	childDuration := child.end.Sub(child.start)
+
+	var skew time.Duration
+	switch {
+	case childDuration > parentDuration:
+		if child.start.Before(parent.start) {
+			skew = parent.start.Sub(child.start)
+		}
+	case child.start.Before(parent.start) || child.end.After(parent.end):
+		latency := (parentDuration - childDuration) / 2
+		skew = parent.start.Add(latency).Sub(child.start)
+	}
+	if skew < a.threshold && -skew < a.threshold {
+		return 0
+	}
+	return skew
+}
+
+// shift moves the timestamps of the span and of its time events by skew.
+func (a *adjuster) shift(n *spanNode, skew time.Duration) {
+	span := n.span
+	if a.recordOriginal {
+		if span.Attributes == nil {
+			span.Attributes = &tracepb.Span_Attributes{}
+		}
+		if span.Attributes.AttributeMap == nil {
+			span.Attributes.AttributeMap = make(map[string]*tracepb.AttributeValue)
+		}
+		span.Attributes.AttributeMap[originalStartTimeAttribute] = timeAttribute(n.start)
+		span.Attributes.AttributeMap[originalEndTimeAttribute] = timeAttribute(n.end)
+	}
+
+	n.start = n.start.Add(skew)
+	n.end = n.end.Add(skew)
+	span.StartTime = internal.TimeToTimestamp(n.start)
+	span.EndTime = internal.TimeToTimestamp(n.end)
+	for _, event := range span.GetTimeEvents().GetTimeEvent() {
+		if event.Time != nil {
+			event.Time = internal.TimeToTimestamp(timestampToTime(event.Time).Add(skew))
+		}
+	}
+}
+
+func timeAttribute(t time.Time) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: t.UTC().Format(time.RFC3339Nano)},
+		},
+	}
+}
+
+func timestampToTime(ts *timestamp.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+}
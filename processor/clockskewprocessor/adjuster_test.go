@@ -0,0 +1,179 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+var baseTime = time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC)
+
+func newSpan(id, parentID byte, start, end time.Duration) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId:   []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanId:    []byte{0, 0, 0, 0, 0, 0, 0, id},
+		StartTime: internal.TimeToTimestamp(baseTime.Add(start)),
+		EndTime:   internal.TimeToTimestamp(baseTime.Add(end)),
+	}
+	if parentID != 0 {
+		span.ParentSpanId = []byte{0, 0, 0, 0, 0, 0, 0, parentID}
+	}
+	return span
+}
+
+func hostNode(host string) *commonpb.Node {
+	return &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{HostName: host}}
+}
+
+func assertTimes(t *testing.T, span *tracepb.Span, start, end time.Duration) {
+	t.Helper()
+	assert.Equal(t, baseTime.Add(start), timestampToTime(span.StartTime), "start of span %v", span.SpanId)
+	assert.Equal(t, baseTime.Add(end), timestampToTime(span.EndTime), "end of span %v", span.SpanId)
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name         string
+		child        *tracepb.Span
+		childHost    string
+		threshold    time.Duration
+		wantStart    time.Duration
+		wantEnd      time.Duration
+		wantAdjusted int
+	}{
+		{
+			name:      "fits in parent",
+			child:     newSpan(2, 1, 10*time.Millisecond, 90*time.Millisecond),
+			childHost: "b",
+			wantStart: 10 * time.Millisecond,
+			wantEnd:   90 * time.Millisecond,
+		},
+		{
+			name:         "starts before parent",
+			child:        newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond),
+			childHost:    "b",
+			wantStart:    10 * time.Millisecond,
+			wantEnd:      90 * time.Millisecond,
+			wantAdjusted: 1,
+		},
+		{
+			name:         "ends after parent",
+			child:        newSpan(2, 1, 60*time.Millisecond, 140*time.Millisecond),
+			childHost:    "b",
+			wantStart:    10 * time.Millisecond,
+			wantEnd:      90 * time.Millisecond,
+			wantAdjusted: 1,
+		},
+		{
+			name:         "longer than parent",
+			child:        newSpan(2, 1, -50*time.Millisecond, 150*time.Millisecond),
+			childHost:    "b",
+			wantStart:    0,
+			wantEnd:      200 * time.Millisecond,
+			wantAdjusted: 1,
+		},
+		{
+			name:      "same host",
+			child:     newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond),
+			childHost: "a",
+			wantStart: -50 * time.Millisecond,
+			wantEnd:   30 * time.Millisecond,
+		},
+		{
+			name:      "below threshold",
+			child:     newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond),
+			childHost: "b",
+			threshold: time.Second,
+			wantStart: -50 * time.Millisecond,
+			wantEnd:   30 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newSpan(1, 0, 0, 100*time.Millisecond)
+			batches := []consumerdata.TraceData{
+				{Node: hostNode("a"), Spans: []*tracepb.Span{root}},
+				{Node: hostNode(tt.childHost), Spans: []*tracepb.Span{tt.child}},
+			}
+			a := &adjuster{threshold: tt.threshold}
+			assert.Equal(t, tt.wantAdjusted, a.adjust(batches))
+			assertTimes(t, root, 0, 100*time.Millisecond)
+			assertTimes(t, tt.child, tt.wantStart, tt.wantEnd)
+		})
+	}
+}
+
+func TestAdjust_InheritedSkew(t *testing.T) {
+	root := newSpan(1, 0, 0, 100*time.Millisecond)
+	child := newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond)
+	child.TimeEvents = &tracepb.Span_TimeEvents{
+		TimeEvent: []*tracepb.Span_TimeEvent{{Time: internal.TimeToTimestamp(baseTime.Add(-40 * time.Millisecond))}},
+	}
+	// The grandchild is reported by the same host as the child, it is
+	// shifted with it.
+	grandchild := newSpan(3, 2, -45*time.Millisecond, 25*time.Millisecond)
+	batches := []consumerdata.TraceData{
+		{Node: hostNode("a"), Spans: []*tracepb.Span{root}},
+		{Node: hostNode("b"), Spans: []*tracepb.Span{child, grandchild}},
+	}
+	a := &adjuster{}
+	assert.Equal(t, 2, a.adjust(batches))
+	assertTimes(t, child, 10*time.Millisecond, 90*time.Millisecond)
+	assertTimes(t, grandchild, 15*time.Millisecond, 85*time.Millisecond)
+	assert.Equal(t, baseTime.Add(20*time.Millisecond), timestampToTime(child.TimeEvents.TimeEvent[0].Time))
+}
+
+func TestAdjust_RecordOriginal(t *testing.T) {
+	root := newSpan(1, 0, 0, 100*time.Millisecond)
+	child := newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond)
+	batches := []consumerdata.TraceData{
+		{Node: hostNode("a"), Spans: []*tracepb.Span{root}},
+		{Node: hostNode("b"), Spans: []*tracepb.Span{child}},
+	}
+	a := &adjuster{recordOriginal: true}
+	assert.Equal(t, 1, a.adjust(batches))
+	attrs := child.Attributes.AttributeMap
+	assert.Equal(t, "2019-07-01T09:59:59.95Z", attrs[originalStartTimeAttribute].GetStringValue().GetValue())
+	assert.Equal(t, "2019-07-01T10:00:00.03Z", attrs[originalEndTimeAttribute].GetStringValue().GetValue())
+	assert.Nil(t, root.Attributes)
+}
+
+func TestAdjust_MissingParent(t *testing.T) {
+	// The parent is not part of the trace, the span is a root.
+	orphan := newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond)
+	noTimes := &tracepb.Span{SpanId: []byte{3}, ParentSpanId: orphan.SpanId}
+	batches := []consumerdata.TraceData{
+		{Node: hostNode("b"), Spans: []*tracepb.Span{orphan}},
+		{Node: hostNode("c"), Spans: []*tracepb.Span{noTimes}},
+	}
+	a := &adjuster{}
+	assert.Equal(t, 0, a.adjust(batches))
+	assertTimes(t, orphan, -50*time.Millisecond, 30*time.Millisecond)
+	assert.Nil(t, noTimes.StartTime)
+}
+
+func TestHostKey(t *testing.T) {
+	assert.Equal(t, "a", hostKey(hostNode("a")))
+	assert.Equal(t, "frontend", hostKey(&commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}}))
+	assert.Equal(t, "", hostKey(nil))
+}
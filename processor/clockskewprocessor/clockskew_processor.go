@@ -0,0 +1,206 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clockskewprocessor corrects the clock skew between the hosts
+// reporting the spans of a trace: the spans starting before or ending after
+// their parent reported by another host are shifted to fit in their parent,
+// as the clock skew adjuster of Jaeger does at query time. The spans are held
+// for a while so that the whole trace is adjusted at once.
+package clockskewprocessor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// flushInterval is the period at which the traces held for longer than the
+// wait are adjusted and passed to the next consumer.
+const flushInterval = time.Second
+
+// pendingTrace holds the batches of spans of a trace, by node.
+type pendingTrace struct {
+	arrival time.Time
+	batches []consumerdata.TraceData
+}
+
+type clockSkewProcessor struct {
+	logger       *zap.Logger
+	nextConsumer consumer.TraceConsumer
+	wait         time.Duration
+	numTraces    int
+	adjuster     *adjuster
+
+	start    sync.Once
+	done     chan struct{}
+	stopOnce sync.Once
+	loop     sync.WaitGroup
+
+	mu     sync.Mutex
+	traces map[string]*pendingTrace
+}
+
+var (
+	_ processor.TraceProcessor = (*clockSkewProcessor)(nil)
+	_ processor.Shutdowner     = (*clockSkewProcessor)(nil)
+)
+
+func newClockSkewProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg Config,
+) (*clockSkewProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if cfg.Wait <= 0 {
+		return nil, errors.New("wait must be positive")
+	}
+	if cfg.NumTraces <= 0 {
+		return nil, errors.New("num-traces must be positive")
+	}
+	if cfg.Threshold < 0 {
+		return nil, errors.New("threshold must not be negative")
+	}
+	return &clockSkewProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		wait:         cfg.Wait,
+		numTraces:    cfg.NumTraces,
+		adjuster: &adjuster{
+			threshold:      cfg.Threshold,
+			recordOriginal: cfg.RecordOriginal,
+		},
+		done:   make(chan struct{}),
+		traces: make(map[string]*pendingTrace),
+	}, nil
+}
+
+// ConsumeTraceData holds the spans until their trace is adjusted, the spans
+// without a valid trace ID and the spans of new traces once num-traces are
+// held are passed to the next consumer right away.
+func (csp *clockSkewProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	csp.start.Do(func() {
+		csp.loop.Add(1)
+		go csp.flushLoop()
+	})
+
+	byTrace := make(map[string][]*tracepb.Span)
+	var passed []*tracepb.Span
+	for _, span := range td.Spans {
+		if len(span.TraceId) != 16 {
+			passed = append(passed, span)
+			continue
+		}
+		byTrace[string(span.TraceId)] = append(byTrace[string(span.TraceId)], span)
+	}
+
+	now := time.Now()
+	csp.mu.Lock()
+	for id, spans := range byTrace {
+		trace, ok := csp.traces[id]
+		if !ok {
+			if len(csp.traces) >= csp.numTraces {
+				passed = append(passed, spans...)
+				continue
+			}
+			trace = &pendingTrace{arrival: now}
+			csp.traces[id] = trace
+		}
+		trace.batches = append(trace.batches, consumerdata.TraceData{
+			Node:         td.Node,
+			Resource:     td.Resource,
+			Spans:        spans,
+			SourceFormat: td.SourceFormat,
+		})
+	}
+	csp.mu.Unlock()
+
+	if len(passed) == 0 {
+		return nil
+	}
+	return csp.nextConsumer.ConsumeTraceData(ctx, consumerdata.TraceData{
+		Node:         td.Node,
+		Resource:     td.Resource,
+		Spans:        passed,
+		SourceFormat: td.SourceFormat,
+	})
+}
+
+func (csp *clockSkewProcessor) flushLoop() {
+	defer csp.loop.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			csp.flush(context.Background(), now)
+		case <-csp.done:
+			return
+		}
+	}
+}
+
+// Shutdown stops the flush loop and passes all the held traces to the next
+// consumer, adjusted with the spans received so far.
+func (csp *clockSkewProcessor) Shutdown(ctx context.Context) error {
+	csp.stopOnce.Do(func() {
+		// The loop is not started by the batches received after the
+		// shutdown.
+		csp.start.Do(func() {})
+		close(csp.done)
+	})
+	csp.loop.Wait()
+	return csp.flush(ctx, time.Now().Add(csp.wait))
+}
+
+// flush adjusts the traces held since before now minus the wait and passes
+// them to the next consumer. The traces not passed yet once ctx is done are
+// dropped and the error of ctx is returned.
+func (csp *clockSkewProcessor) flush(ctx context.Context, now time.Time) error {
+	deadline := now.Add(-csp.wait)
+	var ready []*pendingTrace
+	csp.mu.Lock()
+	for id, trace := range csp.traces {
+		if !trace.arrival.After(deadline) {
+			ready = append(ready, trace)
+			delete(csp.traces, id)
+		}
+	}
+	csp.mu.Unlock()
+
+	for _, trace := range ready {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if adjusted := csp.adjuster.adjust(trace.batches); adjusted > 0 {
+			csp.logger.Debug("Corrected the clock skew of spans", zap.Int("spans", adjusted))
+		}
+		for _, td := range trace.batches {
+			if err := csp.nextConsumer.ConsumeTraceData(context.Background(), td); err != nil {
+				csp.logger.Warn("Error sending the adjusted spans", zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func newTestProcessor(t *testing.T, sink *exportertest.SinkTraceExporter, numTraces int) *clockSkewProcessor {
+	csp, err := newClockSkewProcessor(zap.NewNop(), sink, Config{
+		Wait:      time.Minute,
+		NumTraces: numTraces,
+	})
+	require.NoError(t, err)
+	// The flush loop is not started, the tests flush explicitly.
+	csp.start.Do(func() {})
+	return csp
+}
+
+func TestConsumeTraceData_Flush(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	csp := newTestProcessor(t, sink, 10)
+
+	root := newSpan(1, 0, 0, 100*time.Millisecond)
+	child := newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond)
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("a"),
+		Spans: []*tracepb.Span{root},
+	}))
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("b"),
+		Spans: []*tracepb.Span{child},
+	}))
+	assert.Empty(t, sink.AllTraces())
+
+	// The trace is held until the wait elapses.
+	csp.flush(context.Background(), time.Now())
+	assert.Empty(t, sink.AllTraces())
+
+	csp.flush(context.Background(), time.Now().Add(time.Minute))
+	got := sink.AllTraces()
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].Node.Identifier.HostName)
+	assert.Equal(t, "b", got[1].Node.Identifier.HostName)
+	assertTimes(t, got[1].Spans[0], 10*time.Millisecond, 90*time.Millisecond)
+	assert.Empty(t, csp.traces)
+}
+
+func TestShutdown(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	csp, err := newClockSkewProcessor(zap.NewNop(), sink, Config{
+		Wait:      time.Minute,
+		NumTraces: 10,
+	})
+	require.NoError(t, err)
+
+	root := newSpan(1, 0, 0, 100*time.Millisecond)
+	child := newSpan(2, 1, -50*time.Millisecond, 30*time.Millisecond)
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("a"),
+		Spans: []*tracepb.Span{root},
+	}))
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("b"),
+		Spans: []*tracepb.Span{child},
+	}))
+	assert.Empty(t, sink.AllTraces())
+
+	// The held traces are adjusted and passed without waiting.
+	require.NoError(t, csp.Shutdown(context.Background()))
+	got := sink.AllTraces()
+	require.Len(t, got, 2)
+	assertTimes(t, got[1].Spans[0], 10*time.Millisecond, 90*time.Millisecond)
+	assert.Empty(t, csp.traces)
+	require.NoError(t, csp.Shutdown(context.Background()))
+}
+
+func TestShutdown_Canceled(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	csp := newTestProcessor(t, sink, 10)
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("a"),
+		Spans: []*tracepb.Span{newSpan(1, 0, 0, time.Millisecond)},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, csp.Shutdown(ctx))
+	assert.Empty(t, sink.AllTraces())
+}
+
+func TestConsumeTraceData_PassedSpans(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	csp := newTestProcessor(t, sink, 1)
+
+	held := newSpan(1, 0, 0, time.Millisecond)
+	// Once num-traces are held the spans of new traces are not held.
+	otherTrace := newSpan(2, 0, 0, time.Millisecond)
+	otherTrace.TraceId = []byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	invalidTraceID := &tracepb.Span{TraceId: []byte{1}, SpanId: []byte{3}}
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("a"),
+		Spans: []*tracepb.Span{held},
+	}))
+	require.NoError(t, csp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Node:  hostNode("a"),
+		Spans: []*tracepb.Span{otherTrace, invalidTraceID},
+	}))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	assert.ElementsMatch(t, []*tracepb.Span{otherTrace, invalidTraceID}, got[0].Spans)
+	assert.Len(t, csp.traces, 1)
+}
+
+func TestNewClockSkewProcessor_InvalidConfig(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tests := []struct {
+		cfg     Config
+		wantErr string
+	}{
+		{Config{Wait: time.Second}, "num-traces must be positive"},
+		{Config{Wait: time.Second, NumTraces: 1, Threshold: -time.Second}, "threshold must not be negative"},
+	}
+	for _, tt := range tests {
+		_, err := newClockSkewProcessor(zap.NewNop(), sink, tt.cfg)
+		assert.EqualError(t, err, tt.wantErr)
+	}
+}
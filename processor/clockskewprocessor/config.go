@@ -0,0 +1,41 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the clock skew processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Wait is the time the spans of a trace are held, waiting for the other
+	// spans of the trace, before their clock skew is corrected.
+	Wait time.Duration `mapstructure:"wait"`
+
+	// Threshold is the skew below which the spans are not adjusted.
+	Threshold time.Duration `mapstructure:"threshold"`
+
+	// NumTraces is the maximum number of traces held, the spans of the other
+	// traces are passed to the next consumer without being adjusted.
+	NumTraces int `mapstructure:"num-traces"`
+
+	// RecordOriginal records the original timestamps of the adjusted spans as
+	// attributes.
+	RecordOriginal bool `mapstructure:"record-original"`
+}
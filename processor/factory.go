@@ -17,6 +17,7 @@ package processor
 import (
 	"fmt"
 
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
@@ -44,6 +45,45 @@ type Factory interface {
 		cfg configmodels.Processor) (MetricsProcessor, error)
 }
 
+// CustomUnmarshalerFactory is implemented by the factories whose configuration
+// requires custom unmarshaling. This is typically used if viper.Unmarshal() is not
+// sufficient to unmarshal correctly, e.g. for polymorphic fields.
+type CustomUnmarshalerFactory interface {
+	// CustomUnmarshaler returns a custom unmarshaler for the configuration or nil if
+	// there is no need for custom unmarshaling.
+	CustomUnmarshaler() CustomUnmarshaler
+}
+
+// CustomUnmarshaler is a function that un-marshals a viper data into a config struct
+// in a custom way.
+type CustomUnmarshaler func(v *viper.Viper, viperKey string, intoCfg interface{}) error
+
+// Capabilities describes how a processor handles the data. The order of the
+// processors of the pipelines is checked, and optionally fixed, according to
+// the capabilities of their factories.
+type Capabilities struct {
+	// Limiter processors drop or refuse the data to protect the collector, they
+	// must precede the other processors so that the data is dropped before any
+	// work is spent on it.
+	Limiter bool
+
+	// Batcher processors accumulate the data into batches, they must precede the
+	// queues so that the batches are queued and retried rather than the
+	// individual requests.
+	Batcher bool
+
+	// Queue processors hand the data to the next consumer asynchronously.
+	Queue bool
+}
+
+// CapabilitiesFactory is implemented by the factories of the processors whose
+// position in the pipelines matters.
+type CapabilitiesFactory interface {
+	// Capabilities returns the capabilities of the processors created by the
+	// factory.
+	Capabilities() Capabilities
+}
+
 // Build takes a list of processor factories and returns a map of type map[string]Factory
 // with factory type as keys. It returns a non-nil error when more than one factories
 // have the same type.
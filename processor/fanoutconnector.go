@@ -64,3 +64,30 @@ func (tfc traceFanOutConnector) ConsumeTraceData(ctx context.Context, td consume
 	}
 	return oterr.CombineErrors(errs)
 }
+
+var _ consumer.RawTraceConsumer = (*traceFanOutConnector)(nil)
+
+// AcceptsRawTraceFormat returns true if all trace consumers wrapped by the current
+// one accept the raw trace data format.
+func (tfc traceFanOutConnector) AcceptsRawTraceFormat(format string) bool {
+	for _, tc := range tfc {
+		rtc, ok := tc.(consumer.RawTraceConsumer)
+		if !ok || !rtc.AcceptsRawTraceFormat(format) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConsumeRawTraceData exports the raw trace data to all trace consumers wrapped by
+// the current one. It must only be called if AcceptsRawTraceFormat returned true
+// for the format of the data.
+func (tfc traceFanOutConnector) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error {
+	var errs []error
+	for _, tc := range tfc {
+		if err := tc.(consumer.RawTraceConsumer).ConsumeRawTraceData(ctx, rtd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return oterr.CombineErrors(errs)
+}
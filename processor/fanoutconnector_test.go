@@ -151,6 +151,45 @@ func TestMetricsProcessorWhenOneErrors(t *testing.T) {
 	}
 }
 
+func TestTraceProcessorRawTraceData(t *testing.T) {
+	processors := make([]consumer.TraceConsumer, 3)
+	for i := range processors {
+		processors[i] = &mockRawTraceConsumer{format: "raw"}
+	}
+
+	tfc := NewTraceFanOutConnector(processors).(consumer.RawTraceConsumer)
+	if !tfc.AcceptsRawTraceFormat("raw") {
+		t.Fatalf("Wanted the raw format to be accepted")
+	}
+	if tfc.AcceptsRawTraceFormat("other") {
+		t.Fatalf("Wanted the other format to be rejected")
+	}
+
+	// Make one processor return error
+	processors[1].(*mockRawTraceConsumer).MustFail = true
+	rtd := consumerdata.RawTraceData{Format: "raw", Payload: make([]byte, 3)}
+	for i := 0; i < 2; i++ {
+		if err := tfc.ConsumeRawTraceData(context.Background(), rtd); err == nil {
+			t.Errorf("Wanted error got nil")
+			return
+		}
+	}
+	for _, p := range processors {
+		m := p.(*mockRawTraceConsumer)
+		if m.TotalBytes != 6 {
+			t.Errorf("Wanted %d bytes for every processor but got %d", 6, m.TotalBytes)
+			return
+		}
+	}
+
+	// The raw format is rejected if any consumer doesn't support it.
+	processors = append(processors, &mockTraceConsumer{})
+	tfc = NewTraceFanOutConnector(processors).(consumer.RawTraceConsumer)
+	if tfc.AcceptsRawTraceFormat("raw") {
+		t.Fatalf("Wanted the raw format to be rejected")
+	}
+}
+
 type mockTraceConsumer struct {
 	TotalSpans int
 	MustFail   bool
@@ -167,6 +206,27 @@ func (p *mockTraceConsumer) ConsumeTraceData(ctx context.Context, td consumerdat
 	return nil
 }
 
+type mockRawTraceConsumer struct {
+	mockTraceConsumer
+	format     string
+	TotalBytes int
+}
+
+var _ consumer.RawTraceConsumer = &mockRawTraceConsumer{}
+
+func (p *mockRawTraceConsumer) AcceptsRawTraceFormat(format string) bool {
+	return format == p.format
+}
+
+func (p *mockRawTraceConsumer) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error {
+	p.TotalBytes += len(rtd.Payload)
+	if p.MustFail {
+		return fmt.Errorf("this processor must fail")
+	}
+
+	return nil
+}
+
 type mockMetricsConsumer struct {
 	TotalMetrics int
 	MustFail     bool
@@ -0,0 +1,33 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the GeoIP processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Database is the path of the MaxMind database, GeoIP2 or GeoLite2, of
+	// the City or Country edition.
+	Database string `mapstructure:"database"`
+
+	// IPAttributes are the keys of the attributes holding the IP address of
+	// the client, checked in order on the span attributes and then on the
+	// node attributes. Default is client.ip, http.client_ip and net.peer.ip.
+	IPAttributes []string `mapstructure:"ip-attributes"`
+}
@@ -0,0 +1,212 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geoipprocessor adds the location of the clients to the spans,
+// resolving their IP address against a local MaxMind database, e.g. for the
+// analysis of the traces of edge or real user monitoring.
+package geoipprocessor
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/oschwald/maxminddb-golang"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// CountryAttribute is the span attribute holding the ISO 3166-1 code of
+	// the country of the client.
+	CountryAttribute = "geo.country"
+	// RegionAttribute is the span attribute holding the ISO 3166-2 code,
+	// without the country, of the region of the client.
+	RegionAttribute = "geo.region"
+	// CityAttribute is the span attribute holding the English name of the
+	// city of the client.
+	CityAttribute = "geo.city"
+)
+
+// location is the location of an IP address, its fields are empty when
+// unknown.
+type location struct {
+	country string
+	region  string
+	city    string
+}
+
+// locator resolves the location of IP addresses.
+type locator interface {
+	locate(ip net.IP) (location, error)
+}
+
+// mmdbRecord is the subset of the records of the City and Country editions
+// of the MaxMind databases used by the processor.
+type mmdbRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+type mmdbLocator struct {
+	reader *maxminddb.Reader
+}
+
+func (l *mmdbLocator) locate(ip net.IP) (location, error) {
+	var record mmdbRecord
+	if err := l.reader.Lookup(ip, &record); err != nil {
+		return location{}, err
+	}
+	loc := location{
+		country: record.Country.IsoCode,
+		city:    record.City.Names["en"],
+	}
+	if len(record.Subdivisions) > 0 {
+		loc.region = record.Subdivisions[0].IsoCode
+	}
+	return loc, nil
+}
+
+type geoIPProcessor struct {
+	name         string
+	logger       *zap.Logger
+	nextConsumer consumer.TraceConsumer
+	ipAttributes []string
+	locator      locator
+}
+
+var _ processor.TraceProcessor = (*geoIPProcessor)(nil)
+
+func newGeoIPProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer, cfg Config) (*geoIPProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if cfg.Database == "" {
+		return nil, fmt.Errorf("error creating %q processor: \"database\" must be set", cfg.Name())
+	}
+	ipAttributes := cfg.IPAttributes
+	if len(ipAttributes) == 0 {
+		ipAttributes = defaultIPAttributes
+	}
+	reader, err := maxminddb.Open(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %q processor: failed to open the database: %v", cfg.Name(), err)
+	}
+	return &geoIPProcessor{
+		name:         cfg.Name(),
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		ipAttributes: ipAttributes,
+		locator:      &mmdbLocator{reader: reader},
+	}, nil
+}
+
+// ConsumeTraceData adds the location of the client to the spans whose client
+// IP address is known, from their attributes or else from the node.
+func (gp *geoIPProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	// The node is only located once per batch, if a span needs it.
+	var nodeLoc *location
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		if address, ok := gp.spanAddress(span); ok {
+			addLocation(span, gp.locate(address))
+			continue
+		}
+		if nodeLoc == nil {
+			loc := gp.locate(gp.nodeAddress(td.Node))
+			nodeLoc = &loc
+		}
+		addLocation(span, *nodeLoc)
+	}
+	return gp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+func (gp *geoIPProcessor) spanAddress(span *tracepb.Span) (string, bool) {
+	attrs := span.GetAttributes().GetAttributeMap()
+	for _, key := range gp.ipAttributes {
+		if value := attrs[key].GetStringValue().GetValue(); value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func (gp *geoIPProcessor) nodeAddress(node *commonpb.Node) string {
+	for _, key := range gp.ipAttributes {
+		if value := node.GetAttributes()[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// locate returns the location of the address, empty if it is unknown.
+func (gp *geoIPProcessor) locate(address string) location {
+	// The address may include the port of the client.
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return location{}
+	}
+	loc, err := gp.locator.locate(ip)
+	if err != nil {
+		gp.logger.Debug("Failed to locate the client IP address",
+			zap.String("processor", gp.name), zap.String("ip", address), zap.Error(err))
+		return location{}
+	}
+	return loc
+}
+
+// addLocation adds the known fields of the location to the span, the
+// attributes already set are kept.
+func addLocation(span *tracepb.Span, loc location) {
+	for _, attr := range []struct{ key, value string }{
+		{CountryAttribute, loc.country},
+		{RegionAttribute, loc.region},
+		{CityAttribute, loc.city},
+	} {
+		if attr.value == "" {
+			continue
+		}
+		if span.Attributes == nil {
+			span.Attributes = &tracepb.Span_Attributes{}
+		}
+		if span.Attributes.AttributeMap == nil {
+			span.Attributes.AttributeMap = make(map[string]*tracepb.AttributeValue)
+		}
+		if _, ok := span.Attributes.AttributeMap[attr.key]; ok {
+			continue
+		}
+		span.Attributes.AttributeMap[attr.key] = &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: attr.value}},
+		}
+	}
+}
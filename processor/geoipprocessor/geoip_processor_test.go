@@ -0,0 +1,112 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+)
+
+type fakeLocator map[string]location
+
+func (l fakeLocator) locate(ip net.IP) (location, error) {
+	loc, ok := l[ip.String()]
+	if !ok {
+		return location{}, errors.New("not found")
+	}
+	return loc, nil
+}
+
+func stringAttributes(attrs map[string]string) *tracepb.Span_Attributes {
+	m := make(map[string]*tracepb.AttributeValue, len(attrs))
+	for k, v := range attrs {
+		m[k] = &tracepb.AttributeValue{Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: v}}}
+	}
+	return &tracepb.Span_Attributes{AttributeMap: m}
+}
+
+func spanAttributes(span *tracepb.Span) map[string]string {
+	attrs := make(map[string]string)
+	for k, v := range span.GetAttributes().GetAttributeMap() {
+		attrs[k] = v.GetStringValue().GetValue()
+	}
+	return attrs
+}
+
+func TestGeoIPProcessor(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	gp := &geoIPProcessor{
+		name:         "geoip",
+		logger:       zap.NewNop(),
+		nextConsumer: sink,
+		ipAttributes: defaultIPAttributes,
+		locator: fakeLocator{
+			"81.2.69.142":  {country: "GB", region: "ENG", city: "London"},
+			"2001:db8::1":  {country: "SE"},
+			"203.0.113.10": {country: "US", region: "WA", city: "Seattle"},
+		},
+	}
+
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{Attributes: map[string]string{peeraddr.IPAttribute: "203.0.113.10"}},
+		Spans: []*tracepb.Span{
+			{Attributes: stringAttributes(map[string]string{"client.ip": "81.2.69.142"})},
+			// The port is ignored, the fields unknown are not added.
+			{Attributes: stringAttributes(map[string]string{"http.client_ip": "[2001:db8::1]:443"})},
+			// The attributes already set are kept.
+			{Attributes: stringAttributes(map[string]string{"client.ip": "81.2.69.142", CityAttribute: "Londres"})},
+			// The node address is used when the span has none.
+			{},
+			// The unknown addresses are not located, even if the node is.
+			{Attributes: stringAttributes(map[string]string{"client.ip": "192.0.2.1"})},
+			{Attributes: stringAttributes(map[string]string{"client.ip": "not an ip"})},
+		},
+	}
+	require.NoError(t, gp.ConsumeTraceData(context.Background(), td))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	spans := got[0].Spans
+	assert.Equal(t, map[string]string{
+		"client.ip":      "81.2.69.142",
+		CountryAttribute: "GB",
+		RegionAttribute:  "ENG",
+		CityAttribute:    "London",
+	}, spanAttributes(spans[0]))
+	assert.Equal(t, map[string]string{
+		"http.client_ip": "[2001:db8::1]:443",
+		CountryAttribute: "SE",
+	}, spanAttributes(spans[1]))
+	assert.Equal(t, "Londres", spanAttributes(spans[2])[CityAttribute])
+	assert.Equal(t, map[string]string{
+		CountryAttribute: "US",
+		RegionAttribute:  "WA",
+		CityAttribute:    "Seattle",
+	}, spanAttributes(spans[3]))
+	assert.Equal(t, map[string]string{"client.ip": "192.0.2.1"}, spanAttributes(spans[4]))
+	assert.Equal(t, map[string]string{"client.ip": "not an ip"}, spanAttributes(spans[5]))
+}
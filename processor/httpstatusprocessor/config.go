@@ -0,0 +1,50 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstatusprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// KindSettings defines the status derivation specific to a span kind.
+type KindSettings struct {
+	// ClientErrorsOK sets the OK status for the 4xx HTTP status codes, which
+	// are the caller's fault rather than errors of the span.
+	ClientErrorsOK bool `mapstructure:"client-errors-ok"`
+}
+
+// Config defines configuration for the HTTP status processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Attribute is the span attribute holding the HTTP status code.
+	Attribute string `mapstructure:"attribute"`
+
+	// Overwrite replaces the status already set on the spans. By default only
+	// the spans without status are updated.
+	Overwrite bool `mapstructure:"overwrite"`
+
+	// Mappings maps HTTP status codes, e.g. "404", or classes, e.g. "5xx", to
+	// the name of the status code, e.g. "OK" or "UNAVAILABLE". They take
+	// precedence over the default mapping and the kind settings, exact codes
+	// over classes.
+	Mappings map[string]string `mapstructure:"mappings"`
+
+	// Server applies to the spans of kind SERVER.
+	Server KindSettings `mapstructure:"server"`
+
+	// Client applies to the spans of any other kind.
+	Client KindSettings `mapstructure:"client"`
+}
@@ -0,0 +1,163 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstatusprocessor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// statusCodes maps the names of the status codes to their value.
+var statusCodes = map[string]int32{
+	"OK":                  tracetranslator.OCOK,
+	"CANCELLED":           tracetranslator.OCCancelled,
+	"UNKNOWN":             tracetranslator.OCUnknown,
+	"INVALID_ARGUMENT":    tracetranslator.OCInvalidArgument,
+	"DEADLINE_EXCEEDED":   tracetranslator.OCDeadlineExceeded,
+	"NOT_FOUND":           tracetranslator.OCNotFound,
+	"ALREADY_EXISTS":      tracetranslator.OCAlreadyExists,
+	"PERMISSION_DENIED":   tracetranslator.OCPermissionDenied,
+	"RESOURCE_EXHAUSTED":  tracetranslator.OCResourceExhausted,
+	"FAILED_PRECONDITION": tracetranslator.OCFailedPrecondition,
+	"ABORTED":             tracetranslator.OCAborted,
+	"OUT_OF_RANGE":        tracetranslator.OCOutOfRange,
+	"UNIMPLEMENTED":       tracetranslator.OCUnimplemented,
+	"INTERNAL":            tracetranslator.OCInternal,
+	"UNAVAILABLE":         tracetranslator.OCUnavailable,
+	"DATA_LOSS":           tracetranslator.OCDataLoss,
+	"UNAUTHENTICATED":     tracetranslator.OCUnauthenticated,
+}
+
+type httpStatusProcessor struct {
+	nextConsumer consumer.TraceConsumer
+	attribute    string
+	overwrite    bool
+	// codes and classes hold the configured mappings of the HTTP status codes
+	// and of their classes, indexed by the first digit.
+	codes   map[int64]int32
+	classes map[int64]int32
+	server  KindSettings
+	client  KindSettings
+}
+
+var _ processor.TraceProcessor = (*httpStatusProcessor)(nil)
+
+func newHTTPStatusProcessor(nextConsumer consumer.TraceConsumer, cfg Config) (*httpStatusProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if cfg.Attribute == "" {
+		return nil, fmt.Errorf("error creating %q processor: \"attribute\" must not be empty", cfg.Name())
+	}
+
+	hp := &httpStatusProcessor{
+		nextConsumer: nextConsumer,
+		attribute:    cfg.Attribute,
+		overwrite:    cfg.Overwrite,
+		codes:        make(map[int64]int32),
+		classes:      make(map[int64]int32),
+		server:       cfg.Server,
+		client:       cfg.Client,
+	}
+	for httpCode, statusName := range cfg.Mappings {
+		statusCode, ok := statusCodes[strings.ToUpper(statusName)]
+		if !ok {
+			return nil, fmt.Errorf("error creating %q processor: unknown status code %q", cfg.Name(), statusName)
+		}
+		if err := hp.addMapping(httpCode, statusCode); err != nil {
+			return nil, fmt.Errorf("error creating %q processor: %v", cfg.Name(), err)
+		}
+	}
+	return hp, nil
+}
+
+// addMapping adds the mapping of an HTTP status code, e.g. "404", or of a
+// class, e.g. "4xx".
+func (hp *httpStatusProcessor) addMapping(httpCode string, statusCode int32) error {
+	lower := strings.ToLower(httpCode)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") {
+		class, err := strconv.ParseInt(lower[:1], 10, 64)
+		if err != nil || class < 1 || class > 5 {
+			return fmt.Errorf("invalid HTTP status class %q", httpCode)
+		}
+		hp.classes[class] = statusCode
+		return nil
+	}
+	code, err := strconv.ParseInt(httpCode, 10, 64)
+	if err != nil || code < 100 || code > 599 {
+		return fmt.Errorf("invalid HTTP status code %q", httpCode)
+	}
+	hp.codes[code] = statusCode
+	return nil
+}
+
+func (hp *httpStatusProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	for _, span := range td.Spans {
+		if span == nil || (span.Status != nil && !hp.overwrite) {
+			continue
+		}
+		httpCode, ok := httpStatusCode(span.GetAttributes().GetAttributeMap()[hp.attribute])
+		if !ok || httpCode < 100 || httpCode > 599 {
+			continue
+		}
+		span.Status = &tracepb.Status{Code: hp.statusCode(httpCode, span.Kind)}
+	}
+	return hp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+// statusCode returns the status code derived from the HTTP status code of a
+// span of the given kind.
+func (hp *httpStatusProcessor) statusCode(httpCode int64, kind tracepb.Span_SpanKind) int32 {
+	if statusCode, ok := hp.codes[httpCode]; ok {
+		return statusCode
+	}
+	class := httpCode / 100
+	if statusCode, ok := hp.classes[class]; ok {
+		return statusCode
+	}
+	settings := hp.client
+	if kind == tracepb.Span_SERVER {
+		settings = hp.server
+	}
+	if class < 4 || (class == 4 && settings.ClientErrorsOK) {
+		return tracetranslator.OCOK
+	}
+	return tracetranslator.OCStatusCodeFromHTTP(int32(httpCode))
+}
+
+// httpStatusCode returns the HTTP status code held by the attribute value.
+func httpStatusCode(value *tracepb.AttributeValue) (int64, bool) {
+	switch v := value.GetValue().(type) {
+	case *tracepb.AttributeValue_IntValue:
+		return v.IntValue, true
+	case *tracepb.AttributeValue_DoubleValue:
+		return int64(v.DoubleValue), true
+	case *tracepb.AttributeValue_StringValue:
+		code, err := strconv.ParseInt(v.StringValue.GetValue(), 10, 64)
+		return code, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstatusprocessor
+
+import (
+	"context"
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func httpSpan(kind tracepb.Span_SpanKind, code *tracepb.AttributeValue, status *tracepb.Status) *tracepb.Span {
+	span := &tracepb.Span{Kind: kind, Status: status}
+	if code != nil {
+		span.Attributes = &tracepb.Span_Attributes{
+			AttributeMap: map[string]*tracepb.AttributeValue{
+				tracetranslator.TagHTTPStatusCode: code,
+			},
+		}
+	}
+	return span
+}
+
+func intCode(code int64) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: code}}
+}
+
+func stringCode(code string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: code}},
+	}
+}
+
+func newTestProcessor(t *testing.T, modify func(*Config)) (*httpStatusProcessor, *exportertest.SinkTraceExporter) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	hp, err := newHTTPStatusProcessor(sink, *cfg)
+	require.NoError(t, err)
+	return hp, sink
+}
+
+func TestHTTPStatusProcessor_Default(t *testing.T) {
+	hp, sink := newTestProcessor(t, nil)
+
+	tests := []struct {
+		name string
+		span *tracepb.Span
+		want *tracepb.Status
+	}{
+		{
+			name: "server 200",
+			span: httpSpan(tracepb.Span_SERVER, intCode(200), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCOK},
+		},
+		{
+			name: "client 302",
+			span: httpSpan(tracepb.Span_CLIENT, intCode(302), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCOK},
+		},
+		{
+			name: "server 404",
+			span: httpSpan(tracepb.Span_SERVER, intCode(404), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCOK},
+		},
+		{
+			name: "client 404",
+			span: httpSpan(tracepb.Span_CLIENT, intCode(404), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCNotFound},
+		},
+		{
+			name: "unspecified 404",
+			span: httpSpan(tracepb.Span_SPAN_KIND_UNSPECIFIED, stringCode("404"), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCNotFound},
+		},
+		{
+			name: "server 503",
+			span: httpSpan(tracepb.Span_SERVER, stringCode("503"), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCUnavailable},
+		},
+		{
+			name: "server 599",
+			span: httpSpan(tracepb.Span_SERVER, intCode(599), nil),
+			want: &tracepb.Status{Code: tracetranslator.OCUnknown},
+		},
+		{
+			name: "status already set",
+			span: httpSpan(tracepb.Span_SERVER, intCode(500), &tracepb.Status{Code: tracetranslator.OCOK}),
+			want: &tracepb.Status{Code: tracetranslator.OCOK},
+		},
+		{
+			name: "no status code",
+			span: httpSpan(tracepb.Span_SERVER, nil, nil),
+		},
+		{
+			name: "invalid status code",
+			span: httpSpan(tracepb.Span_SERVER, stringCode("oops"), nil),
+		},
+		{
+			name: "out of range status code",
+			span: httpSpan(tracepb.Span_SERVER, intCode(42), nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := consumerdata.TraceData{Spans: []*tracepb.Span{tt.span, nil}}
+			require.NoError(t, hp.ConsumeTraceData(context.Background(), td))
+			assert.Equal(t, tt.want, tt.span.Status)
+		})
+	}
+	assert.Len(t, sink.AllTraces(), len(tests))
+}
+
+func TestHTTPStatusProcessor_Custom(t *testing.T) {
+	hp, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.Attribute = "http.status"
+		cfg.Overwrite = true
+		cfg.Mappings = map[string]string{
+			"404": "ok",
+			"5xx": "UNAVAILABLE",
+			"503": "RESOURCE_EXHAUSTED",
+		}
+		cfg.Server.ClientErrorsOK = false
+		cfg.Client.ClientErrorsOK = true
+	})
+
+	tests := []struct {
+		name string
+		kind tracepb.Span_SpanKind
+		code int64
+		want int32
+	}{
+		{name: "server 400", kind: tracepb.Span_SERVER, code: 400, want: tracetranslator.OCInvalidArgument},
+		{name: "client 400", kind: tracepb.Span_CLIENT, code: 400, want: tracetranslator.OCOK},
+		{name: "server 404", kind: tracepb.Span_SERVER, code: 404, want: tracetranslator.OCOK},
+		{name: "server 500", kind: tracepb.Span_SERVER, code: 500, want: tracetranslator.OCUnavailable},
+		{name: "server 503", kind: tracepb.Span_SERVER, code: 503, want: tracetranslator.OCResourceExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := &tracepb.Span{
+				Kind:   tt.kind,
+				Status: &tracepb.Status{Code: tracetranslator.OCUnknown, Message: "replaced"},
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{"http.status": intCode(tt.code)},
+				},
+			}
+			td := consumerdata.TraceData{Spans: []*tracepb.Span{span}}
+			require.NoError(t, hp.ConsumeTraceData(context.Background(), td))
+			assert.Equal(t, &tracepb.Status{Code: tt.want}, span.Status)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines where the Kubernetes metadata is read from. Each value is
+// looked up from the environment variable with the configured name, which is
+// expected to be populated via the downward API, e.g.:
+//
+//   env:
+//   - name: POD_NAME
+//     valueFrom:
+//       fieldRef:
+//         fieldPath: metadata.name
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// PodNameEnv is the environment variable holding the pod name. If it is
+	// not set the hostname is used when the service runs in a pod, since
+	// Kubernetes sets the hostname to the pod name by default.
+	PodNameEnv string `mapstructure:"pod-name-env"`
+
+	// PodNamespaceEnv is the environment variable holding the pod namespace.
+	// If it is not set the namespace is read from the service account mount.
+	PodNamespaceEnv string `mapstructure:"pod-namespace-env"`
+
+	// NodeNameEnv is the environment variable holding the node name.
+	NodeNameEnv string `mapstructure:"node-name-env"`
+
+	// PodUIDEnv is the environment variable holding the pod UID.
+	PodUIDEnv string `mapstructure:"pod-uid-env"`
+
+	// ServiceAccountDir is the directory where the service account is mounted.
+	ServiceAccountDir string `mapstructure:"service-account-dir"`
+
+	// Override controls whether detected values replace resource labels that
+	// are already present on the data. By default existing labels are kept.
+	Override bool `mapstructure:"override"`
+}
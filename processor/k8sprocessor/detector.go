@@ -0,0 +1,79 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resource label keys set by the processor.
+const (
+	LabelPodName   = "k8s.pod.name"
+	LabelNamespace = "k8s.namespace.name"
+	LabelNodeName  = "k8s.node.name"
+	LabelPodUID    = "k8s.pod.uid"
+)
+
+// resourceType is set on the resource when the data does not have one.
+const resourceType = "k8s"
+
+// environment abstracts the process environment so detection can be tested.
+type environment struct {
+	getenv   func(string) string
+	readFile func(string) ([]byte, error)
+	hostname func() (string, error)
+}
+
+var osEnvironment = environment{
+	getenv:   os.Getenv,
+	readFile: ioutil.ReadFile,
+	hostname: os.Hostname,
+}
+
+// detect returns the resource labels found for the given configuration. Labels
+// that cannot be detected are omitted.
+func detect(cfg Config, env environment) map[string]string {
+	labels := make(map[string]string)
+	setIfNotEmpty := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			labels[key] = value
+		}
+	}
+
+	namespace := env.getenv(cfg.PodNamespaceEnv)
+	if namespace == "" && cfg.ServiceAccountDir != "" {
+		if b, err := env.readFile(filepath.Join(cfg.ServiceAccountDir, "namespace")); err == nil {
+			namespace = string(b)
+		}
+	}
+	setIfNotEmpty(LabelNamespace, namespace)
+
+	podName := env.getenv(cfg.PodNameEnv)
+	if podName == "" && labels[LabelNamespace] != "" {
+		// Only fall back to the hostname when there is evidence that the
+		// service is running in a pod.
+		if host, err := env.hostname(); err == nil {
+			podName = host
+		}
+	}
+	setIfNotEmpty(LabelPodName, podName)
+
+	setIfNotEmpty(LabelNodeName, env.getenv(cfg.NodeNameEnv))
+	setIfNotEmpty(LabelPodUID, env.getenv(cfg.PodUIDEnv))
+	return labels
+}
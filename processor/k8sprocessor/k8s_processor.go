@@ -0,0 +1,95 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type k8sProcessor struct {
+	nextTraceConsumer   consumer.TraceConsumer
+	nextMetricsConsumer consumer.MetricsConsumer
+	labels              map[string]string
+	override            bool
+}
+
+var _ processor.TraceProcessor = (*k8sProcessor)(nil)
+var _ processor.MetricsProcessor = (*k8sProcessor)(nil)
+
+func newK8sTraceProcessor(nextConsumer consumer.TraceConsumer, cfg Config, env environment) (processor.TraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &k8sProcessor{
+		nextTraceConsumer: nextConsumer,
+		labels:            detect(cfg, env),
+		override:          cfg.Override,
+	}, nil
+}
+
+func newK8sMetricsProcessor(nextConsumer consumer.MetricsConsumer, cfg Config, env environment) (processor.MetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &k8sProcessor{
+		nextMetricsConsumer: nextConsumer,
+		labels:              detect(cfg, env),
+		override:            cfg.Override,
+	}, nil
+}
+
+func (kp *k8sProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	td.Resource = kp.mergeResource(td.Resource)
+	return kp.nextTraceConsumer.ConsumeTraceData(ctx, td)
+}
+
+func (kp *k8sProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	md.Resource = kp.mergeResource(md.Resource)
+	return kp.nextMetricsConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// mergeResource returns a copy of the resource with the detected labels added.
+// The original resource is not modified since receivers may share it between
+// batches.
+func (kp *k8sProcessor) mergeResource(res *resourcepb.Resource) *resourcepb.Resource {
+	if len(kp.labels) == 0 {
+		return res
+	}
+	if res == nil {
+		res = &resourcepb.Resource{Type: resourceType}
+	}
+
+	labels := make(map[string]string, len(res.Labels)+len(kp.labels))
+	for k, v := range res.Labels {
+		labels[k] = v
+	}
+	for k, v := range kp.labels {
+		if _, exists := labels[k]; exists && !kp.override {
+			continue
+		}
+		labels[k] = v
+	}
+	return &resourcepb.Resource{
+		Type:   res.Type,
+		Labels: labels,
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func testEnvironment(vars map[string]string, files map[string]string) environment {
+	return environment{
+		getenv: func(key string) string {
+			return vars[key]
+		},
+		readFile: func(name string) ([]byte, error) {
+			if content, ok := files[name]; ok {
+				return []byte(content), nil
+			}
+			return nil, os.ErrNotExist
+		},
+		hostname: func() (string, error) {
+			return "pod-from-hostname", nil
+		},
+	}
+}
+
+func defaultConfig() Config {
+	return *(&Factory{}).CreateDefaultConfig().(*Config)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		vars  map[string]string
+		files map[string]string
+		want  map[string]string
+	}{
+		{
+			name: "not_in_kubernetes",
+			want: map[string]string{},
+		},
+		{
+			name: "downward_api",
+			vars: map[string]string{
+				"POD_NAME":      "my-pod",
+				"POD_NAMESPACE": "my-namespace",
+				"NODE_NAME":     "my-node",
+				"POD_UID":       "6c5e2fe4-0f2f-11e9-a2c3-42010a800002",
+			},
+			want: map[string]string{
+				LabelPodName:   "my-pod",
+				LabelNamespace: "my-namespace",
+				LabelNodeName:  "my-node",
+				LabelPodUID:    "6c5e2fe4-0f2f-11e9-a2c3-42010a800002",
+			},
+		},
+		{
+			name: "service_account_fallback",
+			files: map[string]string{
+				"/var/run/secrets/kubernetes.io/serviceaccount/namespace": "sa-namespace\n",
+			},
+			want: map[string]string{
+				LabelPodName:   "pod-from-hostname",
+				LabelNamespace: "sa-namespace",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detect(defaultConfig(), testEnvironment(tt.vars, tt.files))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectHostnameError(t *testing.T) {
+	env := testEnvironment(map[string]string{"POD_NAMESPACE": "ns"}, nil)
+	env.hostname = func() (string, error) {
+		return "", errors.New("no hostname")
+	}
+	assert.Equal(t, map[string]string{LabelNamespace: "ns"}, detect(defaultConfig(), env))
+}
+
+func TestK8sProcessor_ConsumeTraceData(t *testing.T) {
+	env := testEnvironment(map[string]string{
+		"POD_NAME":      "my-pod",
+		"POD_NAMESPACE": "my-namespace",
+	}, nil)
+
+	tests := []struct {
+		name     string
+		override bool
+		resource *resourcepb.Resource
+		want     *resourcepb.Resource
+	}{
+		{
+			name: "nil_resource",
+			want: &resourcepb.Resource{
+				Type: resourceType,
+				Labels: map[string]string{
+					LabelPodName:   "my-pod",
+					LabelNamespace: "my-namespace",
+				},
+			},
+		},
+		{
+			name: "keep_existing",
+			resource: &resourcepb.Resource{
+				Type:   "container",
+				Labels: map[string]string{LabelPodName: "other-pod", "a": "b"},
+			},
+			want: &resourcepb.Resource{
+				Type: "container",
+				Labels: map[string]string{
+					LabelPodName:   "other-pod",
+					LabelNamespace: "my-namespace",
+					"a":            "b",
+				},
+			},
+		},
+		{
+			name:     "override_existing",
+			override: true,
+			resource: &resourcepb.Resource{
+				Labels: map[string]string{LabelPodName: "other-pod"},
+			},
+			want: &resourcepb.Resource{
+				Labels: map[string]string{
+					LabelPodName:   "my-pod",
+					LabelNamespace: "my-namespace",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Override = tt.override
+			sink := &exportertest.SinkTraceExporter{}
+			tp, err := newK8sTraceProcessor(sink, cfg, env)
+			require.NoError(t, err)
+
+			var original map[string]string
+			if tt.resource != nil {
+				original = make(map[string]string)
+				for k, v := range tt.resource.Labels {
+					original[k] = v
+				}
+			}
+
+			require.NoError(t, tp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Resource: tt.resource}))
+			got := sink.AllTraces()
+			require.Len(t, got, 1)
+			assert.Equal(t, tt.want, got[0].Resource)
+			if tt.resource != nil {
+				assert.Equal(t, original, tt.resource.Labels, "input resource must not be modified")
+			}
+		})
+	}
+}
+
+func TestK8sProcessor_ConsumeMetricsData(t *testing.T) {
+	env := testEnvironment(map[string]string{"NODE_NAME": "my-node"}, nil)
+	sink := &exportertest.SinkMetricsExporter{}
+	mp, err := newK8sMetricsProcessor(sink, defaultConfig(), env)
+	require.NoError(t, err)
+
+	require.NoError(t, mp.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{}))
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, map[string]string{LabelNodeName: "my-node"}, got[0].Resource.Labels)
+}
+
+func TestK8sProcessor_NothingDetected(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tp, err := newK8sTraceProcessor(sink, defaultConfig(), testEnvironment(nil, nil))
+	require.NoError(t, err)
+
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	assert.Nil(t, sink.AllTraces()[0].Resource)
+}
@@ -53,7 +53,7 @@ func MetricTagKeys(level telemetry.Level) []tag.Key {
 	case telemetry.Normal:
 		tagKeys = append(tagKeys, TagSourceFormatKey)
 		fallthrough
-	case telemetry.Basic:
+	case telemetry.Basic, telemetry.Minimal:
 		tagKeys = append(tagKeys, TagExporterNameKey)
 	default:
 		return nil
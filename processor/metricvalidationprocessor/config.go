@@ -0,0 +1,62 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricvalidationprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Action is the action taken on the invalid metric points.
+type Action string
+
+const (
+	// DROP removes the invalid points.
+	DROP Action = "drop"
+	// KEEP forwards the invalid points unchanged.
+	KEEP Action = "keep"
+	// ZERO replaces the NaN and infinite values with zero.
+	ZERO Action = "zero"
+	// RESET handles a decreasing cumulative value as a reset of the counter,
+	// the start time of the timeseries is moved to the previous point.
+	RESET Action = "reset"
+	// PAD completes the missing label values of a timeseries with unset
+	// values.
+	PAD Action = "pad"
+)
+
+// Config defines configuration for the metric validation processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// NonFinite is the action for the NaN and infinite values, either "drop",
+	// "zero" or "keep".
+	NonFinite Action `mapstructure:"non-finite"`
+
+	// DecreasingCumulative is the action for the cumulative values decreasing
+	// while the start time of their timeseries is unchanged, either "drop",
+	// "reset" or "keep".
+	DecreasingCumulative Action `mapstructure:"decreasing-cumulative"`
+
+	// LabelMismatch is the action for the timeseries with less label values
+	// than label keys, either "drop" or "pad". The timeseries with more label
+	// values than label keys are always dropped.
+	LabelMismatch Action `mapstructure:"label-mismatch"`
+
+	// SeriesTTL is the duration after which the last value of a cumulative
+	// timeseries not seen again is forgotten.
+	SeriesTTL time.Duration `mapstructure:"series-ttl"`
+}
@@ -0,0 +1,101 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricvalidationprocessor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "metric-validation"
+
+	defaultSeriesTTL = 15 * time.Minute
+)
+
+// Factory is the factory for the metric validation processor.
+type Factory struct {
+}
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the processor.
+func (f *Factory) CreateDefaultConfig() configmodels.Processor {
+	return &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		NonFinite:            DROP,
+		DecreasingCumulative: DROP,
+		LabelMismatch:        DROP,
+		SeriesTTL:            defaultSeriesTTL,
+	}
+}
+
+// CreateTraceProcessor creates a trace processor based on this config.
+func (f *Factory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	// Metric validation processor does not support traces.
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// CreateMetricsProcessor creates a metrics processor based on this config.
+func (f *Factory) CreateMetricsProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Processor,
+) (processor.MetricsProcessor, error) {
+	oCfg := *cfg.(*Config)
+	var err error
+	if oCfg.NonFinite, err = parseAction(oCfg.Name(), "non-finite", oCfg.NonFinite, DROP, ZERO, KEEP); err != nil {
+		return nil, err
+	}
+	if oCfg.DecreasingCumulative, err = parseAction(oCfg.Name(), "decreasing-cumulative", oCfg.DecreasingCumulative, DROP, RESET, KEEP); err != nil {
+		return nil, err
+	}
+	if oCfg.LabelMismatch, err = parseAction(oCfg.Name(), "label-mismatch", oCfg.LabelMismatch, DROP, PAD); err != nil {
+		return nil, err
+	}
+	if oCfg.SeriesTTL <= 0 {
+		return nil, fmt.Errorf("error creating %q processor: \"series-ttl\" must be positive", oCfg.Name())
+	}
+	return newValidationProcessor(logger, nextConsumer, oCfg)
+}
+
+func parseAction(name, setting string, action Action, supported ...Action) (Action, error) {
+	action = Action(strings.ToLower(string(action)))
+	for _, s := range supported {
+		if action == s {
+			return action, nil
+		}
+	}
+	return "", fmt.Errorf("error creating %q processor due to unsupported %q action %q", name, setting, action)
+}
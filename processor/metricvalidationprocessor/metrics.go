@@ -0,0 +1,59 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricvalidationprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+var (
+	tagReasonKey, _ = tag.NewKey("reason")
+
+	statInvalidPointsDropped = stats.Int64("invalid_points_dropped", "Number of invalid metric points dropped", stats.UnitDimensionless)
+	statInvalidPointsFixed   = stats.Int64("invalid_points_fixed", "Number of invalid metric points fixed", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to the metric validation
+// processor.
+func MetricViews(level telemetry.Level) []*view.View {
+	if processor.MetricTagKeys(level) == nil {
+		return nil
+	}
+
+	processorTagKeys := []tag.Key{processor.TagExporterNameKey, tagReasonKey}
+
+	droppedView := &view.View{
+		Name:        statInvalidPointsDropped.Name(),
+		Measure:     statInvalidPointsDropped,
+		Description: statInvalidPointsDropped.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	fixedView := &view.View{
+		Name:        statInvalidPointsFixed.Name(),
+		Measure:     statInvalidPointsFixed,
+		Description: statInvalidPointsFixed.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{droppedView, fixedView}
+}
@@ -0,0 +1,474 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metricvalidationprocessor validates the metrics passing through it
+// and fixes or drops the invalid points, so malformed data doesn't reach the
+// exporters.
+package metricvalidationprocessor
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// The reasons for which the points are dropped or fixed.
+const (
+	reasonNil                  = "nil"
+	reasonInvalidDescriptor    = "invalid_descriptor"
+	reasonLabelMismatch        = "label_mismatch"
+	reasonTypeMismatch         = "type_mismatch"
+	reasonNonFinite            = "non_finite"
+	reasonInvalidBuckets       = "invalid_buckets"
+	reasonDecreasingCumulative = "decreasing_cumulative"
+)
+
+type validationProcessor struct {
+	name         string
+	logger       *zap.Logger
+	nextConsumer consumer.MetricsConsumer
+	cfg          Config
+	now          func() time.Time
+
+	// mu protects the state of the cumulative timeseries.
+	mu        sync.Mutex
+	series    map[string]*seriesState
+	lastSweep time.Time
+}
+
+// seriesState is the last value of a cumulative timeseries.
+type seriesState struct {
+	start *timestamp.Timestamp
+	// resetStart is the start time replacing start after a reset.
+	resetStart *timestamp.Timestamp
+	value      float64
+	timestamp  *timestamp.Timestamp
+	seen       time.Time
+}
+
+var _ processor.MetricsProcessor = (*validationProcessor)(nil)
+
+func newValidationProcessor(logger *zap.Logger, nextConsumer consumer.MetricsConsumer, cfg Config) (*validationProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &validationProcessor{
+		name:         cfg.Name(),
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		cfg:          cfg,
+		now:          time.Now,
+		series:       make(map[string]*seriesState),
+	}, nil
+}
+
+// diagnostics counts the points dropped and fixed in a batch, per reason.
+type diagnostics struct {
+	dropped map[string]int
+	fixed   map[string]int
+	// metrics holds the name of the first metric affected, per reason.
+	metrics map[string]string
+}
+
+func newDiagnostics() *diagnostics {
+	return &diagnostics{
+		dropped: make(map[string]int),
+		fixed:   make(map[string]int),
+		metrics: make(map[string]string),
+	}
+}
+
+func (d *diagnostics) drop(reason, metric string, points int) {
+	if points == 0 {
+		return
+	}
+	d.dropped[reason] += points
+	d.note(reason, metric)
+}
+
+func (d *diagnostics) fix(reason, metric string) {
+	d.fixed[reason]++
+	d.note(reason, metric)
+}
+
+func (d *diagnostics) note(reason, metric string) {
+	if _, ok := d.metrics[reason]; !ok {
+		d.metrics[reason] = metric
+	}
+}
+
+// ConsumeMetricsData forwards the batch without its invalid points, and with
+// the fixable ones fixed. Metrics and timeseries left without points are
+// dropped, batches left empty are not forwarded.
+func (vp *validationProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	diag := newDiagnostics()
+	metrics := make([]*metricspb.Metric, 0, len(md.Metrics))
+	for _, metric := range md.Metrics {
+		if validated := vp.validateMetric(metric, diag); validated != nil {
+			metrics = append(metrics, validated)
+		}
+	}
+	vp.report(ctx, diag)
+
+	if len(metrics) == 0 && len(md.Metrics) > 0 {
+		return nil
+	}
+	md.Metrics = metrics
+	return vp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+func (vp *validationProcessor) report(ctx context.Context, diag *diagnostics) {
+	reasons := make([]string, 0, len(diag.metrics))
+	for reason := range diag.metrics {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		tags := []tag.Mutator{
+			tag.Upsert(processor.TagExporterNameKey, vp.name),
+			tag.Upsert(tagReasonKey, reason),
+		}
+		if n := diag.dropped[reason]; n > 0 {
+			stats.RecordWithTags(ctx, tags, statInvalidPointsDropped.M(int64(n)))
+		}
+		if n := diag.fixed[reason]; n > 0 {
+			stats.RecordWithTags(ctx, tags, statInvalidPointsFixed.M(int64(n)))
+		}
+		vp.logger.Debug("Invalid metric points",
+			zap.String("processor", vp.name),
+			zap.String("reason", reason),
+			zap.String("metric", diag.metrics[reason]),
+			zap.Int("dropped", diag.dropped[reason]),
+			zap.Int("fixed", diag.fixed[reason]))
+	}
+}
+
+// validateMetric returns the metric with its invalid points fixed or dropped,
+// or nil if no point is left. The metric and its timeseries are copied when
+// modified, since receivers may share them between batches.
+func (vp *validationProcessor) validateMetric(metric *metricspb.Metric, diag *diagnostics) *metricspb.Metric {
+	if metric == nil {
+		return nil
+	}
+	desc := metric.MetricDescriptor
+	if desc == nil || desc.Name == "" || desc.Type == metricspb.MetricDescriptor_UNSPECIFIED {
+		diag.drop(reasonInvalidDescriptor, desc.GetName(), countPoints(metric.Timeseries))
+		return nil
+	}
+
+	changed := false
+	timeseries := make([]*metricspb.TimeSeries, 0, len(metric.Timeseries))
+	for _, ts := range metric.Timeseries {
+		validated, tsChanged := vp.validateTimeseries(desc, ts, diag)
+		changed = changed || tsChanged
+		timeseries = append(timeseries, validated...)
+	}
+
+	if !changed {
+		return metric
+	}
+	if len(timeseries) == 0 {
+		return nil
+	}
+	metricCopy := *metric
+	metricCopy.Timeseries = timeseries
+	return &metricCopy
+}
+
+// validateTimeseries returns the timeseries with its invalid points fixed or
+// dropped, split in several timeseries if the start time is moved by a reset,
+// and whether the timeseries was modified.
+func (vp *validationProcessor) validateTimeseries(
+	desc *metricspb.MetricDescriptor,
+	ts *metricspb.TimeSeries,
+	diag *diagnostics,
+) ([]*metricspb.TimeSeries, bool) {
+	if ts == nil {
+		return nil, true
+	}
+
+	changed := false
+	labelValues := ts.LabelValues
+	if len(labelValues) != len(desc.LabelKeys) {
+		if len(labelValues) > len(desc.LabelKeys) || vp.cfg.LabelMismatch == DROP {
+			diag.drop(reasonLabelMismatch, desc.Name, len(ts.Points))
+			return nil, true
+		}
+		labelValues = make([]*metricspb.LabelValue, len(desc.LabelKeys))
+		copy(labelValues, ts.LabelValues)
+		for i := len(ts.LabelValues); i < len(labelValues); i++ {
+			labelValues[i] = &metricspb.LabelValue{}
+		}
+		for range ts.Points {
+			diag.fix(reasonLabelMismatch, desc.Name)
+		}
+		changed = true
+	}
+
+	cumulative := isCumulative(desc.Type)
+	var key string
+	if cumulative {
+		key = seriesKey(desc.Name, labelValues)
+	}
+
+	var out []*metricspb.TimeSeries
+	cur := &metricspb.TimeSeries{StartTimestamp: ts.StartTimestamp, LabelValues: labelValues}
+	for _, point := range ts.Points {
+		validated, reason := vp.validatePoint(desc.Type, point)
+		if validated == nil {
+			diag.drop(reason, desc.Name, 1)
+			changed = true
+			continue
+		}
+		if validated != point {
+			diag.fix(reason, desc.Name)
+			changed = true
+		}
+
+		if cumulative {
+			start, decreased := vp.track(key, ts.StartTimestamp, validated)
+			if decreased {
+				switch vp.cfg.DecreasingCumulative {
+				case DROP:
+					diag.drop(reasonDecreasingCumulative, desc.Name, 1)
+					changed = true
+					continue
+				case RESET:
+					diag.fix(reasonDecreasingCumulative, desc.Name)
+				}
+			}
+			if !timestampsEqual(start, cur.StartTimestamp) {
+				changed = true
+				if len(cur.Points) > 0 {
+					out = append(out, cur)
+				}
+				cur = &metricspb.TimeSeries{StartTimestamp: start, LabelValues: labelValues}
+			}
+		}
+		cur.Points = append(cur.Points, validated)
+	}
+
+	if !changed {
+		return []*metricspb.TimeSeries{ts}, false
+	}
+	if len(cur.Points) > 0 {
+		out = append(out, cur)
+	}
+	return out, true
+}
+
+// validatePoint returns the point, a fixed copy of the point, or nil if the
+// point is dropped, along with the reason of the change.
+func (vp *validationProcessor) validatePoint(typ metricspb.MetricDescriptor_Type, point *metricspb.Point) (*metricspb.Point, string) {
+	if point == nil {
+		return nil, reasonNil
+	}
+
+	switch typ {
+	case metricspb.MetricDescriptor_GAUGE_INT64, metricspb.MetricDescriptor_CUMULATIVE_INT64:
+		if _, ok := point.Value.(*metricspb.Point_Int64Value); !ok {
+			return nil, reasonTypeMismatch
+		}
+
+	case metricspb.MetricDescriptor_GAUGE_DOUBLE, metricspb.MetricDescriptor_CUMULATIVE_DOUBLE:
+		v, ok := point.Value.(*metricspb.Point_DoubleValue)
+		if !ok {
+			return nil, reasonTypeMismatch
+		}
+		if isFinite(v.DoubleValue) {
+			break
+		}
+		switch vp.cfg.NonFinite {
+		case DROP:
+			return nil, reasonNonFinite
+		case ZERO:
+			pointCopy := *point
+			pointCopy.Value = &metricspb.Point_DoubleValue{DoubleValue: 0}
+			return &pointCopy, reasonNonFinite
+		}
+
+	case metricspb.MetricDescriptor_GAUGE_DISTRIBUTION, metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION:
+		v, ok := point.Value.(*metricspb.Point_DistributionValue)
+		if !ok || v.DistributionValue == nil {
+			return nil, reasonTypeMismatch
+		}
+		if !validBuckets(v.DistributionValue) {
+			return nil, reasonInvalidBuckets
+		}
+		if isFinite(v.DistributionValue.Sum) {
+			break
+		}
+		switch vp.cfg.NonFinite {
+		case DROP:
+			return nil, reasonNonFinite
+		case ZERO:
+			distCopy := *v.DistributionValue
+			distCopy.Sum = 0
+			pointCopy := *point
+			pointCopy.Value = &metricspb.Point_DistributionValue{DistributionValue: &distCopy}
+			return &pointCopy, reasonNonFinite
+		}
+
+	case metricspb.MetricDescriptor_SUMMARY:
+		v, ok := point.Value.(*metricspb.Point_SummaryValue)
+		if !ok || v.SummaryValue == nil {
+			return nil, reasonTypeMismatch
+		}
+		if v.SummaryValue.Sum == nil || isFinite(v.SummaryValue.Sum.Value) {
+			break
+		}
+		switch vp.cfg.NonFinite {
+		case DROP:
+			return nil, reasonNonFinite
+		case ZERO:
+			summaryCopy := *v.SummaryValue
+			summaryCopy.Sum = &wrappers.DoubleValue{Value: 0}
+			pointCopy := *point
+			pointCopy.Value = &metricspb.Point_SummaryValue{SummaryValue: &summaryCopy}
+			return &pointCopy, reasonNonFinite
+		}
+	}
+	return point, ""
+}
+
+// track records the value of a point of a cumulative timeseries. It returns
+// the start time of the point, moved if the timeseries was reset, and whether
+// the value decreased while the start time is unchanged.
+func (vp *validationProcessor) track(key string, start *timestamp.Timestamp, point *metricspb.Point) (*timestamp.Timestamp, bool) {
+	value := cumulativeValue(point)
+	now := vp.now()
+
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	vp.sweep(now)
+	state, ok := vp.series[key]
+	if !ok || !timestampsEqual(state.start, start) {
+		vp.series[key] = &seriesState{start: start, value: value, timestamp: point.Timestamp, seen: now}
+		return start, false
+	}
+	state.seen = now
+
+	decreased := value < state.value
+	if decreased {
+		switch vp.cfg.DecreasingCumulative {
+		case DROP:
+			// The value the next points are compared with is unchanged.
+			return start, true
+		case RESET:
+			state.resetStart = state.timestamp
+		}
+	}
+	state.value = value
+	state.timestamp = point.Timestamp
+	if state.resetStart != nil {
+		return state.resetStart, decreased
+	}
+	return start, decreased
+}
+
+// sweep forgets the timeseries not seen for the TTL, at most once per TTL.
+func (vp *validationProcessor) sweep(now time.Time) {
+	if now.Sub(vp.lastSweep) < vp.cfg.SeriesTTL {
+		return
+	}
+	vp.lastSweep = now
+	for key, state := range vp.series {
+		if now.Sub(state.seen) >= vp.cfg.SeriesTTL {
+			delete(vp.series, key)
+		}
+	}
+}
+
+func isCumulative(typ metricspb.MetricDescriptor_Type) bool {
+	switch typ {
+	case metricspb.MetricDescriptor_CUMULATIVE_INT64,
+		metricspb.MetricDescriptor_CUMULATIVE_DOUBLE,
+		metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION:
+		return true
+	}
+	return false
+}
+
+func cumulativeValue(point *metricspb.Point) float64 {
+	switch v := point.Value.(type) {
+	case *metricspb.Point_Int64Value:
+		return float64(v.Int64Value)
+	case *metricspb.Point_DoubleValue:
+		return v.DoubleValue
+	case *metricspb.Point_DistributionValue:
+		return float64(v.DistributionValue.Count)
+	}
+	return 0
+}
+
+// validBuckets checks that the buckets match the explicit bounds, which must
+// be increasing.
+func validBuckets(dist *metricspb.DistributionValue) bool {
+	bounds := dist.GetBucketOptions().GetExplicit().GetBounds()
+	for i := 1; i < len(bounds); i++ {
+		if !(bounds[i] > bounds[i-1]) {
+			return false
+		}
+	}
+	if len(dist.Buckets) == 0 {
+		return true
+	}
+	return len(dist.Buckets) == len(bounds)+1
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func timestampsEqual(a, b *timestamp.Timestamp) bool {
+	return a.GetSeconds() == b.GetSeconds() && a.GetNanos() == b.GetNanos()
+}
+
+func seriesKey(name string, labelValues []*metricspb.LabelValue) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, lv := range labelValues {
+		b.WriteByte(0)
+		if lv.GetHasValue() {
+			b.WriteByte(1)
+			b.WriteString(lv.Value)
+		}
+	}
+	return b.String()
+}
+
+func countPoints(timeseries []*metricspb.TimeSeries) int {
+	n := 0
+	for _, ts := range timeseries {
+		n += len(ts.GetPoints())
+	}
+	return n
+}
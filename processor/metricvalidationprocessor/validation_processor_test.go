@@ -0,0 +1,338 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricvalidationprocessor
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+func testConfig() Config {
+	return *(&Factory{}).CreateDefaultConfig().(*Config)
+}
+
+func newTestProcessor(t *testing.T, cfg Config) (*validationProcessor, *exportertest.SinkMetricsExporter) {
+	sink := new(exportertest.SinkMetricsExporter)
+	vp, err := newValidationProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+	return vp, sink
+}
+
+func ts(seconds int64) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: seconds}
+}
+
+func doublePoint(seconds int64, v float64) *metricspb.Point {
+	return &metricspb.Point{Timestamp: ts(seconds), Value: &metricspb.Point_DoubleValue{DoubleValue: v}}
+}
+
+func int64Point(seconds, v int64) *metricspb.Point {
+	return &metricspb.Point{Timestamp: ts(seconds), Value: &metricspb.Point_Int64Value{Int64Value: v}}
+}
+
+func labelValues(values ...string) []*metricspb.LabelValue {
+	lvs := make([]*metricspb.LabelValue, 0, len(values))
+	for _, v := range values {
+		lvs = append(lvs, &metricspb.LabelValue{Value: v, HasValue: true})
+	}
+	return lvs
+}
+
+func metric(name string, typ metricspb.MetricDescriptor_Type, labelKeys []string, timeseries ...*metricspb.TimeSeries) *metricspb.Metric {
+	keys := make([]*metricspb.LabelKey, 0, len(labelKeys))
+	for _, k := range labelKeys {
+		keys = append(keys, &metricspb.LabelKey{Key: k})
+	}
+	return &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{Name: name, Type: typ, LabelKeys: keys},
+		Timeseries:       timeseries,
+	}
+}
+
+func consume(t *testing.T, vp *validationProcessor, metrics ...*metricspb.Metric) {
+	require.NoError(t, vp.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{Metrics: metrics}))
+}
+
+func TestValidationProcessor_NilNextConsumer(t *testing.T) {
+	vp, err := newValidationProcessor(zap.NewNop(), nil, testConfig())
+	assert.Error(t, err)
+	assert.Nil(t, vp)
+}
+
+func TestValidationProcessor_ValidUnchanged(t *testing.T) {
+	vp, sink := newTestProcessor(t, testConfig())
+
+	gauge := metric("gauge", metricspb.MetricDescriptor_GAUGE_DOUBLE, []string{"host"}, &metricspb.TimeSeries{
+		LabelValues: labelValues("a"),
+		Points:      []*metricspb.Point{doublePoint(1, 1.5), doublePoint(2, 0.5)},
+	})
+	counter := metric("counter", metricspb.MetricDescriptor_CUMULATIVE_INT64, nil, &metricspb.TimeSeries{
+		StartTimestamp: ts(0),
+		Points:         []*metricspb.Point{int64Point(1, 1), int64Point(2, 1), int64Point(3, 5)},
+	})
+	consume(t, vp, gauge, counter)
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 2)
+	assert.True(t, gauge == got[0].Metrics[0], "valid metrics must not be copied")
+	assert.True(t, counter == got[0].Metrics[1], "valid metrics must not be copied")
+}
+
+func TestValidationProcessor_InvalidDescriptor(t *testing.T) {
+	vp, sink := newTestProcessor(t, testConfig())
+
+	series := &metricspb.TimeSeries{Points: []*metricspb.Point{doublePoint(1, 1)}}
+	valid := metric("valid", metricspb.MetricDescriptor_GAUGE_DOUBLE, nil, series)
+	consume(t, vp,
+		nil,
+		&metricspb.Metric{Timeseries: []*metricspb.TimeSeries{series}},
+		metric("", metricspb.MetricDescriptor_GAUGE_DOUBLE, nil, series),
+		metric("unspecified", metricspb.MetricDescriptor_UNSPECIFIED, nil, series),
+		valid,
+	)
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, []*metricspb.Metric{valid}, got[0].Metrics)
+}
+
+func TestValidationProcessor_TypeMismatch(t *testing.T) {
+	vp, sink := newTestProcessor(t, testConfig())
+
+	input := metric("gauge", metricspb.MetricDescriptor_GAUGE_INT64, nil, &metricspb.TimeSeries{
+		Points: []*metricspb.Point{int64Point(1, 1), doublePoint(2, 2), nil, {Timestamp: ts(3)}},
+	})
+	consume(t, vp, input)
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 1)
+	assert.Equal(t, []*metricspb.Point{int64Point(1, 1)}, got[0].Metrics[0].Timeseries[0].Points)
+	assert.Len(t, input.Timeseries[0].Points, 4, "the input metric must not be modified")
+}
+
+func TestValidationProcessor_LabelMismatch(t *testing.T) {
+	missing := &metricspb.TimeSeries{LabelValues: labelValues("a"), Points: []*metricspb.Point{doublePoint(1, 1)}}
+	extra := &metricspb.TimeSeries{LabelValues: labelValues("a", "b", "c"), Points: []*metricspb.Point{doublePoint(1, 2)}}
+	input := metric("gauge", metricspb.MetricDescriptor_GAUGE_DOUBLE, []string{"host", "zone"}, missing, extra)
+
+	vp, sink := newTestProcessor(t, testConfig())
+	consume(t, vp, input)
+	assert.Len(t, sink.AllMetrics(), 0, "batches left empty must not be forwarded")
+
+	cfg := testConfig()
+	cfg.LabelMismatch = PAD
+	vp, sink = newTestProcessor(t, cfg)
+	consume(t, vp, input)
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics[0].Timeseries, 1)
+	assert.Equal(t, &metricspb.TimeSeries{
+		LabelValues: []*metricspb.LabelValue{{Value: "a", HasValue: true}, {}},
+		Points:      []*metricspb.Point{doublePoint(1, 1)},
+	}, got[0].Metrics[0].Timeseries[0])
+	assert.Len(t, missing.LabelValues, 1, "the input timeseries must not be modified")
+}
+
+func TestValidationProcessor_NonFinite(t *testing.T) {
+	newInput := func() []*metricspb.Metric {
+		return []*metricspb.Metric{
+			metric("gauge", metricspb.MetricDescriptor_GAUGE_DOUBLE, nil, &metricspb.TimeSeries{
+				Points: []*metricspb.Point{doublePoint(1, math.NaN()), doublePoint(2, math.Inf(1)), doublePoint(3, 3)},
+			}),
+			metric("distribution", metricspb.MetricDescriptor_GAUGE_DISTRIBUTION, nil, &metricspb.TimeSeries{
+				Points: []*metricspb.Point{{
+					Timestamp: ts(1),
+					Value:     &metricspb.Point_DistributionValue{DistributionValue: &metricspb.DistributionValue{Count: 1, Sum: math.Inf(-1)}},
+				}},
+			}),
+			metric("summary", metricspb.MetricDescriptor_SUMMARY, nil, &metricspb.TimeSeries{
+				Points: []*metricspb.Point{{
+					Timestamp: ts(1),
+					Value:     &metricspb.Point_SummaryValue{SummaryValue: &metricspb.SummaryValue{Sum: &wrappers.DoubleValue{Value: math.NaN()}}},
+				}},
+			}),
+		}
+	}
+
+	vp, sink := newTestProcessor(t, testConfig())
+	consume(t, vp, newInput()...)
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 1)
+	assert.Equal(t, []*metricspb.Point{doublePoint(3, 3)}, got[0].Metrics[0].Timeseries[0].Points)
+
+	cfg := testConfig()
+	cfg.NonFinite = ZERO
+	vp, sink = newTestProcessor(t, cfg)
+	input := newInput()
+	consume(t, vp, input...)
+	got = sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 3)
+	assert.Equal(t, []*metricspb.Point{doublePoint(1, 0), doublePoint(2, 0), doublePoint(3, 3)}, got[0].Metrics[0].Timeseries[0].Points)
+	assert.Equal(t, 0.0, got[0].Metrics[1].Timeseries[0].Points[0].GetDistributionValue().Sum)
+	assert.Equal(t, 0.0, got[0].Metrics[2].Timeseries[0].Points[0].GetSummaryValue().Sum.Value)
+	assert.True(t, math.IsNaN(input[0].Timeseries[0].Points[0].GetDoubleValue()), "the input points must not be modified")
+
+	cfg.NonFinite = KEEP
+	vp, sink = newTestProcessor(t, cfg)
+	input = newInput()
+	consume(t, vp, input...)
+	got = sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, input, got[0].Metrics)
+}
+
+func TestValidationProcessor_InvalidBuckets(t *testing.T) {
+	distribution := func(bounds []float64, buckets int) *metricspb.Point {
+		return &metricspb.Point{
+			Timestamp: ts(1),
+			Value: &metricspb.Point_DistributionValue{DistributionValue: &metricspb.DistributionValue{
+				BucketOptions: &metricspb.DistributionValue_BucketOptions{
+					Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+						Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: bounds},
+					},
+				},
+				Buckets: make([]*metricspb.DistributionValue_Bucket, buckets),
+			}},
+		}
+	}
+	valid := distribution([]float64{1, 2}, 3)
+	vp, sink := newTestProcessor(t, testConfig())
+	consume(t, vp, metric("distribution", metricspb.MetricDescriptor_GAUGE_DISTRIBUTION, nil, &metricspb.TimeSeries{
+		Points: []*metricspb.Point{valid, distribution([]float64{1, 2}, 2), distribution([]float64{2, 1}, 3)},
+	}))
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, []*metricspb.Point{valid}, got[0].Metrics[0].Timeseries[0].Points)
+}
+
+func TestValidationProcessor_DecreasingCumulative(t *testing.T) {
+	newInput := func() *metricspb.Metric {
+		return metric("counter", metricspb.MetricDescriptor_CUMULATIVE_INT64, nil, &metricspb.TimeSeries{
+			StartTimestamp: ts(0),
+			Points:         []*metricspb.Point{int64Point(1, 5), int64Point(2, 3), int64Point(3, 6)},
+		})
+	}
+
+	vp, sink := newTestProcessor(t, testConfig())
+	consume(t, vp, newInput())
+	// The points are compared across batches.
+	consume(t, vp, metric("counter", metricspb.MetricDescriptor_CUMULATIVE_INT64, nil, &metricspb.TimeSeries{
+		StartTimestamp: ts(0),
+		Points:         []*metricspb.Point{int64Point(4, 4), int64Point(5, 7)},
+	}))
+	got := sink.AllMetrics()
+	require.Len(t, got, 2)
+	assert.Equal(t, []*metricspb.Point{int64Point(1, 5), int64Point(3, 6)}, got[0].Metrics[0].Timeseries[0].Points)
+	assert.Equal(t, []*metricspb.Point{int64Point(5, 7)}, got[1].Metrics[0].Timeseries[0].Points)
+
+	// A new start time is a legitimate reset.
+	consume(t, vp, metric("counter", metricspb.MetricDescriptor_CUMULATIVE_INT64, nil, &metricspb.TimeSeries{
+		StartTimestamp: ts(5),
+		Points:         []*metricspb.Point{int64Point(6, 1)},
+	}))
+	got = sink.AllMetrics()
+	require.Len(t, got, 3)
+	assert.Equal(t, []*metricspb.Point{int64Point(6, 1)}, got[2].Metrics[0].Timeseries[0].Points)
+
+	cfg := testConfig()
+	cfg.DecreasingCumulative = RESET
+	vp, sink = newTestProcessor(t, cfg)
+	consume(t, vp, newInput())
+	consume(t, vp, metric("counter", metricspb.MetricDescriptor_CUMULATIVE_INT64, nil, &metricspb.TimeSeries{
+		StartTimestamp: ts(0),
+		Points:         []*metricspb.Point{int64Point(4, 7)},
+	}))
+	got = sink.AllMetrics()
+	require.Len(t, got, 2)
+	assert.Equal(t, []*metricspb.TimeSeries{
+		{StartTimestamp: ts(0), Points: []*metricspb.Point{int64Point(1, 5)}},
+		{StartTimestamp: ts(1), Points: []*metricspb.Point{int64Point(2, 3), int64Point(3, 6)}},
+	}, got[0].Metrics[0].Timeseries)
+	assert.Equal(t, []*metricspb.TimeSeries{
+		{StartTimestamp: ts(1), Points: []*metricspb.Point{int64Point(4, 7)}},
+	}, got[1].Metrics[0].Timeseries)
+
+	cfg.DecreasingCumulative = KEEP
+	vp, sink = newTestProcessor(t, cfg)
+	input := newInput()
+	consume(t, vp, input)
+	got = sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.True(t, input == got[0].Metrics[0])
+}
+
+func TestValidationProcessor_SeriesTTL(t *testing.T) {
+	now := time.Unix(1571000000, 0)
+	cfg := testConfig()
+	cfg.SeriesTTL = time.Minute
+	vp, sink := newTestProcessor(t, cfg)
+	vp.now = func() time.Time { return now }
+
+	counter := func(seconds, v int64) *metricspb.Metric {
+		return metric("counter", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, nil, &metricspb.TimeSeries{
+			StartTimestamp: ts(0),
+			Points:         []*metricspb.Point{doublePoint(seconds, float64(v))},
+		})
+	}
+	consume(t, vp, counter(1, 5))
+	now = now.Add(2 * time.Minute)
+	consume(t, vp, counter(2, 3))
+	assert.Len(t, sink.AllMetrics(), 2, "the forgotten timeseries must not be compared")
+}
+
+func TestValidationProcessor_RecordsStats(t *testing.T) {
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	cfg := testConfig()
+	cfg.NameVal = "metric-validation/recorded"
+	cfg.NonFinite = ZERO
+	vp, _ := newTestProcessor(t, cfg)
+	consume(t, vp, metric("gauge", metricspb.MetricDescriptor_GAUGE_DOUBLE, nil, &metricspb.TimeSeries{
+		Points: []*metricspb.Point{doublePoint(1, math.NaN()), int64Point(2, 1), int64Point(3, 1)},
+	}))
+
+	for name, want := range map[string]float64{
+		statInvalidPointsDropped.Name(): 2,
+		statInvalidPointsFixed.Name():   1,
+	} {
+		rows, err := view.RetrieveData(name)
+		require.NoError(t, err)
+		require.Len(t, rows, 1, name)
+		assert.Equal(t, "metric-validation/recorded", rows[0].Tags[0].Value)
+		assert.Equal(t, want, rows[0].Data.(*view.SumData).Value, name)
+	}
+}
@@ -42,4 +42,9 @@ type Config struct {
 	// from a node after which the batcher for that node will be deleted. This is an
 	// advanced configuration option.
 	RemoveAfterTicks *int `mapstructure:"remove-after-ticks,omitempty"`
+
+	// BatchByAttributes lists span attributes, e.g. a tenant ID, whose values
+	// split the batches further than the node and resource: the spans of a
+	// batch have the same values for these attributes.
+	BatchByAttributes []string `mapstructure:"batch-by-attributes,omitempty"`
 }
@@ -53,10 +53,11 @@ func TestLoadConfig(t *testing.T) {
 				TypeVal: "batch",
 				NameVal: "batch/2",
 			},
-			Timeout:          &timeout,
-			NumTickers:       10,
-			RemoveAfterTicks: &removeAfterTicks,
-			SendBatchSize:    &sendBatchSize,
-			TickTime:         &tickTime,
+			Timeout:           &timeout,
+			NumTickers:        10,
+			RemoveAfterTicks:  &removeAfterTicks,
+			SendBatchSize:     &sendBatchSize,
+			TickTime:          &tickTime,
+			BatchByAttributes: []string{"tenant.id"},
 		})
 }
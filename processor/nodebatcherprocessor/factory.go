@@ -37,6 +37,11 @@ func (f *Factory) Type() string {
 	return typeStr
 }
 
+// Capabilities returns the capabilities of the processor, it batches the data before it is queued.
+func (f *Factory) Capabilities() processor.Capabilities {
+	return processor.Capabilities{Batcher: true}
+}
+
 // CreateDefaultConfig creates the default configuration for processor.
 func (f *Factory) CreateDefaultConfig() configmodels.Processor {
 	removeAfterTicks := int(defaultRemoveAfterCycles)
@@ -90,6 +95,12 @@ func (f *Factory) CreateTraceProcessor(
 		)
 	}
 
+	if len(cfg.BatchByAttributes) > 0 {
+		batchingOptions = append(
+			batchingOptions, WithBatchByAttributes(cfg.BatchByAttributes),
+		)
+	}
+
 	return NewBatcher(cfg.NameVal, logger, nextConsumer, batchingOptions...), nil
 }
 
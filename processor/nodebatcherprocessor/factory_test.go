@@ -20,6 +20,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-service/processor"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -29,6 +31,11 @@ func TestCreateDefaultConfig(t *testing.T) {
 	assert.NotNil(t, cfg, "failed to create default config")
 }
 
+func TestCapabilities(t *testing.T) {
+	factory := &Factory{}
+	assert.Equal(t, processor.Capabilities{Batcher: true}, factory.Capabilities())
+}
+
 func TestCreateProcessor(t *testing.T) {
 	factory := &Factory{}
 
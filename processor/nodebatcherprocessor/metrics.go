@@ -30,6 +30,7 @@ var (
 
 	statBatchSizeTriggerSend = stats.Int64("batch_size_trigger_send", "Number of times the batch was sent due to a size trigger", stats.UnitDimensionless)
 	statTimeoutTriggerSend   = stats.Int64("timeout_trigger_send", "Number of times the batch was sent due to a timeout trigger", stats.UnitDimensionless)
+	statShutdownTriggerSend  = stats.Int64("shutdown_trigger_send", "Number of times the batch was sent due to the shutdown of the batcher", stats.UnitDimensionless)
 	statBatchOnDeadNode      = stats.Int64("removed_node_send", "Number of times the batch was sent due to spans being added for a no longer active node", stats.UnitDimensionless)
 )
 
@@ -88,6 +89,14 @@ func MetricViews(level telemetry.Level) []*view.View {
 		Aggregation: view.Sum(),
 	}
 
+	countShutdownTriggerSendView := &view.View{
+		Name:        statShutdownTriggerSend.Name(),
+		Measure:     statShutdownTriggerSend,
+		Description: statShutdownTriggerSend.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.Sum(),
+	}
+
 	countBatchOnDeadNode := &view.View{
 		Name:        statBatchOnDeadNode.Name(),
 		Measure:     statBatchOnDeadNode,
@@ -102,6 +111,7 @@ func MetricViews(level telemetry.Level) []*view.View {
 		nodesRemovedFromBatchesView,
 		countBatchSizeTriggerSendView,
 		countTimeoutTriggerSendView,
+		countShutdownTriggerSendView,
 		countBatchOnDeadNode,
 	}
 }
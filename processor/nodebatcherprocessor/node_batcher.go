@@ -19,6 +19,8 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -56,9 +58,9 @@ const (
 // Third is a bucketTicker that ticks every so often and closes any open and not recently sent batches.
 //
 // When we no longer have to batch by node, the following changes should be made:
-//   1) batcher should be removed and nodebatcher should be promoted to batcher
-//   2) bucketTicker should be simplified significantly and replaced with a single ticker, since
-//      tracking by node is no longer needed.
+//  1. batcher should be removed and nodebatcher should be promoted to batcher
+//  2. bucketTicker should be simplified significantly and replaced with a single ticker, since
+//     tracking by node is no longer needed.
 type batcher struct {
 	buckets sync.Map
 	sender  consumer.TraceConsumer
@@ -71,9 +73,11 @@ type batcher struct {
 	numTickers        int
 	tickTime          time.Duration
 	timeout           time.Duration
+	batchByAttributes []string
 }
 
 var _ consumer.TraceConsumer = (*batcher)(nil)
+var _ processor.Shutdowner = (*batcher)(nil)
 
 // NewBatcher creates a new batcher that batches spans by node and resource
 func NewBatcher(name string, logger *zap.Logger, sender consumer.TraceConsumer, opts ...Option) consumer.TraceConsumer {
@@ -104,11 +108,75 @@ func NewBatcher(name string, logger *zap.Logger, sender consumer.TraceConsumer,
 // batches
 func (b *batcher) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
 	bucketID := b.genBucketID(td.Node, td.Resource, td.SourceFormat)
-	bucket := b.getOrAddBucket(bucketID, td.Node, td.Resource, td.SourceFormat)
-	bucket.add(td.Spans)
+	if len(b.batchByAttributes) == 0 {
+		bucket := b.getOrAddBucket(bucketID, td.Node, td.Resource, td.SourceFormat)
+		bucket.add(td.Spans)
+		return nil
+	}
+
+	// Group the spans by the values of the attributes, keeping their order.
+	var keys []string
+	groups := make(map[string][]*tracepb.Span)
+	for _, span := range td.Spans {
+		key := b.attributesKey(span)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], span)
+	}
+	for _, key := range keys {
+		bucket := b.getOrAddBucket(bucketID+key, td.Node, td.Resource, td.SourceFormat)
+		bucket.add(groups[key])
+	}
 	return nil
 }
 
+// Shutdown stops the tickers and sends the pending batches of all the nodes.
+func (b *batcher) Shutdown(ctx context.Context) error {
+	for _, bt := range b.tickers {
+		bt.stop()
+	}
+
+	var err error
+	b.buckets.Range(func(key, value interface{}) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		nb := value.(*nodeBatch)
+		nb.mu.Lock()
+		itemsToProcess, itemCount := nb.getAndReset()
+		nb.mu.Unlock()
+		if len(itemsToProcess) > 0 {
+			nb.sendItems(itemsToProcess, itemCount, statShutdownTriggerSend)
+		}
+		return true
+	})
+	return err
+}
+
+// attributesKey returns the values of the batchByAttributes attributes of the
+// span, as a bucket ID suffix.
+func (b *batcher) attributesKey(span *tracepb.Span) string {
+	var key strings.Builder
+	attributes := span.GetAttributes().GetAttributeMap()
+	for _, name := range b.batchByAttributes {
+		// A missing attribute has no type prefix so it doesn't match an empty
+		// value.
+		key.WriteByte(0)
+		switch v := attributes[name].GetValue().(type) {
+		case *tracepb.AttributeValue_StringValue:
+			key.WriteString("s" + v.StringValue.GetValue())
+		case *tracepb.AttributeValue_IntValue:
+			key.WriteString("i" + strconv.FormatInt(v.IntValue, 10))
+		case *tracepb.AttributeValue_BoolValue:
+			key.WriteString("b" + strconv.FormatBool(v.BoolValue))
+		case *tracepb.AttributeValue_DoubleValue:
+			key.WriteString("d" + strconv.FormatFloat(v.DoubleValue, 'g', -1, 64))
+		}
+	}
+	return key.String()
+}
+
 func (b *batcher) genBucketID(node *commonpb.Node, resource *resourcepb.Resource, spanFormat string) string {
 	h := sha256.New()
 	if node != nil {
@@ -250,6 +318,7 @@ type bucketTicker struct {
 	pendingNodes chan string
 	stopCn       chan struct{}
 	once         sync.Once
+	stopOnce     sync.Once
 }
 
 func newStartedBucketTickersForBatch(b *batcher) []*bucketTicker {
@@ -330,5 +399,5 @@ func (bt *bucketTicker) processNodeBatch(nbKey string, nb *nodeBatch) {
 }
 
 func (bt *bucketTicker) stop() {
-	close(bt.stopCn)
+	bt.stopOnce.Do(func() { close(bt.stopCn) })
 }
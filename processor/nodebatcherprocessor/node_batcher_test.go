@@ -17,6 +17,7 @@ package nodebatcherprocessor
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -302,6 +303,68 @@ func TestConcurrentBatchAdds(t *testing.T) {
 	}
 }
 
+func TestBatchByAttributes(t *testing.T) {
+	sender := newTestSender()
+	batcher := NewBatcher("test", zap.NewNop(), sender,
+		WithBatchByAttributes([]string{"tenant"}),
+		WithTimeout(10*time.Millisecond),
+		WithTickTime(10*time.Millisecond),
+	).(*batcher)
+	defer func() {
+		for _, ticker := range batcher.tickers {
+			ticker.stop()
+		}
+	}()
+
+	tenantSpan := func(name string, tenant *tracepb.AttributeValue) *tracepb.Span {
+		span := &tracepb.Span{Name: &tracepb.TruncatableString{Value: name}}
+		if tenant != nil {
+			span.Attributes = &tracepb.Span_Attributes{
+				AttributeMap: map[string]*tracepb.AttributeValue{"tenant": tenant},
+			}
+		}
+		return span
+	}
+	stringValue := func(v string) *tracepb.AttributeValue {
+		return &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: v}},
+		}
+	}
+	request := consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans: []*tracepb.Span{
+			tenantSpan("a1", stringValue("a")),
+			tenantSpan("b1", stringValue("b")),
+			tenantSpan("a2", stringValue("a")),
+			tenantSpan("none", nil),
+			tenantSpan("empty", stringValue("")),
+		},
+		SourceFormat: "oc_trace",
+	}
+	if err := batcher.ConsumeTraceData(context.Background(), request); err != nil {
+		t.Fatalf("ConsumeTraceData() error = %v", err)
+	}
+
+	batches := make(map[string]int)
+	for spans := 0; spans < len(request.Spans); {
+		select {
+		case td := <-sender.reqChan:
+			var names []string
+			for _, span := range td.Spans {
+				names = append(names, span.Name.Value)
+			}
+			batches[fmt.Sprint(names)]++
+			spans += len(td.Spans)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for spans, got %v", batches)
+		}
+	}
+	want := map[string]int{"[a1 a2]": 1, "[b1]": 1, "[none]": 1, "[empty]": 1}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("got batches %v, want %v", batches, want)
+	}
+}
+
 func BenchmarkConcurrentBatchAdds(b *testing.B) {
 	sender1 := newNopSender()
 	batcher := NewBatcher("test", zap.NewNop(), sender1).(*batcher)
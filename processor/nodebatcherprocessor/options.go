@@ -61,3 +61,11 @@ func WithRemoveAfterTicks(cycles int) Option {
 		b.removeAfterCycles = uint32(cycles)
 	}
 }
+
+// WithBatchByAttributes sets the span attributes whose values split the
+// batches, in addition to the node and resource.
+func WithBatchByAttributes(keys []string) Option {
+	return func(b *batcher) {
+		b.batchByAttributes = keys
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodefilterprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// NodeAttribute matches the nodes having an attribute.
+type NodeAttribute struct {
+	// Key is the key of the attribute.
+	Key string `mapstructure:"key"`
+
+	// Value is the value the attribute must have, any value matches if it is
+	// empty.
+	Value string `mapstructure:"value"`
+}
+
+// Config defines configuration for the node filter processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Services are the names of the services whose data is dropped.
+	Services []string `mapstructure:"services"`
+
+	// Attributes drop the data of the nodes having any of the attributes. The
+	// attributes are listed rather than mapped by key since the keys usually
+	// contain dots, which are not supported in the keys of the settings.
+	Attributes []NodeAttribute `mapstructure:"attributes"`
+}
@@ -0,0 +1,56 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodefilterprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+var (
+	statFilteredSpans      = stats.Int64("node_filtered_spans", "Number of spans dropped because their node matched the filter", stats.UnitDimensionless)
+	statFilteredTimeSeries = stats.Int64("node_filtered_timeseries", "Number of timeseries dropped because their node matched the filter", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to the node filter processor.
+func MetricViews(level telemetry.Level) []*view.View {
+	if processor.MetricTagKeys(level) == nil {
+		return nil
+	}
+
+	processorTagKeys := []tag.Key{processor.TagExporterNameKey}
+
+	filteredSpansView := &view.View{
+		Name:        statFilteredSpans.Name(),
+		Measure:     statFilteredSpans,
+		Description: statFilteredSpans.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	filteredTimeSeriesView := &view.View{
+		Name:        statFilteredTimeSeries.Name(),
+		Measure:     statFilteredTimeSeries,
+		Description: statFilteredTimeSeries.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{filteredSpansView, filteredTimeSeriesView}
+}
@@ -0,0 +1,30 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodefilterprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+func TestMetricViews(t *testing.T) {
+	assert.Nil(t, MetricViews(telemetry.None))
+	for _, level := range []telemetry.Level{telemetry.Minimal, telemetry.Basic, telemetry.Normal, telemetry.Detailed} {
+		assert.Len(t, MetricViews(level), 2, "level %v", level)
+	}
+}
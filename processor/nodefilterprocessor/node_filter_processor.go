@@ -0,0 +1,154 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nodefilterprocessor drops the data of the nodes matching rules, e.g.
+// of decommissioned or noisy services. The node is checked once per batch,
+// so the data is dropped before any per-span or per-metric work.
+package nodefilterprocessor
+
+import (
+	"context"
+	"fmt"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// nodeFilter holds the rules shared by the trace and metrics processors.
+type nodeFilter struct {
+	name       string
+	logger     *zap.Logger
+	services   map[string]bool
+	attributes []NodeAttribute
+	statsTags  []tag.Mutator
+}
+
+func newNodeFilter(logger *zap.Logger, cfg Config) (nodeFilter, error) {
+	if len(cfg.Services) == 0 && len(cfg.Attributes) == 0 {
+		return nodeFilter{}, fmt.Errorf("error creating %q processor: \"services\" or \"attributes\" must not be empty", cfg.Name())
+	}
+	services := make(map[string]bool, len(cfg.Services))
+	for _, service := range cfg.Services {
+		if service == "" {
+			return nodeFilter{}, fmt.Errorf("error creating %q processor: empty service name", cfg.Name())
+		}
+		services[service] = true
+	}
+	for i, attr := range cfg.Attributes {
+		if attr.Key == "" {
+			return nodeFilter{}, fmt.Errorf("error creating %q processor: missing \"key\" of the %d-th attribute", cfg.Name(), i)
+		}
+	}
+	return nodeFilter{
+		name:       cfg.Name(),
+		logger:     logger,
+		services:   services,
+		attributes: cfg.Attributes,
+		statsTags:  []tag.Mutator{tag.Upsert(processor.TagExporterNameKey, cfg.Name())},
+	}, nil
+}
+
+// matches returns true if the data of the node must be dropped: its service
+// is listed or it has any of the attributes.
+func (f *nodeFilter) matches(node *commonpb.Node) bool {
+	if node == nil {
+		return false
+	}
+	if f.services[node.GetServiceInfo().GetName()] {
+		return true
+	}
+	for _, attr := range f.attributes {
+		value, ok := node.Attributes[attr.Key]
+		if ok && (attr.Value == "" || attr.Value == value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *nodeFilter) recordDropped(ctx context.Context, node *commonpb.Node, measure *stats.Int64Measure, dropped int) {
+	stats.RecordWithTags(ctx, f.statsTags, measure.M(int64(dropped)))
+	f.logger.Debug("Dropped the data of a filtered node",
+		zap.String("processor", f.name),
+		zap.String("service", processor.ServiceNameForNode(node)),
+		zap.String("measure", measure.Name()),
+		zap.Int("count", dropped))
+}
+
+type nodeFilterTraceProcessor struct {
+	nodeFilter
+	nextConsumer consumer.TraceConsumer
+}
+
+var _ processor.TraceProcessor = (*nodeFilterTraceProcessor)(nil)
+
+func newNodeFilterTraceProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer, cfg Config) (*nodeFilterTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	f, err := newNodeFilter(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeFilterTraceProcessor{nodeFilter: f, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeTraceData drops the batch if its node matches the filter, otherwise
+// it is forwarded unchanged.
+func (fp *nodeFilterTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	if fp.matches(td.Node) {
+		fp.recordDropped(ctx, td.Node, statFilteredSpans, len(td.Spans))
+		return nil
+	}
+	return fp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+type nodeFilterMetricsProcessor struct {
+	nodeFilter
+	nextConsumer consumer.MetricsConsumer
+}
+
+var _ processor.MetricsProcessor = (*nodeFilterMetricsProcessor)(nil)
+
+func newNodeFilterMetricsProcessor(logger *zap.Logger, nextConsumer consumer.MetricsConsumer, cfg Config) (*nodeFilterMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	f, err := newNodeFilter(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeFilterMetricsProcessor{nodeFilter: f, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeMetricsData drops the batch if its node matches the filter, otherwise
+// it is forwarded unchanged.
+func (fp *nodeFilterMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	if fp.matches(md.Node) {
+		timeseries := 0
+		for _, metric := range md.Metrics {
+			timeseries += len(metric.GetTimeseries())
+		}
+		fp.recordDropped(ctx, md.Node, statFilteredTimeSeries, timeseries)
+		return nil
+	}
+	return fp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
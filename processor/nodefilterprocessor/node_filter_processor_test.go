@@ -0,0 +1,122 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodefilterprocessor
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+func testConfig() Config {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Services = []string{"legacy"}
+	cfg.Attributes = []NodeAttribute{
+		{Key: "env", Value: "staging"},
+		{Key: "debug"},
+	}
+	return *cfg
+}
+
+func node(service string, attrs map[string]string) *commonpb.Node {
+	return &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: service},
+		Attributes:  attrs,
+	}
+}
+
+func TestNodeFilter_Matches(t *testing.T) {
+	f, err := newNodeFilter(zap.NewNop(), testConfig())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		node *commonpb.Node
+		want bool
+	}{
+		{name: "nil node"},
+		{name: "no service info", node: &commonpb.Node{}},
+		{name: "other service", node: node("frontend", nil)},
+		{name: "listed service", node: node("legacy", nil), want: true},
+		{name: "attribute value", node: node("frontend", map[string]string{"env": "staging"}), want: true},
+		{name: "other attribute value", node: node("frontend", map[string]string{"env": "prod"})},
+		{name: "any attribute value", node: node("frontend", map[string]string{"debug": ""}), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, f.matches(tt.node))
+		})
+	}
+}
+
+func TestNodeFilterTraceProcessor(t *testing.T) {
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	sink := new(exportertest.SinkTraceExporter)
+	fp, err := newNodeFilterTraceProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+
+	spans := []*tracepb.Span{{}, {}}
+	kept := consumerdata.TraceData{Node: node("frontend", nil), Spans: spans}
+	require.NoError(t, fp.ConsumeTraceData(context.Background(), kept))
+	dropped := consumerdata.TraceData{Node: node("legacy", nil), Spans: spans}
+	require.NoError(t, fp.ConsumeTraceData(context.Background(), dropped))
+
+	assert.Equal(t, []consumerdata.TraceData{kept}, sink.AllTraces())
+
+	rows, err := view.RetrieveData(statFilteredSpans.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 2.0, rows[0].Data.(*view.SumData).Value)
+}
+
+func TestNodeFilterMetricsProcessor(t *testing.T) {
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	sink := new(exportertest.SinkMetricsExporter)
+	fp, err := newNodeFilterMetricsProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+
+	metrics := []*metricspb.Metric{
+		{Timeseries: []*metricspb.TimeSeries{{}, {}}},
+		{Timeseries: []*metricspb.TimeSeries{{}}},
+	}
+	kept := consumerdata.MetricsData{Node: node("frontend", nil), Metrics: metrics}
+	require.NoError(t, fp.ConsumeMetricsData(context.Background(), kept))
+	dropped := consumerdata.MetricsData{Node: node("frontend", map[string]string{"debug": "true"}), Metrics: metrics}
+	require.NoError(t, fp.ConsumeMetricsData(context.Background(), dropped))
+
+	assert.Equal(t, []consumerdata.MetricsData{kept}, sink.AllMetrics())
+
+	rows, err := view.RetrieveData(statFilteredTimeSeries.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 3.0, rows[0].Data.(*view.SumData).Value)
+}
@@ -0,0 +1,57 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the plugin processor. Exactly one of
+// Command and Endpoint must be set.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Command is the path of the plugin executable, the service runs it and
+	// restarts it when it exits.
+	Command string `mapstructure:"command"`
+
+	// Args are the arguments of the plugin executable.
+	Args []string `mapstructure:"args"`
+
+	// Env are the "KEY=value" environment variables of the plugin, added to
+	// the environment of the service.
+	Env []string `mapstructure:"env"`
+
+	// Endpoint is the address of a plugin run separately, e.g. in a sidecar
+	// container.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// StartTimeout is the maximum duration between the start of the plugin
+	// and its handshake.
+	StartTimeout time.Duration `mapstructure:"start-timeout"`
+
+	// Timeout is the maximum duration of the processing of a batch by the
+	// plugin.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// HealthCheckInterval is the interval between two health checks of the
+	// plugin, the batches fail while the plugin is not healthy.
+	HealthCheckInterval time.Duration `mapstructure:"health-check-interval"`
+
+	// RestartDelay is the delay before restarting a plugin that exited.
+	RestartDelay time.Duration `mapstructure:"restart-delay"`
+}
@@ -0,0 +1,105 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "plugin"
+
+	defaultStartTimeout        = 10 * time.Second
+	defaultTimeout             = 5 * time.Second
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultRestartDelay        = time.Second
+)
+
+// Factory is the factory for the plugin processor.
+type Factory struct {
+}
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the processor.
+func (f *Factory) CreateDefaultConfig() configmodels.Processor {
+	return &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		StartTimeout:        defaultStartTimeout,
+		Timeout:             defaultTimeout,
+		HealthCheckInterval: defaultHealthCheckInterval,
+		RestartDelay:        defaultRestartDelay,
+	}
+}
+
+// CreateTraceProcessor creates a trace processor based on this config. It
+// starts the plugin process, if any.
+func (f *Factory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	oCfg := cfg.(*Config)
+	if err := validate(oCfg); err != nil {
+		return nil, err
+	}
+	tp, err := newPluginTraceProcessor(logger, nextConsumer, *oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
+
+// CreateMetricsProcessor creates a metrics processor based on this config. It
+// starts the plugin process, if any.
+func (f *Factory) CreateMetricsProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Processor,
+) (processor.MetricsProcessor, error) {
+	oCfg := cfg.(*Config)
+	if err := validate(oCfg); err != nil {
+		return nil, err
+	}
+	mp, err := newPluginMetricsProcessor(logger, nextConsumer, *oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return mp, nil
+}
+
+func validate(cfg *Config) error {
+	if (cfg.Command == "") == (cfg.Endpoint == "") {
+		return fmt.Errorf("%q config requires either a \"command\" or an \"endpoint\"", cfg.Name())
+	}
+	if cfg.StartTimeout <= 0 || cfg.Timeout <= 0 || cfg.HealthCheckInterval <= 0 || cfg.RestartDelay <= 0 {
+		return fmt.Errorf("%q config requires positive durations", cfg.Name())
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprocessor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/open-telemetry/opentelemetry-service/processor/pluginprocessor/pluginprotocol"
+)
+
+// stopTimeout is the delay given to the plugin process to exit once its
+// standard input is closed, before it is killed.
+const stopTimeout = 5 * time.Second
+
+var errPluginUnavailable = errors.New("plugin unavailable")
+
+// process is a running plugin process.
+type process struct {
+	cmd *exec.Cmd
+	// stdin is the standard input of the process, closed to stop it.
+	stdin io.Closer
+	// output is done once the outputs of the process are read.
+	output sync.WaitGroup
+}
+
+// wait waits for the process to exit.
+func (pr *process) wait() error {
+	pr.output.Wait()
+	return pr.cmd.Wait()
+}
+
+// plugin manages the connection to a plugin and, when the service runs it,
+// the plugin process. The process is restarted when it exits and it exits
+// itself when the service exits, its standard input being closed.
+type plugin struct {
+	logger *zap.Logger
+	cfg    Config
+
+	mu      sync.Mutex
+	conn    *grpc.ClientConn
+	client  pluginprotocol.ProcessorClient
+	healthy bool
+	proc    *process
+	stopped bool
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func startPlugin(logger *zap.Logger, cfg Config) (*plugin, error) {
+	p := &plugin{
+		logger: logger.With(zap.String("processor", cfg.Name())),
+		cfg:    cfg,
+		done:   make(chan struct{}),
+	}
+
+	if cfg.Endpoint != "" {
+		conn, err := p.dial("tcp", cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		p.setConn(conn, nil)
+	} else {
+		proc, conn, err := p.launch()
+		if err != nil {
+			return nil, err
+		}
+		p.setConn(conn, proc)
+		p.wg.Add(1)
+		go p.supervise(proc)
+	}
+
+	p.wg.Add(1)
+	go p.checkHealth()
+	return p, nil
+}
+
+func (p *plugin) dial(network, address string) (*grpc.ClientConn, error) {
+	return grpc.Dial(address, grpc.WithInsecure(), grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout(network, addr, timeout)
+	}))
+}
+
+// launch starts the plugin process and connects to it once it completed its
+// handshake.
+func (p *plugin) launch() (*process, *grpc.ClientConn, error) {
+	cmd := exec.Command(p.cfg.Command, p.cfg.Args...)
+	cmd.Env = append(os.Environ(), p.cfg.Env...)
+	cmd.Env = append(cmd.Env,
+		pluginprotocol.MagicCookieKey+"="+pluginprotocol.MagicCookieValue,
+		pluginprotocol.ProtocolVersionKey+"="+strconv.Itoa(pluginprotocol.ProtocolVersion))
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("cannot start plugin %q: %v", p.cfg.Command, err)
+	}
+	proc := &process{cmd: cmd, stdin: stdin}
+
+	// The output of the plugin after the handshake is logged.
+	handshake := make(chan string, 1)
+	proc.output.Add(2)
+	go func() {
+		defer proc.output.Done()
+		scanner := bufio.NewScanner(stdout)
+		if scanner.Scan() {
+			handshake <- scanner.Text()
+		}
+		close(handshake)
+		p.logOutput(scanner)
+	}()
+	go func() {
+		defer proc.output.Done()
+		p.logOutput(bufio.NewScanner(stderr))
+	}()
+
+	fail := func(err error) (*process, *grpc.ClientConn, error) {
+		_ = cmd.Process.Kill()
+		_ = proc.wait()
+		return nil, nil, err
+	}
+	timer := time.NewTimer(p.cfg.StartTimeout)
+	defer timer.Stop()
+	var line string
+	select {
+	case l, ok := <-handshake:
+		if !ok {
+			return fail(fmt.Errorf("plugin %q exited before its handshake", p.cfg.Command))
+		}
+		line = l
+	case <-timer.C:
+		return fail(fmt.Errorf("plugin %q did not complete its handshake in %v", p.cfg.Command, p.cfg.StartTimeout))
+	}
+	network, address, err := pluginprotocol.ParseHandshake(line)
+	if err != nil {
+		return fail(err)
+	}
+	conn, err := p.dial(network, address)
+	if err != nil {
+		return fail(err)
+	}
+	p.logger.Info("Plugin started", zap.String("command", p.cfg.Command), zap.Int("pid", cmd.Process.Pid))
+	return proc, conn, nil
+}
+
+func (p *plugin) logOutput(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		p.logger.Info("Plugin output", zap.String("line", scanner.Text()))
+	}
+}
+
+// supervise restarts the plugin process when it exits.
+func (p *plugin) supervise(proc *process) {
+	defer p.wg.Done()
+	for {
+		err := proc.wait()
+		p.setConn(nil, nil)
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		p.logger.Error("Plugin exited, restarting it", zap.Error(err))
+
+		for proc = nil; proc == nil; {
+			timer := time.NewTimer(p.cfg.RestartDelay)
+			select {
+			case <-timer.C:
+			case <-p.done:
+				timer.Stop()
+				return
+			}
+			var conn *grpc.ClientConn
+			if proc, conn, err = p.launch(); err != nil {
+				p.logger.Error("Failed to restart the plugin", zap.Error(err))
+				continue
+			}
+			if !p.setConn(conn, proc) {
+				// Stopped while restarting.
+				conn.Close()
+				_ = proc.cmd.Process.Kill()
+				_ = proc.wait()
+				return
+			}
+		}
+	}
+}
+
+// checkHealth checks periodically the health of the plugin.
+func (p *plugin) checkHealth() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+
+		p.mu.Lock()
+		conn := p.conn
+		p.mu.Unlock()
+		if conn == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: pluginprotocol.ServiceName})
+		cancel()
+		healthy := err == nil && resp.Status == healthpb.HealthCheckResponse_SERVING
+
+		p.mu.Lock()
+		if conn == p.conn && healthy != p.healthy {
+			p.healthy = healthy
+			if healthy {
+				p.logger.Info("Plugin is healthy")
+			} else {
+				p.logger.Warn("Plugin is not healthy", zap.Error(err))
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// setConn replaces the connection to the plugin, considered healthy until its
+// next health check. It returns false if a connection was set after stop.
+func (p *plugin) setConn(conn *grpc.ClientConn, proc *process) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped && conn != nil {
+		return false
+	}
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn, p.proc = conn, proc
+	p.client = nil
+	if conn != nil {
+		p.client = pluginprotocol.NewProcessorClient(conn)
+	}
+	p.healthy = conn != nil
+	return true
+}
+
+// processorClient returns the client of the plugin or errPluginUnavailable
+// if the plugin is not running or not healthy.
+func (p *plugin) processorClient() (pluginprotocol.ProcessorClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil || !p.healthy {
+		return nil, errPluginUnavailable
+	}
+	return p.client, nil
+}
+
+// stop stops the plugin process, if any, and the health checks. The process
+// is killed if it does not exit in time once its standard input is closed.
+func (p *plugin) stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+		p.mu.Lock()
+		p.stopped = true
+		proc := p.proc
+		p.mu.Unlock()
+		if proc != nil {
+			proc.stdin.Close()
+			timer := time.AfterFunc(stopTimeout, func() { _ = proc.cmd.Process.Kill() })
+			defer timer.Stop()
+		}
+		p.wg.Wait()
+		p.setConn(nil, nil)
+	})
+}
+
+// Shutdown stops the plugin. The plugin keeps stopping in the background if
+// ctx is done first.
+func (p *plugin) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		p.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
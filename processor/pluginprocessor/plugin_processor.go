@@ -0,0 +1,152 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprocessor
+
+import (
+	"context"
+	"sync"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// unsupported forwards the batches unmodified when the plugin does not support
+// their data type, logging it once.
+type unsupported struct {
+	once sync.Once
+}
+
+func (u *unsupported) check(logger *zap.Logger, err error) bool {
+	if status.Code(err) != codes.Unimplemented {
+		return false
+	}
+	u.once.Do(func() {
+		logger.Warn("Plugin does not support the data type of the pipeline, forwarding the data unmodified", zap.Error(err))
+	})
+	return true
+}
+
+type pluginTraceProcessor struct {
+	*plugin
+	unsupported
+	nextConsumer consumer.TraceConsumer
+}
+
+var (
+	_ processor.TraceProcessor = (*pluginTraceProcessor)(nil)
+	_ processor.Shutdowner     = (*pluginTraceProcessor)(nil)
+)
+
+func newPluginTraceProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer, cfg Config) (*pluginTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	p, err := startPlugin(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginTraceProcessor{plugin: p, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeTraceData sends the batch to the plugin and the batch it returns to
+// the next consumer, unless it has no spans.
+func (ptp *pluginTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	client, err := ptp.processorClient()
+	if err != nil {
+		return err
+	}
+	pluginCtx, cancel := context.WithTimeout(ctx, ptp.cfg.Timeout)
+	resp, err := client.ProcessTraces(pluginCtx, &agenttracepb.ExportTraceServiceRequest{
+		Node:     td.Node,
+		Resource: td.Resource,
+		Spans:    td.Spans,
+	})
+	cancel()
+	if err != nil {
+		if ptp.check(ptp.logger, err) {
+			return ptp.nextConsumer.ConsumeTraceData(ctx, td)
+		}
+		return err
+	}
+	if len(resp.Spans) == 0 {
+		return nil
+	}
+	return ptp.nextConsumer.ConsumeTraceData(ctx, consumerdata.TraceData{
+		Node:         resp.Node,
+		Resource:     resp.Resource,
+		Spans:        resp.Spans,
+		SourceFormat: td.SourceFormat,
+	})
+}
+
+type pluginMetricsProcessor struct {
+	*plugin
+	unsupported
+	nextConsumer consumer.MetricsConsumer
+}
+
+var (
+	_ processor.MetricsProcessor = (*pluginMetricsProcessor)(nil)
+	_ processor.Shutdowner       = (*pluginMetricsProcessor)(nil)
+)
+
+func newPluginMetricsProcessor(logger *zap.Logger, nextConsumer consumer.MetricsConsumer, cfg Config) (*pluginMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	p, err := startPlugin(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginMetricsProcessor{plugin: p, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeMetricsData sends the batch to the plugin and the batch it returns
+// to the next consumer, unless it has no metrics.
+func (pmp *pluginMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	client, err := pmp.processorClient()
+	if err != nil {
+		return err
+	}
+	pluginCtx, cancel := context.WithTimeout(ctx, pmp.cfg.Timeout)
+	resp, err := client.ProcessMetrics(pluginCtx, &agentmetricspb.ExportMetricsServiceRequest{
+		Node:     md.Node,
+		Resource: md.Resource,
+		Metrics:  md.Metrics,
+	})
+	cancel()
+	if err != nil {
+		if pmp.check(pmp.logger, err) {
+			return pmp.nextConsumer.ConsumeMetricsData(ctx, md)
+		}
+		return err
+	}
+	if len(resp.Metrics) == 0 {
+		return nil
+	}
+	return pmp.nextConsumer.ConsumeMetricsData(ctx, consumerdata.MetricsData{
+		Node:     resp.Node,
+		Resource: resp.Resource,
+		Metrics:  resp.Metrics,
+	})
+}
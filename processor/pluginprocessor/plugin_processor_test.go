@@ -0,0 +1,213 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor/pluginprocessor/pluginprotocol"
+)
+
+// testPlugin drops the spans named "drop", upper-cases the names of the
+// others and does not support metrics.
+type testPlugin struct{}
+
+func (testPlugin) ProcessTraces(ctx context.Context, td consumerdata.TraceData) (consumerdata.TraceData, error) {
+	var spans []*tracepb.Span
+	for _, span := range td.Spans {
+		if span.Name.Value == "drop" {
+			continue
+		}
+		span.Name.Value = strings.ToUpper(span.Name.Value)
+		spans = append(spans, span)
+	}
+	td.Spans = spans
+	return td, nil
+}
+
+func (testPlugin) ProcessMetrics(ctx context.Context, md consumerdata.MetricsData) (consumerdata.MetricsData, error) {
+	return md, pluginprotocol.ErrNotSupported
+}
+
+// TestHelperPlugin is not a test, it is the plugin process run by the tests.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(pluginprotocol.MagicCookieKey) == "" {
+		return
+	}
+	if os.Getenv("PLUGIN_TEST_MODE") == "no-handshake" {
+		time.Sleep(time.Minute)
+	}
+	if err := pluginprotocol.Serve(testPlugin{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func testConfig(env ...string) Config {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Command = os.Args[0]
+	cfg.Args = []string{"-test.run=TestHelperPlugin"}
+	cfg.Env = env
+	cfg.HealthCheckInterval = 10 * time.Millisecond
+	cfg.RestartDelay = 10 * time.Millisecond
+	return *cfg
+}
+
+func testTraceData(names ...string) consumerdata.TraceData {
+	td := consumerdata.TraceData{SourceFormat: "zipkin"}
+	for _, name := range names {
+		td.Spans = append(td.Spans, &tracepb.Span{Name: &tracepb.TruncatableString{Value: name}})
+	}
+	return td
+}
+
+// waitFor polls cond until it returns true or a few seconds elapsed.
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 500 && !cond(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, cond(), "condition not met in time")
+}
+
+func TestPluginTraceProcessor(t *testing.T) {
+	sink := new(exportertest.SinkTraceExporter)
+	ptp, err := newPluginTraceProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+	defer ptp.stop()
+
+	require.NoError(t, ptp.ConsumeTraceData(context.Background(), testTraceData("get", "drop")))
+	require.NoError(t, ptp.ConsumeTraceData(context.Background(), testTraceData("drop")))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "GET", got[0].Spans[0].Name.Value)
+	assert.Equal(t, "zipkin", got[0].SourceFormat)
+}
+
+func TestPluginMetricsProcessor_NotSupported(t *testing.T) {
+	sink := new(exportertest.SinkMetricsExporter)
+	pmp, err := newPluginMetricsProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+	defer pmp.stop()
+
+	md := consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{{MetricDescriptor: &metricspb.MetricDescriptor{Name: "m"}}},
+	}
+	require.NoError(t, pmp.ConsumeMetricsData(context.Background(), md))
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.Equal(t, md, sink.AllMetrics()[0])
+}
+
+func TestPluginProcessor_Restart(t *testing.T) {
+	sink := new(exportertest.SinkTraceExporter)
+	ptp, err := newPluginTraceProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+	defer ptp.stop()
+
+	ptp.mu.Lock()
+	proc := ptp.proc
+	ptp.mu.Unlock()
+	require.NoError(t, proc.cmd.Process.Kill())
+
+	waitFor(t, func() bool {
+		ptp.mu.Lock()
+		defer ptp.mu.Unlock()
+		return ptp.proc != nil && ptp.proc != proc
+	})
+	waitFor(t, func() bool {
+		return ptp.ConsumeTraceData(context.Background(), testTraceData("get")) == nil
+	})
+	assert.Equal(t, "GET", sink.AllTraces()[0].Spans[0].Name.Value)
+}
+
+func TestPluginProcessor_Shutdown(t *testing.T) {
+	ptp, err := newPluginTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), testConfig())
+	require.NoError(t, err)
+	pmp, err := newPluginMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), testConfig())
+	require.NoError(t, err)
+
+	for _, p := range []*plugin{ptp.plugin, pmp.plugin} {
+		p.mu.Lock()
+		proc := p.proc
+		p.mu.Unlock()
+
+		// The plugin exits once its standard input is closed, it is not
+		// restarted.
+		require.NoError(t, p.Shutdown(context.Background()))
+		require.NotNil(t, proc.cmd.ProcessState)
+		assert.True(t, proc.cmd.ProcessState.Success(), proc.cmd.ProcessState.String())
+		p.mu.Lock()
+		assert.Nil(t, p.proc)
+		p.mu.Unlock()
+		_, err := p.processorClient()
+		assert.Equal(t, errPluginUnavailable, err)
+	}
+}
+
+func TestPluginProcessor_Endpoint(t *testing.T) {
+	launched, err := newPluginTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), testConfig())
+	require.NoError(t, err)
+	defer launched.stop()
+
+	cfg := testConfig()
+	cfg.Command = ""
+	cfg.Endpoint = launched.conn.Target()
+	sink := new(exportertest.SinkTraceExporter)
+	ptp, err := newPluginTraceProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+	defer ptp.stop()
+
+	waitFor(t, func() bool {
+		return ptp.ConsumeTraceData(context.Background(), testTraceData("get")) == nil
+	})
+	assert.Equal(t, "GET", sink.AllTraces()[0].Spans[0].Name.Value)
+
+	// The batches fail once the health checks fail.
+	launched.stop()
+	waitFor(t, func() bool {
+		return ptp.ConsumeTraceData(context.Background(), testTraceData("get")) == errPluginUnavailable
+	})
+}
+
+func TestPluginProcessor_StartFailures(t *testing.T) {
+	_, err := newPluginTraceProcessor(zap.NewNop(), nil, testConfig())
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+
+	cfg := testConfig("PLUGIN_TEST_MODE=no-handshake")
+	cfg.StartTimeout = 100 * time.Millisecond
+	_, err = newPluginTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.Error(t, err)
+
+	cfg = testConfig()
+	cfg.Command = "/nonexistent/plugin"
+	_, err = newPluginMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pluginprotocol defines the gRPC protocol between the plugin
+// processor and the plugin processes it runs, and helps writing plugins in Go.
+//
+// The service starts a plugin with the MagicCookieKey and ProtocolVersionKey
+// environment variables set. The plugin serves the Processor gRPC service of
+// processor.proto and the standard gRPC health service, on a local address,
+// then writes the handshake line "<version>|<network>|<address>|grpc" to its
+// standard output. The plugin must exit when its standard input is closed, so
+// that it does not outlive the service.
+package pluginprotocol
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"google.golang.org/grpc"
+)
+
+const (
+	// ServiceName is the name of the gRPC service served by the plugins, also
+	// used for their health checks.
+	ServiceName = "otelsvc.plugin.v1.Processor"
+
+	// ProtocolVersion is the version of the protocol, the plugins must use the
+	// version set in the ProtocolVersionKey environment variable.
+	ProtocolVersion = 1
+
+	// ProtocolVersionKey is the environment variable holding the protocol
+	// version.
+	ProtocolVersionKey = "OTELSVC_PLUGIN_PROTOCOL_VERSION"
+
+	// MagicCookieKey and MagicCookieValue are set in the environment of the
+	// plugins, so that they can detect they are not started by the service.
+	MagicCookieKey = "OTELSVC_PLUGIN_MAGIC_COOKIE"
+	// MagicCookieValue is the value of the MagicCookieKey environment variable.
+	MagicCookieValue = "9f6c17d2a4be4c3a8e7b0d51f2a6e3c8"
+)
+
+// Handshake returns the handshake line of a plugin serving on the given
+// address, without the trailing new line.
+func Handshake(network, address string) string {
+	return fmt.Sprintf("%d|%s|%s|grpc", ProtocolVersion, network, address)
+}
+
+// ParseHandshake returns the address a plugin serves on from its handshake
+// line.
+func ParseHandshake(line string) (network, address string, err error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 {
+		return "", "", fmt.Errorf("invalid plugin handshake %q", line)
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid plugin handshake %q", line)
+	}
+	if version != ProtocolVersion {
+		return "", "", fmt.Errorf("unsupported plugin protocol version %d, expected %d", version, ProtocolVersion)
+	}
+	if parts[1] != "tcp" && parts[1] != "unix" {
+		return "", "", fmt.Errorf("unsupported plugin network %q", parts[1])
+	}
+	if parts[3] != "grpc" {
+		return "", "", fmt.Errorf("unsupported plugin protocol %q", parts[3])
+	}
+	return parts[1], parts[2], nil
+}
+
+// ProcessorClient is the client of the Processor gRPC service.
+type ProcessorClient interface {
+	// ProcessTraces returns the processed batch of spans.
+	ProcessTraces(ctx context.Context, in *agenttracepb.ExportTraceServiceRequest, opts ...grpc.CallOption) (*agenttracepb.ExportTraceServiceRequest, error)
+	// ProcessMetrics returns the processed batch of metrics.
+	ProcessMetrics(ctx context.Context, in *agentmetricspb.ExportMetricsServiceRequest, opts ...grpc.CallOption) (*agentmetricspb.ExportMetricsServiceRequest, error)
+}
+
+type processorClient struct {
+	cc *grpc.ClientConn
+}
+
+// NewProcessorClient creates a client of the Processor gRPC service.
+func NewProcessorClient(cc *grpc.ClientConn) ProcessorClient {
+	return &processorClient{cc: cc}
+}
+
+func (c *processorClient) ProcessTraces(ctx context.Context, in *agenttracepb.ExportTraceServiceRequest, opts ...grpc.CallOption) (*agenttracepb.ExportTraceServiceRequest, error) {
+	out := new(agenttracepb.ExportTraceServiceRequest)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/ProcessTraces", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *processorClient) ProcessMetrics(ctx context.Context, in *agentmetricspb.ExportMetricsServiceRequest, opts ...grpc.CallOption) (*agentmetricspb.ExportMetricsServiceRequest, error) {
+	out := new(agentmetricspb.ExportMetricsServiceRequest)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/ProcessMetrics", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// processorServer is the server of the Processor gRPC service.
+type processorServer interface {
+	ProcessTraces(ctx context.Context, in *agenttracepb.ExportTraceServiceRequest) (*agenttracepb.ExportTraceServiceRequest, error)
+	ProcessMetrics(ctx context.Context, in *agentmetricspb.ExportMetricsServiceRequest) (*agentmetricspb.ExportMetricsServiceRequest, error)
+}
+
+// serviceDesc is the description of the Processor gRPC service of
+// processor.proto.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*processorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessTraces",
+			Handler:    processTracesHandler,
+		},
+		{
+			MethodName: "ProcessMetrics",
+			Handler:    processMetricsHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "processor.proto",
+}
+
+func processTracesHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(agenttracepb.ExportTraceServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(processorServer).ProcessTraces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/ProcessTraces",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(processorServer).ProcessTraces(ctx, req.(*agenttracepb.ExportTraceServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func processMetricsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(agentmetricspb.ExportMetricsServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(processorServer).ProcessMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/ProcessMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(processorServer).ProcessMetrics(ctx, req.(*agentmetricspb.ExportMetricsServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
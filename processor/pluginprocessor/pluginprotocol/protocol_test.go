@@ -0,0 +1,46 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprotocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHandshake(t *testing.T) {
+	network, address, err := ParseHandshake(Handshake("tcp", "127.0.0.1:1234") + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "127.0.0.1:1234", address)
+
+	network, address, err = ParseHandshake("1|unix|/tmp/plugin.sock|grpc")
+	require.NoError(t, err)
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/tmp/plugin.sock", address)
+
+	for _, line := range []string{
+		"",
+		"listening on 127.0.0.1:1234",
+		"x|tcp|127.0.0.1:1234|grpc",
+		"2|tcp|127.0.0.1:1234|grpc",
+		"1|udp|127.0.0.1:1234|grpc",
+		"1|tcp|127.0.0.1:1234|netrpc",
+	} {
+		_, _, err := ParseHandshake(line)
+		assert.Error(t, err, line)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprotocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// ErrNotSupported is returned by the plugins not supporting a data type.
+var ErrNotSupported = errors.New("data type not supported by the plugin")
+
+// Processor is implemented by the plugins written in Go. The returned batch is
+// sent to the next processor of the pipeline, a batch without spans or metrics
+// is dropped.
+type Processor interface {
+	// ProcessTraces returns the processed batch of spans or ErrNotSupported.
+	ProcessTraces(ctx context.Context, td consumerdata.TraceData) (consumerdata.TraceData, error)
+	// ProcessMetrics returns the processed batch of metrics or ErrNotSupported.
+	ProcessMetrics(ctx context.Context, md consumerdata.MetricsData) (consumerdata.MetricsData, error)
+}
+
+// Serve runs the plugin until its standard input is closed. It must be called
+// from the main function of the plugin and fails if the plugin is not run by
+// the service.
+func Serve(p Processor) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return errors.New("this binary is a plugin of the OpenTelemetry Service, it must be run by the service")
+	}
+	if v := os.Getenv(ProtocolVersionKey); v != strconv.Itoa(ProtocolVersion) {
+		return fmt.Errorf("unsupported plugin protocol version %q, expected %d", v, ProtocolVersion)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	return serve(p, lis, os.Stdin, os.Stdout)
+}
+
+func serve(p Processor, lis net.Listener, stdin io.Reader, stdout io.Writer) error {
+	server := grpc.NewServer()
+	server.RegisterService(&serviceDesc, &processorAdapter{p: p})
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Serve(lis)
+	}()
+	if _, err := fmt.Fprintln(stdout, Handshake(lis.Addr().Network(), lis.Addr().String())); err != nil {
+		server.Stop()
+		return err
+	}
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, stdin)
+		healthServer.SetServingStatus(ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+		server.GracefulStop()
+	}()
+	// The server is stopped before serving if the standard input is closed
+	// right after the handshake, it is not a failure of the plugin.
+	if err := <-errc; err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// processorAdapter serves the Processor gRPC service with a Processor.
+type processorAdapter struct {
+	p Processor
+}
+
+var _ processorServer = (*processorAdapter)(nil)
+
+func (pa *processorAdapter) ProcessTraces(ctx context.Context, in *agenttracepb.ExportTraceServiceRequest) (*agenttracepb.ExportTraceServiceRequest, error) {
+	td, err := pa.p.ProcessTraces(ctx, consumerdata.TraceData{Node: in.Node, Resource: in.Resource, Spans: in.Spans})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &agenttracepb.ExportTraceServiceRequest{Node: td.Node, Resource: td.Resource, Spans: td.Spans}, nil
+}
+
+func (pa *processorAdapter) ProcessMetrics(ctx context.Context, in *agentmetricspb.ExportMetricsServiceRequest) (*agentmetricspb.ExportMetricsServiceRequest, error) {
+	md, err := pa.p.ProcessMetrics(ctx, consumerdata.MetricsData{Node: in.Node, Resource: in.Resource, Metrics: in.Metrics})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &agentmetricspb.ExportMetricsServiceRequest{Node: md.Node, Resource: md.Resource, Metrics: md.Metrics}, nil
+}
+
+func toStatus(err error) error {
+	if err == ErrNotSupported {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	return err
+}
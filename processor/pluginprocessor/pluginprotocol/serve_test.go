@@ -0,0 +1,106 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginprotocol
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// upperProcessor upper-cases the span names and does not support metrics.
+type upperProcessor struct{}
+
+func (upperProcessor) ProcessTraces(ctx context.Context, td consumerdata.TraceData) (consumerdata.TraceData, error) {
+	for _, span := range td.Spans {
+		span.Name.Value = strings.ToUpper(span.Name.Value)
+	}
+	return td, nil
+}
+
+func (upperProcessor) ProcessMetrics(ctx context.Context, md consumerdata.MetricsData) (consumerdata.MetricsData, error) {
+	return md, ErrNotSupported
+}
+
+func TestServe(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(upperProcessor{}, lis, stdinReader, stdoutWriter)
+	}()
+
+	line, err := bufio.NewReader(stdoutReader).ReadString('\n')
+	require.NoError(t, err)
+	network, address, err := ParseHandshake(line)
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, lis.Addr().String(), address)
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: ServiceName})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	client := NewProcessorClient(conn)
+	traces, err := client.ProcessTraces(context.Background(), &agenttracepb.ExportTraceServiceRequest{
+		Spans: []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: "get"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, traces.Spans, 1)
+	assert.Equal(t, "GET", traces.Spans[0].Name.Value)
+
+	_, err = client.ProcessMetrics(context.Background(), &agentmetricspb.ExportMetricsServiceRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// Closing the standard input stops the plugin.
+	require.NoError(t, stdinWriter.Close())
+	assert.NoError(t, <-served)
+}
+
+func TestServe_StdinClosed(t *testing.T) {
+	// The service may stop the plugin right after the handshake, before the
+	// server is serving.
+	for i := 0; i < 10; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		assert.NoError(t, serve(upperProcessor{}, lis, strings.NewReader(""), ioutil.Discard))
+	}
+}
+
+func TestServe_NotRunByService(t *testing.T) {
+	assert.Error(t, Serve(upperProcessor{}))
+}
@@ -26,4 +26,8 @@ type Config struct {
 	// have different sampling rates: if they use the same seed all passing one layer may pass the other even if they have
 	// different sampling rates, configuring different seeds avoids that.
 	HashSeed uint32 `mapstructure:"hash-seed"`
+	// Consistent indicates that all the sampling stages of the spans use the same hash seed, so that a span sampled
+	// by a previous stage with a probability lower than SamplingPercentage is always sampled again. The probability of
+	// the spans is then the lowest of the stages instead of their product.
+	Consistent bool `mapstructure:"consistent"`
 }
@@ -46,6 +46,7 @@ func TestLoadConfig(t *testing.T) {
 			},
 			SamplingPercentage: 15.3,
 			HashSeed:           22,
+			Consistent:         true,
 		})
 
 }
@@ -26,12 +26,14 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 )
 
 const (
 	// The constants below are tags used to read the configuration via viper.
 	samplingPercentageCfgTag = "sampling-percentage"
 	hashSeedCfgTag           = "hash-seed"
+	consistentCfgTag         = "consistent"
 
 	// The constants help translate user friendly percentages to numbers direct used in sampling.
 	numHashBuckets        = 0x4000 // Using a power of 2 to avoid division.
@@ -50,6 +52,9 @@ func (tsc *Config) InitFromViper(v *viper.Viper) (*Config, error) {
 	if err := v.UnmarshalKey(hashSeedCfgTag, &tsc.HashSeed); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal %q: %v", hashSeedCfgTag, err)
 	}
+	if err := v.UnmarshalKey(consistentCfgTag, &tsc.Consistent); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %q: %v", consistentCfgTag, err)
+	}
 	return tsc, nil
 }
 
@@ -57,6 +62,7 @@ type tracesamplerprocessor struct {
 	nextConsumer       consumer.TraceConsumer
 	scaledSamplingRate uint32
 	hashSeed           uint32
+	consistent         bool
 }
 
 var _ processor.TraceProcessor = (*tracesamplerprocessor)(nil)
@@ -73,6 +79,7 @@ func NewTraceProcessor(nextConsumer consumer.TraceConsumer, cfg Config) (process
 		// Adjust sampling percentage on private so recalculations are avoided.
 		scaledSamplingRate: uint32(cfg.SamplingPercentage * percentageScaleFactor),
 		hashSeed:           cfg.HashSeed,
+		consistent:         cfg.Consistent,
 	}, nil
 }
 
@@ -88,12 +95,26 @@ func (tsp *tracesamplerprocessor) ConsumeTraceData(ctx context.Context, td consu
 		SourceFormat: td.SourceFormat,
 	}
 
+	// The probability actually applied, the sampling rate is rounded to the hash buckets.
+	probability := float64(scaledSamplingRate) / numHashBuckets
 	sampledSpans := make([]*tracepb.Span, 0, len(td.Spans))
 	for _, span := range td.Spans {
+		previous := samplingProbability(span)
+		if tsp.consistent && previous <= probability {
+			// A previous stage sampled the span with the same hash and a lower probability.
+			sampledSpans = append(sampledSpans, span)
+			continue
+		}
+
 		// If one assumes random trace ids hashing may seems avoidable, however, traces can be coming from sources
 		// with various different criteria to generate trace id and perhaps were already sampled without hashing.
 		// Hashing here prevents bias due to such systems.
 		if hash(span.TraceId, tsp.hashSeed)&bitMaskHashBuckets < scaledSamplingRate {
+			if tsp.consistent {
+				setSamplingProbability(span, probability)
+			} else {
+				setSamplingProbability(span, previous*probability)
+			}
 			sampledSpans = append(sampledSpans, span)
 		}
 	}
@@ -103,6 +124,32 @@ func (tsp *tracesamplerprocessor) ConsumeTraceData(ctx context.Context, td consu
 	return tsp.nextConsumer.ConsumeTraceData(ctx, sampledTraceData)
 }
 
+// samplingProbability returns the probability recorded by a previous sampling stage, 1 if the span was not sampled.
+func samplingProbability(span *tracepb.Span) float64 {
+	attrib, ok := span.GetAttributes().GetAttributeMap()[tracetranslator.TagSamplingProbability]
+	if !ok {
+		return 1
+	}
+	if p := attrib.GetDoubleValue(); p > 0 && p <= 1 {
+		return p
+	}
+	return 1
+}
+
+// setSamplingProbability records the probability with which the span was sampled, so that the spans can be counted
+// downstream and the following sampling stages can take it into account.
+func setSamplingProbability(span *tracepb.Span, probability float64) {
+	if span.Attributes == nil {
+		span.Attributes = &tracepb.Span_Attributes{}
+	}
+	if span.Attributes.AttributeMap == nil {
+		span.Attributes.AttributeMap = make(map[string]*tracepb.AttributeValue, 1)
+	}
+	span.Attributes.AttributeMap[tracetranslator.TagSamplingProbability] = &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: probability},
+	}
+}
+
 // hash is a murmur3 hash function, see http://en.wikipedia.org/wiki/MurmurHash.
 func hash(key []byte, seed uint32) (hash uint32) {
 	const (
@@ -93,6 +93,19 @@ func TestConfig_InitFromViper(t *testing.T) {
 				HashSeed:           1234,
 			},
 		},
+		{
+			name: "happy_path_consistent",
+			genViperFn: func() *viper.Viper {
+				v := viper.New()
+				v.Set(samplingPercentageCfgTag, 10)
+				v.Set(consistentCfgTag, true)
+				return v
+			},
+			want: &Config{
+				SamplingPercentage: 10,
+				Consistent:         true,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -251,6 +264,79 @@ func Test_tracesamplerprocessor_SamplingPercentageRange(t *testing.T) {
 	}
 }
 
+// Test_tracesamplerprocessor_SamplingProbability checks the probability recorded on the sampled spans, taking into
+// account the probability recorded by a previous stage.
+func Test_tracesamplerprocessor_SamplingProbability(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             Config
+		previous        float64
+		wantProbability float64
+		wantSampled     float64
+	}{
+		{
+			name:            "first_stage",
+			cfg:             Config{SamplingPercentage: 50},
+			wantProbability: 0.5,
+			wantSampled:     0.5,
+		},
+		{
+			name:            "independent_stages",
+			cfg:             Config{SamplingPercentage: 50, HashSeed: 1},
+			previous:        0.5,
+			wantProbability: 0.25,
+			wantSampled:     0.5,
+		},
+		{
+			name:            "consistent_lower_probability",
+			cfg:             Config{SamplingPercentage: 25, Consistent: true},
+			previous:        0.5,
+			wantProbability: 0.25,
+			wantSampled:     0.25,
+		},
+		{
+			name:            "consistent_higher_probability",
+			cfg:             Config{SamplingPercentage: 75, Consistent: true},
+			previous:        0.5,
+			wantProbability: 0.5,
+			wantSampled:     1,
+		},
+	}
+	const testSvcName = "test-svc"
+	const numTraces = 1e4
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &exportertest.SinkTraceExporter{}
+			tsp, err := NewTraceProcessor(sink, tt.cfg)
+			if err != nil {
+				t.Fatalf("error when creating tracesamplerprocessor: %v", err)
+			}
+			for _, td := range genRandomTestData(1, numTraces, testSvcName) {
+				if tt.previous != 0 {
+					for _, span := range td.Spans {
+						setSamplingProbability(span, tt.previous)
+					}
+				}
+				if err := tsp.ConsumeTraceData(context.Background(), td); err != nil {
+					t.Fatalf("tracesamplerprocessor.ConsumeTraceData() error = %v", err)
+				}
+			}
+
+			_, sampled := assertSampledData(t, sink.AllTraces(), testSvcName)
+			if delta := math.Abs(float64(sampled)/numTraces - tt.wantSampled); delta > 0.02 {
+				t.Errorf("got %d sampled spans, want about %f of the spans", sampled, tt.wantSampled)
+			}
+			for _, td := range sink.AllTraces() {
+				for _, span := range td.Spans {
+					if got := samplingProbability(span); got != tt.wantProbability {
+						t.Fatalf("got %f sampling probability, want %f", got, tt.wantProbability)
+					}
+				}
+			}
+		})
+	}
+}
+
 // Test_hash ensures that the hash function supports different key lengths even if in
 // practice it is only expected to receive keys with length 16 (trace id length in OC proto).
 func Test_hash(t *testing.T) {
@@ -16,6 +16,8 @@
 package processor
 
 import (
+	"context"
+
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 )
 
@@ -33,6 +35,16 @@ type MetricsProcessor interface {
 	// TODO: Add processor specific functions.
 }
 
+// Shutdowner is implemented by the processors holding data asynchronously,
+// e.g. in batches or queues, so that the data is not lost when the service
+// shuts down, and by the processors running background work.
+type Shutdowner interface {
+	// Shutdown sends the data held by the processor to the next consumer and
+	// stops the processor. The data still held once ctx is done is dropped and
+	// the error of ctx is returned.
+	Shutdown(ctx context.Context) error
+}
+
 // Processor is a data consumer.
 type Processor interface {
 	consumer.DataConsumer
@@ -32,4 +32,15 @@ type Config struct {
 	RetryOnFailure bool `mapstructure:"retry-on-failure"`
 	// BackoffDelay is the amount of time a worker waits after a failed send before retrying.
 	BackoffDelay time.Duration `mapstructure:"backoff-delay"`
+	// CapacityWarningRatio is the ratio of the queue size above which a warning is logged, zero disables the warning.
+	CapacityWarningRatio float64 `mapstructure:"capacity-warning-ratio"`
+	// FailureLogEvery is the number of repeated send failures or dropped batches per log entry after the first one.
+	FailureLogEvery int `mapstructure:"failure-log-every"`
+	// TenantAttribute is the node attribute identifying the tenant of the batches, e.g. set by the virtual
+	// hosts of the receivers. When set every tenant has its own queue and workers, so that the failures or
+	// the bursts of a tenant do not delay the batches of the others.
+	TenantAttribute string `mapstructure:"tenant-attribute"`
+	// MaxTenants is the maximum number of tenants with their own queue, the batches of the other tenants
+	// share the queue of the batches without tenant.
+	MaxTenants int `mapstructure:"max-tenants"`
 }
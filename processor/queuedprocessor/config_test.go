@@ -51,5 +51,10 @@ func TestLoadConfig(t *testing.T) {
 			QueueSize:      10,
 			RetryOnFailure: true,
 			BackoffDelay:   time.Second * 5,
+
+			CapacityWarningRatio: 0.5,
+			FailureLogEvery:      10,
+			TenantAttribute:      "tenant",
+			MaxTenants:           20,
 		})
 }
@@ -38,6 +38,11 @@ func (f *Factory) Type() string {
 	return typeStr
 }
 
+// Capabilities returns the capabilities of the processor, it hands the data to the exporters asynchronously.
+func (f *Factory) Capabilities() processor.Capabilities {
+	return processor.Capabilities{Queue: true}
+}
+
 // CreateDefaultConfig creates the default configuration for exporter.
 func (f *Factory) CreateDefaultConfig() configmodels.Processor {
 	return &Config{
@@ -49,6 +54,10 @@ func (f *Factory) CreateDefaultConfig() configmodels.Processor {
 		QueueSize:      5000,
 		RetryOnFailure: true,
 		BackoffDelay:   time.Second * 5,
+
+		CapacityWarningRatio: 0.8,
+		FailureLogEvery:      100,
+		MaxTenants:           DefaultMaxTenants,
 	}
 }
 
@@ -60,10 +69,16 @@ func (f *Factory) CreateTraceProcessor(
 ) (processor.TraceProcessor, error) {
 	oCfg := cfg.(*Config)
 	return NewQueuedSpanProcessor(nextConsumer,
+		Options.WithName(oCfg.Name()),
+		Options.WithLogger(logger),
 		Options.WithNumWorkers(oCfg.NumWorkers),
 		Options.WithQueueSize(oCfg.QueueSize),
 		Options.WithRetryOnProcessingFailures(oCfg.RetryOnFailure),
 		Options.WithBackoffDelay(oCfg.BackoffDelay),
+		Options.WithCapacityWarningRatio(oCfg.CapacityWarningRatio),
+		Options.WithFailureLogEvery(oCfg.FailureLogEvery),
+		Options.WithTenantAttribute(oCfg.TenantAttribute),
+		Options.WithMaxTenants(oCfg.MaxTenants),
 	), nil
 }
 
@@ -27,6 +27,8 @@ const (
 	DefaultNumWorkers = 10
 	// DefaultQueueSize is the default maximum number of span batches allowed in the processor's queue
 	DefaultQueueSize = 1000
+	// DefaultMaxTenants is the default maximum number of tenants with their own queue
+	DefaultMaxTenants = 100
 )
 
 type options struct {
@@ -37,8 +39,14 @@ type options struct {
 	backoffDelay             time.Duration
 	extraFormatTypes         []string
 	retryOnProcessingFailure bool
+	capacityWarningRatio     float64
+	failureLogEvery          int
 	batchingEnabled          bool
 	batchingOptions          []nodebatcherprocessor.Option
+	tenantAttribute          string
+	maxTenants               int
+	// tenant is the tenant of the queue, set for the queues of the tenants.
+	tenant string
 }
 
 // Option is a function that sets some option on the component.
@@ -96,6 +104,23 @@ func (options) WithRetryOnProcessingFailures(retryOnProcessingFailure bool) Opti
 	}
 }
 
+// WithCapacityWarningRatio creates an Option that initializes the ratio of
+// the queue size above which a warning is logged, zero disables the warning
+func (options) WithCapacityWarningRatio(capacityWarningRatio float64) Option {
+	return func(b *options) {
+		b.capacityWarningRatio = capacityWarningRatio
+	}
+}
+
+// WithFailureLogEvery creates an Option that initializes how often the
+// failures repeated for every batch are logged: the first one, then one out of
+// failureLogEvery, values lower than 2 log all of them
+func (options) WithFailureLogEvery(failureLogEvery int) Option {
+	return func(b *options) {
+		b.failureLogEvery = failureLogEvery
+	}
+}
+
 // WithBatching creates an Option that enabled batching
 func (options) WithBatching(batchingEnabled bool) Option {
 	return func(b *options) {
@@ -111,6 +136,24 @@ func (options) WithBatchingOptions(opts ...nodebatcherprocessor.Option) Option {
 	}
 }
 
+// WithTenantAttribute creates an Option that queues the batches by the value
+// of the node attribute identifying their tenant, each tenant having its own
+// queue and workers
+func (options) WithTenantAttribute(tenantAttribute string) Option {
+	return func(b *options) {
+		b.tenantAttribute = tenantAttribute
+	}
+}
+
+// WithMaxTenants creates an Option that initializes the maximum number of
+// tenants with their own queue, the batches of the other tenants share the
+// queue of the batches without tenant
+func (options) WithMaxTenants(maxTenants int) Option {
+	return func(b *options) {
+		b.maxTenants = maxTenants
+	}
+}
+
 func (o options) apply(opts ...Option) options {
 	ret := options{}
 	for _, opt := range opts {
@@ -125,5 +168,8 @@ func (o options) apply(opts ...Option) options {
 	if ret.queueSize == 0 {
 		ret.queueSize = DefaultQueueSize
 	}
+	if ret.maxTenants == 0 {
+		ret.maxTenants = DefaultMaxTenants
+	}
 	return ret
 }
@@ -17,6 +17,7 @@ package queuedprocessor
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jaegertracing/jaeger/pkg/queue"
@@ -24,28 +25,61 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/internal/logthrottle"
 	"github.com/open-telemetry/opentelemetry-service/processor"
 	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
 )
 
 type queuedSpanProcessor struct {
-	name                     string
+	name string
+	// tenant is the tenant whose batches are queued, empty if the batches
+	// are not queued by tenant or have no tenant.
+	tenant                   string
 	queue                    *queue.BoundedQueue
 	logger                   *zap.Logger
 	sender                   consumer.TraceConsumer
 	numWorkers               int
 	retryOnProcessingFailure bool
 	backoffDelay             time.Duration
+	capacityWarningRatio     float64
 	stopCh                   chan struct{}
 	stopOnce                 sync.Once
+
+	// failureLog throttles the logs repeated for every batch while the
+	// sender keeps failing or the queue stays full.
+	failureLog *logthrottle.Throttler
+
+	// queued holds the items in the queue, to report the age of the oldest
+	// one.
+	queuedMu sync.Mutex
+	queued   map[*queueItem]struct{}
+	// sending is the number of batches being sent by the workers, accessed
+	// atomically.
+	sending int32
+	// aboveWarning is true while the queue length is above the capacity
+	// warning threshold, it is only used by the reporting goroutine.
+	aboveWarning bool
 }
 
 var _ consumer.TraceConsumer = (*queuedSpanProcessor)(nil)
+var _ processor.Shutdowner = (*queuedSpanProcessor)(nil)
+
+// drainCheckInterval is the interval at which the queue is checked while it is
+// drained on shutdown.
+const drainCheckInterval = 10 * time.Millisecond
+
+// Keys of the throttled log entries.
+const (
+	sendFailureLogKey = "send-failure"
+	badDataLogKey     = "bad-data"
+	droppedLogKey     = "dropped"
+)
 
 type queueItem struct {
 	queuedTime time.Time
@@ -58,15 +92,52 @@ type queueItem struct {
 // provided sender
 func NewQueuedSpanProcessor(sender consumer.TraceConsumer, opts ...Option) consumer.TraceConsumer {
 	options := Options.apply(opts...)
-	sp := newQueuedSpanProcessor(sender, options)
+	var next consumer.TraceConsumer
+	if options.tenantAttribute != "" {
+		next = newTenantSpanProcessor(sender, options)
+	} else {
+		sp := newQueuedSpanProcessor(sender, options)
+		sp.start()
+		next = sp
+	}
 
+	if options.batchingEnabled {
+		options.logger.Info("Using queued processor with batching.")
+		batcher := nodebatcherprocessor.NewBatcher(options.name, options.logger, next, options.batchingOptions...)
+		return &batchingSpanProcessor{TraceConsumer: batcher, queued: next.(processor.Shutdowner)}
+	}
+
+	return next
+}
+
+// batchingSpanProcessor batches the spans before they are queued.
+type batchingSpanProcessor struct {
+	consumer.TraceConsumer
+	queued processor.Shutdowner
+}
+
+var _ processor.Shutdowner = (*batchingSpanProcessor)(nil)
+
+// Shutdown sends the pending batches to the queue and then drains it.
+func (bp *batchingSpanProcessor) Shutdown(ctx context.Context) error {
+	if err := bp.TraceConsumer.(processor.Shutdowner).Shutdown(ctx); err != nil {
+		_ = bp.queued.Shutdown(ctx)
+		return err
+	}
+	return bp.queued.Shutdown(ctx)
+}
+
+// start starts the workers consuming the queue and the reporting of its
+// state.
+func (sp *queuedSpanProcessor) start() {
 	sp.queue.StartConsumers(sp.numWorkers, func(item interface{}) {
 		value := item.(*queueItem)
 		sp.processItemFromQueue(value)
 	})
 
-	// Start a timer to report the queue length.
-	ctx, _ := tag.New(context.Background(), tag.Upsert(processor.TagExporterNameKey, sp.name))
+	// Start a timer to report the queue length and the age of the oldest
+	// item.
+	ctx, _ := tag.New(context.Background(), sp.queueTags()...)
 	ticker := time.NewTicker(1 * time.Second)
 	go func(ctx context.Context) {
 		defer ticker.Stop()
@@ -74,33 +145,105 @@ func NewQueuedSpanProcessor(sender consumer.TraceConsumer, opts ...Option) consu
 			select {
 			case <-sp.stopCh:
 				return
-			case <-ticker.C:
-				length := int64(sp.queue.Size())
-				stats.Record(ctx, statQueueLength.M(length))
+			case now := <-ticker.C:
+				sp.reportQueueState(ctx, now)
 			}
 		}
 	}(ctx)
+}
 
-	if options.batchingEnabled {
-		sp.logger.Info("Using queued processor with batching.")
-		batcher := nodebatcherprocessor.NewBatcher(sp.name, sp.logger, sp, options.batchingOptions...)
-		return batcher
+// queueTags returns the tags of the metrics of the queue.
+func (sp *queuedSpanProcessor) queueTags() []tag.Mutator {
+	return []tag.Mutator{
+		tag.Upsert(processor.TagExporterNameKey, sp.name),
+		tag.Upsert(tagTenantKey, sp.tenant),
 	}
+}
 
-	return sp
+// batchTags returns the tags of the metrics of a batch.
+func (sp *queuedSpanProcessor) batchTags(td consumerdata.TraceData) []tag.Mutator {
+	statsTags := processor.StatsTagsForBatch(sp.name, processor.ServiceNameForNode(td.Node), td.SourceFormat)
+	return append(statsTags, tag.Upsert(tagTenantKey, sp.tenant))
 }
 
 func newQueuedSpanProcessor(sender consumer.TraceConsumer, opts options) *queuedSpanProcessor {
 	boundedQueue := queue.NewBoundedQueue(opts.queueSize, func(item interface{}) {})
+	logger := opts.logger
+	if opts.tenantAttribute != "" {
+		logger = logger.With(zap.String("tenant", opts.tenant))
+	}
 	return &queuedSpanProcessor{
 		name:                     opts.name,
+		tenant:                   opts.tenant,
 		queue:                    boundedQueue,
-		logger:                   opts.logger,
+		logger:                   logger,
 		numWorkers:               opts.numWorkers,
 		sender:                   sender,
 		retryOnProcessingFailure: opts.retryOnProcessingFailure,
 		backoffDelay:             opts.backoffDelay,
+		capacityWarningRatio:     opts.capacityWarningRatio,
 		stopCh:                   make(chan struct{}),
+		failureLog:               logthrottle.New(opts.failureLogEvery),
+		queued:                   make(map[*queueItem]struct{}),
+	}
+}
+
+// enqueue adds the item to the queue, it returns false if the queue is full.
+func (sp *queuedSpanProcessor) enqueue(item *queueItem) bool {
+	sp.queuedMu.Lock()
+	sp.queued[item] = struct{}{}
+	sp.queuedMu.Unlock()
+	if sp.queue.Produce(item) {
+		return true
+	}
+
+	sp.queuedMu.Lock()
+	delete(sp.queued, item)
+	sp.queuedMu.Unlock()
+	ctx, _ := tag.New(context.Background(), sp.queueTags()...)
+	stats.Record(ctx, statEnqueueFailures.M(1))
+	return false
+}
+
+// reportQueueState records the queue metrics and logs a warning when the
+// queue length crosses the capacity warning threshold.
+func (sp *queuedSpanProcessor) reportQueueState(ctx context.Context, now time.Time) {
+	length := sp.queue.Size()
+	var oldestAge time.Duration
+	sp.queuedMu.Lock()
+	for item := range sp.queued {
+		if age := now.Sub(item.queuedTime); age > oldestAge {
+			oldestAge = age
+		}
+	}
+	sp.queuedMu.Unlock()
+	stats.Record(ctx,
+		statQueueLength.M(int64(length)),
+		statOldestItemAgeMs.M(int64(oldestAge/time.Millisecond)))
+
+	// The next dropped batch is logged once the queue has room again.
+	if length < sp.queue.Capacity() {
+		sp.failureLog.Reset(droppedLogKey)
+	}
+
+	if sp.capacityWarningRatio <= 0 {
+		return
+	}
+	threshold := int(sp.capacityWarningRatio * float64(sp.queue.Capacity()))
+	switch {
+	case !sp.aboveWarning && length >= threshold:
+		sp.aboveWarning = true
+		sp.logger.Warn("Queue is filling up, batches will be dropped when it is full",
+			zap.String("processor", sp.name),
+			zap.Int("queue-length", length),
+			zap.Int("queue-size", sp.queue.Capacity()),
+			zap.Duration("oldest-item-age", oldestAge))
+	case sp.aboveWarning && length < threshold:
+		sp.aboveWarning = false
+		sp.logger.Info("Queue is back below its warning threshold",
+			zap.String("processor", sp.name),
+			zap.Int("queue-length", length),
+			zap.Int("queue-size", sp.queue.Capacity()))
 	}
 }
 
@@ -112,6 +255,41 @@ func (sp *queuedSpanProcessor) Stop() {
 	})
 }
 
+// Shutdown waits until the queued batches are sent, then stops the processor.
+// The batches still queued once ctx is done are dropped.
+func (sp *queuedSpanProcessor) Shutdown(ctx context.Context) error {
+	err := sp.drain(ctx)
+	if err != nil {
+		sp.logger.Warn("Queue not drained before the shutdown timeout, the queued batches are dropped",
+			zap.String("processor", sp.name),
+			zap.Int("queue-length", sp.queue.Size()))
+	}
+	sp.Stop()
+	return err
+}
+
+// drain waits until the queue is empty and no batch is being sent, or until
+// ctx is done. The batches failing to be sent are retried meanwhile.
+func (sp *queuedSpanProcessor) drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	for !sp.drained() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// drained returns true if the queue is empty and no batch is being sent.
+func (sp *queuedSpanProcessor) drained() bool {
+	sp.queuedMu.Lock()
+	defer sp.queuedMu.Unlock()
+	return len(sp.queued) == 0 && atomic.LoadInt32(&sp.sending) == 0
+}
+
 // ConsumeTraceData implements the SpanProcessor interface
 func (sp *queuedSpanProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
 	item := &queueItem{
@@ -120,11 +298,11 @@ func (sp *queuedSpanProcessor) ConsumeTraceData(ctx context.Context, td consumer
 		ctx:        ctx,
 	}
 
-	statsTags := processor.StatsTagsForBatch(sp.name, processor.ServiceNameForNode(td.Node), td.SourceFormat)
+	statsTags := sp.batchTags(td)
 	numSpans := len(td.Spans)
 	stats.RecordWithTags(context.Background(), statsTags, processor.StatReceivedSpanCount.M(int64(numSpans)))
 
-	addedToQueue := sp.queue.Produce(item)
+	addedToQueue := sp.enqueue(item)
 	if !addedToQueue {
 		sp.onItemDropped(item, statsTags)
 	}
@@ -132,30 +310,43 @@ func (sp *queuedSpanProcessor) ConsumeTraceData(ctx context.Context, td consumer
 }
 
 func (sp *queuedSpanProcessor) processItemFromQueue(item *queueItem) {
+	// The item is counted as being sent before it leaves the queued items, and
+	// a re-enqueued item is queued again before it stops being counted, so
+	// that the queue is never seen drained while it is being processed.
+	atomic.AddInt32(&sp.sending, 1)
+	defer atomic.AddInt32(&sp.sending, -1)
+
+	sp.queuedMu.Lock()
+	delete(sp.queued, item)
+	sp.queuedMu.Unlock()
+
 	startTime := time.Now()
 	err := sp.sender.ConsumeTraceData(item.ctx, item.td)
 	if err == nil {
 		// Record latency metrics and return
 		sendLatencyMs := int64(time.Since(startTime) / time.Millisecond)
 		inQueueLatencyMs := int64(time.Since(item.queuedTime) / time.Millisecond)
-		statsTags := processor.StatsTagsForBatch(sp.name, processor.ServiceNameForNode(item.td.Node), item.td.SourceFormat)
+		statsTags := sp.batchTags(item.td)
 		stats.RecordWithTags(context.Background(),
 			statsTags,
 			statSuccessSendOps.M(1),
 			statSendLatencyMs.M(sendLatencyMs),
 			statInQueueLatencyMs.M(inQueueLatencyMs))
 
+		if failures := sp.failureLog.Reset(sendFailureLogKey); failures > 0 {
+			sp.logger.Info("Sender recovered", zap.String("processor", sp.name), zap.Int64("failures", failures))
+		}
 		return
 	}
 
 	// There was an error
-	statsTags := processor.StatsTagsForBatch(sp.name, processor.ServiceNameForNode(item.td.Node), item.td.SourceFormat)
+	statsTags := sp.batchTags(item.td)
 
 	// Immediately drop data on permanent errors. In this context permanent
 	// errors indicate some kind of bad data.
 	if consumererror.IsPermanent(err) {
 		numSpans := len(item.td.Spans)
-		sp.logger.Warn(
+		sp.failureLog.Log(sp.logger, zapcore.WarnLevel, badDataLogKey,
 			"Unrecoverable bad data error",
 			zap.String("processor", sp.name),
 			zap.Int("#spans", numSpans),
@@ -172,33 +363,51 @@ func (sp *queuedSpanProcessor) processItemFromQueue(item *queueItem) {
 
 	stats.RecordWithTags(context.Background(), statsTags, statFailedSendOps.M(1))
 	batchSize := len(item.td.Spans)
-	sp.logger.Warn("Sender failed", zap.String("processor", sp.name), zap.Error(err), zap.String("spanFormat", item.td.SourceFormat))
+
+	// While the sender keeps failing only the first failure and every Nth
+	// one are logged.
+	logFailure, failures := sp.failureLog.Occur(sendFailureLogKey)
+	if logFailure {
+		sp.logger.Warn("Sender failed",
+			zap.String("processor", sp.name),
+			zap.Error(err),
+			zap.String("spanFormat", item.td.SourceFormat),
+			zap.Int64(logthrottle.OccurrencesKey, failures))
+	}
 	if !sp.retryOnProcessingFailure {
 		// throw away the batch
-		sp.logger.Error("Failed to process batch, discarding", zap.String("processor", sp.name), zap.Int("batch-size", batchSize))
+		if logFailure {
+			sp.logger.Error("Failed to process batch, discarding", zap.String("processor", sp.name), zap.Int("batch-size", batchSize))
+		}
 		sp.onItemDropped(item, statsTags)
 	} else {
 		// TODO: (@pjanotti) do not put it back on the end of the queue, retry with it directly.
 		// This will have the benefit of keeping the batch closer to related ones in time.
-		if !sp.queue.Produce(item) {
-			sp.logger.Error("Failed to process batch and failed to re-enqueue", zap.String("processor", sp.name), zap.Int("batch-size", batchSize))
+		if !sp.enqueue(item) {
+			if logFailure {
+				sp.logger.Error("Failed to process batch and failed to re-enqueue", zap.String("processor", sp.name), zap.Int("batch-size", batchSize))
+			}
 			sp.onItemDropped(item, statsTags)
-		} else {
+		} else if logFailure {
 			sp.logger.Warn("Failed to process batch, re-enqueued", zap.String("processor", sp.name), zap.Int("batch-size", batchSize))
 		}
 	}
 
 	// back-off for configured delay, but get interrupted when shutting down
 	if sp.backoffDelay > 0 {
-		sp.logger.Warn("Backing off before next attempt",
-			zap.String("processor", sp.name),
-			zap.Duration("backoff-delay", sp.backoffDelay))
+		if logFailure {
+			sp.logger.Warn("Backing off before next attempt",
+				zap.String("processor", sp.name),
+				zap.Duration("backoff-delay", sp.backoffDelay))
+		}
 		select {
 		case <-sp.stopCh:
 			sp.logger.Info("Interrupted due to shutdown", zap.String("processor", sp.name))
 			break
 		case <-time.After(sp.backoffDelay):
-			sp.logger.Info("Resume processing", zap.String("processor", sp.name))
+			if logFailure {
+				sp.logger.Info("Resume processing", zap.String("processor", sp.name))
+			}
 			break
 		}
 	}
@@ -208,7 +417,7 @@ func (sp *queuedSpanProcessor) onItemDropped(item *queueItem, statsTags []tag.Mu
 	numSpans := len(item.td.Spans)
 	stats.RecordWithTags(context.Background(), statsTags, processor.StatDroppedSpanCount.M(int64(numSpans)))
 
-	sp.logger.Warn("Span batch dropped",
+	sp.failureLog.Log(sp.logger, zapcore.WarnLevel, droppedLogKey, "Span batch dropped",
 		zap.String("processor", sp.name),
 		zap.Int("#spans", len(item.td.Spans)),
 		zap.String("spanSource", item.td.SourceFormat))
@@ -216,13 +425,17 @@ func (sp *queuedSpanProcessor) onItemDropped(item *queueItem, statsTags []tag.Mu
 
 // Variables related to metrics specific to queued processor.
 var (
+	tagTenantKey, _ = tag.NewKey("tenant")
+
 	statInQueueLatencyMs = stats.Int64("queue_latency", "Latency (in milliseconds) that a batch stayed in queue", stats.UnitMilliseconds)
 	statSendLatencyMs    = stats.Int64("send_latency", "Latency (in milliseconds) to send a batch", stats.UnitMilliseconds)
 
 	statSuccessSendOps = stats.Int64("success_send", "Number of successful send operations", stats.UnitDimensionless)
 	statFailedSendOps  = stats.Int64("fail_send", "Number of failed send operations", stats.UnitDimensionless)
 
-	statQueueLength = stats.Int64("queue_length", "Current length of the queue (in batches)", stats.UnitDimensionless)
+	statQueueLength     = stats.Int64("queue_length", "Current length of the queue (in batches)", stats.UnitDimensionless)
+	statOldestItemAgeMs = stats.Int64("queue_oldest_item_age", "Age (in milliseconds) of the oldest batch in the queue", stats.UnitMilliseconds)
+	statEnqueueFailures = stats.Int64("queue_enqueue_failures", "Number of batches that could not be added to the queue because it was full", stats.UnitDimensionless)
 )
 
 // MetricViews return the metrics views according to given telemetry level.
@@ -236,7 +449,8 @@ func MetricViews(level telemetry.Level) []*view.View {
 		return nil
 	}
 
-	exporterTagKeys := []tag.Key{processor.TagExporterNameKey}
+	exporterTagKeys := []tag.Key{processor.TagExporterNameKey, tagTenantKey}
+	sendTagKeys := append(append([]tag.Key(nil), tagKeys...), tagTenantKey)
 
 	queueLengthView := &view.View{
 		Name:        statQueueLength.Name(),
@@ -245,18 +459,40 @@ func MetricViews(level telemetry.Level) []*view.View {
 		TagKeys:     exporterTagKeys,
 		Aggregation: view.LastValue(),
 	}
+	oldestItemAgeView := &view.View{
+		Name:        statOldestItemAgeMs.Name(),
+		Measure:     statOldestItemAgeMs,
+		Description: "Current age of the oldest batch in the queued exporter",
+		TagKeys:     exporterTagKeys,
+		Aggregation: view.LastValue(),
+	}
+	countEnqueueFailuresView := &view.View{
+		Name:        statEnqueueFailures.Name(),
+		Measure:     statEnqueueFailures,
+		Description: "The number of batches the queued exporter failed to add to its queue",
+		TagKeys:     exporterTagKeys,
+		Aggregation: view.Sum(),
+	}
 	countSuccessSendView := &view.View{
 		Name:        statSuccessSendOps.Name(),
 		Measure:     statSuccessSendOps,
 		Description: "The number of successful send operations performed by queued exporter",
-		TagKeys:     tagKeys,
+		TagKeys:     sendTagKeys,
 		Aggregation: view.Sum(),
 	}
 	countFailuresSendView := &view.View{
 		Name:        statFailedSendOps.Name(),
 		Measure:     statFailedSendOps,
 		Description: "The number of failed send operations performed by queued exporter",
-		TagKeys:     tagKeys,
+		TagKeys:     sendTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	droppedSpansView := &view.View{
+		Name:        "queue_spans_dropped",
+		Measure:     processor.StatDroppedSpanCount,
+		Description: "The number of spans dropped by the queued exporter",
+		TagKeys:     exporterTagKeys,
 		Aggregation: view.Sum(),
 	}
 
@@ -277,5 +513,5 @@ func MetricViews(level telemetry.Level) []*view.View {
 		Aggregation: latencyDistributionAggregation,
 	}
 
-	return []*view.View{queueLengthView, countSuccessSendView, countFailuresSendView, sendLatencyView, inQueueLatencyView}
+	return []*view.View{queueLengthView, oldestItemAgeView, countEnqueueFailuresView, countSuccessSendView, countFailuresSendView, droppedSpansView, sendLatencyView, inQueueLatencyView}
 }
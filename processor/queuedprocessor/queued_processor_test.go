@@ -23,11 +23,17 @@ import (
 	"time"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
 )
 
 func TestQueuedProcessor_noEnqueueOnPermanentError(t *testing.T) {
@@ -65,6 +71,84 @@ func TestQueuedProcessor_noEnqueueOnPermanentError(t *testing.T) {
 	require.Equal(t, 1, qp.queue.Size())
 }
 
+func TestQueuedProcessor_CapacityWarning(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	sink := &waitGroupTraceConsumer{}
+	qp := newQueuedSpanProcessor(sink, Options.apply(
+		Options.WithLogger(zap.New(core)),
+		Options.WithQueueSize(2),
+		Options.WithCapacityWarningRatio(0.5),
+	))
+	defer qp.Stop()
+
+	// The consumers are not started, the queue fills up.
+	td := consumerdata.TraceData{Spans: make([]*tracepb.Span, 3)}
+	require.True(t, qp.enqueue(&queueItem{queuedTime: time.Now(), td: td, ctx: context.Background()}))
+	require.True(t, qp.enqueue(&queueItem{queuedTime: time.Now(), td: td, ctx: context.Background()}))
+	require.False(t, qp.enqueue(&queueItem{queuedTime: time.Now(), td: td, ctx: context.Background()}))
+	require.Len(t, qp.queued, 2)
+
+	qp.reportQueueState(context.Background(), time.Now().Add(time.Minute))
+	qp.reportQueueState(context.Background(), time.Now().Add(time.Minute))
+	warnings := logs.FilterMessageSnippet("filling up").All()
+	require.Len(t, warnings, 1)
+	fields := warnings[0].ContextMap()
+	assert.EqualValues(t, 2, fields["queue-length"])
+	assert.EqualValues(t, 2, fields["queue-size"])
+	assert.True(t, fields["oldest-item-age"].(time.Duration) >= time.Minute)
+
+	sink.Add(2)
+	qp.queue.StartConsumers(1, func(item interface{}) {
+		qp.processItemFromQueue(item.(*queueItem))
+	})
+	sink.Wait()
+	for qp.queue.Size() != 0 {
+		<-time.After(10 * time.Millisecond)
+	}
+	assert.Len(t, qp.queued, 0)
+
+	qp.reportQueueState(context.Background(), time.Now())
+	assert.Equal(t, 1, logs.FilterMessageSnippet("back below").Len())
+}
+
+func TestQueuedProcessor_ThrottledFailureLogs(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	sink := &waitGroupTraceConsumer{consumeTraceDataError: errors.New("backend unavailable")}
+	qp := newQueuedSpanProcessor(sink, Options.apply(
+		Options.WithLogger(zap.New(core)),
+		Options.WithFailureLogEvery(3),
+	))
+	defer qp.Stop()
+
+	td := consumerdata.TraceData{Spans: make([]*tracepb.Span, 3)}
+	for i := 0; i < 5; i++ {
+		sink.Add(1)
+		qp.processItemFromQueue(&queueItem{queuedTime: time.Now(), td: td, ctx: context.Background()})
+	}
+
+	// Only the 1st and the 4th failures are logged.
+	failures := logs.FilterMessage("Sender failed").All()
+	require.Len(t, failures, 2)
+	assert.EqualValues(t, 1, failures[0].ContextMap()["occurrences"])
+	assert.EqualValues(t, 4, failures[1].ContextMap()["occurrences"])
+	assert.Equal(t, 2, logs.FilterMessage("Failed to process batch, discarding").Len())
+	assert.Equal(t, 2, logs.FilterMessage("Span batch dropped").Len())
+
+	// The recovery is logged with the number of failures, and the next
+	// failure is logged again.
+	sink.consumeTraceDataError = nil
+	sink.Add(1)
+	qp.processItemFromQueue(&queueItem{queuedTime: time.Now(), td: td, ctx: context.Background()})
+	recovered := logs.FilterMessage("Sender recovered").All()
+	require.Len(t, recovered, 1)
+	assert.EqualValues(t, 5, recovered[0].ContextMap()["failures"])
+
+	sink.consumeTraceDataError = errors.New("backend unavailable")
+	sink.Add(1)
+	qp.processItemFromQueue(&queueItem{queuedTime: time.Now(), td: td, ctx: context.Background()})
+	assert.Equal(t, 3, logs.FilterMessage("Sender failed").Len())
+}
+
 type waitGroupTraceConsumer struct {
 	sync.WaitGroup
 	consumeTraceDataError error
@@ -135,3 +219,63 @@ func (p *mockConcurrentSpanProcessor) runConcurrently(fn func()) {
 func (p *mockConcurrentSpanProcessor) awaitAsyncProcessing() {
 	p.waitGroup.Wait()
 }
+
+// slowTraceConsumer counts the spans it consumes, taking delay per batch.
+type slowTraceConsumer struct {
+	delay time.Duration
+	err   error
+	spans int64
+}
+
+func (c *slowTraceConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	time.Sleep(c.delay)
+	if c.err != nil {
+		return c.err
+	}
+	atomic.AddInt64(&c.spans, int64(len(td.Spans)))
+	return nil
+}
+
+func TestQueuedProcessor_ShutdownDrainsQueue(t *testing.T) {
+	sink := &slowTraceConsumer{delay: 20 * time.Millisecond}
+	qp := NewQueuedSpanProcessor(sink, Options.WithNumWorkers(1))
+
+	td := consumerdata.TraceData{Spans: make([]*tracepb.Span, 3)}
+	for i := 0; i < 5; i++ {
+		require.NoError(t, qp.ConsumeTraceData(context.Background(), td))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, qp.(*queuedSpanProcessor).Shutdown(ctx))
+	assert.EqualValues(t, 15, atomic.LoadInt64(&sink.spans))
+}
+
+func TestQueuedProcessor_ShutdownTimeout(t *testing.T) {
+	sink := &slowTraceConsumer{err: errors.New("backend unavailable")}
+	qp := NewQueuedSpanProcessor(sink,
+		Options.WithNumWorkers(1),
+		Options.WithRetryOnProcessingFailures(true),
+		Options.WithBackoffDelay(10*time.Millisecond))
+	require.NoError(t, qp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: make([]*tracepb.Span, 1)}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, qp.(*queuedSpanProcessor).Shutdown(ctx))
+}
+
+func TestQueuedProcessor_ShutdownFlushesBatches(t *testing.T) {
+	sink := &slowTraceConsumer{}
+	qp := NewQueuedSpanProcessor(sink,
+		Options.WithBatching(true),
+		Options.WithBatchingOptions(nodebatcherprocessor.WithTimeout(time.Hour)))
+
+	td := consumerdata.TraceData{Spans: make([]*tracepb.Span, 3)}
+	require.NoError(t, qp.ConsumeTraceData(context.Background(), td))
+	require.NoError(t, qp.ConsumeTraceData(context.Background(), td))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, qp.(processor.Shutdowner).Shutdown(ctx))
+	assert.EqualValues(t, 6, atomic.LoadInt64(&sink.spans))
+}
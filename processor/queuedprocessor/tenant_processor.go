@@ -0,0 +1,127 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuedprocessor
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// tenantSpanProcessor queues the batches of every tenant, identified by a node
+// attribute, in its own queued processor so that a tenant whose backend is
+// down, or which sends a burst, only fills its own queue and only blocks its
+// own workers.
+type tenantSpanProcessor struct {
+	sender  consumer.TraceConsumer
+	options options
+
+	mu      sync.Mutex
+	tenants map[string]*queuedSpanProcessor
+	// overflowed is true once a tenant beyond the maximum was seen.
+	overflowed bool
+	stopped    bool
+}
+
+var _ consumer.TraceConsumer = (*tenantSpanProcessor)(nil)
+var _ processor.Shutdowner = (*tenantSpanProcessor)(nil)
+
+func newTenantSpanProcessor(sender consumer.TraceConsumer, opts options) *tenantSpanProcessor {
+	return &tenantSpanProcessor{
+		sender:  sender,
+		options: opts,
+		tenants: make(map[string]*queuedSpanProcessor),
+	}
+}
+
+// ConsumeTraceData queues the batch in the queue of its tenant.
+func (tp *tenantSpanProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	sp := tp.processorFor(td.Node.GetAttributes()[tp.options.tenantAttribute])
+	if sp == nil {
+		// Stopped, the batch is dropped like when the queue is stopped.
+		return nil
+	}
+	return sp.ConsumeTraceData(ctx, td)
+}
+
+// processorFor returns the queued processor of the tenant, starting it on
+// the first batch of the tenant. The tenants beyond the maximum share the
+// processor of the batches without tenant. It returns nil once stopped.
+func (tp *tenantSpanProcessor) processorFor(tenant string) *queuedSpanProcessor {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if tp.stopped {
+		return nil
+	}
+	if sp, ok := tp.tenants[tenant]; ok {
+		return sp
+	}
+	if tenant != "" && len(tp.tenants) >= tp.options.maxTenants {
+		if !tp.overflowed {
+			tp.overflowed = true
+			tp.options.logger.Warn("Too many tenants, the batches of the new tenants share the queue of the batches without tenant",
+				zap.String("processor", tp.options.name),
+				zap.Int("max-tenants", tp.options.maxTenants))
+		}
+		tenant = ""
+		if sp, ok := tp.tenants[tenant]; ok {
+			return sp
+		}
+	}
+
+	opts := tp.options
+	opts.tenant = tenant
+	sp := newQueuedSpanProcessor(tp.sender, opts)
+	sp.start()
+	tp.tenants[tenant] = sp
+	return sp
+}
+
+// Shutdown drains the queued processors of all the tenants and stops them, the
+// batches received meanwhile are dropped.
+func (tp *tenantSpanProcessor) Shutdown(ctx context.Context) error {
+	tp.mu.Lock()
+	tp.stopped = true
+	tenants := make([]*queuedSpanProcessor, 0, len(tp.tenants))
+	for _, sp := range tp.tenants {
+		tenants = append(tenants, sp)
+	}
+	tp.mu.Unlock()
+
+	var err error
+	for _, sp := range tenants {
+		if spErr := sp.Shutdown(ctx); spErr != nil && err == nil {
+			err = spErr
+		}
+	}
+	return err
+}
+
+// Stop halts the queued processors of all the tenants.
+func (tp *tenantSpanProcessor) Stop() {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	tp.stopped = true
+	for _, sp := range tp.tenants {
+		sp.Stop()
+	}
+}
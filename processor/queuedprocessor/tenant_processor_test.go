@@ -0,0 +1,142 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuedprocessor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// tenantSender fails for the batches of the "down" tenant and delivers the
+// tenants of the other batches.
+type tenantSender struct {
+	delivered chan string
+}
+
+var _ consumer.TraceConsumer = (*tenantSender)(nil)
+
+func (s *tenantSender) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	tenant := td.Node.GetAttributes()["tenant"]
+	if tenant == "down" {
+		return errors.New("backend unavailable")
+	}
+	s.delivered <- tenant
+	return nil
+}
+
+func tenantBatch(tenant string) consumerdata.TraceData {
+	node := &commonpb.Node{}
+	if tenant != "" {
+		node.Attributes = map[string]string{"tenant": tenant}
+	}
+	return consumerdata.TraceData{Node: node, Spans: make([]*tracepb.Span, 1)}
+}
+
+func TestTenantSpanProcessor_Isolation(t *testing.T) {
+	sender := &tenantSender{delivered: make(chan string, 10)}
+	tp := NewQueuedSpanProcessor(sender,
+		Options.WithTenantAttribute("tenant"),
+		Options.WithNumWorkers(1),
+		Options.WithQueueSize(2),
+		Options.WithRetryOnProcessingFailures(true),
+		Options.WithBackoffDelay(time.Hour),
+	).(*tenantSpanProcessor)
+	defer tp.Stop()
+
+	// The worker of the "down" tenant backs off after its first failure and
+	// its queue fills up, the batches of the other tenants still go through.
+	for i := 0; i < 5; i++ {
+		require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("down")))
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("up")))
+	}
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("")))
+
+	delivered := map[string]int{}
+	for i := 0; i < 4; i++ {
+		select {
+		case tenant := <-sender.delivered:
+			delivered[tenant]++
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d batches delivered", i)
+		}
+	}
+	assert.Equal(t, map[string]int{"up": 3, "": 1}, delivered)
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	require.Len(t, tp.tenants, 3)
+	assert.Equal(t, "down", tp.tenants["down"].tenant)
+	assert.Equal(t, 2, tp.tenants["down"].queue.Size())
+}
+
+func TestTenantSpanProcessor_MaxTenants(t *testing.T) {
+	sender := &tenantSender{delivered: make(chan string, 10)}
+	tp := NewQueuedSpanProcessor(sender,
+		Options.WithTenantAttribute("tenant"),
+		Options.WithMaxTenants(1),
+	).(*tenantSpanProcessor)
+
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("a")))
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("b")))
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("c")))
+	for i := 0; i < 3; i++ {
+		<-sender.delivered
+	}
+
+	// The tenants beyond the maximum share the queue without tenant.
+	tp.mu.Lock()
+	assert.Len(t, tp.tenants, 2)
+	assert.Contains(t, tp.tenants, "a")
+	assert.Contains(t, tp.tenants, "")
+	tp.mu.Unlock()
+
+	tp.Stop()
+	assert.Nil(t, tp.processorFor("a"))
+	assert.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("a")))
+}
+
+func TestTenantSpanProcessor_Shutdown(t *testing.T) {
+	sink := &slowTraceConsumer{delay: 10 * time.Millisecond}
+	tp := NewQueuedSpanProcessor(sink,
+		Options.WithTenantAttribute("tenant"),
+		Options.WithNumWorkers(1),
+	).(*tenantSpanProcessor)
+
+	for _, tenant := range []string{"a", "b", "a", "b", ""} {
+		require.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch(tenant)))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, tp.Shutdown(ctx))
+	assert.EqualValues(t, 5, atomic.LoadInt64(&sink.spans))
+
+	// The batches received once shut down are dropped.
+	assert.NoError(t, tp.ConsumeTraceData(context.Background(), tenantBatch("a")))
+	assert.EqualValues(t, 5, atomic.LoadInt64(&sink.spans))
+}
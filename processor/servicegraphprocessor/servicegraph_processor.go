@@ -0,0 +1,48 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package servicegraphprocessor records the spans passing through it in the
+// service dependency graph served by a service-graph extension. The spans are
+// passed unchanged to the next consumer.
+package servicegraphprocessor
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/extensionfeed"
+	"github.com/open-telemetry/opentelemetry-service/internal/servicegraph"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+func newServiceGraphProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg Config,
+) (processor.TraceProcessor, error) {
+	return extensionfeed.NewTraceProcessor(logger, nextConsumer, cfg.Extension, recordInGraph,
+		"Service graph extension is not running, spans are not recorded")
+}
+
+// recordInGraph records the spans in the graph of the service-graph extension
+// named extension, if it is running.
+func recordInGraph(extension string, td consumerdata.TraceData) bool {
+	graph := servicegraph.Lookup(extension)
+	if graph == nil {
+		return false
+	}
+	graph.Record(td.Node, td.Spans)
+	return true
+}
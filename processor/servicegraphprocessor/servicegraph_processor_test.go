@@ -0,0 +1,58 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicegraphprocessor
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/servicegraph"
+)
+
+func TestServiceGraphProcessor(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	sgp, err := newServiceGraphProcessor(zap.NewNop(), sink, Config{Extension: "service-graph/test"})
+	require.NoError(t, err)
+
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+		Spans: []*tracepb.Span{
+			{TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, SpanId: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		},
+	}
+
+	// Without the extension the spans are only passed through.
+	require.NoError(t, sgp.ConsumeTraceData(context.Background(), td))
+	assert.Len(t, sink.AllTraces(), 1)
+
+	graph := servicegraph.NewGraph(10)
+	servicegraph.Register("service-graph/test", graph)
+	defer servicegraph.Unregister("service-graph/test")
+
+	require.NoError(t, sgp.ConsumeTraceData(context.Background(), td))
+	assert.Len(t, sink.AllTraces(), 2)
+	snapshot := graph.Snapshot()
+	require.Len(t, snapshot.Nodes, 1)
+	assert.Equal(t, "frontend", snapshot.Nodes[0].Service)
+	assert.Equal(t, int64(1), snapshot.Nodes[0].Spans)
+}
@@ -0,0 +1,56 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+var (
+	statStaleSpansDropped  = stats.Int64("stale_spans_dropped", "Number of spans dropped because they are older than the maximum age", stats.UnitDimensionless)
+	statStalePointsDropped = stats.Int64("stale_points_dropped", "Number of metric points dropped because they are older than the maximum age", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to the staleness processor.
+func MetricViews(level telemetry.Level) []*view.View {
+	if processor.MetricTagKeys(level) == nil {
+		return nil
+	}
+
+	processorTagKeys := []tag.Key{processor.TagExporterNameKey}
+
+	staleSpansDroppedView := &view.View{
+		Name:        statStaleSpansDropped.Name(),
+		Measure:     statStaleSpansDropped,
+		Description: statStaleSpansDropped.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	stalePointsDroppedView := &view.View{
+		Name:        statStalePointsDropped.Name(),
+		Measure:     statStalePointsDropped,
+		Description: statStalePointsDropped.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{staleSpansDroppedView, stalePointsDroppedView}
+}
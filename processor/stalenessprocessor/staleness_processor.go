@@ -0,0 +1,220 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// staleness holds the settings shared by the trace and metrics processors.
+type staleness struct {
+	name      string
+	logger    *zap.Logger
+	maxAge    time.Duration
+	now       func() time.Time
+	statsTags []tag.Mutator
+}
+
+func newStaleness(logger *zap.Logger, cfg Config) (staleness, error) {
+	if cfg.MaxAge <= 0 {
+		return staleness{}, fmt.Errorf("error creating %q processor: \"max-age\" must be positive", cfg.Name())
+	}
+	return staleness{
+		name:      cfg.Name(),
+		logger:    logger,
+		maxAge:    cfg.MaxAge,
+		now:       time.Now,
+		statsTags: []tag.Mutator{tag.Upsert(processor.TagExporterNameKey, cfg.Name())},
+	}, nil
+}
+
+// cutoff returns the oldest timestamp that is kept, in seconds and nanoseconds
+// as the protobuf timestamps.
+func (s *staleness) cutoff() *timestamp.Timestamp {
+	t := s.now().Add(-s.maxAge)
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// isStale returns true if ts is before the cutoff. Data without timestamp is
+// never stale.
+func isStale(ts, cutoff *timestamp.Timestamp) bool {
+	if ts == nil {
+		return false
+	}
+	return ts.Seconds < cutoff.Seconds || (ts.Seconds == cutoff.Seconds && ts.Nanos < cutoff.Nanos)
+}
+
+func (s *staleness) recordDropped(ctx context.Context, measure *stats.Int64Measure, dropped int) {
+	if dropped == 0 {
+		return
+	}
+	stats.RecordWithTags(ctx, s.statsTags, measure.M(int64(dropped)))
+	s.logger.Debug("Dropped stale data",
+		zap.String("processor", s.name),
+		zap.String("measure", measure.Name()),
+		zap.Int("count", dropped))
+}
+
+type stalenessTraceProcessor struct {
+	staleness
+	nextConsumer consumer.TraceConsumer
+}
+
+var _ processor.TraceProcessor = (*stalenessTraceProcessor)(nil)
+
+func newStalenessTraceProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer, cfg Config) (*stalenessTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	s, err := newStaleness(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &stalenessTraceProcessor{staleness: s, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeTraceData drops the spans that ended before the cutoff, or started
+// before it if they have no end time. Batches left without spans are not
+// forwarded.
+func (sp *stalenessTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	cutoff := sp.cutoff()
+	spans := make([]*tracepb.Span, 0, len(td.Spans))
+	for _, span := range td.Spans {
+		if span == nil || !isStale(spanTimestamp(span), cutoff) {
+			spans = append(spans, span)
+		}
+	}
+
+	dropped := len(td.Spans) - len(spans)
+	sp.recordDropped(ctx, statStaleSpansDropped, dropped)
+	if dropped == 0 {
+		return sp.nextConsumer.ConsumeTraceData(ctx, td)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	td.Spans = spans
+	return sp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+func spanTimestamp(span *tracepb.Span) *timestamp.Timestamp {
+	if span.EndTime != nil {
+		return span.EndTime
+	}
+	return span.StartTime
+}
+
+type stalenessMetricsProcessor struct {
+	staleness
+	nextConsumer consumer.MetricsConsumer
+}
+
+var _ processor.MetricsProcessor = (*stalenessMetricsProcessor)(nil)
+
+func newStalenessMetricsProcessor(logger *zap.Logger, nextConsumer consumer.MetricsConsumer, cfg Config) (*stalenessMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	s, err := newStaleness(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &stalenessMetricsProcessor{staleness: s, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeMetricsData drops the points timestamped before the cutoff. Timeseries,
+// metrics and batches left without points are dropped as well.
+func (sp *stalenessMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	cutoff := sp.cutoff()
+	dropped := 0
+	metrics := make([]*metricspb.Metric, 0, len(md.Metrics))
+	for _, metric := range md.Metrics {
+		if metric == nil {
+			metrics = append(metrics, metric)
+			continue
+		}
+		kept, n := dropStalePoints(metric, cutoff)
+		dropped += n
+		if kept != nil {
+			metrics = append(metrics, kept)
+		}
+	}
+
+	sp.recordDropped(ctx, statStalePointsDropped, dropped)
+	if dropped == 0 {
+		return sp.nextConsumer.ConsumeMetricsData(ctx, md)
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	md.Metrics = metrics
+	return sp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// dropStalePoints returns the metric without the points timestamped before the
+// cutoff and the number of dropped points, the returned metric is nil if no
+// point is left. The metric is copied if any point is dropped, since receivers
+// may share the metrics between batches.
+func dropStalePoints(metric *metricspb.Metric, cutoff *timestamp.Timestamp) (*metricspb.Metric, int) {
+	dropped := 0
+	timeseries := make([]*metricspb.TimeSeries, 0, len(metric.Timeseries))
+	for _, ts := range metric.Timeseries {
+		if ts == nil {
+			timeseries = append(timeseries, ts)
+			continue
+		}
+		points := make([]*metricspb.Point, 0, len(ts.Points))
+		for _, point := range ts.Points {
+			if point == nil || !isStale(point.Timestamp, cutoff) {
+				points = append(points, point)
+			}
+		}
+		if len(points) == len(ts.Points) {
+			timeseries = append(timeseries, ts)
+			continue
+		}
+
+		dropped += len(ts.Points) - len(points)
+		if len(points) > 0 {
+			tsCopy := *ts
+			tsCopy.Points = points
+			timeseries = append(timeseries, &tsCopy)
+		}
+	}
+
+	if dropped == 0 {
+		return metric, 0
+	}
+	if len(timeseries) == 0 {
+		return nil, dropped
+	}
+	metricCopy := *metric
+	metricCopy.Timeseries = timeseries
+	return &metricCopy, dropped
+}
@@ -0,0 +1,157 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+var testNow = time.Unix(1571000000, 500)
+
+func testConfig() Config {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.MaxAge = time.Minute
+	return *cfg
+}
+
+func ts(age time.Duration) *timestamp.Timestamp {
+	t := testNow.Add(-age)
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+func span(name string, start, end *timestamp.Timestamp) *tracepb.Span {
+	return &tracepb.Span{
+		Name:      &tracepb.TruncatableString{Value: name},
+		StartTime: start,
+		EndTime:   end,
+	}
+}
+
+func TestStalenessTraceProcessor(t *testing.T) {
+	sink := new(exportertest.SinkTraceExporter)
+	sp, err := newStalenessTraceProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+	sp.now = func() time.Time { return testNow }
+
+	fresh := span("fresh", ts(2*time.Minute), ts(30*time.Second))
+	cutoff := span("cutoff", ts(2*time.Minute), ts(time.Minute))
+	noEnd := span("no-end", ts(30*time.Second), nil)
+	noTimestamps := span("no-timestamps", nil, nil)
+	stale := span("stale", ts(2*time.Minute), ts(time.Minute+time.Nanosecond))
+	staleNoEnd := span("stale-no-end", ts(2*time.Minute), nil)
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{fresh, stale, cutoff, noEnd, staleNoEnd, noTimestamps},
+	}
+	require.NoError(t, sp.ConsumeTraceData(context.Background(), td))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	assert.Equal(t, []*tracepb.Span{fresh, cutoff, noEnd, noTimestamps}, got[0].Spans)
+	assert.Len(t, td.Spans, 6, "the input batch must not be modified")
+
+	// Batches without fresh spans are not forwarded.
+	td = consumerdata.TraceData{Spans: []*tracepb.Span{stale, staleNoEnd}}
+	require.NoError(t, sp.ConsumeTraceData(context.Background(), td))
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+func point(age time.Duration, value int64) *metricspb.Point {
+	return &metricspb.Point{
+		Timestamp: ts(age),
+		Value:     &metricspb.Point_Int64Value{Int64Value: value},
+	}
+}
+
+func gauge(name string, points ...*metricspb.Point) *metricspb.Metric {
+	return &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name: name,
+			Type: metricspb.MetricDescriptor_GAUGE_INT64,
+		},
+		Timeseries: []*metricspb.TimeSeries{{Points: points}},
+	}
+}
+
+func TestStalenessMetricsProcessor(t *testing.T) {
+	sink := new(exportertest.SinkMetricsExporter)
+	sp, err := newStalenessMetricsProcessor(zap.NewNop(), sink, testConfig())
+	require.NoError(t, err)
+	sp.now = func() time.Time { return testNow }
+
+	fresh := gauge("fresh", point(10*time.Second, 1), point(20*time.Second, 2))
+	mixed := gauge("mixed", point(10*time.Second, 1), point(2*time.Minute, 2))
+	stale := gauge("stale", point(2*time.Minute, 1), point(3*time.Minute, 2))
+	md := consumerdata.MetricsData{Metrics: []*metricspb.Metric{fresh, mixed, stale}}
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), md))
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 2)
+	assert.Equal(t, fresh, got[0].Metrics[0])
+	assert.Equal(t, gauge("mixed", point(10*time.Second, 1)), got[0].Metrics[1])
+	assert.Len(t, mixed.Timeseries[0].Points, 2, "the input metric must not be modified")
+
+	// Batches without fresh points are not forwarded.
+	md = consumerdata.MetricsData{Metrics: []*metricspb.Metric{stale}}
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), md))
+	assert.Len(t, sink.AllMetrics(), 1)
+}
+
+func TestStalenessProcessor_RecordsDropped(t *testing.T) {
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	cfg := testConfig()
+	cfg.NameVal = "staleness/recorded"
+	tp, err := newStalenessTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	require.NoError(t, err)
+	tp.now = func() time.Time { return testNow }
+	mp, err := newStalenessMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	require.NoError(t, err)
+	mp.now = func() time.Time { return testNow }
+
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), consumerdata.TraceData{
+		Spans: []*tracepb.Span{span("stale", nil, ts(time.Hour)), span("fresh", nil, ts(0))},
+	}))
+	require.NoError(t, mp.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{gauge("stale", point(time.Hour, 1), point(time.Hour, 2), point(0, 3))},
+	}))
+
+	for name, want := range map[string]float64{
+		statStaleSpansDropped.Name():  1,
+		statStalePointsDropped.Name(): 2,
+	} {
+		rows, err := view.RetrieveData(name)
+		require.NoError(t, err)
+		require.Len(t, rows, 1, name)
+		assert.Equal(t, "staleness/recorded", rows[0].Tags[0].Value)
+		assert.Equal(t, want, rows[0].Data.(*view.SumData).Value, name)
+	}
+}
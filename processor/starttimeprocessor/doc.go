@@ -0,0 +1,20 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package starttimeprocessor sets the start timestamp of cumulative metrics
+// that arrive without one, e.g. from the Prometheus receiver. The start time
+// of a series is the timestamp of its first observed point and is moved
+// forward when the series is reset, so backends computing rates over the
+// cumulative values get consistent intervals.
+package starttimeprocessor
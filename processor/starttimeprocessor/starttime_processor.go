@@ -0,0 +1,252 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package starttimeprocessor
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// seriesInfo holds the start time assigned to a series and the last point
+// seen, used to detect resets. Series are garbage collected with the same
+// mark-and-sweep approach used by the Prometheus receiver metrics adjuster:
+// each access marks the series and every gc removes the unmarked ones.
+type seriesInfo struct {
+	mark     bool
+	start    *timestamp.Timestamp
+	previous *metricspb.Point
+}
+
+type startTimeProcessor struct {
+	nextConsumer consumer.MetricsConsumer
+	gcInterval   time.Duration
+	now          func() time.Time
+
+	// mu protects the fields below
+	mu     sync.Mutex
+	lastGC time.Time
+	series map[string]*seriesInfo
+}
+
+var _ processor.MetricsProcessor = (*startTimeProcessor)(nil)
+
+func newStartTimeProcessor(nextConsumer consumer.MetricsConsumer, cfg Config) (*startTimeProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	gcInterval := cfg.GCInterval
+	if gcInterval <= 0 {
+		gcInterval = defaultGCInterval
+	}
+	return &startTimeProcessor{
+		nextConsumer: nextConsumer,
+		gcInterval:   gcInterval,
+		now:          time.Now,
+		lastGC:       time.Now(),
+		series:       make(map[string]*seriesInfo),
+	}, nil
+}
+
+func (sp *startTimeProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	prefix := seriesPrefix(md.Node, md.Resource)
+
+	sp.mu.Lock()
+	metrics := make([]*metricspb.Metric, 0, len(md.Metrics))
+	for _, metric := range md.Metrics {
+		metrics = append(metrics, sp.adjustMetric(prefix, metric))
+	}
+	sp.maybeGC()
+	sp.mu.Unlock()
+
+	md.Metrics = metrics
+	return sp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// adjustMetric returns the metric with the start timestamp set on its
+// timeseries. The metric is copied if any of them needs to be changed, since
+// receivers may share the metrics between batches.
+func (sp *startTimeProcessor) adjustMetric(prefix string, metric *metricspb.Metric) *metricspb.Metric {
+	if metric == nil || !isCumulative(metric.GetMetricDescriptor().GetType()) {
+		return metric
+	}
+
+	name := metric.GetMetricDescriptor().GetName()
+	var timeseries []*metricspb.TimeSeries
+	for i, ts := range metric.Timeseries {
+		start := sp.startTime(prefix, name, metric.GetMetricDescriptor().GetType(), ts)
+		if start == nil {
+			if timeseries != nil {
+				timeseries = append(timeseries, ts)
+			}
+			continue
+		}
+
+		if timeseries == nil {
+			timeseries = make([]*metricspb.TimeSeries, i, len(metric.Timeseries))
+			copy(timeseries, metric.Timeseries[:i])
+		}
+		newTS := *ts
+		newTS.StartTimestamp = start
+		timeseries = append(timeseries, &newTS)
+	}
+	if timeseries == nil {
+		return metric
+	}
+
+	newMetric := *metric
+	newMetric.Timeseries = timeseries
+	return &newMetric
+}
+
+// startTime returns the start timestamp to set on the timeseries, or nil if
+// the timeseries already has one or has no points.
+func (sp *startTimeProcessor) startTime(
+	prefix string,
+	name string,
+	metricType metricspb.MetricDescriptor_Type,
+	ts *metricspb.TimeSeries,
+) *timestamp.Timestamp {
+	if ts == nil || len(ts.Points) == 0 || !isZero(ts.StartTimestamp) {
+		return nil
+	}
+	first, last := ts.Points[0], ts.Points[len(ts.Points)-1]
+
+	sig := seriesSignature(prefix, name, ts.LabelValues)
+	info, ok := sp.series[sig]
+	if !ok || isReset(metricType, info.previous, first) {
+		start := first.Timestamp
+		if isZero(start) {
+			// Points without a timestamp are recorded now.
+			start, _ = ptypes.TimestampProto(sp.now())
+		}
+		info = &seriesInfo{start: start}
+		sp.series[sig] = info
+	}
+	info.mark = true
+	info.previous = last
+	return info.start
+}
+
+// maybeGC removes the series that were not seen since the previous gc.
+func (sp *startTimeProcessor) maybeGC() {
+	now := sp.now()
+	if now.Sub(sp.lastGC) < sp.gcInterval {
+		return
+	}
+	for sig, info := range sp.series {
+		if !info.mark {
+			delete(sp.series, sig)
+		} else {
+			info.mark = false
+		}
+	}
+	sp.lastGC = now
+}
+
+func isCumulative(metricType metricspb.MetricDescriptor_Type) bool {
+	switch metricType {
+	case metricspb.MetricDescriptor_CUMULATIVE_INT64,
+		metricspb.MetricDescriptor_CUMULATIVE_DOUBLE,
+		metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION,
+		metricspb.MetricDescriptor_SUMMARY:
+		return true
+	default:
+		return false
+	}
+}
+
+func isZero(ts *timestamp.Timestamp) bool {
+	return ts == nil || (ts.Seconds == 0 && ts.Nanos == 0)
+}
+
+// isReset reports whether the cumulative value of current is lower than the
+// one of previous, which means that the series was reset.
+func isReset(metricType metricspb.MetricDescriptor_Type, previous, current *metricspb.Point) bool {
+	if previous == nil {
+		return false
+	}
+	switch metricType {
+	case metricspb.MetricDescriptor_CUMULATIVE_INT64:
+		return current.GetInt64Value() < previous.GetInt64Value()
+	case metricspb.MetricDescriptor_CUMULATIVE_DOUBLE:
+		return current.GetDoubleValue() < previous.GetDoubleValue()
+	case metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION:
+		return current.GetDistributionValue().GetCount() < previous.GetDistributionValue().GetCount() ||
+			current.GetDistributionValue().GetSum() < previous.GetDistributionValue().GetSum()
+	case metricspb.MetricDescriptor_SUMMARY:
+		return current.GetSummaryValue().GetCount().GetValue() < previous.GetSummaryValue().GetCount().GetValue() ||
+			current.GetSummaryValue().GetSum().GetValue() < previous.GetSummaryValue().GetSum().GetValue()
+	default:
+		return false
+	}
+}
+
+// seriesPrefix identifies the source of the metrics, so series with the same
+// name and label values reported by different nodes are kept apart.
+func seriesPrefix(node *commonpb.Node, resource *resourcepb.Resource) string {
+	var b strings.Builder
+	b.WriteString(node.GetServiceInfo().GetName())
+	b.WriteByte(0)
+	b.WriteString(node.GetIdentifier().GetHostName())
+	b.WriteByte(0)
+	b.WriteString(strconv.Itoa(int(node.GetIdentifier().GetPid())))
+	b.WriteByte(0)
+	b.WriteString(resource.GetType())
+
+	keys := make([]string, 0, len(resource.GetLabels()))
+	for k := range resource.GetLabels() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(resource.Labels[k])
+	}
+	return b.String()
+}
+
+func seriesSignature(prefix, name string, values []*metricspb.LabelValue) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteByte(0)
+	b.WriteString(name)
+	for _, v := range values {
+		b.WriteByte(0)
+		if v.GetHasValue() {
+			b.WriteString(v.GetValue())
+		} else {
+			// Distinguish a missing value from an empty one.
+			b.WriteByte(1)
+		}
+	}
+	return b.String()
+}
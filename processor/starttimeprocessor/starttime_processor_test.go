@@ -0,0 +1,145 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package starttimeprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func cumulativeMetric(name string, metricType metricspb.MetricDescriptor_Type, start *timestamp.Timestamp, seconds int64, value float64) *metricspb.Metric {
+	return &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      name,
+			Type:      metricType,
+			LabelKeys: []*metricspb.LabelKey{{Key: "method"}},
+		},
+		Timeseries: []*metricspb.TimeSeries{
+			{
+				StartTimestamp: start,
+				LabelValues:    []*metricspb.LabelValue{{Value: "get", HasValue: true}},
+				Points: []*metricspb.Point{
+					{
+						Timestamp: &timestamp.Timestamp{Seconds: seconds},
+						Value:     &metricspb.Point_DoubleValue{DoubleValue: value},
+					},
+				},
+			},
+		},
+	}
+}
+
+func metricsData(node string, metrics ...*metricspb.Metric) consumerdata.MetricsData {
+	return consumerdata.MetricsData{
+		Node:    &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: node}},
+		Metrics: metrics,
+	}
+}
+
+func newTestProcessor(t *testing.T, sink *exportertest.SinkMetricsExporter) *startTimeProcessor {
+	sp, err := newStartTimeProcessor(sink, Config{GCInterval: time.Minute})
+	require.NoError(t, err)
+	return sp
+}
+
+func lastStart(t *testing.T, sink *exportertest.SinkMetricsExporter, metric int) *timestamp.Timestamp {
+	all := sink.AllMetrics()
+	require.NotEmpty(t, all)
+	return all[len(all)-1].Metrics[metric].Timeseries[0].StartTimestamp
+}
+
+func TestStartTimeProcessor_FirstObservation(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	sp := newTestProcessor(t, sink)
+
+	in := cumulativeMetric("requests", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, nil, 100, 1)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc", in)))
+	assert.Equal(t, &timestamp.Timestamp{Seconds: 100}, lastStart(t, sink, 0))
+	// The input is not modified.
+	assert.Nil(t, in.Timeseries[0].StartTimestamp)
+
+	m := cumulativeMetric("requests", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, &timestamp.Timestamp{}, 110, 5)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc", m)))
+	assert.Equal(t, &timestamp.Timestamp{Seconds: 100}, lastStart(t, sink, 0))
+
+	// The same series from another node has its own start time.
+	m = cumulativeMetric("requests", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, nil, 120, 5)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("other", m)))
+	assert.Equal(t, &timestamp.Timestamp{Seconds: 120}, lastStart(t, sink, 0))
+}
+
+func TestStartTimeProcessor_Reset(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	sp := newTestProcessor(t, sink)
+
+	for _, p := range []struct {
+		seconds   int64
+		value     float64
+		wantStart int64
+	}{
+		{seconds: 100, value: 10, wantStart: 100},
+		{seconds: 110, value: 20, wantStart: 100},
+		{seconds: 120, value: 3, wantStart: 120},
+		{seconds: 130, value: 4, wantStart: 120},
+	} {
+		m := cumulativeMetric("requests", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, nil, p.seconds, p.value)
+		require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc", m)))
+		assert.Equal(t, &timestamp.Timestamp{Seconds: p.wantStart}, lastStart(t, sink, 0))
+	}
+}
+
+func TestStartTimeProcessor_Unchanged(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	sp := newTestProcessor(t, sink)
+
+	gauge := cumulativeMetric("temperature", metricspb.MetricDescriptor_GAUGE_DOUBLE, nil, 100, 1)
+	withStart := cumulativeMetric("requests", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, &timestamp.Timestamp{Seconds: 50}, 100, 1)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc", gauge, withStart)))
+
+	got := sink.AllMetrics()[0].Metrics
+	assert.True(t, gauge == got[0])
+	assert.True(t, withStart == got[1])
+}
+
+func TestStartTimeProcessor_GC(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	sp := newTestProcessor(t, sink)
+	now := time.Now()
+	sp.now = func() time.Time { return now }
+
+	m := cumulativeMetric("requests", metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, nil, 100, 1)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc", m)))
+	require.Len(t, sp.series, 1)
+
+	// The series was seen during the first interval, so it is kept.
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc")))
+	assert.Len(t, sp.series, 1)
+
+	// It was not seen during the second one.
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, sp.ConsumeMetricsData(context.Background(), metricsData("svc")))
+	assert.Empty(t, sp.series)
+}
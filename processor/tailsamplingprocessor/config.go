@@ -77,6 +77,22 @@ type RateLimitingCfg struct {
 	SpansPerSecond int64 `mapstructure:"spans-per-second"`
 }
 
+// LinkedTracesCfg holds the configurable settings to keep the traces linked,
+// by span links, to the traces kept by the sampling policies.
+type LinkedTracesCfg struct {
+	// Enabled makes the traces linked to kept traces inherit the decision.
+	Enabled bool `mapstructure:"enabled"`
+	// CacheSize is the maximum number of kept trace IDs remembered, the oldest
+	// are forgotten first.
+	CacheSize int `mapstructure:"cache-size"`
+	// GossipEndpoint is the host:port where the IDs of the traces kept by
+	// the peers are received, not served if empty.
+	GossipEndpoint string `mapstructure:"gossip-endpoint"`
+	// Peers are the gossip endpoints of the other replicas, the IDs of the
+	// traces kept by this replica are sent to them.
+	Peers []string `mapstructure:"peers"`
+}
+
 // Config holds the configuration for tail-based sampling.
 type Config struct {
 	configmodels.ProcessorSettings `mapstructure:",squash"`
@@ -92,4 +108,6 @@ type Config struct {
 	// PolicyCfgs sets the tail-based sampling policy which makes a sampling decision
 	// for a given trace when requested.
 	PolicyCfgs []PolicyCfg `mapstructure:"policies"`
+	// LinkedTraces makes the traces linked to kept traces inherit the decision.
+	LinkedTraces LinkedTracesCfg `mapstructure:"linked-traces"`
 }
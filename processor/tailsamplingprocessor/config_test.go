@@ -67,5 +67,11 @@ func TestLoadConfig(t *testing.T) {
 					RateLimitingCfg: RateLimitingCfg{SpansPerSecond: 35},
 				},
 			},
+			LinkedTraces: LinkedTracesCfg{
+				Enabled:        true,
+				CacheSize:      1000,
+				GossipEndpoint: ":7947",
+				Peers:          []string{"tail-sampling-1:7947", "tail-sampling-2:7947"},
+			},
 		})
 }
@@ -0,0 +1,75 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package decisioncache keeps the IDs of the traces kept by the tail sampling
+// processor, so that the traces linked to them inherit the decision, and
+// gossips them to the other replicas of the processor.
+package decisioncache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a set of trace IDs bounded in size, the least recently added IDs
+// are evicted first. It is safe for concurrent use.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	ids   map[string]*list.Element
+}
+
+// New creates a Cache holding at most size IDs.
+func New(size int) *Cache {
+	return &Cache{
+		size:  size,
+		order: list.New(),
+		ids:   make(map[string]*list.Element, size),
+	}
+}
+
+// Add adds the IDs to the cache, evicting the oldest IDs beyond its size.
+func (c *Cache) Add(ids ...[]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		key := string(id)
+		if e, ok := c.ids[key]; ok {
+			c.order.MoveToFront(e)
+			continue
+		}
+		c.ids[key] = c.order.PushFront(key)
+		if c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.ids, oldest.Value.(string))
+		}
+	}
+}
+
+// Contains returns whether the ID is in the cache.
+func (c *Cache) Contains(id []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.ids[string(id)]
+	return ok
+}
+
+// Len returns the number of IDs in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,37 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisioncache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := New(2)
+	c.Add([]byte("a"), []byte("b"))
+	assert.True(t, c.Contains([]byte("a")))
+	assert.True(t, c.Contains([]byte("b")))
+	assert.False(t, c.Contains([]byte("c")))
+
+	// Adding "a" again makes "b" the oldest ID, evicted by "c".
+	c.Add([]byte("a"))
+	c.Add([]byte("c"))
+	assert.Equal(t, 2, c.Len())
+	assert.True(t, c.Contains([]byte("a")))
+	assert.False(t, c.Contains([]byte("b")))
+	assert.True(t, c.Contains([]byte("c")))
+}
@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisioncache
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// GossipPath is the path of the gossip endpoint receiving the IDs of the
+// traces kept by the other replicas.
+const GossipPath = "/decisions"
+
+const gossipTimeout = 5 * time.Second
+
+// maxGossipBodySize is the maximum size of the bodies posted to the gossip
+// endpoint, about 100k trace IDs.
+const maxGossipBodySize = 4 << 20
+
+// gossipMessage is the JSON body posted to the gossip endpoint of the peers.
+type gossipMessage struct {
+	TraceIDs []string `json:"trace-ids"`
+}
+
+// Gossip shares the IDs of the kept traces with the peer replicas, and adds
+// the IDs received from them to its cache. The IDs received are not relayed,
+// each replica must list all the others as peers.
+type Gossip struct {
+	logger *zap.Logger
+	cache  *Cache
+	peers  []string
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []string
+}
+
+// NewGossip creates a Gossip sharing the IDs with the peers, the gossip
+// endpoints in the host:port form.
+func NewGossip(logger *zap.Logger, cache *Cache, peers []string) *Gossip {
+	return &Gossip{
+		logger: logger,
+		cache:  cache,
+		peers:  peers,
+		client: &http.Client{Timeout: gossipTimeout},
+	}
+}
+
+// Publish queues the IDs to be sent to the peers on the next Flush.
+func (g *Gossip) Publish(ids ...[]byte) {
+	if len(g.peers) == 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, id := range ids {
+		g.pending = append(g.pending, hex.EncodeToString(id))
+	}
+}
+
+// Flush sends the queued IDs to the peers, the IDs failing to be sent to a
+// peer are not retried.
+func (g *Gossip) Flush() {
+	g.mu.Lock()
+	pending := g.pending
+	g.pending = nil
+	g.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(gossipMessage{TraceIDs: pending})
+	if err != nil {
+		g.logger.Warn("Failed to encode the kept trace IDs", zap.Error(err))
+		return
+	}
+	for _, peer := range g.peers {
+		if err := g.send(peer, body); err != nil {
+			g.logger.Warn("Failed to gossip the kept trace IDs", zap.String("peer", peer), zap.Error(err))
+		}
+	}
+}
+
+func (g *Gossip) send(peer string, body []byte) error {
+	resp, err := g.client.Post("http://"+peer+GossipPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return nil
+}
+
+// ServeHTTP adds the IDs posted by the peers to the cache.
+func (g *Gossip) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != GossipPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	var msg gossipMessage
+	body := http.MaxBytesReader(w, r.Body, maxGossipBodySize)
+	if err := json.NewDecoder(body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ids := make([][]byte, 0, len(msg.TraceIDs))
+	for _, s := range msg.TraceIDs {
+		id, err := hex.DecodeString(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid trace ID %q", s), http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+	g.cache.Add(ids...)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisioncache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGossip(t *testing.T) {
+	peerCache := New(10)
+	peer := httptest.NewServer(NewGossip(zap.NewNop(), peerCache, nil))
+	defer peer.Close()
+
+	cache := New(10)
+	g := NewGossip(zap.NewNop(), cache, []string{strings.TrimPrefix(peer.URL, "http://")})
+	g.Publish([]byte{0x01, 0x02}, []byte{0x03})
+	g.Flush()
+
+	assert.True(t, peerCache.Contains([]byte{0x01, 0x02}))
+	assert.True(t, peerCache.Contains([]byte{0x03}))
+	// Publish does not add the IDs to the local cache.
+	assert.Equal(t, 0, cache.Len())
+
+	// Nothing is left to send.
+	g.Flush()
+	assert.Equal(t, 2, peerCache.Len())
+}
+
+func TestGossip_InvalidRequests(t *testing.T) {
+	g := NewGossip(zap.NewNop(), New(10), nil)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodPost, GossipPath, `{"trace-ids": ["0102"]}`, http.StatusNoContent},
+		{http.MethodGet, GossipPath, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/other", `{"trace-ids": []}`, http.StatusNotFound},
+		{http.MethodPost, GossipPath, `not json`, http.StatusBadRequest},
+		{http.MethodPost, GossipPath, `{"trace-ids": ["not hex"]}`, http.StatusBadRequest},
+		{http.MethodPost, GossipPath, `{"trace-ids": ["` + strings.Repeat("00", maxGossipBodySize) + `"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		assert.Equal(t, tt.status, rec.Code, "%s %s %s", tt.method, tt.path, tt.body)
+	}
+}
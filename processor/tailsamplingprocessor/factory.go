@@ -28,6 +28,8 @@ import (
 const (
 	// The value of "type" Tail Sampling in configuration.
 	typeStr = "tail-sampling"
+
+	defaultLinkedTracesCacheSize = 100000
 )
 
 // Factory is the factory for Tail Sampling processor.
@@ -44,6 +46,9 @@ func (f *Factory) CreateDefaultConfig() configmodels.Processor {
 	return &Config{
 		DecisionWait: 30 * time.Second,
 		NumTraces:    50000,
+		LinkedTraces: LinkedTracesCfg{
+			CacheSize: defaultLinkedTracesCacheSize,
+		},
 	}
 }
 
@@ -115,6 +115,20 @@ func SamplingProcessorMetricViews(level telemetry.Level) []*view.View {
 		Aggregation: view.LastValue(),
 	}
 
+	if level == telemetry.Minimal {
+		// The latency and age distributions are the most expensive views, they
+		// are only left out at the minimal level.
+		return []*view.View{
+			countPolicyEvaluationErrorView,
+
+			countTracesSampledView,
+
+			countTraceDroppedTooEarlyView,
+			countTraceIDArrivalView,
+			trackTracesOnMemorylView,
+		}
+	}
+
 	return []*view.View{
 		decisionLatencyView,
 		overallDecisionLatencyView,
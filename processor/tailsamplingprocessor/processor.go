@@ -17,6 +17,8 @@ package tailsamplingprocessor
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -32,6 +34,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/decisioncache"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/idbatcher"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/sampling"
 )
@@ -51,6 +54,10 @@ type Policy struct {
 // type to help track usage.
 type traceKey string
 
+// linkedTracesPolicyName is the name of the policy sampling the traces linked
+// to kept traces.
+const linkedTracesPolicyName = "linked-traces"
+
 // tailSamplingSpanProcessor handles the incoming trace data and uses the given sampling
 // policy to sample traces.
 type tailSamplingSpanProcessor struct {
@@ -65,13 +72,22 @@ type tailSamplingSpanProcessor struct {
 	decisionBatcher idbatcher.Batcher
 	deleteChan      chan traceKey
 	numTracesOnMap  uint64
+	// keptTraces and gossip are only set when the traces linked to kept
+	// traces inherit the decision.
+	keptTraces *decisioncache.Cache
+	gossip     *decisioncache.Gossip
+	// gossipServer serves the gossip endpoint, nil if there is none.
+	gossipServer *http.Server
 }
 
 const (
 	sourceFormat = "tail-sampling"
 )
 
-var _ processor.TraceProcessor = (*tailSamplingSpanProcessor)(nil)
+var (
+	_ processor.TraceProcessor = (*tailSamplingSpanProcessor)(nil)
+	_ processor.Shutdowner     = (*tailSamplingSpanProcessor)(nil)
+)
 
 // NewTraceProcessor returns a processor.TraceProcessor that will perform tail sampling according to the given
 // configuration.
@@ -111,15 +127,56 @@ func NewTraceProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer,
 		maxNumTraces:    cfg.NumTraces,
 		logger:          logger,
 		decisionBatcher: inBatcher,
-		policies:        policies,
 	}
 
+	if cfg.LinkedTraces.Enabled {
+		if cfg.LinkedTraces.CacheSize <= 0 {
+			return nil, fmt.Errorf("linked-traces cache-size must be positive, got %d", cfg.LinkedTraces.CacheSize)
+		}
+		policyCtx, err := tag.New(ctx, tag.Upsert(tagPolicyKey, linkedTracesPolicyName), tag.Upsert(observability.TagKeyReceiver, sourceFormat))
+		if err != nil {
+			return nil, err
+		}
+		tsp.keptTraces = decisioncache.New(cfg.LinkedTraces.CacheSize)
+		// Evaluated last so that it only samples the traces the other
+		// policies did not sample.
+		policies = append(policies, &Policy{
+			Name:      linkedTracesPolicyName,
+			Evaluator: sampling.NewLinkedTraces(tsp.keptTraces.Contains),
+			ctx:       policyCtx,
+		})
+		tsp.gossip = decisioncache.NewGossip(logger, tsp.keptTraces, cfg.LinkedTraces.Peers)
+		if cfg.LinkedTraces.GossipEndpoint != "" {
+			ln, err := net.Listen("tcp", cfg.LinkedTraces.GossipEndpoint)
+			if err != nil {
+				return nil, fmt.Errorf("failed to bind to linked-traces gossip-endpoint %q: %v", cfg.LinkedTraces.GossipEndpoint, err)
+			}
+			tsp.gossipServer = &http.Server{Handler: tsp.gossip}
+			go func() {
+				if err := tsp.gossipServer.Serve(ln); err != http.ErrServerClosed {
+					logger.Error("Linked traces gossip server stopped", zap.Error(err))
+				}
+			}()
+		}
+	} else if len(cfg.LinkedTraces.Peers) > 0 || cfg.LinkedTraces.GossipEndpoint != "" {
+		return nil, fmt.Errorf("linked-traces peers and gossip-endpoint require linked-traces to be enabled")
+	}
+	tsp.policies = policies
+
 	tsp.policyTicker = &policyTicker{onTick: tsp.samplingPolicyOnTick}
 	tsp.deleteChan = make(chan traceKey, cfg.NumTraces)
 
 	return tsp, nil
 }
 
+// Shutdown closes the gossip endpoint, if any.
+func (tsp *tailSamplingSpanProcessor) Shutdown(ctx context.Context) error {
+	if tsp.gossipServer == nil {
+		return nil
+	}
+	return tsp.gossipServer.Shutdown(ctx)
+}
+
 func getPolicyEvaluator(cfg *PolicyCfg) (sampling.PolicyEvaluator, error) {
 	switch cfg.Type {
 	case AlwaysSample:
@@ -193,11 +250,18 @@ func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() {
 			}
 		}
 
+		if tsp.keptTraces != nil {
+			tsp.keepLinkedTraces(id, trace)
+		}
+
 		// Sampled or not, remove the batches
 		trace.Lock()
 		trace.ReceivedBatches = nil
 		trace.Unlock()
 	}
+	if tsp.gossip != nil {
+		go tsp.gossip.Flush()
+	}
 
 	stats.Record(tsp.ctx,
 		statOverallDecisionLatencyµs.M(int64(time.Since(startTime)/time.Microsecond)),
@@ -214,6 +278,32 @@ func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() {
 	)
 }
 
+// keepLinkedTraces remembers the sampled trace and the traces its spans link
+// to, so that the traces linked to it inherit the decision, and shares them
+// with the peers.
+func (tsp *tailSamplingSpanProcessor) keepLinkedTraces(id []byte, trace *sampling.TraceData) {
+	trace.Lock()
+	defer trace.Unlock()
+	sampled := false
+	for _, decision := range trace.Decisions {
+		if decision == sampling.Sampled {
+			sampled = true
+			break
+		}
+	}
+	if !sampled {
+		return
+	}
+	ids := [][]byte{id}
+	for _, batch := range trace.ReceivedBatches {
+		for _, span := range batch.Spans {
+			ids = append(ids, sampling.LinkedTraceIDs(span)...)
+		}
+	}
+	tsp.keptTraces.Add(ids...)
+	tsp.gossip.Publish(ids...)
+}
+
 // ConsumeTraceData is required by the SpanProcessor interface.
 func (tsp *tailSamplingSpanProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
 	tsp.start.Do(func() {
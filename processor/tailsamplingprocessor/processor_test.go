@@ -16,6 +16,9 @@ package tailsamplingprocessor
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -25,7 +28,9 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/decisioncache"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/idbatcher"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/sampling"
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
@@ -217,6 +222,124 @@ func TestSamplingPolicyTypicalPath(t *testing.T) {
 	}
 }
 
+func TestLinkedTracesInheritDecision(t *testing.T) {
+	cfg := Config{
+		DecisionWait:            time.Second,
+		NumTraces:               100,
+		ExpectedNewTracesPerSec: 10,
+		PolicyCfgs: []PolicyCfg{{
+			Name:               "errors",
+			Type:               StringAttribute,
+			StringAttributeCfg: StringAttributeCfg{Key: "error", Values: []string{"true"}},
+		}},
+		LinkedTraces: LinkedTracesCfg{Enabled: true, CacheSize: 10},
+	}
+	msp := &mockSpanProcessor{}
+	sp, err := NewTraceProcessor(zap.NewNop(), msp, cfg)
+	if err != nil {
+		t.Fatalf("NewTraceProcessor failed: %v", err)
+	}
+	tsp := sp.(*tailSamplingSpanProcessor)
+	tsp.decisionBatcher = newSyncIDBatcher(1)
+	tsp.policyTicker = &manualTTicker{}
+
+	traceID := func(i uint64) []byte {
+		return tracetranslator.UInt64ToByteTraceID(1, i)
+	}
+	span := func(id []byte, sampled bool, links ...[]byte) *tracepb.Span {
+		s := &tracepb.Span{TraceId: id, SpanId: tracetranslator.UInt64ToByteSpanID(1)}
+		if sampled {
+			s.Attributes = &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+				"error": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "true"}}},
+			}}
+		}
+		if len(links) > 0 {
+			s.Links = &tracepb.Span_Links{}
+			for _, link := range links {
+				s.Links.Link = append(s.Links.Link, &tracepb.Span_Link{TraceId: link})
+			}
+		}
+		return s
+	}
+
+	// Trace 1 is sampled and trace 2 links to it. Trace 3 is sampled and
+	// links to trace 4. Trace 5 is not linked.
+	tsp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: []*tracepb.Span{
+		span(traceID(1), true),
+		span(traceID(3), true, traceID(4)),
+	}})
+	tsp.samplingPolicyOnTick()
+	tsp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: []*tracepb.Span{
+		span(traceID(2), false, traceID(1)),
+		span(traceID(4), false),
+		span(traceID(5), false),
+	}})
+	tsp.samplingPolicyOnTick()
+	if msp.TotalSpans != 2 {
+		t.Fatalf("sampled traces were not forwarded: got %d spans, want 2", msp.TotalSpans)
+	}
+	tsp.samplingPolicyOnTick()
+	if msp.TotalSpans != 4 {
+		t.Fatalf("linked traces were not forwarded: got %d spans, want 4", msp.TotalSpans)
+	}
+	if tsp.keptTraces.Contains(traceID(5)) {
+		t.Fatalf("trace without link was kept")
+	}
+}
+
+func TestLinkedTracesGossipEndpoint(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	cfg := Config{
+		DecisionWait:            time.Second,
+		NumTraces:               100,
+		ExpectedNewTracesPerSec: 10,
+		PolicyCfgs:              testPolicy,
+		LinkedTraces:            LinkedTracesCfg{Enabled: true, CacheSize: 10, GossipEndpoint: addr},
+	}
+	sp, err := NewTraceProcessor(zap.NewNop(), &exportertest.SinkTraceExporter{}, cfg)
+	if err != nil {
+		t.Fatalf("NewTraceProcessor failed: %v", err)
+	}
+	tsp := sp.(*tailSamplingSpanProcessor)
+
+	resp, err := http.Post("http://"+addr+decisioncache.GossipPath, "application/json", strings.NewReader(`{"trace-ids": ["0102"]}`))
+	if err != nil {
+		t.Fatalf("failed to post to the gossip endpoint: %v", err)
+	}
+	resp.Body.Close()
+	if !tsp.keptTraces.Contains([]byte{0x01, 0x02}) {
+		t.Fatalf("gossiped trace ID was not kept")
+	}
+
+	// The endpoint is closed on shutdown.
+	if err := tsp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("gossip endpoint still bound after shutdown: %v", err)
+	}
+	ln.Close()
+}
+
+func TestLinkedTracesConfigErrors(t *testing.T) {
+	cfg := Config{
+		DecisionWait:            time.Second,
+		NumTraces:               100,
+		ExpectedNewTracesPerSec: 10,
+		PolicyCfgs:              testPolicy,
+		LinkedTraces:            LinkedTracesCfg{Enabled: true},
+	}
+	if _, err := NewTraceProcessor(zap.NewNop(), &exportertest.SinkTraceExporter{}, cfg); err == nil {
+		t.Fatalf("expected an error for a zero cache-size")
+	}
+
+	cfg.LinkedTraces = LinkedTracesCfg{Peers: []string{"localhost:7947"}}
+	if _, err := NewTraceProcessor(zap.NewNop(), &exportertest.SinkTraceExporter{}, cfg); err == nil {
+		t.Fatalf("expected an error for peers without linked-traces enabled")
+	}
+}
+
 func generateIdsAndBatches(numIds int) ([][]byte, []consumerdata.TraceData) {
 	traceIds := make([][]byte, numIds)
 	for i := 0; i < numIds; i++ {
@@ -0,0 +1,86 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+type linkedTraces struct {
+	isKept func(traceID []byte) bool
+}
+
+var _ PolicyEvaluator = (*linkedTraces)(nil)
+
+// NewLinkedTraces creates a policy evaluator that samples the traces linked to
+// kept traces, either because the trace itself is kept by a trace linking to
+// it or because one of its spans links to a kept trace. The traces already
+// sampled by the policies evaluated before it are not sampled again.
+func NewLinkedTraces(isKept func(traceID []byte) bool) PolicyEvaluator {
+	return &linkedTraces{isKept: isKept}
+}
+
+// OnLateArrivingSpans notifies the evaluator that the given list of spans arrived
+// after the sampling decision was already taken for the trace.
+// This gives the evaluator a chance to log any message/metrics and/or update any
+// related internal state.
+func (lt *linkedTraces) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return nil
+}
+
+// Evaluate looks at the trace data and returns a corresponding SamplingDecision.
+func (lt *linkedTraces) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	trace.Lock()
+	decisions := trace.Decisions
+	batches := trace.ReceivedBatches
+	trace.Unlock()
+	for _, decision := range decisions {
+		if decision == Sampled {
+			return NotSampled, nil
+		}
+	}
+
+	if lt.isKept(traceID) {
+		return Sampled, nil
+	}
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			for _, linkedID := range LinkedTraceIDs(span) {
+				if lt.isKept(linkedID) {
+					return Sampled, nil
+				}
+			}
+		}
+	}
+	return NotSampled, nil
+}
+
+// OnDroppedSpans is called when the trace needs to be dropped, due to memory
+// pressure, before the decision_wait time has been reached.
+func (lt *linkedTraces) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
+
+// LinkedTraceIDs returns the IDs of the other traces the span links to.
+func LinkedTraceIDs(span *tracepb.Span) [][]byte {
+	if span == nil || span.Links == nil {
+		return nil
+	}
+	var ids [][]byte
+	for _, link := range span.Links.Link {
+		if link != nil && len(link.TraceId) == 16 && string(link.TraceId) != string(span.TraceId) {
+			ids = append(ids, link.TraceId)
+		}
+	}
+	return ids
+}
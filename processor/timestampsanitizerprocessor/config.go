@@ -0,0 +1,38 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampsanitizerprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the timestamp sanitizer processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// MaxPast is how far in the past the timestamps of the spans can be, the
+	// older ones, e.g. the unset ones decoded as 1970, are clamped.
+	MaxPast time.Duration `mapstructure:"max-past"`
+
+	// MaxFuture is how far in the future the timestamps of the spans can be,
+	// the later ones, e.g. the overflowed ones decoded as 2262, are clamped.
+	MaxFuture time.Duration `mapstructure:"max-future"`
+
+	// Attribute is the span attribute annotating the clamped spans with the
+	// comma separated list of the clamped timestamps.
+	Attribute string `mapstructure:"attribute"`
+}
@@ -0,0 +1,155 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package timestampsanitizerprocessor clamps the bogus timestamps of the
+// spans, e.g. unset or overflowed ones and spans ending before they start,
+// which break the retention of several backends.
+package timestampsanitizerprocessor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// The clamped timestamps listed by the annotation attribute.
+const (
+	clampedStartTime      = "start_time"
+	clampedEndTime        = "end_time"
+	clampedEndBeforeStart = "end_before_start"
+	clampedTimeEvent      = "time_event"
+)
+
+type timestampSanitizer struct {
+	nextConsumer consumer.TraceConsumer
+	maxPast      time.Duration
+	maxFuture    time.Duration
+	attribute    string
+
+	// now is replaced by the tests.
+	now func() time.Time
+}
+
+var _ processor.TraceProcessor = (*timestampSanitizer)(nil)
+
+func newTimestampSanitizer(nextConsumer consumer.TraceConsumer, cfg Config) (*timestampSanitizer, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &timestampSanitizer{
+		nextConsumer: nextConsumer,
+		maxPast:      cfg.MaxPast,
+		maxFuture:    cfg.MaxFuture,
+		attribute:    cfg.Attribute,
+		now:          time.Now,
+	}, nil
+}
+
+func (ts *timestampSanitizer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	now := ts.now()
+	min, max := now.Add(-ts.maxPast), now.Add(ts.maxFuture)
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		if clamped := sanitize(span, now, min, max); len(clamped) > 0 && ts.attribute != "" {
+			annotate(span, ts.attribute, strings.Join(clamped, ","))
+		}
+	}
+	return ts.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+// sanitize clamps the timestamps of the span out of [min, max] and returns
+// the list of the clamped ones. An out of range start or end is replaced by
+// the other one, or both by now, an end before the start by the start, and
+// the time events are clamped within the span.
+func sanitize(span *tracepb.Span, now, min, max time.Time) []string {
+	var clamped []string
+	start, startOK := inRange(span.StartTime, min, max)
+	end, endOK := inRange(span.EndTime, min, max)
+	switch {
+	case !startOK && !endOK:
+		start, end = now, now
+		clamped = append(clamped, clampedStartTime, clampedEndTime)
+	case !startOK:
+		start = end
+		clamped = append(clamped, clampedStartTime)
+	case !endOK:
+		end = start
+		clamped = append(clamped, clampedEndTime)
+	case end.Before(start):
+		end = start
+		clamped = append(clamped, clampedEndBeforeStart)
+	}
+	if len(clamped) > 0 {
+		span.StartTime = internal.TimeToTimestamp(start)
+		span.EndTime = internal.TimeToTimestamp(end)
+	}
+
+	eventClamped := false
+	for _, event := range span.GetTimeEvents().GetTimeEvent() {
+		if event == nil {
+			continue
+		}
+		t, ok := inRange(event.Time, start, end)
+		if ok {
+			continue
+		}
+		// The unset times are clamped to the start.
+		if t.After(end) {
+			t = end
+		} else {
+			t = start
+		}
+		event.Time = internal.TimeToTimestamp(t)
+		eventClamped = true
+	}
+	if eventClamped {
+		clamped = append(clamped, clampedTimeEvent)
+	}
+	return clamped
+}
+
+// inRange returns the time of the timestamp and whether it is set and within
+// [min, max], min is returned for an unset timestamp.
+func inRange(ts *timestamp.Timestamp, min, max time.Time) (time.Time, bool) {
+	if ts == nil {
+		return min, false
+	}
+	t := time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+	return t, !t.Before(min) && !t.After(max)
+}
+
+func annotate(span *tracepb.Span, key, value string) {
+	if span.Attributes == nil {
+		span.Attributes = &tracepb.Span_Attributes{}
+	}
+	if span.Attributes.AttributeMap == nil {
+		span.Attributes.AttributeMap = make(map[string]*tracepb.AttributeValue)
+	}
+	span.Attributes.AttributeMap[key] = &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: value},
+		},
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampsanitizerprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+var testNow = time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+
+func TestTimestampSanitizer(t *testing.T) {
+	valid := testNow.Add(-time.Minute)
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		unset     bool
+		wantStart time.Time
+		wantEnd   time.Time
+		want      string
+	}{
+		{
+			name:      "valid",
+			start:     valid,
+			end:       valid.Add(time.Second),
+			wantStart: valid,
+			wantEnd:   valid.Add(time.Second),
+		},
+		{
+			name:      "start in 1970",
+			start:     time.Unix(0, 0),
+			end:       valid,
+			wantStart: valid,
+			wantEnd:   valid,
+			want:      "start_time",
+		},
+		{
+			name:      "end in 2262",
+			start:     valid,
+			end:       time.Unix(0, 1<<63-1),
+			wantStart: valid,
+			wantEnd:   valid,
+			want:      "end_time",
+		},
+		{
+			name:      "unset",
+			unset:     true,
+			wantStart: testNow,
+			wantEnd:   testNow,
+			want:      "start_time,end_time",
+		},
+		{
+			name:      "end before start",
+			start:     valid,
+			end:       valid.Add(-time.Second),
+			wantStart: valid,
+			wantEnd:   valid,
+			want:      "end_before_start",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := &tracepb.Span{}
+			if !tt.unset {
+				span.StartTime = internal.TimeToTimestamp(tt.start)
+				span.EndTime = internal.TimeToTimestamp(tt.end)
+			}
+			got := sanitizeSpan(t, span)
+
+			assert.Equal(t, internal.TimeToTimestamp(tt.wantStart), got.StartTime)
+			assert.Equal(t, internal.TimeToTimestamp(tt.wantEnd), got.EndTime)
+			annotation := got.GetAttributes().GetAttributeMap()[defaultAttribute]
+			assert.Equal(t, tt.want, annotation.GetStringValue().GetValue())
+		})
+	}
+}
+
+func TestTimestampSanitizer_TimeEvents(t *testing.T) {
+	start := testNow.Add(-time.Minute)
+	end := start.Add(time.Second)
+	span := &tracepb.Span{
+		StartTime: internal.TimeToTimestamp(start),
+		EndTime:   internal.TimeToTimestamp(end),
+		TimeEvents: &tracepb.Span_TimeEvents{
+			TimeEvent: []*tracepb.Span_TimeEvent{
+				{Time: internal.TimeToTimestamp(start.Add(time.Millisecond))},
+				{Time: internal.TimeToTimestamp(time.Unix(0, 0))},
+				{Time: internal.TimeToTimestamp(end.Add(time.Hour))},
+				{},
+			},
+		},
+	}
+	got := sanitizeSpan(t, span)
+
+	events := got.TimeEvents.TimeEvent
+	assert.Equal(t, internal.TimeToTimestamp(start.Add(time.Millisecond)), events[0].Time)
+	assert.Equal(t, internal.TimeToTimestamp(start), events[1].Time)
+	assert.Equal(t, internal.TimeToTimestamp(end), events[2].Time)
+	assert.Equal(t, internal.TimeToTimestamp(start), events[3].Time)
+	annotation := got.GetAttributes().GetAttributeMap()[defaultAttribute]
+	assert.Equal(t, "time_event", annotation.GetStringValue().GetValue())
+}
+
+func TestTimestampSanitizer_NoAttribute(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Attribute = ""
+	sink := &exportertest.SinkTraceExporter{}
+	ts, err := newTimestampSanitizer(sink, *cfg)
+	require.NoError(t, err)
+	ts.now = func() time.Time { return testNow }
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{{}, nil}}
+	require.NoError(t, ts.ConsumeTraceData(context.Background(), td))
+	got := sink.AllTraces()[0].Spans[0]
+	assert.Equal(t, internal.TimeToTimestamp(testNow), got.StartTime)
+	assert.Nil(t, got.Attributes)
+}
+
+func sanitizeSpan(t *testing.T, span *tracepb.Span) *tracepb.Span {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	sink := &exportertest.SinkTraceExporter{}
+	ts, err := newTimestampSanitizer(sink, *cfg)
+	require.NoError(t, err)
+	ts.now = func() time.Time { return testNow }
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{span}}
+	require.NoError(t, ts.ConsumeTraceData(context.Background(), td))
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+	return got[0].Spans[0]
+}
@@ -0,0 +1,48 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracebufferprocessor records the spans passing through it in the
+// buffer of recent traces served by a trace-buffer extension. The spans are
+// passed unchanged to the next consumer.
+package tracebufferprocessor
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/extensionfeed"
+	"github.com/open-telemetry/opentelemetry-service/internal/tracebuffer"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+func newTraceBufferProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg Config,
+) (processor.TraceProcessor, error) {
+	return extensionfeed.NewTraceProcessor(logger, nextConsumer, cfg.Extension, addToBuffer,
+		"Trace buffer extension is not running, spans are not buffered")
+}
+
+// addToBuffer adds the spans to the buffer of the trace-buffer extension named
+// extension, if it is running.
+func addToBuffer(extension string, td consumerdata.TraceData) bool {
+	buffer := tracebuffer.Lookup(extension)
+	if buffer == nil {
+		return false
+	}
+	buffer.Add(td.Node, td.Spans)
+	return true
+}
@@ -0,0 +1,110 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracebufferprocessor
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/tracebuffer"
+)
+
+var (
+	traceID = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	rootID  = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	childID = []byte{2, 3, 4, 5, 6, 7, 8, 9}
+)
+
+const hexTraceID = "0102030405060708090a0b0c0d0e0f10"
+
+func batch(service string, spans ...*tracepb.Span) consumerdata.TraceData {
+	return consumerdata.TraceData{
+		Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: service}},
+		Spans: spans,
+	}
+}
+
+func TestTraceBufferProcessor_AssemblesTraces(t *testing.T) {
+	buffer := tracebuffer.NewBuffer(10, 10)
+	tracebuffer.Register("trace-buffer/test", buffer)
+	defer tracebuffer.Unregister("trace-buffer/test")
+
+	sink := &exportertest.SinkTraceExporter{}
+	tbp, err := newTraceBufferProcessor(zap.NewNop(), sink, Config{Extension: "trace-buffer/test"})
+	require.NoError(t, err)
+
+	// The spans of a trace reported by different services in separate
+	// batches are buffered together.
+	frontend := batch("frontend", &tracepb.Span{
+		TraceId: traceID,
+		SpanId:  rootID,
+		Name:    &tracepb.TruncatableString{Value: "GET /"},
+	})
+	backend := batch("backend", &tracepb.Span{
+		TraceId:      traceID,
+		SpanId:       childID,
+		ParentSpanId: rootID,
+		Name:         &tracepb.TruncatableString{Value: "query"},
+	})
+	require.NoError(t, tbp.ConsumeTraceData(context.Background(), frontend))
+	require.NoError(t, tbp.ConsumeTraceData(context.Background(), backend))
+	assert.Equal(t, []consumerdata.TraceData{frontend, backend}, sink.AllTraces())
+
+	trace, ok := buffer.Get(hexTraceID)
+	require.True(t, ok)
+	require.Len(t, trace.Spans, 2)
+	assert.Equal(t, "frontend", trace.Spans[0].Service)
+	assert.Equal(t, "GET /", trace.Spans[0].Name)
+	assert.Equal(t, "backend", trace.Spans[1].Service)
+	assert.Equal(t, "0102030405060708", trace.Spans[1].ParentSpanID)
+
+	summaries := buffer.List("backend", 10)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, hexTraceID, summaries[0].TraceID)
+	assert.Equal(t, "GET /", summaries[0].Root)
+}
+
+func TestTraceBufferProcessor_ExtensionRestarted(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tbp, err := newTraceBufferProcessor(zap.NewNop(), sink, Config{Extension: "trace-buffer/test"})
+	require.NoError(t, err)
+	td := batch("frontend", &tracepb.Span{TraceId: traceID, SpanId: rootID})
+
+	// The spans passing through while the extension is stopped are lost, the
+	// buffer of the restarted extension only gets the next ones.
+	before := tracebuffer.NewBuffer(10, 10)
+	tracebuffer.Register("trace-buffer/test", before)
+	tracebuffer.Unregister("trace-buffer/test")
+	require.NoError(t, tbp.ConsumeTraceData(context.Background(), td))
+	_, ok := before.Get(hexTraceID)
+	assert.False(t, ok)
+
+	after := tracebuffer.NewBuffer(10, 10)
+	tracebuffer.Register("trace-buffer/test", after)
+	defer tracebuffer.Unregister("trace-buffer/test")
+	require.NoError(t, tbp.ConsumeTraceData(context.Background(), td))
+	trace, ok := after.Get(hexTraceID)
+	require.True(t, ok)
+	assert.Len(t, trace.Spans, 1)
+	assert.Len(t, sink.AllTraces(), 2)
+}
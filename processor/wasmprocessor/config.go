@@ -0,0 +1,38 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the WASM processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Module is the path of the WebAssembly module processing the batches.
+	Module string `mapstructure:"module"`
+
+	// ReloadInterval is the interval between two checks of the module file,
+	// the module is reloaded when the file changes. Zero disables the reload.
+	ReloadInterval time.Duration `mapstructure:"reload-interval"`
+
+	// ExecutionBudget is the number of steps, function calls and loop
+	// iterations, the module can run on a batch before the batch is failed.
+	// Zero disables the limit.
+	ExecutionBudget int64 `mapstructure:"execution-budget"`
+}
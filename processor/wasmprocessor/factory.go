@@ -0,0 +1,104 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "wasm"
+
+	defaultReloadInterval  = 10 * time.Second
+	defaultExecutionBudget = 10000000
+)
+
+// Factory is the factory for the WASM processor.
+type Factory struct {
+}
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the processor.
+func (f *Factory) CreateDefaultConfig() configmodels.Processor {
+	return &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		ReloadInterval:  defaultReloadInterval,
+		ExecutionBudget: defaultExecutionBudget,
+	}
+}
+
+// CreateTraceProcessor creates a trace processor based on this config. It
+// loads the module, which must export the traces function.
+func (f *Factory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	oCfg := cfg.(*Config)
+	if err := validate(oCfg); err != nil {
+		return nil, err
+	}
+	tp, err := newWasmTraceProcessor(logger, nextConsumer, *oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
+
+// CreateMetricsProcessor creates a metrics processor based on this config. It
+// loads the module, which must export the metrics function.
+func (f *Factory) CreateMetricsProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Processor,
+) (processor.MetricsProcessor, error) {
+	oCfg := cfg.(*Config)
+	if err := validate(oCfg); err != nil {
+		return nil, err
+	}
+	mp, err := newWasmMetricsProcessor(logger, nextConsumer, *oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return mp, nil
+}
+
+func validate(cfg *Config) error {
+	if cfg.Module == "" {
+		return fmt.Errorf("%q config requires a \"module\"", cfg.Name())
+	}
+	if cfg.ReloadInterval < 0 {
+		return fmt.Errorf("%q config requires a non-negative \"reload-interval\"", cfg.Name())
+	}
+	if cfg.ExecutionBudget < 0 {
+		return fmt.Errorf("%q config requires a non-negative \"execution-budget\"", cfg.Name())
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := Factory{}
+	assert.Equal(t, typeStr, factory.Type())
+}
+
+func TestFactory_CreateTraceProcessor(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	factory := Factory{}
+	cfg := testConfig(testModule{traces: identityBody}.write(t, dir))
+
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), &cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, tp)
+	tp.(*wasmTraceProcessor).stop()
+
+	// The default config has no module.
+	tp, err = factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), factory.CreateDefaultConfig())
+	assert.Error(t, err)
+	assert.Nil(t, tp)
+
+	cfg.ReloadInterval = -1
+	tp, err = factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), &cfg)
+	assert.Error(t, err)
+	assert.Nil(t, tp)
+}
+
+func TestFactory_CreateMetricsProcessor(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	factory := Factory{}
+	cfg := testConfig(testModule{metrics: identityBody}.write(t, dir))
+
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), &cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, mp)
+	mp.(*wasmMetricsProcessor).stop()
+
+	mp, err = factory.CreateMetricsProcessor(zap.NewNop(), nil, &cfg)
+	assert.Error(t, err)
+	assert.Nil(t, mp)
+}
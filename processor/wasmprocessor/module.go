@@ -0,0 +1,355 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/go-interpreter/wagon/disasm"
+	"github.com/go-interpreter/wagon/exec"
+	wasmvalidate "github.com/go-interpreter/wagon/validate"
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/go-interpreter/wagon/wasm/leb128"
+	ops "github.com/go-interpreter/wagon/wasm/operators"
+	"go.uber.org/zap"
+)
+
+// Names of the functions of the ABI between the processor and the modules.
+const (
+	allocExport   = "alloc"
+	tracesExport  = "process_traces"
+	metricsExport = "process_metrics"
+
+	hostModule = "env"
+	logImport  = "log"
+)
+
+var (
+	allocSig = wasm.FunctionSig{
+		Form:        wasm.TypeFunc,
+		ParamTypes:  []wasm.ValueType{wasm.ValueTypeI32},
+		ReturnTypes: []wasm.ValueType{wasm.ValueTypeI32},
+	}
+	processSig = wasm.FunctionSig{
+		Form:        wasm.TypeFunc,
+		ParamTypes:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32},
+		ReturnTypes: []wasm.ValueType{wasm.ValueTypeI64},
+	}
+	logSig = wasm.FunctionSig{
+		Form:       wasm.TypeFunc,
+		ParamTypes: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32},
+	}
+
+	errModuleFailed    = errors.New("wasm module failed to process the batch")
+	errOutOfBounds     = errors.New("wasm module returned a buffer out of its memory")
+	errBudgetExhausted = errors.New("wasm module exhausted its execution budget")
+)
+
+// module is an instance of a WebAssembly module. Its memory and globals are
+// reset before each batch, so that the modules can allocate the buffers
+// without freeing them.
+type module struct {
+	vm *exec.VM
+	// memory is the content of the memory after the instantiation.
+	memory []byte
+
+	alloc int64
+	// traces and metrics are the indexes of the processing functions, -1
+	// when they are not exported.
+	traces  int64
+	metrics int64
+}
+
+// loadModule reads, validates and instantiates the module at path. The
+// messages logged by the module are logged with logger, and each call of the
+// module is limited to budget steps, unless budget is zero.
+func loadModule(logger *zap.Logger, path string, budget int64) (mod *module, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := wasm.ReadModule(f, hostResolver(logger))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read wasm module %q: %v", path, err)
+	}
+	if err := wasmvalidate.VerifyModule(m); err != nil {
+		return nil, fmt.Errorf("invalid wasm module %q: %v", path, err)
+	}
+	if m.Memory == nil || len(m.Memory.Entries) == 0 {
+		return nil, fmt.Errorf("wasm module %q has no memory", path)
+	}
+	if budget > 0 {
+		if err := limitSteps(m, budget); err != nil {
+			return nil, fmt.Errorf("cannot instrument wasm module %q: %v", path, err)
+		}
+	}
+
+	mod = &module{}
+	if mod.alloc, err = lookupFunction(m, allocExport, allocSig); err != nil {
+		return nil, fmt.Errorf("invalid wasm module %q: %v", path, err)
+	}
+	if mod.alloc < 0 {
+		return nil, fmt.Errorf("wasm module %q does not export %q", path, allocExport)
+	}
+	if mod.traces, err = lookupFunction(m, tracesExport, processSig); err != nil {
+		return nil, fmt.Errorf("invalid wasm module %q: %v", path, err)
+	}
+	if mod.metrics, err = lookupFunction(m, metricsExport, processSig); err != nil {
+		return nil, fmt.Errorf("invalid wasm module %q: %v", path, err)
+	}
+
+	// The start function of the module runs in NewVM, before the panics of
+	// the VM can be recovered.
+	defer func() {
+		if r := recover(); r != nil {
+			mod, err = nil, fmt.Errorf("cannot instantiate wasm module %q: %v", path, r)
+		}
+	}()
+	vm, err := exec.NewVM(m)
+	if err != nil {
+		return nil, fmt.Errorf("cannot instantiate wasm module %q: %v", path, err)
+	}
+	vm.RecoverPanic = true
+	mod.vm = vm
+	mod.memory = append([]byte(nil), vm.Memory()...)
+	return mod, nil
+}
+
+// lookupFunction returns the index of the function exported as name, or -1
+// if there is none.
+func lookupFunction(m *wasm.Module, name string, sig wasm.FunctionSig) (int64, error) {
+	if m.Export == nil {
+		return -1, nil
+	}
+	entry, ok := m.Export.Entries[name]
+	if !ok {
+		return -1, nil
+	}
+	if entry.Kind != wasm.ExternalFunction {
+		return -1, fmt.Errorf("export %q is not a function", name)
+	}
+	fn := m.GetFunction(int(entry.Index))
+	if fn == nil || !sameTypes(fn.Sig.ParamTypes, sig.ParamTypes) || !sameTypes(fn.Sig.ReturnTypes, sig.ReturnTypes) {
+		return -1, fmt.Errorf("function %q does not have the signature %v", name, sig)
+	}
+	return int64(entry.Index), nil
+}
+
+func sameTypes(a, b []wasm.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// limitSteps instruments the functions of the module so that each call of
+// the module can run at most budget steps, a step being a function call or an
+// iteration of a loop. Once the budget is spent the module calls a host
+// function failing the call with errBudgetExhausted.
+//
+// The budget is kept in a global added after the globals of the module, so
+// that it is reset with them before each batch, and the host function is
+// added after the functions of the module, so that their indexes do not
+// change. The instrumentation runs after the validation, the module cannot
+// reference them.
+func limitSteps(m *wasm.Module, budget int64) error {
+	global := uint32(len(m.GlobalIndexSpace))
+	init := append([]byte{ops.I64Const}, sleb128(budget)...)
+	m.GlobalIndexSpace = append(m.GlobalIndexSpace, wasm.GlobalEntry{
+		Type: wasm.GlobalVar{Type: wasm.ValueTypeI64, Mutable: true},
+		Init: append(init, ops.End),
+	})
+
+	exhausted := uint32(len(m.FunctionIndexSpace))
+	check, err := stepInstrs(global, exhausted)
+	if err != nil {
+		return err
+	}
+	for i := range m.FunctionIndexSpace {
+		fn := &m.FunctionIndexSpace[i]
+		if fn.IsHost() {
+			continue
+		}
+		instrs, err := disasm.Disassemble(fn.Body.Code)
+		if err != nil {
+			return err
+		}
+		out := append([]disasm.Instr(nil), check...)
+		for _, instr := range instrs {
+			out = append(out, instr)
+			if instr.Op.Code == ops.Loop {
+				out = append(out, check...)
+			}
+		}
+		if fn.Body.Code, err = disasm.Assemble(out); err != nil {
+			return err
+		}
+	}
+
+	// The disassembler looks the signatures of the called functions up in
+	// the type and function sections.
+	m.Types.Entries = append(m.Types.Entries, wasm.FunctionSig{Form: wasm.TypeFunc})
+	m.Function.Types = append(m.Function.Types, uint32(len(m.Types.Entries)-1))
+	m.FunctionIndexSpace = append(m.FunctionIndexSpace, wasm.Function{
+		Sig:  &m.Types.Entries[len(m.Types.Entries)-1],
+		Host: reflect.ValueOf(func(*exec.Process) { panic(errBudgetExhausted) }),
+		Body: &wasm.FunctionBody{},
+	})
+	return nil
+}
+
+// stepInstrs returns the instructions counting a step in the global and
+// calling the function exhausted once the global reaches zero.
+func stepInstrs(global, exhausted uint32) ([]disasm.Instr, error) {
+	codes := []struct {
+		code      byte
+		immediate interface{}
+	}{
+		{ops.GetGlobal, global},
+		{ops.I64Eqz, nil},
+		{ops.If, wasm.BlockTypeEmpty},
+		{ops.Call, exhausted},
+		{ops.End, nil},
+		{ops.GetGlobal, global},
+		{ops.I64Const, int64(1)},
+		{ops.I64Sub, nil},
+		{ops.SetGlobal, global},
+	}
+	instrs := make([]disasm.Instr, len(codes))
+	for i, c := range codes {
+		op, err := ops.New(c.code)
+		if err != nil {
+			return nil, err
+		}
+		instrs[i].Op = op
+		if c.immediate != nil {
+			instrs[i].Immediates = []interface{}{c.immediate}
+		}
+	}
+	return instrs, nil
+}
+
+func sleb128(v int64) []byte {
+	var b bytes.Buffer
+	leb128.WriteVarint64(&b, v)
+	return b.Bytes()
+}
+
+// hostResolver resolves the imports of the modules, only the log function of
+// the host module can be imported.
+func hostResolver(logger *zap.Logger) wasm.ResolveFunc {
+	return func(name string) (*wasm.Module, error) {
+		if name != hostModule {
+			return nil, fmt.Errorf("unknown import module %q", name)
+		}
+		log := func(proc *exec.Process, ptr, length int32) {
+			if length < 0 || int(length) > proc.MemSize() {
+				return
+			}
+			msg := make([]byte, length)
+			if _, err := proc.ReadAt(msg, int64(uint32(ptr))); err != nil {
+				return
+			}
+			logger.Info("WASM module message", zap.ByteString("message", msg))
+		}
+
+		m := wasm.NewModule()
+		m.Types = &wasm.SectionTypes{Entries: []wasm.FunctionSig{logSig}}
+		m.FunctionIndexSpace = []wasm.Function{{
+			Sig:  &m.Types.Entries[0],
+			Host: reflect.ValueOf(log),
+			Body: &wasm.FunctionBody{},
+		}}
+		m.Export = &wasm.SectionExports{Entries: map[string]wasm.ExportEntry{
+			logImport: {FieldStr: logImport, Kind: wasm.ExternalFunction, Index: 0},
+		}}
+		return m, nil
+	}
+}
+
+// call runs the function fn of the module on the payload and returns the
+// payload returned by the module, nil if the module dropped the batch.
+//
+// The module allocates the input buffer with alloc(len) and the function
+// returns the pointer of the output buffer in the high 32 bits of its result
+// and its length in the low 32 bits, or -1 if it failed.
+func (m *module) call(fn int64, payload []byte) ([]byte, error) {
+	m.reset()
+
+	res, err := m.vm.ExecCode(m.alloc, uint64(len(payload)))
+	if err != nil {
+		return nil, fmt.Errorf("wasm module failed to allocate the batch: %v", err)
+	}
+	ptr := res.(uint32)
+	if err := m.write(ptr, payload); err != nil {
+		return nil, err
+	}
+
+	res, err = m.vm.ExecCode(fn, uint64(ptr), uint64(len(payload)))
+	if err == errBudgetExhausted {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", errModuleFailed, err)
+	}
+	ret := res.(uint64)
+	if int64(ret) == -1 {
+		return nil, errModuleFailed
+	}
+	if uint32(ret) == 0 {
+		return nil, nil
+	}
+	return m.read(uint32(ret>>32), uint32(ret))
+}
+
+// reset restores the memory and the globals of the module to their state
+// after the instantiation.
+func (m *module) reset() {
+	m.vm.Restart()
+	mem := m.vm.Memory()
+	n := copy(mem, m.memory)
+	for i := n; i < len(mem); i++ {
+		mem[i] = 0
+	}
+}
+
+func (m *module) write(ptr uint32, payload []byte) error {
+	mem := m.vm.Memory()
+	if uint64(ptr)+uint64(len(payload)) > uint64(len(mem)) {
+		return errOutOfBounds
+	}
+	copy(mem[ptr:], payload)
+	return nil
+}
+
+func (m *module) read(ptr, length uint32) ([]byte, error) {
+	mem := m.vm.Memory()
+	if uint64(ptr)+uint64(length) > uint64(len(mem)) {
+		return nil, errOutOfBounds
+	}
+	// The memory is reset before the next batch.
+	return append([]byte(nil), mem[ptr:ptr+length]...), nil
+}
@@ -0,0 +1,282 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Instructions of the function bodies of the test modules.
+var (
+	// identityBody returns the input buffer.
+	identityBody = []byte{
+		0x20, 0x00, 0xad, // local.get 0, i64.extend_i32_u
+		0x42, 0x20, 0x86, // i64.const 32, i64.shl
+		0x20, 0x01, 0xad, // local.get 1, i64.extend_i32_u
+		0x84, // i64.or
+	}
+	// dropBody drops the batch.
+	dropBody = []byte{0x42, 0x00} // i64.const 0
+	// failBody fails.
+	failBody = []byte{0x42, 0x7f} // i64.const -1
+	// trapBody traps.
+	trapBody = []byte{0x00} // unreachable
+	// loopBody never returns.
+	loopBody = []byte{
+		0x03, 0x40, 0x0c, 0x00, 0x0b, // loop, br 0, end
+		0x42, 0x00, // i64.const 0
+	}
+)
+
+// dataOffset is the offset of the data segment of the test modules.
+const dataOffset = 16
+
+// dataBody returns the data segment of a module, whose length is n.
+func dataBody(n int) []byte {
+	return i64Const(int64(dataOffset)<<32 | int64(n))
+}
+
+// logBody logs the data segment of a module, whose length is n, then runs
+// body.
+func logBody(n int, body []byte) []byte {
+	b := append([]byte{0x41}, sleb(dataOffset)...)
+	b = append(b, 0x41)
+	b = append(b, sleb(int64(n))...)
+	b = append(b, 0x10, 0x00) // call 0
+	return append(b, body...)
+}
+
+// testModule describes a module exporting a bump allocator starting after its
+// data segment.
+type testModule struct {
+	traces  []byte
+	metrics []byte
+	data    []byte
+	log     bool
+}
+
+// encode assembles the module in the WebAssembly binary format.
+func (tm testModule) encode() []byte {
+	var imports, funcs, exports, code [][]byte
+	index := 0
+	if tm.log {
+		imports = append(imports, concat(name(hostModule), name(logImport), []byte{0x00, 0x02}))
+		index++
+	}
+	addFunc := func(exportName string, typeIdx byte, body []byte) {
+		funcs = append(funcs, []byte{typeIdx})
+		exports = append(exports, concat(name(exportName), []byte{0x00}, uleb(uint64(index))))
+		fn := concat([]byte{0x00}, body, []byte{0x0b})
+		code = append(code, concat(uleb(uint64(len(fn))), fn))
+		index++
+	}
+	addFunc(allocExport, 0x00, []byte{
+		0x23, 0x00, 0x23, 0x00, // global.get 0, global.get 0
+		0x20, 0x00, 0x6a, // local.get 0, i32.add
+		0x24, 0x00, // global.set 0
+	})
+	if tm.traces != nil {
+		addFunc(tracesExport, 0x01, tm.traces)
+	}
+	if tm.metrics != nil {
+		addFunc(metricsExport, 0x01, tm.metrics)
+	}
+	exports = append(exports, concat(name("memory"), []byte{0x02, 0x00}))
+
+	heap := dataOffset + len(tm.data)
+	b := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	b = append(b, section(1, vector([][]byte{
+		{0x60, 0x01, 0x7f, 0x01, 0x7f},       // (i32) -> i32
+		{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e}, // (i32, i32) -> i64
+		{0x60, 0x02, 0x7f, 0x7f, 0x00},       // (i32, i32) -> ()
+	}))...)
+	if len(imports) > 0 {
+		b = append(b, section(2, vector(imports))...)
+	}
+	b = append(b, section(3, vector(funcs))...)
+	b = append(b, section(5, vector([][]byte{{0x00, 0x10}}))...) // 16 pages
+	b = append(b, section(6, vector([][]byte{concat([]byte{0x7f, 0x01, 0x41}, sleb(int64(heap)), []byte{0x0b})}))...)
+	b = append(b, section(7, vector(exports))...)
+	b = append(b, section(10, vector(code))...)
+	if len(tm.data) > 0 {
+		b = append(b, section(11, vector([][]byte{
+			concat([]byte{0x00, 0x41}, sleb(dataOffset), []byte{0x0b}, uleb(uint64(len(tm.data))), tm.data),
+		}))...)
+	}
+	return b
+}
+
+// write writes the module in a file of dir and returns its path.
+func (tm testModule) write(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "module.wasm")
+	require.NoError(t, ioutil.WriteFile(path, tm.encode(), 0600))
+	return path
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func name(s string) []byte {
+	return concat(uleb(uint64(len(s))), []byte(s))
+}
+
+func vector(items [][]byte) []byte {
+	return concat(uleb(uint64(len(items))), concat(items...))
+}
+
+func section(id byte, content []byte) []byte {
+	return concat([]byte{id}, uleb(uint64(len(content))), content)
+}
+
+func i64Const(v int64) []byte {
+	return append([]byte{0x42}, sleb(v)...)
+}
+
+func uleb(v uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)]
+}
+
+func sleb(v int64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "wasmprocessor")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestLoadModule(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	mod, err := loadModule(zap.NewNop(), testModule{traces: identityBody}.write(t, dir), defaultExecutionBudget)
+	require.NoError(t, err)
+	assert.True(t, mod.traces >= 0)
+	assert.Equal(t, int64(-1), mod.metrics)
+
+	_, err = loadModule(zap.NewNop(), filepath.Join(dir, "missing.wasm"), defaultExecutionBudget)
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "invalid.wasm")
+	require.NoError(t, ioutil.WriteFile(path, []byte("not a module"), 0600))
+	_, err = loadModule(zap.NewNop(), path, defaultExecutionBudget)
+	assert.Error(t, err)
+
+	// The function bodies are validated.
+	_, err = loadModule(zap.NewNop(), testModule{traces: []byte{0x41, 0x00}}.write(t, dir), defaultExecutionBudget)
+	assert.Error(t, err)
+}
+
+func TestModule_Call(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	data := []byte("replacement")
+	tests := []struct {
+		name    string
+		body    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{name: "identity", body: identityBody, want: []byte("batch")},
+		{name: "data", body: dataBody(len(data)), want: data},
+		{name: "drop", body: dropBody},
+		{name: "fail", body: failBody, wantErr: true},
+		{name: "trap", body: trapBody, wantErr: true},
+		{name: "out of bounds", body: i64Const(0x7fff0000<<32 | 16), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := loadModule(zap.NewNop(), testModule{traces: tt.body, data: data}.write(t, dir), defaultExecutionBudget)
+			require.NoError(t, err)
+
+			// The module is reset between the batches, even after a failure.
+			for i := 0; i < 3; i++ {
+				got, err := mod.call(mod.traces, []byte("batch"))
+				if tt.wantErr {
+					assert.Error(t, err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestModule_Budget(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	mod, err := loadModule(zap.NewNop(), testModule{traces: loopBody}.write(t, dir), 1000)
+	require.NoError(t, err)
+	_, err = mod.call(mod.traces, []byte("batch"))
+	assert.Equal(t, errBudgetExhausted, err)
+
+	// The calls of alloc and of the function take one step each, the budget
+	// is reset before each batch.
+	mod, err = loadModule(zap.NewNop(), testModule{traces: identityBody}.write(t, dir), 2)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		got, err := mod.call(mod.traces, []byte("batch"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("batch"), got)
+	}
+
+	mod, err = loadModule(zap.NewNop(), testModule{traces: identityBody}.write(t, dir), 1)
+	require.NoError(t, err)
+	_, err = mod.call(mod.traces, []byte("batch"))
+	assert.Equal(t, errBudgetExhausted, err)
+}
+
+func TestModule_Log(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	core, logs := observer.New(zap.InfoLevel)
+	msg := []byte("hello from wasm")
+	mod, err := loadModule(zap.New(core), testModule{traces: logBody(len(msg), dropBody), data: msg, log: true}.write(t, dir), defaultExecutionBudget)
+	require.NoError(t, err)
+
+	got, err := mod.call(mod.traces, []byte("batch"))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "hello from wasm", logs.All()[0].ContextMap()["message"])
+}
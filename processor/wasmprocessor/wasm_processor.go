@@ -0,0 +1,247 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// runtime runs the module of a processor, reloading it when its file changes.
+// The batches are processed one at a time.
+type runtime struct {
+	logger *zap.Logger
+	cfg    Config
+	// function returns the index of the processing function of the data type
+	// of the processor, -1 if the module does not export it.
+	function func(*module) int64
+
+	mu      sync.Mutex
+	mod     *module
+	modTime time.Time
+	size    int64
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func startRuntime(logger *zap.Logger, cfg Config, function func(*module) int64) (*runtime, error) {
+	fi, err := os.Stat(cfg.Module)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := loadModule(logger, cfg.Module, cfg.ExecutionBudget)
+	if err != nil {
+		return nil, err
+	}
+	if function(mod) < 0 {
+		return nil, configerror.ErrDataTypeIsNotSupported
+	}
+
+	r := &runtime{
+		logger:   logger,
+		cfg:      cfg,
+		function: function,
+		mod:      mod,
+		modTime:  fi.ModTime(),
+		size:     fi.Size(),
+		done:     make(chan struct{}),
+	}
+	if cfg.ReloadInterval > 0 {
+		go r.watch()
+	}
+	return r, nil
+}
+
+func (r *runtime) watch() {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.reload()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// reload loads the module again if its file changed. The current module is
+// kept if the new one is invalid.
+func (r *runtime) reload() {
+	fi, err := os.Stat(r.cfg.Module)
+	if err != nil {
+		r.logger.Warn("Cannot check the WASM module", zap.String("module", r.cfg.Module), zap.Error(err))
+		return
+	}
+	if fi.ModTime().Equal(r.modTime) && fi.Size() == r.size {
+		return
+	}
+	// The file is only loaded again once it changes again.
+	r.modTime, r.size = fi.ModTime(), fi.Size()
+
+	mod, err := loadModule(r.logger, r.cfg.Module, r.cfg.ExecutionBudget)
+	if err == nil && r.function(mod) < 0 {
+		err = configerror.ErrDataTypeIsNotSupported
+	}
+	if err != nil {
+		r.logger.Error("Cannot reload the WASM module, keeping the current one", zap.String("module", r.cfg.Module), zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	r.mod = mod
+	r.mu.Unlock()
+	r.logger.Info("Reloaded the WASM module", zap.String("module", r.cfg.Module))
+}
+
+// process runs the module on the payload and returns the payload it returned,
+// nil if it dropped the batch. A batch exhausting the execution budget would
+// exhaust it again, so it is failed with a permanent error.
+func (r *runtime) process(payload []byte) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out, err := r.mod.call(r.function(r.mod), payload)
+	if err == errBudgetExhausted {
+		return nil, consumererror.Permanent(err)
+	}
+	return out, err
+}
+
+// stop stops the reload of the module.
+func (r *runtime) stop() {
+	r.stopOnce.Do(func() { close(r.done) })
+}
+
+// Shutdown stops the reload of the module.
+func (r *runtime) Shutdown(ctx context.Context) error {
+	r.stop()
+	return nil
+}
+
+type wasmTraceProcessor struct {
+	*runtime
+	nextConsumer consumer.TraceConsumer
+}
+
+var (
+	_ processor.TraceProcessor = (*wasmTraceProcessor)(nil)
+	_ processor.Shutdowner     = (*wasmTraceProcessor)(nil)
+)
+
+func newWasmTraceProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer, cfg Config) (*wasmTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	r, err := startRuntime(logger, cfg, func(m *module) int64 { return m.traces })
+	if err != nil {
+		return nil, err
+	}
+	return &wasmTraceProcessor{runtime: r, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeTraceData runs the module on the batch and sends the batch it
+// returns to the next consumer, unless it has no spans.
+func (wtp *wasmTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	payload, err := proto.Marshal(&agenttracepb.ExportTraceServiceRequest{
+		Node:     td.Node,
+		Resource: td.Resource,
+		Spans:    td.Spans,
+	})
+	if err != nil {
+		return err
+	}
+	out, err := wtp.process(payload)
+	if err != nil {
+		return err
+	}
+	resp := &agenttracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(out, resp); err != nil {
+		return err
+	}
+	if len(resp.Spans) == 0 {
+		return nil
+	}
+	return wtp.nextConsumer.ConsumeTraceData(ctx, consumerdata.TraceData{
+		Node:         resp.Node,
+		Resource:     resp.Resource,
+		Spans:        resp.Spans,
+		SourceFormat: td.SourceFormat,
+	})
+}
+
+type wasmMetricsProcessor struct {
+	*runtime
+	nextConsumer consumer.MetricsConsumer
+}
+
+var (
+	_ processor.MetricsProcessor = (*wasmMetricsProcessor)(nil)
+	_ processor.Shutdowner       = (*wasmMetricsProcessor)(nil)
+)
+
+func newWasmMetricsProcessor(logger *zap.Logger, nextConsumer consumer.MetricsConsumer, cfg Config) (*wasmMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	r, err := startRuntime(logger, cfg, func(m *module) int64 { return m.metrics })
+	if err != nil {
+		return nil, err
+	}
+	return &wasmMetricsProcessor{runtime: r, nextConsumer: nextConsumer}, nil
+}
+
+// ConsumeMetricsData runs the module on the batch and sends the batch it
+// returns to the next consumer, unless it has no metrics.
+func (wmp *wasmMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	payload, err := proto.Marshal(&agentmetricspb.ExportMetricsServiceRequest{
+		Node:     md.Node,
+		Resource: md.Resource,
+		Metrics:  md.Metrics,
+	})
+	if err != nil {
+		return err
+	}
+	out, err := wmp.process(payload)
+	if err != nil {
+		return err
+	}
+	resp := &agentmetricspb.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(out, resp); err != nil {
+		return err
+	}
+	if len(resp.Metrics) == 0 {
+		return nil
+	}
+	return wmp.nextConsumer.ConsumeMetricsData(ctx, consumerdata.MetricsData{
+		Node:     resp.Node,
+		Resource: resp.Resource,
+		Metrics:  resp.Metrics,
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmprocessor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+func testConfig(module string) Config {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Module = module
+	cfg.ReloadInterval = 0
+	return *cfg
+}
+
+func testTraceData(names ...string) consumerdata.TraceData {
+	td := consumerdata.TraceData{SourceFormat: "zipkin"}
+	for _, name := range names {
+		td.Spans = append(td.Spans, &tracepb.Span{Name: &tracepb.TruncatableString{Value: name}})
+	}
+	return td
+}
+
+// replaceModule returns a module replacing the spans with a span named name.
+func replaceModule(t *testing.T, name string) testModule {
+	data, err := proto.Marshal(&agenttracepb.ExportTraceServiceRequest{
+		Spans: []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: name}}},
+	})
+	require.NoError(t, err)
+	return testModule{traces: dataBody(len(data)), data: data}
+}
+
+// waitFor polls cond until it returns true or a few seconds elapsed.
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 500 && !cond(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, cond(), "condition not met in time")
+}
+
+func TestWasmTraceProcessor(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkTraceExporter)
+	wtp, err := newWasmTraceProcessor(zap.NewNop(), sink, testConfig(replaceModule(t, "wasm").write(t, dir)))
+	require.NoError(t, err)
+	defer wtp.stop()
+
+	require.NoError(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get", "post")))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "wasm", got[0].Spans[0].Name.Value)
+	assert.Equal(t, "zipkin", got[0].SourceFormat)
+}
+
+func TestWasmTraceProcessor_DropAndFail(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkTraceExporter)
+	wtp, err := newWasmTraceProcessor(zap.NewNop(), sink, testConfig(testModule{traces: dropBody}.write(t, dir)))
+	require.NoError(t, err)
+	defer wtp.stop()
+	require.NoError(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get")))
+	assert.Len(t, sink.AllTraces(), 0)
+
+	wtp, err = newWasmTraceProcessor(zap.NewNop(), sink, testConfig(testModule{traces: trapBody}.write(t, dir)))
+	require.NoError(t, err)
+	defer wtp.stop()
+	assert.Error(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get")))
+	assert.Len(t, sink.AllTraces(), 0)
+
+	_, err = newWasmTraceProcessor(zap.NewNop(), nil, testConfig(testModule{traces: dropBody}.write(t, dir)))
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+}
+
+func TestWasmTraceProcessor_Budget(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkTraceExporter)
+	cfg := testConfig(testModule{traces: loopBody}.write(t, dir))
+	cfg.ExecutionBudget = 1000
+	wtp, err := newWasmTraceProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+	defer wtp.stop()
+
+	err = wtp.ConsumeTraceData(context.Background(), testTraceData("get"))
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Len(t, sink.AllTraces(), 0)
+}
+
+func TestWasmMetricsProcessor(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkMetricsExporter)
+	wmp, err := newWasmMetricsProcessor(zap.NewNop(), sink, testConfig(testModule{metrics: identityBody}.write(t, dir)))
+	require.NoError(t, err)
+	defer wmp.stop()
+
+	md := consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{{MetricDescriptor: &metricspb.MetricDescriptor{Name: "m"}}},
+	}
+	require.NoError(t, wmp.ConsumeMetricsData(context.Background(), md))
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.True(t, proto.Equal(md.Metrics[0], sink.AllMetrics()[0].Metrics[0]))
+
+	// The module does not export the traces function.
+	_, err = newWasmTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), testConfig(testModule{metrics: identityBody}.write(t, dir)))
+	assert.Equal(t, configerror.ErrDataTypeIsNotSupported, err)
+}
+
+func TestWasmProcessor_Reload(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkTraceExporter)
+	cfg := testConfig(replaceModule(t, "v1").write(t, dir))
+	cfg.ReloadInterval = 10 * time.Millisecond
+	wtp, err := newWasmTraceProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+	defer wtp.stop()
+
+	replaceModule(t, "v2").write(t, dir)
+	// Make sure that the modification time changes.
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(cfg.Module, later, later))
+
+	waitFor(t, func() bool {
+		require.NoError(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get")))
+		got := sink.AllTraces()
+		return got[len(got)-1].Spans[0].Name.Value == "v2"
+	})
+}
+
+func TestWasmProcessor_Shutdown(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkTraceExporter)
+	cfg := testConfig(replaceModule(t, "v1").write(t, dir))
+	cfg.ReloadInterval = 10 * time.Millisecond
+	wtp, err := newWasmTraceProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+	require.NoError(t, wtp.Shutdown(context.Background()))
+	require.NoError(t, wtp.Shutdown(context.Background()))
+
+	// The module is no longer reloaded.
+	replaceModule(t, "v2").write(t, dir)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(cfg.Module, later, later))
+	time.Sleep(10 * cfg.ReloadInterval)
+	require.NoError(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get")))
+	assert.Equal(t, "v1", sink.AllTraces()[0].Spans[0].Name.Value)
+}
+
+func TestWasmProcessor_ReloadInvalid(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	sink := new(exportertest.SinkTraceExporter)
+	cfg := testConfig(replaceModule(t, "v1").write(t, dir))
+	wtp, err := newWasmTraceProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+	defer wtp.stop()
+
+	// The current module is kept when the new one is invalid or does not
+	// export the traces function.
+	for _, content := range [][]byte{[]byte("not a module"), testModule{metrics: identityBody}.encode()} {
+		require.NoError(t, ioutil.WriteFile(cfg.Module, content, 0600))
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(cfg.Module, later, later))
+		wtp.reload()
+
+		require.NoError(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get")))
+		got := sink.AllTraces()
+		assert.Equal(t, "v1", got[len(got)-1].Spans[0].Name.Value)
+	}
+
+	require.NoError(t, os.Remove(cfg.Module))
+	wtp.reload()
+	require.NoError(t, wtp.ConsumeTraceData(context.Background(), testTraceData("get")))
+
+	_, err = newWasmTraceProcessor(zap.NewNop(), sink, testConfig(filepath.Join(dir, "missing.wasm")))
+	assert.Error(t, err)
+}
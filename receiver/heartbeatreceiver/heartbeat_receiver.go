@@ -0,0 +1,191 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package heartbeatreceiver periodically emits, into a metrics pipeline, a
+// heartbeat metric identifying the collector instance and its uptime, so that
+// backends can alert on collectors that stop reporting without scraping them.
+package heartbeatreceiver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/version"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+const (
+	metricsSource = "Heartbeat"
+
+	// UptimeMetricName is the cumulative uptime of the collector in seconds,
+	// its start time is the start of the collector process.
+	UptimeMetricName = "otelsvc_uptime"
+
+	serviceName        = "otelsvc"
+	instanceIDLabelKey = "instance_id"
+	versionLabelKey    = "version"
+)
+
+// processStart approximates the start of the collector process.
+var processStart = time.Now()
+
+var uptimeDescriptor = &metricspb.MetricDescriptor{
+	Name:        UptimeMetricName,
+	Description: "Uptime of the collector instance",
+	Unit:        "s",
+	Type:        metricspb.MetricDescriptor_CUMULATIVE_DOUBLE,
+	LabelKeys: []*metricspb.LabelKey{
+		{Key: instanceIDLabelKey},
+		{Key: versionLabelKey},
+	},
+}
+
+type heartbeatReceiver struct {
+	logger       *zap.Logger
+	name         string
+	interval     time.Duration
+	node         *commonpb.Node
+	labelValues  []*metricspb.LabelValue
+	nextConsumer consumer.MetricsConsumer
+
+	mu        sync.Mutex
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+var _ receiver.MetricsReceiver = (*heartbeatReceiver)(nil)
+
+func newHeartbeatReceiver(
+	logger *zap.Logger,
+	cfg Config,
+	nextConsumer consumer.MetricsConsumer,
+) (*heartbeatReceiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
+		instanceID = hex.EncodeToString(id)
+	}
+	hostname, _ := os.Hostname()
+	return &heartbeatReceiver{
+		logger:   logger,
+		name:     cfg.Name(),
+		interval: cfg.Interval,
+		node: &commonpb.Node{
+			Identifier: &commonpb.ProcessIdentifier{
+				HostName:       hostname,
+				Pid:            uint32(os.Getpid()),
+				StartTimestamp: internal.TimeToTimestamp(processStart),
+			},
+			ServiceInfo: &commonpb.ServiceInfo{Name: serviceName},
+		},
+		labelValues: []*metricspb.LabelValue{
+			{Value: instanceID, HasValue: true},
+			{Value: version.Version, HasValue: true},
+		},
+		nextConsumer: nextConsumer,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}, nil
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (hr *heartbeatReceiver) MetricsSource() string {
+	return metricsSource
+}
+
+// StartMetricsReception emits the heartbeat right away and then periodically.
+func (hr *heartbeatReceiver) StartMetricsReception(host receiver.Host) error {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	hr.startOnce.Do(func() {
+		go hr.emitLoop(host.Context())
+		err = nil
+	})
+	return err
+}
+
+// StopMetricsReception stops emitting the heartbeat.
+func (hr *heartbeatReceiver) StopMetricsReception() error {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	hr.stopOnce.Do(func() {
+		close(hr.done)
+		<-hr.stopped
+		err = nil
+	})
+	return err
+}
+
+func (hr *heartbeatReceiver) emitLoop(ctx context.Context) {
+	defer close(hr.stopped)
+	hr.emit(ctx)
+	ticker := time.NewTicker(hr.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			hr.emit(ctx)
+		case <-hr.done:
+			return
+		}
+	}
+}
+
+func (hr *heartbeatReceiver) emit(ctx context.Context) {
+	md := consumerdata.MetricsData{
+		Node:    hr.node,
+		Metrics: []*metricspb.Metric{hr.heartbeat(time.Now())},
+	}
+	if err := hr.nextConsumer.ConsumeMetricsData(ctx, md); err != nil {
+		hr.logger.Warn("Failed to emit the heartbeat", zap.String("receiver", hr.name), zap.Error(err))
+	}
+}
+
+func (hr *heartbeatReceiver) heartbeat(now time.Time) *metricspb.Metric {
+	return &metricspb.Metric{
+		MetricDescriptor: uptimeDescriptor,
+		Timeseries: []*metricspb.TimeSeries{{
+			StartTimestamp: internal.TimeToTimestamp(processStart),
+			LabelValues:    hr.labelValues,
+			Points: []*metricspb.Point{{
+				Timestamp: internal.TimeToTimestamp(now),
+				Value:     &metricspb.Point_DoubleValue{DoubleValue: now.Sub(processStart).Seconds()},
+			}},
+		}},
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heartbeatreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/version"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestHeartbeatReceiver(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	// Long enough for the heartbeat to be emitted only on start.
+	cfg.Interval = time.Hour
+	cfg.InstanceID = "collector-1"
+	hr, err := newHeartbeatReceiver(zap.NewNop(), *cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, hr.StartMetricsReception(receivertest.NewMockHost()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, hr.StartMetricsReception(receivertest.NewMockHost()))
+	require.NoError(t, hr.StopMetricsReception())
+	assert.Equal(t, oterr.ErrAlreadyStopped, hr.StopMetricsReception())
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, serviceName, got[0].Node.ServiceInfo.Name)
+	require.Len(t, got[0].Metrics, 1)
+	metric := got[0].Metrics[0]
+	assert.Equal(t, UptimeMetricName, metric.MetricDescriptor.Name)
+	require.Len(t, metric.Timeseries, 1)
+	ts := metric.Timeseries[0]
+	assert.Equal(t, "collector-1", ts.LabelValues[0].Value)
+	assert.Equal(t, version.Version, ts.LabelValues[1].Value)
+	assert.True(t, ts.Points[0].GetDoubleValue() > 0)
+}
+
+func TestHeartbeatReceiver_Interval(t *testing.T) {
+	sink := &exportertest.SinkMetricsExporter{}
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Interval = 10 * time.Millisecond
+	hr, err := newHeartbeatReceiver(zap.NewNop(), *cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, hr.StartMetricsReception(receivertest.NewMockHost()))
+	defer hr.StopMetricsReception()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.AllMetrics()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := sink.AllMetrics()
+	require.True(t, len(got) >= 3)
+	// The random instance ID is the same in all the heartbeats.
+	first := got[0].Metrics[0].Timeseries[0].LabelValues[0].Value
+	assert.Len(t, first, 32)
+	assert.Equal(t, first, got[2].Metrics[0].Timeseries[0].LabelValues[0].Value)
+}
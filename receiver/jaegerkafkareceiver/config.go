@@ -0,0 +1,46 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerkafkareceiver
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the Jaeger Kafka receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+
+	// Brokers are the addresses of the Kafka brokers, defaults to
+	// "127.0.0.1:9092" when empty.
+	Brokers []string `mapstructure:"brokers"`
+
+	// Topic is the topic the spans are consumed from.
+	Topic string `mapstructure:"topic"`
+
+	// GroupID is the consumer group of the receiver, keeping the group of
+	// jaeger-ingester resumes from the offsets it committed.
+	GroupID string `mapstructure:"group-id"`
+
+	// ClientID identifies the receiver to the brokers.
+	ClientID string `mapstructure:"client-id"`
+
+	// Encoding of the spans in the topic, "protobuf" or "json", as configured
+	// on jaeger-collector with --kafka.producer.encoding.
+	Encoding string `mapstructure:"encoding"`
+
+	// ProtocolVersion is the Kafka protocol version used with the brokers,
+	// consumer groups require at least 0.10.2.0.
+	ProtocolVersion string `mapstructure:"protocol-version"`
+}
@@ -0,0 +1,129 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerkafkareceiver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+// This file implements factory for the Jaeger Kafka receiver.
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "jaeger-kafka"
+
+	// The defaults of jaeger-collector and jaeger-ingester.
+	defaultBroker   = "127.0.0.1:9092"
+	defaultTopic    = "jaeger-spans"
+	defaultGroupID  = "jaeger-ingester"
+	defaultClientID = "jaeger-ingester"
+
+	encodingProtobuf = "protobuf"
+	encodingJSON     = "json"
+
+	defaultProtocolVersion = "0.10.2.0"
+)
+
+// Factory is the factory for the Jaeger Kafka receiver.
+type Factory struct {
+}
+
+// Type gets the type of the Receiver config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CustomUnmarshaler returns nil because we don't need custom unmarshaling for this config.
+func (f *Factory) CustomUnmarshaler() receiver.CustomUnmarshaler {
+	return nil
+}
+
+// CreateDefaultConfig creates the default configuration for the Jaeger Kafka receiver.
+func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
+	return &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		Topic:           defaultTopic,
+		GroupID:         defaultGroupID,
+		ClientID:        defaultClientID,
+		Encoding:        encodingProtobuf,
+		ProtocolVersion: defaultProtocolVersion,
+	}
+}
+
+// CreateTraceReceiver creates a trace receiver based on provided config.
+func (f *Factory) CreateTraceReceiver(
+	ctx context.Context,
+	logger *zap.Logger,
+	cfg configmodels.Receiver,
+	nextConsumer consumer.TraceConsumer,
+) (receiver.TraceReceiver, error) {
+	rCfg := cfg.(*Config)
+	if rCfg.Topic == "" {
+		return nil, fmt.Errorf("%q config requires a non-empty \"topic\"", rCfg.Name())
+	}
+	if rCfg.GroupID == "" {
+		return nil, fmt.Errorf("%q config requires a non-empty \"group-id\"", rCfg.Name())
+	}
+	unmarshal, err := unmarshalerForEncoding(rCfg.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encoding of %s receiver: %v", rCfg.Name(), err)
+	}
+	version, err := sarama.ParseKafkaVersion(rCfg.ProtocolVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protocol-version of %s receiver: %v", rCfg.Name(), err)
+	}
+	if !version.IsAtLeast(sarama.V0_10_2_0) {
+		return nil, fmt.Errorf("invalid protocol-version of %s receiver: consumer groups require at least %s", rCfg.Name(), sarama.V0_10_2_0)
+	}
+
+	brokers := rCfg.Brokers
+	if len(brokers) == 0 {
+		brokers = []string{defaultBroker}
+	}
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.ClientID = rCfg.ClientID
+	saramaConfig.Version = version
+	saramaConfig.Consumer.Return.Errors = true
+
+	return newKafkaReceiver(logger, rCfg.Name(), settings{
+		brokers:   brokers,
+		topic:     rCfg.Topic,
+		groupID:   rCfg.GroupID,
+		config:    saramaConfig,
+		unmarshal: unmarshal,
+	}, nextConsumer)
+}
+
+// CreateMetricsReceiver creates a metrics receiver based on provided config.
+func (f *Factory) CreateMetricsReceiver(
+	logger *zap.Logger,
+	cfg configmodels.Receiver,
+	consumer consumer.MetricsConsumer,
+) (receiver.MetricsReceiver, error) {
+	// Jaeger Kafka receiver only receives spans.
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
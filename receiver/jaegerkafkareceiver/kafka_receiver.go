@@ -0,0 +1,236 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jaegerkafkareceiver consumes the spans jaeger-collector produces to
+// Kafka, so that the collector can take the place of jaeger-ingester.
+package jaegerkafkareceiver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/jaegertracing/jaeger/model"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
+)
+
+const (
+	traceSource      = "JaegerKafka"
+	receiverTagValue = "jaeger-kafka"
+	sourceFormat     = "jaeger"
+)
+
+// unmarshaler decodes the value of a Kafka message into a Jaeger span.
+type unmarshaler func([]byte) (*model.Span, error)
+
+func unmarshalerForEncoding(encoding string) (unmarshaler, error) {
+	switch encoding {
+	case encodingProtobuf:
+		return unmarshalProtobuf, nil
+	case encodingJSON:
+		return unmarshalJSON, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q, must be %q or %q", encoding, encodingProtobuf, encodingJSON)
+	}
+}
+
+func unmarshalProtobuf(b []byte) (*model.Span, error) {
+	span := &model.Span{}
+	if err := span.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return span, nil
+}
+
+func unmarshalJSON(b []byte) (*model.Span, error) {
+	span := &model.Span{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), span); err != nil {
+		return nil, err
+	}
+	return span, nil
+}
+
+// settings are the validated settings of the receiver.
+type settings struct {
+	brokers   []string
+	topic     string
+	groupID   string
+	config    *sarama.Config
+	unmarshal unmarshaler
+}
+
+type kafkaReceiver struct {
+	logger       *zap.Logger
+	name         string
+	settings     settings
+	nextConsumer consumer.TraceConsumer
+
+	// newConsumerGroup is replaced by the tests to avoid requiring brokers.
+	newConsumerGroup func(addrs []string, groupID string, config *sarama.Config) (sarama.ConsumerGroup, error)
+
+	mu        sync.Mutex
+	startOnce sync.Once
+	stopOnce  sync.Once
+	group     sarama.ConsumerGroup
+	cancel    context.CancelFunc
+	stopped   chan struct{}
+}
+
+var _ receiver.TraceReceiver = (*kafkaReceiver)(nil)
+
+func newKafkaReceiver(
+	logger *zap.Logger,
+	name string,
+	settings settings,
+	nextConsumer consumer.TraceConsumer,
+) (*kafkaReceiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &kafkaReceiver{
+		logger:           logger,
+		name:             name,
+		settings:         settings,
+		nextConsumer:     nextConsumer,
+		newConsumerGroup: sarama.NewConsumerGroup,
+		stopped:          make(chan struct{}),
+	}, nil
+}
+
+// TraceSource returns the name of the trace data source.
+func (kr *kafkaReceiver) TraceSource() string {
+	return traceSource
+}
+
+// StartTraceReception joins the consumer group and consumes the spans of the
+// topic until the receiver is stopped.
+func (kr *kafkaReceiver) StartTraceReception(host receiver.Host) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	kr.startOnce.Do(func() {
+		var group sarama.ConsumerGroup
+		group, err = kr.newConsumerGroup(kr.settings.brokers, kr.settings.groupID, kr.settings.config)
+		if err != nil {
+			err = fmt.Errorf("failed to join the consumer group %q: %v", kr.settings.groupID, err)
+			return
+		}
+		kr.group = group
+		ctx, cancel := context.WithCancel(host.Context())
+		kr.cancel = cancel
+		go kr.logErrors()
+		go kr.consumeLoop(ctx)
+	})
+	return err
+}
+
+// StopTraceReception leaves the consumer group, committing the offsets of
+// the consumed spans.
+func (kr *kafkaReceiver) StopTraceReception() error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	kr.stopOnce.Do(func() {
+		if kr.group == nil {
+			err = nil
+			return
+		}
+		kr.cancel()
+		<-kr.stopped
+		err = kr.group.Close()
+	})
+	return err
+}
+
+func (kr *kafkaReceiver) consumeLoop(ctx context.Context) {
+	defer close(kr.stopped)
+	handler := &groupHandler{receiver: kr}
+	topics := []string{kr.settings.topic}
+	for {
+		// Consume returns at every rebalance of the group, it is called
+		// again to rejoin the group with the new assignment.
+		if err := kr.group.Consume(ctx, topics, handler); err != nil {
+			kr.logger.Warn("Failed to consume the topic", zap.String("receiver", kr.name), zap.String("topic", kr.settings.topic), zap.Error(err))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (kr *kafkaReceiver) logErrors() {
+	for err := range kr.group.Errors() {
+		kr.logger.Warn("Kafka consumer error", zap.String("receiver", kr.name), zap.Error(err))
+	}
+}
+
+// groupHandler consumes the messages of the partitions claimed by the
+// receiver, one span per message.
+type groupHandler struct {
+	receiver *kafkaReceiver
+}
+
+var _ sarama.ConsumerGroupHandler = (*groupHandler)(nil)
+
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := observability.ContextWithReceiverName(session.Context(), receiverTagValue)
+	for msg := range claim.Messages() {
+		h.receiver.consumeMessage(ctx, msg)
+		// Like jaeger-ingester, the messages failing to be decoded or
+		// consumed are marked too rather than blocking the partition.
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+func (kr *kafkaReceiver) consumeMessage(ctx context.Context, msg *sarama.ConsumerMessage) {
+	span, err := kr.settings.unmarshal(msg.Value)
+	if err != nil {
+		kr.logger.Warn("Failed to decode the span of a message", zap.String("receiver", kr.name),
+			zap.Int32("partition", msg.Partition), zap.Int64("offset", msg.Offset), zap.Error(err))
+		observability.RecordMetricsForTraceReceiver(ctx, 0, 1)
+		return
+	}
+	td, err := jaegertranslator.ProtoBatchToOCProto(model.Batch{Spans: []*model.Span{span}, Process: span.Process})
+	if err != nil {
+		kr.logger.Warn("Failed to translate the span of a message", zap.String("receiver", kr.name), zap.Error(err))
+		observability.RecordMetricsForTraceReceiver(ctx, 0, 1)
+		return
+	}
+	td.SourceFormat = sourceFormat
+	if err := kr.nextConsumer.ConsumeTraceData(ctx, td); err != nil {
+		observability.RecordMetricsForTraceReceiver(ctx, 0, len(td.Spans))
+		return
+	}
+	observability.RecordMetricsForTraceReceiver(ctx, len(td.Spans), 0)
+}
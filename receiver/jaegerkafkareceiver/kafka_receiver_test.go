@@ -0,0 +1,220 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerkafkareceiver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestKafkaReceiver(t *testing.T) {
+	protobufSpan, err := testSpan("op-protobuf").Marshal()
+	require.NoError(t, err)
+	group := newFakeConsumerGroup([]*sarama.ConsumerMessage{
+		{Topic: defaultTopic, Offset: 0, Value: protobufSpan},
+		{Topic: defaultTopic, Offset: 1, Value: []byte("not a span")},
+		{Topic: defaultTopic, Offset: 2, Value: protobufSpan},
+	})
+	sink := &exportertest.SinkTraceExporter{}
+	kr := newTestReceiver(t, encodingProtobuf, group, sink)
+
+	require.NoError(t, kr.StartTraceReception(receivertest.NewMockHost()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, kr.StartTraceReception(receivertest.NewMockHost()))
+	<-group.session.done
+	require.NoError(t, kr.StopTraceReception())
+	assert.Equal(t, oterr.ErrAlreadyStopped, kr.StopTraceReception())
+
+	got := sink.AllTraces()
+	require.Len(t, got, 2)
+	for _, td := range got {
+		assert.Equal(t, "jaeger", td.SourceFormat)
+		assert.Equal(t, "svc", td.Node.ServiceInfo.Name)
+		require.Len(t, td.Spans, 1)
+		assert.Equal(t, "op-protobuf", td.Spans[0].Name.Value)
+	}
+	// The message that failed to be decoded is marked too.
+	assert.Equal(t, []int64{0, 1, 2}, group.session.markedOffsets())
+	assert.Equal(t, []string{defaultTopic}, group.topics)
+	assert.True(t, group.closed)
+}
+
+func TestKafkaReceiver_JSON(t *testing.T) {
+	jsonSpan, err := (&jsonpb.Marshaler{}).MarshalToString(testSpan("op-json"))
+	require.NoError(t, err)
+	group := newFakeConsumerGroup([]*sarama.ConsumerMessage{
+		{Topic: defaultTopic, Offset: 0, Value: []byte(jsonSpan)},
+	})
+	sink := &exportertest.SinkTraceExporter{}
+	kr := newTestReceiver(t, encodingJSON, group, sink)
+
+	require.NoError(t, kr.StartTraceReception(receivertest.NewMockHost()))
+	<-group.session.done
+	require.NoError(t, kr.StopTraceReception())
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "op-json", got[0].Spans[0].Name.Value)
+	assert.Equal(t, []int64{0}, group.session.markedOffsets())
+}
+
+func TestKafkaReceiver_JoinFailure(t *testing.T) {
+	kr := newTestReceiver(t, encodingProtobuf, nil, exportertest.NewNopTraceExporter())
+	kr.newConsumerGroup = func([]string, string, *sarama.Config) (sarama.ConsumerGroup, error) {
+		return nil, errors.New("no brokers")
+	}
+	assert.Error(t, kr.StartTraceReception(receivertest.NewMockHost()))
+	assert.NoError(t, kr.StopTraceReception())
+}
+
+func newTestReceiver(t *testing.T, encoding string, group *fakeConsumerGroup, nextConsumer consumer.TraceConsumer) *kafkaReceiver {
+	unmarshal, err := unmarshalerForEncoding(encoding)
+	require.NoError(t, err)
+	kr, err := newKafkaReceiver(zap.NewNop(), typeStr, settings{
+		brokers:   []string{defaultBroker},
+		topic:     defaultTopic,
+		groupID:   defaultGroupID,
+		config:    sarama.NewConfig(),
+		unmarshal: unmarshal,
+	}, nextConsumer)
+	require.NoError(t, err)
+	kr.newConsumerGroup = func(addrs []string, groupID string, _ *sarama.Config) (sarama.ConsumerGroup, error) {
+		assert.Equal(t, []string{defaultBroker}, addrs)
+		assert.Equal(t, defaultGroupID, groupID)
+		return group, nil
+	}
+	return kr
+}
+
+func testSpan(operation string) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(1, 2),
+		SpanID:        model.NewSpanID(3),
+		OperationName: operation,
+		StartTime:     time.Unix(1500000000, 0).UTC(),
+		Duration:      time.Second,
+		Process:       &model.Process{ServiceName: "svc"},
+	}
+}
+
+// fakeConsumerGroup hands the messages to the handler in a single claim on
+// the first call to Consume, the following calls wait for the cancellation.
+type fakeConsumerGroup struct {
+	messages []*sarama.ConsumerMessage
+	session  *fakeSession
+	errors   chan error
+	topics   []string
+	closed   bool
+	once     sync.Once
+}
+
+func newFakeConsumerGroup(messages []*sarama.ConsumerMessage) *fakeConsumerGroup {
+	return &fakeConsumerGroup{
+		messages: messages,
+		session:  &fakeSession{done: make(chan struct{})},
+		errors:   make(chan error),
+	}
+}
+
+func (g *fakeConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	g.topics = topics
+	var err error
+	g.once.Do(func() {
+		defer close(g.session.done)
+		g.session.ctx = ctx
+		messages := make(chan *sarama.ConsumerMessage, len(g.messages))
+		for _, msg := range g.messages {
+			messages <- msg
+		}
+		close(messages)
+		err = handler.ConsumeClaim(g.session, &fakeClaim{messages: messages})
+	})
+	if err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (g *fakeConsumerGroup) Errors() <-chan error {
+	return g.errors
+}
+
+func (g *fakeConsumerGroup) Close() error {
+	g.closed = true
+	close(g.errors)
+	return nil
+}
+
+type fakeSession struct {
+	ctx  context.Context
+	done chan struct{}
+
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Claims() map[string][]int32 { return nil }
+
+func (s *fakeSession) MemberID() string { return "" }
+
+func (s *fakeSession) GenerationID() int32 { return 0 }
+
+func (s *fakeSession) MarkOffset(string, int32, int64, string) {}
+
+func (s *fakeSession) ResetOffset(string, int32, int64, string) {}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) markedOffsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.marked
+}
+
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string { return defaultTopic }
+
+func (c *fakeClaim) Partition() int32 { return 0 }
+
+func (c *fakeClaim) InitialOffset() int64 { return 0 }
+
+func (c *fakeClaim) HighWaterMarkOffset() int64 { return 0 }
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
@@ -0,0 +1,123 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestReception_Auth(t *testing.T) {
+	auth.Register("test-auth", auth.ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, auth.ErrInvalidToken
+		}
+		return ctx, nil
+	}))
+	defer auth.Unregister("test-auth")
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Protocols = map[string]*ProtocolSettings{
+		protoGRPC:       {Endpoint: testutils.GetAvailableLocalAddress(t)},
+		protoThriftHTTP: {Endpoint: testutils.GetAvailableLocalAddress(t)},
+	}
+	cfg.Auth = "test-auth"
+	sink := new(exportertest.SinkTraceExporter)
+	tr, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, tr.StartTraceReception(receivertest.NewMockHost()))
+	defer tr.StopTraceReception()
+
+	// HTTP
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	post := func(authorization string) int {
+		req, err := http.NewRequest(http.MethodPost, "http://"+cfg.Protocols[protoThriftHTTP].Endpoint+"/api/traces", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-thrift")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusUnauthorized, post(""))
+	assert.Equal(t, http.StatusUnauthorized, post("Bearer wrong"))
+	assert.Empty(t, sink.AllTraces())
+	assert.Equal(t, http.StatusAccepted, post("Bearer secret"))
+	assert.Len(t, sink.AllTraces(), 1)
+
+	// gRPC
+	conn, err := grpc.Dial(cfg.Protocols[protoGRPC].Endpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := api_v2.NewCollectorServiceClient(conn)
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+
+	_, err = client.PostSpans(context.Background(), req, grpc.WaitForReady(true))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	_, err = client.PostSpans(ctx, req, grpc.WaitForReady(true))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Len(t, sink.AllTraces(), 1)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.PostSpans(ctx, req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	assert.Len(t, sink.AllTraces(), 2)
+
+	// The SDKs fetch their sampling strategy without token.
+	_, err = api_v2.NewSamplingManagerClient(conn).GetSamplingStrategy(context.Background(), &api_v2.SamplingStrategyParameters{ServiceName: "frontend"})
+	assert.NoError(t, err)
+}
+
+func TestCreateWithAuth_TChannel(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Auth = "test-auth"
+	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, "thrift-tchannel protocol of jaeger receiver doesn't support auth, it must be disabled")
+
+	cfg.Protocols[protoThriftTChannel].Disabled = true
+	tr, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, tr.(*jReceiver).authenticator)
+}
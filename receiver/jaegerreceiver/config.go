@@ -15,14 +15,116 @@
 package jaegerreceiver
 
 import (
-	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 // Config defines configuration for Jaeger receiver.
 type Config struct {
-	TypeVal   string                                    `mapstructure:"-"`
-	NameVal   string                                    `mapstructure:"-"`
-	Protocols map[string]*configmodels.ReceiverSettings `mapstructure:"protocols"`
+	TypeVal string `mapstructure:"-"`
+	NameVal string `mapstructure:"-"`
+
+	// Protocols are the listeners of the receiver by protocol, only the
+	// configured protocols are started.
+	Protocols map[string]*ProtocolSettings `mapstructure:"protocols"`
+
+	// Relay enables relaying the gRPC requests to the exporters without
+	// decoding them, when all the attached pipelines support it.
+	Relay bool `mapstructure:"relay"`
+
+	// ProcessTags controls how the process tags are merged into the span
+	// attributes, by default they are kept apart.
+	ProcessTags jaegertranslator.ProcessTagsMapping `mapstructure:"process-tags"`
+
+	// PeerAddress adds the address of the clients to the node of the received
+	// spans, the spans received by the agent listeners are not annotated.
+	PeerAddress peeraddr.Settings `mapstructure:"peer-address"`
+
+	// SamplingStrategies is the name of the adaptive-sampling extension whose
+	// sampling probabilities are served to the SDKs by the agent-http
+	// listener and the SamplingManager service of the grpc listener. If empty
+	// the SDKs get the default strategy.
+	SamplingStrategies string `mapstructure:"sampling-strategies"`
+
+	// SamplingStrategiesFile is the path of a JSON file, in the format of the
+	// static strategies of the Jaeger collector, whose per-service strategies
+	// are served to the SDKs by the agent-http listener and the
+	// SamplingManager service of the grpc listener. It can't be set with
+	// SamplingStrategies.
+	SamplingStrategiesFile string `mapstructure:"sampling-strategies-file"`
+
+	// DefaultSamplingRate is the probability of the default strategy served
+	// to the SDKs when no other strategy applies, between 0 and 1. If zero
+	// the default strategy is empty and the SDKs keep their own sampler.
+	DefaultSamplingRate float64 `mapstructure:"default-sampling-rate"`
+
+	// UpstreamSampling proxies the strategies served to the SDKs by the
+	// agent-http listener and the SamplingManager service of the grpc
+	// listener from the sampling endpoint of an upstream Jaeger agent or
+	// collector, caching them per service. The default strategy is served
+	// while the upstream has none. It can't be set with SamplingStrategies or
+	// SamplingStrategiesFile.
+	UpstreamSampling UpstreamSamplingSettings `mapstructure:"upstream-sampling"`
+
+	// AgentHTTPSpansPath is the path of the agent-http listener accepting the
+	// Thrift binary batches POSTed by the SDKs, in the format of the
+	// collector thrift-http listener. The batches are not accepted if it is
+	// empty.
+	AgentHTTPSpansPath string `mapstructure:"agent-http-spans-path"`
+
+	// AgentUDP tunes the thrift-compact and thrift-binary listeners of the
+	// agent.
+	AgentUDP UDPSettings `mapstructure:"agent-udp"`
+
+	// GRPCServer tunes the gRPC server of the grpc listener.
+	GRPCServer GRPCServerSettings `mapstructure:"grpc-server"`
+
+	// MaxInFlight limits the requests processed concurrently by the collector
+	// listeners and the agent-http-spans-path, the requests exceeding it are
+	// throttled. No limit other than
+	// the global one is applied if it is not positive.
+	MaxInFlight int `mapstructure:"max-in-flight"`
+
+	// AllowedCIDRs are the networks, in CIDR notation, of the clients allowed
+	// to connect to the collector listeners, the connections of the other
+	// clients are closed. Their requests to the agent-http-spans-path are
+	// rejected with the 403 status. All the clients are allowed if it is
+	// empty.
+	AllowedCIDRs []string `mapstructure:"allowed-cidrs"`
+
+	// Auth is the name of the extension validating the bearer tokens of the
+	// requests of the thrift-http and grpc collector listeners and of the
+	// agent-http-spans-path, none are
+	// authenticated if it is empty. The thrift-tchannel listener can't be
+	// authenticated and must be disabled.
+	Auth string `mapstructure:"auth"`
+
+	// Decompression limits the decompressed size of the compressed bodies of
+	// the thrift-http listener and of the agent-http-spans-path, the requests
+	// exceeding the limits are rejected with the 413 status.
+	Decompression decompression.Settings `mapstructure:"decompression"`
+}
+
+// ProtocolSettings configures the listener of a protocol of the Jaeger
+// receiver.
+type ProtocolSettings struct {
+	// Endpoint is the address, in the host:port form, the listener binds to.
+	// The default endpoint of the protocol is used if it is not configured.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Disabled keeps the listener from being started.
+	Disabled bool `mapstructure:"disabled"`
+
+	// TLSCredentials serves the listener over TLS, only the grpc and
+	// thrift-http protocols support it.
+	TLSCredentials *tlsutil.Credentials `mapstructure:"tls-credentials"`
+}
+
+// IsEnabled returns true if the listener of the protocol is started.
+func (ps *ProtocolSettings) IsEnabled() bool {
+	return !ps.Disabled
 }
 
 // Name gets the receiver name.
@@ -17,12 +17,16 @@ package jaegerreceiver
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-service/config"
-	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -38,7 +42,7 @@ func TestLoadConfig(t *testing.T) {
 
 	// The receiver `jaeger/disabled` doesn't count because disabled receivers
 	// are excluded from the final list.
-	assert.Equal(t, len(cfg.Receivers), 2)
+	assert.Equal(t, len(cfg.Receivers), 6)
 
 	r0 := cfg.Receivers["jaeger"]
 	assert.Equal(t, r0, factory.CreateDefaultConfig())
@@ -48,9 +52,14 @@ func TestLoadConfig(t *testing.T) {
 		&Config{
 			TypeVal: typeStr,
 			NameVal: "jaeger/customname",
-			Protocols: map[string]*configmodels.ReceiverSettings{
+			Protocols: map[string]*ProtocolSettings{
 				"grpc": {
 					Endpoint: "127.0.0.1:9876",
+					TLSCredentials: &tlsutil.Credentials{
+						CertFile:     "testdata/server.crt",
+						KeyFile:      "testdata/server.key",
+						ClientCAFile: "testdata/server.crt",
+					},
 				},
 				"thrift-http": {
 					Endpoint: ":3456",
@@ -58,6 +67,93 @@ func TestLoadConfig(t *testing.T) {
 				"thrift-tchannel": {
 					Endpoint: "0.0.0.0:123",
 				},
+				"thrift-compact": {
+					Endpoint: "127.0.0.1:6831",
+				},
+				"thrift-binary": {
+					Endpoint: "127.0.0.1:6832",
+				},
+				"agent-http": {
+					Endpoint: "127.0.0.1:5778",
+				},
+			},
+			Relay: true,
+			ProcessTags: jaegertranslator.ProcessTagsMapping{
+				CopyToSpans: true,
+				Prefix:      "process.",
+				Precedence:  jaegertranslator.PrecedenceSpan,
+			},
+			PeerAddress: peeraddr.Settings{
+				Enabled: true,
+			},
+			SamplingStrategies:  "adaptive-sampling",
+			DefaultSamplingRate: 0.1,
+			AgentUDP: UDPSettings{
+				QueueSize:        5000,
+				MaxPacketSize:    defaultUDPMaxPacketSize,
+				Workers:          20,
+				SocketBufferSize: 4194304,
+			},
+			GRPCServer: GRPCServerSettings{
+				MaxRecvMsgSizeMiB:    16,
+				MaxConcurrentStreams: 100,
+				Keepalive: KeepaliveSettings{
+					Time:    time.Minute,
+					Timeout: 10 * time.Second,
+				},
+			},
+			MaxInFlight:  100,
+			AllowedCIDRs: []string{"10.0.0.0/8", "192.168.0.0/16"},
+			Decompression: decompression.Settings{
+				MaxDecompressedSize: 10485760,
+				MaxRatio:            50,
 			},
 		})
+
+	r2 := cfg.Receivers["jaeger/ipv6"].(*Config)
+	assert.Equal(t, map[string]*ProtocolSettings{
+		"grpc": {
+			Endpoint: "[::]:14250",
+		},
+		"thrift-http": {
+			Endpoint: "[::1]:14268",
+		},
+		"thrift-tchannel": {
+			Endpoint: "[::1]:14267",
+		},
+	}, r2.Protocols)
+
+	r3 := cfg.Receivers["jaeger/agent"].(*Config)
+	assert.Equal(t, map[string]*ProtocolSettings{
+		"thrift-compact": {
+			Endpoint: defaultCompactBindEndpoint,
+		},
+		"agent-http": {
+			Endpoint: defaultAgentHTTPBindEndpoint,
+		},
+		"grpc": {
+			Endpoint: "127.0.0.1:9876",
+			Disabled: true,
+		},
+	}, r3.Protocols)
+	assert.Equal(t, "testdata/strategies.json", r3.SamplingStrategiesFile)
+	assert.Equal(t, "/api/traces", r3.AgentHTTPSpansPath)
+
+	r4 := cfg.Receivers["jaeger/auth"].(*Config)
+	assert.Equal(t, map[string]*ProtocolSettings{
+		"grpc": {
+			Endpoint: defaultGRPCBindEndpoint,
+		},
+		"thrift-http": {
+			Endpoint: defaultHTTPBindEndpoint,
+		},
+	}, r4.Protocols)
+	assert.Equal(t, "bearer-token-auth", r4.Auth)
+
+	r5 := cfg.Receivers["jaeger/upstream-sampling"].(*Config)
+	assert.Equal(t, UpstreamSamplingSettings{
+		Endpoint:        "http://jaeger-agent:5778/sampling",
+		RefreshInterval: time.Minute,
+		MaxStaleness:    time.Hour,
+	}, r5.UpstreamSampling)
 }
@@ -20,13 +20,19 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/netacl"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 )
 
@@ -41,10 +47,23 @@ const (
 	//	Remove ThriftTChannel support.
 	protoThriftTChannel = "thrift-tchannel"
 
+	// Protocol values of the Jaeger agent listeners.
+	protoThriftCompact = "thrift-compact"
+	protoThriftBinary  = "thrift-binary"
+	protoAgentHTTP     = "agent-http"
+
 	// Default endpoints to bind to.
 	defaultGRPCBindEndpoint     = "127.0.0.1:14250"
 	defaultHTTPBindEndpoint     = "127.0.0.1:14268"
 	defaultTChannelBindEndpoint = "127.0.0.1:14267"
+
+	// Default endpoints of the Jaeger agent listeners, on all network
+	// interfaces like the Jaeger agent.
+	defaultCompactBindEndpoint   = ":6831"
+	defaultBinaryBindEndpoint    = ":6832"
+	defaultAgentHTTPBindEndpoint = ":5778"
+
+	protocolsKeyName = "protocols"
 )
 
 // Factory is the factory for Jaeger receiver.
@@ -56,8 +75,35 @@ func (f *Factory) Type() string {
 	return typeStr
 }
 
-// CustomUnmarshaler returns nil because we don't need custom unmarshaling for this config.
+// CustomUnmarshaler returns the unmarshaler replacing the default protocols
+// by the configured ones.
 func (f *Factory) CustomUnmarshaler() receiver.CustomUnmarshaler {
+	return customUnmarshaler
+}
+
+// customUnmarshaler unmarshals the config, only keeping the configured
+// protocols if any. The protocols configured without an endpoint get their
+// default endpoint.
+func customUnmarshaler(v *viper.Viper, viperKey string, intoCfg interface{}) error {
+	cfg := intoCfg.(*Config)
+	defaults := cfg.Protocols
+	if v.IsSet(viperKey + "." + protocolsKeyName) {
+		// Otherwise the configured protocols would be merged into the
+		// default ones.
+		cfg.Protocols = nil
+	}
+	if err := v.UnmarshalKey(viperKey, intoCfg); err != nil {
+		return err
+	}
+	for name, ps := range cfg.Protocols {
+		if ps == nil {
+			ps = &ProtocolSettings{}
+			cfg.Protocols[name] = ps
+		}
+		if def, ok := defaults[name]; ok && ps.Endpoint == "" {
+			ps.Endpoint = def.Endpoint
+		}
+	}
 	return nil
 }
 
@@ -66,7 +112,7 @@ func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
 	return &Config{
 		TypeVal: typeStr,
 		NameVal: typeStr,
-		Protocols: map[string]*configmodels.ReceiverSettings{
+		Protocols: map[string]*ProtocolSettings{
 			protoGRPC: {
 				Endpoint: defaultGRPCBindEndpoint,
 			},
@@ -76,7 +122,22 @@ func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
 			protoThriftHTTP: {
 				Endpoint: defaultHTTPBindEndpoint,
 			},
+			protoThriftCompact: {
+				Endpoint: defaultCompactBindEndpoint,
+			},
+			protoThriftBinary: {
+				Endpoint: defaultBinaryBindEndpoint,
+			},
+			protoAgentHTTP: {
+				Endpoint: defaultAgentHTTPBindEndpoint,
+			},
+		},
+		AgentUDP: UDPSettings{
+			QueueSize:     defaultUDPQueueSize,
+			MaxPacketSize: defaultUDPMaxPacketSize,
+			Workers:       defaultUDPWorkers,
 		},
+		Decompression: decompression.DefaultSettings(),
 	}
 }
 
@@ -93,50 +154,88 @@ func (f *Factory) CreateTraceReceiver(
 
 	rCfg := cfg.(*Config)
 
-	protoGRPC := rCfg.Protocols[protoGRPC]
-	protoHTTP := rCfg.Protocols[protoThriftHTTP]
-	protoTChannel := rCfg.Protocols[protoThriftTChannel]
-
 	config := Configuration{}
+	if err := setProtocols(&config, rCfg); err != nil {
+		return nil, err
+	}
 
-	// Set ports
-	if protoGRPC != nil && protoGRPC.IsEnabled() {
-		var err error
-		config.CollectorGRPCPort, err = extractPortFromEndpoint(protoGRPC.Endpoint)
-		if err != nil {
-			return nil, err
-		}
+	if err := rCfg.ProcessTags.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid process-tags of %s receiver: %v", rCfg.Name(), err)
+	}
+	config.ProcessTags = rCfg.ProcessTags
+	config.PeerAddress = rCfg.PeerAddress
+	if rCfg.SamplingStrategies != "" && rCfg.SamplingStrategiesFile != "" {
+		return nil, fmt.Errorf("sampling-strategies and sampling-strategies-file of %s receiver are mutually exclusive", rCfg.Name())
+	}
+	config.SamplingStrategies = rCfg.SamplingStrategies
+	config.SamplingStrategiesFile = rCfg.SamplingStrategiesFile
+	if rCfg.DefaultSamplingRate < 0 || rCfg.DefaultSamplingRate > 1 {
+		return nil, fmt.Errorf("default-sampling-rate of %s receiver must be between 0 and 1, got %v", rCfg.Name(), rCfg.DefaultSamplingRate)
+	}
+	config.DefaultSamplingRate = rCfg.DefaultSamplingRate
+	if err := rCfg.UpstreamSampling.validate(); err != nil {
+		return nil, fmt.Errorf("invalid upstream-sampling of %s receiver: %v", rCfg.Name(), err)
+	}
+	if rCfg.UpstreamSampling.Endpoint != "" && (rCfg.SamplingStrategies != "" || rCfg.SamplingStrategiesFile != "") {
+		return nil, fmt.Errorf("upstream-sampling of %s receiver can't be set with sampling-strategies or sampling-strategies-file", rCfg.Name())
 	}
+	config.UpstreamSampling = rCfg.UpstreamSampling
 
-	if protoHTTP != nil && protoHTTP.IsEnabled() {
-		var err error
-		config.CollectorHTTPPort, err = extractPortFromEndpoint(protoHTTP.Endpoint)
-		if err != nil {
-			return nil, err
-		}
+	if err := validateAgentHTTPSpansPath(rCfg.AgentHTTPSpansPath); err != nil {
+		return nil, fmt.Errorf("invalid agent-http-spans-path of %s receiver: %v", rCfg.Name(), err)
 	}
+	if rCfg.AgentHTTPSpansPath != "" && config.AgentEndpoint == "" {
+		return nil, fmt.Errorf("agent-http-spans-path of %s receiver requires the %s protocol", rCfg.Name(), protoAgentHTTP)
+	}
+	config.AgentHTTPSpansPath = rCfg.AgentHTTPSpansPath
 
-	if protoTChannel != nil && protoTChannel.IsEnabled() {
-		var err error
-		config.CollectorThriftPort, err = extractPortFromEndpoint(protoTChannel.Endpoint)
-		if err != nil {
-			return nil, err
+	if rCfg.AgentUDP.QueueSize < 0 || rCfg.AgentUDP.MaxPacketSize < 0 ||
+		rCfg.AgentUDP.Workers < 0 || rCfg.AgentUDP.SocketBufferSize < 0 {
+		return nil, fmt.Errorf("invalid agent-udp of %s receiver: the settings must not be negative", rCfg.Name())
+	}
+	config.AgentUDP = rCfg.AgentUDP
+
+	if err := rCfg.GRPCServer.validate(); err != nil {
+		return nil, fmt.Errorf("invalid grpc-server of %s receiver: %v", rCfg.Name(), err)
+	}
+	config.GRPCServer = rCfg.GRPCServer
+	config.MaxInFlight = rCfg.MaxInFlight
+
+	if _, err := netacl.New(rCfg.AllowedCIDRs, rCfg.Name()); err != nil {
+		return nil, fmt.Errorf("invalid allowed-cidrs of %s receiver: %v", rCfg.Name(), err)
+	}
+	config.AllowedCIDRs = rCfg.AllowedCIDRs
+
+	if err := rCfg.Decompression.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid decompression of %s receiver: %v", rCfg.Name(), err)
+	}
+
+	if rCfg.Relay {
+		config.CollectorGRPCRelay = true
+		if !rCfg.ProcessTags.IsDefault() {
+			logger.Info("The gRPC requests are decoded, the process tags are merged into the spans",
+				zap.String("receiver", rCfg.Name()))
+		} else if rCfg.PeerAddress.Enabled {
+			logger.Info("The gRPC requests are decoded, the peer address is added to the spans",
+				zap.String("receiver", rCfg.Name()))
+		} else if acceptsRelay(nextConsumer) == nil {
+			logger.Info("The gRPC requests are decoded, the attached pipelines don't support relaying them",
+				zap.String("receiver", rCfg.Name()))
 		}
 	}
 
-	if (protoGRPC == nil && protoHTTP == nil && protoTChannel == nil) ||
-		(config.CollectorGRPCPort == 0 && config.CollectorHTTPPort == 0 && config.CollectorThriftPort == 0) {
-		err := fmt.Errorf("either %v, %v, or %v protocol endpoint with non-zero port must be enabled for %s receiver",
-			protoGRPC,
-			protoThriftHTTP,
-			protoThriftTChannel,
-			typeStr,
-		)
-		return nil, err
+	if rCfg.Auth != "" && (config.CollectorThriftEndpoint != "" || config.CollectorThriftPort != 0) {
+		return nil, fmt.Errorf("%s protocol of %s receiver doesn't support auth, it must be disabled", protoThriftTChannel, rCfg.Name())
 	}
 
 	// Create the receiver.
-	return New(ctx, &config, nextConsumer)
+	r, err := New(ctx, &config, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	r.(*jReceiver).authenticator = auth.NewAuthenticator(logger, rCfg.Auth, collectorReceiverTagValue)
+	r.(*jReceiver).decompression = rCfg.Decompression
+	return r, nil
 }
 
 // CreateMetricsReceiver creates a metrics receiver based on provided config.
@@ -148,6 +247,83 @@ func (f *Factory) CreateMetricsReceiver(
 	return nil, configerror.ErrDataTypeIsNotSupported
 }
 
+// setProtocols sets the listeners of the enabled protocols of the config.
+func setProtocols(config *Configuration, rCfg *Config) error {
+	names := make([]string, 0, len(rCfg.Protocols))
+	for name := range rCfg.Protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	enabled := false
+	for _, name := range names {
+		ps := rCfg.Protocols[name]
+		if ps == nil || !ps.IsEnabled() {
+			continue
+		}
+		enabled = true
+
+		port, err := extractPortFromEndpoint(ps.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid endpoint of %s protocol of %s receiver: %v", name, rCfg.Name(), err)
+		}
+		switch name {
+		case protoGRPC:
+			config.CollectorGRPCPort = port
+			config.CollectorGRPCEndpoint = ps.Endpoint
+			config.CollectorGRPCTLS = ps.TLSCredentials
+		case protoThriftHTTP:
+			config.CollectorHTTPPort = port
+			config.CollectorHTTPEndpoint = ps.Endpoint
+			config.CollectorHTTPTLS = ps.TLSCredentials
+		case protoThriftTChannel:
+			config.CollectorThriftPort = port
+			config.CollectorThriftEndpoint = ps.Endpoint
+		case protoThriftCompact:
+			config.AgentCompactThriftPort = port
+			config.AgentCompactThriftEndpoint = ps.Endpoint
+		case protoThriftBinary:
+			config.AgentBinaryThriftPort = port
+			config.AgentBinaryThriftEndpoint = ps.Endpoint
+		case protoAgentHTTP:
+			config.AgentPort = port
+			config.AgentEndpoint = ps.Endpoint
+		default:
+			return fmt.Errorf("unknown protocol %q of %s receiver", name, rCfg.Name())
+		}
+
+		if ps.TLSCredentials != nil {
+			if name != protoGRPC && name != protoThriftHTTP {
+				return fmt.Errorf("%s protocol of %s receiver doesn't support tls-credentials", name, rCfg.Name())
+			}
+			if _, err := ps.TLSCredentials.ServerConfig(); err != nil {
+				return fmt.Errorf("invalid tls-credentials of %s protocol of %s receiver: %v", name, rCfg.Name(), err)
+			}
+		}
+	}
+
+	if !enabled {
+		return fmt.Errorf("at least one protocol must be enabled for %s receiver", rCfg.Name())
+	}
+	return nil
+}
+
+// validateAgentHTTPSpansPath returns an error if the path is not absolute or
+// is served by the sampling endpoints of the agent-http listener.
+func validateAgentHTTPSpansPath(path string) error {
+	if path == "" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path %q must start with /", path)
+	}
+	switch path {
+	case "/", "/sampling", "/baggageRestrictions":
+		return fmt.Errorf("path %q is served by the sampling endpoints", path)
+	}
+	return nil
+}
+
 // extract the port number from string in "address:port" format. If the
 // port number cannot be extracted returns an error.
 func extractPortFromEndpoint(endpoint string) (int, error) {
@@ -16,12 +16,16 @@ package jaegerreceiver
 
 import (
 	"context"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -98,11 +102,72 @@ func TestCreateNoProtocols(t *testing.T) {
 	cfg := factory.CreateDefaultConfig()
 	rCfg := cfg.(*Config)
 
-	delete(rCfg.Protocols, protoGRPC)
-	delete(rCfg.Protocols, protoThriftHTTP)
-	delete(rCfg.Protocols, protoThriftTChannel)
+	rCfg.Protocols = map[string]*ProtocolSettings{}
 	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
-	assert.Error(t, err, "receiver creation with no protocols must fail")
+	assert.EqualError(t, err, "at least one protocol must be enabled for jaeger receiver")
+
+	rCfg.Protocols[protoGRPC] = &ProtocolSettings{Endpoint: defaultGRPCBindEndpoint, Disabled: true}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, "at least one protocol must be enabled for jaeger receiver")
+}
+
+func TestCreateUnknownProtocol(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Protocols["thrift-udp"] = &ProtocolSettings{Endpoint: "127.0.0.1:6831"}
+	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, `unknown protocol "thrift-udp" of jaeger receiver`)
+}
+
+func TestCreateOnlyConfiguredProtocols(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Protocols = map[string]*ProtocolSettings{
+		protoThriftCompact: {Endpoint: "127.0.0.1:6831"},
+		protoThriftHTTP:    {Endpoint: "127.0.0.1:14268", Disabled: true},
+	}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+
+	jr := tReceiver.(*jReceiver)
+	assert.Equal(t, "127.0.0.1:6831", jr.agentCompactThriftAddr())
+	assert.Equal(t, "", jr.agentBinaryThriftAddr())
+	assert.Equal(t, "", jr.agentAddress())
+	assert.Equal(t, "", jr.collectorAddr())
+	assert.Equal(t, "", jr.tchannelAddr())
+	assert.Equal(t, "", jr.grpcAddr())
+}
+
+func TestCreateWithTLSCredentials(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	creds := &tlsutil.Credentials{
+		CertFile: path.Join(".", "testdata", "server.crt"),
+		KeyFile:  path.Join(".", "testdata", "server.key"),
+	}
+	rCfg.Protocols[protoGRPC].TLSCredentials = creds
+	rCfg.Protocols[protoThriftHTTP].TLSCredentials = creds
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+
+	jr := tReceiver.(*jReceiver)
+	assert.Equal(t, creds, jr.config.CollectorGRPCTLS)
+	assert.Equal(t, creds, jr.config.CollectorHTTPTLS)
+
+	rCfg.Protocols[protoThriftTChannel].TLSCredentials = creds
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, "thrift-tchannel protocol of jaeger receiver doesn't support tls-credentials")
+
+	rCfg.Protocols[protoThriftTChannel].TLSCredentials = nil
+	rCfg.Protocols[protoGRPC].TLSCredentials = &tlsutil.Credentials{CertFile: creds.CertFile}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, "invalid tls-credentials of grpc protocol of jaeger receiver: both cert-file and key-file must be set")
 }
 
 func TestCreateWithoutThrift(t *testing.T) {
@@ -115,3 +180,236 @@ func TestCreateWithoutThrift(t *testing.T) {
 	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
 	assert.NoError(t, err, "receiver creation without the Thrift protocols must not fail")
 }
+
+func TestCreateWithEndpoints(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Protocols[protoGRPC].Endpoint = "127.0.0.1:9876"
+	rCfg.Protocols[protoThriftHTTP].Endpoint = ":3456"
+	rCfg.Protocols[protoThriftTChannel].Endpoint = "0.0.0.0:123"
+	rCfg.Protocols[protoThriftCompact] = &ProtocolSettings{Endpoint: "127.0.0.1:6831"}
+	rCfg.Protocols[protoThriftBinary] = &ProtocolSettings{Endpoint: "127.0.0.1:6832"}
+	rCfg.Protocols[protoAgentHTTP] = &ProtocolSettings{Endpoint: "127.0.0.1:5778"}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+
+	jr := tReceiver.(*jReceiver)
+	assert.Equal(t, "127.0.0.1:9876", jr.grpcAddr())
+	assert.Equal(t, ":3456", jr.collectorAddr())
+	assert.Equal(t, "0.0.0.0:123", jr.tchannelAddr())
+	assert.Equal(t, "127.0.0.1:6831", jr.agentCompactThriftAddr())
+	assert.Equal(t, "127.0.0.1:6832", jr.agentBinaryThriftAddr())
+	assert.Equal(t, "127.0.0.1:5778", jr.agentAddress())
+}
+
+func TestCreateWithIPv6Endpoints(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Protocols[protoGRPC].Endpoint = "[::]:9876"
+	rCfg.Protocols[protoThriftHTTP].Endpoint = "[::1]:3456"
+	rCfg.Protocols[protoThriftTChannel].Endpoint = "[fe80::1%eth0]:123"
+	rCfg.Protocols[protoThriftCompact] = &ProtocolSettings{Endpoint: "[::1]:6831"}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+
+	jr := tReceiver.(*jReceiver)
+	assert.Equal(t, "[::]:9876", jr.grpcAddr())
+	assert.Equal(t, "[::1]:3456", jr.collectorAddr())
+	assert.Equal(t, "[fe80::1%eth0]:123", jr.tchannelAddr())
+	assert.Equal(t, "[::1]:6831", jr.agentCompactThriftAddr())
+
+	// An IPv6 host without square brackets is ambiguous.
+	rCfg.Protocols[protoGRPC].Endpoint = "::1:9876"
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with unbracketed IPv6 endpoint must fail")
+}
+
+func TestCreateInvalidAgentEndpoint(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Protocols[protoThriftCompact] = &ProtocolSettings{Endpoint: "6831"}
+	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with invalid agent endpoint must fail")
+}
+
+func TestCreateWithRelay(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Relay = true
+	next := &mockRawTraceConsumer{accepts: true}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, next)
+	require.NoError(t, err, "receiver creation failed")
+
+	jr := tReceiver.(*jReceiver)
+	assert.True(t, jr.config.CollectorGRPCRelay)
+	assert.Equal(t, next, jr.relayConsumer())
+
+	next.accepts = false
+	assert.Nil(t, jr.relayConsumer())
+}
+
+func TestCreateWithProcessTags(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Relay = true
+	rCfg.ProcessTags.CopyToSpans = true
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockRawTraceConsumer{accepts: true})
+	require.NoError(t, err, "receiver creation failed")
+
+	jr := tReceiver.(*jReceiver)
+	assert.True(t, jr.config.ProcessTags.CopyToSpans)
+	// Relaying the requests would bypass the process tags mapping.
+	assert.Nil(t, jr.relayConsumer())
+
+	rCfg.ProcessTags.Precedence = "node"
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with invalid precedence must fail")
+}
+
+func TestCreateWithAgentUDP(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.AgentUDP.SocketBufferSize = 1 << 20
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.Equal(t, rCfg.AgentUDP, tReceiver.(*jReceiver).config.AgentUDP)
+
+	rCfg.AgentUDP.Workers = -1
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with negative workers must fail")
+}
+
+func TestCreateWithAgentHTTPSpansPath(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.AgentHTTPSpansPath = "/api/traces"
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.Equal(t, "/api/traces", tReceiver.(*jReceiver).config.AgentHTTPSpansPath)
+
+	for _, path := range []string{"api/traces", "/", "/sampling"} {
+		rCfg.AgentHTTPSpansPath = path
+		_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+		assert.Error(t, err, "receiver creation with path %q must fail", path)
+	}
+
+	rCfg.AgentHTTPSpansPath = "/api/traces"
+	rCfg.Protocols[protoAgentHTTP].Disabled = true
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation without the agent-http protocol must fail")
+}
+
+func TestCreateWithGRPCServer(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.GRPCServer.MaxRecvMsgSizeMiB = 16
+	rCfg.GRPCServer.Keepalive.Time = time.Minute
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.Equal(t, rCfg.GRPCServer, tReceiver.(*jReceiver).config.GRPCServer)
+
+	rCfg.GRPCServer.Keepalive.Timeout = -time.Second
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with negative keepalive timeout must fail")
+}
+
+func TestCreateWithDecompression(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.Decompression.MaxRatio = 10
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.Equal(t, rCfg.Decompression, tReceiver.(*jReceiver).decompression)
+
+	rCfg.Decompression.MaxRatio = -1
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, "invalid decompression of jaeger receiver: max-ratio must not be negative")
+}
+
+func TestCreateWithAllowedCIDRs(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.AllowedCIDRs = []string{"10.0.0.0/8"}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.NotNil(t, tReceiver.(*jReceiver).acl)
+
+	rCfg.AllowedCIDRs = []string{"10.0.0.0/8", "not-a-cidr"}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.EqualError(t, err, `invalid allowed-cidrs of jaeger receiver: invalid CIDR "not-a-cidr"`)
+}
+
+func TestCreateWithSamplingStrategiesFile(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.SamplingStrategiesFile = path.Join(".", "testdata", "strategies.json")
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.NotNil(t, tReceiver.(*jReceiver).staticStrategies)
+
+	rCfg.SamplingStrategies = "adaptive-sampling"
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with both sampling strategies must fail")
+
+	rCfg.SamplingStrategies = ""
+	rCfg.SamplingStrategiesFile = path.Join(".", "testdata", "missing.json")
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with missing strategies file must fail")
+}
+
+func TestCreateWithUpstreamSampling(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.UpstreamSampling.Endpoint = "http://jaeger-agent:5778/sampling"
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.NotNil(t, tReceiver.(*jReceiver).upstreamStrategies)
+
+	rCfg.SamplingStrategiesFile = path.Join(".", "testdata", "strategies.json")
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with both upstream and file strategies must fail")
+
+	rCfg.SamplingStrategiesFile = ""
+	rCfg.UpstreamSampling.Endpoint = "jaeger-agent:5778"
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with an endpoint that is not a URL must fail")
+}
+
+func TestCreateWithDefaultSamplingRate(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.DefaultSamplingRate = 0.1
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	require.NoError(t, err, "receiver creation failed")
+	assert.Equal(t, 0.1, tReceiver.(*jReceiver).config.DefaultSamplingRate)
+
+	rCfg.DefaultSamplingRate = 1.5
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with a sampling rate above 1 must fail")
+}
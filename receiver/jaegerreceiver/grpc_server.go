@@ -0,0 +1,79 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// GRPCServerSettings tunes the gRPC server of the grpc collector listener,
+// the gRPC defaults are used for the unset settings.
+type GRPCServerSettings struct {
+	// MaxRecvMsgSizeMiB is the size in MiB of the largest request accepted,
+	// the larger ones fail with RESOURCE_EXHAUSTED. The gRPC default is 4MiB.
+	MaxRecvMsgSizeMiB uint64 `mapstructure:"max-recv-msg-size-mib"`
+
+	// MaxConcurrentStreams limits the concurrent streams, i.e. requests, of
+	// each client connection.
+	MaxConcurrentStreams uint32 `mapstructure:"max-concurrent-streams"`
+
+	// Keepalive pings the idle client connections so that the intermediaries
+	// don't drop them.
+	Keepalive KeepaliveSettings `mapstructure:"keepalive"`
+}
+
+// KeepaliveSettings configures the pings of the idle client connections of
+// the gRPC server.
+type KeepaliveSettings struct {
+	// Time is the idle time of a connection after which it is pinged. The
+	// gRPC default is 2 hours.
+	Time time.Duration `mapstructure:"time"`
+
+	// Timeout is the time the server waits for the answer of a ping before
+	// closing the connection. The gRPC default is 20 seconds.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// validate returns an error if the settings are invalid.
+func (s GRPCServerSettings) validate() error {
+	if s.Keepalive.Time < 0 || s.Keepalive.Timeout < 0 {
+		return errors.New("keepalive durations must not be negative")
+	}
+	return nil
+}
+
+// serverOptions returns the options of the gRPC server applying the
+// settings.
+func (s GRPCServerSettings) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if s.MaxRecvMsgSizeMiB > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(s.MaxRecvMsgSizeMiB*1024*1024)))
+	}
+	if s.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(s.MaxConcurrentStreams))
+	}
+	// The server applies the defaults to the zero values.
+	if s.Keepalive.Time > 0 || s.Keepalive.Timeout > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    s.Keepalive.Time,
+			Timeout: s.Keepalive.Timeout,
+		}))
+	}
+	return opts
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
 )
 
@@ -40,6 +41,20 @@ func TestJaegerAgentUDP_ThriftCompact_6831(t *testing.T) {
 	})
 }
 
+func TestJaegerAgentUDP_ThriftCompact_Endpoint(t *testing.T) {
+	// Bind all the listeners to free ports of the loopback interface, so the
+	// test doesn't depend on the default ports being available.
+	endpoint := testutils.GetAvailableLocalAddress(t)
+	testJaegerAgent(t, endpoint, &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalAddress(t),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: endpoint,
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+	})
+}
+
 func TestJaegerAgentUDP_ThriftBinary_6832(t *testing.T) {
 	t.Skipf("Unfortunately due to Jaeger internal versioning, OpenCensus-Go's Thrift seems to conflict with ours")
 
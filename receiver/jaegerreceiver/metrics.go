@@ -0,0 +1,65 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+var (
+	tagUDPListenerKey, _   = tag.NewKey("listener")
+	tagUDPDropReasonKey, _ = tag.NewKey("reason")
+
+	statUDPDroppedPackets = stats.Int64("jaeger_agent_udp_dropped_packets", "Number of UDP packets dropped by the agent listeners, because their queue was full or by the OS because the socket buffer was full", stats.UnitDimensionless)
+	statUDPReceivedSpans  = stats.Int64("jaeger_agent_udp_received_spans", "Number of spans received by the agent listeners", stats.UnitDimensionless)
+	statUDPDroppedSpans   = stats.Int64("jaeger_agent_udp_dropped_spans", "Number of spans received by the agent listeners and dropped because they failed to be translated", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to the Jaeger receiver.
+func MetricViews(level telemetry.Level) []*view.View {
+	if level == telemetry.None {
+		return nil
+	}
+
+	udpDroppedPacketsView := &view.View{
+		Name:        statUDPDroppedPackets.Name(),
+		Measure:     statUDPDroppedPackets,
+		Description: statUDPDroppedPackets.Description(),
+		TagKeys:     []tag.Key{tagUDPListenerKey, tagUDPDropReasonKey},
+		Aggregation: view.Sum(),
+	}
+
+	udpReceivedSpansView := &view.View{
+		Name:        statUDPReceivedSpans.Name(),
+		Measure:     statUDPReceivedSpans,
+		Description: statUDPReceivedSpans.Description(),
+		TagKeys:     []tag.Key{tagUDPListenerKey},
+		Aggregation: view.Sum(),
+	}
+
+	udpDroppedSpansView := &view.View{
+		Name:        statUDPDroppedSpans.Name(),
+		Measure:     statUDPDroppedSpans,
+		Description: statUDPDroppedSpans.Description(),
+		TagKeys:     []tag.Key{tagUDPListenerKey},
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{udpDroppedPacketsView, udpReceivedSpansView, udpDroppedSpansView}
+}
@@ -0,0 +1,99 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/jaegerrelay"
+)
+
+// relayServer is the gRPC collector service relaying the PostSpans requests
+// without decoding them.
+type relayServer interface {
+	relayPostSpans(ctx context.Context, req jaegerrelay.Message) (*api_v2.PostSpansResponse, error)
+}
+
+var relayServiceDesc = grpc.ServiceDesc{
+	ServiceName: jaegerrelay.ServiceName,
+	HandlerType: (*relayServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PostSpans",
+			Handler:    relayPostSpansHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api_v2.proto",
+}
+
+func relayPostSpansHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var in jaegerrelay.Message
+	if err := dec(&in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(relayServer).relayPostSpans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: jaegerrelay.PostSpansMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(relayServer).relayPostSpans(ctx, req.(jaegerrelay.Message))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// relayConsumer returns the next consumer if the gRPC requests must be relayed
+// to it, i.e. if the relay is enabled, the process tags are not merged, the
+// peer address is not added and the consumer accepts the requests.
+func (jr *jReceiver) relayConsumer() consumer.RawTraceConsumer {
+	if jr.config == nil || !jr.config.CollectorGRPCRelay || !jr.config.ProcessTags.IsDefault() ||
+		jr.config.PeerAddress.Enabled {
+		return nil
+	}
+	return acceptsRelay(jr.nextConsumer)
+}
+
+// acceptsRelay returns the consumer if it accepts the relayed gRPC requests.
+func acceptsRelay(nextConsumer consumer.TraceConsumer) consumer.RawTraceConsumer {
+	rtc, ok := nextConsumer.(consumer.RawTraceConsumer)
+	if !ok || !rtc.AcceptsRawTraceFormat(jaegerrelay.Format) {
+		return nil
+	}
+	return rtc
+}
+
+func (jr *jReceiver) relayPostSpans(ctx context.Context, req jaegerrelay.Message) (*api_v2.PostSpansResponse, error) {
+	rtd := consumerdata.RawTraceData{
+		Format:  jaegerrelay.Format,
+		Payload: req,
+	}
+	if err := jr.relayConsumer().ConsumeRawTraceData(ctx, rtd); err != nil {
+		return nil, err
+	}
+	return &api_v2.PostSpansResponse{}, nil
+}
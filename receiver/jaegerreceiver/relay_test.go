@@ -0,0 +1,117 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/jaegerrelay"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+type mockRawTraceConsumer struct {
+	exportertest.SinkTraceExporter
+	accepts bool
+	raw     []consumerdata.RawTraceData
+}
+
+var _ consumer.RawTraceConsumer = (*mockRawTraceConsumer)(nil)
+
+func (m *mockRawTraceConsumer) AcceptsRawTraceFormat(format string) bool {
+	return m.accepts && format == jaegerrelay.Format
+}
+
+func (m *mockRawTraceConsumer) ConsumeRawTraceData(ctx context.Context, rtd consumerdata.RawTraceData) error {
+	m.raw = append(m.raw, rtd)
+	return nil
+}
+
+func TestGRPCRelay(t *testing.T) {
+	next := &mockRawTraceConsumer{accepts: true}
+	req := postSpansRelay(t, next)
+
+	require.Len(t, next.raw, 1)
+	assert.Equal(t, jaegerrelay.Format, next.raw[0].Format)
+	got := &api_v2.PostSpansRequest{}
+	require.NoError(t, proto.Unmarshal(next.raw[0].Payload, got))
+	assert.Equal(t, req, got)
+	assert.Empty(t, next.AllTraces())
+}
+
+func TestGRPCRelay_NotAccepted(t *testing.T) {
+	next := &mockRawTraceConsumer{accepts: false}
+	req := postSpansRelay(t, next)
+
+	assert.Empty(t, next.raw)
+	got := next.AllTraces()
+	require.Len(t, got, 1)
+	assert.Len(t, got[0].Spans, len(req.Batch.Spans))
+}
+
+func TestGRPCRelay_PeerAddress(t *testing.T) {
+	next := &mockRawTraceConsumer{accepts: true}
+	req := postSpansRelay(t, next, func(config *Configuration) {
+		config.PeerAddress.Enabled = true
+	})
+
+	// The requests are decoded to add the peer address.
+	assert.Empty(t, next.raw)
+	got := next.AllTraces()
+	require.Len(t, got, 1)
+	assert.Len(t, got[0].Spans, len(req.Batch.Spans))
+	assert.NotEmpty(t, got[0].Node.Attributes[peeraddr.IPAttribute])
+}
+
+func postSpansRelay(t *testing.T, next consumer.TraceConsumer, opts ...func(*Configuration)) *api_v2.PostSpansRequest {
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCRelay:         true,
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	jr, err := New(context.Background(), config, next)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	cl := api_v2.NewCollectorServiceClient(conn)
+	_, err = cl.PostSpans(context.Background(), req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	return req
+}
@@ -0,0 +1,185 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
+)
+
+const (
+	defaultUpstreamRefreshInterval = time.Minute
+	defaultUpstreamTimeout         = 5 * time.Second
+)
+
+// UpstreamSamplingSettings configures the proxying of the sampling
+// strategies served to the SDKs from an upstream Jaeger agent or collector.
+type UpstreamSamplingSettings struct {
+	// Endpoint is the URL of the sampling endpoint of the upstream, e.g.
+	// http://jaeger-agent:5778/sampling, queried with the service parameter.
+	// The strategies are not proxied if it is empty.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// RefreshInterval is the time the strategy of a service is cached before
+	// being fetched again, 1 minute by default. A failed fetch is retried
+	// after the same interval.
+	RefreshInterval time.Duration `mapstructure:"refresh-interval"`
+
+	// MaxStaleness is the time a cached strategy keeps being served after
+	// failing to refresh it, after which the default strategy is served. The
+	// cached strategies are served until the upstream is reachable again if
+	// it is zero.
+	MaxStaleness time.Duration `mapstructure:"max-staleness"`
+
+	// Timeout limits the requests to the upstream, 5 seconds by default.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// validate returns an error if the settings are invalid.
+func (s UpstreamSamplingSettings) validate() error {
+	if s.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("endpoint %q is not an http or https URL", s.Endpoint)
+	}
+	if s.RefreshInterval < 0 || s.MaxStaleness < 0 || s.Timeout < 0 {
+		return errors.New("durations must not be negative")
+	}
+	return nil
+}
+
+// samplingProxy serves the sampling strategies fetched from the upstream,
+// cached per service.
+type samplingProxy struct {
+	endpoint        *url.URL
+	client          *http.Client
+	refreshInterval time.Duration
+	maxStaleness    time.Duration
+	now             func() time.Time
+
+	mu       sync.Mutex
+	services map[string]*cachedStrategy
+}
+
+// cachedStrategy is the last strategy fetched for a service, its mutex is
+// held while fetching it so that the concurrent requests of the service wait
+// for a single fetch.
+type cachedStrategy struct {
+	mu          sync.Mutex
+	resp        *sampling.SamplingStrategyResponse
+	lastRefresh time.Time
+	lastAttempt time.Time
+}
+
+func newSamplingProxy(s UpstreamSamplingSettings) (*samplingProxy, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	refreshInterval := s.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultUpstreamRefreshInterval
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultUpstreamTimeout
+	}
+	return &samplingProxy{
+		endpoint:        endpoint,
+		client:          &http.Client{Timeout: timeout},
+		refreshInterval: refreshInterval,
+		maxStaleness:    s.MaxStaleness,
+		now:             time.Now,
+		services:        make(map[string]*cachedStrategy),
+	}, nil
+}
+
+// strategy returns the strategy of the service, fetched from the upstream if
+// the cached one is older than the refresh interval. The cached strategy is
+// returned while the upstream fails, unless it is older than the max
+// staleness, in which case the error is returned.
+func (sp *samplingProxy) strategy(ctx context.Context, serviceName string) (*sampling.SamplingStrategyResponse, error) {
+	sp.mu.Lock()
+	cached, ok := sp.services[serviceName]
+	if !ok {
+		cached = &cachedStrategy{}
+		sp.services[serviceName] = cached
+	}
+	sp.mu.Unlock()
+
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+
+	now := sp.now()
+	if now.Sub(cached.lastAttempt) >= sp.refreshInterval {
+		cached.lastAttempt = now
+		resp, err := sp.fetch(ctx, serviceName)
+		if err == nil {
+			cached.resp = resp
+			cached.lastRefresh = now
+			return resp, nil
+		}
+		if cached.resp == nil {
+			return nil, err
+		}
+	}
+	if cached.resp == nil {
+		return nil, fmt.Errorf("no sampling strategy of service %q fetched from %s", serviceName, sp.endpoint)
+	}
+	if sp.maxStaleness > 0 && now.Sub(cached.lastRefresh) >= sp.refreshInterval+sp.maxStaleness {
+		return nil, fmt.Errorf("the sampling strategy of service %q fetched from %s is stale", serviceName, sp.endpoint)
+	}
+	return cached.resp, nil
+}
+
+// fetch gets the strategy of the service from the upstream, in the JSON
+// format of the sampling endpoint of the Jaeger agent.
+func (sp *samplingProxy) fetch(ctx context.Context, serviceName string) (*sampling.SamplingStrategyResponse, error) {
+	u := *sp.endpoint
+	query := u.Query()
+	query.Set("service", serviceName)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sp.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s failed with status %d", u.String(), resp.StatusCode)
+	}
+	strategy := &sampling.SamplingStrategyResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(strategy); err != nil {
+		return nil, fmt.Errorf("GET %s returned an invalid strategy: %v", u.String(), err)
+	}
+	return strategy, nil
+}
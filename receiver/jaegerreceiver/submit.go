@@ -0,0 +1,113 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uber/tchannel-go"
+	"github.com/uber/tchannel-go/thrift"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Headers of the responses of the collector reporting the number of spans
+// accepted and rejected. The schemas of the Thrift and gRPC responses can't be
+// extended, so the counts are sent as TChannel headers, HTTP headers and gRPC
+// trailers.
+const (
+	spansAcceptedHeader        = "spans-accepted"
+	spansRejectedHeader        = "spans-rejected"
+	spansRejectedReasonsHeader = "spans-rejected-reasons"
+)
+
+// Reasons the spans are rejected for.
+const (
+	// The batch couldn't be translated.
+	rejectReasonInvalid = "invalid"
+	// The spans were dropped by the translation, e.g. nil spans.
+	rejectReasonDropped = "dropped"
+	// The next consumer failed to consume the spans.
+	rejectReasonRefused = "refused"
+)
+
+// submitResult counts the spans accepted and rejected by a request.
+type submitResult struct {
+	accepted int
+	rejected map[string]int
+}
+
+func (sr *submitResult) accept(spans int) {
+	sr.accepted += spans
+}
+
+func (sr *submitResult) reject(reason string, spans int) {
+	if spans <= 0 {
+		return
+	}
+	if sr.rejected == nil {
+		sr.rejected = make(map[string]int)
+	}
+	sr.rejected[reason] += spans
+}
+
+// headers returns the headers reporting the result. The rejected spans are
+// reported by reason as "reason=count" pairs, sorted by reason.
+func (sr *submitResult) headers() map[string]string {
+	rejected := 0
+	reasons := make([]string, 0, len(sr.rejected))
+	for reason, spans := range sr.rejected {
+		rejected += spans
+		reasons = append(reasons, fmt.Sprintf("%s=%d", reason, spans))
+	}
+	sort.Strings(reasons)
+
+	headers := map[string]string{
+		spansAcceptedHeader: strconv.Itoa(sr.accepted),
+		spansRejectedHeader: strconv.Itoa(rejected),
+	}
+	if len(reasons) > 0 {
+		headers[spansRejectedReasonsHeader] = strings.Join(reasons, ",")
+	}
+	return headers
+}
+
+// setTChannelHeaders sets the headers on the response of the TChannel call,
+// if the context is the one of a call.
+func (sr *submitResult) setTChannelHeaders(ctx thrift.Context) {
+	if tchannel.CurrentCall(ctx) == nil {
+		return
+	}
+	ctx.SetResponseHeaders(sr.headers())
+}
+
+// setHTTPHeaders sets the headers on the HTTP response.
+func (sr *submitResult) setHTTPHeaders(header http.Header) {
+	for k, v := range sr.headers() {
+		header.Set(k, v)
+	}
+}
+
+// setGRPCTrailer sets the headers as trailer of the gRPC call. The trailer is
+// sent with the errors too.
+func (sr *submitResult) setGRPCTrailer(ctx context.Context) {
+	// It fails only outside of a gRPC call.
+	_ = grpc.SetTrailer(ctx, metadata.New(sr.headers()))
+}
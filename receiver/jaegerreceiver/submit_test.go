@@ -0,0 +1,320 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tchanThrift "github.com/uber/tchannel-go/thrift"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestSubmitResult_Headers(t *testing.T) {
+	sr := &submitResult{}
+	assert.Equal(t, map[string]string{
+		spansAcceptedHeader: "0",
+		spansRejectedHeader: "0",
+	}, sr.headers())
+
+	sr.accept(3)
+	sr.reject(rejectReasonRefused, 2)
+	sr.reject(rejectReasonDropped, 0)
+	sr.reject(rejectReasonInvalid, 1)
+	sr.reject(rejectReasonRefused, 4)
+	assert.Equal(t, map[string]string{
+		spansAcceptedHeader:        "3",
+		spansRejectedHeader:        "7",
+		spansRejectedReasonsHeader: "invalid=1,refused=6",
+	}, sr.headers())
+}
+
+func TestSubmitBatches_Result(t *testing.T) {
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}, {OperationName: "b"}},
+	}
+
+	jr := &jReceiver{nextConsumer: new(exportertest.SinkTraceExporter)}
+	ctx, cancel := tchanThrift.NewContext(time.Second)
+	defer cancel()
+	jbsr, result := jr.submitBatches(ctx, []*jaeger.Batch{batch, batch}, "")
+	require.Len(t, jbsr, 2)
+	assert.True(t, jbsr[0].Ok)
+	assert.True(t, jbsr[1].Ok)
+	assert.Equal(t, &submitResult{accepted: 4}, result)
+
+	jr = &jReceiver{nextConsumer: exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("full")))}
+	jbsr, result = jr.submitBatches(ctx, []*jaeger.Batch{batch}, "")
+	require.Len(t, jbsr, 1)
+	assert.False(t, jbsr[0].Ok)
+	assert.Equal(t, &submitResult{rejected: map[string]int{rejectReasonRefused: 2}}, result)
+}
+
+func TestReception_ResultHeaders(t *testing.T) {
+	config := &Configuration{
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalAddress(t),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+	}
+	next := exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("full")))
+	jr, err := New(context.Background(), config, next)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	// HTTP
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	resp, err := http.Post("http://"+config.CollectorHTTPEndpoint+"/api/traces", "application/x-thrift", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get(spansAcceptedHeader))
+	assert.Equal(t, "1", resp.Header.Get(spansRejectedHeader))
+	assert.Equal(t, "refused=1", resp.Header.Get(spansRejectedReasonsHeader))
+
+	// gRPC
+	conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	var trailer metadata.MD
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(context.Background(), req,
+		grpc.WaitForReady(true), grpc.Trailer(&trailer))
+	require.Error(t, err)
+	assert.Equal(t, []string{"0"}, trailer.Get(spansAcceptedHeader))
+	assert.Equal(t, []string{"2"}, trailer.Get(spansRejectedHeader))
+	assert.Equal(t, []string{"refused=2"}, trailer.Get(spansRejectedReasonsHeader))
+}
+
+func TestSubmitBatches_Throttled(t *testing.T) {
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), &Configuration{MaxInFlight: 1}, sink)
+	require.NoError(t, err)
+	controller := jr.(*jReceiver).admission
+	require.NotNil(t, controller)
+
+	// Hold the only request slot.
+	done, ok := controller.Admit(context.Background())
+	require.True(t, ok)
+
+	ctx, cancel := tchanThrift.NewContext(time.Second)
+	defer cancel()
+	_, err = jr.(*jReceiver).SubmitBatches(ctx, []*jaeger.Batch{batch})
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(nil))
+	jr.(*jReceiver).collectorHTTPHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Empty(t, sink.AllTraces())
+
+	done()
+	jbsr, err := jr.(*jReceiver).SubmitBatches(ctx, []*jaeger.Batch{batch})
+	require.NoError(t, err)
+	require.Len(t, jbsr, 1)
+	assert.True(t, jbsr[0].Ok)
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+func TestHTTPReception_Decompression(t *testing.T) {
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	gzw := gzip.NewWriter(&gzipped)
+	_, err = gzw.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	tests := []struct {
+		name     string
+		settings decompression.Settings
+		want     int
+	}{
+		{"within limits", decompression.DefaultSettings(), http.StatusAccepted},
+		{"too large", decompression.Settings{MaxDecompressedSize: int64(len(body) - 1)}, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			jr, err := New(context.Background(), &Configuration{}, sink)
+			require.NoError(t, err)
+			jr.(*jReceiver).decompression = tt.settings
+
+			req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(gzipped.Bytes()))
+			req.Header.Set("Content-Type", "application/x-thrift")
+			req.Header.Set("Content-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			jr.(*jReceiver).collectorHTTPHandler().ServeHTTP(rec, req)
+			require.Equal(t, tt.want, rec.Code)
+			require.Equal(t, tt.want == http.StatusAccepted, len(sink.AllTraces()) > 0)
+		})
+	}
+}
+
+func TestHTTPReception_DecompressionFailure(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	jr, err := New(context.Background(), &Configuration{}, new(exportertest.SinkTraceExporter))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/traces", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Type", "application/x-thrift")
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		jr.(*jReceiver).collectorHTTPHandler().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDecompressionFailures(collectorReceiverTagValue, 2))
+}
+
+func TestAgentHTTPSpans(t *testing.T) {
+	config := &Configuration{
+		AgentEndpoint:      testutils.GetAvailableLocalAddress(t),
+		AgentHTTPSpansPath: "/api/traces",
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "frontend"},
+		Spans:   []*jaeger.Span{{TraceIdLow: 1, SpanId: 2, OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	url := "http://" + config.AgentEndpoint + "/api/traces"
+	resp, err := http.Post(url, "application/vnd.apache.thrift.binary", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	assert.Equal(t, "frontend", got[0].Node.ServiceInfo.Name)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "a", got[0].Spans[0].Name.Value)
+
+	resp, err = http.Post(url, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// The sampling endpoints are still served.
+	resp, err = http.Get("http://" + config.AgentEndpoint + "/sampling?service=frontend")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+func TestAgentHTTPSpans_Restricted(t *testing.T) {
+	auth.Register("test-auth", auth.ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, auth.ErrInvalidToken
+		}
+		return ctx, nil
+	}))
+	defer auth.Unregister("test-auth")
+
+	config := &Configuration{
+		AgentHTTPSpansPath: "/api/traces",
+		AllowedCIDRs:       []string{"10.0.0.0/8"},
+		MaxInFlight:        1,
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	jr.(*jReceiver).authenticator = auth.NewAuthenticator(zap.NewNop(), "test-auth", collectorReceiverTagValue)
+	handler := jr.(*jReceiver).agentHTTPSpansHandler()
+
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	post := func(remoteAddr, authorization string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-thrift")
+		req.RemoteAddr = remoteAddr
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusForbidden, post("172.16.0.1:1234", "Bearer secret"))
+	assert.Equal(t, http.StatusUnauthorized, post("10.0.0.1:1234", ""))
+	assert.Equal(t, http.StatusUnauthorized, post("10.0.0.1:1234", "Bearer wrong"))
+	assert.Empty(t, sink.AllTraces())
+
+	// Hold the only request slot.
+	done, ok := jr.(*jReceiver).admission.Admit(context.Background())
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, post("10.0.0.1:1234", "Bearer secret"))
+	assert.Empty(t, sink.AllTraces())
+
+	done()
+	assert.Equal(t, http.StatusAccepted, post("10.0.0.1:1234", "Bearer secret"))
+	assert.Len(t, sink.AllTraces(), 1)
+}
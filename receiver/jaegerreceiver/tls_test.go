@@ -0,0 +1,164 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestReception_TLS(t *testing.T) {
+	creds := &tlsutil.Credentials{
+		CertFile: path.Join(".", "testdata", "server.crt"),
+		KeyFile:  path.Join(".", "testdata", "server.key"),
+	}
+	config := &Configuration{
+		CollectorHTTPEndpoint: testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPTLS:      creds,
+		CollectorGRPCEndpoint: testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCTLS:      creds,
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	clientTLS := &tls.Config{InsecureSkipVerify: true}
+
+	// HTTP
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+	resp, err := client.Post("https://"+config.CollectorHTTPEndpoint+"/api/traces", "application/x-thrift", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// The plaintext requests are rejected.
+	resp, err = http.Post("http://"+config.CollectorHTTPEndpoint+"/api/traces", "application/x-thrift", bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	// gRPC
+	conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+	require.NoError(t, err)
+	defer conn.Close()
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(context.Background(), req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	assert.Len(t, sink.AllTraces(), 2)
+}
+
+func TestGRPCReception_MutualTLS(t *testing.T) {
+	config := &Configuration{
+		CollectorGRPCEndpoint: testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCTLS: &tlsutil.Credentials{
+			CertFile: path.Join(".", "testdata", "server.crt"),
+			KeyFile:  path.Join(".", "testdata", "server.key"),
+			// The self-signed certificate is also the CA of the clients.
+			ClientCAFile: path.Join(".", "testdata", "server.crt"),
+		},
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	postSpans := func(clientTLS *tls.Config) error {
+		conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(ctx, req)
+		return err
+	}
+
+	// The clients without certificate are rejected.
+	assert.Error(t, postSpans(&tls.Config{InsecureSkipVerify: true}))
+	assert.Empty(t, sink.AllTraces())
+
+	cert, err := tls.LoadX509KeyPair(config.CollectorGRPCTLS.CertFile, config.CollectorGRPCTLS.KeyFile)
+	require.NoError(t, err)
+	require.NoError(t, postSpans(&tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{cert}}))
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+func TestHTTPReception_MutualTLS(t *testing.T) {
+	config := &Configuration{
+		CollectorHTTPEndpoint: testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPTLS: &tlsutil.Credentials{
+			CertFile:     path.Join(".", "testdata", "server.crt"),
+			KeyFile:      path.Join(".", "testdata", "server.key"),
+			ClientCAFile: path.Join(".", "testdata", "server.crt"),
+		},
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	batch := &jaeger.Batch{
+		Process: jaeger.NewProcess(),
+		Spans:   []*jaeger.Span{{OperationName: "a"}},
+	}
+	body, err := thrift.NewTSerializer().Write(batch)
+	require.NoError(t, err)
+	post := func(clientTLS *tls.Config) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		return client.Post("https://"+config.CollectorHTTPEndpoint+"/api/traces", "application/x-thrift", bytes.NewReader(body))
+	}
+
+	// The clients without certificate are rejected during the handshake.
+	_, err = post(&tls.Config{InsecureSkipVerify: true})
+	assert.Error(t, err)
+
+	cert, err := tls.LoadX509KeyPair(config.CollectorHTTPTLS.CertFile, config.CollectorHTTPTLS.KeyFile)
+	require.NoError(t, err)
+	resp, err := post(&tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Len(t, sink.AllTraces(), 1)
+}
@@ -17,17 +17,29 @@ package jaegerreceiver
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"mime"
 	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	apachethrift "github.com/apache/thrift/lib/go/thrift"
 	"github.com/gorilla/mux"
-	agentapp "github.com/jaegertracing/jaeger/cmd/agent/app"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/httpserver"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/processors"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
-	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/servers"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/servers/thriftudp"
+	collectorSampling "github.com/jaegertracing/jaeger/cmd/collector/app/sampling"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategystore/static"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/thrift-gen/baggage"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
@@ -38,8 +50,19 @@ import (
 	"github.com/uber/tchannel-go/thrift"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/internal/admission"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/jaegerrelay"
+	"github.com/open-telemetry/opentelemetry-service/internal/netacl"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/thriftutil"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
@@ -48,6 +71,10 @@ import (
 
 // Configuration defines the behavior and the ports that
 // the Jaeger receiver will use.
+//
+// Each listener binds to its endpoint, in the host:port form, if set. Otherwise
+// it binds to its port on all network interfaces. The listeners with neither
+// an endpoint nor a port are not started.
 type Configuration struct {
 	CollectorThriftPort int `mapstructure:"tchannel_port"`
 	CollectorHTTPPort   int `mapstructure:"collector_http_port"`
@@ -56,6 +83,70 @@ type Configuration struct {
 	AgentPort              int `mapstructure:"agent_port"`
 	AgentCompactThriftPort int `mapstructure:"agent_compact_thrift_port"`
 	AgentBinaryThriftPort  int `mapstructure:"agent_binary_thrift_port"`
+
+	CollectorThriftEndpoint string `mapstructure:"tchannel_endpoint"`
+	CollectorHTTPEndpoint   string `mapstructure:"collector_http_endpoint"`
+	CollectorGRPCEndpoint   string `mapstructure:"collector_grpc_endpoint"`
+
+	AgentEndpoint              string `mapstructure:"agent_endpoint"`
+	AgentCompactThriftEndpoint string `mapstructure:"agent_compact_thrift_endpoint"`
+	AgentBinaryThriftEndpoint  string `mapstructure:"agent_binary_thrift_endpoint"`
+
+	// CollectorHTTPTLS and CollectorGRPCTLS enable TLS on the collector
+	// listeners, if not nil.
+	CollectorHTTPTLS *tlsutil.Credentials `mapstructure:"collector_http_tls"`
+	CollectorGRPCTLS *tlsutil.Credentials `mapstructure:"collector_grpc_tls"`
+
+	// CollectorGRPCRelay enables relaying the gRPC requests to the next consumer
+	// without decoding them, if the consumer accepts the requests as raw trace data.
+	CollectorGRPCRelay bool `mapstructure:"collector_grpc_relay"`
+
+	// ProcessTags controls how the process tags of the received batches are
+	// merged into the span attributes.
+	ProcessTags jaegertranslator.ProcessTagsMapping `mapstructure:"process_tags"`
+
+	// PeerAddress adds the address of the clients of the collector listeners
+	// to the node of the received spans.
+	PeerAddress peeraddr.Settings `mapstructure:"peer_address"`
+
+	// SamplingStrategies is the name of the adaptive-sampling extension whose
+	// sampling probabilities are returned by GetSamplingStrategy.
+	SamplingStrategies string `mapstructure:"sampling_strategies"`
+
+	// SamplingStrategiesFile is the path of the JSON file of the static
+	// sampling strategies returned by GetSamplingStrategy, it takes precedence
+	// over SamplingStrategies.
+	SamplingStrategiesFile string `mapstructure:"sampling_strategies_file"`
+
+	// DefaultSamplingRate is the probability of the strategy returned by
+	// GetSamplingStrategy when no other strategy applies, an empty strategy is
+	// returned if it is zero.
+	DefaultSamplingRate float64 `mapstructure:"default_sampling_rate"`
+
+	// UpstreamSampling proxies the strategies returned by GetSamplingStrategy
+	// from an upstream Jaeger agent or collector, if its endpoint is set.
+	UpstreamSampling UpstreamSamplingSettings `mapstructure:"upstream_sampling"`
+
+	// AgentHTTPSpansPath is the path of the agent HTTP listener accepting the
+	// thrift batches POSTed by the SDKs, none are accepted if it is empty.
+	AgentHTTPSpansPath string `mapstructure:"agent_http_spans_path"`
+
+	// AgentUDP tunes the UDP listeners of the agent, the defaults are used
+	// for the unset settings.
+	AgentUDP UDPSettings `mapstructure:"agent_udp"`
+
+	// GRPCServer tunes the gRPC server of the collector, the gRPC defaults
+	// are used for the unset settings.
+	GRPCServer GRPCServerSettings `mapstructure:"grpc_server"`
+
+	// MaxInFlight limits the requests processed concurrently by the collector
+	// listeners, no limit other than the global one is applied if it is not
+	// positive.
+	MaxInFlight int `mapstructure:"max_in_flight"`
+
+	// AllowedCIDRs are the networks of the clients allowed to connect to the
+	// collector listeners, all the clients are allowed if it is empty.
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
 }
 
 // Receiver type is used to receive spans that were originally intended to be sent to Jaeger.
@@ -71,113 +162,152 @@ type jReceiver struct {
 
 	config *Configuration
 
-	agent *agentapp.Agent
+	// agentProcessors serve the UDP listeners of the agent, agentServer its
+	// HTTP listener.
+	agentProcessors []processors.Processor
+	agentServer     *http.Server
+	// udpDone stops the reporting of the drops of the UDP sockets of the
+	// agent.
+	udpDone chan struct{}
+	udpWG   sync.WaitGroup
 
 	grpc            *grpc.Server
 	tchannel        *tchannel.Channel
 	collectorServer *http.Server
 
 	defaultAgentCtx context.Context
+
+	peerAddr *peeraddr.Annotator
+
+	// staticStrategies serves the sampling strategies loaded from the
+	// strategies file, if configured.
+	staticStrategies strategystore.StrategyStore
+
+	// upstreamStrategies serves the sampling strategies fetched from the
+	// upstream, if configured.
+	upstreamStrategies *samplingProxy
+
+	// admission throttles the requests of the collector listeners and of the
+	// agent-http spans path exceeding the in-flight limits.
+	admission *admission.Controller
+
+	// acl closes the connections to the collector listeners, and rejects the
+	// requests to the agent-http spans path, of the clients outside the
+	// allowed networks.
+	acl *netacl.ACL
+
+	// authenticator authenticates the requests of the HTTP and gRPC collector
+	// listeners and of the agent-http spans path, if not nil.
+	authenticator *auth.Authenticator
+
+	// decompression limits the decompressed bodies of the thrift batches
+	// POSTed over HTTP.
+	decompression decompression.Settings
 }
 
 const (
-	// As per https://www.jaegertracing.io/docs/1.13/deployment/
-	// By default, the port used by jaeger-agent to send spans in model.proto format
-	defaultGRPCPort = 14250
-	// By default, the port used by jaeger-agent to send spans in jaeger.thrift format
-	defaultTChannelPort = 14267
-	// By default, can accept spans directly from clients in jaeger.thrift format over binary thrift protocol
-	defaultCollectorHTTPPort = 14268
-
-	// As per https://www.jaegertracing.io/docs/1.7/deployment/#agent
-	// 5775	UDP accept zipkin.thrift over compact thrift protocol
-	// 6831	UDP accept jaeger.thrift over compact thrift protocol
-	// 6832	UDP accept jaeger.thrift over binary thrift protocol
-	defaultCompactThriftUDPPort = 6831
-	defaultBinaryThriftUDPPort  = 6832
-
 	traceSource string = "Jaeger"
 )
 
 // New creates a TraceReceiver that receives traffic as a collector with both Thrift and HTTP transports.
 func New(ctx context.Context, config *Configuration, nextConsumer consumer.TraceConsumer) (receiver.TraceReceiver, error) {
-	return &jReceiver{
+	jr := &jReceiver{
 		config:          config,
 		defaultAgentCtx: observability.ContextWithReceiverName(context.Background(), "jaeger-agent"),
 		nextConsumer:    nextConsumer,
-	}, nil
+		decompression:   decompression.DefaultSettings(),
+	}
+	if config != nil {
+		jr.peerAddr = peeraddr.NewAnnotator(config.PeerAddress)
+		jr.admission = admission.NewController(config.MaxInFlight, collectorReceiverTagValue)
+		acl, err := netacl.New(config.AllowedCIDRs, collectorReceiverTagValue)
+		if err != nil {
+			return nil, err
+		}
+		jr.acl = acl
+		if config.SamplingStrategiesFile != "" {
+			store, err := static.NewStrategyStore(static.Options{StrategiesFile: config.SamplingStrategiesFile}, zap.NewNop())
+			if err != nil {
+				return nil, err
+			}
+			jr.staticStrategies = store
+		}
+		if config.UpstreamSampling.Endpoint != "" {
+			proxy, err := newSamplingProxy(config.UpstreamSampling)
+			if err != nil {
+				return nil, err
+			}
+			jr.upstreamStrategies = proxy
+		}
+	}
+	return jr, nil
 }
 
 var _ receiver.TraceReceiver = (*jReceiver)(nil)
 
-func (jr *jReceiver) collectorAddr() string {
-	var port int
-	if jr.config != nil {
-		port = jr.config.CollectorHTTPPort
+// listenAddr returns the address a listener binds to: its endpoint, else its
+// port on all network interfaces, or an empty string if it has neither.
+func listenAddr(endpoint string, port int) string {
+	if endpoint != "" {
+		return endpoint
 	}
-	if port <= 0 {
-		port = defaultCollectorHTTPPort
+	if port > 0 {
+		return fmt.Sprintf(":%d", port)
 	}
-	return fmt.Sprintf(":%d", port)
+	return ""
 }
 
-const defaultAgentPort = 5778
+func (jr *jReceiver) collectorAddr() string {
+	if jr.config == nil {
+		return ""
+	}
+	return listenAddr(jr.config.CollectorHTTPEndpoint, jr.config.CollectorHTTPPort)
+}
 
 func (jr *jReceiver) agentAddress() string {
-	var port int
-	if jr.config != nil {
-		port = jr.config.AgentPort
+	if jr.config == nil {
+		return ""
 	}
-	if port <= 0 {
-		port = defaultAgentPort
+	return listenAddr(jr.config.AgentEndpoint, jr.config.AgentPort)
+}
+
+// agentHTTPSpansPath returns the path of the agent-http listener accepting
+// the thrift batches, empty if it doesn't accept them.
+func (jr *jReceiver) agentHTTPSpansPath() string {
+	if jr.config == nil {
+		return ""
 	}
-	return fmt.Sprintf(":%d", port)
+	return jr.config.AgentHTTPSpansPath
 }
 
 // TODO https://github.com/open-telemetry/opentelemetry-service/issues/267
-//	Remove ThriftTChannel support.
+// Remove ThriftTChannel support.
 func (jr *jReceiver) tchannelAddr() string {
-	var port int
-	if jr.config != nil {
-		port = jr.config.CollectorThriftPort
-	}
-	if port <= 0 {
-		port = defaultTChannelPort
+	if jr.config == nil {
+		return ""
 	}
-	return fmt.Sprintf(":%d", port)
+	return listenAddr(jr.config.CollectorThriftEndpoint, jr.config.CollectorThriftPort)
 }
 
 func (jr *jReceiver) grpcAddr() string {
-	var port int
-	if jr.config != nil {
-		port = jr.config.CollectorGRPCPort
-	}
-	if port <= 0 {
-		port = defaultGRPCPort
+	if jr.config == nil {
+		return ""
 	}
-	return fmt.Sprintf(":%d", port)
+	return listenAddr(jr.config.CollectorGRPCEndpoint, jr.config.CollectorGRPCPort)
 }
 
 func (jr *jReceiver) agentCompactThriftAddr() string {
-	var port int
-	if jr.config != nil {
-		port = jr.config.AgentCompactThriftPort
-	}
-	if port <= 0 {
-		port = defaultCompactThriftUDPPort
+	if jr.config == nil {
+		return ""
 	}
-	return fmt.Sprintf(":%d", port)
+	return listenAddr(jr.config.AgentCompactThriftEndpoint, jr.config.AgentCompactThriftPort)
 }
 
 func (jr *jReceiver) agentBinaryThriftAddr() string {
-	var port int
-	if jr.config != nil {
-		port = jr.config.AgentBinaryThriftPort
+	if jr.config == nil {
+		return ""
 	}
-	if port <= 0 {
-		port = defaultBinaryThriftUDPPort
-	}
-	return fmt.Sprintf(":%d", port)
+	return listenAddr(jr.config.AgentBinaryThriftEndpoint, jr.config.AgentBinaryThriftPort)
 }
 
 func (jr *jReceiver) TraceSource() string {
@@ -217,9 +347,20 @@ func (jr *jReceiver) stopTraceReceptionLocked() error {
 	jr.stopOnce.Do(func() {
 		var errs []error
 
-		if jr.agent != nil {
-			jr.agent.Stop()
-			jr.agent = nil
+		for _, proc := range jr.agentProcessors {
+			proc.Stop()
+		}
+		jr.agentProcessors = nil
+		if jr.agentServer != nil {
+			if aerr := jr.agentServer.Close(); aerr != nil {
+				errs = append(errs, aerr)
+			}
+			jr.agentServer = nil
+		}
+		if jr.udpDone != nil {
+			close(jr.udpDone)
+			jr.udpWG.Wait()
+			jr.udpDone = nil
 		}
 
 		if jr.collectorServer != nil {
@@ -253,32 +394,82 @@ func (jr *jReceiver) stopTraceReceptionLocked() error {
 
 const collectorReceiverTagValue = "jaeger-collector"
 
+// thriftBatchToOCProto translates the batch and merges its process tags
+// according to the configuration.
+func (jr *jReceiver) thriftBatchToOCProto(batch *jaeger.Batch) (consumerdata.TraceData, error) {
+	td, err := jaegertranslator.ThriftBatchToOCProto(batch)
+	if err != nil || jr.config == nil {
+		return td, err
+	}
+	return jr.config.ProcessTags.Apply(td), nil
+}
+
+// protoBatchToOCProto translates the batch and merges its process tags
+// according to the configuration.
+func (jr *jReceiver) protoBatchToOCProto(batch model.Batch) (consumerdata.TraceData, error) {
+	td, err := jaegertranslator.ProtoBatchToOCProto(batch)
+	if err != nil || jr.config == nil {
+		return td, err
+	}
+	return jr.config.ProcessTags.Apply(td), nil
+}
+
 func (jr *jReceiver) SubmitBatches(ctx thrift.Context, batches []*jaeger.Batch) ([]*jaeger.BatchSubmitResponse, error) {
+	if jr.admission != nil {
+		done, ok := jr.admission.Admit(ctx)
+		if !ok {
+			return nil, tchannel.NewSystemError(tchannel.ErrCodeBusy, "too many requests in flight, retry later")
+		}
+		defer done()
+	}
+	var peerIP string
+	if call := tchannel.CurrentCall(ctx); call != nil && jr.peerAddr != nil {
+		// The address of the ephemeral peers is unknown.
+		peerIP = peeraddr.FromHostPort(call.RemotePeer().HostPort)
+	}
+	jbsr, result := jr.submitBatches(ctx, batches, peerIP)
+	result.setTChannelHeaders(ctx)
+	return jbsr, nil
+}
+
+// submitBatches consumes the batches, a batch is reported as not ok if it
+// couldn't be translated or consumed. The result counts the spans accepted and
+// rejected.
+func (jr *jReceiver) submitBatches(ctx thrift.Context, batches []*jaeger.Batch, peerIP string) ([]*jaeger.BatchSubmitResponse, *submitResult) {
 	jbsr := make([]*jaeger.BatchSubmitResponse, 0, len(batches))
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, collectorReceiverTagValue)
+	result := &submitResult{}
 
 	for _, batch := range batches {
-		td, err := jaegertranslator.ThriftBatchToOCProto(batch)
-		// TODO: (@odeke-em) add this error for Jaeger observability
-		ok := false
-
-		if err == nil {
-			ok = true
-			td.SourceFormat = "jaeger"
-			jr.nextConsumer.ConsumeTraceData(ctx, td)
-			// We MUST unconditionally record metrics from this reception.
-			observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(batch.Spans), len(batch.Spans)-len(td.Spans))
+		td, err := jr.thriftBatchToOCProto(batch)
+		if err != nil {
+			result.reject(rejectReasonInvalid, len(batch.Spans))
+			observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(batch.Spans), len(batch.Spans))
+			jbsr = append(jbsr, &jaeger.BatchSubmitResponse{Ok: false})
+			continue
 		}
 
+		td.SourceFormat = "jaeger"
+		td.Node = jr.peerAddr.Node(td.Node, peerIP)
+		err = jr.nextConsumer.ConsumeTraceData(ctx, td)
+		// We MUST unconditionally record metrics from this reception.
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(batch.Spans), len(batch.Spans)-len(td.Spans))
+
+		result.reject(rejectReasonDropped, len(batch.Spans)-len(td.Spans))
+		if err != nil {
+			result.reject(rejectReasonRefused, len(td.Spans))
+		} else {
+			result.accept(len(td.Spans))
+		}
 		jbsr = append(jbsr, &jaeger.BatchSubmitResponse{
-			Ok: ok,
+			Ok: err == nil,
 		})
 	}
-	return jbsr, nil
+	return jbsr, result
 }
 
 var _ reporter.Reporter = (*jReceiver)(nil)
-var _ agentapp.CollectorProxy = (*jReceiver)(nil)
+var _ configmanager.ClientConfigManager = (*jReceiver)(nil)
 var _ api_v2.CollectorServiceServer = (*jReceiver)(nil)
 
 // EmitZipkinBatch implements cmd/agent/reporter.Reporter and it forwards
@@ -290,16 +481,24 @@ func (jr *jReceiver) EmitZipkinBatch(spans []*zipkincore.Span) error {
 // EmitBatch implements cmd/agent/reporter.Reporter and it forwards
 // Jaeger spans received by the Jaeger agent processor.
 func (jr *jReceiver) EmitBatch(batch *jaeger.Batch) error {
-	td, err := jaegertranslator.ThriftBatchToOCProto(batch)
+	_, err := jr.emitBatch(batch)
+	return err
+}
+
+// emitBatch translates and forwards the batch received by the agent, whatever
+// its protocol, and returns the number of dropped spans.
+func (jr *jReceiver) emitBatch(batch *jaeger.Batch) (int, error) {
+	td, err := jr.thriftBatchToOCProto(batch)
 	if err != nil {
 		observability.RecordMetricsForTraceReceiver(jr.defaultAgentCtx, len(batch.Spans), len(batch.Spans))
-		return err
+		return len(batch.Spans), err
 	}
 
 	err = jr.nextConsumer.ConsumeTraceData(jr.defaultAgentCtx, td)
-	observability.RecordMetricsForTraceReceiver(jr.defaultAgentCtx, len(batch.Spans), len(batch.Spans)-len(td.Spans))
+	droppedSpans := len(batch.Spans) - len(td.Spans)
+	observability.RecordMetricsForTraceReceiver(jr.defaultAgentCtx, len(batch.Spans), droppedSpans)
 
-	return err
+	return droppedSpans, err
 }
 
 func (jr *jReceiver) GetReporter() reporter.Reporter {
@@ -310,8 +509,63 @@ func (jr *jReceiver) GetManager() configmanager.ClientConfigManager {
 	return jr
 }
 
+// GetSamplingStrategy returns the strategy of the service loaded from the
+// strategies file, else the one fetched from the upstream, else the sampling
+// probabilities computed by the adaptive-sampling extension for the service,
+// if configured and running, else the default strategy. It serves both the
+// agent-http listener and the SamplingManager service of the gRPC listener.
 func (jr *jReceiver) GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error) {
-	return &sampling.SamplingStrategyResponse{}, nil
+	if jr.staticStrategies != nil {
+		return jr.staticStrategies.GetSamplingStrategy(serviceName)
+	}
+	if jr.upstreamStrategies != nil {
+		// The default strategy is served while the upstream has no strategy
+		// of the service.
+		if resp, err := jr.upstreamStrategies.strategy(context.Background(), serviceName); err == nil {
+			return resp, nil
+		}
+		return jr.defaultSamplingStrategy(), nil
+	}
+	if jr.config == nil {
+		return &sampling.SamplingStrategyResponse{}, nil
+	}
+	var controller *adaptivesampling.Controller
+	if jr.config.SamplingStrategies != "" {
+		controller = adaptivesampling.Lookup(jr.config.SamplingStrategies)
+	}
+	if controller == nil {
+		return jr.defaultSamplingStrategy(), nil
+	}
+
+	defaultProbability, operations := controller.Strategies(serviceName)
+	strategies := make([]*sampling.OperationSamplingStrategy, 0, len(operations))
+	for operation, probability := range operations {
+		strategies = append(strategies, &sampling.OperationSamplingStrategy{
+			Operation:             operation,
+			ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: probability},
+		})
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Operation < strategies[j].Operation
+	})
+	return &sampling.SamplingStrategyResponse{
+		StrategyType:          sampling.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: defaultProbability},
+		OperationSampling: &sampling.PerOperationSamplingStrategies{
+			DefaultSamplingProbability: defaultProbability,
+			PerOperationStrategies:     strategies,
+		},
+	}, nil
+}
+
+func (jr *jReceiver) defaultSamplingStrategy() *sampling.SamplingStrategyResponse {
+	if jr.config.DefaultSamplingRate == 0 {
+		return &sampling.SamplingStrategyResponse{}
+	}
+	return &sampling.SamplingStrategyResponse{
+		StrategyType:          sampling.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: jr.config.DefaultSamplingRate},
+	}
 }
 
 func (jr *jReceiver) GetBaggageRestrictions(serviceName string) ([]*baggage.BaggageRestriction, error) {
@@ -320,111 +574,350 @@ func (jr *jReceiver) GetBaggageRestrictions(serviceName string) ([]*baggage.Bagg
 
 func (jr *jReceiver) PostSpans(ctx context.Context, r *api_v2.PostSpansRequest) (*api_v2.PostSpansResponse, error) {
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, collectorReceiverTagValue)
+	result := &submitResult{}
+	defer result.setGRPCTrailer(ctx)
 
-	td, err := jaegertranslator.ProtoBatchToOCProto(r.Batch)
+	td, err := jr.protoBatchToOCProto(r.Batch)
 	td.SourceFormat = "jaeger"
 	if err != nil {
+		result.reject(rejectReasonInvalid, len(r.Batch.Spans))
 		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(r.Batch.Spans), len(r.Batch.Spans))
 		return nil, err
 	}
 
+	if jr.peerAddr != nil {
+		td.Node = jr.peerAddr.Node(td.Node, peeraddr.FromGRPC(ctx))
+	}
+
 	err = jr.nextConsumer.ConsumeTraceData(ctx, td)
 	observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(r.Batch.Spans), len(r.Batch.Spans)-len(td.Spans))
+	result.reject(rejectReasonDropped, len(r.Batch.Spans)-len(td.Spans))
 	if err != nil {
+		result.reject(rejectReasonRefused, len(td.Spans))
 		return nil, err
 	}
+	result.accept(len(td.Spans))
 
 	return &api_v2.PostSpansResponse{}, err
 }
 
 func (jr *jReceiver) startAgent(_ receiver.Host) error {
-	processorConfigs := []agentapp.ProcessorConfiguration{
-		{
-			// Compact Thrift running by default on 6831.
-			Model:    "jaeger",
-			Protocol: "compact",
-			Server: agentapp.ServerConfiguration{
-				HostPort: jr.agentCompactThriftAddr(),
-			},
-		},
-		{
-			// Binary Thrift running by default on 6832.
-			Model:    "jaeger",
-			Protocol: "binary",
-			Server: agentapp.ServerConfiguration{
-				HostPort: jr.agentBinaryThriftAddr(),
-			},
-		},
+	var udp UDPSettings
+	if jr.config != nil {
+		udp = jr.config.AgentUDP
+	}
+	udp = udp.withDefaults()
+
+	listeners := []struct {
+		name     string
+		addr     string
+		protocol apachethrift.TProtocolFactory
+	}{
+		{protoThriftCompact, jr.agentCompactThriftAddr(), apachethrift.NewTCompactProtocolFactory()},
+		{protoThriftBinary, jr.agentBinaryThriftAddr(), apachethrift.NewTBinaryProtocolFactoryDefault()},
 	}
 
-	builder := agentapp.Builder{
-		Processors: processorConfigs,
-		HTTPServer: agentapp.HTTPServerConfiguration{
-			HostPort: jr.agentAddress(),
-		},
+	var (
+		procs   []processors.Processor
+		sockets []*udpSocket
+	)
+	closeTransports := func() {
+		for _, s := range sockets {
+			s.conn.Close()
+		}
 	}
+	for _, l := range listeners {
+		if l.addr == "" {
+			continue
+		}
+		transport, err := thriftudp.NewTUDPServerTransport(l.addr)
+		if err != nil {
+			closeTransports()
+			return err
+		}
+		sockets = append(sockets, newUDPSocket(l.name, transport.Conn()))
+		if udp.SocketBufferSize > 0 {
+			if err := transport.Conn().SetReadBuffer(udp.SocketBufferSize); err != nil {
+				closeTransports()
+				return err
+			}
+		}
 
-	agent, err := builder.CreateAgent(jr, zap.NewNop(), metrics.NullFactory)
-	if err != nil {
-		return err
+		mFactory := newUDPMetricsFactory(l.name)
+		server, err := servers.NewTBufferedServer(transport, udp.QueueSize, udp.MaxPacketSize, mFactory)
+		if err != nil {
+			closeTransports()
+			return err
+		}
+		proc, err := processors.NewThriftProcessor(server, udp.Workers, mFactory, l.protocol, jaeger.NewAgentProcessor(newUDPReporter(l.name, jr)), zap.NewNop())
+		if err != nil {
+			closeTransports()
+			return err
+		}
+		procs = append(procs, proc)
 	}
 
-	if err := agent.Run(); err != nil {
-		return err
+	if aaddr := jr.agentAddress(); aaddr != "" {
+		aln, err := net.Listen("tcp", aaddr)
+		if err != nil {
+			closeTransports()
+			return fmt.Errorf("failed to bind to agent address %q: %v", aaddr, err)
+		}
+		server := httpserver.NewHTTPServer(aaddr, jr, metrics.NullFactory)
+		if path := jr.agentHTTPSpansPath(); path != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/", server.Handler)
+			mux.Handle(path, jr.agentHTTPSpansHandler())
+			server.Handler = mux
+		}
+		go func() {
+			_ = server.Serve(aln)
+		}()
+		jr.agentServer = server
 	}
 
-	// Otherwise no error was encountered,
-	jr.agent = agent
+	for _, proc := range procs {
+		go proc.Serve()
+	}
+	jr.agentProcessors = procs
+
+	if len(sockets) > 0 {
+		jr.udpDone = make(chan struct{})
+		jr.udpWG.Add(1)
+		go reportUDPDrops(sockets, jr.udpDone, &jr.udpWG)
+	}
 
 	return nil
 }
 
-func (jr *jReceiver) startCollector(host receiver.Host) error {
-	tch, terr := tchannel.NewChannel("jaeger-collector", new(tchannel.ChannelOptions))
-	if terr != nil {
-		return fmt.Errorf("failed to create NewTChannel: %v", terr)
+// acceptedThriftFormats are the content types of the thrift batches accepted
+// by the collector HTTP endpoint.
+var acceptedThriftFormats = map[string]bool{
+	"application/x-thrift":                 true,
+	"application/vnd.apache.thrift.binary": true,
+}
+
+// readThriftBatch reads the thrift batch of the request, decompressing its
+// body within the limits of the receiver, it writes the error to the response
+// and returns nil if the request is invalid. The failures to decompress are
+// recorded for the receiver of ctx. The batch is decoded with a protocol
+// bounding the sizes of its lists to the size of the body, so that a small
+// malformed request can't allocate huge slices.
+func (jr *jReceiver) readThriftBatch(ctx context.Context, w http.ResponseWriter, r *http.Request) *jaeger.Batch {
+	body, err := decompression.ReadAll(r, jr.decompression)
+	r.Body.Close()
+	if err != nil {
+		if decompression.IsCompressed(r) {
+			observability.RecordDecompressionFailure(ctx)
+		}
+		http.Error(w, fmt.Sprintf("Unable to process request body: %v", err), decompression.StatusCode(err))
+		return nil
 	}
 
-	server := thrift.NewServer(tch)
-	server.Register(jaeger.NewTChanCollectorServer(jr))
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot parse content type: %v", err), http.StatusBadRequest)
+		return nil
+	}
+	if !acceptedThriftFormats[contentType] {
+		http.Error(w, fmt.Sprintf("Unsupported content type: %v", contentType), http.StatusBadRequest)
+		return nil
+	}
 
-	taddr := jr.tchannelAddr()
-	tln, terr := net.Listen("tcp", taddr)
-	if terr != nil {
-		return fmt.Errorf("failed to bind to TChannel address %q: %v", taddr, terr)
+	batch := &jaeger.Batch{}
+	if err := thriftutil.Deserialize(batch, body); err != nil {
+		http.Error(w, fmt.Sprintf("Unable to process request body: %v", err), http.StatusBadRequest)
+		return nil
 	}
-	tch.Serve(tln)
-	jr.tchannel = tch
+	return batch
+}
 
-	// Now the collector that runs over HTTP
-	caddr := jr.collectorAddr()
-	cln, cerr := net.Listen("tcp", caddr)
-	if cerr != nil {
-		// Abort and close tch
-		tch.Close()
-		return fmt.Errorf("failed to bind to Collector address %q: %v", caddr, cerr)
+// saveBatch mirrors the API handler of the Jaeger collector.
+func (jr *jReceiver) saveBatch(w http.ResponseWriter, r *http.Request) {
+	batch := jr.readThriftBatch(observability.ContextWithReceiverName(r.Context(), collectorReceiverTagValue), w, r)
+	if batch == nil {
+		return
+	}
+
+	var peerIP string
+	if jr.peerAddr != nil {
+		peerIP = peeraddr.FromHTTP(r)
+	}
+	ctx, cancel := thrift.NewContext(time.Minute)
+	defer cancel()
+	_, result := jr.submitBatches(ctx, []*jaeger.Batch{batch}, peerIP)
+	result.setHTTPHeaders(w.Header())
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// emitHTTPBatch serves the thrift batches POSTed to the agent-http listener
+// by the SDKs, like the batches of the UDP listeners of the agent.
+func (jr *jReceiver) emitHTTPBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
+	batch := jr.readThriftBatch(jr.defaultAgentCtx, w, r)
+	if batch == nil {
+		return
+	}
+	if _, err := jr.emitBatch(batch); err != nil {
+		http.Error(w, fmt.Sprintf("Cannot submit Jaeger batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
 
+func (jr *jReceiver) collectorHTTPHandler() http.Handler {
 	nr := mux.NewRouter()
-	apiHandler := app.NewAPIHandler(jr)
-	apiHandler.RegisterRoutes(nr)
-	jr.collectorServer = &http.Server{Handler: nr}
-	go func() {
-		_ = jr.collectorServer.Serve(cln)
-	}()
+	nr.HandleFunc("/api/traces", jr.saveBatch).Methods(http.MethodPost)
+	var handler http.Handler = nr
+	if jr.admission != nil {
+		handler = jr.admission.HTTPHandler(handler)
+	}
+	// The requests are authenticated before being admitted, so that the
+	// rejected requests don't take the place of the others.
+	if jr.authenticator != nil {
+		handler = jr.authenticator.HTTPHandler(handler)
+	}
+	return handler
+}
+
+// agentHTTPSpansHandler serves the thrift batches of the agent-http listener
+// with the same restrictions as the collector listeners. The sampling
+// endpoints of the listener are left open to the SDKs fetching their strategy.
+func (jr *jReceiver) agentHTTPSpansHandler() http.Handler {
+	var handler http.Handler = http.HandlerFunc(jr.emitHTTPBatch)
+	if jr.admission != nil {
+		handler = jr.admission.HTTPHandler(handler)
+	}
+	if jr.authenticator != nil {
+		handler = jr.authenticator.HTTPHandler(handler)
+	}
+	// The agent-http listener is created by the agent server, the clients
+	// are filtered per request instead of per connection.
+	return jr.acl.HTTPHandler(handler)
+}
+
+// samplingManagerMethodPrefix is the prefix of the methods of the
+// SamplingManager service, which are not authenticated since the SDKs
+// fetching their strategy don't have the tokens of the spans exporters.
+const samplingManagerMethodPrefix = "/jaeger.api_v2.SamplingManager/"
+
+// unaryServerInterceptor authenticates and then admits the gRPC requests of
+// the collector service, it returns nil if there is nothing to intercept.
+func (jr *jReceiver) unaryServerInterceptor() grpc.UnaryServerInterceptor {
+	var authenticate, admit grpc.UnaryServerInterceptor
+	if jr.authenticator != nil {
+		authenticate = jr.authenticator.UnaryServerInterceptor()
+	}
+	if jr.admission != nil {
+		admit = jr.admission.UnaryServerInterceptor()
+	}
+	if authenticate == nil && admit == nil {
+		return nil
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, samplingManagerMethodPrefix) {
+			return handler(ctx, req)
+		}
+		if admit != nil {
+			next := handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return admit(ctx, req, info, next)
+			}
+		}
+		if authenticate != nil {
+			return authenticate(ctx, req, info, handler)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (jr *jReceiver) startCollector(host receiver.Host) error {
+	if taddr := jr.tchannelAddr(); taddr != "" {
+		tch, terr := tchannel.NewChannel("jaeger-collector", new(tchannel.ChannelOptions))
+		if terr != nil {
+			return fmt.Errorf("failed to create NewTChannel: %v", terr)
+		}
+
+		server := thrift.NewServer(tch)
+		server.Register(jaeger.NewTChanCollectorServer(jr))
+
+		tln, terr := net.Listen("tcp", taddr)
+		if terr != nil {
+			return fmt.Errorf("failed to bind to TChannel address %q: %v", taddr, terr)
+		}
+		tln = jr.acl.Listener(tln)
+		tch.Serve(tln)
+		jr.tchannel = tch
+	}
+
+	// Now the collector that runs over HTTP
+	var cln net.Listener
+	if caddr := jr.collectorAddr(); caddr != "" {
+		tlsCfg, err := jr.config.CollectorHTTPTLS.ServerConfig()
+		if err != nil {
+			jr.closeCollectorListeners(nil)
+			return fmt.Errorf("failed to load the TLS credentials of the Collector: %v", err)
+		}
+		var cerr error
+		cln, cerr = net.Listen("tcp", caddr)
+		if cerr != nil {
+			// Abort and close tch
+			jr.closeCollectorListeners(nil)
+			return fmt.Errorf("failed to bind to Collector address %q: %v", caddr, cerr)
+		}
+		cln = jr.acl.Listener(cln)
+		if tlsCfg != nil {
+			cln = tls.NewListener(cln, tlsCfg)
+		}
+
+		jr.collectorServer = &http.Server{Handler: jr.collectorHTTPHandler()}
+		go func(server *http.Server) {
+			_ = server.Serve(cln)
+		}(jr.collectorServer)
+	}
 
 	// And finally, the gRPC server
-	jr.grpc = grpc.NewServer()
 	gaddr := jr.grpcAddr()
+	if gaddr == "" {
+		return nil
+	}
+	relay := jr.relayConsumer() != nil
+	grpcOpts := jr.config.GRPCServer.serverOptions()
+	if interceptor := jr.unaryServerInterceptor(); interceptor != nil {
+		grpcOpts = append(grpcOpts, grpc.UnaryInterceptor(interceptor))
+	}
+	if relay {
+		grpcOpts = append(grpcOpts, grpc.CustomCodec(jaegerrelay.Codec{}))
+	}
+	tlsCfg, err := jr.config.CollectorGRPCTLS.ServerConfig()
+	if err != nil {
+		jr.closeCollectorListeners(cln)
+		return fmt.Errorf("failed to load the TLS credentials of the gRPC collector: %v", err)
+	}
+	if tlsCfg != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
 	gln, gerr := net.Listen("tcp", gaddr)
 	if gerr != nil {
 		// Abort and close tch, cln
-		tch.Close()
-		cln.Close()
+		jr.closeCollectorListeners(cln)
 		return fmt.Errorf("failed to bind to gRPC address %q: %v", gaddr, gerr)
 	}
+	gln = jr.acl.Listener(gln)
 
-	api_v2.RegisterCollectorServiceServer(jr.grpc, jr)
+	jr.grpc = grpc.NewServer(grpcOpts...)
+	if relay {
+		jr.grpc.RegisterService(&relayServiceDesc, jr)
+	} else {
+		api_v2.RegisterCollectorServiceServer(jr.grpc, jr)
+	}
+	// jReceiver is the strategy store of the remote sampling of the SDKs
+	// using gRPC.
+	api_v2.RegisterSamplingManagerServer(jr.grpc, collectorSampling.NewGRPCHandler(jr))
 
 	go func() {
 		if err := jr.grpc.Serve(gln); err != nil {
@@ -434,3 +927,16 @@ func (jr *jReceiver) startCollector(host receiver.Host) error {
 
 	return nil
 }
+
+// closeCollectorListeners releases the collector listeners that were already
+// started when a later one fails to start.
+func (jr *jReceiver) closeCollectorListeners(cln net.Listener) {
+	if jr.tchannel != nil {
+		jr.tchannel.Close()
+		jr.tchannel = nil
+	}
+	if cln != nil {
+		cln.Close()
+		jr.collectorServer = nil
+	}
+}
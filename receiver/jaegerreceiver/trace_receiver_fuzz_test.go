@@ -0,0 +1,71 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package jaegerreceiver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+// FuzzCollectorHTTP posts the input as a Thrift batch to the collector HTTP
+// endpoint. The request must not panic, and must either be accepted or
+// rejected as a bad request.
+func FuzzCollectorHTTP(f *testing.F) {
+	batches := []*jaeger.Batch{
+		{
+			Process: jaeger.NewProcess(),
+			Spans:   []*jaeger.Span{{TraceIdLow: 1, SpanId: 1, OperationName: "a"}},
+		},
+		{
+			Process: &jaeger.Process{
+				ServiceName: "api",
+				Tags:        []*jaeger.Tag{{Key: "hostname", VType: jaeger.TagType_STRING}},
+			},
+			Spans: []*jaeger.Span{
+				{TraceIdLow: 1, SpanId: 1, OperationName: "a"},
+				{TraceIdLow: 1, SpanId: 2, ParentSpanId: 1, OperationName: "b", Duration: 10},
+			},
+		},
+	}
+	for _, batch := range batches {
+		seed, err := thrift.NewTSerializer().Write(batch)
+		if err != nil {
+			f.Fatalf("Failed to serialize Jaeger Thrift: %v", err)
+		}
+		f.Add(seed)
+	}
+
+	jr := &jReceiver{nextConsumer: new(exportertest.SinkTraceExporter)}
+	handler := jr.collectorHTTPHandler()
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-thrift")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted && rec.Code != http.StatusBadRequest {
+			t.Fatalf("Unexpected status %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
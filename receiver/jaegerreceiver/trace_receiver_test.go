@@ -17,7 +17,13 @@ package jaegerreceiver
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,22 +33,34 @@ import (
 	"github.com/google/go-cmp/cmp"
 	model "github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
 func TestReception(t *testing.T) {
 	// 1. Create the Jaeger receiver aka "server"
 	config := &Configuration{
 		CollectorHTTPPort: 14268, // that's the only one used by this test
+		// The agent stops asynchronously, bind it to free ports so the tests
+		// don't collide on the default ones.
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
 	}
 	sink := new(exportertest.SinkTraceExporter)
 
@@ -94,6 +112,11 @@ func TestGRPCReception(t *testing.T) {
 	// prepare
 	config := &Configuration{
 		CollectorGRPCPort: 14250, // that's the only one used by this test
+		// The agent stops asynchronously, bind it to free ports so the tests
+		// don't collide on the default ones.
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
 	}
 	sink := new(exportertest.SinkTraceExporter)
 
@@ -302,3 +325,388 @@ func grpcFixture(t1 time.Time, d1, d2 time.Duration) *api_v2.PostSpansRequest {
 		},
 	}
 }
+
+func TestGRPCReception_ProcessTags(t *testing.T) {
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalAddress(t),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+		ProcessTags: jaegertranslator.ProcessTagsMapping{
+			CopyToSpans: true,
+			Prefix:      "process.",
+		},
+	}
+	sink := new(exportertest.SinkTraceExporter)
+
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	cl := api_v2.NewCollectorServiceClient(conn)
+	_, err = cl.PostSpans(context.Background(), req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, len(req.Batch.Spans))
+	for _, span := range got[0].Spans {
+		attrs := span.GetAttributes().GetAttributeMap()
+		assert.Equal(t, "yes", attrs["process.string"].GetStringValue().GetValue())
+		assert.Equal(t, "10000000", attrs["process.int64"].GetStringValue().GetValue())
+	}
+	assert.Equal(t, "yes", got[0].Node.Attributes["string"])
+}
+
+func TestReception_PeerAddress(t *testing.T) {
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalAddress(t),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+		PeerAddress:                peeraddr.Settings{Enabled: true},
+	}
+	sink := new(exportertest.SinkTraceExporter)
+
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	now := time.Unix(1542158650, 536343000).UTC()
+	_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(context.Background(),
+		grpcFixture(now, 10*time.Minute, 2*time.Second), grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	jexp, err := jaeger.NewExporter(jaeger.Options{
+		Process:           jaeger.Process{ServiceName: "issaTest"},
+		CollectorEndpoint: fmt.Sprintf("http://%s/api/traces", config.CollectorHTTPEndpoint),
+	})
+	require.NoError(t, err)
+	for _, sd := range traceFixture(now, now.Add(time.Minute), now.Add(2*time.Minute)) {
+		jexp.ExportSpan(sd)
+	}
+	jexp.Flush()
+
+	got := sink.AllTraces()
+	require.Len(t, got, 2)
+	for _, td := range got {
+		assert.Equal(t, "issaTest", td.Node.ServiceInfo.Name)
+		ip := net.ParseIP(td.Node.Attributes[peeraddr.IPAttribute])
+		assert.True(t, ip != nil && ip.IsLoopback(), "unexpected peer address %v", ip)
+	}
+}
+
+func TestSamplingStrategies(t *testing.T) {
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalAddress(t),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+		SamplingStrategies:         "adaptive-sampling/test",
+	}
+	sink := new(exportertest.SinkTraceExporter)
+
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	// Without the extension the SDKs get the default strategy.
+	strategy, err := jr.(*jReceiver).GetSamplingStrategy("frontend")
+	require.NoError(t, err)
+	assert.Equal(t, &sampling.SamplingStrategyResponse{}, strategy)
+
+	controller := adaptivesampling.NewController(1, 0.01, 0.5)
+	adaptivesampling.Register("adaptive-sampling/test", controller)
+	defer adaptivesampling.Unregister("adaptive-sampling/test")
+	controller.Record("frontend", "GET /", 1000)
+	controller.Adjust(time.Now().Add(time.Second))
+
+	resp, err := http.Get("http://" + config.AgentEndpoint + "/sampling?service=frontend")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	strategy = &sampling.SamplingStrategyResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(strategy))
+	assert.Equal(t, sampling.SamplingStrategyType_PROBABILISTIC, strategy.StrategyType)
+	assert.Equal(t, 0.5, strategy.ProbabilisticSampling.SamplingRate)
+	require.NotNil(t, strategy.OperationSampling)
+	assert.Equal(t, 0.5, strategy.OperationSampling.DefaultSamplingProbability)
+	require.Len(t, strategy.OperationSampling.PerOperationStrategies, 1)
+	assert.Equal(t, "GET /", strategy.OperationSampling.PerOperationStrategies[0].Operation)
+	assert.True(t, strategy.OperationSampling.PerOperationStrategies[0].ProbabilisticSampling.SamplingRate < 0.5)
+}
+
+func TestSamplingStrategiesFile(t *testing.T) {
+	config := &Configuration{
+		AgentEndpoint:          testutils.GetAvailableLocalAddress(t),
+		SamplingStrategiesFile: path.Join(".", "testdata", "strategies.json"),
+	}
+	sink := new(exportertest.SinkTraceExporter)
+
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	getStrategy := func(service string) *sampling.SamplingStrategyResponse {
+		resp, err := http.Get("http://" + config.AgentEndpoint + "/sampling?service=" + service)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		strategy := &sampling.SamplingStrategyResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(strategy))
+		return strategy
+	}
+
+	strategy := getStrategy("frontend")
+	assert.Equal(t, sampling.SamplingStrategyType_PROBABILISTIC, strategy.StrategyType)
+	assert.Equal(t, 0.8, strategy.ProbabilisticSampling.SamplingRate)
+	require.NotNil(t, strategy.OperationSampling)
+	require.Len(t, strategy.OperationSampling.PerOperationStrategies, 1)
+	assert.Equal(t, "GET /health", strategy.OperationSampling.PerOperationStrategies[0].Operation)
+	assert.Equal(t, 0.01, strategy.OperationSampling.PerOperationStrategies[0].ProbabilisticSampling.SamplingRate)
+
+	strategy = getStrategy("billing")
+	assert.Equal(t, sampling.SamplingStrategyType_RATE_LIMITING, strategy.StrategyType)
+	assert.EqualValues(t, 5, strategy.RateLimitingSampling.MaxTracesPerSecond)
+
+	// The services without strategy get the default one of the file.
+	strategy = getStrategy("unknown")
+	assert.Equal(t, sampling.SamplingStrategyType_PROBABILISTIC, strategy.StrategyType)
+	assert.Equal(t, 0.5, strategy.ProbabilisticSampling.SamplingRate)
+}
+
+func TestUpstreamSampling(t *testing.T) {
+	var requests, failing int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/sampling" || r.URL.Query().Get("service") != "frontend" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.3}}`)
+	}))
+	defer upstream.Close()
+
+	config := &Configuration{
+		AgentEndpoint: testutils.GetAvailableLocalAddress(t),
+		UpstreamSampling: UpstreamSamplingSettings{
+			Endpoint:        upstream.URL + "/sampling",
+			RefreshInterval: time.Minute,
+			MaxStaleness:    time.Hour,
+		},
+		DefaultSamplingRate: 0.1,
+	}
+	sink := new(exportertest.SinkTraceExporter)
+
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	now := time.Now()
+	jr.(*jReceiver).upstreamStrategies.now = func() time.Time { return now }
+
+	getStrategy := func(service string) *sampling.SamplingStrategyResponse {
+		resp, err := http.Get("http://" + config.AgentEndpoint + "/sampling?service=" + service)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		strategy := &sampling.SamplingStrategyResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(strategy))
+		return strategy
+	}
+
+	strategy := getStrategy("frontend")
+	assert.Equal(t, sampling.SamplingStrategyType_PROBABILISTIC, strategy.StrategyType)
+	assert.Equal(t, 0.3, strategy.ProbabilisticSampling.SamplingRate)
+
+	// The strategy is cached until the refresh interval elapses.
+	getStrategy("frontend")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+
+	// The services unknown to the upstream get the default strategy.
+	strategy = getStrategy("unknown")
+	assert.Equal(t, 0.1, strategy.ProbabilisticSampling.SamplingRate)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	// The cached strategy is served while the upstream fails, until it is
+	// older than the max staleness.
+	atomic.StoreInt32(&failing, 1)
+	now = now.Add(2 * time.Minute)
+	strategy = getStrategy("frontend")
+	assert.Equal(t, 0.3, strategy.ProbabilisticSampling.SamplingRate)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+
+	now = now.Add(2 * time.Hour)
+	strategy = getStrategy("frontend")
+	assert.Equal(t, 0.1, strategy.ProbabilisticSampling.SamplingRate)
+	assert.EqualValues(t, 4, atomic.LoadInt32(&requests))
+}
+
+func TestGRPCSamplingManager(t *testing.T) {
+	config := &Configuration{
+		CollectorGRPCEndpoint:  testutils.GetAvailableLocalAddress(t),
+		SamplingStrategiesFile: path.Join(".", "testdata", "strategies.json"),
+	}
+	sink := new(exportertest.SinkTraceExporter)
+
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(config.CollectorGRPCEndpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := api_v2.NewSamplingManagerClient(conn)
+
+	strategy, err := client.GetSamplingStrategy(context.Background(), &api_v2.SamplingStrategyParameters{ServiceName: "billing"})
+	require.NoError(t, err)
+	assert.Equal(t, api_v2.SamplingStrategyType_RATE_LIMITING, strategy.StrategyType)
+	assert.EqualValues(t, 5, strategy.RateLimitingSampling.MaxTracesPerSecond)
+
+	strategy, err = client.GetSamplingStrategy(context.Background(), &api_v2.SamplingStrategyParameters{ServiceName: "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, api_v2.SamplingStrategyType_PROBABILISTIC, strategy.StrategyType)
+	assert.Equal(t, 0.5, strategy.ProbabilisticSampling.SamplingRate)
+}
+
+func TestDefaultSamplingRate(t *testing.T) {
+	jr := &jReceiver{config: &Configuration{}}
+	strategy, err := jr.GetSamplingStrategy("frontend")
+	require.NoError(t, err)
+	assert.Equal(t, &sampling.SamplingStrategyResponse{}, strategy)
+
+	// The default strategy also applies while the adaptive-sampling extension
+	// is not running.
+	jr.config = &Configuration{SamplingStrategies: "adaptive-sampling", DefaultSamplingRate: 0.25}
+	strategy, err = jr.GetSamplingStrategy("frontend")
+	require.NoError(t, err)
+	assert.Equal(t, sampling.SamplingStrategyType_PROBABILISTIC, strategy.StrategyType)
+	assert.Equal(t, 0.25, strategy.ProbabilisticSampling.SamplingRate)
+}
+
+func TestGRPCReception_IPv6(t *testing.T) {
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalIPv6Address(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalIPv6Address(t),
+		CollectorGRPCEndpoint:      testutils.GetAvailableLocalIPv6Address(t),
+		AgentEndpoint:              testutils.GetAvailableLocalIPv6Address(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalIPv6Address(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalIPv6Address(t),
+	}
+	postSpansTo(t, config, config.CollectorGRPCEndpoint)
+}
+
+func TestGRPCReception_DualStack(t *testing.T) {
+	_, port, err := net.SplitHostPort(testutils.GetAvailableLocalIPv6Address(t))
+	require.NoError(t, err)
+
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      net.JoinHostPort("::", port),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+	}
+	// A listener on the IPv6 unspecified address also accepts IPv4 clients.
+	postSpansTo(t, config, net.JoinHostPort("::1", port), net.JoinHostPort("127.0.0.1", port))
+}
+
+func TestGRPCReception_BindHost(t *testing.T) {
+	_, port, err := net.SplitHostPort(testutils.GetAvailableLocalAddress(t))
+	require.NoError(t, err)
+
+	config := &Configuration{
+		CollectorThriftEndpoint:    testutils.GetAvailableLocalAddress(t),
+		CollectorHTTPEndpoint:      testutils.GetAvailableLocalAddress(t),
+		CollectorGRPCEndpoint:      net.JoinHostPort("127.0.0.1", port),
+		AgentEndpoint:              testutils.GetAvailableLocalAddress(t),
+		AgentCompactThriftEndpoint: testutils.GetAvailableLocalAddress(t),
+		AgentBinaryThriftEndpoint:  testutils.GetAvailableLocalAddress(t),
+	}
+	jr, err := New(context.Background(), config, exportertest.NewNopTraceExporter())
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	// The listener only accepts the connections to the host of its endpoint.
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", port), time.Second)
+	require.NoError(t, err)
+	conn.Close()
+	_, err = net.DialTimeout("tcp", net.JoinHostPort("127.0.0.2", port), time.Second)
+	assert.Error(t, err)
+}
+
+func TestGRPCReception_MaxRecvMsgSize(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	config := &Configuration{
+		CollectorGRPCEndpoint: addr,
+		GRPCServer:            GRPCServerSettings{MaxRecvMsgSizeMiB: 1},
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	cl := api_v2.NewCollectorServiceClient(conn)
+
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	_, err = cl.PostSpans(context.Background(), req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	// The batches larger than 1MiB are rejected.
+	req.Batch.Spans[0].OperationName = string(make([]byte, 2*1024*1024))
+	_, err = cl.PostSpans(context.Background(), req, grpc.WaitForReady(true))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+// postSpansTo starts a receiver with the given configuration and posts the
+// gRPC fixture to each of the given addresses.
+func postSpansTo(t *testing.T, config *Configuration, addrs ...string) {
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), config, sink)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	req := grpcFixture(time.Unix(1542158650, 536343000).UTC(), 10*time.Minute, 2*time.Second)
+	for _, addr := range addrs {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		require.NoError(t, err)
+		cl := api_v2.NewCollectorServiceClient(conn)
+		_, err = cl.PostSpans(context.Background(), req, grpc.WaitForReady(true))
+		conn.Close()
+		require.NoError(t, err, "failed to post spans to %s", addr)
+	}
+	assert.Len(t, sink.AllTraces(), len(addrs))
+}
@@ -0,0 +1,189 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+const (
+	defaultUDPQueueSize     = 1000
+	defaultUDPMaxPacketSize = 65000
+	defaultUDPWorkers       = 10
+
+	// udpDropsInterval is the interval at which the drops of the sockets
+	// are read from the OS.
+	udpDropsInterval = 10 * time.Second
+)
+
+// Reasons of the UDP packet drops.
+const (
+	udpDropQueueFull    = "queue_full"
+	udpDropSocketBuffer = "socket_buffer"
+)
+
+// UDPSettings tunes the UDP listeners of the agent.
+type UDPSettings struct {
+	// QueueSize is the number of packets read from each listener and waiting
+	// to be processed above which the packets are dropped.
+	QueueSize int `mapstructure:"queue-size"`
+
+	// MaxPacketSize is the size in bytes of the largest packet, the larger
+	// packets are truncated and fail to be decoded.
+	MaxPacketSize int `mapstructure:"max-packet-size"`
+
+	// Workers is the number of goroutines processing the packets of each
+	// listener.
+	Workers int `mapstructure:"workers"`
+
+	// SocketBufferSize is the size in bytes of the receive buffer of the
+	// sockets. The OS default is used if 0, the OS may cap the size, e.g.
+	// net.core.rmem_max on Linux.
+	SocketBufferSize int `mapstructure:"socket-buffer-size"`
+}
+
+// withDefaults returns the settings with the defaults applied to the unset
+// ones.
+func (s UDPSettings) withDefaults() UDPSettings {
+	if s.QueueSize <= 0 {
+		s.QueueSize = defaultUDPQueueSize
+	}
+	if s.MaxPacketSize <= 0 {
+		s.MaxPacketSize = defaultUDPMaxPacketSize
+	}
+	if s.Workers <= 0 {
+		s.Workers = defaultUDPWorkers
+	}
+	return s
+}
+
+// udpMetricsFactory records the packets dropped by the queue of a UDP
+// listener of the agent, the other metrics of the agent are discarded.
+type udpMetricsFactory struct {
+	metrics.Factory
+	ctx context.Context
+}
+
+func newUDPMetricsFactory(listener string) *udpMetricsFactory {
+	ctx, _ := tag.New(context.Background(),
+		tag.Upsert(tagUDPListenerKey, listener),
+		tag.Upsert(tagUDPDropReasonKey, udpDropQueueFull))
+	return &udpMetricsFactory{Factory: metrics.NullFactory, ctx: ctx}
+}
+
+func (f *udpMetricsFactory) Counter(options metrics.Options) metrics.Counter {
+	if options.Name == "thrift.udp.server.packets.dropped" {
+		return udpDropCounter{ctx: f.ctx}
+	}
+	return f.Factory.Counter(options)
+}
+
+func (f *udpMetricsFactory) Namespace(scope metrics.NSOptions) metrics.Factory {
+	return f
+}
+
+type udpDropCounter struct {
+	ctx context.Context
+}
+
+func (c udpDropCounter) Inc(delta int64) {
+	stats.Record(c.ctx, statUDPDroppedPackets.M(delta))
+}
+
+// udpReporter forwards the batches received by a UDP listener of the agent
+// and records its received and dropped spans.
+type udpReporter struct {
+	ctx context.Context
+	jr  *jReceiver
+}
+
+func newUDPReporter(listener string, jr *jReceiver) *udpReporter {
+	ctx, _ := tag.New(context.Background(), tag.Upsert(tagUDPListenerKey, listener))
+	return &udpReporter{ctx: ctx, jr: jr}
+}
+
+// EmitZipkinBatch implements cmd/agent/reporter.Reporter, the Zipkin spans
+// are ignored like by the receiver.
+func (r *udpReporter) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	return r.jr.EmitZipkinBatch(spans)
+}
+
+// EmitBatch implements cmd/agent/reporter.Reporter.
+func (r *udpReporter) EmitBatch(batch *jaeger.Batch) error {
+	droppedSpans, err := r.jr.emitBatch(batch)
+	stats.Record(r.ctx,
+		statUDPReceivedSpans.M(int64(len(batch.Spans))),
+		statUDPDroppedSpans.M(int64(droppedSpans)))
+	return err
+}
+
+var _ reporter.Reporter = (*udpReporter)(nil)
+
+// udpSocket is a socket of a UDP listener whose drops are read from the OS.
+type udpSocket struct {
+	ctx   context.Context
+	conn  *net.UDPConn
+	drops uint64
+}
+
+func newUDPSocket(listener string, conn *net.UDPConn) *udpSocket {
+	ctx, _ := tag.New(context.Background(),
+		tag.Upsert(tagUDPListenerKey, listener),
+		tag.Upsert(tagUDPDropReasonKey, udpDropSocketBuffer))
+	s := &udpSocket{ctx: ctx, conn: conn}
+	// The drops counted before the start, if any, are not the receiver's.
+	s.drops, _ = socketDrops(conn)
+	return s
+}
+
+// report records the packets dropped by the OS since the previous call.
+func (s *udpSocket) report() {
+	drops, ok := socketDrops(s.conn)
+	if !ok {
+		return
+	}
+	if drops > s.drops {
+		stats.Record(s.ctx, statUDPDroppedPackets.M(int64(drops-s.drops)))
+	}
+	s.drops = drops
+}
+
+// reportUDPDrops records the packets dropped by the OS for the sockets until
+// done is closed.
+func reportUDPDrops(sockets []*udpSocket, done <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(udpDropsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, s := range sockets {
+				s.report()
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// socketDrops returns the number of packets dropped by the OS for the socket,
+// read from /proc/net/udp or /proc/net/udp6, and false if it can't be found.
+func socketDrops(conn *net.UDPConn) (uint64, bool) {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+	var st syscall.Stat_t
+	var statErr error
+	if err := rc.Control(func(fd uintptr) {
+		statErr = syscall.Fstat(int(fd), &st)
+	}); err != nil || statErr != nil {
+		return 0, false
+	}
+	inode := strconv.FormatUint(st.Ino, 10)
+
+	for _, path := range []string{"/proc/net/udp", "/proc/net/udp6"} {
+		if drops, ok := procNetUDPDrops(path, inode); ok {
+			return drops, true
+		}
+	}
+	return 0, false
+}
+
+// procNetUDPDrops returns the drops of the socket with the given inode in the
+// file at path, in the format of /proc/net/udp.
+func procNetUDPDrops(path, inode string) (uint64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// Skip the header.
+	scanner.Scan()
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when
+		// retrnsmt uid timeout inode ref pointer drops
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 || fields[9] != inode {
+			continue
+		}
+		drops, err := strconv.ParseUint(fields[12], 10, 64)
+		return drops, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,138 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+func TestUDPSettings_WithDefaults(t *testing.T) {
+	assert.Equal(t, UDPSettings{
+		QueueSize:     defaultUDPQueueSize,
+		MaxPacketSize: defaultUDPMaxPacketSize,
+		Workers:       defaultUDPWorkers,
+	}, UDPSettings{}.withDefaults())
+
+	s := UDPSettings{QueueSize: 1, MaxPacketSize: 2, Workers: 3, SocketBufferSize: 4}
+	assert.Equal(t, s, s.withDefaults())
+}
+
+func TestProcNetUDPDrops(t *testing.T) {
+	file := path.Join(".", "testdata", "proc_net_udp")
+
+	drops, ok := procNetUDPDrops(file, "41235")
+	assert.True(t, ok)
+	assert.EqualValues(t, 1742, drops)
+
+	drops, ok = procNetUDPDrops(file, "41234")
+	assert.True(t, ok)
+	assert.EqualValues(t, 0, drops)
+
+	_, ok = procNetUDPDrops(file, "1")
+	assert.False(t, ok)
+
+	_, ok = procNetUDPDrops(path.Join(".", "testdata", "missing"), "41234")
+	assert.False(t, ok)
+}
+
+func TestSocketDrops(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the drops of the sockets are only read on Linux")
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	drops, ok := socketDrops(conn)
+	assert.True(t, ok)
+	assert.EqualValues(t, 0, drops)
+}
+
+func TestUDPMetricsFactory(t *testing.T) {
+	views := MetricViews(telemetry.Normal)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	factory := newUDPMetricsFactory(protoThriftCompact).Namespace(metrics.NSOptions{
+		Tags: map[string]string{"protocol": "compact"},
+	})
+	factory.Counter(metrics.Options{Name: "thrift.udp.server.packets.dropped"}).Inc(3)
+	factory.Counter(metrics.Options{Name: "thrift.udp.server.packets.processed"}).Inc(5)
+
+	rows, err := view.RetrieveData(statUDPDroppedPackets.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 3.0, rows[0].Data.(*view.SumData).Value)
+	assert.Contains(t, rows[0].Tags, tag.Tag{Key: tagUDPListenerKey, Value: protoThriftCompact})
+	assert.Contains(t, rows[0].Tags, tag.Tag{Key: tagUDPDropReasonKey, Value: udpDropQueueFull})
+}
+
+func TestUDPReporter(t *testing.T) {
+	views := MetricViews(telemetry.Normal)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	sink := new(exportertest.SinkTraceExporter)
+	jr, err := New(context.Background(), &Configuration{}, sink)
+	require.NoError(t, err)
+
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans: []*jaeger.Span{
+			{TraceIdLow: 1, SpanId: 1, OperationName: "a"},
+			{TraceIdLow: 1, SpanId: 2, OperationName: "b"},
+		},
+	}
+	require.NoError(t, newUDPReporter(protoThriftCompact, jr.(*jReceiver)).EmitBatch(batch))
+	require.Len(t, sink.AllTraces(), 1)
+	assert.Len(t, sink.AllTraces()[0].Spans, 2)
+
+	failing := exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("failed")))
+	jr, err = New(context.Background(), &Configuration{}, failing)
+	require.NoError(t, err)
+	require.Error(t, newUDPReporter(protoThriftBinary, jr.(*jReceiver)).EmitBatch(batch))
+
+	for _, listener := range []string{protoThriftCompact, protoThriftBinary} {
+		rows, err := view.RetrieveData(statUDPReceivedSpans.Name())
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, sumForListener(rows, listener), "listener %s", listener)
+	}
+}
+
+func sumForListener(rows []*view.Row, listener string) float64 {
+	for _, row := range rows {
+		for _, tg := range row.Tags {
+			if tg.Key == tagUDPListenerKey && tg.Value == listener {
+				return row.Data.(*view.SumData).Value
+			}
+		}
+	}
+	return 0
+}
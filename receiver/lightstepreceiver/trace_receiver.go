@@ -0,0 +1,237 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lightstepreceiver receives spans reported by Lightstep tracers over
+// HTTP and converts them to the internal format.
+package lightstepreceiver
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/lightstep/lightstep-tracer-common/golang/gogo/collectorpb"
+	"go.opencensus.io/trace"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	lightsteptranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/lightstep"
+)
+
+const (
+	// reportsPath is the path used by the Lightstep tracers to send reports.
+	reportsPath = "/api/v2/reports"
+
+	contentTypeJSON  = "application/json"
+	contentTypeProto = "application/octet-stream"
+
+	traceSource      = "Lightstep"
+	receiverTagValue = "lightstep"
+)
+
+// Receiver receives ReportRequest messages, encoded as protobuf or JSON, from
+// Lightstep tracers.
+type Receiver struct {
+	// mu protects the fields of this struct
+	mu sync.Mutex
+
+	// addr is the address onto which the HTTP server will be bound
+	addr         string
+	nextConsumer consumer.TraceConsumer
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	server    *http.Server
+
+	// decompression limits the decompressed bodies of the requests.
+	decompression decompression.Settings
+}
+
+var _ receiver.TraceReceiver = (*Receiver)(nil)
+var _ http.Handler = (*Receiver)(nil)
+
+// New creates a new lightstepreceiver.Receiver reference.
+func New(address string, nextConsumer consumer.TraceConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+
+	return &Receiver{
+		addr:          address,
+		nextConsumer:  nextConsumer,
+		decompression: decompression.DefaultSettings(),
+	}, nil
+}
+
+// TraceSource returns the name of the trace data source.
+func (lr *Receiver) TraceSource() string {
+	return traceSource
+}
+
+// StartTraceReception spins up the receiver's HTTP server and makes the receiver start its processing.
+func (lr *Receiver) StartTraceReception(host receiver.Host) error {
+	if host == nil {
+		return errors.New("nil host")
+	}
+
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+
+	lr.startOnce.Do(func() {
+		ln, lerr := net.Listen("tcp", lr.addr)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(reportsPath, lr)
+		lr.server = &http.Server{Handler: mux}
+		go func() {
+			if serr := lr.server.Serve(ln); serr != http.ErrServerClosed {
+				host.ReportFatalError(serr)
+			}
+		}()
+
+		err = nil
+	})
+
+	return err
+}
+
+// StopTraceReception tells the receiver that should stop reception,
+// giving it a chance to perform any necessary clean-up and shutting down
+// its HTTP server.
+func (lr *Receiver) StopTraceReception() error {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	lr.stopOnce.Do(func() {
+		if lr.server == nil {
+			err = nil
+			return
+		}
+		err = lr.server.Close()
+	})
+	return err
+}
+
+// ServeHTTP handles a single ReportRequest, the response uses the same
+// encoding as the request.
+func (lr *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parentCtx := r.Context()
+	ctx, span := trace.StartSpan(parentCtx, "LightstepReceiver.Export")
+	defer span.End()
+
+	// If the starting RPC has a parent span, then add it as a parent link.
+	observability.SetParentLink(parentCtx, span)
+
+	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
+
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), contentTypeJSON)
+	req, err := decodeReportRequest(r, isJSON, lr.decompression)
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), decompression.StatusCode(err))
+		return
+	}
+
+	td, err := lightsteptranslator.ReportRequestToOCProto(req)
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(req.GetSpans()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	td.SourceFormat = receiverTagValue
+
+	if err := lr.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td); err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnavailable,
+			Message: err.Error(),
+		})
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(td.Spans))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(td.Spans), 0)
+
+	writeReportResponse(w, isJSON)
+}
+
+func decodeReportRequest(r *http.Request, isJSON bool, settings decompression.Settings) (*collectorpb.ReportRequest, error) {
+	blob, err := decompression.ReadAll(r, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &collectorpb.ReportRequest{}
+	if isJSON {
+		if err := jsonpb.Unmarshal(bytes.NewReader(blob), req); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	if err := proto.Unmarshal(blob, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func writeReportResponse(w http.ResponseWriter, isJSON bool) {
+	resp := &collectorpb.ReportResponse{}
+	if isJSON {
+		var buf bytes.Buffer
+		if err := (&jsonpb.Marshaler{}).Marshal(&buf, resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+
+	blob, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeProto)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(blob)
+}
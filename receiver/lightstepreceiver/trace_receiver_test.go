@@ -0,0 +1,199 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightstepreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/lightstep/lightstep-tracer-common/golang/gogo/collectorpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func testReportRequest() *collectorpb.ReportRequest {
+	return &collectorpb.ReportRequest{
+		Reporter: &collectorpb.Reporter{
+			Tags: []*collectorpb.KeyValue{
+				{Key: "lightstep.component_name", Value: &collectorpb.KeyValue_StringValue{StringValue: "frontend"}},
+			},
+		},
+		Spans: []*collectorpb.Span{
+			{SpanContext: &collectorpb.SpanContext{TraceId: 1, SpanId: 2}, OperationName: "a"},
+			{SpanContext: &collectorpb.SpanContext{TraceId: 1, SpanId: 3}, OperationName: "b"},
+		},
+	}
+}
+
+func startReceiver(t *testing.T, sink *exportertest.SinkTraceExporter) (*Receiver, string) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	lr, err := New(addr, sink)
+	require.NoError(t, err)
+	require.NoError(t, lr.StartTraceReception(receivertest.NewMockHost()))
+	return lr, fmt.Sprintf("http://%s%s", addr, reportsPath)
+}
+
+func TestReceiver_Encodings(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	lr, url := startReceiver(t, sink)
+	defer lr.StopTraceReception()
+
+	protoBlob, err := proto.Marshal(testReportRequest())
+	require.NoError(t, err)
+	var jsonBuf bytes.Buffer
+	require.NoError(t, (&jsonpb.Marshaler{}).Marshal(&jsonBuf, testReportRequest()))
+	var gzipBuf bytes.Buffer
+	gzw := gzip.NewWriter(&gzipBuf)
+	_, err = gzw.Write(protoBlob)
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	tests := []struct {
+		name         string
+		body         []byte
+		contentType  string
+		gzip         bool
+		wantRespType string
+	}{
+		{name: "proto", body: protoBlob, contentType: contentTypeProto, wantRespType: contentTypeProto},
+		{name: "json", body: jsonBuf.Bytes(), contentType: contentTypeJSON, wantRespType: contentTypeJSON},
+		{name: "gzip_proto", body: gzipBuf.Bytes(), contentType: contentTypeProto, gzip: true, wantRespType: contentTypeProto},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.wantRespType, resp.Header.Get("Content-Type"))
+
+			got := sink.AllTraces()
+			require.Len(t, got, i+1)
+			assert.Equal(t, "frontend", got[i].Node.ServiceInfo.Name)
+			assert.Len(t, got[i].Spans, 2)
+			assert.Equal(t, "lightstep", got[i].SourceFormat)
+		})
+	}
+}
+
+func TestReceiver_DecompressionLimits(t *testing.T) {
+	protoBlob, err := proto.Marshal(testReportRequest())
+	require.NoError(t, err)
+
+	sink := &exportertest.SinkTraceExporter{}
+	addr := testutils.GetAvailableLocalAddress(t)
+	lr, err := New(addr, sink)
+	require.NoError(t, err)
+	lr.decompression = decompression.Settings{MaxDecompressedSize: int64(len(protoBlob) - 1)}
+	require.NoError(t, lr.StartTraceReception(receivertest.NewMockHost()))
+	defer lr.StopTraceReception()
+	url := fmt.Sprintf("http://%s%s", addr, reportsPath)
+
+	var gzipBuf bytes.Buffer
+	gzw := gzip.NewWriter(&gzipBuf)
+	_, err = gzw.Write(protoBlob)
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	req, err := http.NewRequest(http.MethodPost, url, &gzipBuf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentTypeProto)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Empty(t, sink.AllTraces())
+}
+
+func TestReceiver_BadRequests(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	lr, url := startReceiver(t, sink)
+	defer lr.StopTraceReception()
+
+	invalidSpan, err := proto.Marshal(&collectorpb.ReportRequest{
+		Spans: []*collectorpb.Span{{OperationName: "no ids"}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+	}{
+		{name: "invalid_proto", body: []byte{0xff, 0xff}, contentType: contentTypeProto},
+		{name: "invalid_json", body: []byte("{"), contentType: contentTypeJSON},
+		{name: "invalid_span", body: invalidSpan, contentType: contentTypeProto},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(url, tt.contentType, bytes.NewReader(tt.body))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+	assert.Empty(t, sink.AllTraces())
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestReceiver_ConsumerError(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	lr, err := New(addr, exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("busy"))))
+	require.NoError(t, err)
+	require.NoError(t, lr.StartTraceReception(receivertest.NewMockHost()))
+	defer lr.StopTraceReception()
+
+	blob, err := proto.Marshal(testReportRequest())
+	require.NoError(t, err)
+	resp, err := http.Post(fmt.Sprintf("http://%s%s", addr, reportsPath), contentTypeProto, bytes.NewReader(blob))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestReceiver_StartStop(t *testing.T) {
+	lr, err := New(testutils.GetAvailableLocalAddress(t), exportertest.NewNopTraceExporter())
+	require.NoError(t, err)
+
+	assert.Error(t, lr.StartTraceReception(nil))
+	require.NoError(t, lr.StartTraceReception(receivertest.NewMockHost()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, lr.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, lr.StopTraceReception())
+	assert.Error(t, lr.StopTraceReception())
+}
@@ -15,14 +15,23 @@
 package opencensusreceiver
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/admission"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/netacl"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
+	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver/ocmetrics"
+	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver/octrace"
 )
 
 // Config defines configuration for OpenCensus receiver.
@@ -46,12 +55,36 @@ type Config struct {
 
 	// MaxConcurrentStreams sets the limit on the number of concurrent streams to each ServerTransport.
 	MaxConcurrentStreams uint32 `mapstructure:"max-concurrent-streams,omitempty"`
+
+	// PeerAddress adds the address of the clients to the node of the
+	// received data.
+	PeerAddress peeraddr.Settings `mapstructure:"peer-address"`
+
+	// Auth is the name of the extension validating the bearer tokens of the
+	// requests, none are authenticated if it is empty.
+	Auth string `mapstructure:"auth"`
+
+	// MaxInFlight is the maximum number of requests, or messages of the
+	// streams, processed concurrently, the ones exceeding it are throttled.
+	// No limit is applied if it is 0.
+	MaxInFlight int `mapstructure:"max-in-flight"`
+
+	// AllowedCIDRs are the networks, in CIDR notation, of the clients allowed
+	// to connect, the connections of the other clients are closed. All the
+	// clients are allowed if it is empty.
+	AllowedCIDRs []string `mapstructure:"allowed-cidrs"`
+
+	// VirtualHosts are the settings of the virtual hosts, by name, selected
+	// by the server name of the requests. The requests for other server
+	// names are served with the settings of the receiver.
+	VirtualHosts map[string]vhost.Settings `mapstructure:"virtual-hosts"`
 }
 
 // tlsCredentials holds the fields for TLS credentials
 // that are used for starting a server.
 // TODO(ccaraman): Add validation to check that these files exist at configuration loading time.
-//  Currently, these values aren't validated until the receiver is started.
+//
+//	Currently, these values aren't validated until the receiver is started.
 type tlsCredentials struct {
 	// CertFile is the file path containing the TLS certificate.
 	CertFile string `mapstructure:"cert-file"`
@@ -84,7 +117,7 @@ type keepaliveEnforcementPolicy struct {
 	PermitWithoutStream bool          `mapstructure:"permit-without-stream,omitempty"`
 }
 
-func (rOpts *Config) buildOptions() (opts []Option, err error) {
+func (rOpts *Config) buildOptions(logger *zap.Logger) (opts []Option, err error) {
 	tlsCredsOption, hasTLSCreds, err := rOpts.TLSCredentials.ToOpenCensusReceiverServerOption()
 	if err != nil {
 		return opts, fmt.Errorf("error initializing OpenCensus receiver %q TLS Credentials: %v", rOpts.NameVal, err)
@@ -96,7 +129,42 @@ func (rOpts *Config) buildOptions() (opts []Option, err error) {
 		opts = append(opts, WithCorsOrigins(rOpts.CorsOrigins))
 	}
 
+	acl, err := netacl.New(rOpts.AllowedCIDRs, rOpts.Name())
+	if err != nil {
+		return opts, fmt.Errorf("invalid allowed-cidrs of OpenCensus receiver %q: %v", rOpts.NameVal, err)
+	}
+	if acl != nil {
+		opts = append(opts, WithAllowedNetworks(acl))
+	}
+
+	if annotator := peeraddr.NewAnnotator(rOpts.PeerAddress); annotator != nil {
+		opts = append(opts,
+			WithTraceReceiverOptions(octrace.WithPeerAddress(annotator)),
+			WithMetricsReceiverOptions(ocmetrics.WithPeerAddress(annotator)))
+	}
+
 	grpcServerOptions := rOpts.grpcServerOptions()
+	// The requests are throttled before any work is done on them. The HTTP
+	// requests are forwarded to the gRPC server, so they are intercepted too.
+	controller := admission.NewController(rOpts.MaxInFlight, rOpts.Name())
+	unaryInterceptors := []grpc.UnaryServerInterceptor{controller.UnaryServerInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{controller.StreamServerInterceptor()}
+	router, err := vhost.New(logger, rOpts.VirtualHosts, rOpts.Auth, rOpts.Name())
+	if err != nil {
+		return opts, fmt.Errorf("invalid virtual-hosts of OpenCensus receiver %q: %v", rOpts.NameVal, err)
+	}
+	if router != nil {
+		// The router authenticates the requests with the settings of their
+		// virtual host, or of the receiver.
+		unaryInterceptors = append(unaryInterceptors, router.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, router.StreamServerInterceptor())
+	} else if authenticator := auth.NewAuthenticator(logger, rOpts.Auth, rOpts.Name()); authenticator != nil {
+		unaryInterceptors = append(unaryInterceptors, authenticator.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, authenticator.StreamServerInterceptor())
+	}
+	grpcServerOptions = append(grpcServerOptions,
+		grpc.UnaryInterceptor(chainUnaryInterceptors(unaryInterceptors)),
+		grpc.StreamInterceptor(chainStreamInterceptors(streamInterceptors)))
 	if len(grpcServerOptions) > 0 {
 		opts = append(opts, WithGRPCServerOptions(grpcServerOptions...))
 	}
@@ -104,6 +172,34 @@ func (rOpts *Config) buildOptions() (opts []Option, err error) {
 	return opts, err
 }
 
+// chainUnaryInterceptors returns the interceptor calling the interceptors in
+// order, the server accepts a single one.
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// chainStreamInterceptors returns the interceptor calling the interceptors in
+// order, the server accepts a single one.
+func chainStreamInterceptors(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
 func (rOpts *Config) grpcServerOptions() []grpc.ServerOption {
 	var grpcServerOptions []grpc.ServerOption
 	if rOpts.MaxRecvMsgSizeMiB > 0 {
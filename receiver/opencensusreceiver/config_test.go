@@ -15,15 +15,20 @@
 package opencensusreceiver
 
 import (
+	"context"
 	"path"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -39,7 +44,7 @@ func TestLoadConfig(t *testing.T) {
 
 	// Currently disabled receivers are removed from the total list of receivers so 'opencensus/disabled' doesn't
 	// contribute to the count.
-	assert.Equal(t, len(cfg.Receivers), 6)
+	assert.Equal(t, len(cfg.Receivers), 11)
 
 	r0 := cfg.Receivers["opencensus"]
 	assert.Equal(t, r0, factory.CreateDefaultConfig())
@@ -118,4 +123,129 @@ func TestLoadConfig(t *testing.T) {
 			},
 			CorsOrigins: []string{"https://*.test.com", "https://test.com"},
 		})
+
+	r6 := cfg.Receivers["opencensus/peer-address"].(*Config)
+	assert.Equal(t, r6,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "opencensus/peer-address",
+				Endpoint: "127.0.0.1:55678",
+			},
+			PeerAddress: peeraddr.Settings{
+				Enabled:         true,
+				ResolveHostname: true,
+			},
+		})
+
+	r7 := cfg.Receivers["opencensus/auth"].(*Config)
+	assert.Equal(t, r7,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "opencensus/auth",
+				Endpoint: "127.0.0.1:55678",
+			},
+			Auth: "bearer-token-auth",
+		})
+
+	r8 := cfg.Receivers["opencensus/max-in-flight"].(*Config)
+	assert.Equal(t, r8,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "opencensus/max-in-flight",
+				Endpoint: "127.0.0.1:55678",
+			},
+			MaxInFlight: 100,
+		})
+
+	r9 := cfg.Receivers["opencensus/allowed-cidrs"].(*Config)
+	assert.Equal(t, r9,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "opencensus/allowed-cidrs",
+				Endpoint: "127.0.0.1:55678",
+			},
+			AllowedCIDRs: []string{"10.0.0.0/8", "192.168.1.7"},
+		})
+
+	r10 := cfg.Receivers["opencensus/virtual-hosts"].(*Config)
+	assert.Equal(t, r10,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "opencensus/virtual-hosts",
+				Endpoint: "127.0.0.1:55678",
+			},
+			Auth: "oidc-auth",
+			VirtualHosts: map[string]vhost.Settings{
+				"tenant-a": {
+					Hosts:      []string{"a.ingest.example.com"},
+					Auth:       "bearer-token-auth",
+					Attributes: map[string]string{"tenant": "a"},
+				},
+				"tenant-b": {
+					Hosts: []string{"b.ingest.example.com", "b.ingest.example.org"},
+				},
+			},
+		})
+}
+
+func TestBuildOptions_AllowedCIDRs(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.AllowedCIDRs = []string{"10.0.0.0/8"}
+	_, err := cfg.buildOptions(zap.NewNop())
+	assert.NoError(t, err)
+
+	cfg.AllowedCIDRs = []string{"10.0.0.0/8", "10.0.0.0/99"}
+	_, err = cfg.buildOptions(zap.NewNop())
+	assert.EqualError(t, err, `invalid allowed-cidrs of OpenCensus receiver "opencensus": invalid CIDR "10.0.0.0/99"`)
+}
+
+func TestBuildOptions_VirtualHosts(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.VirtualHosts = map[string]vhost.Settings{"tenant-a": {Hosts: []string{"a.example.com"}}}
+	_, err := cfg.buildOptions(zap.NewNop())
+	assert.NoError(t, err)
+
+	cfg.VirtualHosts = map[string]vhost.Settings{"tenant-a": {}}
+	_, err = cfg.buildOptions(zap.NewNop())
+	assert.EqualError(t, err, `invalid virtual-hosts of OpenCensus receiver "opencensus": virtual host "tenant-a" has no hosts`)
+}
+
+func TestChainUnaryInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+	chain := chainUnaryInterceptors([]grpc.UnaryServerInterceptor{interceptor("first"), interceptor("second")})
+	resp, err := chain(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "req", resp)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestChainStreamInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.StreamServerInterceptor {
+		return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, name)
+			return handler(srv, ss)
+		}
+	}
+	chain := chainStreamInterceptors([]grpc.StreamServerInterceptor{interceptor("first"), interceptor("second")})
+	err := chain(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
 }
@@ -63,7 +63,7 @@ func (f *Factory) CreateTraceReceiver(
 	cfg configmodels.Receiver,
 	nextConsumer consumer.TraceConsumer,
 ) (receiver.TraceReceiver, error) {
-	r, err := f.createReceiver(cfg)
+	r, err := f.createReceiver(logger, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func (f *Factory) CreateMetricsReceiver(
 	consumer consumer.MetricsConsumer,
 ) (receiver.MetricsReceiver, error) {
 
-	r, err := f.createReceiver(cfg)
+	r, err := f.createReceiver(logger, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +90,7 @@ func (f *Factory) CreateMetricsReceiver(
 	return r, nil
 }
 
-func (f *Factory) createReceiver(cfg configmodels.Receiver) (*Receiver, error) {
+func (f *Factory) createReceiver(logger *zap.Logger, cfg configmodels.Receiver) (*Receiver, error) {
 	rCfg := cfg.(*Config)
 
 	// There must be one receiver for both metrics and traces. We maintain a map of
@@ -100,7 +100,7 @@ func (f *Factory) createReceiver(cfg configmodels.Receiver) (*Receiver, error) {
 	receiver, ok := receivers[rCfg]
 	if !ok {
 		// Build the configuration options.
-		opts, err := rCfg.buildOptions()
+		opts, err := rCfg.buildOptions(logger)
 		if err != nil {
 			return nil, err
 		}
@@ -30,6 +30,8 @@ import (
 	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 )
@@ -39,6 +41,7 @@ type Receiver struct {
 	nextConsumer       consumer.MetricsConsumer
 	metricBufferPeriod time.Duration
 	metricBufferCount  int
+	peerAddr           *peeraddr.Annotator
 }
 
 // New creates a new ocmetrics.Receiver reference.
@@ -93,13 +96,20 @@ func (ocr *Receiver) Export(mes agentmetricspb.MetricsService_ExportServer) erro
 		return errMetricsExportProtocolViolation
 	}
 
+	var peerIP string
+	if ocr.peerAddr != nil {
+		peerIP = peeraddr.FromGRPC(mes.Context())
+	}
+	// The virtual host, if any, the stream was routed to.
+	vh := vhost.FromContext(mes.Context())
+
 	var lastNonNilNode *commonpb.Node
 	var resource *resourcepb.Resource
 	// Now that we've got the first message with a Node, we can start to receive streamed up metrics.
 	for {
 		// If a Node has been sent from downstream, save and use it.
 		if recv.Node != nil {
-			lastNonNilNode = recv.Node
+			lastNonNilNode = vh.Node(ocr.peerAddr.Node(recv.Node, peerIP))
 		}
 
 		// TODO(songya): differentiate between unset and nil resource. See
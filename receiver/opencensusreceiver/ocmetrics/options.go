@@ -14,7 +14,11 @@
 
 package ocmetrics
 
-import "time"
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+)
 
 // Option interface defines for configuration settings to be applied to receivers.
 //
@@ -54,3 +58,19 @@ func (mpc metricBufferCount) WithReceiver(oci *Receiver) {
 func WithMetricBufferCount(count int) Option {
 	return metricBufferCount(count)
 }
+
+type peerAddress struct {
+	annotator *peeraddr.Annotator
+}
+
+var _ Option = (*peerAddress)(nil)
+
+func (pa *peerAddress) WithReceiver(ocr *Receiver) {
+	ocr.peerAddr = pa.annotator
+}
+
+// WithPeerAddress is an option that adds the address of the clients to the
+// node of the received metrics.
+func WithPeerAddress(annotator *peeraddr.Annotator) Option {
+	return &peerAddress{annotator: annotator}
+}
@@ -26,6 +26,8 @@ import (
 	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 )
@@ -42,6 +44,7 @@ type Receiver struct {
 	numWorkers   int
 	workers      []*receiverWorker
 	messageChan  chan *traceDataWithCtx
+	peerAddr     *peeraddr.Annotator
 }
 
 type traceDataWithCtx struct {
@@ -108,13 +111,20 @@ func (ocr *Receiver) Export(tes agenttracepb.TraceService_ExportServer) error {
 		return errTraceExportProtocolViolation
 	}
 
+	var peerIP string
+	if ocr.peerAddr != nil {
+		peerIP = peeraddr.FromGRPC(tes.Context())
+	}
+	// The virtual host, if any, the stream was routed to.
+	vh := vhost.FromContext(tes.Context())
+
 	var lastNonNilNode *commonpb.Node
 	var resource *resourcepb.Resource
 	// Now that we've got the first message with a Node, we can start to receive streamed up spans.
 	for {
 		// If a Node has been sent from downstream, save and use it.
 		if recv.Node != nil {
-			lastNonNilNode = recv.Node
+			lastNonNilNode = vh.Node(ocr.peerAddr.Node(recv.Node, peerIP))
 		}
 
 		// TODO(songya): differentiate between unset and nil resource. See
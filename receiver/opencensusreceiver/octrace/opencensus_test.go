@@ -39,6 +39,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"go.opencensus.io/trace"
 	"go.opencensus.io/trace/tracestate"
@@ -430,6 +431,47 @@ func TestExportProtocolConformation_spansInFirstMessage(t *testing.T) {
 	}
 }
 
+func TestExportPeerAddress(t *testing.T) {
+	spanSink := newSpanAppender()
+
+	annotator := peeraddr.NewAnnotator(peeraddr.Settings{Enabled: true})
+	_, port, doneFn := ocReceiverOnGRPCServer(t, spanSink, WithPeerAddress(annotator))
+	defer doneFn()
+
+	traceClient, traceClientDoneFn, err := makeTraceServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC TraceService_ExportClient: %v", err)
+	}
+	defer traceClientDoneFn()
+
+	ni := &commonpb.Node{
+		Identifier: &commonpb.ProcessIdentifier{Pid: 1},
+		Attributes: map[string]string{"a": "b"},
+	}
+	sLi := []*tracepb.Span{{TraceId: []byte("1234567890abcde")}}
+	if err := traceClient.Send(&agenttracepb.ExportTraceServiceRequest{Node: ni, Spans: sLi}); err != nil {
+		t.Fatalf("Failed to send the first message: %v", err)
+	}
+
+	// Give it time to be sent over the wire, then exported.
+	<-time.After(100 * time.Millisecond)
+
+	var nodes []*commonpb.Node
+	spanSink.forEachEntry(func(node *commonpb.Node, spans []*tracepb.Span) {
+		nodes = append(nodes, node)
+	})
+	if len(nodes) != 1 {
+		t.Fatalf("Got %d nodes, want 1", len(nodes))
+	}
+	ip := net.ParseIP(nodes[0].Attributes[peeraddr.IPAttribute])
+	if ip == nil || !ip.IsLoopback() {
+		t.Errorf("Got peer address %q, want a loopback address", nodes[0].Attributes[peeraddr.IPAttribute])
+	}
+	if nodes[0].Attributes["a"] != "b" || nodes[0].Identifier.Pid != 1 {
+		t.Errorf("The node was not preserved: %v", nodes[0])
+	}
+}
+
 // Helper functions from here on below
 func makeTraceServiceClient(port int) (agenttracepb.TraceService_ExportClient, func(), error) {
 	addr := fmt.Sprintf(":%d", port)
@@ -14,6 +14,8 @@
 
 package octrace
 
+import "github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+
 // Option interface defines for configuration settings to be applied to receivers.
 //
 // WithReceiver applies the configuration to the given receiver.
@@ -26,3 +28,11 @@ func WithWorkerCount(workerCount int) Option {
 		r.numWorkers = workerCount
 	}
 }
+
+// WithPeerAddress adds the address of the clients to the node of the
+// received spans.
+func WithPeerAddress(annotator *peeraddr.Annotator) Option {
+	return func(r *Receiver) {
+		r.peerAddr = annotator
+	}
+}
@@ -28,13 +28,16 @@ import (
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
 	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
 )
@@ -209,6 +212,54 @@ func TestMetricsGrpcGatewayCors_endToEnd(t *testing.T) {
 	verifyCorsResp(t, url, "disallowed-origin.com", 200, false)
 }
 
+func TestGrpcGatewayAuth_endToEnd(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+
+	auth.Register("test-auth", auth.ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, auth.ErrInvalidToken
+		}
+		return ctx, nil
+	}))
+	defer auth.Unregister("test-auth")
+
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Auth = "test-auth"
+	opts, err := cfg.buildOptions(zap.NewNop())
+	require.NoError(t, err)
+
+	sink := new(exportertest.SinkTraceExporter)
+	ocr, err := New(addr, sink, nil, opts...)
+	require.NoError(t, err)
+	defer ocr.StopTraceReception()
+
+	mh := receivertest.NewMockHost()
+	require.NoError(t, ocr.StartTraceReception(mh))
+
+	// TODO(songy23): make starting server deterministic
+	// Wait for the servers to start
+	<-time.After(10 * time.Millisecond)
+
+	url := fmt.Sprintf("http://%s/v1/trace", addr)
+	post := func(authorization string) int {
+		req, err := http.NewRequest("POST", url, bytes.NewBufferString(`{"node":{"identifier":{"hostName":"testHost"}},"spans":[{"name":{"value":"testSpan"}}]}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post(""))
+	assert.Equal(t, http.StatusUnauthorized, post("Bearer wrong"))
+	assert.Equal(t, http.StatusOK, post("Bearer secret"))
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
 // As per Issue https://github.com/census-instrumentation/opencensus-service/issues/366
 // the agent's mux should be able to accept all Proto affiliated content-types and not
 // redirect them to the web-grpc-gateway endpoint.
@@ -17,6 +17,7 @@ package opencensusreceiver
 import (
 	"google.golang.org/grpc"
 
+	"github.com/open-telemetry/opentelemetry-service/internal/netacl"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver/ocmetrics"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver/octrace"
 )
@@ -90,6 +91,22 @@ func WithGRPCServerOptions(gsOpts ...grpc.ServerOption) Option {
 	return gsvOpts
 }
 
+type allowedNetworks struct {
+	acl *netacl.ACL
+}
+
+var _ Option = (*allowedNetworks)(nil)
+
+func (an *allowedNetworks) withReceiver(ocr *Receiver) {
+	ocr.ln = an.acl.Listener(ocr.ln)
+}
+
+// WithAllowedNetworks is an option to close the connections of the clients
+// outside the networks allowed by the ACL, before any request is read.
+func WithAllowedNetworks(acl *netacl.ACL) Option {
+	return &allowedNetworks{acl: acl}
+}
+
 type noopOption int
 
 var _ Option = (noopOption)(0)
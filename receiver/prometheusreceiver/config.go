@@ -15,11 +15,14 @@
 package prometheusreceiver
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/prometheus/prometheus/config"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver/internal/sharding"
 )
 
 // Config defines configuration for Prometheus receiver.
@@ -29,4 +32,37 @@ type Config struct {
 	BufferPeriod                  time.Duration       `mapstructure:"buffer_period"`
 	BufferCount                   int                 `mapstructure:"buffer_count"`
 	IncludeFilter                 map[string][]string `mapstructure:"include_filter"`
+	LeaderElection                string              `mapstructure:"leader_election"`
+	Sharding                      ShardingConfig      `mapstructure:"sharding"`
+	ScrapeMetrics                 bool                `mapstructure:"scrape_metrics"`
+}
+
+// ShardingConfig defines how the scrape targets are split across the replicas
+// of a StatefulSet, each target being scraped by a single replica.
+type ShardingConfig struct {
+	// Replicas is the number of replicas sharing the targets, sharding is
+	// disabled below 2.
+	Replicas int `mapstructure:"replicas"`
+	// Index is the index of this replica, in [0, replicas). By default it is
+	// the ordinal suffix of the host name, that is of the StatefulSet pod name.
+	Index *int `mapstructure:"index"`
+}
+
+// sharder returns the sharder of the replica or nil if sharding is disabled.
+func (s *ShardingConfig) sharder() (*sharding.Sharder, error) {
+	if s.Replicas < 2 {
+		return nil, nil
+	}
+	if s.Index != nil {
+		return sharding.NewSharder(s.Replicas, *s.Index)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the sharding index from the host name: %v", err)
+	}
+	index, err := sharding.IndexFromHostname(hostname)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the sharding index from the host name: %v", err)
+	}
+	return sharding.NewSharder(s.Replicas, index)
 }
@@ -56,4 +56,8 @@ func TestLoadConfig(t *testing.T) {
 		"localhost:9778": {"http/client/roundtrip_latency"},
 	}
 	assert.Equal(t, r1.IncludeFilter, wantFilter)
+	assert.Equal(t, "leader-election", r1.LeaderElection)
+	index := 1
+	assert.Equal(t, ShardingConfig{Replicas: 3, Index: &index}, r1.Sharding)
+	assert.False(t, r1.ScrapeMetrics)
 }
@@ -93,6 +93,7 @@ func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
 			NameVal:  typeStr,
 			Endpoint: "127.0.0.1:9090",
 		},
+		ScrapeMetrics: true,
 	}
 }
 
@@ -118,14 +119,20 @@ func (f *Factory) CreateMetricsReceiver(
 
 	// Create receiver Configuration from our input cfg
 	config := Configuration{
-		BufferCount:   rCfg.BufferCount,
-		BufferPeriod:  rCfg.BufferPeriod,
-		ScrapeConfig:  rCfg.PrometheusConfig,
-		IncludeFilter: rCfg.IncludeFilter,
+		BufferCount:    rCfg.BufferCount,
+		BufferPeriod:   rCfg.BufferPeriod,
+		ScrapeConfig:   rCfg.PrometheusConfig,
+		IncludeFilter:  rCfg.IncludeFilter,
+		LeaderElection: rCfg.LeaderElection,
+		Sharding:       rCfg.Sharding,
+		ScrapeMetrics:  rCfg.ScrapeMetrics,
 	}
 
 	if config.ScrapeConfig == nil || len(config.ScrapeConfig.ScrapeConfigs) == 0 {
 		return nil, errNilScrapeConfig
 	}
+	if _, err := config.Sharding.sharder(); err != nil {
+		return nil, fmt.Errorf("invalid sharding config of %q: %v", cfg.Name(), err)
+	}
 	return newPrometheusReceiver(logger, &config, consumer), nil
 }
@@ -18,6 +18,7 @@ import (
 	"context"
 	"testing"
 
+	promconfig "github.com/prometheus/prometheus/config"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
@@ -44,3 +45,20 @@ func TestCreateReceiver(t *testing.T) {
 	assert.Equal(t, err, errNilScrapeConfig)
 	assert.Nil(t, mReceiver)
 }
+
+func TestCreateReceiver_Sharding(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.PrometheusConfig = &promconfig.Config{ScrapeConfigs: []*promconfig.ScrapeConfig{{JobName: "demo"}}}
+
+	index := 3
+	cfg.Sharding = ShardingConfig{Replicas: 3, Index: &index}
+	mReceiver, err := factory.CreateMetricsReceiver(zap.NewNop(), cfg, nil)
+	assert.Error(t, err)
+	assert.Nil(t, mReceiver)
+
+	index = 2
+	mReceiver, err = factory.CreateMetricsReceiver(zap.NewNop(), cfg, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, mReceiver)
+}
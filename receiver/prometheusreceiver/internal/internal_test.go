@@ -37,7 +37,8 @@ func init() {
 }
 
 type mockMetadataCache struct {
-	data map[string]scrape.MetricMetadata
+	data         map[string]scrape.MetricMetadata
+	targetLabels labels.Labels
 }
 
 func newMockMetadataCache(data map[string]scrape.MetricMetadata) *mockMetadataCache {
@@ -53,6 +54,10 @@ func (m *mockMetadataCache) SharedLabels() labels.Labels {
 	return labels.FromStrings("__scheme__", "http")
 }
 
+func (m *mockMetadataCache) TargetLabels() labels.Labels {
+	return m.targetLabels
+}
+
 func newMockConsumer() *mockConsumer {
 	return &mockConsumer{}
 }
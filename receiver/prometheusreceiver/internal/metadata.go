@@ -31,6 +31,9 @@ type MetadataService interface {
 type MetadataCache interface {
 	Metadata(metricName string) (scrape.MetricMetadata, bool)
 	SharedLabels() labels.Labels
+	// TargetLabels returns the labels of the target after relabeling, without
+	// the internal ones, which prometheus adds to all the scraped samples.
+	TargetLabels() labels.Labels
 }
 
 type mService struct {
@@ -65,3 +68,7 @@ func (m *mCache) Metadata(metricName string) (scrape.MetricMetadata, bool) {
 func (m *mCache) SharedLabels() labels.Labels {
 	return m.t.DiscoveredLabels()
 }
+
+func (m *mCache) TargetLabels() labels.Labels {
+	return m.t.Labels()
+}
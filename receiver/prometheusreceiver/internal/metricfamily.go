@@ -62,6 +62,10 @@ func newMetricFamily(metricName string, mc MetadataCache) MetricFamily {
 			metadata.Type = textparse.MetricTypeUnknown
 		}
 	}
+	// the synthetic scrape metrics have no metadata in the target
+	if scrapeMetadata, isScrapeMetric := scrapeMetricsMetadata[familyName]; !ok && isScrapeMetric {
+		metadata = scrapeMetadata
+	}
 
 	return &metricFamily{
 		name:             familyName,
@@ -27,6 +27,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/pkg/textparse"
+	"github.com/prometheus/prometheus/scrape"
 )
 
 const metricsSuffixCount = "_count"
@@ -40,9 +41,36 @@ var errEmptyBoundaryLabel = errors.New("BucketLabel or QuantileLabel is empty")
 
 var dummyMetrics = make([]*metricspb.Metric, 0)
 
+// scrapeMetricsMetadata is the metadata of the synthetic metrics prometheus reports for each scrape of a target, see
+// https://prometheus.io/docs/concepts/jobs_instances/#automatically-generated-labels-and-time-series
+var scrapeMetricsMetadata = map[string]scrape.MetricMetadata{
+	"up": {
+		Metric: "up",
+		Type:   textparse.MetricTypeGauge,
+		Help:   "1 if the target is healthy, i.e. the scrape succeeded, 0 otherwise.",
+	},
+	"scrape_duration_seconds": {
+		Metric: "scrape_duration_seconds",
+		Type:   textparse.MetricTypeGauge,
+		Help:   "Duration of the scrape.",
+		Unit:   "s",
+	},
+	"scrape_samples_scraped": {
+		Metric: "scrape_samples_scraped",
+		Type:   textparse.MetricTypeGauge,
+		Help:   "The number of samples the target exposed.",
+	},
+	"scrape_samples_post_metric_relabeling": {
+		Metric: "scrape_samples_post_metric_relabeling",
+		Type:   textparse.MetricTypeGauge,
+		Help:   "The number of samples remaining after metric relabeling was applied.",
+	},
+}
+
 type metricBuilder struct {
 	hasData           bool
 	hasInternalMetric bool
+	scrapeMetrics     bool
 	mc                MetadataCache
 	metrics           []*metricspb.Metric
 	logger            *zap.SugaredLogger
@@ -51,13 +79,15 @@ type metricBuilder struct {
 
 // newMetricBuilder creates a MetricBuilder which is allowed to feed all the datapoints from a single prometheus
 // scraped page by calling its AddDataPoint function, and turn them into an opencensus data.MetricsData object
-// by calling its Build function
-func newMetricBuilder(mc MetadataCache, logger *zap.SugaredLogger) *metricBuilder {
+// by calling its Build function. The synthetic metrics reported for each scrape, such as up, are kept if scrapeMetrics
+// is true, and skipped otherwise
+func newMetricBuilder(mc MetadataCache, scrapeMetrics bool, logger *zap.SugaredLogger) *metricBuilder {
 
 	return &metricBuilder{
-		mc:      mc,
-		metrics: make([]*metricspb.Metric, 0),
-		logger:  logger,
+		mc:            mc,
+		scrapeMetrics: scrapeMetrics,
+		metrics:       make([]*metricspb.Metric, 0),
+		logger:        logger,
 	}
 }
 
@@ -66,7 +96,7 @@ func (b *metricBuilder) AddDataPoint(ls labels.Labels, t int64, v float64) error
 	metricName := ls.Get(model.MetricNameLabel)
 	if metricName == "" {
 		return errMetricNameNotFound
-	} else if shouldSkip(metricName) {
+	} else if shouldSkip(metricName, b.scrapeMetrics) {
 		b.hasInternalMetric = true
 		lm := ls.Map()
 		delete(lm, model.MetricNameLabel)
@@ -245,7 +275,10 @@ func timestampFromMs(timeAtMs int64) *timestamp.Timestamp {
 	}
 }
 
-func shouldSkip(metricName string) bool {
+func shouldSkip(metricName string, scrapeMetrics bool) bool {
+	if _, ok := scrapeMetricsMetadata[metricName]; ok && scrapeMetrics {
+		return false
+	}
 	if metricName == "up" || strings.HasPrefix(metricName, "scrape_") {
 		return true
 	}
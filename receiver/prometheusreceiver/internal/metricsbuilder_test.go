@@ -94,7 +94,7 @@ func runBuilderTests(t *testing.T, tests []buildTestData) {
 			mc := newMockMetadataCache(testMetadata)
 			st := startTs
 			for i, page := range tt.inputs {
-				b := newMetricBuilder(mc, testLogger)
+				b := newMetricBuilder(mc, false, testLogger)
 				for _, pt := range page.pts {
 					// set ts for testing
 					pt.t = st
@@ -1064,10 +1064,67 @@ func Test_metricBuilder_skipped(t *testing.T) {
 	runBuilderTests(t, tests)
 }
 
+func Test_metricBuilder_scrapeMetrics(t *testing.T) {
+	mc := newMockMetadataCache(testMetadata)
+	b := newMetricBuilder(mc, true, testLogger)
+	pts := []*testDataPoint{
+		createDataPoint("up", 1.0),
+		createDataPoint("scrape_duration_seconds", 0.5),
+		createDataPoint("scrape_foo", 1),
+	}
+	for _, pt := range pts {
+		if err := b.AddDataPoint(pt.lb, startTs, pt.v); err != nil {
+			t.Error("unexpected error adding data", err)
+		}
+	}
+	metrics, err := b.Build()
+	if err != nil {
+		t.Error("unexpected error on build", err)
+	}
+
+	want := []*metricspb.Metric{
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:        "up",
+				Description: scrapeMetricsMetadata["up"].Help,
+				Type:        metricspb.MetricDescriptor_GAUGE_DOUBLE,
+				LabelKeys:   []*metricspb.LabelKey{}},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					LabelValues: []*metricspb.LabelValue{},
+					Points: []*metricspb.Point{
+						{Timestamp: timestampFromMs(startTs), Value: &metricspb.Point_DoubleValue{DoubleValue: 1.0}},
+					},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:        "scrape_duration_seconds",
+				Description: scrapeMetricsMetadata["scrape_duration_seconds"].Help,
+				Unit:        "s",
+				Type:        metricspb.MetricDescriptor_GAUGE_DOUBLE,
+				LabelKeys:   []*metricspb.LabelKey{}},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					LabelValues: []*metricspb.LabelValue{},
+					Points: []*metricspb.Point{
+						{Timestamp: timestampFromMs(startTs), Value: &metricspb.Point_DoubleValue{DoubleValue: 0.5}},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(metrics, want) {
+		diff := cmp.Diff(string(exportertest.ToJSON(want)), string(exportertest.ToJSON(metrics)))
+		t.Errorf("metricBuilder.Build() mismatch (-want +got):\n%v", diff)
+	}
+}
+
 func Test_metricBuilder_baddata(t *testing.T) {
 	t.Run("empty-metric-name", func(t *testing.T) {
 		mc := newMockMetadataCache(testMetadata)
-		b := newMetricBuilder(mc, testLogger)
+		b := newMetricBuilder(mc, false, testLogger)
 		if err := b.AddDataPoint(labels.FromStrings("a", "b"), startTs, 123); err != errMetricNameNotFound {
 			t.Error("expecting errMetricNameNotFound error, but get nil")
 			return
@@ -1080,7 +1137,7 @@ func Test_metricBuilder_baddata(t *testing.T) {
 
 	t.Run("histogram-datapoint-no-bucket-label", func(t *testing.T) {
 		mc := newMockMetadataCache(testMetadata)
-		b := newMetricBuilder(mc, testLogger)
+		b := newMetricBuilder(mc, false, testLogger)
 		if err := b.AddDataPoint(createLabels("hist_test", "k", "v"), startTs, 123); err != errEmptyBoundaryLabel {
 			t.Error("expecting errEmptyBoundaryLabel error, but get nil")
 		}
@@ -1088,7 +1145,7 @@ func Test_metricBuilder_baddata(t *testing.T) {
 
 	t.Run("summary-datapoint-no-quantile-label", func(t *testing.T) {
 		mc := newMockMetadataCache(testMetadata)
-		b := newMetricBuilder(mc, testLogger)
+		b := newMetricBuilder(mc, false, testLogger)
 		if err := b.AddDataPoint(createLabels("summary_test", "k", "v"), startTs, 123); err != errEmptyBoundaryLabel {
 			t.Error("expecting errEmptyBoundaryLabel error, but get nil")
 		}
@@ -38,6 +38,7 @@ const (
 
 var idSeq int64
 var noop = &noopAppender{}
+var discard = &discardAppender{}
 
 // OcaStore is an interface combines io.Closer and prometheus' scrape.Appendable
 type OcaStore interface {
@@ -55,17 +56,25 @@ type ocaStore struct {
 	once    *sync.Once
 	ctx     context.Context
 	jobsMap *JobsMap
+	// scrapeMetrics reports whether the synthetic metrics of each scrape,
+	// such as up, are exported.
+	scrapeMetrics bool
+	// isLeader reports whether the scraped metrics should be exported, nil
+	// when they always are.
+	isLeader func() bool
 }
 
 // NewOcaStore returns an ocaStore instance, which can be acted as prometheus' scrape.Appendable
-func NewOcaStore(ctx context.Context, sink consumer.MetricsConsumer, logger *zap.SugaredLogger, jobsMap *JobsMap) OcaStore {
+func NewOcaStore(ctx context.Context, sink consumer.MetricsConsumer, logger *zap.SugaredLogger, jobsMap *JobsMap, scrapeMetrics bool, isLeader func() bool) OcaStore {
 	return &ocaStore{
-		running: runningStateInit,
-		ctx:     ctx,
-		sink:    sink,
-		logger:  logger,
-		once:    &sync.Once{},
-		jobsMap: jobsMap,
+		running:       runningStateInit,
+		ctx:           ctx,
+		sink:          sink,
+		logger:        logger,
+		once:          &sync.Once{},
+		jobsMap:       jobsMap,
+		scrapeMetrics: scrapeMetrics,
+		isLeader:      isLeader,
 	}
 }
 
@@ -80,7 +89,10 @@ func (o *ocaStore) SetScrapeManager(scrapeManager *scrape.Manager) {
 func (o *ocaStore) Appender() (storage.Appender, error) {
 	state := atomic.LoadInt32(&o.running)
 	if state == runningStateReady {
-		return newTransaction(o.ctx, o.jobsMap, o.mc, o.sink, o.logger), nil
+		if o.isLeader != nil && !o.isLeader() {
+			return discard, nil
+		}
+		return newTransaction(o.ctx, o.jobsMap, o.scrapeMetrics, o.mc, o.sink, o.logger), nil
 	} else if state == runningStateInit {
 		return nil, errors.New("ScrapeManager is not set")
 	}
@@ -111,3 +123,23 @@ func (*noopAppender) Commit() error {
 func (*noopAppender) Rollback() error {
 	return nil
 }
+
+// discardAppender drops the scraped samples, used while another collector
+// instance is the leader so that replicas do not report the same metrics
+type discardAppender struct{}
+
+func (*discardAppender) Add(l labels.Labels, t int64, v float64) (uint64, error) {
+	return 0, nil
+}
+
+func (*discardAppender) AddFast(l labels.Labels, ref uint64, t int64, v float64) error {
+	return nil
+}
+
+func (*discardAppender) Commit() error {
+	return nil
+}
+
+func (*discardAppender) Rollback() error {
+	return nil
+}
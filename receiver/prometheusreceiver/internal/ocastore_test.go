@@ -24,7 +24,7 @@ import (
 
 func TestOcaStore(t *testing.T) {
 
-	o := NewOcaStore(context.Background(), nil, nil, nil)
+	o := NewOcaStore(context.Background(), nil, nil, nil, false, nil)
 
 	_, err := o.Appender()
 	if err == nil {
@@ -52,6 +52,29 @@ func TestOcaStore(t *testing.T) {
 	}
 }
 
+func TestOcaStore_NotLeader(t *testing.T) {
+	leader := false
+	o := NewOcaStore(context.Background(), nil, nil, nil, false, func() bool { return leader })
+	o.SetScrapeManager(&scrape.Manager{})
+
+	app, err := o.Appender()
+	if app != discard || err != nil {
+		t.Fatalf("expect app==discard and err==nil, got app=%v and err=%v", app, err)
+	}
+	if _, err := app.Add(labels.FromStrings("t", "v"), 1, 1); err != nil {
+		t.Errorf("expecting no error from Add method of discardAppender, got %v", err)
+	}
+	if err := app.Commit(); err != nil {
+		t.Errorf("expecting no error from Commit method of discardAppender, got %v", err)
+	}
+
+	leader = true
+	app, err = o.Appender()
+	if _, ok := app.(*transaction); !ok || err != nil {
+		t.Fatalf("expect a transaction and err==nil, got app=%v and err=%v", app, err)
+	}
+}
+
 func TestNoopAppender(t *testing.T) {
 	if _, err := noop.Add(labels.FromStrings("t", "v"), 1, 1); err == nil {
 		t.Error("expecting error from Add method of noopApender")
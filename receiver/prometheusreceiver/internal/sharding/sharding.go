@@ -0,0 +1,142 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharding splits the scrape targets discovered by the Prometheus
+// receiver across the replicas of the service, so that each target is scraped
+// by a single replica.
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+)
+
+// Sharder keeps the targets assigned to one replica. Targets are assigned with
+// rendezvous hashing, so that changing the number of replicas only moves the
+// targets of the replicas added or removed.
+type Sharder struct {
+	replicas int
+	index    int
+}
+
+// NewSharder creates a Sharder for the replica with the given index, in
+// [0, replicas).
+func NewSharder(replicas, index int) (*Sharder, error) {
+	if replicas < 1 {
+		return nil, fmt.Errorf("replicas must be positive, got %d", replicas)
+	}
+	if index < 0 || index >= replicas {
+		return nil, fmt.Errorf("index must be in [0, %d), got %d", replicas, index)
+	}
+	return &Sharder{replicas: replicas, index: index}, nil
+}
+
+// IndexFromHostname returns the ordinal of a StatefulSet pod from its host
+// name, e.g. 2 for "otelsvc-2".
+func IndexFromHostname(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, errors.New("host name has no ordinal suffix: " + hostname)
+	}
+	index, err := strconv.Atoi(hostname[i+1:])
+	if err != nil || index < 0 {
+		return 0, errors.New("host name has no ordinal suffix: " + hostname)
+	}
+	return index, nil
+}
+
+// Owner returns the index of the replica scraping the target with the given
+// key.
+func (s *Sharder) Owner(key string) int {
+	owner := 0
+	var max uint64
+	for i := 0; i < s.replicas; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(i)))
+		// FNV mixes the last bytes poorly, finalize it so that the weights of
+		// the replicas are independent.
+		if w := mix(h.Sum64()); i == 0 || w > max {
+			owner, max = i, w
+		}
+	}
+	return owner
+}
+
+// mix is the finalizer of SplitMix64.
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// Filter returns the target groups with only the targets of the replica. The
+// groups left without targets are kept so that their previous targets are
+// dropped by the scrape manager.
+func (s *Sharder) Filter(tsets map[string][]*targetgroup.Group) map[string][]*targetgroup.Group {
+	filtered := make(map[string][]*targetgroup.Group, len(tsets))
+	for job, groups := range tsets {
+		fgroups := make([]*targetgroup.Group, 0, len(groups))
+		for _, group := range groups {
+			if group == nil {
+				continue
+			}
+			fgroup := &targetgroup.Group{Labels: group.Labels, Source: group.Source}
+			for _, target := range group.Targets {
+				address, ok := target[model.AddressLabel]
+				if !ok {
+					address = group.Labels[model.AddressLabel]
+				}
+				if s.Owner(job+"/"+string(address)) == s.index {
+					fgroup.Targets = append(fgroup.Targets, target)
+				}
+			}
+			fgroups = append(fgroups, fgroup)
+		}
+		filtered[job] = fgroups
+	}
+	return filtered
+}
+
+// Run filters the target groups received on in and sends them on the returned
+// channel until the context is cancelled.
+func (s *Sharder) Run(ctx context.Context, in <-chan map[string][]*targetgroup.Group) <-chan map[string][]*targetgroup.Group {
+	out := make(chan map[string][]*targetgroup.Group)
+	go func() {
+		for {
+			select {
+			case tsets := <-in:
+				select {
+				case out <- s.Filter(tsets):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,127 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSharder(t *testing.T) {
+	_, err := NewSharder(0, 0)
+	assert.Error(t, err)
+	_, err = NewSharder(3, 3)
+	assert.Error(t, err)
+	_, err = NewSharder(3, -1)
+	assert.Error(t, err)
+	_, err = NewSharder(3, 2)
+	assert.NoError(t, err)
+}
+
+func TestIndexFromHostname(t *testing.T) {
+	index, err := IndexFromHostname("otelsvc-collector-12")
+	require.NoError(t, err)
+	assert.Equal(t, 12, index)
+
+	for _, hostname := range []string{"otelsvc", "otelsvc-", "otelsvc-a", "otelsvc-1a"} {
+		_, err = IndexFromHostname(hostname)
+		assert.Error(t, err, hostname)
+	}
+}
+
+func TestSharder_Owner(t *testing.T) {
+	const numKeys = 3000
+	three, err := NewSharder(3, 0)
+	require.NoError(t, err)
+	four, err := NewSharder(4, 0)
+	require.NoError(t, err)
+
+	counts := make([]int, 3)
+	moved := 0
+	for i := 0; i < numKeys; i++ {
+		key := fmt.Sprintf("job/10.0.0.%d:9090", i)
+		owner := three.Owner(key)
+		counts[owner]++
+		assert.Equal(t, owner, three.Owner(key))
+		if newOwner := four.Owner(key); newOwner != owner {
+			// Adding a replica only moves targets to the new replica.
+			assert.Equal(t, 3, newOwner)
+			moved++
+		}
+	}
+	for _, count := range counts {
+		assert.InDelta(t, numKeys/3, count, numKeys/10)
+	}
+	assert.InDelta(t, numKeys/4, moved, numKeys/10)
+}
+
+func TestSharder_Filter(t *testing.T) {
+	tsets := map[string][]*targetgroup.Group{
+		"job": {
+			{
+				Source: "static/0",
+				Labels: model.LabelSet{"env": "prod"},
+			},
+			nil,
+		},
+	}
+	for i := 0; i < 100; i++ {
+		tsets["job"][0].Targets = append(tsets["job"][0].Targets,
+			model.LabelSet{model.AddressLabel: model.LabelValue(fmt.Sprintf("10.0.0.%d:9090", i))})
+	}
+
+	seen := make(map[model.LabelValue]int)
+	for index := 0; index < 2; index++ {
+		s, err := NewSharder(2, index)
+		require.NoError(t, err)
+		filtered := s.Filter(tsets)
+		require.Len(t, filtered["job"], 1)
+		group := filtered["job"][0]
+		assert.Equal(t, "static/0", group.Source)
+		assert.Equal(t, model.LabelSet{"env": "prod"}, group.Labels)
+		assert.True(t, len(group.Targets) > 0 && len(group.Targets) < 100)
+		for _, target := range group.Targets {
+			assert.Equal(t, index, s.Owner("job/"+string(target[model.AddressLabel])))
+			seen[target[model.AddressLabel]]++
+		}
+	}
+	// Every target is scraped by exactly one replica.
+	assert.Len(t, seen, 100)
+	for address, count := range seen {
+		assert.Equal(t, 1, count, address)
+	}
+	// The input is left untouched.
+	assert.Len(t, tsets["job"][0].Targets, 100)
+}
+
+func TestSharder_Run(t *testing.T) {
+	s, err := NewSharder(2, 0)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan map[string][]*targetgroup.Group)
+	out := s.Run(ctx, in)
+	in <- map[string][]*targetgroup.Group{"job": {{Source: "static/0"}}}
+	got := <-out
+	require.Len(t, got["job"], 1)
+	assert.Equal(t, "static/0", got["job"][0].Source)
+}
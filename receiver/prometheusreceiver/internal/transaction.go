@@ -18,7 +18,7 @@ import (
 	"context"
 	"errors"
 	"math"
-	"strings"
+	"net"
 	"sync/atomic"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
@@ -53,21 +53,23 @@ type transaction struct {
 	job           string
 	instance      string
 	jobsMap       *JobsMap
+	scrapeMetrics bool
 	ms            MetadataService
 	node          *commonpb.Node
 	metricBuilder *metricBuilder
 	logger        *zap.SugaredLogger
 }
 
-func newTransaction(ctx context.Context, jobsMap *JobsMap, ms MetadataService, sink consumer.MetricsConsumer, logger *zap.SugaredLogger) *transaction {
+func newTransaction(ctx context.Context, jobsMap *JobsMap, scrapeMetrics bool, ms MetadataService, sink consumer.MetricsConsumer, logger *zap.SugaredLogger) *transaction {
 	return &transaction{
-		id:      atomic.AddInt64(&idSeq, 1),
-		ctx:     ctx,
-		isNew:   true,
-		sink:    sink,
-		jobsMap: jobsMap,
-		ms:      ms,
-		logger:  logger,
+		id:            atomic.AddInt64(&idSeq, 1),
+		ctx:           ctx,
+		isNew:         true,
+		sink:          sink,
+		jobsMap:       jobsMap,
+		scrapeMetrics: scrapeMetrics,
+		ms:            ms,
+		logger:        logger,
 	}
 }
 
@@ -119,8 +121,8 @@ func (tr *transaction) initTransaction(ls labels.Labels) error {
 		tr.job = job
 		tr.instance = instance
 	}
-	tr.node = createNode(job, instance, mc.SharedLabels().Get(model.SchemeLabel))
-	tr.metricBuilder = newMetricBuilder(mc, tr.logger)
+	tr.node = createNode(job, instance, mc.SharedLabels().Get(model.SchemeLabel), mc.TargetLabels())
+	tr.metricBuilder = newMetricBuilder(mc, tr.scrapeMetrics, tr.logger)
 	tr.isNew = false
 	return nil
 }
@@ -155,20 +157,28 @@ func (tr *transaction) Rollback() error {
 	return nil
 }
 
-func createNode(job, instance, scheme string) *commonpb.Node {
-	splitted := strings.Split(instance, ":")
-	host, port := splitted[0], "80"
-	if len(splitted) >= 2 {
-		port = splitted[1]
+// createNode creates the node of the metrics scraped from a target. The target labels other than job and instance,
+// such as the ones added by relabeling, are added to the node attributes without replacing the port and the scheme.
+func createNode(job, instance, scheme string, targetLabels labels.Labels) *commonpb.Node {
+	// net.SplitHostPort keeps bracketed IPv6 instances such as "[::1]:8080"
+	// intact; an instance without a port falls back to the default one.
+	host, port, err := net.SplitHostPort(instance)
+	if err != nil {
+		host, port = instance, "80"
+	}
+	attributes := make(map[string]string, len(targetLabels)+2)
+	for _, l := range targetLabels {
+		if l.Name != model.JobLabel && l.Name != model.InstanceLabel {
+			attributes[l.Name] = l.Value
+		}
 	}
+	attributes[portAttr] = port
+	attributes[schemeAttr] = scheme
 	return &commonpb.Node{
 		ServiceInfo: &commonpb.ServiceInfo{Name: job},
 		Identifier: &commonpb.ProcessIdentifier{
 			HostName: host,
 		},
-		Attributes: map[string]string{
-			portAttr:   port,
-			schemeAttr: scheme,
-		},
+		Attributes: attributes,
 	}
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/scrape"
 )
@@ -34,7 +35,7 @@ func Test_transaction(t *testing.T) {
 
 	t.Run("Commit Without Adding", func(t *testing.T) {
 		mcon := newMockConsumer()
-		tr := newTransaction(context.Background(), nil, ms, mcon, testLogger)
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
 		if got := tr.Commit(); got != nil {
 			t.Errorf("expecting nil from Commit() but got err %v", got)
 		}
@@ -42,7 +43,7 @@ func Test_transaction(t *testing.T) {
 
 	t.Run("Rollback dose nothing", func(t *testing.T) {
 		mcon := newMockConsumer()
-		tr := newTransaction(context.Background(), nil, ms, mcon, testLogger)
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
 		if got := tr.Rollback(); got != nil {
 			t.Errorf("expecting nil from Rollback() but got err %v", got)
 		}
@@ -51,7 +52,7 @@ func Test_transaction(t *testing.T) {
 	badLabels := labels.Labels([]labels.Label{{Name: "foo", Value: "bar"}})
 	t.Run("Add One No Target", func(t *testing.T) {
 		mcon := newMockConsumer()
-		tr := newTransaction(context.Background(), nil, ms, mcon, testLogger)
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
 		if _, got := tr.Add(badLabels, time.Now().Unix()*1000, 1.0); got == nil {
 			t.Errorf("expecting error from Add() but got nil")
 		}
@@ -63,7 +64,7 @@ func Test_transaction(t *testing.T) {
 		{Name: "foo", Value: "bar"}})
 	t.Run("Add One Job not found", func(t *testing.T) {
 		mcon := newMockConsumer()
-		tr := newTransaction(context.Background(), nil, ms, mcon, testLogger)
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
 		if _, got := tr.Add(jobNotFoundLb, time.Now().Unix()*1000, 1.0); got == nil {
 			t.Errorf("expecting error from Add() but got nil")
 		}
@@ -74,7 +75,7 @@ func Test_transaction(t *testing.T) {
 		{Name: "__name__", Value: "foo"}})
 	t.Run("Add One Good", func(t *testing.T) {
 		mcon := newMockConsumer()
-		tr := newTransaction(context.Background(), nil, ms, mcon, testLogger)
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
 		if _, got := tr.Add(goodLabels, time.Now().Unix()*1000, 1.0); got != nil {
 			t.Errorf("expecting error == nil from Add() but got: %v\n", got)
 		}
@@ -82,7 +83,7 @@ func Test_transaction(t *testing.T) {
 			t.Errorf("expecting nil from Commit() but got err %v", got)
 		}
 
-		expected := createNode("test", "localhost:8080", "http")
+		expected := createNode("test", "localhost:8080", "http", nil)
 		md := mcon.md
 		if !reflect.DeepEqual(md.Node, expected) {
 			t.Errorf("generated node %v and expected node %v is different\n", md.Node, expected)
@@ -95,7 +96,7 @@ func Test_transaction(t *testing.T) {
 
 	t.Run("Drop NaN value", func(t *testing.T) {
 		mcon := newMockConsumer()
-		tr := newTransaction(context.Background(), nil, ms, mcon, testLogger)
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
 		if _, got := tr.Add(goodLabels, time.Now().Unix()*1000, math.NaN()); got != nil {
 			t.Errorf("expecting error == nil from Add() but got: %v\n", got)
 		}
@@ -108,4 +109,82 @@ func Test_transaction(t *testing.T) {
 		}
 	})
 
+	upLabels := labels.Labels([]labels.Label{{Name: "instance", Value: "localhost:8080"},
+		{Name: "job", Value: "test"},
+		{Name: "__name__", Value: "up"}})
+	t.Run("Scrape metrics disabled", func(t *testing.T) {
+		mcon := newMockConsumer()
+		tr := newTransaction(context.Background(), nil, false, ms, mcon, testLogger)
+		if _, got := tr.Add(upLabels, time.Now().Unix()*1000, 1.0); got != nil {
+			t.Errorf("expecting error == nil from Add() but got: %v\n", got)
+		}
+		if got := tr.Commit(); got != nil {
+			t.Errorf("expecting nil from Commit() but got err %v", got)
+		}
+
+		if mcon.md != nil {
+			t.Errorf("wanted nil, got %v\n", mcon.md)
+		}
+	})
+
+	t.Run("Scrape metrics enabled", func(t *testing.T) {
+		mcon := newMockConsumer()
+		tr := newTransaction(context.Background(), nil, true, ms, mcon, testLogger)
+		if _, got := tr.Add(upLabels, time.Now().Unix()*1000, 1.0); got != nil {
+			t.Errorf("expecting error == nil from Add() but got: %v\n", got)
+		}
+		if got := tr.Commit(); got != nil {
+			t.Errorf("expecting nil from Commit() but got err %v", got)
+		}
+
+		md := mcon.md
+		if md == nil || len(md.Metrics) != 1 {
+			t.Fatalf("expecting one metrics, but got %v\n", md)
+		}
+		if got := md.Metrics[0].MetricDescriptor.Type; got != metricspb.MetricDescriptor_GAUGE_DOUBLE {
+			t.Errorf("got type %v, want %v", got, metricspb.MetricDescriptor_GAUGE_DOUBLE)
+		}
+	})
+}
+
+func Test_createNode(t *testing.T) {
+	tests := []struct {
+		instance string
+		host     string
+		port     string
+	}{
+		{"localhost:8080", "localhost", "8080"},
+		{"localhost", "localhost", "80"},
+		{"10.0.0.1:9090", "10.0.0.1", "9090"},
+		{"[::1]:9090", "::1", "9090"},
+		{"[fe80::1%eth0]:9100", "fe80::1%eth0", "9100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.instance, func(t *testing.T) {
+			node := createNode("test", tt.instance, "http", nil)
+			if got := node.Identifier.HostName; got != tt.host {
+				t.Errorf("got host %q, want %q", got, tt.host)
+			}
+			if got := node.Attributes[portAttr]; got != tt.port {
+				t.Errorf("got port %q, want %q", got, tt.port)
+			}
+		})
+	}
+}
+
+func Test_createNodeTargetLabels(t *testing.T) {
+	targetLabels := labels.FromStrings(
+		"job", "test",
+		"instance", "localhost:8080",
+		"env", "prod",
+		"port", "1234")
+	node := createNode("test", "localhost:8080", "http", targetLabels)
+	want := map[string]string{
+		"env":      "prod",
+		portAttr:   "8080",
+		schemeAttr: "http",
+	}
+	if !reflect.DeepEqual(node.Attributes, want) {
+		t.Errorf("got attributes %v, want %v", node.Attributes, want)
+	}
 }
@@ -26,6 +26,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/leaderelection"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver/internal"
 
@@ -41,6 +42,15 @@ type Configuration struct {
 	BufferPeriod  time.Duration       `mapstructure:"buffer_period"`
 	BufferCount   int                 `mapstructure:"buffer_count"`
 	IncludeFilter map[string][]string `mapstructure:"include_filter"`
+	// LeaderElection is the name of a leader-election extension, when set
+	// the scraped metrics are only exported while this instance is the leader.
+	LeaderElection string `mapstructure:"leader_election"`
+	// Sharding splits the scrape targets across the replicas of the service.
+	Sharding ShardingConfig `mapstructure:"sharding"`
+	// ScrapeMetrics exports the up, scrape_duration_seconds,
+	// scrape_samples_scraped and scrape_samples_post_metric_relabeling
+	// metrics reported for each scrape of a target.
+	ScrapeMetrics bool `mapstructure:"scrape_metrics"`
 }
 
 type metricsMap map[string]bool
@@ -124,6 +134,16 @@ func newPrometheusReceiver(logger *zap.Logger, cfg *Configuration, next consumer
 	return pr
 }
 
+// isLeader reports whether the scraped metrics should be exported, that is
+// when no leader election is configured or this instance is the leader.
+func (pr *Preceiver) isLeader() bool {
+	if pr.cfg.LeaderElection == "" {
+		return true
+	}
+	elector := leaderelection.Lookup(pr.cfg.LeaderElection)
+	return elector != nil && elector.IsLeader()
+}
+
 const metricsSource string = "Prometheus"
 
 // MetricsSource returns the name of the metrics data source.
@@ -139,12 +159,21 @@ func (pr *Preceiver) StartMetricsReception(host receiver.Host) error {
 		c, cancel := context.WithCancel(ctx)
 		pr.cancel = cancel
 		jobsMap := internal.NewJobsMap(time.Duration(2 * time.Minute))
-		app := internal.NewOcaStore(c, pr.consumer, pr.logger.Sugar(), jobsMap)
+		app := internal.NewOcaStore(c, pr.consumer, pr.logger.Sugar(), jobsMap, pr.cfg.ScrapeMetrics, pr.isLeader)
 		// need to use a logger with the gokitLog interface
 		l := internal.NewZapToGokitLogAdapter(pr.logger)
 		scrapeManager := scrape.NewManager(l, app)
 		app.SetScrapeManager(scrapeManager)
 		discoveryManagerScrape := discovery.NewManager(ctx, l)
+		syncCh := discoveryManagerScrape.SyncCh()
+		sharder, err := pr.cfg.Sharding.sharder()
+		if err != nil {
+			host.ReportFatalError(err)
+			return
+		}
+		if sharder != nil {
+			syncCh = sharder.Run(c, syncCh)
+		}
 		go func() {
 			if err := discoveryManagerScrape.Run(); err != nil {
 				host.ReportFatalError(err)
@@ -162,7 +191,7 @@ func (pr *Preceiver) StartMetricsReception(host receiver.Host) error {
 			defer close(errsChan)
 			<-time.After(100 * time.Millisecond)
 			close(syncConfig)
-			if err := scrapeManager.Run(discoveryManagerScrape.SyncCh()); err != nil {
+			if err := scrapeManager.Run(syncCh); err != nil {
 				errsChan <- err
 			}
 		}()
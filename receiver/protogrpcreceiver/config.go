@@ -0,0 +1,173 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the proto-grpc receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+
+	// DescriptorSet is the path of the FileDescriptorSet describing the
+	// method, as written by protoc --include_imports --descriptor_set_out.
+	DescriptorSet string `mapstructure:"descriptor-set"`
+
+	// Method is the unary method accepting the telemetry, in the
+	// "package.Service/Method" form. Its requests are decoded with the
+	// descriptors of DescriptorSet and its responses are always empty.
+	Method string `mapstructure:"method"`
+
+	// Reflection registers the gRPC server reflection service, so that the
+	// clients like grpcurl can discover the method.
+	Reflection bool `mapstructure:"reflection"`
+
+	// ServiceName is the service name of the node of the received data.
+	ServiceName string `mapstructure:"service-name"`
+
+	// TimeUnit is the unit of the timestamps held by integer fields, one of
+	// s, ms, us or ns. The google.protobuf.Timestamp fields are always
+	// supported.
+	TimeUnit string `mapstructure:"time-unit"`
+
+	// Spans maps the requests to spans, the receiver can't be used in traces
+	// pipelines if it is not set.
+	Spans *SpanMapping `mapstructure:"spans"`
+
+	// Metrics maps the requests to metrics, the receiver can't be used in
+	// metrics pipelines if it is not set.
+	Metrics *MetricMapping `mapstructure:"metrics"`
+}
+
+// SpanMapping maps the fields of the requests to spans. Fields are selected
+// with dotted paths of their names, e.g. "request.method" or "events.0.id",
+// the keys of map fields are selected like field names.
+type SpanMapping struct {
+	// Items is the path of the repeated field of the request whose elements
+	// are each converted to a span, the paths of the other settings are
+	// relative to the elements. The whole request is converted to a single
+	// span if it is empty.
+	Items string `mapstructure:"items"`
+
+	// Name is the path of the span name, the method name is used if it is
+	// not set or not present.
+	Name string `mapstructure:"name"`
+
+	// TraceID, SpanID and ParentSpanID are the paths of the IDs, either bytes
+	// or hex strings. Random IDs are generated for the spans without trace
+	// or span ID.
+	TraceID      string `mapstructure:"trace-id"`
+	SpanID       string `mapstructure:"span-id"`
+	ParentSpanID string `mapstructure:"parent-span-id"`
+
+	// StartTime and EndTime are the paths of the span times. The time the
+	// request was received is used if the start time is not present, the
+	// start time if the end time is not present.
+	StartTime string `mapstructure:"start-time"`
+	EndTime   string `mapstructure:"end-time"`
+
+	// Attributes maps span attribute keys to the paths of their values.
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+// MetricMapping maps the fields of the requests to metric points, the
+// paths are selected as in SpanMapping.
+type MetricMapping struct {
+	// Items is the path of the repeated field of the request whose elements
+	// are each converted to a point, the paths of the other settings are
+	// relative to the elements. The whole request is converted to a single
+	// point if it is empty.
+	Items string `mapstructure:"items"`
+
+	// Name is the path of the metric name, the points without name are
+	// dropped.
+	Name string `mapstructure:"name"`
+
+	// Value is the path of the numeric value of the point, the points without
+	// value are dropped.
+	Value string `mapstructure:"value"`
+
+	// Timestamp is the path of the time of the point, the time the request
+	// was received is used if it is not present.
+	Timestamp string `mapstructure:"timestamp"`
+
+	// Type is the type of the metrics, gauge, the default, or cumulative. The
+	// start of the cumulative series is the time the receiver started.
+	Type string `mapstructure:"type"`
+
+	// Labels maps label keys to the paths of their values.
+	Labels map[string]string `mapstructure:"labels"`
+}
+
+const (
+	metricTypeGauge      = "gauge"
+	metricTypeCumulative = "cumulative"
+)
+
+func (cfg *Config) validate() error {
+	if cfg.DescriptorSet == "" {
+		return errors.New("descriptor-set is required")
+	}
+	if _, _, err := splitMethod(cfg.Method); err != nil {
+		return err
+	}
+	if _, ok := timeUnits[cfg.TimeUnit]; !ok {
+		return fmt.Errorf("time-unit %q is not one of s, ms, us or ns", cfg.TimeUnit)
+	}
+	if cfg.Spans != nil {
+		if err := validateLabels(cfg.Spans.Attributes); err != nil {
+			return fmt.Errorf("spans: %v", err)
+		}
+	}
+	if cfg.Metrics != nil {
+		if cfg.Metrics.Name == "" || cfg.Metrics.Value == "" {
+			return errors.New("metrics: name and value are required")
+		}
+		switch cfg.Metrics.Type {
+		case "", metricTypeGauge, metricTypeCumulative:
+		default:
+			return fmt.Errorf("metrics: type %q is not gauge or cumulative", cfg.Metrics.Type)
+		}
+		if err := validateLabels(cfg.Metrics.Labels); err != nil {
+			return fmt.Errorf("metrics: %v", err)
+		}
+	}
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	for key, path := range labels {
+		if key == "" || path == "" {
+			return errors.New("the mappings must have a key and a path")
+		}
+	}
+	return nil
+}
+
+// splitMethod returns the fully-qualified service name and the method name
+// of a method in the "package.Service/Method" form.
+func splitMethod(method string) (string, string, error) {
+	method = strings.TrimPrefix(method, "/")
+	i := strings.LastIndex(method, "/")
+	if i <= 0 || i == len(method)-1 {
+		return "", "", fmt.Errorf("method %q is not in the package.Service/Method form", method)
+	}
+	return method[:i], method[i+1:], nil
+}
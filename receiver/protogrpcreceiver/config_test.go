@@ -0,0 +1,134 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Receivers[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, len(cfg.Receivers), 2)
+
+	r0 := cfg.Receivers["proto-grpc"]
+	assert.Equal(t, r0, factory.CreateDefaultConfig())
+
+	r1 := cfg.Receivers["proto-grpc/acme"].(*Config)
+	assert.Equal(t, r1,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "proto-grpc/acme",
+				Endpoint: "0.0.0.0:55691",
+			},
+			DescriptorSet: "/etc/otelsvc/acme.pb",
+			Method:        "acme.telemetry.Collector/Export",
+			Reflection:    false,
+			ServiceName:   "acme",
+			TimeUnit:      "ms",
+			Spans: &SpanMapping{
+				Items:     "events",
+				Name:      "name",
+				TraceID:   "trace_id",
+				SpanID:    "span_id",
+				StartTime: "start_ms",
+				EndTime:   "end_ms",
+				Attributes: map[string]string{
+					"http.method": "request.method",
+					"level":       "level",
+				},
+			},
+			Metrics: &MetricMapping{
+				Items: "samples",
+				Name:  "metric",
+				Value: "value",
+				Type:  "cumulative",
+				Labels: map[string]string{
+					"host": "host",
+				},
+			},
+		})
+	assert.NoError(t, r1.validate())
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			DescriptorSet: "acme.pb",
+			Method:        "acme.telemetry.Collector/Export",
+			TimeUnit:      "ns",
+			Spans:         &SpanMapping{},
+			Metrics:       &MetricMapping{Name: "metric", Value: "value", Type: "gauge"},
+		}
+	}
+	require.NoError(t, valid().validate())
+
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{
+			name:   "NoDescriptorSet",
+			modify: func(cfg *Config) { cfg.DescriptorSet = "" },
+		},
+		{
+			name:   "NoService",
+			modify: func(cfg *Config) { cfg.Method = "Export" },
+		},
+		{
+			name:   "NoMethod",
+			modify: func(cfg *Config) { cfg.Method = "acme.telemetry.Collector/" },
+		},
+		{
+			name:   "InvalidTimeUnit",
+			modify: func(cfg *Config) { cfg.TimeUnit = "h" },
+		},
+		{
+			name:   "EmptyAttributeKey",
+			modify: func(cfg *Config) { cfg.Spans.Attributes = map[string]string{"": "method"} },
+		},
+		{
+			name:   "NoMetricValue",
+			modify: func(cfg *Config) { cfg.Metrics.Value = "" },
+		},
+		{
+			name:   "InvalidMetricType",
+			modify: func(cfg *Config) { cfg.Metrics.Type = "histogram" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			assert.Error(t, cfg.validate())
+		})
+	}
+}
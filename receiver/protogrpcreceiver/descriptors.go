@@ -0,0 +1,304 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+
+	"github.com/golang/protobuf/proto"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// descriptors indexes the messages and enums of a FileDescriptorSet by their
+// fully-qualified names, with the leading dot used by the type names of the
+// fields.
+type descriptors struct {
+	files    []*descpb.FileDescriptorProto
+	messages map[string]*descpb.DescriptorProto
+	enums    map[string]*descpb.EnumDescriptorProto
+}
+
+func loadDescriptors(path string) (*descriptors, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := &descpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(blob, set); err != nil {
+		return nil, fmt.Errorf("%s is not a FileDescriptorSet: %v", path, err)
+	}
+
+	d := &descriptors{
+		files:    set.File,
+		messages: make(map[string]*descpb.DescriptorProto),
+		enums:    make(map[string]*descpb.EnumDescriptorProto),
+	}
+	for _, file := range set.File {
+		prefix := "." + file.GetPackage()
+		if file.GetPackage() == "" {
+			prefix = ""
+		}
+		d.addMessages(prefix, file.MessageType)
+		for _, enum := range file.EnumType {
+			d.enums[prefix+"."+enum.GetName()] = enum
+		}
+	}
+	return d, nil
+}
+
+func (d *descriptors) addMessages(prefix string, messages []*descpb.DescriptorProto) {
+	for _, msg := range messages {
+		name := prefix + "." + msg.GetName()
+		d.messages[name] = msg
+		d.addMessages(name, msg.NestedType)
+		for _, enum := range msg.EnumType {
+			d.enums[name+"."+enum.GetName()] = enum
+		}
+	}
+}
+
+// method returns the file declaring the method and the descriptor of its
+// request message.
+func (d *descriptors) method(service, method string) (*descpb.FileDescriptorProto, *descpb.DescriptorProto, error) {
+	for _, file := range d.files {
+		for _, svc := range file.Service {
+			name := svc.GetName()
+			if file.GetPackage() != "" {
+				name = file.GetPackage() + "." + name
+			}
+			if name != service {
+				continue
+			}
+			for _, m := range svc.Method {
+				if m.GetName() != method {
+					continue
+				}
+				if m.GetClientStreaming() || m.GetServerStreaming() {
+					return nil, nil, fmt.Errorf("method %s/%s is not unary", service, method)
+				}
+				input, ok := d.messages[m.GetInputType()]
+				if !ok {
+					return nil, nil, fmt.Errorf("the descriptor set has no message %s", m.GetInputType())
+				}
+				return file, input, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("the descriptor set has no method %s/%s", service, method)
+}
+
+// registerFiles registers the files of the set unknown to the proto
+// package, which serves them to the gRPC server reflection.
+func (d *descriptors) registerFiles() error {
+	for _, file := range d.files {
+		if proto.FileDescriptor(file.GetName()) != nil {
+			continue
+		}
+		blob, err := proto.Marshal(file)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(blob); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		proto.RegisterFile(file.GetName(), buf.Bytes())
+	}
+	return nil
+}
+
+// decode decodes a message of the given type to a map of its field names to
+// their values: int64, uint64, float64, bool, string, []byte, the maps of
+// the messages and map fields, and the []interface{} of the repeated
+// fields. The enums are decoded to the names of their values, the unknown
+// fields are skipped.
+func (d *descriptors) decode(msg *descpb.DescriptorProto, b []byte) (map[string]interface{}, error) {
+	fields := make(map[int32]*descpb.FieldDescriptorProto, len(msg.Field))
+	for _, field := range msg.Field {
+		fields[field.GetNumber()] = field
+	}
+
+	values := make(map[string]interface{})
+	for len(b) > 0 {
+		key, n := proto.DecodeVarint(b)
+		if n == 0 {
+			return nil, errTruncated
+		}
+		b = b[n:]
+		num, wireType := int32(key>>3), int(key&7)
+
+		var raw uint64
+		var payload []byte
+		switch wireType {
+		case proto.WireVarint:
+			if raw, n = proto.DecodeVarint(b); n == 0 {
+				return nil, errTruncated
+			}
+		case proto.WireFixed64:
+			if n = 8; len(b) < n {
+				return nil, errTruncated
+			}
+			raw = binary.LittleEndian.Uint64(b)
+		case proto.WireFixed32:
+			if n = 4; len(b) < n {
+				return nil, errTruncated
+			}
+			raw = uint64(binary.LittleEndian.Uint32(b))
+		case proto.WireBytes:
+			size, m := proto.DecodeVarint(b)
+			if m == 0 || uint64(len(b)-m) < size {
+				return nil, errTruncated
+			}
+			payload, n = b[m:m+int(size)], m+int(size)
+		default:
+			return nil, fmt.Errorf("wire type %d is not supported", wireType)
+		}
+		b = b[n:]
+
+		field, ok := fields[num]
+		if !ok {
+			continue
+		}
+		if err := d.decodeField(values, field, wireType, raw, payload); err != nil {
+			return nil, fmt.Errorf("field %s: %v", field.GetName(), err)
+		}
+	}
+	return values, nil
+}
+
+var errTruncated = errors.New("the message is truncated")
+
+func (d *descriptors) decodeField(values map[string]interface{}, field *descpb.FieldDescriptorProto, wireType int, raw uint64, payload []byte) error {
+	name := field.GetName()
+	repeated := field.GetLabel() == descpb.FieldDescriptorProto_LABEL_REPEATED
+
+	switch field.GetType() {
+	case descpb.FieldDescriptorProto_TYPE_MESSAGE:
+		msg, ok := d.messages[field.GetTypeName()]
+		if !ok {
+			return fmt.Errorf("unknown message %s", field.GetTypeName())
+		}
+		v, err := d.decode(msg, payload)
+		if err != nil {
+			return err
+		}
+		if msg.GetOptions().GetMapEntry() {
+			entries, _ := values[name].(map[string]interface{})
+			if entries == nil {
+				entries = make(map[string]interface{})
+				values[name] = entries
+			}
+			entries[fmt.Sprint(v["key"])] = v["value"]
+			return nil
+		}
+		setValue(values, name, v, repeated)
+	case descpb.FieldDescriptorProto_TYPE_STRING:
+		setValue(values, name, string(payload), repeated)
+	case descpb.FieldDescriptorProto_TYPE_BYTES:
+		setValue(values, name, append([]byte(nil), payload...), repeated)
+	case descpb.FieldDescriptorProto_TYPE_GROUP:
+		return errors.New("groups are not supported")
+	default:
+		if wireType != proto.WireBytes {
+			setValue(values, name, d.scalar(field, raw), repeated)
+			return nil
+		}
+		// Packed repeated scalars.
+		for len(payload) > 0 {
+			var n int
+			switch wireTypeOf(field.GetType()) {
+			case proto.WireFixed64:
+				if n = 8; len(payload) < n {
+					return errTruncated
+				}
+				raw = binary.LittleEndian.Uint64(payload)
+			case proto.WireFixed32:
+				if n = 4; len(payload) < n {
+					return errTruncated
+				}
+				raw = uint64(binary.LittleEndian.Uint32(payload))
+			default:
+				if raw, n = proto.DecodeVarint(payload); n == 0 {
+					return errTruncated
+				}
+			}
+			payload = payload[n:]
+			setValue(values, name, d.scalar(field, raw), true)
+		}
+	}
+	return nil
+}
+
+func setValue(values map[string]interface{}, name string, v interface{}, repeated bool) {
+	if !repeated {
+		values[name] = v
+		return
+	}
+	list, _ := values[name].([]interface{})
+	values[name] = append(list, v)
+}
+
+func wireTypeOf(t descpb.FieldDescriptorProto_Type) int {
+	switch t {
+	case descpb.FieldDescriptorProto_TYPE_DOUBLE, descpb.FieldDescriptorProto_TYPE_FIXED64, descpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return proto.WireFixed64
+	case descpb.FieldDescriptorProto_TYPE_FLOAT, descpb.FieldDescriptorProto_TYPE_FIXED32, descpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return proto.WireFixed32
+	}
+	return proto.WireVarint
+}
+
+// scalar converts the raw value of a numeric, bool or enum field.
+func (d *descriptors) scalar(field *descpb.FieldDescriptorProto, raw uint64) interface{} {
+	switch field.GetType() {
+	case descpb.FieldDescriptorProto_TYPE_DOUBLE:
+		return math.Float64frombits(raw)
+	case descpb.FieldDescriptorProto_TYPE_FLOAT:
+		return float64(math.Float32frombits(uint32(raw)))
+	case descpb.FieldDescriptorProto_TYPE_INT64, descpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return int64(raw)
+	case descpb.FieldDescriptorProto_TYPE_INT32, descpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return int64(int32(raw))
+	case descpb.FieldDescriptorProto_TYPE_SINT64:
+		return int64(raw>>1) ^ -int64(raw&1)
+	case descpb.FieldDescriptorProto_TYPE_SINT32:
+		return int64(int32(uint32(raw)>>1) ^ -int32(raw&1))
+	case descpb.FieldDescriptorProto_TYPE_UINT32, descpb.FieldDescriptorProto_TYPE_FIXED32:
+		return uint64(uint32(raw))
+	case descpb.FieldDescriptorProto_TYPE_BOOL:
+		return raw != 0
+	case descpb.FieldDescriptorProto_TYPE_ENUM:
+		if enum, ok := d.enums[field.GetTypeName()]; ok {
+			for _, value := range enum.Value {
+				if int64(value.GetNumber()) == int64(int32(raw)) {
+					return value.GetName()
+				}
+			}
+		}
+		return int64(int32(raw))
+	}
+	// uint64 and fixed64.
+	return raw
+}
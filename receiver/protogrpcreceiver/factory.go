@@ -0,0 +1,134 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+// This file implements factory for the proto-grpc receiver.
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "proto-grpc"
+
+	defaultBindEndpoint = "127.0.0.1:55690"
+)
+
+// Factory is the factory for the proto-grpc receiver.
+type Factory struct {
+}
+
+// Type gets the type of the Receiver config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CustomUnmarshaler returns nil because we don't need custom unmarshaling for this config.
+func (f *Factory) CustomUnmarshaler() receiver.CustomUnmarshaler {
+	return nil
+}
+
+// CreateDefaultConfig creates the default configuration for the proto-grpc
+// receiver.
+func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
+	return &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal:  typeStr,
+			NameVal:  typeStr,
+			Endpoint: defaultBindEndpoint,
+		},
+		Reflection: true,
+		TimeUnit:   "ns",
+	}
+}
+
+// CreateTraceReceiver creates a trace receiver based on provided config.
+func (f *Factory) CreateTraceReceiver(
+	ctx context.Context,
+	logger *zap.Logger,
+	cfg configmodels.Receiver,
+	nextConsumer consumer.TraceConsumer,
+) (receiver.TraceReceiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	rCfg := cfg.(*Config)
+	if rCfg.Spans == nil {
+		return nil, fmt.Errorf("%s receiver has no spans mapping", rCfg.Name())
+	}
+	r, err := f.createReceiver(rCfg)
+	if err != nil {
+		return nil, err
+	}
+	r.traceConsumer = nextConsumer
+	return r, nil
+}
+
+// CreateMetricsReceiver creates a metrics receiver based on provided config.
+func (f *Factory) CreateMetricsReceiver(
+	logger *zap.Logger,
+	cfg configmodels.Receiver,
+	nextConsumer consumer.MetricsConsumer,
+) (receiver.MetricsReceiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	rCfg := cfg.(*Config)
+	if rCfg.Metrics == nil {
+		return nil, fmt.Errorf("%s receiver has no metrics mapping", rCfg.Name())
+	}
+	r, err := f.createReceiver(rCfg)
+	if err != nil {
+		return nil, err
+	}
+	r.metricsConsumer = nextConsumer
+	return r, nil
+}
+
+// createReceiver returns the receiver of the configuration, the traces and
+// metrics pipelines share it since they share the endpoint.
+func (f *Factory) createReceiver(rCfg *Config) (*Receiver, error) {
+	receiversMu.Lock()
+	defer receiversMu.Unlock()
+
+	r, ok := receivers[rCfg]
+	if !ok {
+		if err := rCfg.validate(); err != nil {
+			return nil, fmt.Errorf("invalid config of %s receiver: %v", rCfg.Name(), err)
+		}
+		var err error
+		if r, err = New(rCfg); err != nil {
+			return nil, fmt.Errorf("cannot create %s receiver: %v", rCfg.Name(), err)
+		}
+		receivers[rCfg] = r
+	}
+	return r, nil
+}
+
+// receivers are the receivers created by the factory, by configuration.
+var (
+	receiversMu sync.Mutex
+	receivers   = map[*Config]*Receiver{}
+)
@@ -0,0 +1,70 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+}
+
+func TestCreateReceiver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protogrpcreceiver")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	factory := &Factory{}
+	cfg := testConfig(t, dir)
+
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, exportertest.NewNopTraceExporter())
+	require.NoError(t, err, "receiver creation failed")
+	mReceiver, err := factory.CreateMetricsReceiver(zap.NewNop(), cfg, exportertest.NewNopMetricsExporter())
+	require.NoError(t, err, "receiver creation failed")
+	assert.True(t, tReceiver.(*Receiver) == mReceiver.(*Receiver), "the pipelines must share the receiver")
+
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err)
+}
+
+func TestCreateReceiver_InvalidConfig(t *testing.T) {
+	factory := &Factory{}
+
+	// The default configuration has neither descriptor set nor mappings.
+	cfg := factory.CreateDefaultConfig().(*Config)
+	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, exportertest.NewNopTraceExporter())
+	assert.Error(t, err)
+
+	cfg.Spans = &SpanMapping{}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, exportertest.NewNopTraceExporter())
+	assert.Error(t, err)
+
+	cfg.DescriptorSet = "testdata/missing.pb"
+	cfg.Method = "acme.telemetry.Collector/Export"
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, exportertest.NewNopTraceExporter())
+	assert.Error(t, err)
+}
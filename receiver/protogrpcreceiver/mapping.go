@@ -0,0 +1,348 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// timeUnits are the durations of the units of the integer timestamps.
+var timeUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+// converter converts the decoded requests to spans and metrics.
+type converter struct {
+	cfg        *Config
+	methodName string
+	timeUnit   time.Duration
+	// started is the start of the cumulative series.
+	started time.Time
+}
+
+func (c *converter) node() *commonpb.Node {
+	return &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: c.cfg.ServiceName},
+	}
+}
+
+// items returns the elements of the repeated field at the path, or the
+// request itself if the path is empty.
+func items(request map[string]interface{}, path string) []map[string]interface{} {
+	if path == "" {
+		return []map[string]interface{}{request}
+	}
+	v, ok := lookup(request, path)
+	if !ok {
+		return nil
+	}
+	var result []map[string]interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		result = append(result, v)
+	case []interface{}:
+		for _, item := range v {
+			if item, ok := item.(map[string]interface{}); ok {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// toTraceData converts the request to spans, see SpanMapping.
+func (c *converter) toTraceData(request map[string]interface{}, received time.Time) (consumerdata.TraceData, error) {
+	mapping := c.cfg.Spans
+	elements := items(request, mapping.Items)
+	td := consumerdata.TraceData{
+		Node:  c.node(),
+		Spans: make([]*tracepb.Span, 0, len(elements)),
+	}
+	keys := sortedKeys(mapping.Attributes)
+
+	for _, item := range elements {
+		traceID := c.id(item, mapping.TraceID, 16)
+		spanID := c.id(item, mapping.SpanID, 8)
+		if traceID == nil || spanID == nil {
+			var err error
+			if traceID, spanID, err = newIDs(traceID, spanID); err != nil {
+				return td, err
+			}
+		}
+
+		start := c.timestamp(item, mapping.StartTime, received)
+		end := c.timestamp(item, mapping.EndTime, start)
+		span := &tracepb.Span{
+			TraceId:      traceID,
+			SpanId:       spanID,
+			ParentSpanId: c.id(item, mapping.ParentSpanID, 8),
+			Name:         &tracepb.TruncatableString{Value: c.methodName},
+			StartTime:    toTimestamp(start),
+			EndTime:      toTimestamp(end),
+		}
+		if v, ok := lookup(item, mapping.Name); ok {
+			if name := toString(v); name != "" {
+				span.Name.Value = name
+			}
+		}
+
+		attrs := make(map[string]*tracepb.AttributeValue)
+		for _, key := range keys {
+			if v, ok := lookup(item, mapping.Attributes[key]); ok {
+				attrs[key] = toAttributeValue(v)
+			}
+		}
+		if len(attrs) > 0 {
+			span.Attributes = &tracepb.Span_Attributes{AttributeMap: attrs}
+		}
+		td.Spans = append(td.Spans, span)
+	}
+	return td, nil
+}
+
+// toMetricsData converts the request to metrics, one per name with a time
+// series per point, see MetricMapping. It returns the number of points
+// dropped for lack of name or value.
+func (c *converter) toMetricsData(request map[string]interface{}, received time.Time) (consumerdata.MetricsData, int) {
+	mapping := c.cfg.Metrics
+	md := consumerdata.MetricsData{Node: c.node()}
+	keys := sortedKeys(mapping.Labels)
+	labelKeys := make([]*metricspb.LabelKey, 0, len(keys))
+	for _, key := range keys {
+		labelKeys = append(labelKeys, &metricspb.LabelKey{Key: key})
+	}
+
+	metricType := metricspb.MetricDescriptor_GAUGE_DOUBLE
+	var startTimestamp *timestamp.Timestamp
+	if mapping.Type == metricTypeCumulative {
+		metricType = metricspb.MetricDescriptor_CUMULATIVE_DOUBLE
+		startTimestamp = toTimestamp(c.started)
+	}
+
+	metrics := make(map[string]*metricspb.Metric)
+	dropped := 0
+	for _, item := range items(request, mapping.Items) {
+		var name string
+		if v, ok := lookup(item, mapping.Name); ok {
+			name = toString(v)
+		}
+		v, _ := lookup(item, mapping.Value)
+		value, ok := toFloat(v)
+		if name == "" || !ok {
+			dropped++
+			continue
+		}
+
+		metric, ok := metrics[name]
+		if !ok {
+			metric = &metricspb.Metric{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      name,
+					Type:      metricType,
+					LabelKeys: labelKeys,
+				},
+			}
+			metrics[name] = metric
+			md.Metrics = append(md.Metrics, metric)
+		}
+
+		labelValues := make([]*metricspb.LabelValue, 0, len(keys))
+		for _, key := range keys {
+			if v, ok := lookup(item, mapping.Labels[key]); ok {
+				labelValues = append(labelValues, &metricspb.LabelValue{Value: toString(v), HasValue: true})
+			} else {
+				labelValues = append(labelValues, &metricspb.LabelValue{})
+			}
+		}
+		metric.Timeseries = append(metric.Timeseries, &metricspb.TimeSeries{
+			StartTimestamp: startTimestamp,
+			LabelValues:    labelValues,
+			Points: []*metricspb.Point{{
+				Timestamp: toTimestamp(c.timestamp(item, mapping.Timestamp, received)),
+				Value:     &metricspb.Point_DoubleValue{DoubleValue: value},
+			}},
+		})
+	}
+	return md, dropped
+}
+
+// id returns the ID at the path, either bytes or a hex string, nil if it is
+// not present or not of the given size.
+func (c *converter) id(item map[string]interface{}, path string, size int) []byte {
+	v, ok := lookup(item, path)
+	if !ok {
+		return nil
+	}
+	var id []byte
+	switch v := v.(type) {
+	case []byte:
+		id = v
+	case string:
+		id, _ = hex.DecodeString(v)
+	}
+	if len(id) != size {
+		return nil
+	}
+	return id
+}
+
+// timestamp returns the time at the path, either a google.protobuf.Timestamp
+// or an integer in the time unit, else the default time.
+func (c *converter) timestamp(item map[string]interface{}, path string, def time.Time) time.Time {
+	v, ok := lookup(item, path)
+	if !ok {
+		return def
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		seconds, _ := v["seconds"].(int64)
+		nanos, _ := v["nanos"].(int64)
+		return time.Unix(seconds, nanos)
+	case int64:
+		return time.Unix(0, 0).Add(time.Duration(v) * c.timeUnit)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return time.Unix(0, 0).Add(time.Duration(v) * c.timeUnit)
+		}
+	}
+	return def
+}
+
+// lookup returns the value at the dotted path, numeric segments index the
+// repeated fields.
+func lookup(item map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var cur interface{} = item
+	for _, segment := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toTimestamp(t time.Time) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return hex.EncodeToString(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	blob, _ := json.Marshal(v)
+	return string(blob)
+}
+
+// toAttributeValue converts a field value to an attribute, the bytes are hex
+// encoded and the messages and repeated fields are kept as their JSON
+// encoding.
+func toAttributeValue(v interface{}) *tracepb.AttributeValue {
+	switch v := v.(type) {
+	case bool:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: v}}
+	case int64:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: v}}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: int64(v)}}
+		}
+	case float64:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: v}}
+	}
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: toString(v)},
+		},
+	}
+}
+
+// newIDs returns the IDs, replacing the nil ones by random ones.
+func newIDs(traceID, spanID []byte) ([]byte, []byte, error) {
+	ids := make([]byte, 24)
+	if _, err := rand.Read(ids); err != nil {
+		return nil, nil, err
+	}
+	if traceID == nil {
+		traceID = ids[:16]
+	}
+	if spanID == nil {
+		spanID = ids[16:]
+	}
+	return traceID, spanID, nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protogrpcreceiver receives the telemetry of proprietary protocols
+// sent to a unary gRPC method, decoding the requests with the descriptors of
+// a FileDescriptorSet and mapping their fields to spans and metrics with the
+// configuration, without generated code.
+package protogrpcreceiver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+const source = "ProtoGRPC"
+
+// Receiver serves the configured gRPC method, it is shared by the traces and
+// metrics pipelines of its configuration.
+type Receiver struct {
+	// mu protects the fields of this struct
+	mu sync.Mutex
+
+	cfg         *Config
+	descs       *descriptors
+	input       *descpb.DescriptorProto
+	serviceDesc grpc.ServiceDesc
+	converter   converter
+
+	traceConsumer   consumer.TraceConsumer
+	metricsConsumer consumer.MetricsConsumer
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	server    *grpc.Server
+}
+
+var _ receiver.TraceReceiver = (*Receiver)(nil)
+var _ receiver.MetricsReceiver = (*Receiver)(nil)
+
+// New creates a receiver of the method of the configuration, loading the
+// descriptors of its descriptor set.
+func New(cfg *Config) (*Receiver, error) {
+	service, method, err := splitMethod(cfg.Method)
+	if err != nil {
+		return nil, err
+	}
+	descs, err := loadDescriptors(cfg.DescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+	file, input, err := descs.method(service, method)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Receiver{
+		cfg:   cfg,
+		descs: descs,
+		input: input,
+		converter: converter{
+			cfg:        cfg,
+			methodName: method,
+			timeUnit:   timeUnits[cfg.TimeUnit],
+		},
+	}
+	r.serviceDesc = grpc.ServiceDesc{
+		ServiceName: service,
+		// Any handler is accepted, the method is served by the receiver.
+		HandlerType: (*interface{})(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: method, Handler: r.handle}},
+		Streams:     []grpc.StreamDesc{},
+		// The reflection looks the descriptor of the file up by its name.
+		Metadata: file.GetName(),
+	}
+	return r, nil
+}
+
+// TraceSource returns the name of the trace data source.
+func (r *Receiver) TraceSource() string {
+	return source
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *Receiver) MetricsSource() string {
+	return source
+}
+
+// StartTraceReception starts the gRPC server, if not started yet by the
+// metrics pipelines.
+func (r *Receiver) StartTraceReception(host receiver.Host) error {
+	return r.start(host)
+}
+
+// StartMetricsReception starts the gRPC server, if not started yet by the
+// traces pipelines.
+func (r *Receiver) StartMetricsReception(host receiver.Host) error {
+	return r.start(host)
+}
+
+// StopTraceReception stops the gRPC server, it also stops the metrics
+// reception.
+func (r *Receiver) StopTraceReception() error {
+	return r.stop()
+}
+
+// StopMetricsReception stops the gRPC server, it also stops the trace
+// reception.
+func (r *Receiver) StopMetricsReception() error {
+	return r.stop()
+}
+
+func (r *Receiver) start(host receiver.Host) error {
+	if host == nil {
+		return errors.New("nil host")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := oterr.ErrAlreadyStarted
+	r.startOnce.Do(func() {
+		if r.cfg.Reflection {
+			if err = r.descs.registerFiles(); err != nil {
+				return
+			}
+		}
+		ln, lerr := net.Listen("tcp", r.cfg.Endpoint)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+
+		r.converter.started = time.Now()
+		r.server = observability.GRPCServerWithObservabilityEnabled()
+		r.server.RegisterService(&r.serviceDesc, r)
+		if r.cfg.Reflection {
+			reflection.Register(r.server)
+		}
+		go func() {
+			if serr := r.server.Serve(ln); serr != nil {
+				host.ReportFatalError(serr)
+			}
+		}()
+		err = nil
+	})
+	if err == oterr.ErrAlreadyStarted {
+		// The other pipelines share the server.
+		return nil
+	}
+	return err
+}
+
+func (r *Receiver) stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopOnce.Do(func() {
+		if r.server != nil {
+			r.server.Stop()
+		}
+	})
+	return nil
+}
+
+// handle serves the method, its response is always empty.
+func (r *Receiver) handle(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := &rawMessage{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return r.export(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     r,
+		FullMethod: "/" + r.serviceDesc.ServiceName + "/" + r.serviceDesc.Methods[0].MethodName,
+	}
+	return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return r.export(ctx, req.(*rawMessage))
+	})
+}
+
+func (r *Receiver) export(parentCtx context.Context, req *rawMessage) (interface{}, error) {
+	ctx, span := trace.StartSpan(parentCtx, "ProtoGRPCReceiver.Export")
+	defer span.End()
+
+	// If the starting RPC has a parent span, then add it as a parent link.
+	observability.SetParentLink(parentCtx, span)
+
+	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, r.cfg.Name())
+	received := time.Now()
+
+	request, err := r.descs.decode(r.input, req.data)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeInvalidArgument, Message: err.Error()})
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", r.input.GetName(), err)
+	}
+
+	if r.traceConsumer != nil {
+		td, err := r.converter.toTraceData(request, received)
+		if err == nil {
+			td.SourceFormat = typeStr
+			err = r.traceConsumer.ConsumeTraceData(ctxWithReceiverName, td)
+		}
+		if err != nil {
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+			observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(td.Spans))
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(td.Spans), 0)
+	}
+
+	if r.metricsConsumer != nil {
+		md, dropped := r.converter.toMetricsData(request, received)
+		timeSeries := 0
+		for _, metric := range md.Metrics {
+			timeSeries += len(metric.Timeseries)
+		}
+		if err := r.metricsConsumer.ConsumeMetricsData(ctxWithReceiverName, md); err != nil {
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+			observability.RecordMetricsForMetricsReceiver(ctxWithReceiverName, 0, timeSeries+dropped)
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		observability.RecordMetricsForMetricsReceiver(ctxWithReceiverName, timeSeries, dropped)
+	}
+	return &rawMessage{}, nil
+}
+
+// rawMessage keeps the encoding of the requests, decoded by the receiver
+// with the descriptors, and encodes the empty responses.
+type rawMessage struct {
+	data []byte
+}
+
+var _ proto.Message = (*rawMessage)(nil)
+var _ proto.Unmarshaler = (*rawMessage)(nil)
+var _ proto.Marshaler = (*rawMessage)(nil)
+
+func (m *rawMessage) Reset()         { m.data = nil }
+func (m *rawMessage) String() string { return "" }
+func (m *rawMessage) ProtoMessage()  {}
+
+func (m *rawMessage) Unmarshal(data []byte) error {
+	m.data = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *rawMessage) Marshal() ([]byte, error) {
+	return m.data, nil
+}
@@ -0,0 +1,377 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogrpcreceiver
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/descriptor"
+	"github.com/golang/protobuf/proto"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+// The test messages are encoded by the proto package from their struct tags,
+// they match the messages of testFileDescriptor.
+
+type testRequest struct {
+	Method string `protobuf:"bytes,1,opt,name=method,proto3"`
+}
+
+func (m *testRequest) Reset()         { *m = testRequest{} }
+func (m *testRequest) String() string { return proto.CompactTextString(m) }
+func (*testRequest) ProtoMessage()    {}
+
+type testEvent struct {
+	Name    string               `protobuf:"bytes,1,opt,name=name,proto3"`
+	TraceID []byte               `protobuf:"bytes,2,opt,name=trace_id,proto3"`
+	SpanID  []byte               `protobuf:"bytes,3,opt,name=span_id,proto3"`
+	StartMs int64                `protobuf:"varint,4,opt,name=start_ms,proto3"`
+	End     *timestamp.Timestamp `protobuf:"bytes,5,opt,name=end,proto3"`
+	Request *testRequest         `protobuf:"bytes,6,opt,name=request,proto3"`
+	Level   int32                `protobuf:"varint,7,opt,name=level,proto3"`
+	Tags    map[string]string    `protobuf:"bytes,8,rep,name=tags,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Codes   []int32              `protobuf:"zigzag32,9,rep,packed,name=codes,proto3"`
+}
+
+func (m *testEvent) Reset()         { *m = testEvent{} }
+func (m *testEvent) String() string { return proto.CompactTextString(m) }
+func (*testEvent) ProtoMessage()    {}
+
+type testSample struct {
+	Metric string  `protobuf:"bytes,1,opt,name=metric,proto3"`
+	Value  float64 `protobuf:"fixed64,2,opt,name=value,proto3"`
+	Host   string  `protobuf:"bytes,3,opt,name=host,proto3"`
+	TsMs   uint64  `protobuf:"fixed64,4,opt,name=ts_ms,proto3"`
+}
+
+func (m *testSample) Reset()         { *m = testSample{} }
+func (m *testSample) String() string { return proto.CompactTextString(m) }
+func (*testSample) ProtoMessage()    {}
+
+type testExportRequest struct {
+	Events  []*testEvent  `protobuf:"bytes,1,rep,name=events,proto3"`
+	Samples []*testSample `protobuf:"bytes,2,rep,name=samples,proto3"`
+}
+
+func (m *testExportRequest) Reset()         { *m = testExportRequest{} }
+func (m *testExportRequest) String() string { return proto.CompactTextString(m) }
+func (*testExportRequest) ProtoMessage()    {}
+
+func testField(name string, number int32, typ descpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descpb.FieldDescriptorProto {
+	label := descpb.FieldDescriptorProto_LABEL_OPTIONAL
+	if repeated {
+		label = descpb.FieldDescriptorProto_LABEL_REPEATED
+	}
+	field := &descpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  label.Enum(),
+		Type:   typ.Enum(),
+	}
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+	return field
+}
+
+// testFileDescriptor is the descriptor of acme/telemetry.proto.
+func testFileDescriptor() *descpb.FileDescriptorProto {
+	return &descpb.FileDescriptorProto{
+		Name:       proto.String("acme/telemetry.proto"),
+		Package:    proto.String("acme.telemetry"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		Syntax:     proto.String("proto3"),
+		EnumType: []*descpb.EnumDescriptorProto{{
+			Name: proto.String("Level"),
+			Value: []*descpb.EnumValueDescriptorProto{
+				{Name: proto.String("INFO"), Number: proto.Int32(0)},
+				{Name: proto.String("ERROR"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descpb.DescriptorProto{
+			{
+				Name: proto.String("Request"),
+				Field: []*descpb.FieldDescriptorProto{
+					testField("method", 1, descpb.FieldDescriptorProto_TYPE_STRING, "", false),
+				},
+			},
+			{
+				Name: proto.String("Event"),
+				Field: []*descpb.FieldDescriptorProto{
+					testField("name", 1, descpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					testField("trace_id", 2, descpb.FieldDescriptorProto_TYPE_BYTES, "", false),
+					testField("span_id", 3, descpb.FieldDescriptorProto_TYPE_BYTES, "", false),
+					testField("start_ms", 4, descpb.FieldDescriptorProto_TYPE_INT64, "", false),
+					testField("end", 5, descpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp", false),
+					testField("request", 6, descpb.FieldDescriptorProto_TYPE_MESSAGE, ".acme.telemetry.Request", false),
+					testField("level", 7, descpb.FieldDescriptorProto_TYPE_ENUM, ".acme.telemetry.Level", false),
+					testField("tags", 8, descpb.FieldDescriptorProto_TYPE_MESSAGE, ".acme.telemetry.Event.TagsEntry", true),
+					testField("codes", 9, descpb.FieldDescriptorProto_TYPE_SINT32, "", true),
+				},
+				NestedType: []*descpb.DescriptorProto{{
+					Name: proto.String("TagsEntry"),
+					Field: []*descpb.FieldDescriptorProto{
+						testField("key", 1, descpb.FieldDescriptorProto_TYPE_STRING, "", false),
+						testField("value", 2, descpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					},
+					Options: &descpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{
+				Name: proto.String("Sample"),
+				Field: []*descpb.FieldDescriptorProto{
+					testField("metric", 1, descpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					testField("value", 2, descpb.FieldDescriptorProto_TYPE_DOUBLE, "", false),
+					testField("host", 3, descpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					testField("ts_ms", 4, descpb.FieldDescriptorProto_TYPE_FIXED64, "", false),
+				},
+			},
+			{
+				Name: proto.String("ExportRequest"),
+				Field: []*descpb.FieldDescriptorProto{
+					testField("events", 1, descpb.FieldDescriptorProto_TYPE_MESSAGE, ".acme.telemetry.Event", true),
+					testField("samples", 2, descpb.FieldDescriptorProto_TYPE_MESSAGE, ".acme.telemetry.Sample", true),
+				},
+			},
+			{Name: proto.String("ExportResponse")},
+		},
+		Service: []*descpb.ServiceDescriptorProto{{
+			Name: proto.String("Collector"),
+			Method: []*descpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("Export"),
+					InputType:  proto.String(".acme.telemetry.ExportRequest"),
+					OutputType: proto.String(".acme.telemetry.ExportResponse"),
+				},
+				{
+					Name:            proto.String("Stream"),
+					InputType:       proto.String(".acme.telemetry.ExportRequest"),
+					OutputType:      proto.String(".acme.telemetry.ExportResponse"),
+					ClientStreaming: proto.Bool(true),
+				},
+			},
+		}},
+	}
+}
+
+// writeDescriptorSet writes the descriptor set of acme/telemetry.proto and
+// its imports, as protoc --include_imports does, to the directory and returns
+// its path.
+func writeDescriptorSet(t *testing.T, dir string) string {
+	timestampFile, _ := descriptor.ForMessage(&timestamp.Timestamp{})
+	blob, err := proto.Marshal(&descpb.FileDescriptorSet{
+		File: []*descpb.FileDescriptorProto{timestampFile, testFileDescriptor()},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "acme.pb")
+	require.NoError(t, ioutil.WriteFile(path, blob, 0600))
+	return path
+}
+
+func testConfig(t *testing.T, dir string) *Config {
+	return &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal:  typeStr,
+			NameVal:  typeStr,
+			Endpoint: testutils.GetAvailableLocalAddress(t),
+		},
+		DescriptorSet: writeDescriptorSet(t, dir),
+		Method:        "acme.telemetry.Collector/Export",
+		Reflection:    true,
+		ServiceName:   "acme",
+		TimeUnit:      "ms",
+		Spans: &SpanMapping{
+			Items:     "events",
+			Name:      "name",
+			TraceID:   "trace_id",
+			SpanID:    "span_id",
+			StartTime: "start_ms",
+			EndTime:   "end",
+			Attributes: map[string]string{
+				"http.method": "request.method",
+				"level":       "level",
+				"region":      "tags.region",
+				"code":        "codes.1",
+			},
+		},
+		Metrics: &MetricMapping{
+			Items:     "samples",
+			Name:      "metric",
+			Value:     "value",
+			Timestamp: "ts_ms",
+			Labels:    map[string]string{"host": "host"},
+		},
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protogrpcreceiver")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := testConfig(t, dir)
+	r, err := New(cfg)
+	require.NoError(t, err)
+	traceSink := new(exportertest.SinkTraceExporter)
+	metricsSink := new(exportertest.SinkMetricsExporter)
+	r.traceConsumer = traceSink
+	r.metricsConsumer = metricsSink
+
+	require.NoError(t, r.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+	defer r.StopTraceReception()
+
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	traceID, _ := hex.DecodeString("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := hex.DecodeString("0102030405060708")
+	req := &testExportRequest{
+		Events: []*testEvent{
+			{
+				Name:    "checkout",
+				TraceID: traceID,
+				SpanID:  spanID,
+				StartMs: 1500000000000,
+				End:     &timestamp.Timestamp{Seconds: 1500000001, Nanos: 500},
+				Request: &testRequest{Method: "POST"},
+				Level:   1,
+				Tags:    map[string]string{"region": "eu"},
+				Codes:   []int32{-1, -7},
+			},
+			{},
+		},
+		Samples: []*testSample{
+			{Metric: "queue_size", Value: 12, Host: "a", TsMs: 1500000000000},
+			{Metric: "queue_size", Value: 3},
+			{Value: 1},
+		},
+	}
+	require.NoError(t, conn.Invoke(context.Background(), "/acme.telemetry.Collector/Export", req, &testRequest{}))
+
+	traces := traceSink.AllTraces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, "acme", traces[0].Node.ServiceInfo.Name)
+	require.Len(t, traces[0].Spans, 2)
+	span := traces[0].Spans[0]
+	assert.Equal(t, "checkout", span.Name.Value)
+	assert.Equal(t, traceID, span.TraceId)
+	assert.Equal(t, spanID, span.SpanId)
+	assert.Equal(t, int64(1500000000), span.StartTime.Seconds)
+	assert.Equal(t, int64(1500000001), span.EndTime.Seconds)
+	assert.Equal(t, int32(500), span.EndTime.Nanos)
+	attrs := span.Attributes.AttributeMap
+	assert.Equal(t, "POST", attrs["http.method"].GetStringValue().Value)
+	assert.Equal(t, "ERROR", attrs["level"].GetStringValue().Value)
+	assert.Equal(t, "eu", attrs["region"].GetStringValue().Value)
+	assert.Equal(t, int64(-7), attrs["code"].GetIntValue())
+
+	// The empty event gets the defaults.
+	span = traces[0].Spans[1]
+	assert.Equal(t, "Export", span.Name.Value)
+	assert.Len(t, span.TraceId, 16)
+	assert.Len(t, span.SpanId, 8)
+	assert.Equal(t, span.StartTime, span.EndTime)
+	// The fields with the default values are not sent.
+	assert.Nil(t, span.Attributes)
+
+	metrics := metricsSink.AllMetrics()
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Metrics, 1)
+	metric := metrics[0].Metrics[0]
+	assert.Equal(t, "queue_size", metric.MetricDescriptor.Name)
+	require.Len(t, metric.Timeseries, 2)
+	assert.Equal(t, "a", metric.Timeseries[0].LabelValues[0].Value)
+	assert.Equal(t, 12.0, metric.Timeseries[0].Points[0].GetDoubleValue())
+	assert.Equal(t, int64(1500000000), metric.Timeseries[0].Points[0].Timestamp.Seconds)
+	assert.False(t, metric.Timeseries[1].LabelValues[0].HasValue)
+
+	// The requests that can't be decoded are rejected.
+	err = conn.Invoke(context.Background(), "/acme.telemetry.Collector/Export", &rawMessage{data: []byte{0x0a, 0x05}}, &testRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestReceiver_Reflection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protogrpcreceiver")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := testConfig(t, dir)
+	r, err := New(cfg)
+	require.NoError(t, err)
+	r.traceConsumer = new(exportertest.SinkTraceExporter)
+	require.NoError(t, r.StartTraceReception(receivertest.NewMockHost()))
+	defer r.StopTraceReception()
+
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "acme.telemetry.Collector"},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	require.Len(t, files, 1)
+	file := &descpb.FileDescriptorProto{}
+	require.NoError(t, proto.Unmarshal(files[0], file))
+	assert.Equal(t, "acme/telemetry.proto", file.GetName())
+}
+
+func TestNew_Errors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protogrpcreceiver")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := testConfig(t, dir)
+
+	cfg.Method = "acme.telemetry.Collector/Stream"
+	_, err = New(cfg)
+	assert.Error(t, err, "streaming methods must not be accepted")
+
+	cfg.Method = "acme.telemetry.Collector/Unknown"
+	_, err = New(cfg)
+	assert.Error(t, err, "unknown methods must not be accepted")
+
+	cfg.Method = "acme.telemetry.Collector/Export"
+	cfg.DescriptorSet = filepath.Join("testdata", "missing.pb")
+	_, err = New(cfg)
+	assert.Error(t, err, "missing descriptor sets must not be accepted")
+}
@@ -0,0 +1,160 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplesreceiver
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
+)
+
+// Formats of the samples.
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// Formats of the timestamps of the samples.
+const (
+	timestampRFC3339 = "rfc3339"
+	timestampUnix    = "unix"
+	timestampUnixMs  = "unix_ms"
+)
+
+// Types of the metrics.
+const (
+	metricTypeGauge      = "gauge"
+	metricTypeCumulative = "cumulative"
+)
+
+// Config defines configuration for the samples receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+	scraperhelper.ScraperSettings `mapstructure:",squash"`
+
+	// URL is the http or https URL of the samples fetched on every scrape.
+	// Either URL or Path must be set.
+	URL string `mapstructure:"url"`
+
+	// Headers are added to the requests of URL, e.g. for authentication.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Path is the path of the file of the samples read on every scrape.
+	Path string `mapstructure:"path"`
+
+	// Format is the format of the samples: csv, whose first row names the
+	// columns, or jsonl, one JSON object per line.
+	Format string `mapstructure:"format"`
+
+	// Delimiter is the field delimiter of the CSV samples, "," by default.
+	Delimiter string `mapstructure:"delimiter"`
+
+	// MetricPrefix is prepended, followed by a slash, to the names of the
+	// metrics.
+	MetricPrefix string `mapstructure:"metric_prefix"`
+
+	// Metrics are the metrics whose points are read from every sample.
+	Metrics []MetricConfig `mapstructure:"metrics"`
+
+	// Labels maps the label keys of all the metrics to the columns, or the
+	// dotted paths of the JSON fields, of their values.
+	Labels map[string]string `mapstructure:"labels"`
+
+	// Timestamp is the column, or the dotted path of the JSON field, of the
+	// time of the samples. The time of the scrape is used if it is not set.
+	Timestamp string `mapstructure:"timestamp"`
+
+	// TimestampFormat is the format of the timestamps: rfc3339, the default,
+	// unix for seconds or unix_ms for milliseconds since the epoch.
+	TimestampFormat string `mapstructure:"timestamp_format"`
+}
+
+// MetricConfig defines a metric whose points are read from the samples.
+type MetricConfig struct {
+	// Name is the name of the metric. Either Name or NameColumn must be
+	// set.
+	Name string `mapstructure:"name"`
+
+	// NameColumn is the column, or the dotted path of the JSON field, of the
+	// name of the metric, for the samples holding a metric name and a value.
+	NameColumn string `mapstructure:"name_column"`
+
+	// Value is the column, or the dotted path of the JSON field, of the
+	// value of the points.
+	Value string `mapstructure:"value"`
+
+	// Type is the type of the metric, gauge, the default, or cumulative. The
+	// start of the cumulative series is the time the receiver started.
+	Type string `mapstructure:"type"`
+
+	// Description and Unit describe the metric.
+	Description string `mapstructure:"description"`
+	Unit        string `mapstructure:"unit"`
+}
+
+// validate returns an error if the configuration is invalid.
+func (cfg *Config) validate() error {
+	if (cfg.URL == "") == (cfg.Path == "") {
+		return errors.New("exactly one of url and path must be set")
+	}
+	if cfg.URL != "" {
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return err
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url %q is not an http or https URL", cfg.URL)
+		}
+	}
+	switch cfg.Format {
+	case formatCSV, formatJSONL:
+	default:
+		return fmt.Errorf("format must be csv or jsonl, got %q", cfg.Format)
+	}
+	if cfg.Delimiter != "" && utf8.RuneCountInString(cfg.Delimiter) != 1 {
+		return fmt.Errorf("delimiter must be a single character, got %q", cfg.Delimiter)
+	}
+	switch cfg.TimestampFormat {
+	case "", timestampRFC3339, timestampUnix, timestampUnixMs:
+	default:
+		return fmt.Errorf("timestamp_format must be rfc3339, unix or unix_ms, got %q", cfg.TimestampFormat)
+	}
+	if len(cfg.Metrics) == 0 {
+		return errors.New("no metrics configured")
+	}
+	for i, m := range cfg.Metrics {
+		if (m.Name == "") == (m.NameColumn == "") {
+			return fmt.Errorf("metrics[%d]: exactly one of name and name_column must be set", i)
+		}
+		if m.Value == "" {
+			return fmt.Errorf("metrics[%d]: value is required", i)
+		}
+		switch m.Type {
+		case "", metricTypeGauge, metricTypeCumulative:
+		default:
+			return fmt.Errorf("metrics[%d]: type must be gauge or cumulative, got %q", i, m.Type)
+		}
+	}
+	for key, column := range cfg.Labels {
+		if key == "" || column == "" {
+			return errors.New("labels must have a key and a column")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplesreceiver
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Receivers[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, len(cfg.Receivers), 3)
+
+	r0 := cfg.Receivers["samples"]
+	assert.Equal(t, r0, factory.CreateDefaultConfig())
+
+	r1 := cfg.Receivers["samples/customname"].(*Config)
+	assert.Equal(t, r1,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal: typeStr,
+				NameVal: "samples/customname",
+			},
+			ScraperSettings: scraperhelper.ScraperSettings{
+				ScrapeInterval: time.Minute,
+			},
+			URL:             "https://reports.example.com/orders.csv",
+			Headers:         map[string]string{"authorization": "Bearer token"},
+			Format:          formatCSV,
+			Delimiter:       ";",
+			MetricPrefix:    "orders",
+			Timestamp:       "time",
+			TimestampFormat: timestampUnix,
+			Labels:          map[string]string{"region": "region"},
+			Metrics: []MetricConfig{
+				{
+					Name:        "placed",
+					Value:       "placed",
+					Type:        metricTypeCumulative,
+					Description: "Number of orders placed",
+					Unit:        "1",
+				},
+				{
+					Name:  "revenue",
+					Value: "revenue",
+					Unit:  "USD",
+				},
+			},
+		})
+	assert.NoError(t, r1.validate())
+
+	r2 := cfg.Receivers["samples/jsonl"].(*Config)
+	assert.Equal(t, "testdata/samples.jsonl", r2.Path)
+	assert.Equal(t, formatJSONL, r2.Format)
+	assert.Equal(t, []MetricConfig{{NameColumn: "metric", Value: "value"}}, r2.Metrics)
+	assert.NoError(t, r2.validate())
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			URL:     "http://localhost/samples.csv",
+			Format:  formatCSV,
+			Metrics: []MetricConfig{{Name: "orders", Value: "orders"}},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{
+			name:   "NoSource",
+			modify: func(cfg *Config) { cfg.URL = "" },
+		},
+		{
+			name:   "URLAndPath",
+			modify: func(cfg *Config) { cfg.Path = "samples.csv" },
+		},
+		{
+			name:   "NotHTTP",
+			modify: func(cfg *Config) { cfg.URL = "ftp://localhost/samples.csv" },
+		},
+		{
+			name:   "InvalidFormat",
+			modify: func(cfg *Config) { cfg.Format = "xml" },
+		},
+		{
+			name:   "InvalidDelimiter",
+			modify: func(cfg *Config) { cfg.Delimiter = ";;" },
+		},
+		{
+			name:   "InvalidTimestampFormat",
+			modify: func(cfg *Config) { cfg.TimestampFormat = "unix_ns" },
+		},
+		{
+			name:   "NoMetrics",
+			modify: func(cfg *Config) { cfg.Metrics = nil },
+		},
+		{
+			name:   "NameAndNameColumn",
+			modify: func(cfg *Config) { cfg.Metrics[0].NameColumn = "metric" },
+		},
+		{
+			name:   "NoValue",
+			modify: func(cfg *Config) { cfg.Metrics[0].Value = "" },
+		},
+		{
+			name:   "InvalidType",
+			modify: func(cfg *Config) { cfg.Metrics[0].Type = "histogram" },
+		},
+		{
+			name:   "EmptyLabelColumn",
+			modify: func(cfg *Config) { cfg.Labels = map[string]string{"region": ""} },
+		},
+	}
+	cfg := valid()
+	require.NoError(t, cfg.validate())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			assert.Error(t, cfg.validate())
+		})
+	}
+}
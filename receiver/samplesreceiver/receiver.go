@@ -0,0 +1,167 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package samplesreceiver periodically fetches a URL, or reads a file, of
+// samples in the CSV or JSON lines format and maps their columns or fields to
+// the names, labels and values of metrics, e.g. to ingest the business
+// metrics exported by legacy systems.
+package samplesreceiver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
+)
+
+const metricsSource = "Samples"
+
+// maxSamplesSize is the maximum size of the samples read on a scrape.
+const maxSamplesSize = 16 << 20
+
+type samplesReceiver struct {
+	logger  *zap.Logger
+	cfg     *Config
+	scraper *scraperhelper.Scraper
+	client  *http.Client
+	parser  *parser
+
+	mu        sync.Mutex
+	started   bool
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+var _ receiver.MetricsReceiver = (*samplesReceiver)(nil)
+
+func newSamplesReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	nextConsumer consumer.MetricsConsumer,
+) (*samplesReceiver, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	r := &samplesReceiver{
+		logger: logger,
+		cfg:    cfg,
+		client: &http.Client{},
+		parser: newParser(cfg, time.Now()),
+	}
+	var err error
+	r.scraper, err = scraperhelper.NewScraper(logger, cfg.Name(), cfg.ScraperSettings, r.scrape, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *samplesReceiver) MetricsSource() string {
+	return metricsSource
+}
+
+// StartMetricsReception starts scraping the samples.
+func (r *samplesReceiver) StartMetricsReception(host receiver.Host) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	r.startOnce.Do(func() {
+		err = r.scraper.Start(host.Context())
+		r.started = err == nil
+	})
+	return err
+}
+
+// StopMetricsReception stops scraping the samples.
+func (r *samplesReceiver) StopMetricsReception() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		err = nil
+		if r.started {
+			err = r.scraper.Stop()
+		}
+	})
+	return err
+}
+
+func (r *samplesReceiver) scrape(ctx context.Context) ([]*metricspb.Metric, error) {
+	data, err := r.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metrics, invalid, err := r.parser.parse(data, time.Now())
+	if invalid > 0 {
+		r.logger.Warn("Skipped the invalid samples",
+			zap.String("receiver", r.cfg.Name()), zap.Int("count", invalid))
+	}
+	return metrics, err
+}
+
+// read returns the samples of the URL or of the file.
+func (r *samplesReceiver) read(ctx context.Context) ([]byte, error) {
+	if r.cfg.Path != "" {
+		f, err := os.Open(r.cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return readLimited(f, r.cfg.Path)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, r.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range r.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s failed with status %d", r.cfg.URL, resp.StatusCode)
+	}
+	return readLimited(resp.Body, r.cfg.URL)
+}
+
+// readLimited reads src up to maxSamplesSize, failing if it is larger.
+func readLimited(src io.Reader, name string) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(src, maxSamplesSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSamplesSize {
+		return nil, fmt.Errorf("the samples of %s exceed %d bytes", name, maxSamplesSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplesreceiver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+const ordersCSV = `time;region;placed;revenue
+1569931200;emea;10;1250.5
+1569931200;apac;4;
+1569931260;emea;12;1400
+1569931260;apac;many;10
+`
+
+func newOrdersServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(ordersCSV))
+	}))
+}
+
+func newOrdersConfig(url string) *Config {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.URL = url
+	cfg.Headers = map[string]string{"authorization": "Bearer token"}
+	cfg.Delimiter = ";"
+	cfg.MetricPrefix = "orders"
+	cfg.Timestamp = "time"
+	cfg.TimestampFormat = timestampUnix
+	cfg.Labels = map[string]string{"region": "region"}
+	cfg.Metrics = []MetricConfig{
+		{Name: "placed", Value: "placed", Type: metricTypeCumulative},
+		{Name: "revenue", Value: "revenue", Unit: "USD"},
+	}
+	return cfg
+}
+
+func TestScrapeCSV(t *testing.T) {
+	server := newOrdersServer()
+	defer server.Close()
+
+	r, err := newSamplesReceiver(zap.NewNop(), newOrdersConfig(server.URL), exportertest.NewNopMetricsExporter())
+	require.NoError(t, err)
+
+	metrics, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	placed := metrics[0]
+	assert.Equal(t, "orders/placed", placed.MetricDescriptor.Name)
+	assert.Equal(t, metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, placed.MetricDescriptor.Type)
+	assert.Equal(t, []*metricspb.LabelKey{{Key: "region"}}, placed.MetricDescriptor.LabelKeys)
+	// The invalid apac sample of the second minute is skipped.
+	require.Len(t, placed.Timeseries, 2)
+	emea := placed.Timeseries[0]
+	assert.Equal(t, []*metricspb.LabelValue{{Value: "emea", HasValue: true}}, emea.LabelValues)
+	assert.Equal(t, r.parser.start, emea.StartTimestamp)
+	require.Len(t, emea.Points, 2)
+	assert.Equal(t, int64(1569931200), emea.Points[0].Timestamp.Seconds)
+	assert.Equal(t, 10.0, emea.Points[0].GetDoubleValue())
+	assert.Equal(t, int64(1569931260), emea.Points[1].Timestamp.Seconds)
+	assert.Equal(t, 12.0, emea.Points[1].GetDoubleValue())
+	require.Len(t, placed.Timeseries[1].Points, 1)
+	assert.Equal(t, 4.0, placed.Timeseries[1].Points[0].GetDoubleValue())
+
+	revenue := metrics[1]
+	assert.Equal(t, "orders/revenue", revenue.MetricDescriptor.Name)
+	assert.Equal(t, "USD", revenue.MetricDescriptor.Unit)
+	assert.Equal(t, metricspb.MetricDescriptor_GAUGE_DOUBLE, revenue.MetricDescriptor.Type)
+	// The empty revenue of apac is not a point.
+	require.Len(t, revenue.Timeseries, 1)
+	assert.Nil(t, revenue.Timeseries[0].StartTimestamp)
+	require.Len(t, revenue.Timeseries[0].Points, 2)
+	assert.Equal(t, 1250.5, revenue.Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestScrapeJSONLines(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Path = "testdata/samples.jsonl"
+	cfg.Format = formatJSONL
+	cfg.Timestamp = "time"
+	cfg.Labels = map[string]string{"host": "source.host"}
+	cfg.Metrics = []MetricConfig{{NameColumn: "metric", Value: "value"}}
+	r, err := newSamplesReceiver(zap.NewNop(), cfg, exportertest.NewNopMetricsExporter())
+	require.NoError(t, err)
+
+	metrics, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	queueDepth := metrics[0]
+	assert.Equal(t, "queue_depth", queueDepth.MetricDescriptor.Name)
+	require.Len(t, queueDepth.Timeseries, 2)
+	assert.Equal(t, []*metricspb.LabelValue{{Value: "mainframe-1", HasValue: true}}, queueDepth.Timeseries[0].LabelValues)
+	require.Len(t, queueDepth.Timeseries[0].Points, 2)
+	assert.Equal(t, 15.0, queueDepth.Timeseries[0].Points[1].GetDoubleValue())
+	assert.Equal(t, 3.5, queueDepth.Timeseries[1].Points[0].GetDoubleValue())
+
+	// The string values are parsed and the missing labels have no value.
+	batchJobs := metrics[1]
+	assert.Equal(t, "batch_jobs", batchJobs.MetricDescriptor.Name)
+	require.Len(t, batchJobs.Timeseries, 1)
+	assert.Equal(t, []*metricspb.LabelValue{{}}, batchJobs.Timeseries[0].LabelValues)
+	require.Len(t, batchJobs.Timeseries[0].Points, 1)
+	assert.Equal(t, 7.0, batchJobs.Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestScrapeErrors(t *testing.T) {
+	server := newOrdersServer()
+	defer server.Close()
+
+	cfg := newOrdersConfig(server.URL)
+	cfg.Headers = nil
+	r, err := newSamplesReceiver(zap.NewNop(), cfg, exportertest.NewNopMetricsExporter())
+	require.NoError(t, err)
+	_, err = r.scrape(context.Background())
+	assert.Error(t, err)
+
+	cfg = newOrdersConfig("")
+	cfg.Path = "testdata/missing.csv"
+	r, err = newSamplesReceiver(zap.NewNop(), cfg, exportertest.NewNopMetricsExporter())
+	require.NoError(t, err)
+	_, err = r.scrape(context.Background())
+	assert.Error(t, err)
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts, err := parseTimestamp("1569931200.5", timestampUnix)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1569931200, 5e8).UTC(), ts)
+
+	ts, err = parseTimestamp("1569931200500", timestampUnixMs)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1569931200, 5e8).UTC(), ts)
+
+	ts, err = parseTimestamp("2019-10-01T12:00:00.5Z", timestampRFC3339)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1569931200, 5e8).UTC(), ts)
+
+	_, err = parseTimestamp("yesterday", timestampUnix)
+	assert.Error(t, err)
+}
+
+func TestStartStop(t *testing.T) {
+	server := newOrdersServer()
+	defer server.Close()
+
+	cfg := newOrdersConfig(server.URL)
+	cfg.ScrapeInterval = 10 * time.Millisecond
+	sink := &exportertest.SinkMetricsExporter{}
+	r, err := newSamplesReceiver(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.AllMetrics()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, r.StopMetricsReception())
+	assert.Error(t, r.StopMetricsReception())
+
+	require.NotEmpty(t, sink.AllMetrics())
+	assert.Equal(t, "orders/placed", sink.AllMetrics()[0].Metrics[0].MetricDescriptor.Name)
+}
@@ -0,0 +1,327 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplesreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+// sample returns the value of a column, or of the JSON field at a dotted
+// path, and whether it is present.
+type sample func(field string) (string, bool)
+
+// parser maps the samples to metrics.
+type parser struct {
+	cfg       *Config
+	labelKeys []string
+	// start is the start of the cumulative metrics.
+	start *timestamp.Timestamp
+}
+
+func newParser(cfg *Config, start time.Time) *parser {
+	labelKeys := make([]string, 0, len(cfg.Labels))
+	for key := range cfg.Labels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	return &parser{
+		cfg:       cfg,
+		labelKeys: labelKeys,
+		start:     internal.TimeToTimestamp(start),
+	}
+}
+
+// parse returns the metrics of the samples in data, and the number of the
+// invalid samples that were skipped. The samples without a timestamp are
+// stamped with now.
+func (p *parser) parse(data []byte, now time.Time) ([]*metricspb.Metric, int, error) {
+	b := &metricsBuilder{
+		parser: p,
+		now:    internal.TimeToTimestamp(now),
+		byName: make(map[string]*metricEntry),
+	}
+	var err error
+	switch p.cfg.Format {
+	case formatJSONL:
+		err = p.parseJSONLines(data, b)
+	default:
+		err = p.parseCSV(data, b)
+	}
+	if err != nil {
+		return nil, b.invalid, err
+	}
+	return b.metrics, b.invalid, nil
+}
+
+func (p *parser) parseCSV(data []byte, b *metricsBuilder) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = ','
+	if p.cfg.Delimiter != "" {
+		reader.Comma = []rune(p.cfg.Delimiter)[0]
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid CSV header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// The record is malformed, the reader resumes at the next line.
+			if _, ok := err.(*csv.ParseError); ok {
+				b.invalid++
+				continue
+			}
+			return err
+		}
+		b.add(func(field string) (string, bool) {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return "", false
+			}
+			return record[i], true
+		})
+	}
+}
+
+func (p *parser) parseJSONLines(data []byte, b *metricsBuilder) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, maxSamplesSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var object map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); err != nil || object == nil {
+			b.invalid++
+			continue
+		}
+		b.add(func(field string) (string, bool) {
+			return lookup(object, field)
+		})
+	}
+	return scanner.Err()
+}
+
+// lookup returns the scalar value at the dotted path of the object as a
+// string, and false if there is none.
+func lookup(object map[string]interface{}, path string) (string, bool) {
+	var value interface{} = object
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// metricEntry is a metric and its time series by label values.
+type metricEntry struct {
+	metric     *metricspb.Metric
+	timeseries map[string]*metricspb.TimeSeries
+}
+
+// metricsBuilder accumulates the points of the samples into metrics.
+type metricsBuilder struct {
+	*parser
+	now     *timestamp.Timestamp
+	metrics []*metricspb.Metric
+	byName  map[string]*metricEntry
+	invalid int
+}
+
+// add adds the points of a sample, skipping it entirely if any of its values
+// or its timestamp is invalid. Empty or missing values are not points.
+func (b *metricsBuilder) add(s sample) {
+	ts, err := b.timestamp(s)
+	if err != nil {
+		b.invalid++
+		return
+	}
+
+	type point struct {
+		cfg   *MetricConfig
+		name  string
+		value float64
+	}
+	var points []point
+	for i := range b.cfg.Metrics {
+		mc := &b.cfg.Metrics[i]
+		raw, ok := s(mc.Value)
+		if !ok || strings.TrimSpace(raw) == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || math.IsNaN(value) {
+			b.invalid++
+			return
+		}
+		name := mc.Name
+		if mc.NameColumn != "" {
+			if name, ok = s(mc.NameColumn); !ok || strings.TrimSpace(name) == "" {
+				b.invalid++
+				return
+			}
+			name = strings.TrimSpace(name)
+		}
+		if b.cfg.MetricPrefix != "" {
+			name = b.cfg.MetricPrefix + "/" + name
+		}
+		points = append(points, point{cfg: mc, name: name, value: value})
+	}
+
+	labelValues := make([]*metricspb.LabelValue, len(b.labelKeys))
+	var key strings.Builder
+	for i, labelKey := range b.labelKeys {
+		value, ok := s(b.cfg.Labels[labelKey])
+		labelValues[i] = &metricspb.LabelValue{Value: value, HasValue: ok}
+		key.WriteString(strconv.Quote(value))
+		key.WriteString(strconv.FormatBool(ok))
+	}
+
+	for _, pt := range points {
+		entry := b.entry(pt.cfg, pt.name)
+		series, ok := entry.timeseries[key.String()]
+		if !ok {
+			series = &metricspb.TimeSeries{LabelValues: copyLabelValues(labelValues)}
+			if pt.cfg.Type == metricTypeCumulative {
+				series.StartTimestamp = b.start
+			}
+			entry.timeseries[key.String()] = series
+			entry.metric.Timeseries = append(entry.metric.Timeseries, series)
+		}
+		series.Points = append(series.Points, &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_DoubleValue{DoubleValue: pt.value},
+		})
+	}
+}
+
+func copyLabelValues(values []*metricspb.LabelValue) []*metricspb.LabelValue {
+	copied := make([]*metricspb.LabelValue, len(values))
+	for i, v := range values {
+		copied[i] = &metricspb.LabelValue{Value: v.Value, HasValue: v.HasValue}
+	}
+	return copied
+}
+
+// entry returns the metric of the given name, creating it from its
+// configuration.
+func (b *metricsBuilder) entry(mc *MetricConfig, name string) *metricEntry {
+	if entry, ok := b.byName[name]; ok {
+		return entry
+	}
+	descriptor := &metricspb.MetricDescriptor{
+		Name:        name,
+		Description: mc.Description,
+		Unit:        mc.Unit,
+		Type:        metricspb.MetricDescriptor_GAUGE_DOUBLE,
+	}
+	if mc.Type == metricTypeCumulative {
+		descriptor.Type = metricspb.MetricDescriptor_CUMULATIVE_DOUBLE
+	}
+	for _, labelKey := range b.labelKeys {
+		descriptor.LabelKeys = append(descriptor.LabelKeys, &metricspb.LabelKey{Key: labelKey})
+	}
+	entry := &metricEntry{
+		metric:     &metricspb.Metric{MetricDescriptor: descriptor},
+		timeseries: make(map[string]*metricspb.TimeSeries),
+	}
+	b.byName[name] = entry
+	b.metrics = append(b.metrics, entry.metric)
+	return entry
+}
+
+// timestamp returns the timestamp of a sample, the time of the scrape if no
+// timestamp column is configured or the sample has none.
+func (b *metricsBuilder) timestamp(s sample) (*timestamp.Timestamp, error) {
+	if b.cfg.Timestamp == "" {
+		return b.now, nil
+	}
+	raw, ok := s(b.cfg.Timestamp)
+	raw = strings.TrimSpace(raw)
+	if !ok || raw == "" {
+		return b.now, nil
+	}
+	t, err := parseTimestamp(raw, b.cfg.TimestampFormat)
+	if err != nil {
+		return nil, err
+	}
+	return internal.TimeToTimestamp(t), nil
+}
+
+var errInvalidTimestamp = errors.New("invalid timestamp")
+
+// parseTimestamp parses a timestamp in the given format, rfc3339 by default.
+func parseTimestamp(raw, format string) (time.Time, error) {
+	switch format {
+	case timestampUnix, timestampUnixMs:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return time.Time{}, errInvalidTimestamp
+		}
+		if format == timestampUnixMs {
+			f /= 1e3
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	default:
+		return time.Parse(time.RFC3339Nano, raw)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sapmreceiver receives spans sent with the SignalFx APM protocol
+// (SAPM), for example by the SignalFx Smart Agent, and converts them to the
+// internal format.
+package sapmreceiver
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.opencensus.io/trace"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/sapm"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
+)
+
+const (
+	traceSource      = "SAPM"
+	receiverTagValue = "sapm"
+)
+
+// Receiver receives PostSpansRequest messages from SAPM clients.
+type Receiver struct {
+	// mu protects the fields of this struct
+	mu sync.Mutex
+
+	// addr is the address onto which the HTTP server will be bound
+	addr         string
+	nextConsumer consumer.TraceConsumer
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	server    *http.Server
+
+	// decompression limits the decompressed bodies of the requests.
+	decompression decompression.Settings
+}
+
+var _ receiver.TraceReceiver = (*Receiver)(nil)
+var _ http.Handler = (*Receiver)(nil)
+
+// New creates a new sapmreceiver.Receiver reference.
+func New(address string, nextConsumer consumer.TraceConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+
+	return &Receiver{
+		addr:          address,
+		nextConsumer:  nextConsumer,
+		decompression: decompression.DefaultSettings(),
+	}, nil
+}
+
+// TraceSource returns the name of the trace data source.
+func (sr *Receiver) TraceSource() string {
+	return traceSource
+}
+
+// StartTraceReception spins up the receiver's HTTP server and makes the receiver start its processing.
+func (sr *Receiver) StartTraceReception(host receiver.Host) error {
+	if host == nil {
+		return errors.New("nil host")
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+
+	sr.startOnce.Do(func() {
+		ln, lerr := net.Listen("tcp", sr.addr)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(sapm.TracePath, sr)
+		sr.server = &http.Server{Handler: mux}
+		go func() {
+			if serr := sr.server.Serve(ln); serr != http.ErrServerClosed {
+				host.ReportFatalError(serr)
+			}
+		}()
+
+		err = nil
+	})
+
+	return err
+}
+
+// StopTraceReception tells the receiver that should stop reception,
+// giving it a chance to perform any necessary clean-up and shutting down
+// its HTTP server.
+func (sr *Receiver) StopTraceReception() error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	sr.stopOnce.Do(func() {
+		if sr.server == nil {
+			err = nil
+			return
+		}
+		err = sr.server.Close()
+	})
+	return err
+}
+
+// ServeHTTP handles a single PostSpansRequest, each of its batches is passed
+// to the next consumer separately since each batch has its own process.
+func (sr *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parentCtx := r.Context()
+	ctx, span := trace.StartSpan(parentCtx, "SAPMReceiver.Export")
+	defer span.End()
+
+	// If the starting RPC has a parent span, then add it as a parent link.
+	observability.SetParentLink(parentCtx, span)
+
+	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
+
+	req, err := sapm.ReadRequest(r, sr.decompression)
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), decompression.StatusCode(err))
+		return
+	}
+
+	for _, batch := range req.Batches {
+		if batch == nil {
+			continue
+		}
+
+		td, err := jaegertranslator.ProtoBatchToOCProto(*batch)
+		if err != nil {
+			span.SetStatus(trace.Status{
+				Code:    trace.StatusCodeInvalidArgument,
+				Message: err.Error(),
+			})
+			observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(batch.Spans))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		td.SourceFormat = receiverTagValue
+
+		if err := sr.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td); err != nil {
+			span.SetStatus(trace.Status{
+				Code:    trace.StatusCodeUnavailable,
+				Message: err.Error(),
+			})
+			observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(td.Spans))
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(td.Spans), 0)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
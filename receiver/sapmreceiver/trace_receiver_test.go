@@ -0,0 +1,186 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmreceiver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/sapm"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func testPostSpansRequest() *sapm.PostSpansRequest {
+	return &sapm.PostSpansRequest{
+		Batches: []*model.Batch{
+			{
+				Process: &model.Process{ServiceName: "frontend"},
+				Spans: []*model.Span{
+					{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(2), OperationName: "a"},
+				},
+			},
+			{
+				Process: &model.Process{ServiceName: "backend"},
+				Spans: []*model.Span{
+					{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(3), OperationName: "b"},
+					{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(4), OperationName: "c"},
+				},
+			},
+		},
+	}
+}
+
+func startReceiver(t *testing.T, nextConsumer *exportertest.SinkTraceExporter) (*Receiver, string) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	sr, err := New(addr, nextConsumer)
+	require.NoError(t, err)
+	require.NoError(t, sr.StartTraceReception(receivertest.NewMockHost()))
+	return sr, fmt.Sprintf("http://%s%s", addr, sapm.TracePath)
+}
+
+func TestReceiver_PostSpans(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	sr, url := startReceiver(t, sink)
+	defer sr.StopTraceReception()
+
+	req, err := sapm.NewHTTPRequest(url, testPostSpansRequest())
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 2)
+	assert.Equal(t, "frontend", got[0].Node.ServiceInfo.Name)
+	assert.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "backend", got[1].Node.ServiceInfo.Name)
+	assert.Len(t, got[1].Spans, 2)
+	assert.Equal(t, "sapm", got[1].SourceFormat)
+}
+
+func TestReceiver_Uncompressed(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	sr, url := startReceiver(t, sink)
+	defer sr.StopTraceReception()
+
+	blob, err := testPostSpansRequest().Marshal()
+	require.NoError(t, err)
+	resp, err := http.Post(url, sapm.ContentType, bytes.NewReader(blob))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, sink.AllTraces(), 2)
+}
+
+func TestReceiver_DecompressionLimits(t *testing.T) {
+	blob, err := testPostSpansRequest().Marshal()
+	require.NoError(t, err)
+
+	sink := &exportertest.SinkTraceExporter{}
+	addr := testutils.GetAvailableLocalAddress(t)
+	sr, err := New(addr, sink)
+	require.NoError(t, err)
+	sr.decompression = decompression.Settings{MaxDecompressedSize: int64(len(blob) - 1)}
+	require.NoError(t, sr.StartTraceReception(receivertest.NewMockHost()))
+	defer sr.StopTraceReception()
+
+	req, err := sapm.NewHTTPRequest(fmt.Sprintf("http://%s%s", addr, sapm.TracePath), testPostSpansRequest())
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Empty(t, sink.AllTraces())
+}
+
+func TestReceiver_BadRequests(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	sr, url := startReceiver(t, sink)
+	defer sr.StopTraceReception()
+
+	resp, err := http.Post(url, sapm.ContentType, bytes.NewReader([]byte{0xff, 0xff}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte("not gzip")))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", sapm.GzipEncoding)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, sink.AllTraces())
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestReceiver_ConsumerError(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	sr, err := New(addr, exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("busy"))))
+	require.NoError(t, err)
+	require.NoError(t, sr.StartTraceReception(receivertest.NewMockHost()))
+	defer sr.StopTraceReception()
+
+	req, err := sapm.NewHTTPRequest(fmt.Sprintf("http://%s%s", addr, sapm.TracePath), testPostSpansRequest())
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestReceiver_StartStop(t *testing.T) {
+	sr, err := New(testutils.GetAvailableLocalAddress(t), exportertest.NewNopTraceExporter())
+	require.NoError(t, err)
+
+	assert.Error(t, sr.StartTraceReception(nil))
+	require.NoError(t, sr.StartTraceReception(receivertest.NewMockHost()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, sr.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, sr.StopTraceReception())
+	assert.Error(t, sr.StopTraceReception())
+}
+
+func TestReceiver_IPv6(t *testing.T) {
+	addr := testutils.GetAvailableLocalIPv6Address(t)
+	sink := &exportertest.SinkTraceExporter{}
+	sr, err := New(addr, sink)
+	require.NoError(t, err)
+	require.NoError(t, sr.StartTraceReception(receivertest.NewMockHost()))
+	defer sr.StopTraceReception()
+
+	req, err := sapm.NewHTTPRequest(fmt.Sprintf("http://%s%s", addr, sapm.TracePath), testPostSpansRequest())
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, sink.AllTraces(), 2)
+}
@@ -0,0 +1,84 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scraperhelper
+
+import (
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+// Names of the metrics reporting every scrape, labeled with the receiver name.
+const (
+	// UpMetricName is the gauge set to 1 if the scrape succeeded, 0 otherwise.
+	UpMetricName = "up"
+	// ScrapeDurationMetricName is the gauge of the scrape duration in seconds.
+	ScrapeDurationMetricName = "scrape_duration_seconds"
+
+	receiverLabelKey = "receiver"
+)
+
+var (
+	upDescriptor = &metricspb.MetricDescriptor{
+		Name:        UpMetricName,
+		Description: "Whether the last scrape of the receiver succeeded",
+		Unit:        "1",
+		Type:        metricspb.MetricDescriptor_GAUGE_INT64,
+		LabelKeys:   []*metricspb.LabelKey{{Key: receiverLabelKey}},
+	}
+
+	scrapeDurationDescriptor = &metricspb.MetricDescriptor{
+		Name:        ScrapeDurationMetricName,
+		Description: "Duration of the last scrape of the receiver",
+		Unit:        "s",
+		Type:        metricspb.MetricDescriptor_GAUGE_DOUBLE,
+		LabelKeys:   []*metricspb.LabelKey{{Key: receiverLabelKey}},
+	}
+)
+
+// scrapeMetrics returns the metrics reporting a scrape of the receiver.
+func scrapeMetrics(name string, up bool, duration time.Duration, ts time.Time) []*metricspb.Metric {
+	labelValues := []*metricspb.LabelValue{{Value: name, HasValue: true}}
+	timestamp := internal.TimeToTimestamp(ts)
+
+	var upValue int64
+	if up {
+		upValue = 1
+	}
+	return []*metricspb.Metric{
+		{
+			MetricDescriptor: upDescriptor,
+			Timeseries: []*metricspb.TimeSeries{{
+				LabelValues: labelValues,
+				Points: []*metricspb.Point{{
+					Timestamp: timestamp,
+					Value:     &metricspb.Point_Int64Value{Int64Value: upValue},
+				}},
+			}},
+		},
+		{
+			MetricDescriptor: scrapeDurationDescriptor,
+			Timeseries: []*metricspb.TimeSeries{{
+				LabelValues: labelValues,
+				Points: []*metricspb.Point{{
+					Timestamp: timestamp,
+					Value:     &metricspb.Point_DoubleValue{DoubleValue: duration.Seconds()},
+				}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scraperhelper provides the scraping loop shared by the receivers
+// polling their metrics periodically, so that they all honor the same
+// settings and report their scrapes the same way.
+package scraperhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/leaderelection"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+const (
+	// DefaultScrapeInterval is the interval used when none is configured.
+	DefaultScrapeInterval = 10 * time.Second
+)
+
+// ScraperSettings defines the scraping settings common to the polling
+// receivers, to be squashed into their configuration.
+type ScraperSettings struct {
+	// ScrapeInterval is the interval between two scrapes, 10s by default.
+	ScrapeInterval time.Duration `mapstructure:"scrape_interval"`
+
+	// ScrapeTimeout is the maximum duration of a scrape, the scrape interval
+	// by default.
+	ScrapeTimeout time.Duration `mapstructure:"scrape_timeout"`
+
+	// Jitter is the maximum random delay added to the scrape times, chosen
+	// once at start so that the scrapes stay one interval apart.
+	Jitter time.Duration `mapstructure:"jitter"`
+
+	// Align aligns the scrapes to the multiples of the scrape interval since
+	// the Unix epoch, so that receivers with the same interval scrape at the
+	// same time.
+	Align bool `mapstructure:"align"`
+
+	// LeaderElection is the name of a leader-election extension, when set the
+	// receiver only scrapes while this collector instance is the leader so
+	// that replicas do not scrape the same targets.
+	LeaderElection string `mapstructure:"leader_election"`
+}
+
+// Validate returns an error if the settings are invalid.
+func (s *ScraperSettings) Validate() error {
+	switch {
+	case s.ScrapeInterval < 0:
+		return fmt.Errorf("scrape_interval must not be negative, got %v", s.ScrapeInterval)
+	case s.ScrapeTimeout < 0:
+		return fmt.Errorf("scrape_timeout must not be negative, got %v", s.ScrapeTimeout)
+	case s.Jitter < 0:
+		return fmt.Errorf("jitter must not be negative, got %v", s.Jitter)
+	}
+	return nil
+}
+
+func (s ScraperSettings) withDefaults() ScraperSettings {
+	if s.ScrapeInterval <= 0 {
+		s.ScrapeInterval = DefaultScrapeInterval
+	}
+	if s.ScrapeTimeout <= 0 {
+		s.ScrapeTimeout = s.ScrapeInterval
+	}
+	return s
+}
+
+// Scrape specifies the function invoked to scrape the metrics. It may return
+// the metrics scraped before an error, they are exported along with the scrape
+// metrics. The context is cancelled when the scrape timeout is reached.
+type Scrape func(ctx context.Context) ([]*metricspb.Metric, error)
+
+var errScrapeTimeout = errors.New("scrape timed out")
+
+// Scraper calls a Scrape function periodically and sends the scraped metrics,
+// along with the scrape metrics, to the next consumer.
+type Scraper struct {
+	logger       *zap.Logger
+	name         string
+	settings     ScraperSettings
+	scrape       Scrape
+	nextConsumer consumer.MetricsConsumer
+
+	// offset is the random delay added to the scrape times.
+	offset time.Duration
+	now    func() time.Time
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewScraper creates a Scraper for the receiver with the given name.
+func NewScraper(
+	logger *zap.Logger,
+	name string,
+	settings ScraperSettings,
+	scrape Scrape,
+	nextConsumer consumer.MetricsConsumer,
+) (*Scraper, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if scrape == nil {
+		return nil, errors.New("nil scrape function")
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Scraper{
+		logger:       logger,
+		name:         name,
+		settings:     settings.withDefaults(),
+		scrape:       scrape,
+		nextConsumer: nextConsumer,
+		now:          time.Now,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	if settings.Jitter > 0 {
+		s.offset = time.Duration(rand.Int63n(int64(settings.Jitter)))
+	}
+	return s, nil
+}
+
+// Start starts scraping periodically until Stop is called.
+func (s *Scraper) Start(ctx context.Context) error {
+	var err = oterr.ErrAlreadyStarted
+	s.startOnce.Do(func() {
+		go s.run(ctx)
+		err = nil
+	})
+	return err
+}
+
+// Stop stops scraping, waiting for an in-flight scrape to complete.
+func (s *Scraper) Stop() error {
+	var err = oterr.ErrAlreadyStopped
+	s.stopOnce.Do(func() {
+		close(s.done)
+		<-s.stopped
+		err = nil
+	})
+	return err
+}
+
+// firstDelay returns the delay before the first scrape.
+func (s *Scraper) firstDelay(now time.Time) time.Duration {
+	interval := s.settings.ScrapeInterval
+	if !s.settings.Align {
+		return interval + s.offset
+	}
+	return interval - time.Duration(now.UnixNano()%int64(interval)) + s.offset
+}
+
+func (s *Scraper) run(ctx context.Context) {
+	defer close(s.stopped)
+
+	timer := time.NewTimer(s.firstDelay(s.now()))
+	select {
+	case <-timer.C:
+	case <-s.done:
+		timer.Stop()
+		return
+	}
+
+	ticker := time.NewTicker(s.settings.ScrapeInterval)
+	defer ticker.Stop()
+	s.scrapeAndExport(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.scrapeAndExport(ctx)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// isLeader reports whether the scraper should scrape, that is when no
+// leader election is configured or this instance holds the leadership.
+func (s *Scraper) isLeader() bool {
+	if s.settings.LeaderElection == "" {
+		return true
+	}
+	elector := leaderelection.Lookup(s.settings.LeaderElection)
+	if elector == nil {
+		s.logger.Warn("Leader election extension not found, skipping the scrape",
+			zap.String("receiver", s.name), zap.String("leader_election", s.settings.LeaderElection))
+		return false
+	}
+	return elector.IsLeader()
+}
+
+func (s *Scraper) scrapeAndExport(ctx context.Context) {
+	if !s.isLeader() {
+		return
+	}
+
+	ctx, span := trace.StartSpan(ctx, "scraperhelper.scrape")
+	defer span.End()
+	span.AddAttributes(trace.StringAttribute("receiver", s.name))
+
+	start := s.now()
+	scrapeCtx, cancel := context.WithTimeout(ctx, s.settings.ScrapeTimeout)
+	metrics, err := s.scrape(scrapeCtx)
+	if err == nil && scrapeCtx.Err() == context.DeadlineExceeded {
+		err = errScrapeTimeout
+	}
+	cancel()
+	end := s.now()
+
+	receiverCtx := observability.ContextWithReceiverName(ctx, s.name)
+	observability.RecordMetricsForScraper(receiverCtx, err != nil)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeDataLoss, Message: fmt.Sprintf("Error(s) when scraping metrics: %v", err)})
+		s.logger.Warn("Failed to scrape the metrics", zap.String("receiver", s.name), zap.Error(err))
+	}
+
+	numTimeSeries := 0
+	for _, metric := range metrics {
+		numTimeSeries += len(metric.GetTimeseries())
+	}
+	observability.RecordMetricsForMetricsReceiver(receiverCtx, numTimeSeries, 0)
+
+	metrics = append(metrics, scrapeMetrics(s.name, err == nil, end.Sub(start), end)...)
+	if cerr := s.nextConsumer.ConsumeMetricsData(ctx, consumerdata.MetricsData{Metrics: metrics}); cerr != nil {
+		s.logger.Warn("Failed to export the scraped metrics", zap.String("receiver", s.name), zap.Error(cerr))
+	}
+}
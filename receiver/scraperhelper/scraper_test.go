@@ -0,0 +1,189 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scraperhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/leaderelection"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+const receiverName = "fake_scraper"
+
+var testMetric = &metricspb.Metric{
+	MetricDescriptor: &metricspb.MetricDescriptor{Name: "test"},
+	Timeseries: []*metricspb.TimeSeries{
+		{Points: []*metricspb.Point{{Value: &metricspb.Point_Int64Value{Int64Value: 1}}}},
+		{Points: []*metricspb.Point{{Value: &metricspb.Point_Int64Value{Int64Value: 2}}}},
+	},
+}
+
+func TestNewScraper(t *testing.T) {
+	scrape := func(context.Context) ([]*metricspb.Metric, error) { return nil, nil }
+	sink := new(exportertest.SinkMetricsExporter)
+
+	_, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{}, scrape, nil)
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+	_, err = NewScraper(zap.NewNop(), receiverName, ScraperSettings{}, nil, sink)
+	assert.Error(t, err)
+	_, err = NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeInterval: -time.Second}, scrape, sink)
+	assert.Error(t, err)
+	_, err = NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeTimeout: -time.Second}, scrape, sink)
+	assert.Error(t, err)
+	_, err = NewScraper(zap.NewNop(), receiverName, ScraperSettings{Jitter: -time.Second}, scrape, sink)
+	assert.Error(t, err)
+
+	s, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{}, scrape, sink)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultScrapeInterval, s.settings.ScrapeInterval)
+	assert.Equal(t, DefaultScrapeInterval, s.settings.ScrapeTimeout)
+	assert.Equal(t, time.Duration(0), s.offset)
+}
+
+func TestScraper_FirstDelay(t *testing.T) {
+	scrape := func(context.Context) ([]*metricspb.Metric, error) { return nil, nil }
+	sink := new(exportertest.SinkMetricsExporter)
+	now := time.Unix(1000, int64(3*time.Second))
+
+	s, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeInterval: 10 * time.Second}, scrape, sink)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, s.firstDelay(now))
+
+	s, err = NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeInterval: 10 * time.Second, Align: true}, scrape, sink)
+	require.NoError(t, err)
+	assert.Equal(t, 7*time.Second, s.firstDelay(now))
+
+	s, err = NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeInterval: 10 * time.Second, Align: true, Jitter: time.Second}, scrape, sink)
+	require.NoError(t, err)
+	assert.True(t, s.offset >= 0 && s.offset < time.Second, "unexpected offset %v", s.offset)
+	assert.Equal(t, 7*time.Second+s.offset, s.firstDelay(now))
+}
+
+func TestScraper_ScrapeAndExport(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	scrapeErr := errors.New("partial scrape")
+	calls := 0
+	scrape := func(context.Context) ([]*metricspb.Metric, error) {
+		calls++
+		if calls == 2 {
+			return []*metricspb.Metric{testMetric}, scrapeErr
+		}
+		return []*metricspb.Metric{testMetric}, nil
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	s, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{}, scrape, sink)
+	require.NoError(t, err)
+
+	s.scrapeAndExport(context.Background())
+	s.scrapeAndExport(context.Background())
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 2)
+	for i, wantUp := range []int64{1, 0} {
+		metrics := got[i].Metrics
+		require.Len(t, metrics, 3)
+		assert.Equal(t, testMetric, metrics[0])
+		assert.Equal(t, UpMetricName, metrics[1].MetricDescriptor.Name)
+		assert.Equal(t, receiverName, metrics[1].Timeseries[0].LabelValues[0].Value)
+		assert.Equal(t, wantUp, metrics[1].Timeseries[0].Points[0].GetInt64Value())
+		assert.Equal(t, ScrapeDurationMetricName, metrics[2].MetricDescriptor.Name)
+		assert.True(t, metrics[2].Timeseries[0].Points[0].GetDoubleValue() >= 0)
+	}
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverScrapes(receiverName, 2))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverFailedScrapes(receiverName, 1))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverReceivedTimeSeries(receiverName, 4))
+}
+
+func TestScraper_Timeout(t *testing.T) {
+	scrape := func(ctx context.Context) ([]*metricspb.Metric, error) {
+		<-ctx.Done()
+		return nil, nil
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	s, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeTimeout: 10 * time.Millisecond}, scrape, sink)
+	require.NoError(t, err)
+
+	s.scrapeAndExport(context.Background())
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 2)
+	assert.Equal(t, int64(0), got[0].Metrics[0].Timeseries[0].Points[0].GetInt64Value())
+}
+
+type fakeElector bool
+
+func (e *fakeElector) IsLeader() bool { return bool(*e) }
+
+func TestScraper_LeaderElection(t *testing.T) {
+	scrape := func(context.Context) ([]*metricspb.Metric, error) { return []*metricspb.Metric{testMetric}, nil }
+	sink := new(exportertest.SinkMetricsExporter)
+	s, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{LeaderElection: "leader-election"}, scrape, sink)
+	require.NoError(t, err)
+
+	// Not scraping until the extension is registered.
+	s.scrapeAndExport(context.Background())
+	assert.Len(t, sink.AllMetrics(), 0)
+
+	leader := fakeElector(false)
+	leaderelection.Register("leader-election", &leader)
+	defer leaderelection.Unregister("leader-election")
+	s.scrapeAndExport(context.Background())
+	assert.Len(t, sink.AllMetrics(), 0)
+
+	leader = true
+	s.scrapeAndExport(context.Background())
+	assert.Len(t, sink.AllMetrics(), 1)
+}
+
+func TestScraper_StartStop(t *testing.T) {
+	scraped := make(chan struct{}, 10)
+	scrape := func(context.Context) ([]*metricspb.Metric, error) {
+		select {
+		case scraped <- struct{}{}:
+		default:
+		}
+		return nil, nil
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	s, err := NewScraper(zap.NewNop(), receiverName, ScraperSettings{ScrapeInterval: 5 * time.Millisecond}, scrape, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Start(context.Background()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, s.Start(context.Background()))
+	<-scraped
+	<-scraped
+	require.NoError(t, s.Stop())
+	assert.Equal(t, oterr.ErrAlreadyStopped, s.Stop())
+
+	// No scrape happens once stopped.
+	n := len(sink.AllMetrics())
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, n, len(sink.AllMetrics()))
+}
@@ -15,16 +15,15 @@
 package vmmetricsreceiver
 
 import (
-	"time"
-
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
 )
 
 // Config defines configuration for VMMetrics receiver.
 type Config struct {
 	configmodels.ReceiverSettings `mapstructure:",squash"`
-	ScrapeInterval                time.Duration `mapstructure:"scrape_interval"`
-	MountPoint                    string        `mapstructure:"mount_point"`
-	ProcessMountPoint             string        `mapstructure:"process_mount_point"`
-	MetricPrefix                  string        `mapstructure:"metric_prefix"`
+	scraperhelper.ScraperSettings `mapstructure:",squash"`
+	MountPoint                    string `mapstructure:"mount_point"`
+	ProcessMountPoint             string `mapstructure:"process_mount_point"`
+	MetricPrefix                  string `mapstructure:"metric_prefix"`
 }
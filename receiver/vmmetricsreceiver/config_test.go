@@ -24,6 +24,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -49,7 +50,11 @@ func TestLoadConfig(t *testing.T) {
 				TypeVal: typeStr,
 				NameVal: "vmmetrics/customname",
 			},
-			ScrapeInterval:    5 * time.Second,
+			ScraperSettings: scraperhelper.ScraperSettings{
+				ScrapeInterval: 5 * time.Second,
+				ScrapeTimeout:  2 * time.Second,
+				Align:          true,
+			},
 			MetricPrefix:      "testmetric",
 			MountPoint:        "/mountpoint",
 			ProcessMountPoint: "/proc",
@@ -82,7 +82,7 @@ func (f *Factory) CreateMetricsReceiver(
 	}
 	cfg := config.(*Config)
 
-	vmc, err := NewVMMetricsCollector(cfg.ScrapeInterval, cfg.MountPoint, cfg.ProcessMountPoint, cfg.MetricPrefix, consumer)
+	vmc, err := NewVMMetricsCollector(logger, cfg.Name(), cfg.ScraperSettings, cfg.MountPoint, cfg.ProcessMountPoint, cfg.MetricPrefix, consumer)
 	if err != nil {
 		return nil, err
 	}
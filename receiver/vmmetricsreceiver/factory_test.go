@@ -24,6 +24,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -41,7 +42,7 @@ func TestCreateReceiver(t *testing.T) {
 	assert.Equal(t, err, configerror.ErrDataTypeIsNotSupported)
 	assert.Nil(t, tReceiver)
 
-	mReceiver, err := factory.CreateMetricsReceiver(zap.NewNop(), cfg, nil)
+	mReceiver, err := factory.CreateMetricsReceiver(zap.NewNop(), cfg, exportertest.NewNopMetricsExporter())
 
 	// Currently vmmetrics receiver is only supported on linux.
 	if runtime.GOOS != "linux" {
@@ -52,4 +53,7 @@ func TestCreateReceiver(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.NotNil(t, mReceiver)
+
+	_, err = factory.CreateMetricsReceiver(zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with nil next consumer must fail")
 }
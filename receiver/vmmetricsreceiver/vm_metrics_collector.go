@@ -26,17 +26,17 @@ import (
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
 	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
 	"github.com/prometheus/procfs"
-	"go.opencensus.io/trace"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
-	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/internal"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
 )
 
 // VMMetricsCollector is a struct that collects and reports VM and process metrics (cpu, mem, etc).
 type VMMetricsCollector struct {
-	consumer consumer.MetricsConsumer
+	scraper *scraperhelper.Scraper
 
 	startTime time.Time
 
@@ -44,30 +44,31 @@ type VMMetricsCollector struct {
 	processFs procfs.FS
 	pid       int
 
-	scrapeInterval time.Duration
-	metricPrefix   string
-	done           chan struct{}
+	metricPrefix string
 }
 
 const (
-	defaultMountPoint     = procfs.DefaultMountPoint // "/proc"
-	defaultScrapeInterval = 10 * time.Second
+	defaultMountPoint = procfs.DefaultMountPoint // "/proc"
 )
 
 var rsc *resourcepb.Resource
 var resourceDetectionSync sync.Once
 
-// NewVMMetricsCollector creates a new set of VM and Process Metrics (mem, cpu).
-func NewVMMetricsCollector(si time.Duration, mountPoint, processMountPoint, prefix string, consumer consumer.MetricsConsumer) (*VMMetricsCollector, error) {
+// NewVMMetricsCollector creates a new set of VM and Process Metrics (mem, cpu),
+// scraped according to the given settings.
+func NewVMMetricsCollector(
+	logger *zap.Logger,
+	name string,
+	settings scraperhelper.ScraperSettings,
+	mountPoint, processMountPoint, prefix string,
+	consumer consumer.MetricsConsumer,
+) (*VMMetricsCollector, error) {
 	if mountPoint == "" {
 		mountPoint = defaultMountPoint
 	}
 	if processMountPoint == "" {
 		processMountPoint = defaultMountPoint
 	}
-	if si <= 0 {
-		si = defaultScrapeInterval
-	}
 	fs, err := procfs.NewFS(mountPoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new VMMetricsCollector: %s", err)
@@ -77,14 +78,15 @@ func NewVMMetricsCollector(si time.Duration, mountPoint, processMountPoint, pref
 		return nil, fmt.Errorf("failed to create new VMMetricsCollector: %s", err)
 	}
 	vmc := &VMMetricsCollector{
-		consumer:       consumer,
-		startTime:      time.Now(),
-		fs:             fs,
-		processFs:      processFs,
-		pid:            os.Getpid(),
-		scrapeInterval: si,
-		metricPrefix:   prefix,
-		done:           make(chan struct{}),
+		startTime:    time.Now(),
+		fs:           fs,
+		processFs:    processFs,
+		pid:          os.Getpid(),
+		metricPrefix: prefix,
+	}
+	vmc.scraper, err = scraperhelper.NewScraper(logger, name, settings, vmc.scrape, consumer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new VMMetricsCollector: %s", err)
 	}
 
 	return vmc, nil
@@ -112,29 +114,15 @@ func detectResource() {
 func (vmc *VMMetricsCollector) StartCollection() {
 	detectResource()
 
-	go func() {
-		ticker := time.NewTicker(vmc.scrapeInterval)
-		for {
-			select {
-			case <-ticker.C:
-				vmc.scrapeAndExport()
-
-			case <-vmc.done:
-				return
-			}
-		}
-	}()
+	vmc.scraper.Start(context.Background())
 }
 
 // StopCollection stops the collection of metric information
 func (vmc *VMMetricsCollector) StopCollection() {
-	close(vmc.done)
+	vmc.scraper.Stop()
 }
 
-func (vmc *VMMetricsCollector) scrapeAndExport() {
-	ctx, span := trace.StartSpan(context.Background(), "VMMetricsCollector.scrapeAndExport")
-	defer span.End()
-
+func (vmc *VMMetricsCollector) scrape(ctx context.Context) ([]*metricspb.Metric, error) {
 	metrics := make([]*metricspb.Metric, 0, len(vmMetricDescriptors))
 	var errs []error
 
@@ -215,13 +203,7 @@ func (vmc *VMMetricsCollector) scrapeAndExport() {
 		errs = append(errs, err)
 	}
 
-	if len(errs) > 0 {
-		span.SetStatus(trace.Status{Code: trace.StatusCodeDataLoss, Message: fmt.Sprintf("Error(s) when scraping VM metrics: %v", oterr.CombineErrors(errs))})
-	}
-
-	if len(metrics) > 0 {
-		vmc.consumer.ConsumeMetricsData(ctx, consumerdata.MetricsData{Metrics: metrics})
-	}
+	return metrics, oterr.CombineErrors(errs)
 }
 
 func (vmc *VMMetricsCollector) getInt64TimeSeries(val uint64) *metricspb.TimeSeries {
@@ -0,0 +1,78 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookreceiver
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for the webhook receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+
+	// Path is the HTTP path on which the events are accepted.
+	Path string `mapstructure:"path"`
+
+	// Secret is the shared secret used to verify the HMAC-SHA256 signature of
+	// the requests. If empty the requests are not verified.
+	Secret string `mapstructure:"secret"`
+
+	// SignatureHeader is the header carrying the hex encoded signature of
+	// the request body, optionally prefixed with "sha256=" as GitHub does.
+	SignatureHeader string `mapstructure:"signature_header"`
+
+	// ServiceName is the service name of the node that reports the events.
+	ServiceName string `mapstructure:"service_name"`
+
+	// Mapping selects the fields of the events used to build the spans.
+	Mapping FieldMapping `mapstructure:"mapping"`
+}
+
+// FieldMapping maps the fields of a JSON event to a span. Fields are selected
+// with dotted paths, e.g. "repository.full_name" or "commits.0.id".
+type FieldMapping struct {
+	// Name is the path of the field used as the span name.
+	Name string `mapstructure:"name"`
+
+	// NameHeader is the request header used as the span name when Name is
+	// not set or not present in the event, e.g. "X-GitHub-Event".
+	NameHeader string `mapstructure:"name_header"`
+
+	// Timestamp is the path of the field holding the event time, either a
+	// RFC 3339 string or the number of seconds since the epoch. If not set,
+	// or not present, the time the event was received is used.
+	Timestamp string `mapstructure:"timestamp"`
+
+	// Attributes maps span attribute keys to the paths of their values.
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+func (cfg *Config) validate() error {
+	if !strings.HasPrefix(cfg.Path, "/") {
+		return errors.New("path must start with \"/\"")
+	}
+	if cfg.Secret != "" && cfg.SignatureHeader == "" {
+		return errors.New("signature_header is required when secret is set")
+	}
+	for key, path := range cfg.Mapping.Attributes {
+		if key == "" || path == "" {
+			return errors.New("attribute mappings must have a key and a path")
+		}
+	}
+	return nil
+}
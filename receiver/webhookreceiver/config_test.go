@@ -0,0 +1,91 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Receivers[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, len(cfg.Receivers), 2)
+
+	r0 := cfg.Receivers["webhook"]
+	assert.Equal(t, r0, factory.CreateDefaultConfig())
+
+	r1 := cfg.Receivers["webhook/github"].(*Config)
+	assert.Equal(t, r1,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  "webhook/github",
+				Endpoint: "0.0.0.0:8765",
+			},
+			Path:            "/github",
+			Secret:          "s3cr3t",
+			SignatureHeader: defaultSignatureHeader,
+			ServiceName:     "github",
+			Mapping: FieldMapping{
+				NameHeader: "X-GitHub-Event",
+				Timestamp:  "head_commit.timestamp",
+				Attributes: map[string]string{
+					"repository": "repository.full_name",
+					"sender":     "sender.login",
+				},
+			},
+		})
+	assert.NoError(t, r1.validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "RelativePath",
+			cfg:  Config{Path: "events"},
+		},
+		{
+			name: "NoSignatureHeader",
+			cfg:  Config{Path: "/events", Secret: "s3cr3t"},
+		},
+		{
+			name: "EmptyAttributePath",
+			cfg:  Config{Path: "/events", Mapping: FieldMapping{Attributes: map[string]string{"repository": ""}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.cfg.validate())
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookreceiver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+const defaultSpanName = "webhook"
+
+var errNoEvents = errors.New("the request has no events")
+
+// decodeEvents parses a request body holding either a single JSON object or
+// an array of them.
+func decodeEvents(body []byte) ([]map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var events []map[string]interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		events = append(events, v)
+	case []interface{}:
+		for _, item := range v {
+			event, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("events must be JSON objects")
+			}
+			events = append(events, event)
+		}
+	default:
+		return nil, errors.New("events must be JSON objects")
+	}
+	if len(events) == 0 {
+		return nil, errNoEvents
+	}
+	return events, nil
+}
+
+// eventsToTraceData converts the events to spans, one span without duration
+// per event, each in its own trace.
+func eventsToTraceData(
+	cfg *Config,
+	header http.Header,
+	events []map[string]interface{},
+	received time.Time,
+) (consumerdata.TraceData, error) {
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{
+			ServiceInfo: &commonpb.ServiceInfo{Name: cfg.ServiceName},
+		},
+		Spans: make([]*tracepb.Span, 0, len(events)),
+	}
+
+	// Sort the attribute keys so the spans do not depend on the map order.
+	keys := make([]string, 0, len(cfg.Mapping.Attributes))
+	for key := range cfg.Mapping.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, event := range events {
+		traceID, spanID, err := newIDs()
+		if err != nil {
+			return td, err
+		}
+		ts := eventTimestamp(cfg.Mapping.Timestamp, event, received)
+		span := &tracepb.Span{
+			TraceId:   traceID,
+			SpanId:    spanID,
+			Name:      &tracepb.TruncatableString{Value: eventName(cfg.Mapping, header, event)},
+			StartTime: ts,
+			EndTime:   ts,
+		}
+
+		attrs := make(map[string]*tracepb.AttributeValue)
+		for _, key := range keys {
+			if v, ok := lookup(event, cfg.Mapping.Attributes[key]); ok {
+				attrs[key] = toAttributeValue(v)
+			}
+		}
+		if len(attrs) > 0 {
+			span.Attributes = &tracepb.Span_Attributes{AttributeMap: attrs}
+		}
+		td.Spans = append(td.Spans, span)
+	}
+	return td, nil
+}
+
+func eventName(mapping FieldMapping, header http.Header, event map[string]interface{}) string {
+	if mapping.Name != "" {
+		if v, ok := lookup(event, mapping.Name); ok {
+			if name := toString(v); name != "" {
+				return name
+			}
+		}
+	}
+	if mapping.NameHeader != "" {
+		if name := header.Get(mapping.NameHeader); name != "" {
+			return name
+		}
+	}
+	return defaultSpanName
+}
+
+func eventTimestamp(path string, event map[string]interface{}, received time.Time) *timestamp.Timestamp {
+	t := received
+	if path != "" {
+		if v, ok := lookup(event, path); ok {
+			if et, ok := toTime(v); ok {
+				t = et
+			}
+		}
+	}
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// lookup returns the value at the dotted path, numeric segments index arrays.
+func lookup(event map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = event
+	for _, segment := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch v := v.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	}
+	return time.Time{}, false
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	blob, _ := json.Marshal(v)
+	return string(blob)
+}
+
+// toAttributeValue converts a JSON value to an attribute, objects and arrays
+// are kept as their JSON encoding.
+func toAttributeValue(v interface{}) *tracepb.AttributeValue {
+	switch v := v.(type) {
+	case bool:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: i}}
+		}
+		if f, err := v.Float64(); err == nil {
+			return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: f}}
+		}
+	}
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: toString(v)},
+		},
+	}
+}
+
+func newIDs() ([]byte, []byte, error) {
+	ids := make([]byte, 24)
+	if _, err := rand.Read(ids); err != nil {
+		return nil, nil, err
+	}
+	return ids[:16], ids[16:], nil
+}
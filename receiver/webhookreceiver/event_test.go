@@ -0,0 +1,127 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookreceiver
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pushEvent = `{
+  "ref": "refs/heads/master",
+  "forced": false,
+  "size": 2,
+  "head_commit": {"id": "abc123", "timestamp": "2019-11-05T10:00:00.5Z"},
+  "commits": [{"id": "abc122"}, {"id": "abc123"}],
+  "repository": {"full_name": "open-telemetry/opentelemetry-service", "stars": 1.5}
+}`
+
+func TestDecodeEvents(t *testing.T) {
+	events, err := decodeEvents([]byte(pushEvent))
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	events, err = decodeEvents([]byte(`[{"a": 1}, {"b": 2}]`))
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	for _, invalid := range []string{"", "{", "1", `"event"`, "[]", `[{"a": 1}, 2]`} {
+		_, err := decodeEvents([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestEventsToTraceData(t *testing.T) {
+	cfg := &Config{
+		ServiceName: "github",
+		Mapping: FieldMapping{
+			Name:       "action",
+			NameHeader: "X-GitHub-Event",
+			Timestamp:  "head_commit.timestamp",
+			Attributes: map[string]string{
+				"ref":        "ref",
+				"forced":     "forced",
+				"size":       "size",
+				"stars":      "repository.stars",
+				"last":       "commits.1.id",
+				"repository": "repository",
+				"missing":    "sender.login",
+			},
+		},
+	}
+	events, err := decodeEvents([]byte(pushEvent))
+	require.NoError(t, err)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+
+	td, err := eventsToTraceData(cfg, header, events, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "github", td.Node.ServiceInfo.Name)
+	require.Len(t, td.Spans, 1)
+
+	span := td.Spans[0]
+	assert.Len(t, span.TraceId, 16)
+	assert.Len(t, span.SpanId, 8)
+	// There is no "action" field in push events, the header is used instead.
+	assert.Equal(t, "push", span.Name.Value)
+	assert.Equal(t, int64(1572948000), span.StartTime.Seconds)
+	assert.Equal(t, int32(500000000), span.StartTime.Nanos)
+	assert.Equal(t, span.StartTime, span.EndTime)
+
+	attrs := span.Attributes.AttributeMap
+	assert.Len(t, attrs, 6)
+	assert.Equal(t, "refs/heads/master", attrs["ref"].GetStringValue().Value)
+	assert.Equal(t, &tracepb.AttributeValue_BoolValue{BoolValue: false}, attrs["forced"].Value)
+	assert.Equal(t, int64(2), attrs["size"].GetIntValue())
+	assert.Equal(t, 1.5, attrs["stars"].GetDoubleValue())
+	assert.Equal(t, "abc123", attrs["last"].GetStringValue().Value)
+	assert.Equal(t, `{"full_name":"open-telemetry/opentelemetry-service","stars":1.5}`,
+		attrs["repository"].GetStringValue().Value)
+}
+
+func TestEventsToTraceData_Defaults(t *testing.T) {
+	received := time.Unix(1572948000, 0)
+	events := []map[string]interface{}{{"action": "opened"}, {"other": "field"}}
+
+	td, err := eventsToTraceData(&Config{Mapping: FieldMapping{Name: "action"}}, http.Header{}, events, received)
+	require.NoError(t, err)
+	require.Len(t, td.Spans, 2)
+	assert.Equal(t, "opened", td.Spans[0].Name.Value)
+	assert.Equal(t, defaultSpanName, td.Spans[1].Name.Value)
+	assert.Equal(t, received.Unix(), td.Spans[1].StartTime.Seconds)
+	assert.Nil(t, td.Spans[1].Attributes)
+	assert.NotEqual(t, td.Spans[0].TraceId, td.Spans[1].TraceId)
+}
+
+func TestToTime(t *testing.T) {
+	events, err := decodeEvents([]byte(`{"unix": 1572948000.25, "text": "2019-11-05T10:00:00Z", "bad": "yesterday"}`))
+	require.NoError(t, err)
+
+	ts, ok := toTime(events[0]["unix"])
+	require.True(t, ok)
+	assert.Equal(t, time.Unix(1572948000, 250000000), ts)
+
+	ts, ok = toTime(events[0]["text"])
+	require.True(t, ok)
+	assert.Equal(t, int64(1572948000), ts.Unix())
+
+	_, ok = toTime(events[0]["bad"])
+	assert.False(t, ok)
+}
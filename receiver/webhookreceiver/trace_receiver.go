@@ -0,0 +1,227 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhookreceiver accepts JSON events POSTed by webhooks, e.g. from
+// CI/CD systems or SaaS products, and converts each of them to a span.
+package webhookreceiver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+const (
+	// maxBodySize limits the size of the accepted requests.
+	maxBodySize = 10 << 20
+
+	traceSource      = "Webhook"
+	receiverTagValue = "webhook"
+)
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// Receiver receives webhook events over HTTP.
+type Receiver struct {
+	// mu protects the fields of this struct
+	mu sync.Mutex
+
+	cfg          *Config
+	nextConsumer consumer.TraceConsumer
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	server    *http.Server
+}
+
+var _ receiver.TraceReceiver = (*Receiver)(nil)
+var _ http.Handler = (*Receiver)(nil)
+
+// New creates a new webhookreceiver.Receiver reference.
+func New(cfg *Config, nextConsumer consumer.TraceConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Receiver{
+		cfg:          cfg,
+		nextConsumer: nextConsumer,
+	}, nil
+}
+
+// TraceSource returns the name of the trace data source.
+func (wr *Receiver) TraceSource() string {
+	return traceSource
+}
+
+// StartTraceReception spins up the receiver's HTTP server and makes the receiver start its processing.
+func (wr *Receiver) StartTraceReception(host receiver.Host) error {
+	if host == nil {
+		return errors.New("nil host")
+	}
+
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+
+	wr.startOnce.Do(func() {
+		ln, lerr := net.Listen("tcp", wr.cfg.Endpoint)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(wr.cfg.Path, wr)
+		wr.server = &http.Server{Handler: mux}
+		go func() {
+			if serr := wr.server.Serve(ln); serr != http.ErrServerClosed {
+				host.ReportFatalError(serr)
+			}
+		}()
+
+		err = nil
+	})
+
+	return err
+}
+
+// StopTraceReception tells the receiver that should stop reception,
+// giving it a chance to perform any necessary clean-up and shutting down
+// its HTTP server.
+func (wr *Receiver) StopTraceReception() error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	wr.stopOnce.Do(func() {
+		if wr.server == nil {
+			err = nil
+			return
+		}
+		err = wr.server.Close()
+	})
+	return err
+}
+
+// ServeHTTP handles a single request, holding either one event or an array
+// of events, all of them are passed to the next consumer together.
+func (wr *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parentCtx := r.Context()
+	ctx, span := trace.StartSpan(parentCtx, "WebhookReceiver.Export")
+	defer span.End()
+
+	// If the starting RPC has a parent span, then add it as a parent link.
+	observability.SetParentLink(parentCtx, span)
+
+	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := wr.verifySignature(r.Header, body); err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnauthenticated,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	events, err := decodeEvents(body)
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	td, err := eventsToTraceData(wr.cfg, r.Header, events, time.Now())
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInternal,
+			Message: err.Error(),
+		})
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(events))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	td.SourceFormat = receiverTagValue
+
+	if err := wr.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td); err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnavailable,
+			Message: err.Error(),
+		})
+		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, 0, len(td.Spans))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(td.Spans), 0)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifySignature checks the HMAC-SHA256 of the body against the signature
+// header, it accepts every request if no secret is configured.
+func (wr *Receiver) verifySignature(header http.Header, body []byte) error {
+	if wr.cfg.Secret == "" {
+		return nil
+	}
+
+	sig := strings.TrimPrefix(header.Get(wr.cfg.SignatureHeader), "sha256=")
+	got, err := hex.DecodeString(sig)
+	if err != nil || len(got) == 0 {
+		return errInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(wr.cfg.Secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errInvalidSignature
+	}
+	return nil
+}
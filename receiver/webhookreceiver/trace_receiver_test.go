@@ -0,0 +1,136 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookreceiver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+const testSecret = "s3cr3t"
+
+func startReceiver(t *testing.T, secret string, nextConsumer consumer.TraceConsumer) (*Receiver, string) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+	cfg.Secret = secret
+	cfg.Mapping.NameHeader = "X-GitHub-Event"
+	wr, err := New(cfg, nextConsumer)
+	require.NoError(t, err)
+	require.NoError(t, wr.StartTraceReception(receivertest.NewMockHost()))
+	return wr, fmt.Sprintf("http://%s%s", cfg.Endpoint, cfg.Path)
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func post(t *testing.T, url string, body []byte, signature string) int {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "push")
+	if signature != "" {
+		req.Header.Set(defaultSignatureHeader, signature)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestReceiver_Events(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	wr, url := startReceiver(t, "", sink)
+	defer wr.StopTraceReception()
+
+	assert.Equal(t, http.StatusOK, post(t, url, []byte(pushEvent), ""))
+	assert.Equal(t, http.StatusOK, post(t, url, []byte(`[{"a": 1}, {"b": 2}]`), ""))
+	assert.Equal(t, http.StatusBadRequest, post(t, url, []byte("{"), ""))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 2)
+	assert.Equal(t, "webhook", got[0].SourceFormat)
+	assert.Equal(t, defaultServiceName, got[0].Node.ServiceInfo.Name)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "push", got[0].Spans[0].Name.Value)
+	assert.Len(t, got[1].Spans, 2)
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestReceiver_Signature(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	wr, url := startReceiver(t, testSecret, sink)
+	defer wr.StopTraceReception()
+
+	body := []byte(pushEvent)
+	tests := []struct {
+		name      string
+		signature string
+		want      int
+	}{
+		{name: "valid", signature: sign(body), want: http.StatusOK},
+		{name: "valid_without_prefix", signature: sign(body)[len("sha256="):], want: http.StatusOK},
+		{name: "missing", want: http.StatusUnauthorized},
+		{name: "not_hex", signature: "sha256=xyz", want: http.StatusUnauthorized},
+		{name: "other_body", signature: sign([]byte("{}")), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, post(t, url, body, tt.signature))
+		})
+	}
+	assert.Len(t, sink.AllTraces(), 2)
+}
+
+func TestReceiver_ConsumerError(t *testing.T) {
+	wr, url := startReceiver(t, "", exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("busy"))))
+	defer wr.StopTraceReception()
+
+	assert.Equal(t, http.StatusServiceUnavailable, post(t, url, []byte(pushEvent), ""))
+}
+
+func TestReceiver_StartStop(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+	wr, err := New(cfg, exportertest.NewNopTraceExporter())
+	require.NoError(t, err)
+
+	assert.Error(t, wr.StartTraceReception(nil))
+	require.NoError(t, wr.StartTraceReception(receivertest.NewMockHost()))
+	assert.Equal(t, oterr.ErrAlreadyStarted, wr.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, wr.StopTraceReception())
+	assert.Error(t, wr.StopTraceReception())
+}
@@ -0,0 +1,101 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
+)
+
+// defaultETWLevel is the level of the ETW events enabled when none is
+// configured, TRACE_LEVEL_INFORMATION.
+const defaultETWLevel = 4
+
+// Config defines configuration for the Windows performance counters receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+	scraperhelper.ScraperSettings `mapstructure:",squash"`
+
+	// MetricPrefix is prepended, followed by a slash, to the names of the
+	// metrics.
+	MetricPrefix string `mapstructure:"metric_prefix"`
+
+	// PerfCounters are the performance counters collected on every scrape.
+	PerfCounters []PerfCounterConfig `mapstructure:"perfcounters"`
+
+	// ETW are the Event Tracing for Windows providers whose events are
+	// counted.
+	ETW []ETWProviderConfig `mapstructure:"etw"`
+}
+
+// PerfCounterConfig defines the counters collected from a performance object.
+type PerfCounterConfig struct {
+	// Object is the English name of the performance object, e.g. "Processor".
+	Object string `mapstructure:"object"`
+
+	// Instances are the instances of the object collected, "*" for all of
+	// them. Empty for the objects without instances, e.g. "Memory".
+	Instances []string `mapstructure:"instances"`
+
+	// Counters are the English names of the counters, e.g.
+	// "% Processor Time".
+	Counters []string `mapstructure:"counters"`
+}
+
+// ETWProviderConfig defines an ETW provider enabled in the trace session of
+// the receiver.
+type ETWProviderConfig struct {
+	// Name identifies the provider in the metrics, the GUID by default.
+	Name string `mapstructure:"name"`
+
+	// GUID is the GUID of the provider, e.g.
+	// "{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}".
+	GUID string `mapstructure:"guid"`
+
+	// Level is the maximum level of the events enabled, from 1 (critical) to
+	// 5 (verbose). Default is 4 (information).
+	Level uint8 `mapstructure:"level"`
+
+	// Keywords is the bitmask of the keywords of the events enabled, all the
+	// events when zero.
+	Keywords uint64 `mapstructure:"keywords"`
+}
+
+// validate returns an error if the configuration is invalid.
+func (cfg *Config) validate() error {
+	if len(cfg.PerfCounters) == 0 && len(cfg.ETW) == 0 {
+		return errors.New("no perfcounters nor etw providers configured")
+	}
+	for i, pc := range cfg.PerfCounters {
+		if pc.Object == "" {
+			return fmt.Errorf("perfcounters[%d]: object is required", i)
+		}
+		if len(pc.Counters) == 0 {
+			return fmt.Errorf("perfcounters[%d]: no counters configured for object %q", i, pc.Object)
+		}
+	}
+	for i, p := range cfg.ETW {
+		if _, err := parseGUID(p.GUID); err != nil {
+			return fmt.Errorf("etw[%d]: %v", i, err)
+		}
+		if p.Level > 5 {
+			return fmt.Errorf("etw[%d]: level must be between 1 and 5, got %d", i, p.Level)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Receivers[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, len(cfg.Receivers), 2)
+
+	r0 := cfg.Receivers["windowsperfcounters"]
+	assert.Equal(t, r0, factory.CreateDefaultConfig())
+
+	r1 := cfg.Receivers["windowsperfcounters/customname"].(*Config)
+	assert.Equal(t, r1,
+		&Config{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal: typeStr,
+				NameVal: "windowsperfcounters/customname",
+			},
+			ScraperSettings: scraperhelper.ScraperSettings{
+				ScrapeInterval: 30 * time.Second,
+			},
+			MetricPrefix: "windows",
+			PerfCounters: []PerfCounterConfig{
+				{
+					Object:    "Processor",
+					Instances: []string{"*"},
+					Counters:  []string{"% Processor Time", "% Idle Time"},
+				},
+				{
+					Object:   "Memory",
+					Counters: []string{"Available Bytes"},
+				},
+			},
+			ETW: []ETWProviderConfig{
+				{
+					Name:     "kernel-process",
+					GUID:     "{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}",
+					Level:    3,
+					Keywords: 16,
+				},
+			},
+		})
+	assert.NoError(t, r1.validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "Empty",
+		},
+		{
+			name: "NoObject",
+			cfg:  Config{PerfCounters: []PerfCounterConfig{{Counters: []string{"Available Bytes"}}}},
+		},
+		{
+			name: "NoCounters",
+			cfg:  Config{PerfCounters: []PerfCounterConfig{{Object: "Memory"}}},
+		},
+		{
+			name: "InvalidGUID",
+			cfg:  Config{ETW: []ETWProviderConfig{{GUID: "Microsoft-Windows-Kernel-Process"}}},
+		},
+		{
+			name: "InvalidLevel",
+			cfg:  Config{ETW: []ETWProviderConfig{{GUID: "22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716", Level: 6}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.cfg.validate())
+		})
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modadvapi32 = windows.NewLazySystemDLL("advapi32.dll")
+
+	procStartTraceW    = modadvapi32.NewProc("StartTraceW")
+	procControlTraceW  = modadvapi32.NewProc("ControlTraceW")
+	procEnableTraceEx2 = modadvapi32.NewProc("EnableTraceEx2")
+	procOpenTraceW     = modadvapi32.NewProc("OpenTraceW")
+	procProcessTrace   = modadvapi32.NewProc("ProcessTrace")
+	procCloseTrace     = modadvapi32.NewProc("CloseTrace")
+)
+
+// ETW constants, see evntrace.h and evntcons.h.
+const (
+	wnodeFlagTracedGUID            = 0x00020000
+	eventTraceRealTimeMode         = 0x00000100
+	eventTraceControlStop          = 1
+	eventControlCodeEnableProvider = 1
+	processTraceModeRealTime       = 0x00000100
+	processTraceModeEventRecord    = 0x10000000
+	invalidProcessTraceHandle      = ^uint64(0)
+
+	errorSuccess         = 0
+	errorAlreadyExists   = 183
+	errorCtxClosePending = 0x00001B5F
+
+	// maxSessionNameLength is the maximum length of the name of a trace
+	// session, including the terminating NUL.
+	maxSessionNameLength = 1024
+)
+
+// wnodeHeader is WNODE_HEADER.
+type wnodeHeader struct {
+	BufferSize        uint32
+	ProviderID        uint32
+	HistoricalContext uint64
+	TimeStamp         int64
+	GUID              guid
+	ClientContext     uint32
+	Flags             uint32
+}
+
+// eventTraceProperties is EVENT_TRACE_PROPERTIES followed by the session
+// name. The layout only matches on 64-bit Windows.
+type eventTraceProperties struct {
+	Wnode               wnodeHeader
+	BufferSize          uint32
+	MinimumBuffers      uint32
+	MaximumBuffers      uint32
+	MaximumFileSize     uint32
+	LogFileMode         uint32
+	FlushTimer          uint32
+	EnableFlags         uint32
+	AgeLimit            int32
+	NumberOfBuffers     uint32
+	FreeBuffers         uint32
+	EventsLost          uint32
+	BuffersWritten      uint32
+	LogBuffersLost      uint32
+	RealTimeBuffersLost uint32
+	LoggerThreadID      uintptr
+	LogFileNameOffset   uint32
+	LoggerNameOffset    uint32
+
+	loggerName [maxSessionNameLength]uint16
+}
+
+func newEventTraceProperties() *eventTraceProperties {
+	p := &eventTraceProperties{}
+	p.Wnode.BufferSize = uint32(unsafe.Sizeof(*p))
+	p.Wnode.Flags = wnodeFlagTracedGUID
+	// Query performance counter resolution of the event timestamps.
+	p.Wnode.ClientContext = 1
+	p.LogFileMode = eventTraceRealTimeMode
+	p.LoggerNameOffset = uint32(unsafe.Offsetof(p.loggerName))
+	return p
+}
+
+// eventTraceLogfile is EVENT_TRACE_LOGFILEW, the current event and the log
+// file header are not used. The layout only matches on 64-bit Windows.
+type eventTraceLogfile struct {
+	LogFileName         *uint16
+	LoggerName          *uint16
+	CurrentTime         int64
+	BuffersRead         uint32
+	ProcessTraceMode    uint32
+	CurrentEvent        [88]byte
+	LogfileHeader       [280]byte
+	BufferCallback      uintptr
+	BufferSize          uint32
+	Filled              uint32
+	EventsLost          uint32
+	EventRecordCallback uintptr
+	IsKernelTrace       uint32
+	Context             uintptr
+}
+
+// eventDescriptor is EVENT_DESCRIPTOR.
+type eventDescriptor struct {
+	ID      uint16
+	Version uint8
+	Channel uint8
+	Level   uint8
+	Opcode  uint8
+	Task    uint16
+	Keyword uint64
+}
+
+// eventRecord is EVENT_RECORD, the fields after the header are not used
+// except the user context.
+type eventRecord struct {
+	Size              uint16
+	HeaderType        uint16
+	Flags             uint16
+	EventProperty     uint16
+	ThreadID          uint32
+	ProcessID         uint32
+	TimeStamp         int64
+	ProviderID        guid
+	EventDescriptor   eventDescriptor
+	ProcessorTime     uint64
+	ActivityID        guid
+	BufferContext     uint32
+	ExtendedDataCount uint16
+	UserDataLength    uint16
+	ExtendedData      uintptr
+	UserData          uintptr
+	UserContext       uintptr
+}
+
+type etwKey struct {
+	provider guid
+	eventID  uint16
+	level    uint8
+}
+
+// realTimeSession is a real-time ETW trace session counting the events of
+// its providers.
+type realTimeSession struct {
+	logger     *zap.Logger
+	name       string
+	handle     uint64
+	properties *eventTraceProperties
+	trace      uint64
+	names      map[guid]string
+	// id identifies the session in the events, see sessions.
+	id        uintptr
+	processed chan struct{}
+
+	mu          sync.Mutex
+	eventCounts map[etwKey]int64
+}
+
+var (
+	// sessions are the open sessions by id, the events are dispatched to
+	// them by the callback shared by all the sessions.
+	sessionsMu    sync.Mutex
+	sessions      = make(map[uintptr]*realTimeSession)
+	lastSessionID uintptr
+
+	eventRecordCallbackOnce sync.Once
+	eventRecordCallback     uintptr
+)
+
+func newETWSession(logger *zap.Logger, name string, providers []ETWProviderConfig) (etwSession, error) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		return nil, errors.New("ETW is only supported on 64-bit windows")
+	}
+	s := &realTimeSession{
+		logger:      logger,
+		name:        "otelsvc-" + strings.Replace(name, "/", "-", -1),
+		names:       make(map[guid]string),
+		processed:   make(chan struct{}),
+		eventCounts: make(map[etwKey]int64),
+	}
+	if len(s.name) >= maxSessionNameLength {
+		return nil, fmt.Errorf("ETW session name %q is too long", s.name)
+	}
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		if err := s.enable(p); err != nil {
+			s.stop()
+			return nil, err
+		}
+	}
+	if err := s.open(); err != nil {
+		s.stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// start starts the trace session, stopping first the session with the same
+// name left by a previous run.
+func (s *realTimeSession) start() error {
+	name, err := windows.UTF16PtrFromString(s.name)
+	if err != nil {
+		return err
+	}
+	s.properties = newEventTraceProperties()
+	status, _, _ := procStartTraceW.Call(
+		uintptr(unsafe.Pointer(&s.handle)), uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(s.properties)))
+	if status == errorAlreadyExists {
+		s.logger.Info("Stopping the ETW session left by a previous run", zap.String("session", s.name))
+		procControlTraceW.Call(
+			0, uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(newEventTraceProperties())), eventTraceControlStop)
+		s.properties = newEventTraceProperties()
+		status, _, _ = procStartTraceW.Call(
+			uintptr(unsafe.Pointer(&s.handle)), uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(s.properties)))
+	}
+	if status != errorSuccess {
+		return fmt.Errorf("failed to start the ETW session %q: %v", s.name, syscall.Errno(status))
+	}
+	return nil
+}
+
+func (s *realTimeSession) enable(p ETWProviderConfig) error {
+	provider, err := parseGUID(p.GUID)
+	if err != nil {
+		return err
+	}
+	level := p.Level
+	if level == 0 {
+		level = defaultETWLevel
+	}
+	status, _, _ := procEnableTraceEx2.Call(
+		uintptr(s.handle), uintptr(unsafe.Pointer(&provider)), eventControlCodeEnableProvider,
+		uintptr(level), uintptr(p.Keywords), 0, 0, 0)
+	if status != errorSuccess {
+		return fmt.Errorf("failed to enable the ETW provider %s: %v", p.GUID, syscall.Errno(status))
+	}
+	s.names[provider] = p.Name
+	if p.Name == "" {
+		s.names[provider] = provider.String()
+	}
+	return nil
+}
+
+// open opens the session for real-time consumption and processes its events
+// in the background.
+func (s *realTimeSession) open() error {
+	eventRecordCallbackOnce.Do(func() {
+		eventRecordCallback = syscall.NewCallback(onEventRecord)
+	})
+	sessionsMu.Lock()
+	lastSessionID++
+	s.id = lastSessionID
+	sessions[s.id] = s
+	sessionsMu.Unlock()
+
+	name, err := windows.UTF16PtrFromString(s.name)
+	if err != nil {
+		return err
+	}
+	logfile := &eventTraceLogfile{
+		LoggerName:          name,
+		ProcessTraceMode:    processTraceModeRealTime | processTraceModeEventRecord,
+		EventRecordCallback: eventRecordCallback,
+		Context:             s.id,
+	}
+	r1, _, callErr := procOpenTraceW.Call(uintptr(unsafe.Pointer(logfile)))
+	if uint64(r1) == invalidProcessTraceHandle {
+		s.unregister()
+		return fmt.Errorf("failed to open the ETW session %q: %v", s.name, callErr)
+	}
+	s.trace = uint64(r1)
+
+	go func() {
+		defer close(s.processed)
+		status, _, _ := procProcessTrace.Call(uintptr(unsafe.Pointer(&s.trace)), 1, 0, 0)
+		if status != errorSuccess {
+			s.logger.Warn("ETW session stopped", zap.String("session", s.name), zap.Error(syscall.Errno(status)))
+		}
+	}()
+	return nil
+}
+
+func onEventRecord(record *eventRecord) uintptr {
+	sessionsMu.Lock()
+	s := sessions[record.UserContext]
+	sessionsMu.Unlock()
+	if s != nil {
+		key := etwKey{
+			provider: record.ProviderID,
+			eventID:  record.EventDescriptor.ID,
+			level:    record.EventDescriptor.Level,
+		}
+		s.mu.Lock()
+		s.eventCounts[key]++
+		s.mu.Unlock()
+	}
+	return 0
+}
+
+func (s *realTimeSession) counts() []etwCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make([]etwCount, 0, len(s.eventCounts))
+	for key, count := range s.eventCounts {
+		counts = append(counts, etwCount{
+			provider: s.names[key.provider],
+			eventID:  key.eventID,
+			level:    key.level,
+			count:    count,
+		})
+	}
+	return counts
+}
+
+func (s *realTimeSession) unregister() {
+	sessionsMu.Lock()
+	delete(sessions, s.id)
+	sessionsMu.Unlock()
+}
+
+// stop stops the trace session, which also disables its providers.
+func (s *realTimeSession) stop() error {
+	status, _, _ := procControlTraceW.Call(
+		uintptr(s.handle), 0, uintptr(unsafe.Pointer(newEventTraceProperties())), eventTraceControlStop)
+	if status != errorSuccess {
+		return fmt.Errorf("failed to stop the ETW session %q: %v", s.name, syscall.Errno(status))
+	}
+	return nil
+}
+
+func (s *realTimeSession) close() error {
+	status, _, _ := procCloseTrace.Call(uintptr(s.trace))
+	err := s.stop()
+	if status != errorSuccess && status != errorCtxClosePending && err == nil {
+		err = fmt.Errorf("failed to close the ETW session %q: %v", s.name, syscall.Errno(status))
+	}
+	<-s.processed
+	s.unregister()
+	return err
+}
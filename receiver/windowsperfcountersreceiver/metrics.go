@@ -0,0 +1,203 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+const (
+	instanceLabelKey = "instance"
+	providerLabelKey = "provider"
+	eventIDLabelKey  = "event_id"
+	levelLabelKey    = "level"
+
+	etwEventsMetricName = "etw/events"
+)
+
+// guid has the memory layout of the Windows GUID structure.
+type guid struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+// parseGUID parses a GUID in the registry format, with or without braces,
+// e.g. "{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}".
+func parseGUID(s string) (guid, error) {
+	var g guid
+	str := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	parts := strings.Split(str, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 ||
+		len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return g, fmt.Errorf("invalid GUID %q", s)
+	}
+	d1, err1 := strconv.ParseUint(parts[0], 16, 32)
+	d2, err2 := strconv.ParseUint(parts[1], 16, 16)
+	d3, err3 := strconv.ParseUint(parts[2], 16, 16)
+	d4, err4 := hex.DecodeString(parts[3] + parts[4])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return g, fmt.Errorf("invalid GUID %q", s)
+	}
+	g.Data1 = uint32(d1)
+	g.Data2 = uint16(d2)
+	g.Data3 = uint16(d3)
+	copy(g.Data4[:], d4)
+	return g, nil
+}
+
+// String returns the GUID in the registry format.
+func (g guid) String() string {
+	return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}", g.Data1, g.Data2, g.Data3, g.Data4[:2], g.Data4[2:])
+}
+
+// counterValue is the value of a performance counter for an instance of its
+// object.
+type counterValue struct {
+	object  string
+	counter string
+	// instanced is true if the instances of the object are collected,
+	// instance is empty otherwise.
+	instanced bool
+	instance  string
+	value     float64
+}
+
+// etwCount is the number of events of an ETW provider with the same id and
+// level received since the session started.
+type etwCount struct {
+	provider string
+	eventID  uint16
+	level    uint8
+	count    int64
+}
+
+// metricName returns the name of the metric of a performance counter, e.g.
+// "processor/percent_processor_time" for "\Processor(*)\% Processor Time".
+func metricName(prefix, object, counter string) string {
+	name := sanitize(object) + "/" + sanitize(counter)
+	if prefix != "" {
+		name = prefix + "/" + name
+	}
+	return name
+}
+
+// sanitize lowercases s and replaces the sequences of characters other than
+// letters and digits by underscores, "%" and "/" being spelled out.
+func sanitize(s string) string {
+	s = strings.NewReplacer("%", " percent ", "/", " per ").Replace(strings.ToLower(s))
+	var b strings.Builder
+	underscore := false
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		underscore = true
+	}
+	return b.String()
+}
+
+// perfCounterMetrics returns a gauge per performance counter, with a time
+// series per instance.
+func perfCounterMetrics(prefix string, values []counterValue, now time.Time) []*metricspb.Metric {
+	ts := internal.TimeToTimestamp(now)
+	var metrics []*metricspb.Metric
+	byName := make(map[string]*metricspb.Metric)
+	for _, v := range values {
+		name := metricName(prefix, v.object, v.counter)
+		metric, ok := byName[name]
+		if !ok {
+			descriptor := &metricspb.MetricDescriptor{
+				Name:        name,
+				Description: fmt.Sprintf(`Performance counter \%s\%s`, v.object, v.counter),
+				Unit:        "1",
+				Type:        metricspb.MetricDescriptor_GAUGE_DOUBLE,
+			}
+			if v.instanced {
+				descriptor.LabelKeys = []*metricspb.LabelKey{{Key: instanceLabelKey}}
+			}
+			metric = &metricspb.Metric{MetricDescriptor: descriptor}
+			byName[name] = metric
+			metrics = append(metrics, metric)
+		}
+		timeseries := &metricspb.TimeSeries{
+			Points: []*metricspb.Point{{
+				Timestamp: ts,
+				Value:     &metricspb.Point_DoubleValue{DoubleValue: v.value},
+			}},
+		}
+		if v.instanced {
+			timeseries.LabelValues = []*metricspb.LabelValue{{Value: v.instance, HasValue: true}}
+		}
+		metric.Timeseries = append(metric.Timeseries, timeseries)
+	}
+	return metrics
+}
+
+// etwMetric returns the cumulative count of the ETW events, nil if no event
+// was received.
+func etwMetric(prefix string, counts []etwCount, start, now time.Time) *metricspb.Metric {
+	if len(counts) == 0 {
+		return nil
+	}
+	name := etwEventsMetricName
+	if prefix != "" {
+		name = prefix + "/" + name
+	}
+	metric := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:        name,
+			Description: "Number of events received from the ETW providers",
+			Unit:        "1",
+			Type:        metricspb.MetricDescriptor_CUMULATIVE_INT64,
+			LabelKeys: []*metricspb.LabelKey{
+				{Key: providerLabelKey},
+				{Key: eventIDLabelKey},
+				{Key: levelLabelKey},
+			},
+		},
+	}
+	startTs := internal.TimeToTimestamp(start)
+	ts := internal.TimeToTimestamp(now)
+	for _, c := range counts {
+		metric.Timeseries = append(metric.Timeseries, &metricspb.TimeSeries{
+			StartTimestamp: startTs,
+			LabelValues: []*metricspb.LabelValue{
+				{Value: c.provider, HasValue: true},
+				{Value: strconv.Itoa(int(c.eventID)), HasValue: true},
+				{Value: strconv.Itoa(int(c.level)), HasValue: true},
+			},
+			Points: []*metricspb.Point{{
+				Timestamp: ts,
+				Value:     &metricspb.Point_Int64Value{Int64Value: c.count},
+			}},
+		})
+	}
+	return metric
+}
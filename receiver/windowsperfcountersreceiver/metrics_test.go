@@ -0,0 +1,100 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGUID(t *testing.T) {
+	g, err := parseGUID("{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}")
+	require.NoError(t, err)
+	assert.Equal(t, guid{
+		Data1: 0x22FB2CD6,
+		Data2: 0x0E7B,
+		Data3: 0x422B,
+		Data4: [8]byte{0xA0, 0xC7, 0x2F, 0xAD, 0x1F, 0xD0, 0xE7, 0x16},
+	}, g)
+	assert.Equal(t, "{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}", g.String())
+
+	// The braces are optional.
+	g2, err := parseGUID("22fb2cd6-0e7b-422b-a0c7-2fad1fd0e716")
+	require.NoError(t, err)
+	assert.Equal(t, g, g2)
+
+	for _, invalid := range []string{"", "22FB2CD6-0E7B-422B-A0C7", "{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E7XX}"} {
+		_, err := parseGUID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "processor/percent_processor_time", metricName("", "Processor", "% Processor Time"))
+	assert.Equal(t, "windows/network_interface/bytes_received_per_sec",
+		metricName("windows", "Network Interface", "Bytes Received/sec"))
+	assert.Equal(t, "logicaldisk/avg_disk_sec_per_read", metricName("", "LogicalDisk", "Avg. Disk sec/Read"))
+}
+
+func TestPerfCounterMetrics(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	values := []counterValue{
+		{object: "Processor", counter: "% Processor Time", instanced: true, instance: "0", value: 12.5},
+		{object: "Processor", counter: "% Processor Time", instanced: true, instance: "_Total", value: 10},
+		{object: "Memory", counter: "Available Bytes", value: 1024},
+	}
+	metrics := perfCounterMetrics("", values, now)
+	require.Len(t, metrics, 2)
+
+	cpu := metrics[0]
+	assert.Equal(t, "processor/percent_processor_time", cpu.MetricDescriptor.Name)
+	assert.Equal(t, metricspb.MetricDescriptor_GAUGE_DOUBLE, cpu.MetricDescriptor.Type)
+	assert.Equal(t, []*metricspb.LabelKey{{Key: instanceLabelKey}}, cpu.MetricDescriptor.LabelKeys)
+	require.Len(t, cpu.Timeseries, 2)
+	assert.Equal(t, "_Total", cpu.Timeseries[1].LabelValues[0].Value)
+	assert.Equal(t, 10.0, cpu.Timeseries[1].Points[0].GetDoubleValue())
+	assert.Equal(t, now.Unix(), cpu.Timeseries[1].Points[0].Timestamp.Seconds)
+
+	memory := metrics[1]
+	assert.Equal(t, "memory/available_bytes", memory.MetricDescriptor.Name)
+	assert.Empty(t, memory.MetricDescriptor.LabelKeys)
+	require.Len(t, memory.Timeseries, 1)
+	assert.Empty(t, memory.Timeseries[0].LabelValues)
+	assert.Equal(t, 1024.0, memory.Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestETWMetric(t *testing.T) {
+	assert.Nil(t, etwMetric("", nil, time.Now(), time.Now()))
+
+	start := time.Unix(1500000000, 0)
+	now := start.Add(time.Minute)
+	metric := etwMetric("windows", []etwCount{{provider: "kernel-process", eventID: 1, level: 4, count: 7}}, start, now)
+	require.NotNil(t, metric)
+	assert.Equal(t, "windows/etw/events", metric.MetricDescriptor.Name)
+	assert.Equal(t, metricspb.MetricDescriptor_CUMULATIVE_INT64, metric.MetricDescriptor.Type)
+	require.Len(t, metric.Timeseries, 1)
+	ts := metric.Timeseries[0]
+	assert.Equal(t, start.Unix(), ts.StartTimestamp.Seconds)
+	assert.Equal(t, []*metricspb.LabelValue{
+		{Value: "kernel-process", HasValue: true},
+		{Value: "1", HasValue: true},
+		{Value: "4", HasValue: true},
+	}, ts.LabelValues)
+	assert.Equal(t, int64(7), ts.Points[0].GetInt64Value())
+}
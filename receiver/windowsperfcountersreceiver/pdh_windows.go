@@ -0,0 +1,270 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+var (
+	modpdh = windows.NewLazySystemDLL("pdh.dll")
+
+	procPdhOpenQueryW                = modpdh.NewProc("PdhOpenQueryW")
+	procPdhAddEnglishCounterW        = modpdh.NewProc("PdhAddEnglishCounterW")
+	procPdhCollectQueryData          = modpdh.NewProc("PdhCollectQueryData")
+	procPdhGetFormattedCounterValue  = modpdh.NewProc("PdhGetFormattedCounterValue")
+	procPdhGetFormattedCounterArrayW = modpdh.NewProc("PdhGetFormattedCounterArrayW")
+	procPdhCloseQuery                = modpdh.NewProc("PdhCloseQuery")
+)
+
+// PDH status codes and formats, see pdhmsg.h and pdh.h.
+const (
+	pdhCStatusValidData = 0x00000000
+	pdhCStatusNewData   = 0x00000001
+	pdhMoreData         = 0x800007D2
+	pdhNoData           = 0x800007D5
+	pdhInvalidData      = 0xC0000BC6
+
+	pdhFmtDouble   = 0x00000200
+	pdhFmtNoCap100 = 0x00008000
+)
+
+// pdhFmtCounterValueDouble is PDH_FMT_COUNTERVALUE holding a double, the union
+// is 8 bytes aligned.
+type pdhFmtCounterValueDouble struct {
+	CStatus     uint32
+	_           uint32
+	DoubleValue float64
+}
+
+// pdhFmtCounterValueItemDouble is PDH_FMT_COUNTERVALUE_ITEM_W holding a
+// double. The layout only matches on 64-bit Windows.
+type pdhFmtCounterValueItemDouble struct {
+	Name     *uint16
+	FmtValue pdhFmtCounterValueDouble
+}
+
+func pdhError(function string, status uintptr) error {
+	return fmt.Errorf("%s failed with status 0x%08X", function, uint32(status))
+}
+
+// pdhCounter is a counter added to the query, for one instance or for all of
+// them.
+type pdhCounter struct {
+	handle    uintptr
+	path      string
+	object    string
+	counter   string
+	instanced bool
+	// wildcard is true if the counter collects all the instances, their
+	// names are returned along with the values.
+	wildcard bool
+	instance string
+}
+
+// pdhQuery collects performance counters with the Performance Data Helper
+// library.
+type pdhQuery struct {
+	logger   *zap.Logger
+	handle   uintptr
+	counters []*pdhCounter
+}
+
+func newPerfCounterQuery(logger *zap.Logger, perfCounters []PerfCounterConfig) (perfCounterQuery, error) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		return nil, errors.New("windowsperfcounters receiver is only supported on 64-bit windows")
+	}
+	q := &pdhQuery{logger: logger}
+	if status, _, _ := procPdhOpenQueryW.Call(0, 0, uintptr(unsafe.Pointer(&q.handle))); status != 0 {
+		return nil, pdhError("PdhOpenQueryW", status)
+	}
+
+	for _, pc := range perfCounters {
+		for _, counter := range pc.Counters {
+			if len(pc.Instances) == 0 {
+				q.add(&pdhCounter{
+					path:    fmt.Sprintf(`\%s\%s`, pc.Object, counter),
+					object:  pc.Object,
+					counter: counter,
+				})
+				continue
+			}
+			for _, instance := range pc.Instances {
+				q.add(&pdhCounter{
+					path:      fmt.Sprintf(`\%s(%s)\%s`, pc.Object, instance, counter),
+					object:    pc.Object,
+					counter:   counter,
+					instanced: true,
+					wildcard:  instance == "*",
+					instance:  instance,
+				})
+			}
+		}
+	}
+	if len(q.counters) == 0 {
+		q.close()
+		return nil, errors.New("none of the performance counters could be added")
+	}
+
+	// The rate counters need two samples, take the first one now so that the
+	// first scrape has values.
+	procPdhCollectQueryData.Call(q.handle)
+	return q, nil
+}
+
+// add adds the counter to the query, the counters that don't exist on this
+// host are logged and skipped.
+func (q *pdhQuery) add(c *pdhCounter) {
+	path, err := windows.UTF16PtrFromString(c.path)
+	if err != nil {
+		q.logger.Warn("Invalid performance counter path", zap.String("path", c.path), zap.Error(err))
+		return
+	}
+	status, _, _ := procPdhAddEnglishCounterW.Call(q.handle, uintptr(unsafe.Pointer(path)), 0, uintptr(unsafe.Pointer(&c.handle)))
+	if status != 0 {
+		q.logger.Warn("Failed to add the performance counter, skipping it",
+			zap.String("path", c.path), zap.Error(pdhError("PdhAddEnglishCounterW", status)))
+		return
+	}
+	q.counters = append(q.counters, c)
+}
+
+func (q *pdhQuery) collect() ([]counterValue, error) {
+	if status, _, _ := procPdhCollectQueryData.Call(q.handle); status != 0 && status != pdhNoData {
+		return nil, pdhError("PdhCollectQueryData", status)
+	}
+
+	var values []counterValue
+	var errs []error
+	for _, c := range q.counters {
+		if !c.wildcard {
+			var value pdhFmtCounterValueDouble
+			status, _, _ := procPdhGetFormattedCounterValue.Call(
+				c.handle, pdhFmtDouble|pdhFmtNoCap100, 0, uintptr(unsafe.Pointer(&value)))
+			if status == pdhInvalidData || !validData(value.CStatus) {
+				// The instance doesn't exist anymore or has no value yet.
+				continue
+			}
+			if status != 0 {
+				errs = append(errs, fmt.Errorf("%s: %v", c.path, pdhError("PdhGetFormattedCounterValue", status)))
+				continue
+			}
+			values = append(values, counterValue{
+				object:    c.object,
+				counter:   c.counter,
+				instanced: c.instanced,
+				instance:  c.instance,
+				value:     value.DoubleValue,
+			})
+			continue
+		}
+
+		items, err := formattedCounterArray(c.handle)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", c.path, err))
+			continue
+		}
+		// The instances with the same name, e.g. the processes running the
+		// same executable, are numbered like in the Performance Monitor.
+		seen := make(map[string]int)
+		for _, item := range items {
+			if !validData(item.value.CStatus) {
+				continue
+			}
+			instance := item.name
+			if n := seen[instance]; n > 0 {
+				seen[instance]++
+				instance += "#" + strconv.Itoa(n)
+			} else {
+				seen[instance] = 1
+			}
+			values = append(values, counterValue{
+				object:    c.object,
+				counter:   c.counter,
+				instanced: true,
+				instance:  instance,
+				value:     item.value.DoubleValue,
+			})
+		}
+	}
+	return values, oterr.CombineErrors(errs)
+}
+
+func (q *pdhQuery) close() error {
+	if status, _, _ := procPdhCloseQuery.Call(q.handle); status != 0 {
+		return pdhError("PdhCloseQuery", status)
+	}
+	return nil
+}
+
+func validData(status uint32) bool {
+	return status == pdhCStatusValidData || status == pdhCStatusNewData
+}
+
+// instanceValue is the value of an instance of a wildcard counter.
+type instanceValue struct {
+	name  string
+	value pdhFmtCounterValueDouble
+}
+
+// formattedCounterArray returns the values of all the instances of a
+// wildcard counter.
+func formattedCounterArray(counter uintptr) ([]instanceValue, error) {
+	var size, count uint32
+	status, _, _ := procPdhGetFormattedCounterArrayW.Call(
+		counter, pdhFmtDouble|pdhFmtNoCap100, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), 0)
+	if status == pdhInvalidData {
+		return nil, nil
+	}
+	if status != pdhMoreData {
+		return nil, pdhError("PdhGetFormattedCounterArrayW", status)
+	}
+
+	// The buffer holds the items followed by their names, allocate it as
+	// items to have it aligned.
+	itemSize := uint32(unsafe.Sizeof(pdhFmtCounterValueItemDouble{}))
+	buf := make([]pdhFmtCounterValueItemDouble, (size+itemSize-1)/itemSize)
+	status, _, _ = procPdhGetFormattedCounterArrayW.Call(
+		counter, pdhFmtDouble|pdhFmtNoCap100, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)),
+		uintptr(unsafe.Pointer(&buf[0])))
+	if status != 0 {
+		return nil, pdhError("PdhGetFormattedCounterArrayW", status)
+	}
+	values := make([]instanceValue, count)
+	for i := range values {
+		values[i] = instanceValue{name: utf16PtrToString(buf[i].Name), value: buf[i].FmtValue}
+	}
+	return values, nil
+}
+
+// utf16PtrToString converts a NUL terminated UTF-16 string.
+func utf16PtrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	var s []uint16
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; ptr = unsafe.Pointer(uintptr(ptr) + 2) {
+		s = append(s, *(*uint16)(ptr))
+	}
+	return windows.UTF16ToString(s)
+}
@@ -0,0 +1,170 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package windowsperfcountersreceiver collects Windows performance counters,
+// and optionally counts the events of Event Tracing for Windows (ETW)
+// providers, into metrics. It is only supported on Windows.
+package windowsperfcountersreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/scraperhelper"
+)
+
+const metricsSource = "WindowsPerfCounters"
+
+// perfCounterQuery collects the values of the configured performance
+// counters.
+type perfCounterQuery interface {
+	collect() ([]counterValue, error)
+	close() error
+}
+
+// etwSession counts the events of the configured ETW providers.
+type etwSession interface {
+	counts() []etwCount
+	close() error
+}
+
+type perfCountersReceiver struct {
+	logger  *zap.Logger
+	cfg     *Config
+	scraper *scraperhelper.Scraper
+
+	mu           sync.Mutex
+	query        perfCounterQuery
+	session      etwSession
+	sessionStart time.Time
+	started      bool
+	startOnce    sync.Once
+	stopOnce     sync.Once
+}
+
+var _ receiver.MetricsReceiver = (*perfCountersReceiver)(nil)
+
+func newPerfCountersReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	nextConsumer consumer.MetricsConsumer,
+) (*perfCountersReceiver, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	r := &perfCountersReceiver{logger: logger, cfg: cfg}
+	var err error
+	r.scraper, err = scraperhelper.NewScraper(logger, cfg.Name(), cfg.ScraperSettings, r.scrape, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *perfCountersReceiver) MetricsSource() string {
+	return metricsSource
+}
+
+// StartMetricsReception opens the performance counters query and the ETW
+// session, then starts scraping.
+func (r *perfCountersReceiver) StartMetricsReception(host receiver.Host) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	r.startOnce.Do(func() {
+		err = r.open()
+		if err != nil {
+			return
+		}
+		err = r.scraper.Start(host.Context())
+		r.started = err == nil
+	})
+	return err
+}
+
+func (r *perfCountersReceiver) open() error {
+	if len(r.cfg.PerfCounters) > 0 {
+		query, err := newPerfCounterQuery(r.logger, r.cfg.PerfCounters)
+		if err != nil {
+			return err
+		}
+		r.query = query
+	}
+	if len(r.cfg.ETW) > 0 {
+		session, err := newETWSession(r.logger, r.cfg.Name(), r.cfg.ETW)
+		if err != nil {
+			if r.query != nil {
+				r.query.close()
+			}
+			return err
+		}
+		r.session = session
+		r.sessionStart = time.Now()
+	}
+	return nil
+}
+
+// StopMetricsReception stops scraping and closes the query and the session.
+func (r *perfCountersReceiver) StopMetricsReception() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		var errs []error
+		if r.started {
+			if serr := r.scraper.Stop(); serr != nil {
+				errs = append(errs, serr)
+			}
+		}
+		if r.query != nil {
+			if qerr := r.query.close(); qerr != nil {
+				errs = append(errs, qerr)
+			}
+		}
+		if r.session != nil {
+			if serr := r.session.close(); serr != nil {
+				errs = append(errs, serr)
+			}
+		}
+		err = oterr.CombineErrors(errs)
+	})
+	return err
+}
+
+func (r *perfCountersReceiver) scrape(ctx context.Context) ([]*metricspb.Metric, error) {
+	now := time.Now()
+	var metrics []*metricspb.Metric
+	var err error
+	if r.query != nil {
+		var values []counterValue
+		values, err = r.query.collect()
+		metrics = perfCounterMetrics(r.cfg.MetricPrefix, values, now)
+	}
+	if r.session != nil {
+		if metric := etwMetric(r.cfg.MetricPrefix, r.session.counts(), r.sessionStart, now); metric != nil {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics, err
+}
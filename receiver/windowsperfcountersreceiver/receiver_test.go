@@ -0,0 +1,83 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcountersreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+type fakeQuery struct {
+	values []counterValue
+	err    error
+	closed bool
+}
+
+func (fq *fakeQuery) collect() ([]counterValue, error) {
+	return fq.values, fq.err
+}
+
+func (fq *fakeQuery) close() error {
+	fq.closed = true
+	return nil
+}
+
+type fakeSession struct {
+	eventCounts []etwCount
+	closed      bool
+}
+
+func (fs *fakeSession) counts() []etwCount {
+	return fs.eventCounts
+}
+
+func (fs *fakeSession) close() error {
+	fs.closed = true
+	return nil
+}
+
+func TestScrape(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.PerfCounters = []PerfCounterConfig{{Object: "Memory", Counters: []string{"Available Bytes"}}}
+	r, err := newPerfCountersReceiver(zap.NewNop(), cfg, exportertest.NewNopMetricsExporter())
+	require.NoError(t, err)
+
+	// The values collected before an error are still returned.
+	collectErr := errors.New("counter failed")
+	query := &fakeQuery{
+		values: []counterValue{{object: "Memory", counter: "Available Bytes", value: 1024}},
+		err:    collectErr,
+	}
+	session := &fakeSession{eventCounts: []etwCount{{provider: "kernel-process", eventID: 1, level: 4, count: 3}}}
+	r.query = query
+	r.session = session
+
+	metrics, err := r.scrape(context.Background())
+	assert.Equal(t, collectErr, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "memory/available_bytes", metrics[0].MetricDescriptor.Name)
+	assert.Equal(t, "etw/events", metrics[1].MetricDescriptor.Name)
+
+	require.NoError(t, r.StopMetricsReception())
+	assert.True(t, query.closed)
+	assert.True(t, session.closed)
+}
@@ -0,0 +1,30 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package windowsperfcountersreceiver
+
+import (
+	"go.uber.org/zap"
+)
+
+func newPerfCounterQuery(logger *zap.Logger, perfCounters []PerfCounterConfig) (perfCounterQuery, error) {
+	return nil, errNotSupported
+}
+
+func newETWSession(logger *zap.Logger, name string, providers []ETWProviderConfig) (etwSession, error) {
+	return nil, errNotSupported
+}
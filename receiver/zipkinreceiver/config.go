@@ -14,9 +14,44 @@
 
 package zipkinreceiver
 
-import "github.com/open-telemetry/opentelemetry-service/config/configmodels"
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
+)
 
 // Config defines configuration for Zipkin receiver.
 type Config struct {
 	configmodels.ReceiverSettings `mapstructure:",squash"`
+
+	// PeerAddress adds the address of the clients to the node of the
+	// received spans.
+	PeerAddress peeraddr.Settings `mapstructure:"peer-address"`
+
+	// Auth is the name of the extension validating the bearer tokens of the
+	// requests, none are authenticated if it is empty.
+	Auth string `mapstructure:"auth"`
+
+	// MaxInFlight is the maximum number of requests processed concurrently,
+	// the requests exceeding it are throttled. No limit is applied if it is 0.
+	MaxInFlight int `mapstructure:"max-in-flight"`
+
+	// AllowedCIDRs are the networks, in CIDR notation, of the clients allowed
+	// to connect, the connections of the other clients are closed. All the
+	// clients are allowed if it is empty.
+	AllowedCIDRs []string `mapstructure:"allowed-cidrs"`
+
+	// VirtualHosts are the settings of the virtual hosts, by name, selected
+	// by the Host header of the requests. The requests for other hosts are
+	// served with the settings of the receiver.
+	VirtualHosts map[string]vhost.Settings `mapstructure:"virtual-hosts"`
+
+	// TLSCredentials serves the receiver over TLS, if set.
+	TLSCredentials *tlsutil.Credentials `mapstructure:"tls-credentials"`
+
+	// Decompression limits the decompressed size of the compressed bodies,
+	// the requests exceeding the limits are rejected with the 413 status.
+	Decompression decompression.Settings `mapstructure:"decompression"`
 }
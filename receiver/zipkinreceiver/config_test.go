@@ -23,6 +23,10 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -49,5 +53,27 @@ func TestLoadConfig(t *testing.T) {
 				NameVal:  "zipkin/customname",
 				Endpoint: "127.0.0.1:8765",
 			},
+			PeerAddress: peeraddr.Settings{
+				Enabled:         true,
+				ResolveHostname: true,
+			},
+			Auth:         "bearer-token-auth",
+			MaxInFlight:  100,
+			AllowedCIDRs: []string{"10.0.0.0/8"},
+			VirtualHosts: map[string]vhost.Settings{
+				"tenant-a": {
+					Hosts:      []string{"a.ingest.example.com"},
+					Auth:       "oidc-auth",
+					Attributes: map[string]string{"tenant": "a"},
+				},
+			},
+			TLSCredentials: &tlsutil.Credentials{
+				CertFile: "testdata/server.crt",
+				KeyFile:  "testdata/server.key",
+			},
+			Decompression: decompression.Settings{
+				MaxDecompressedSize: 10485760,
+				MaxRatio:            50,
+			},
 		})
 }
@@ -16,12 +16,19 @@ package zipkinreceiver
 
 import (
 	"context"
+	"fmt"
 
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/admission"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/netacl"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 )
 
@@ -56,6 +63,7 @@ func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
 			NameVal:  typeStr,
 			Endpoint: defaultBindEndpoint,
 		},
+		Decompression: decompression.DefaultSettings(),
 	}
 }
 
@@ -68,7 +76,33 @@ func (f *Factory) CreateTraceReceiver(
 ) (receiver.TraceReceiver, error) {
 
 	rCfg := cfg.(*Config)
-	return New(rCfg.Endpoint, nextConsumer)
+	acl, err := netacl.New(rCfg.AllowedCIDRs, rCfg.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed-cidrs of %s receiver: %v", rCfg.Name(), err)
+	}
+	router, err := vhost.New(logger, rCfg.VirtualHosts, rCfg.Auth, rCfg.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid virtual-hosts of %s receiver: %v", rCfg.Name(), err)
+	}
+	tlsCfg, err := rCfg.TLSCredentials.ServerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls-credentials of %s receiver: %v", rCfg.Name(), err)
+	}
+	if err := rCfg.Decompression.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid decompression of %s receiver: %v", rCfg.Name(), err)
+	}
+	zr, err := New(rCfg.Endpoint, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	zr.tlsConfig = tlsCfg
+	zr.acl = acl
+	zr.peerAddr = peeraddr.NewAnnotator(rCfg.PeerAddress)
+	zr.authenticator = auth.NewAuthenticator(logger, rCfg.Auth, rCfg.Name())
+	zr.router = router
+	zr.admission = admission.NewController(rCfg.MaxInFlight, rCfg.Name())
+	zr.decompression = rCfg.Decompression
+	return zr, nil
 }
 
 // CreateMetricsReceiver creates a metrics receiver based on provided config.
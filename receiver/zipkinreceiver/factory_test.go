@@ -16,6 +16,7 @@ package zipkinreceiver
 
 import (
 	"context"
+	"path"
 	"testing"
 
 	"go.uber.org/zap"
@@ -24,6 +25,8 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -55,3 +58,62 @@ func TestCreateReceiver(t *testing.T) {
 	assert.Equal(t, err, configerror.ErrDataTypeIsNotSupported)
 	assert.Nil(t, mReceiver)
 }
+
+func TestCreateReceiver_AllowedCIDRs(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	cfg.AllowedCIDRs = []string{"10.0.0.0/8"}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.Nil(t, err, "receiver creation failed")
+	assert.NotNil(t, tReceiver.(*ZipkinReceiver).acl)
+
+	cfg.AllowedCIDRs = []string{"10.0.0.0/8", "10.0.0"}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.EqualError(t, err, `invalid allowed-cidrs of zipkin receiver: invalid CIDR "10.0.0"`)
+}
+
+func TestCreateReceiver_VirtualHosts(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	cfg.VirtualHosts = map[string]vhost.Settings{"tenant-a": {Hosts: []string{"a.example.com"}}}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.Nil(t, err, "receiver creation failed")
+	assert.NotNil(t, tReceiver.(*ZipkinReceiver).router)
+
+	cfg.VirtualHosts = map[string]vhost.Settings{"tenant-a": {}}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.EqualError(t, err, `invalid virtual-hosts of zipkin receiver: virtual host "tenant-a" has no hosts`)
+}
+
+func TestCreateReceiver_TLSCredentials(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	cfg.TLSCredentials = &tlsutil.Credentials{
+		CertFile: path.Join(".", "testdata", "server.crt"),
+		KeyFile:  path.Join(".", "testdata", "server.key"),
+	}
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.Nil(t, err, "receiver creation failed")
+	assert.NotNil(t, tReceiver.(*ZipkinReceiver).tlsConfig)
+
+	cfg.TLSCredentials.KeyFile = ""
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.EqualError(t, err, "invalid tls-credentials of zipkin receiver: both cert-file and key-file must be set")
+}
+
+func TestCreateReceiver_Decompression(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	cfg.Decompression.MaxRatio = 10
+	tReceiver, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.Nil(t, err, "receiver creation failed")
+	assert.Equal(t, cfg.Decompression, tReceiver.(*ZipkinReceiver).decompression)
+
+	cfg.Decompression.MaxRatio = -1
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, &mockTraceConsumer{})
+	assert.EqualError(t, err, "invalid decompression of zipkin receiver: max-ratio must not be negative")
+}
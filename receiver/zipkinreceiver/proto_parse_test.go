@@ -15,17 +15,22 @@
 package zipkinreceiver
 
 import (
+	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	zipkin_proto3 "github.com/openzipkin/zipkin-go/proto/v2"
+	"github.com/stretchr/testify/require"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
 )
 
@@ -194,3 +199,50 @@ func TestConvertSpansToTraceSpans_protobuf(t *testing.T) {
 		t.Errorf("Got:\n\t%v\nWant:\n\t%v", g, w)
 	}
 }
+
+func TestServeHTTP_Protobuf(t *testing.T) {
+	protoBlob, err := proto.Marshal(&zipkin_proto3.ListOfSpans{
+		Spans: []*zipkin_proto3.Span{
+			{
+				TraceId:       []byte{0x7F, 0x6F, 0x5F, 0x4F, 0x3F, 0x2F, 0x1F, 0x0F, 0xF7, 0xF6, 0xF5, 0xF4, 0xF3, 0xF2, 0xF1, 0xF0},
+				Id:            []byte{0xF7, 0xF6, 0xF5, 0xF4, 0xF3, 0xF2, 0xF1, 0xF0},
+				Name:          "ProtoSpan1",
+				LocalEndpoint: &zipkin_proto3.Endpoint{ServiceName: "svc-1"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, contentType := range []string{
+		"application/x-protobuf",
+		"application/protobuf",
+		"Application/X-Protobuf; charset=utf-8",
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("127.0.0.1:0", sink)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(protoBlob))
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusAccepted, rec.Code)
+
+			traces := sink.AllTraces()
+			require.Len(t, traces, 1)
+			require.Len(t, traces[0].Spans, 1)
+			require.Equal(t, "ProtoSpan1", traces[0].Spans[0].Name.Value)
+			require.Equal(t, "svc-1", traces[0].Node.ServiceInfo.Name)
+		})
+	}
+
+	// The JSON bodies can't be decoded as protobuf.
+	zr, err := New("127.0.0.1:0", new(exportertest.SinkTraceExporter))
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", strings.NewReader(`[{"traceId":"1"}]`))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -15,13 +15,11 @@
 package zipkinreceiver
 
 import (
-	"compress/gzip"
-	"compress/zlib"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
+	"mime"
 	"net"
 	"net/http"
 	"strconv"
@@ -39,6 +37,13 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/admission"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/netacl"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
+	"github.com/open-telemetry/opentelemetry-service/internal/thriftutil"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
@@ -59,6 +64,30 @@ type ZipkinReceiver struct {
 	startOnce sync.Once
 	stopOnce  sync.Once
 	server    *http.Server
+
+	// peerAddr adds the address of the clients to the received spans.
+	peerAddr *peeraddr.Annotator
+
+	// authenticator authenticates the requests, if not nil.
+	authenticator *auth.Authenticator
+
+	// router routes the requests to the virtual hosts, if not nil. It
+	// replaces the authenticator.
+	router *vhost.Router
+
+	// admission throttles the requests exceeding the in-flight limits, if
+	// not nil.
+	admission *admission.Controller
+
+	// acl closes the connections of the clients outside the allowed
+	// networks, if not nil.
+	acl *netacl.ACL
+
+	// tlsConfig serves the receiver over TLS, if not nil.
+	tlsConfig *tls.Config
+
+	// decompression limits the decompressed bodies of the requests.
+	decompression decompression.Settings
 }
 
 var _ receiver.TraceReceiver = (*ZipkinReceiver)(nil)
@@ -71,8 +100,9 @@ func New(address string, nextConsumer consumer.TraceConsumer) (*ZipkinReceiver,
 	}
 
 	zr := &ZipkinReceiver{
-		addr:         address,
-		nextConsumer: nextConsumer,
+		addr:          address,
+		nextConsumer:  nextConsumer,
+		decompression: decompression.DefaultSettings(),
 	}
 	return zr, nil
 }
@@ -111,9 +141,23 @@ func (zr *ZipkinReceiver) StartTraceReception(host receiver.Host) error {
 			err = lerr
 			return
 		}
+		ln = zr.acl.Listener(ln)
+		if zr.tlsConfig != nil {
+			ln = tls.NewListener(ln, zr.tlsConfig)
+		}
 
 		zr.host = host
-		server := &http.Server{Handler: zr}
+		var handler http.Handler = zr
+		if zr.router != nil {
+			handler = zr.router.HTTPHandler(handler)
+		} else if zr.authenticator != nil {
+			handler = zr.authenticator.HTTPHandler(handler)
+		}
+		if zr.admission != nil {
+			// The requests are throttled before any work is done on them.
+			handler = zr.admission.HTTPHandler(handler)
+		}
+		server := &http.Server{Handler: handler}
 		zr.server = server
 		go func() {
 			host.ReportFatalError(server.Serve(ln))
@@ -127,7 +171,7 @@ func (zr *ZipkinReceiver) StartTraceReception(host receiver.Host) error {
 
 // v1ToTraceSpans parses Zipkin v1 JSON traces and converts them to OpenCensus Proto spans.
 func (zr *ZipkinReceiver) v1ToTraceSpans(blob []byte, hdr http.Header) (reqs []consumerdata.TraceData, err error) {
-	if hdr.Get("Content-Type") == "application/x-thrift" {
+	if mediaType(hdr) == "application/x-thrift" {
 		zSpans, err := deserializeThrift(blob)
 		if err != nil {
 			return nil, err
@@ -142,11 +186,26 @@ func (zr *ZipkinReceiver) v1ToTraceSpans(blob []byte, hdr http.Header) (reqs []c
 // This code comes from jaegertracing/jaeger, ideally we should have imported
 // it but this was creating many conflicts so brought the code to here.
 // https://github.com/jaegertracing/jaeger/blob/6bc0c122bfca8e737a747826ae60a22a306d7019/model/converter/thrift/zipkin/deserialize.go#L36
+//
+// Besides the lists of spans, the legacy Brave transports may send spans one
+// after the other, which are decoded until the end of the payload like the
+// Zipkin server does.
 func deserializeThrift(b []byte) ([]*zipkincore.Span, error) {
-	buffer := thrift.NewTMemoryBuffer()
-	buffer.Write(b)
+	// The sizes of the lists are checked so that malformed payloads don't
+	// preallocate unbounded memory.
+	transport := thriftutil.NewBinaryProtocol(b)
+	if len(b) > 0 && thrift.TType(b[0]) != thrift.STRUCT {
+		var spans []*zipkincore.Span
+		for transport.Transport().RemainingBytes() > 0 {
+			zs := &zipkincore.Span{}
+			if err := zs.Read(transport); err != nil {
+				return nil, err
+			}
+			spans = append(spans, zs)
+		}
+		return spans, nil
+	}
 
-	transport := thrift.NewTBinaryProtocolTransport(buffer)
 	_, size, err := transport.ReadListBegin() // Ignore the returned element type
 	if err != nil {
 		return nil, err
@@ -175,9 +234,9 @@ func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []c
 	var zipkinSpans []*zipkinmodel.SpanModel
 
 	// Zipkin can send protobuf via http
-	switch hdr.Get("Content-Type") {
+	switch mediaType(hdr) {
 	// TODO: (@odeke-em) record the unique types of Content-Type uploads
-	case "application/x-protobuf":
+	case "application/x-protobuf", "application/protobuf":
 		zipkinSpans, err = zipkinproto.ParseSpans(blob, debugWasSet)
 
 	default: // By default, we'll assume using JSON
@@ -223,6 +282,17 @@ func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []c
 	return reqs, nil
 }
 
+// mediaType returns the lowercased media type of the Content-Type header,
+// without its parameters, e.g. "application/x-protobuf" for
+// "application/x-protobuf; charset=utf-8".
+func mediaType(hdr http.Header) string {
+	contentType := hdr.Get("Content-Type")
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
 func (zr *ZipkinReceiver) deserializeFromJSON(jsonBlob []byte, debugWasSet bool) (zs []*zipkinmodel.SpanModel, err error) {
 	if err = json.Unmarshal(jsonBlob, &zs); err != nil {
 		return nil, err
@@ -241,42 +311,6 @@ func (zr *ZipkinReceiver) StopTraceReception() error {
 	return err
 }
 
-// processBodyIfNecessary checks the "Content-Encoding" HTTP header and if
-// a compression such as "gzip", "deflate", "zlib", is found, the body will
-// be uncompressed accordingly or return the body untouched if otherwise.
-// Clients such as Zipkin-Java do this behavior e.g.
-//    send "Content-Encoding":"gzip" of the JSON content.
-func processBodyIfNecessary(req *http.Request) io.Reader {
-	switch req.Header.Get("Content-Encoding") {
-	default:
-		return req.Body
-
-	case "gzip":
-		return gunzippedBodyIfPossible(req.Body)
-
-	case "deflate", "zlib":
-		return zlibUncompressedbody(req.Body)
-	}
-}
-
-func gunzippedBodyIfPossible(r io.Reader) io.Reader {
-	gzr, err := gzip.NewReader(r)
-	if err != nil {
-		// Just return the old body as was
-		return r
-	}
-	return gzr
-}
-
-func zlibUncompressedbody(r io.Reader) io.Reader {
-	zr, err := zlib.NewReader(r)
-	if err != nil {
-		// Just return the old body as was
-		return r
-	}
-	return zr
-}
-
 const (
 	zipkinV1TagValue = "zipkinV1"
 	zipkinV2TagValue = "zipkinV2"
@@ -305,15 +339,23 @@ func (zr *ZipkinReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
 
-	pr := processBodyIfNecessary(r)
-	slurp, _ := ioutil.ReadAll(pr)
-	if c, ok := pr.(io.Closer); ok {
-		_ = c.Close()
-	}
+	// Clients such as Zipkin-Java send "Content-Encoding":"gzip" of the JSON
+	// content.
+	slurp, err := decompression.ReadAll(r, zr.decompression)
 	_ = r.Body.Close()
+	if err != nil {
+		if decompression.IsCompressed(r) {
+			observability.RecordDecompressionFailure(ctxWithReceiverName)
+		}
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), decompression.StatusCode(err))
+		return
+	}
 
 	var tds []consumerdata.TraceData
-	var err error
 	if asZipkinv1 {
 		tds, err = zr.v1ToTraceSpans(slurp, r.Header)
 	} else {
@@ -329,9 +371,17 @@ func (zr *ZipkinReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var peerIP string
+	if zr.peerAddr != nil {
+		peerIP = peeraddr.FromHTTP(r)
+	}
+
+	vh := vhost.FromContext(r.Context())
+
 	tdsSize := 0
 	for _, td := range tds {
 		td.SourceFormat = "zipkin"
+		td.Node = vh.Node(zr.peerAddr.Node(td.Node, peerIP))
 		zr.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td)
 		tdsSize += len(td.Spans)
 	}
@@ -398,6 +448,7 @@ func zipkinSpanToTraceSpan(zs *zipkinmodel.SpanModel) (*tracepb.Span, *commonpb.
 		Attributes:   zipkinTagsToTraceAttributes(zs.Tags),
 		TimeEvents:   zipkinAnnotationsToProtoTimeEvents(zs.Annotations),
 	}
+	tracetranslator.OCSpanFieldsFromAttributes(pbs)
 
 	return pbs, node, nil
 }
@@ -0,0 +1,107 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package zipkinreceiver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	zipkinproto "github.com/openzipkin/zipkin-go/proto/v2"
+)
+
+// FuzzV1ToTraceSpans decodes and translates the input as a Zipkin V1 upload,
+// in JSON or Thrift, the decoding and the translation must not panic.
+func FuzzV1ToTraceSpans(f *testing.F) {
+	blob, err := ioutil.ReadFile("../../translator/trace/zipkin/testdata/zipkin_v1_single_batch.json")
+	if err != nil {
+		f.Fatalf("Failed to read sample JSON file: %v", err)
+	}
+	f.Add(blob, false)
+
+	blob, err = ioutil.ReadFile("../../translator/trace/zipkin/testdata/zipkin_v1_thrift_single_batch.json")
+	if err != nil {
+		f.Fatalf("Failed to read sample Thrift file: %v", err)
+	}
+	var zSpans []*zipkincore.Span
+	if err = json.Unmarshal(blob, &zSpans); err != nil {
+		f.Fatalf("Failed to unmarshal sample Thrift file: %v", err)
+	}
+	f.Add(serializeThrift(f, zSpans), true)
+
+	zr := new(ZipkinReceiver)
+	f.Fuzz(func(t *testing.T, blob []byte, asThrift bool) {
+		hdr := http.Header{}
+		if asThrift {
+			hdr.Set("Content-Type", "application/x-thrift")
+		}
+		_, _ = zr.v1ToTraceSpans(blob, hdr)
+	})
+}
+
+// FuzzV2ToTraceSpans decodes and translates the input as a Zipkin V2 upload,
+// in JSON or Protobuf, the decoding and the translation must not panic.
+func FuzzV2ToTraceSpans(f *testing.F) {
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	if err != nil {
+		f.Fatalf("Failed to read sample JSON file: %v", err)
+	}
+	f.Add(blob, false)
+
+	var zSpans []*zipkinmodel.SpanModel
+	if err = json.Unmarshal(blob, &zSpans); err != nil {
+		f.Fatalf("Failed to unmarshal sample JSON file: %v", err)
+	}
+	protoBlob, err := zipkinproto.SpanSerializer{}.Serialize(zSpans)
+	if err != nil {
+		f.Fatalf("Failed to serialize the sample spans to Protobuf: %v", err)
+	}
+	f.Add(protoBlob, true)
+
+	zr := new(ZipkinReceiver)
+	f.Fuzz(func(t *testing.T, blob []byte, asProto bool) {
+		hdr := http.Header{}
+		if asProto {
+			hdr.Set("Content-Type", "application/x-protobuf")
+		}
+		_, _ = zr.v2ToTraceSpans(blob, hdr)
+	})
+}
+
+// serializeThrift encodes the spans as a Thrift list, as uploaded by the
+// Zipkin V1 clients.
+func serializeThrift(f *testing.F, zSpans []*zipkincore.Span) []byte {
+	buffer := thrift.NewTMemoryBuffer()
+	transport := thrift.NewTBinaryProtocolTransport(buffer)
+	if err := transport.WriteListBegin(thrift.STRUCT, len(zSpans)); err != nil {
+		f.Fatalf("Failed to serialize the spans to Thrift: %v", err)
+	}
+	for _, zs := range zSpans {
+		if err := zs.Write(transport); err != nil {
+			f.Fatalf("Failed to serialize the spans to Thrift: %v", err)
+		}
+	}
+	if err := transport.WriteListEnd(); err != nil {
+		f.Fatalf("Failed to serialize the spans to Thrift: %v", err)
+	}
+	return buffer.Bytes()
+}
@@ -16,29 +16,44 @@ package zipkinreceiver
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"contrib.go.opencensus.io/exporter/zipkin"
+	"github.com/apache/thrift/lib/go/thrift"
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
 	openzipkin "github.com/openzipkin/zipkin-go"
 	zipkinmodel "github.com/openzipkin/zipkin-go/model"
 	zhttp "github.com/openzipkin/zipkin-go/reporter/http"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/auth"
+	"github.com/open-telemetry/opentelemetry-service/internal/decompression"
+	"github.com/open-telemetry/opentelemetry-service/internal/peeraddr"
 	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/internal/tlsutil"
+	"github.com/open-telemetry/opentelemetry-service/internal/vhost"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
@@ -114,6 +129,31 @@ func TestShortIDSpanConversion(t *testing.T) {
 	}
 }
 
+func TestOCSpanFieldsFromTags(t *testing.T) {
+	traceID, _ := zipkinmodel.TraceIDFromHex("0102030405060708")
+	zs := zipkinmodel.SpanModel{
+		SpanContext: zipkinmodel.SpanContext{TraceID: traceID, ID: zipkinmodel.ID(traceID.Low)},
+		Tags: map[string]string{
+			"oc.sameprocessasparentspan": "false",
+			"oc.span.childcount":         "3",
+		},
+	}
+
+	ocSpan, _, err := zipkinSpanToTraceSpan(&zs)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if ocSpan.SameProcessAsParentSpan == nil || ocSpan.SameProcessAsParentSpan.Value {
+		t.Errorf("SameProcessAsParentSpan: got=%v want=false", ocSpan.SameProcessAsParentSpan)
+	}
+	if ocSpan.ChildSpanCount == nil || ocSpan.ChildSpanCount.Value != 3 {
+		t.Errorf("ChildSpanCount: got=%v want=3", ocSpan.ChildSpanCount)
+	}
+	if ocSpan.Attributes != nil {
+		t.Errorf("the fields must not remain as attributes, got %v", ocSpan.Attributes)
+	}
+}
+
 func TestNew(t *testing.T) {
 	type args struct {
 		address      string
@@ -556,3 +596,353 @@ func TestStartTraceReception(t *testing.T) {
 		})
 	}
 }
+
+func TestStartTraceReception_IPv6(t *testing.T) {
+	addr := testutils.GetAvailableLocalIPv6Address(t)
+	sink := new(exportertest.SinkTraceExporter)
+	zr, err := New(addr, sink)
+	require.NoError(t, err)
+	require.NoError(t, zr.StartTraceReception(receivertest.NewMockHost()))
+	defer zr.StopTraceReception()
+
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	resp, err := http.Post(fmt.Sprintf("http://%s/api/v2/spans", addr), "application/json", bytes.NewReader(blob))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.NotEmpty(t, sink.AllTraces())
+}
+
+func TestStartTraceReception_TLS(t *testing.T) {
+	tlsCfg, err := (&tlsutil.Credentials{
+		CertFile: path.Join(".", "testdata", "server.crt"),
+		KeyFile:  path.Join(".", "testdata", "server.key"),
+	}).ServerConfig()
+	require.NoError(t, err)
+
+	addr := testutils.GetAvailableLocalAddress(t)
+	sink := new(exportertest.SinkTraceExporter)
+	zr, err := New(addr, sink)
+	require.NoError(t, err)
+	zr.tlsConfig = tlsCfg
+	require.NoError(t, zr.StartTraceReception(receivertest.NewMockHost()))
+	defer zr.StopTraceReception()
+
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Post(fmt.Sprintf("https://%s/api/v2/spans", addr), "application/json", bytes.NewReader(blob))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.NotEmpty(t, sink.AllTraces())
+}
+
+func TestServeHTTP_PeerAddress(t *testing.T) {
+	sink := new(exportertest.SinkTraceExporter)
+	zr, err := New("127.0.0.1:0", sink)
+	require.NoError(t, err)
+	zr.peerAddr = peeraddr.NewAnnotator(peeraddr.Settings{Enabled: true})
+
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(blob))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.1:5678"
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	got := sink.AllTraces()
+	require.NotEmpty(t, got)
+	for _, td := range got {
+		require.Equal(t, "10.0.0.1", td.Node.Attributes[peeraddr.IPAttribute])
+		require.NotEmpty(t, td.Node.ServiceInfo.GetName())
+	}
+}
+
+func TestServeHTTP_Thrift(t *testing.T) {
+	host := &zipkincore.Endpoint{Ipv4: 0x0a000004, Port: 8080, ServiceName: "frontend"}
+	zSpans := []*zipkincore.Span{
+		{
+			TraceID: 1,
+			ID:      2,
+			Name:    "get",
+			Annotations: []*zipkincore.Annotation{
+				{Timestamp: 1544805927453923, Value: zipkincore.CLIENT_SEND, Host: host},
+				{Timestamp: 1544805927457717, Value: zipkincore.CLIENT_RECV, Host: host},
+			},
+		},
+		{
+			TraceID: 1,
+			ID:      3,
+			Name:    "put",
+			BinaryAnnotations: []*zipkincore.BinaryAnnotation{{
+				Key:            zipkincore.LOCAL_COMPONENT,
+				Value:          []byte("frontend"),
+				AnnotationType: zipkincore.AnnotationType_STRING,
+				Host:           host,
+			}},
+		},
+	}
+
+	// The spans are sent as a list, or one after the other by the legacy
+	// Brave transports.
+	buffer := thrift.NewTMemoryBuffer()
+	protocol := thrift.NewTBinaryProtocolTransport(buffer)
+	require.NoError(t, protocol.WriteListBegin(thrift.STRUCT, len(zSpans)))
+	for _, zSpan := range zSpans {
+		require.NoError(t, zSpan.Write(protocol))
+	}
+	require.NoError(t, protocol.WriteListEnd())
+	list := append([]byte(nil), buffer.Bytes()...)
+
+	buffer.Reset()
+	for _, zSpan := range zSpans {
+		require.NoError(t, zSpan.Write(protocol))
+	}
+	concatenated := append([]byte(nil), buffer.Bytes()...)
+
+	for name, blob := range map[string][]byte{"list": list, "concatenated": concatenated} {
+		t.Run(name, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("127.0.0.1:0", sink)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/spans", bytes.NewReader(blob))
+			req.Header.Set("Content-Type", "application/x-thrift")
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusAccepted, rec.Code)
+
+			traces := sink.AllTraces()
+			require.Len(t, traces, 1)
+			require.Equal(t, "frontend", traces[0].Node.ServiceInfo.Name)
+			require.Equal(t, "10.0.0.4", traces[0].Node.Attributes["ipv4"])
+			require.Len(t, traces[0].Spans, 2)
+			require.Equal(t, "get", traces[0].Spans[0].Name.Value)
+			require.Equal(t, tracepb.Span_CLIENT, traces[0].Spans[0].Kind)
+			require.Equal(t, "put", traces[0].Spans[1].Name.Value)
+		})
+	}
+
+	// A truncated payload is rejected.
+	zr, err := New("127.0.0.1:0", new(exportertest.SinkTraceExporter))
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/spans", bytes.NewReader(concatenated[:len(concatenated)-3]))
+	req.Header.Set("Content-Type", "application/x-thrift")
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServeHTTP_Decompression(t *testing.T) {
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	gzw := gzip.NewWriter(&gzipped)
+	_, err = gzw.Write(blob)
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	tests := []struct {
+		name     string
+		settings decompression.Settings
+		want     int
+	}{
+		{"within limits", decompression.DefaultSettings(), http.StatusAccepted},
+		{"too large", decompression.Settings{MaxDecompressedSize: int64(len(blob) - 1)}, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("127.0.0.1:0", sink)
+			require.NoError(t, err)
+			zr.decompression = tt.settings
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(gzipped.Bytes()))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+			require.Equal(t, tt.want, rec.Code)
+			require.Equal(t, tt.want == http.StatusAccepted, len(sink.AllTraces()) > 0)
+		})
+	}
+}
+
+func TestServeHTTP_Deflate(t *testing.T) {
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	var deflated bytes.Buffer
+	zw := zlib.NewWriter(&deflated)
+	_, err = zw.Write(blob)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	sink := new(exportertest.SinkTraceExporter)
+	zr, err := New("127.0.0.1:0", sink)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(deflated.Bytes()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "deflate")
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.NotEmpty(t, sink.AllTraces())
+}
+
+func TestServeHTTP_DecompressionFailure(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	zr, err := New("127.0.0.1:0", new(exportertest.SinkTraceExporter))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		zr.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDecompressionFailures(zipkinV2TagValue, 2))
+}
+
+func TestStartTraceReception_Auth(t *testing.T) {
+	auth.Register("test-auth", auth.ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, auth.ErrInvalidToken
+		}
+		return ctx, nil
+	}))
+	defer auth.Unregister("test-auth")
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+	cfg.Auth = "test-auth"
+	sink := new(exportertest.SinkTraceExporter)
+	tr, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, tr.StartTraceReception(receivertest.NewMockHost()))
+	defer tr.StopTraceReception()
+
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	post := func(authorization string) int {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/api/v2/spans", cfg.Endpoint), bytes.NewReader(blob))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusUnauthorized, post(""))
+	require.Equal(t, http.StatusUnauthorized, post("Bearer wrong"))
+	require.Empty(t, sink.AllTraces())
+	require.Equal(t, http.StatusAccepted, post("Bearer secret"))
+	require.NotEmpty(t, sink.AllTraces())
+}
+
+func TestStartTraceReception_VirtualHosts(t *testing.T) {
+	auth.Register("test-auth", auth.ValidatorFunc(func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, auth.ErrInvalidToken
+		}
+		return ctx, nil
+	}))
+	defer auth.Unregister("test-auth")
+
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+	cfg.Auth = "test-auth"
+	cfg.VirtualHosts = map[string]vhost.Settings{
+		"tenant-a": {
+			Hosts:      []string{"a.example.com"},
+			Attributes: map[string]string{"tenant": "a"},
+		},
+	}
+	sink := new(exportertest.SinkTraceExporter)
+	tr, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, tr.StartTraceReception(receivertest.NewMockHost()))
+	defer tr.StopTraceReception()
+
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	post := func(host string) int {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/api/v2/spans", cfg.Endpoint), bytes.NewReader(blob))
+		require.NoError(t, err)
+		req.Host = host
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The requests for other hosts are authenticated by the receiver.
+	require.Equal(t, http.StatusUnauthorized, post("b.example.com"))
+	require.Empty(t, sink.AllTraces())
+
+	// The virtual host doesn't authenticate the requests and stamps its
+	// attributes on the spans.
+	require.Equal(t, http.StatusAccepted, post("a.example.com"))
+	got := sink.AllTraces()
+	require.NotEmpty(t, got)
+	for _, td := range got {
+		require.Equal(t, "a", td.Node.Attributes["tenant"])
+		require.NotEmpty(t, td.Node.ServiceInfo.GetName())
+	}
+}
+
+// blockingConsumer blocks the consumption of the spans until unblocked.
+type blockingConsumer struct {
+	consuming chan struct{}
+	unblock   chan struct{}
+}
+
+func (bc *blockingConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	bc.consuming <- struct{}{}
+	<-bc.unblock
+	return nil
+}
+
+func TestStartTraceReception_MaxInFlight(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = testutils.GetAvailableLocalAddress(t)
+	cfg.MaxInFlight = 1
+	next := &blockingConsumer{consuming: make(chan struct{}, 1), unblock: make(chan struct{})}
+	tr, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+	require.NoError(t, tr.StartTraceReception(receivertest.NewMockHost()))
+	defer tr.StopTraceReception()
+
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.NoError(t, err)
+	post := func() int {
+		resp, err := http.Post(fmt.Sprintf("http://%s/api/v2/spans", cfg.Endpoint), "application/json", bytes.NewReader(blob))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	first := make(chan int)
+	go func() { first <- post() }()
+	<-next.consuming
+	require.Equal(t, http.StatusTooManyRequests, post())
+
+	close(next.unblock)
+	require.Equal(t, http.StatusAccepted, <-first)
+	go func() { <-next.consuming }()
+	require.Equal(t, http.StatusAccepted, post())
+}
@@ -17,32 +17,65 @@
 package builder
 
 import (
+	"bytes"
+	"encoding/csv"
 	"flag"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 const (
 	// flags
-	configCfg      = "config"
-	memBallastFlag = "mem-ballast-size-mib"
+	configCfg           = "config"
+	memBallastFlag      = "mem-ballast-size-mib"
+	shutdownTimeoutFlag = "shutdown-timeout"
 )
 
 // Flags adds flags related to basic building of the collector application to the given flagset.
 func Flags(flags *flag.FlagSet) {
-	flags.String(configCfg, "", "Path to the config file")
+	flags.Var(&stringSliceValue{}, configCfg,
+		"Path to a config file, can be repeated to merge several files, the later files override the earlier ones")
 	flags.Uint(memBallastFlag, 0,
 		fmt.Sprintf("Flag to specify size of memory (MiB) ballast to set. Ballast is not used when this is not specified. "+
 			"default settings: 0"))
+	flags.Duration(shutdownTimeoutFlag, 10*time.Second,
+		"Maximum duration to drain the batches and the queues of the processors on shutdown")
 }
 
-// GetConfigFile gets the config file from the config file flag.
-func GetConfigFile(v *viper.Viper) string {
-	return v.GetString(configCfg)
+// GetConfigFiles gets the config files from the config file flag, in the order
+// they must be merged.
+func GetConfigFiles(v *viper.Viper) []string {
+	return v.GetStringSlice(configCfg)
 }
 
 // MemBallastSize returns the size of memory ballast to use in MBs
 func MemBallastSize(v *viper.Viper) int {
 	return v.GetInt(memBallastFlag)
 }
+
+// ShutdownTimeout returns the maximum duration to drain the processors on
+// shutdown.
+func ShutdownTimeout(v *viper.Viper) time.Duration {
+	return v.GetDuration(shutdownTimeoutFlag)
+}
+
+// stringSliceValue is a flag.Value appending the value of each occurrence of the
+// flag. Viper reads the flags of type "stringSlice", derived from the name of
+// the type, as a comma-separated list.
+type stringSliceValue []string
+
+func (s *stringSliceValue) String() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(*s)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (s *stringSliceValue) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
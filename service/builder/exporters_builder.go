@@ -30,6 +30,9 @@ import (
 type builtExporter struct {
 	te exporter.TraceExporter
 	me exporter.MetricsExporter
+
+	// wrapper is true if the exporter wraps other exporters.
+	wrapper bool
 }
 
 // Shutdown the trace component and the metrics component of an exporter.
@@ -53,10 +56,18 @@ func (exp *builtExporter) Shutdown() error {
 // Exporters is a map of exporters created from exporter configs.
 type Exporters map[configmodels.Exporter]*builtExporter
 
-// ShutdownAll stops all exporters.
+// ShutdownAll stops all exporters. The wrapper exporters are stopped first so
+// that they can flush their data to the exporters they wrap.
 func (exps Exporters) ShutdownAll() {
 	for _, exp := range exps {
-		exp.Shutdown()
+		if exp.wrapper {
+			exp.Shutdown()
+		}
+	}
+	for _, exp := range exps {
+		if !exp.wrapper {
+			exp.Shutdown()
+		}
 	}
 }
 
@@ -71,6 +82,9 @@ type dataTypeRequirements map[configmodels.DataType]dataTypeRequirement
 // Data type requirements for all exporters.
 type exportersRequiredDataTypes map[configmodels.Exporter]dataTypeRequirements
 
+// Configs of the exporters wrapped by each wrapper exporter.
+type wrappedExporters map[configmodels.Exporter][]configmodels.Exporter
+
 // ExportersBuilder builds exporters from config.
 type ExportersBuilder struct {
 	logger    *zap.Logger
@@ -91,13 +105,29 @@ func NewExportersBuilder(
 func (eb *ExportersBuilder) Build() (Exporters, error) {
 	exporters := make(Exporters)
 
+	wrapped, err := eb.resolveWrappedExporters()
+	if err != nil {
+		return nil, err
+	}
+
 	// We need to calculate required input data types for each exporter so that we know
 	// which data type must be started for each exporter.
-	exporterInputDataTypes := eb.calcExportersRequiredDataTypes()
+	exporterInputDataTypes := eb.calcExportersRequiredDataTypes(wrapped)
 
-	// Build exporters based on configuration and required input data types.
+	// Build exporters based on configuration and required input data types. The
+	// wrapped exporters must be built before the wrappers.
 	for _, cfg := range eb.config.Exporters {
-		exp, err := eb.buildExporter(cfg, exporterInputDataTypes)
+		if _, ok := wrapped[cfg]; ok {
+			continue
+		}
+		exp, err := eb.buildExporter(cfg, exporterInputDataTypes, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		exporters[cfg] = exp
+	}
+	for cfg, wrappedCfgs := range wrapped {
+		exp, err := eb.buildExporter(cfg, exporterInputDataTypes, wrappedCfgs, exporters)
 		if err != nil {
 			return nil, err
 		}
@@ -107,7 +137,32 @@ func (eb *ExportersBuilder) Build() (Exporters, error) {
 	return exporters, nil
 }
 
-func (eb *ExportersBuilder) calcExportersRequiredDataTypes() exportersRequiredDataTypes {
+// resolveWrappedExporters finds the configs of the exporters wrapped by the
+// exporters whose factory is an exporter.WrapperFactory.
+func (eb *ExportersBuilder) resolveWrappedExporters() (wrappedExporters, error) {
+	result := make(wrappedExporters)
+	for _, cfg := range eb.config.Exporters {
+		factory, ok := eb.factories[cfg.Type()].(exporter.WrapperFactory)
+		if !ok {
+			continue
+		}
+
+		result[cfg] = []configmodels.Exporter{}
+		for _, name := range factory.WrappedExporters(cfg) {
+			wrappedCfg := eb.config.Exporters[name]
+			if wrappedCfg == nil {
+				return nil, fmt.Errorf("exporter %q wrapped by %s is not defined", name, cfg.Name())
+			}
+			if _, ok := eb.factories[wrappedCfg.Type()].(exporter.WrapperFactory); ok {
+				return nil, fmt.Errorf("exporter %q wrapped by %s must not wrap other exporters", name, cfg.Name())
+			}
+			result[cfg] = append(result[cfg], wrappedCfg)
+		}
+	}
+	return result, nil
+}
+
+func (eb *ExportersBuilder) calcExportersRequiredDataTypes(wrapped wrappedExporters) exportersRequiredDataTypes {
 
 	// Go over all pipelines. The data type of the pipeline defines what data type
 	// each exporter is expected to receive. Collect all required types for each
@@ -124,6 +179,11 @@ func (eb *ExportersBuilder) calcExportersRequiredDataTypes() exportersRequiredDa
 	for _, pipeline := range eb.config.Pipelines {
 		// Iterate over all exporters for this pipeline.
 		for _, expName := range pipeline.Exporters {
+			// The connectors are built with the pipelines.
+			if eb.config.Connectors[expName] != nil {
+				continue
+			}
+
 			// Find the exporter config by name.
 			exporter := eb.config.Exporters[expName]
 
@@ -137,19 +197,37 @@ func (eb *ExportersBuilder) calcExportersRequiredDataTypes() exportersRequiredDa
 			result[exporter][pipeline.InputType] = dataTypeRequirement{pipeline}
 		}
 	}
+
+	// The wrapped exporters receive the data types of their wrappers.
+	for wrapper, wrappedCfgs := range wrapped {
+		for _, exporter := range wrappedCfgs {
+			for dataType, requirement := range result[wrapper] {
+				if result[exporter] == nil {
+					result[exporter] = make(dataTypeRequirements)
+				}
+				result[exporter][dataType] = requirement
+			}
+		}
+	}
 	return result
 }
 
+// buildExporter builds the exporter of the given config. The wrapped configs
+// are those of the exporters wrapped by a wrapper exporter, they are looked up
+// in the already built exporters.
 func (eb *ExportersBuilder) buildExporter(
 	config configmodels.Exporter,
 	exportersInputDataTypes exportersRequiredDataTypes,
+	wrappedCfgs []configmodels.Exporter,
+	built Exporters,
 ) (*builtExporter, error) {
 	factory := eb.factories[config.Type()]
 	if factory == nil {
 		return nil, fmt.Errorf("exporter factory not found for type: %s", config.Type())
 	}
+	_, isWrapper := factory.(exporter.WrapperFactory)
 
-	exporter := &builtExporter{}
+	exporter := &builtExporter{wrapper: isWrapper}
 
 	inputDataTypes := exportersInputDataTypes[config]
 	if inputDataTypes == nil {
@@ -164,11 +242,14 @@ func (eb *ExportersBuilder) buildExporter(
 
 	if requirement, ok := inputDataTypes[configmodels.TracesDataType]; ok {
 		// Traces data type is required. Create a trace exporter based on config.
-		te, err := factory.CreateTraceExporter(eb.logger, config)
+		te, err := eb.createTraceExporter(factory, config, wrappedCfgs, built)
+		if err == nil && te == nil {
+			err = configerror.ErrDataTypeIsNotSupported
+		}
 		if err != nil {
 			if err == configerror.ErrDataTypeIsNotSupported {
 				// Could not create because this exporter does not support this data type.
-				return nil, typeMismatchErr(config, requirement.requiredBy, configmodels.TracesDataType)
+				return nil, typeMismatchErr("exporter", config, requirement.requiredBy, configmodels.TracesDataType)
 			}
 			return nil, fmt.Errorf("error creating %s exporter: %v", config.Name(), err)
 		}
@@ -178,11 +259,14 @@ func (eb *ExportersBuilder) buildExporter(
 
 	if requirement, ok := inputDataTypes[configmodels.MetricsDataType]; ok {
 		// Metrics data type is required. Create a trace exporter based on config.
-		me, err := factory.CreateMetricsExporter(eb.logger, config)
+		me, err := eb.createMetricsExporter(factory, config, wrappedCfgs, built)
+		if err == nil && me == nil {
+			err = configerror.ErrDataTypeIsNotSupported
+		}
 		if err != nil {
 			if err == configerror.ErrDataTypeIsNotSupported {
 				// Could not create because this exporter does not support this data type.
-				return nil, typeMismatchErr(config, requirement.requiredBy, configmodels.MetricsDataType)
+				return nil, typeMismatchErr("exporter", config, requirement.requiredBy, configmodels.MetricsDataType)
 			}
 			return nil, fmt.Errorf("error creating %s exporter: %v", config.Name(), err)
 		}
@@ -195,13 +279,52 @@ func (eb *ExportersBuilder) buildExporter(
 	return exporter, nil
 }
 
-func typeMismatchErr(
+func (eb *ExportersBuilder) createTraceExporter(
+	factory exporter.Factory,
+	config configmodels.Exporter,
+	wrappedCfgs []configmodels.Exporter,
+	built Exporters,
+) (exporter.TraceExporter, error) {
+	wrapperFactory, ok := factory.(exporter.WrapperFactory)
+	if !ok {
+		return factory.CreateTraceExporter(eb.logger, config)
+	}
+
+	wrapped := make(map[string]exporter.TraceExporter)
+	for _, cfg := range wrappedCfgs {
+		wrapped[cfg.Name()] = built[cfg].te
+	}
+	return wrapperFactory.CreateWrapperTraceExporter(eb.logger, config, wrapped)
+}
+
+func (eb *ExportersBuilder) createMetricsExporter(
+	factory exporter.Factory,
 	config configmodels.Exporter,
+	wrappedCfgs []configmodels.Exporter,
+	built Exporters,
+) (exporter.MetricsExporter, error) {
+	wrapperFactory, ok := factory.(exporter.WrapperFactory)
+	if !ok {
+		return factory.CreateMetricsExporter(eb.logger, config)
+	}
+
+	wrapped := make(map[string]exporter.MetricsExporter)
+	for _, cfg := range wrappedCfgs {
+		wrapped[cfg.Name()] = built[cfg].me
+	}
+	return wrapperFactory.CreateWrapperMetricsExporter(eb.logger, config, wrapped)
+}
+
+// typeMismatchErr returns the error reported when a component of a pipeline,
+// e.g. an "exporter", doesn't support the data type of the pipeline.
+func typeMismatchErr(
+	kind string,
+	config configmodels.NamedEntity,
 	requiredByPipeline *configmodels.Pipeline,
 	dataType configmodels.DataType,
 ) error {
-	return fmt.Errorf("%s is a %s pipeline but has a %s which does not support %s",
+	return fmt.Errorf("%s is a %s pipeline but has the %s %s which does not support %s",
 		requiredByPipeline.Name, dataType.GetString(),
-		config.Name(), dataType.GetString(),
+		kind, config.Name(), dataType.GetString(),
 	)
 }
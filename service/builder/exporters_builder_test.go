@@ -15,6 +15,7 @@
 package builder
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,9 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/teeexporter"
 )
 
 func TestExportersBuilder_Build(t *testing.T) {
@@ -93,6 +96,76 @@ func TestExportersBuilder_Build(t *testing.T) {
 	// TODO: once we have an exporter that supports metrics data type test it too.
 }
 
+func TestExportersBuilder_BuildWrapper(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	teeFactory := &teeexporter.Factory{}
+	factories.Exporters[teeFactory.Type()] = teeFactory
+	teeCfg := teeFactory.CreateDefaultConfig().(*teeexporter.Config)
+	teeCfg.Primary = "exampleexporter"
+	teeCfg.Shadow = "exampleexporter/shadow"
+	cfg := &configmodels.Config{
+		Exporters: map[string]configmodels.Exporter{
+			"exampleexporter": &config.ExampleExporter{
+				ExporterSettings: configmodels.ExporterSettings{
+					NameVal: "exampleexporter",
+					TypeVal: "exampleexporter",
+				},
+			},
+			"exampleexporter/shadow": &config.ExampleExporter{
+				ExporterSettings: configmodels.ExporterSettings{
+					NameVal: "exampleexporter/shadow",
+					TypeVal: "exampleexporter",
+				},
+			},
+			"tee": teeCfg,
+		},
+
+		Pipelines: map[string]*configmodels.Pipeline{
+			"trace": {
+				Name:      "trace",
+				InputType: configmodels.TracesDataType,
+				Exporters: []string{"tee"},
+			},
+		},
+	}
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	require.NoError(t, err)
+
+	// The wrapped exporters are built for the data types of the wrapper.
+	tee := exporters[cfg.Exporters["tee"]]
+	require.NotNil(t, tee)
+	require.NotNil(t, tee.te)
+	assert.Nil(t, tee.me)
+	assert.True(t, tee.wrapper)
+	primary := exporters[cfg.Exporters["exampleexporter"]]
+	require.NotNil(t, primary.te)
+	assert.Nil(t, primary.me)
+	shadow := exporters[cfg.Exporters["exampleexporter/shadow"]]
+	require.NotNil(t, shadow.te)
+	assert.Nil(t, shadow.me)
+
+	require.NoError(t, tee.te.ConsumeTraceData(context.Background(), consumerdata.TraceData{}))
+	exporters.ShutdownAll()
+	assert.Len(t, primary.te.(*config.ExampleExporterConsumer).Traces, 1)
+	assert.Len(t, shadow.te.(*config.ExampleExporterConsumer).Traces, 1)
+
+	// The wrapped exporters must be defined.
+	teeCfg.Shadow = "exampleexporter/undefined"
+	_, err = NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.Error(t, err)
+
+	// Wrappers can't be wrapped.
+	teeCfg.Shadow = "tee/nested"
+	nestedCfg := teeFactory.CreateDefaultConfig().(*teeexporter.Config)
+	nestedCfg.NameVal = "tee/nested"
+	cfg.Exporters["tee/nested"] = nestedCfg
+	_, err = NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.Error(t, err)
+}
+
 func TestExportersBuilder_StopAll(t *testing.T) {
 	exporters := make(Exporters)
 	expCfg := &configmodels.ExporterSettings{}
@@ -0,0 +1,147 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+// builtExtension is an extension that is built based on a config.
+type builtExtension struct {
+	name      string
+	extension extension.ServiceExtension
+}
+
+// Extensions is an ordered list of extensions created from extension configs,
+// in the order they are listed in the service section of the configuration.
+type Extensions []*builtExtension
+
+// StartAll starts all extensions in order.
+func (exts Extensions) StartAll(logger *zap.Logger, host extension.Host) error {
+	for _, ext := range exts {
+		logger.Info("Extension is starting...", zap.String("extension", ext.name))
+
+		if err := ext.extension.Start(host); err != nil {
+			return fmt.Errorf("cannot start extension %q: %v", ext.name, err)
+		}
+
+		logger.Info("Extension started.", zap.String("extension", ext.name))
+	}
+	return nil
+}
+
+// ShutdownAll stops all extensions in reverse order.
+func (exts Extensions) ShutdownAll() error {
+	var errors []error
+	for i := len(exts) - 1; i >= 0; i-- {
+		if err := exts[i].extension.Shutdown(); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return oterr.CombineErrors(errors)
+}
+
+// NotifyConfigLoaded calls ConfigLoaded on the extensions implementing
+// extension.ConfigWatcher.
+func (exts Extensions) NotifyConfigLoaded(cfg *configmodels.Config) error {
+	var errors []error
+	for _, ext := range exts {
+		if cw, ok := ext.extension.(extension.ConfigWatcher); ok {
+			if err := cw.ConfigLoaded(cfg); err != nil {
+				errors = append(errors, err)
+			}
+		}
+	}
+	return oterr.CombineErrors(errors)
+}
+
+// NotifyPipelineReady calls Ready on the extensions implementing
+// extension.PipelineWatcher.
+func (exts Extensions) NotifyPipelineReady() error {
+	var errors []error
+	for _, ext := range exts {
+		if pw, ok := ext.extension.(extension.PipelineWatcher); ok {
+			if err := pw.Ready(); err != nil {
+				errors = append(errors, err)
+			}
+		}
+	}
+	return oterr.CombineErrors(errors)
+}
+
+// NotifyPipelineNotReady calls NotReady on the extensions implementing
+// extension.PipelineWatcher.
+func (exts Extensions) NotifyPipelineNotReady() error {
+	var errors []error
+	for _, ext := range exts {
+		if pw, ok := ext.extension.(extension.PipelineWatcher); ok {
+			if err := pw.NotReady(); err != nil {
+				errors = append(errors, err)
+			}
+		}
+	}
+	return oterr.CombineErrors(errors)
+}
+
+// ExtensionsBuilder builds extensions from config.
+type ExtensionsBuilder struct {
+	logger    *zap.Logger
+	config    *configmodels.Config
+	factories map[string]extension.Factory
+}
+
+// NewExtensionsBuilder creates a new ExtensionsBuilder. Call Build() on the returned value.
+func NewExtensionsBuilder(
+	logger *zap.Logger,
+	config *configmodels.Config,
+	factories map[string]extension.Factory,
+) *ExtensionsBuilder {
+	return &ExtensionsBuilder{logger, config, factories}
+}
+
+// Build extensions listed in the service section of the config.
+func (eb *ExtensionsBuilder) Build() (Extensions, error) {
+	extensions := make(Extensions, 0, len(eb.config.Service.Extensions))
+	for _, name := range eb.config.Service.Extensions {
+		cfg, ok := eb.config.Extensions[name]
+		if !ok {
+			return nil, fmt.Errorf("extension %q is not configured", name)
+		}
+
+		factory := eb.factories[cfg.Type()]
+		if factory == nil {
+			return nil, fmt.Errorf("extension factory not found for type: %s", cfg.Type())
+		}
+
+		ext, err := factory.CreateExtension(eb.logger, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("error creating %s extension: %v", name, err)
+		}
+		if ext == nil {
+			return nil, fmt.Errorf("factory for %s extension returned nil", name)
+		}
+
+		eb.logger.Info("Extension is enabled.", zap.String("extension", name))
+		extensions = append(extensions, &builtExtension{name: name, extension: ext})
+	}
+
+	return extensions, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+type recordingExtension struct {
+	name   string
+	events *[]string
+}
+
+func (e *recordingExtension) Start(host extension.Host) error {
+	*e.events = append(*e.events, "start "+e.name)
+	return nil
+}
+
+func (e *recordingExtension) Shutdown() error {
+	*e.events = append(*e.events, "shutdown "+e.name)
+	return nil
+}
+
+func (e *recordingExtension) ConfigLoaded(cfg *configmodels.Config) error {
+	*e.events = append(*e.events, "config "+e.name)
+	return nil
+}
+
+func (e *recordingExtension) Ready() error {
+	*e.events = append(*e.events, "ready "+e.name)
+	return nil
+}
+
+func (e *recordingExtension) NotReady() error {
+	*e.events = append(*e.events, "notready "+e.name)
+	return nil
+}
+
+type recordingExtensionFactory struct {
+	events []string
+	err    error
+}
+
+func (f *recordingExtensionFactory) Type() string {
+	return "recording"
+}
+
+func (f *recordingExtensionFactory) CreateDefaultConfig() configmodels.Extension {
+	return &configmodels.ExtensionSettings{TypeVal: "recording", NameVal: "recording"}
+}
+
+func (f *recordingExtensionFactory) CreateExtension(
+	logger *zap.Logger,
+	cfg configmodels.Extension,
+) (extension.ServiceExtension, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &recordingExtension{name: cfg.Name(), events: &f.events}, nil
+}
+
+func extensionsTestConfig(names ...string) *configmodels.Config {
+	cfg := &configmodels.Config{
+		Extensions: make(configmodels.Extensions),
+		Service:    configmodels.Service{Extensions: names},
+	}
+	for _, name := range names {
+		cfg.Extensions[name] = &configmodels.ExtensionSettings{TypeVal: "recording", NameVal: name}
+	}
+	return cfg
+}
+
+func TestExtensionsBuilder_Build(t *testing.T) {
+	factory := &recordingExtensionFactory{}
+	factories, err := extension.Build(factory)
+	require.NoError(t, err)
+
+	cfg := extensionsTestConfig("recording/1", "recording/2")
+	// Extensions not listed in the service section are not built.
+	cfg.Extensions["recording/unused"] = &configmodels.ExtensionSettings{TypeVal: "recording", NameVal: "recording/unused"}
+
+	exts, err := NewExtensionsBuilder(zap.NewNop(), cfg, factories).Build()
+	require.NoError(t, err)
+	require.Len(t, exts, 2)
+
+	require.NoError(t, exts.NotifyConfigLoaded(cfg))
+	require.NoError(t, exts.StartAll(zap.NewNop(), receivertest.NewMockHost()))
+	require.NoError(t, exts.NotifyPipelineReady())
+	require.NoError(t, exts.NotifyPipelineNotReady())
+	require.NoError(t, exts.ShutdownAll())
+
+	assert.Equal(t, []string{
+		"config recording/1",
+		"config recording/2",
+		"start recording/1",
+		"start recording/2",
+		"ready recording/1",
+		"ready recording/2",
+		"notready recording/1",
+		"notready recording/2",
+		"shutdown recording/2",
+		"shutdown recording/1",
+	}, factory.events)
+}
+
+func TestExtensionsBuilder_Errors(t *testing.T) {
+	factories, err := extension.Build(&recordingExtensionFactory{err: errors.New("cannot create")})
+	require.NoError(t, err)
+
+	_, err = NewExtensionsBuilder(zap.NewNop(), extensionsTestConfig("recording"), factories).Build()
+	assert.Error(t, err)
+
+	_, err = NewExtensionsBuilder(zap.NewNop(), extensionsTestConfig("recording"), nil).Build()
+	assert.Error(t, err)
+
+	cfg := extensionsTestConfig()
+	cfg.Service.Extensions = []string{"missing"}
+	_, err = NewExtensionsBuilder(zap.NewNop(), cfg, factories).Build()
+	assert.Error(t, err)
+}
@@ -15,13 +15,20 @@
 package builder
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/connector"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/componentusage"
+	"github.com/open-telemetry/opentelemetry-service/internal/panicrecovery"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
 )
 
 // builtProcessor is a processor that is built based on a config.
@@ -29,18 +36,125 @@ import (
 type builtProcessor struct {
 	tc consumer.TraceConsumer
 	mc consumer.MetricsConsumer
+
+	// shutdowners are the processors of the pipeline holding data, in the
+	// order of the pipeline.
+	shutdowners []namedShutdowner
+	// seq is the position of the pipeline in the build order.
+	seq int
+}
+
+// namedShutdowner is a processor holding data and its name.
+type namedShutdowner struct {
+	name string
+	processor.Shutdowner
 }
 
 // PipelineProcessors is a map of entry-point processors created from pipeline configs.
 // Each element of the map points to the first processor of the pipeline.
 type PipelineProcessors map[*configmodels.Pipeline]*builtProcessor
 
+// builtConnector is a connector that is built based on a config, for the data
+// type it consumes and the data type it emits.
+type builtConnector struct {
+	name string
+	tc   connector.TraceConnector
+	mc   connector.MetricsConnector
+	// seq is the position of the connector in the build order.
+	seq int
+}
+
+func (bc *builtConnector) connector() connector.Connector {
+	if bc.tc != nil {
+		return bc.tc
+	}
+	return bc.mc
+}
+
+// Connectors are the connectors created from connector configs, in the order
+// they were built: a connector is built after the connectors of the pipelines
+// it emits into.
+type Connectors []*builtConnector
+
+// StartAll starts all connectors, the ones emitting into the pipelines of the
+// others first.
+func (conns Connectors) StartAll(logger *zap.Logger, host receiver.Host) error {
+	for _, conn := range conns {
+		logger.Info("Connector is starting...", zap.String("connector", conn.name))
+
+		if err := conn.connector().Start(host); err != nil {
+			return err
+		}
+		logger.Info("Connector is started.", zap.String("connector", conn.name))
+	}
+	return nil
+}
+
+// ShutdownAll stops all connectors in the reverse order, so that the data they
+// flush goes through the connectors they emit into.
+func (conns Connectors) ShutdownAll(logger *zap.Logger) {
+	for i := len(conns) - 1; i >= 0; i-- {
+		if err := conns[i].connector().Shutdown(); err != nil {
+			logger.Warn("Connector failed to shut down.", zap.String("connector", conns[i].name), zap.Error(err))
+		}
+	}
+}
+
+// ShutdownPipelines drains the processors of the pipelines holding data and
+// shuts down the connectors linking the pipelines. They are shut down in the
+// reverse order of their build, so that the data flushed by a processor or a
+// connector goes through the processors it is sent to before they are drained
+// in turn. The data still held once ctx is done is dropped.
+func ShutdownPipelines(ctx context.Context, logger *zap.Logger, pipelines PipelineProcessors, conns Connectors) {
+	type step struct {
+		seq      int
+		pipeline *builtProcessor
+		conn     *builtConnector
+	}
+	steps := make([]step, 0, len(pipelines)+len(conns))
+	for _, bp := range pipelines {
+		steps = append(steps, step{seq: bp.seq, pipeline: bp})
+	}
+	for _, conn := range conns {
+		steps = append(steps, step{seq: conn.seq, conn: conn})
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].seq > steps[j].seq })
+
+	for _, s := range steps {
+		if s.conn != nil {
+			if err := s.conn.connector().Shutdown(); err != nil {
+				logger.Warn("Connector failed to shut down.", zap.String("connector", s.conn.name), zap.Error(err))
+			}
+			continue
+		}
+		for _, p := range s.pipeline.shutdowners {
+			if err := p.Shutdown(ctx); err != nil {
+				logger.Warn("Processor failed to shut down.", zap.String("processor", p.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// connectorKey identifies the connectors built for a connector config and the
+// data type they consume.
+type connectorKey struct {
+	name     string
+	dataType configmodels.DataType
+}
+
 // PipelinesBuilder builds pipelines from config.
 type PipelinesBuilder struct {
-	logger    *zap.Logger
-	config    *configmodels.Config
-	exporters Exporters
-	factories map[string]processor.Factory
+	logger             *zap.Logger
+	config             *configmodels.Config
+	exporters          Exporters
+	factories          map[string]processor.Factory
+	connectorFactories map[string]connector.Factory
+
+	pipelineProcessors PipelineProcessors
+	connectors         Connectors
+	connectorConsumers map[connectorKey]*builtProcessor
+	// built is the number of pipelines and connectors built so far.
+	built int
 }
 
 // NewPipelinesBuilder creates a new PipelinesBuilder. Requires exporters to be already
@@ -50,23 +164,49 @@ func NewPipelinesBuilder(
 	config *configmodels.Config,
 	exporters Exporters,
 	factories map[string]processor.Factory,
+	connectorFactories map[string]connector.Factory,
 ) *PipelinesBuilder {
-	return &PipelinesBuilder{logger, config, exporters, factories}
+	return &PipelinesBuilder{
+		logger:             logger,
+		config:             config,
+		exporters:          exporters,
+		factories:          factories,
+		connectorFactories: connectorFactories,
+	}
 }
 
-// Build pipeline processors from config.
-func (pb *PipelinesBuilder) Build() (PipelineProcessors, error) {
-	pipelineProcessors := make(PipelineProcessors)
+// Build pipeline processors and the connectors linking them from config.
+func (pb *PipelinesBuilder) Build() (PipelineProcessors, Connectors, error) {
+	pb.pipelineProcessors = make(PipelineProcessors)
+	pb.connectors = nil
+	pb.built = 0
+	pb.connectorConsumers = make(map[connectorKey]*builtProcessor)
 
 	for _, pipeline := range pb.config.Pipelines {
-		firstProcessor, err := pb.buildPipeline(pipeline)
-		if err != nil {
-			return nil, err
+		if _, err := pb.getOrBuildPipeline(pipeline); err != nil {
+			return nil, nil, err
 		}
-		pipelineProcessors[pipeline] = firstProcessor
 	}
 
-	return pipelineProcessors, nil
+	return pb.pipelineProcessors, pb.connectors, nil
+}
+
+// getOrBuildPipeline returns the first processor of the pipeline, building it
+// if needed. The pipelines emitting data into other pipelines via connectors
+// are built after them, the configuration guarantees there is no cycle.
+func (pb *PipelinesBuilder) getOrBuildPipeline(pipelineCfg *configmodels.Pipeline) (*builtProcessor, error) {
+	if firstProcessor := pb.pipelineProcessors[pipelineCfg]; firstProcessor != nil {
+		return firstProcessor, nil
+	}
+
+	firstProcessor, err := pb.buildPipeline(pipelineCfg)
+	if err != nil {
+		return nil, err
+	}
+	firstProcessor.seq = pb.built
+	pb.built++
+	pb.pipelineProcessors[pipelineCfg] = firstProcessor
+	return firstProcessor, nil
 }
 
 // Builds a pipeline of processors. Returns the first processor in the pipeline.
@@ -81,12 +221,17 @@ func (pb *PipelinesBuilder) buildPipeline(
 	// First create a consumer junction point that fans out the data to all exporters.
 	var tc consumer.TraceConsumer
 	var mc consumer.MetricsConsumer
+	var shutdowners []namedShutdowner
 
+	var err error
 	switch pipelineCfg.InputType {
 	case configmodels.TracesDataType:
-		tc = pb.buildFanoutExportersTraceConsumer(pipelineCfg.Exporters)
+		tc, err = pb.buildFanoutExportersTraceConsumer(pipelineCfg)
 	case configmodels.MetricsDataType:
-		mc = pb.buildFanoutExportersMetricsConsumer(pipelineCfg.Exporters)
+		mc, err = pb.buildFanoutExportersMetricsConsumer(pipelineCfg)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Now build the processors backwards, starting from the last one.
@@ -102,66 +247,196 @@ func (pb *PipelinesBuilder) buildPipeline(
 		// This processor must point to the next consumer and then
 		// it becomes the next for the previous one (previous in the pipeline,
 		// which we will build in the next loop iteration).
-		var err error
+		var proc interface{}
 		switch pipelineCfg.InputType {
 		case configmodels.TracesDataType:
-			tc, err = factory.CreateTraceProcessor(pb.logger, tc, procCfg)
+			var tp processor.TraceProcessor
+			tp, err = factory.CreateTraceProcessor(pb.logger, tc, procCfg)
+			if err == nil && tp == nil {
+				err = configerror.ErrDataTypeIsNotSupported
+			}
+			proc = tp
+			tc = componentusage.WrapTraceConsumer(componentusage.KindProcessor, procName,
+				panicrecovery.WrapTraceConsumer(pb.logger, componentusage.KindProcessor, procName, tp))
 		case configmodels.MetricsDataType:
-			mc, err = factory.CreateMetricsProcessor(pb.logger, mc, procCfg)
+			var mp processor.MetricsProcessor
+			mp, err = factory.CreateMetricsProcessor(pb.logger, mc, procCfg)
+			if err == nil && mp == nil {
+				err = configerror.ErrDataTypeIsNotSupported
+			}
+			proc = mp
+			mc = componentusage.WrapMetricsConsumer(componentusage.KindProcessor, procName,
+				panicrecovery.WrapMetricsConsumer(pb.logger, componentusage.KindProcessor, procName, mp))
 		}
 
+		if err == configerror.ErrDataTypeIsNotSupported {
+			return nil, typeMismatchErr("processor", procCfg, pipelineCfg, pipelineCfg.InputType)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("error creating processor %q in pipeline %q: %v",
 				procName, pipelineCfg.Name, err)
 		}
+		if s, ok := proc.(processor.Shutdowner); ok {
+			shutdowners = append([]namedShutdowner{{procName, s}}, shutdowners...)
+		}
 	}
 
 	pb.logger.Info("Pipeline is enabled.", zap.String("pipelines", pipelineCfg.Name))
 
-	return &builtProcessor{tc, mc}, nil
+	return &builtProcessor{tc: tc, mc: mc, shutdowners: shutdowners}, nil
 }
 
-// Converts the list of exporter names to a list of corresponding builtExporters.
-func (pb *PipelinesBuilder) getBuiltExportersByNames(exporterNames []string) []*builtExporter {
-	var result []*builtExporter
-	for _, name := range exporterNames {
-		exporter := pb.exporters[pb.config.Exporters[name]]
-		result = append(result, exporter)
+func (pb *PipelinesBuilder) buildFanoutExportersTraceConsumer(
+	pipelineCfg *configmodels.Pipeline,
+) (consumer.TraceConsumer, error) {
+	var exporters []consumer.TraceConsumer
+	for _, name := range pipelineCfg.Exporters {
+		// The data exported to a connector goes into other pipelines.
+		if pb.config.Connectors[name] != nil {
+			conn, err := pb.getOrBuildConnector(name, pipelineCfg)
+			if err != nil {
+				return nil, err
+			}
+			exporters = append(exporters, conn.tc)
+			continue
+		}
+
+		builtExp := pb.exporters[pb.config.Exporters[name]]
+		exporters = append(exporters,
+			componentusage.WrapTraceConsumer(componentusage.KindExporter, name,
+				panicrecovery.WrapTraceConsumer(pb.logger, componentusage.KindExporter, name, builtExp.te)))
 	}
 
-	return result
+	// Optimize for the case when there is only one exporter, no need to create junction point.
+	if len(exporters) == 1 {
+		return exporters[0], nil
+	}
+
+	// Create a junction point that fans out to all exporters.
+	return processor.NewTraceFanOutConnector(exporters), nil
 }
 
-func (pb *PipelinesBuilder) buildFanoutExportersTraceConsumer(exporterNames []string) consumer.TraceConsumer {
-	builtExporters := pb.getBuiltExportersByNames(exporterNames)
+func (pb *PipelinesBuilder) buildFanoutExportersMetricsConsumer(
+	pipelineCfg *configmodels.Pipeline,
+) (consumer.MetricsConsumer, error) {
+	var exporters []consumer.MetricsConsumer
+	for _, name := range pipelineCfg.Exporters {
+		// The data exported to a connector goes into other pipelines.
+		if pb.config.Connectors[name] != nil {
+			conn, err := pb.getOrBuildConnector(name, pipelineCfg)
+			if err != nil {
+				return nil, err
+			}
+			exporters = append(exporters, conn.mc)
+			continue
+		}
 
-	// Optimize for the case when there is only one exporter, no need to create junction point.
-	if len(builtExporters) == 1 {
-		return builtExporters[0].te
+		builtExp := pb.exporters[pb.config.Exporters[name]]
+		exporters = append(exporters,
+			componentusage.WrapMetricsConsumer(componentusage.KindExporter, name,
+				panicrecovery.WrapMetricsConsumer(pb.logger, componentusage.KindExporter, name, builtExp.me)))
 	}
 
-	var exporters []consumer.TraceConsumer
-	for _, builtExp := range builtExporters {
-		exporters = append(exporters, builtExp.te)
+	// Optimize for the case when there is only one exporter, no need to create junction point.
+	if len(exporters) == 1 {
+		return exporters[0], nil
 	}
 
 	// Create a junction point that fans out to all exporters.
-	return processor.NewTraceFanOutConnector(exporters)
+	return processor.NewMetricsFanOutConnector(exporters), nil
 }
 
-func (pb *PipelinesBuilder) buildFanoutExportersMetricsConsumer(exporterNames []string) consumer.MetricsConsumer {
-	builtExporters := pb.getBuiltExportersByNames(exporterNames)
+// getOrBuildConnector returns the consumer of the data the pipeline exports to
+// the connector, building it if needed. All the pipelines of the same data type
+// exporting to the connector share the consumer. It emits into the pipelines
+// receiving from the connector, which are built first. When these pipelines
+// are of both data types, a connector is built for each and the consumer fans
+// out to them.
+func (pb *PipelinesBuilder) getOrBuildConnector(
+	name string,
+	pipelineCfg *configmodels.Pipeline,
+) (*builtProcessor, error) {
+	key := connectorKey{name, pipelineCfg.InputType}
+	if consumers := pb.connectorConsumers[key]; consumers != nil {
+		return consumers, nil
+	}
 
-	// Optimize for the case when there is only one exporter, no need to create junction point.
-	if len(builtExporters) == 1 {
-		return builtExporters[0].me
+	cfg := pb.config.Connectors[name]
+	factory := pb.connectorFactories[cfg.Type()]
+	if factory == nil {
+		return nil, fmt.Errorf("connector factory not found for type: %s", cfg.Type())
 	}
 
-	var exporters []consumer.MetricsConsumer
-	for _, builtExp := range builtExporters {
-		exporters = append(exporters, builtExp.me)
+	// Build the pipelines receiving from the connector.
+	nextPipelines := make(map[configmodels.DataType][]*builtProcessor)
+	for _, nextCfg := range pb.config.Pipelines {
+		if !hasReceiver(nextCfg, name) {
+			continue
+		}
+		firstProcessor, err := pb.getOrBuildPipeline(nextCfg)
+		if err != nil {
+			return nil, err
+		}
+		nextPipelines[nextCfg.InputType] = append(nextPipelines[nextCfg.InputType], firstProcessor)
 	}
 
-	// Create a junction point that fans out to all exporters.
-	return processor.NewMetricsFanOutConnector(exporters)
+	var tcs []consumer.TraceConsumer
+	var mcs []consumer.MetricsConsumer
+	for _, outType := range []configmodels.DataType{configmodels.TracesDataType, configmodels.MetricsDataType} {
+		next := nextPipelines[outType]
+		if len(next) == 0 {
+			continue
+		}
+
+		conn := &builtConnector{name: name}
+		var err error
+		switch {
+		case pipelineCfg.InputType == configmodels.TracesDataType && outType == configmodels.TracesDataType:
+			conn.tc, err = factory.CreateTracesToTraces(pb.logger, buildFanoutTraceConsumer(next), cfg)
+		case pipelineCfg.InputType == configmodels.TracesDataType:
+			conn.tc, err = factory.CreateTracesToMetrics(pb.logger, buildFanoutMetricConsumer(next), cfg)
+		case outType == configmodels.TracesDataType:
+			conn.mc, err = factory.CreateMetricsToTraces(pb.logger, buildFanoutTraceConsumer(next), cfg)
+		default:
+			conn.mc, err = factory.CreateMetricsToMetrics(pb.logger, buildFanoutMetricConsumer(next), cfg)
+		}
+		if err == nil && conn.tc == nil && conn.mc == nil {
+			err = configerror.ErrDataTypeIsNotSupported
+		}
+		if err == configerror.ErrDataTypeIsNotSupported {
+			return nil, fmt.Errorf("%s is a %s pipeline but has the connector %s which does not support emitting %s",
+				pipelineCfg.Name, pipelineCfg.InputType.GetString(), name, outType.GetString())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error creating connector %q in pipeline %q: %v", name, pipelineCfg.Name, err)
+		}
+
+		if conn.tc != nil {
+			tcs = append(tcs, componentusage.WrapTraceConsumer(componentusage.KindConnector, name,
+				panicrecovery.WrapTraceConsumer(pb.logger, componentusage.KindConnector, name, conn.tc)))
+		} else {
+			mcs = append(mcs, componentusage.WrapMetricsConsumer(componentusage.KindConnector, name,
+				panicrecovery.WrapMetricsConsumer(pb.logger, componentusage.KindConnector, name, conn.mc)))
+		}
+		conn.seq = pb.built
+		pb.built++
+		pb.connectors = append(pb.connectors, conn)
+
+		pb.logger.Info("Connector is enabled.", zap.String("connector", name),
+			zap.String("from", pipelineCfg.InputType.GetString()), zap.String("to", outType.GetString()))
+	}
+
+	consumers := &builtProcessor{}
+	switch {
+	case len(tcs) == 1:
+		consumers.tc = tcs[0]
+	case len(tcs) > 1:
+		consumers.tc = processor.NewTraceFanOutConnector(tcs)
+	case len(mcs) == 1:
+		consumers.mc = mcs[0]
+	case len(mcs) > 1:
+		consumers.mc = processor.NewMetricsFanOutConnector(mcs)
+	}
+	pb.connectorConsumers[key] = consumers
+	return consumers, nil
 }
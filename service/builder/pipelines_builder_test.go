@@ -16,7 +16,10 @@ package builder
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -27,8 +30,13 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/processor"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
 )
 
 func TestPipelinesBuilder_Build(t *testing.T) {
@@ -68,7 +76,7 @@ func testPipeline(t *testing.T, pipelineName string, exporterNames []string) {
 	// Build the pipeline
 	allExporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
 	assert.NoError(t, err)
-	pipelineProcessors, err := NewPipelinesBuilder(zap.NewNop(), cfg, allExporters, factories.Processors).Build()
+	pipelineProcessors, _, err := NewPipelinesBuilder(zap.NewNop(), cfg, allExporters, factories.Processors, factories.Connectors).Build()
 
 	assert.NoError(t, err)
 	require.NotNil(t, pipelineProcessors)
@@ -141,7 +149,247 @@ func TestPipelinesBuilder_Error(t *testing.T) {
 
 	// This should fail because "attributes" processor defined in the config does
 	// not support metrics data type.
-	_, err = NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors).Build()
+	_, _, err = NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
 
-	assert.NotNil(t, err)
+	require.Error(t, err)
+	assert.Equal(t, "traces is a metrics pipeline but has the processor attributes which does not support metrics", err.Error())
+}
+
+func TestPipelinesBuilder_Connectors(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	attrFactory := &attributesprocessor.Factory{}
+	factories.Processors[attrFactory.Type()] = attrFactory
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_connectors.yaml", factories)
+	require.Nil(t, err)
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.NoError(t, err)
+	pipelineProcessors, connectors, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.NoError(t, err)
+	require.Equal(t, 4, len(pipelineProcessors))
+	require.Equal(t, 2, len(connectors))
+
+	require.NoError(t, connectors.StartAll(zap.NewNop(), nil))
+	for _, conn := range connectors {
+		assert.True(t, conn.connector().(*config.ExampleConnectorConsumer).Started)
+	}
+
+	// The traces go through the processors of both pipelines.
+	name := tracepb.TruncatableString{Value: "testspanname"}
+	traceData := consumerdata.TraceData{Spans: []*tracepb.Span{{Name: &name}}}
+	err = pipelineProcessors[cfg.Pipelines["traces"]].tc.ConsumeTraceData(context.Background(), traceData)
+	require.NoError(t, err)
+
+	exp := exporters[cfg.Exporters["exampleexporter"]].te.(*config.ExampleExporterConsumer)
+	require.Equal(t, 1, len(exp.Traces))
+	assert.Equal(t, int64(12345),
+		exp.Traces[0].Spans[0].Attributes.AttributeMap["attr1"].GetIntValue())
+
+	// The metrics go to the exporter of the pipeline and through the connector.
+	err = pipelineProcessors[cfg.Pipelines["metrics"]].mc.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(exporters[cfg.Exporters["exampleexporter"]].me.(*config.ExampleExporterConsumer).Metrics))
+	assert.Equal(t, 1, len(exporters[cfg.Exporters["exampleexporter/2"]].me.(*config.ExampleExporterConsumer).Metrics))
+
+	connectors.ShutdownAll(zap.NewNop())
+	for _, conn := range connectors {
+		assert.True(t, conn.connector().(*config.ExampleConnectorConsumer).Stopped)
+	}
+}
+
+func TestPipelinesBuilder_ConnectorDataTypeError(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	attrFactory := &attributesprocessor.Factory{}
+	factories.Processors[attrFactory.Type()] = attrFactory
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_connectors.yaml", factories)
+	require.Nil(t, err)
+
+	// The example connector doesn't emit metrics from traces.
+	pipeline := cfg.Pipelines["traces/downstream"]
+	pipeline.InputType = configmodels.MetricsDataType
+	pipeline.Processors = nil
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.NoError(t, err)
+	_, _, err = NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.Error(t, err)
+	assert.Equal(t, "traces is a traces pipeline but has the connector exampleconnector which does not support emitting metrics", err.Error())
+}
+
+// nilProcessorFactory creates no trace processor and no error.
+type nilProcessorFactory struct {
+	attributesprocessor.Factory
+}
+
+func (f *nilProcessorFactory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	return nil, nil
+}
+
+func TestPipelinesBuilder_NilProcessor(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	attrFactory := &attributesprocessor.Factory{}
+	factories.Processors[attrFactory.Type()] = attrFactory
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder.yaml", factories)
+	require.Nil(t, err)
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.NoError(t, err)
+
+	// A processor created as nil is reported as not supporting the data type
+	// instead of making the pipeline panic.
+	factories.Processors[attrFactory.Type()] = &nilProcessorFactory{}
+	_, _, err = NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has the processor attributes which does not support traces")
+}
+
+// panickingProcessorFactory creates trace processors panicking on every batch.
+type panickingProcessorFactory struct {
+	attributesprocessor.Factory
+}
+
+type panickingProcessor struct{}
+
+func (pp *panickingProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	panic("malformed batch")
+}
+
+func (f *panickingProcessorFactory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	return &panickingProcessor{}, nil
+}
+
+func TestPipelinesBuilder_PanickingProcessor(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	attrFactory := &attributesprocessor.Factory{}
+	factories.Processors[attrFactory.Type()] = &panickingProcessorFactory{}
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder.yaml", factories)
+	require.Nil(t, err)
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.NoError(t, err)
+	pipelineProcessors, _, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.NoError(t, err)
+
+	// The panic is recovered and the batch is dropped.
+	err = pipelineProcessors[cfg.Pipelines["traces"]].tc.ConsumeTraceData(context.Background(), consumerdata.TraceData{})
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+// slowExporter takes some time to export a batch and rejects the batches once
+// shut down.
+type slowExporter struct {
+	config.ExampleExporterConsumer
+	mu sync.Mutex
+}
+
+func (exp *slowExporter) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	time.Sleep(20 * time.Millisecond)
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	if exp.ExporterShutdown {
+		return consumererror.Permanent(errors.New("exporter shut down"))
+	}
+	exp.Traces = append(exp.Traces, td)
+	return nil
+}
+
+func (exp *slowExporter) Shutdown() error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	exp.ExporterShutdown = true
+	return nil
+}
+
+type slowExporterFactory struct {
+	config.ExampleExporterFactory
+}
+
+func (f *slowExporterFactory) CreateTraceExporter(logger *zap.Logger, cfg configmodels.Exporter) (exporter.TraceExporter, error) {
+	return &slowExporter{}, nil
+}
+
+func TestShutdownPipelines_DrainsQueues(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	require.NoError(t, err)
+	queuedFactory := &queuedprocessor.Factory{}
+	factories.Processors[queuedFactory.Type()] = queuedFactory
+	factories.Exporters["exampleexporter"] = &slowExporterFactory{}
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_shutdown.yaml", factories)
+	require.NoError(t, err)
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	require.NoError(t, err)
+	pipelineProcessors, connectors, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.NoError(t, err)
+
+	name := tracepb.TruncatableString{Value: "testspanname"}
+	traceData := consumerdata.TraceData{Spans: []*tracepb.Span{{Name: &name}}}
+	for i := 0; i < 5; i++ {
+		require.NoError(t, pipelineProcessors[cfg.Pipelines["traces"]].tc.ConsumeTraceData(context.Background(), traceData))
+	}
+
+	// The queued batches reach the exporter before it is shut down.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ShutdownPipelines(ctx, zap.NewNop(), pipelineProcessors, connectors)
+	exporters.ShutdownAll()
+
+	exp := exporters[cfg.Exporters["exampleexporter"]].te.(*slowExporter)
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	assert.Equal(t, 5, len(exp.Traces))
+}
+
+func hasExporter(pipeline *configmodels.Pipeline, exporterName string) bool {
+	for _, name := range pipeline.Exporters {
+		if name == exporterName {
+			return true
+		}
+	}
+	return false
+}
+
+func TestShutdownPipelines_Order(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	attrFactory := &attributesprocessor.Factory{}
+	factories.Processors[attrFactory.Type()] = attrFactory
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_connectors.yaml", factories)
+	require.Nil(t, err)
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	require.NoError(t, err)
+	pipelineProcessors, connectors, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.NoError(t, err)
+
+	// A connector is built after the pipelines it emits into and before the
+	// pipelines emitting into it, so that it is shut down in between.
+	for _, conn := range connectors {
+		for pipelineCfg, bp := range pipelineProcessors {
+			switch {
+			case hasReceiver(pipelineCfg, conn.name):
+				assert.True(t, bp.seq < conn.seq, "pipeline %q receiving from %q", pipelineCfg.Name, conn.name)
+			case hasExporter(pipelineCfg, conn.name):
+				assert.True(t, bp.seq > conn.seq, "pipeline %q exporting to %q", pipelineCfg.Name, conn.name)
+			}
+		}
+	}
+
+	ShutdownPipelines(context.Background(), zap.NewNop(), pipelineProcessors, connectors)
+	for _, conn := range connectors {
+		assert.True(t, conn.connector().(*config.ExampleConnectorConsumer).Stopped)
+	}
 }
@@ -191,20 +191,21 @@ func (rb *ReceiversBuilder) attachReceiverToPipelines(
 
 		// Now create the receiver and tell it to send to the junction point.
 		rcv.trace, err = factory.CreateTraceReceiver(context.Background(), rb.logger, config, junction)
+		if err == nil && rcv.trace == nil {
+			err = configerror.ErrDataTypeIsNotSupported
+		}
 
 	case configmodels.MetricsDataType:
 		junction := buildFanoutMetricConsumer(pipelineProcessors)
 		rcv.metrics, err = factory.CreateMetricsReceiver(rb.logger, config, junction)
+		if err == nil && rcv.metrics == nil {
+			err = configerror.ErrDataTypeIsNotSupported
+		}
 	}
 
 	if err != nil {
 		if err == configerror.ErrDataTypeIsNotSupported {
-			return fmt.Errorf(
-				"receiver %s does not support %s but it was used in a "+
-					"%s pipeline",
-				config.Name(),
-				dataType.GetString(),
-				dataType.GetString())
+			return typeMismatchErr("receiver", config, rb.firstPipeline(config, dataType), dataType)
 		}
 		return fmt.Errorf("cannot create receiver %s: %s", config.Name(), err.Error())
 	}
@@ -215,6 +216,21 @@ func (rb *ReceiversBuilder) attachReceiverToPipelines(
 	return nil
 }
 
+// firstPipeline returns the first pipeline of the given data type the receiver
+// is attached to.
+func (rb *ReceiversBuilder) firstPipeline(config configmodels.Receiver, dataType configmodels.DataType) *configmodels.Pipeline {
+	var first *configmodels.Pipeline
+	for _, pipelineCfg := range rb.config.Pipelines {
+		if pipelineCfg.InputType != dataType || !hasReceiver(pipelineCfg, config.Name()) {
+			continue
+		}
+		if first == nil || pipelineCfg.Name < first.Name {
+			first = pipelineCfg
+		}
+	}
+	return first
+}
+
 func (rb *ReceiversBuilder) buildReceiver(config configmodels.Receiver) (*builtReceiver, error) {
 
 	// First find pipelines that must be attached to this receiver.
@@ -16,9 +16,15 @@ package builder
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,7 +35,12 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/internal/jaegerrelay"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
 )
 
@@ -102,7 +113,7 @@ func testReceivers(
 	// Build the pipeline
 	allExporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
 	assert.NoError(t, err)
-	pipelineProcessors, err := NewPipelinesBuilder(zap.NewNop(), cfg, allExporters, factories.Processors).Build()
+	pipelineProcessors, _, err := NewPipelinesBuilder(zap.NewNop(), cfg, allExporters, factories.Processors, factories.Connectors).Build()
 	assert.NoError(t, err)
 	receivers, err := NewReceiversBuilder(zap.NewNop(), cfg, pipelineProcessors, factories.Receivers).Build()
 
@@ -219,13 +230,14 @@ func TestReceiversBuilder_DataTypeError(t *testing.T) {
 	// Build the pipeline
 	allExporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
 	assert.NoError(t, err)
-	pipelineProcessors, err := NewPipelinesBuilder(zap.NewNop(), cfg, allExporters, factories.Processors).Build()
+	pipelineProcessors, _, err := NewPipelinesBuilder(zap.NewNop(), cfg, allExporters, factories.Processors, factories.Connectors).Build()
 	assert.NoError(t, err)
 	receivers, err := NewReceiversBuilder(zap.NewNop(), cfg, pipelineProcessors, factories.Receivers).Build()
 
 	// This should fail because "examplereceiver" is attached to "traces" pipeline
 	// which is a configuration error.
-	assert.NotNil(t, err)
+	require.Error(t, err)
+	assert.Equal(t, "traces is a traces pipeline but has the receiver examplereceiver which does not support traces", err.Error())
 	assert.Nil(t, receivers)
 }
 
@@ -270,3 +282,93 @@ func TestReceiversBuilder_StopAll(t *testing.T) {
 	assert.Equal(t, true, receiver.TraceStopped)
 	assert.Equal(t, true, receiver.MetricsStopped)
 }
+
+// rawExporter records the trace data and the raw trace data exported to it.
+type rawExporter struct {
+	mu     sync.Mutex
+	traces []consumerdata.TraceData
+	raw    []consumerdata.RawTraceData
+}
+
+func (re *rawExporter) pushTraceData(_ context.Context, td consumerdata.TraceData) (int, error) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	re.traces = append(re.traces, td)
+	return 0, nil
+}
+
+func (re *rawExporter) pushRawTraceData(_ context.Context, rtd consumerdata.RawTraceData) error {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	re.raw = append(re.raw, rtd)
+	return nil
+}
+
+type rawExporterFactory struct {
+	config.ExampleExporterFactory
+	exporter *rawExporter
+}
+
+func (f *rawExporterFactory) CreateTraceExporter(logger *zap.Logger, cfg configmodels.Exporter) (exporter.TraceExporter, error) {
+	return exporterhelper.NewTraceExporter(cfg.Name(), f.exporter.pushTraceData,
+		exporterhelper.WithRawTraceData(jaegerrelay.Format, f.exporter.pushRawTraceData))
+}
+
+func TestReceiversBuilder_Relay(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	require.NoError(t, err)
+	jaegerFactory := &jaegerreceiver.Factory{}
+	factories.Receivers[jaegerFactory.Type()] = jaegerFactory
+	attrFactory := &attributesprocessor.Factory{}
+	factories.Processors[attrFactory.Type()] = attrFactory
+	exp := &rawExporter{}
+	factories.Exporters["exampleexporter"] = &rawExporterFactory{exporter: exp}
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_relay.yaml", factories)
+	require.NoError(t, err)
+	endpoints := make(map[string]string)
+	for _, name := range []string{"jaeger", "jaeger/decoded"} {
+		endpoints[name] = testutils.GetAvailableLocalAddress(t)
+		cfg.Receivers[name].(*jaegerreceiver.Config).Protocols["grpc"].Endpoint = endpoints[name]
+	}
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	require.NoError(t, err)
+	pipelineProcessors, _, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors, factories.Connectors).Build()
+	require.NoError(t, err)
+	receivers, err := NewReceiversBuilder(zap.NewNop(), cfg, pipelineProcessors, factories.Receivers).Build()
+	require.NoError(t, err)
+	require.NoError(t, receivers.StartAll(zap.NewNop(), receivertest.NewMockHost()))
+	defer receivers.StopAll()
+
+	req := &api_v2.PostSpansRequest{
+		Batch: model.Batch{
+			Process: &model.Process{ServiceName: "frontend"},
+			Spans: []*model.Span{{
+				TraceID:       model.NewTraceID(1, 2),
+				SpanID:        model.NewSpanID(3),
+				OperationName: "checkout",
+				StartTime:     time.Unix(1542158650, 0).UTC(),
+				Duration:      time.Second,
+			}},
+		},
+	}
+	for _, name := range []string{"jaeger", "jaeger/decoded"} {
+		conn, err := grpc.Dial(endpoints[name], grpc.WithInsecure())
+		require.NoError(t, err)
+		_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(context.Background(), req, grpc.WaitForReady(true))
+		conn.Close()
+		require.NoError(t, err)
+	}
+
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	// The request of the pipeline without processors is relayed as is.
+	require.Len(t, exp.raw, 1)
+	assert.Equal(t, jaegerrelay.Format, exp.raw[0].Format)
+	got := &api_v2.PostSpansRequest{}
+	require.NoError(t, proto.Unmarshal(exp.raw[0].Payload, got))
+	assert.Equal(t, req, got)
+	// The request of the pipeline with a processor is decoded.
+	require.Len(t, exp.traces, 1)
+	assert.Len(t, exp.traces[0].Spans, 1)
+}
@@ -16,10 +16,16 @@ package service
 
 import (
 	"flag"
+	"net"
+	"net/http"
+	"strconv"
 
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/version"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/backendhealth"
 )
 
 const (
@@ -30,8 +36,38 @@ func healthCheckFlags(flags *flag.FlagSet) {
 	flags.Uint(healthCheckHTTPPort, 13133, "Port on which to run the healthcheck http server.")
 }
 
+// newHealthCheck starts the health check HTTP server. Besides the status of
+// the service on "/", it serves the health of the backends probed by the
+// extensions on "/backends".
 func newHealthCheck(v *viper.Viper, logger *zap.Logger) (*healthcheck.HealthCheck, error) {
-	return healthcheck.New(
-		healthcheck.Unavailable, healthcheck.Logger(logger),
-	).Serve(v.GetInt(healthCheckHTTPPort))
+	hc := healthcheck.New(healthcheck.Unavailable, healthcheck.Logger(logger))
+
+	port := v.GetInt(healthCheckHTTPPort)
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		logger.Error("Health Check server failed to listen", zap.Error(err))
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		if hc.Get() == healthcheck.Ready {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("Server not available"))
+	})
+	mux.Handle("/backends", backendhealth.Handler())
+	version.RegisterHandler(mux, logger)
+
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(ln); err != nil {
+			logger.Error("failed to serve", zap.Error(err))
+			hc.Set(healthcheck.Broken)
+		}
+	}()
+	logger.Info("Health Check server started", zap.Int("http-port", port), zap.Stringer("status", hc.Get()))
+	return hc, nil
 }
@@ -0,0 +1,135 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/config/viperutils"
+)
+
+const (
+	modeCfg = "mode"
+)
+
+// Presets are complete configurations for the common deployment topologies.
+// They are loaded before the config file, if any, so that the file only needs
+// to contain the settings that differ from the preset.
+var presets = map[string]string{
+	// The agent runs next to the application, so it keeps little data in
+	// memory and forwards everything to a gateway as soon as possible. Its
+	// memory is bounded by the memory ballast and by throttling the receivers
+	// once too many requests are in flight.
+	"agent": `
+mem-ballast-size-mib: 64
+receivers-max-in-flight: 64
+
+receivers:
+  opencensus:
+    endpoint: "127.0.0.1:55678"
+
+processors:
+  batch:
+    timeout: 1s
+    send-batch-size: 256
+  queued-retry:
+    num-workers: 2
+    queue-size: 500
+    retry-on-failure: true
+    backoff-delay: 5s
+
+exporters:
+  opencensus:
+    endpoint: "otelsvc-gateway:55678"
+    compression: "gzip"
+
+pipelines:
+  traces:
+    receivers: [opencensus]
+    processors: [batch, queued-retry]
+    exporters: [opencensus]
+`,
+
+	// The gateway receives from many agents, so it holds complete traces for
+	// tail sampling and uses large queues to absorb backend slowdowns.
+	"gateway": `
+receivers:
+  opencensus:
+    endpoint: "0.0.0.0:55678"
+
+processors:
+  tail-sampling:
+    decision-wait: 30s
+    num-traces: 100000
+    expected-new-traces-per-sec: 1000
+    policies:
+      [
+        {
+          name: always-sample,
+          type: always-sample
+        }
+      ]
+  batch:
+    timeout: 5s
+    send-batch-size: 8192
+  queued-retry:
+    num-workers: 16
+    queue-size: 50000
+    retry-on-failure: true
+    backoff-delay: 5s
+
+exporters:
+  opencensus:
+    endpoint: "otelsvc-backend:55678"
+    compression: "gzip"
+    num-workers: 8
+
+pipelines:
+  traces:
+    receivers: [opencensus]
+    processors: [tail-sampling, batch, queued-retry]
+    exporters: [opencensus]
+`,
+}
+
+func presetFlags(flags *flag.FlagSet) {
+	flags.String(modeCfg, "",
+		fmt.Sprintf("Preset configuration to start from (%s), settings in the config file override the preset.",
+			strings.ToUpper(strings.Join(presetNames(), ", "))))
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadPreset loads the preset configuration for the given mode into v. The
+// lookup is case-insensitive.
+func loadPreset(v *viper.Viper, mode string) error {
+	preset, ok := presets[strings.ToLower(mode)]
+	if !ok {
+		return fmt.Errorf("unknown mode %q, supported modes are: %s", mode, strings.Join(presetNames(), ", "))
+	}
+	return viperutils.LoadYAMLBytes(v, []byte(preset))
+}
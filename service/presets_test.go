@@ -0,0 +1,91 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver"
+)
+
+func presetFactories(t *testing.T) config.Factories {
+	receivers, err := receiver.Build(&opencensusreceiver.Factory{})
+	require.NoError(t, err)
+	processors, err := processor.Build(
+		&nodebatcherprocessor.Factory{},
+		&queuedprocessor.Factory{},
+		&tailsamplingprocessor.Factory{},
+	)
+	require.NoError(t, err)
+	exporters, err := exporter.Build(&opencensusexporter.Factory{})
+	require.NoError(t, err)
+	return config.Factories{
+		Receivers:  receivers,
+		Processors: processors,
+		Exporters:  exporters,
+	}
+}
+
+func TestPresetsLoad(t *testing.T) {
+	factories := presetFactories(t)
+	for _, mode := range presetNames() {
+		t.Run(mode, func(t *testing.T) {
+			v := viper.New()
+			require.NoError(t, loadPreset(v, mode))
+			cfg, err := config.Load(v, factories, zap.NewNop())
+			require.NoError(t, err)
+			assert.Contains(t, cfg.Pipelines, "traces")
+		})
+	}
+}
+
+func TestPresetOverride(t *testing.T) {
+	v := viper.New()
+	require.NoError(t, loadPreset(v, "AGENT"))
+	v.SetConfigFile("testdata/preset-override.yaml")
+	require.NoError(t, v.MergeInConfig())
+
+	cfg, err := config.Load(v, presetFactories(t), zap.NewNop())
+	require.NoError(t, err)
+
+	oc := cfg.Exporters["opencensus"].(*opencensusexporter.Config)
+	assert.Equal(t, "gateway.example.com:55678", oc.Endpoint)
+	// Settings not present in the file keep the preset value.
+	assert.Equal(t, "gzip", oc.Compression)
+}
+
+func TestPresetAgentMemoryLimits(t *testing.T) {
+	v := viper.New()
+	require.NoError(t, loadPreset(v, "agent"))
+	assert.Equal(t, 64, v.GetInt("mem-ballast-size-mib"))
+	assert.Equal(t, 64, v.GetInt("receivers-max-in-flight"))
+}
+
+func TestPresetUnknownMode(t *testing.T) {
+	assert.Error(t, loadPreset(viper.New(), "sidecar"))
+}
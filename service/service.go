@@ -28,12 +28,17 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/open-telemetry/opentelemetry-service/config"
-	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/config/configsecret"
+	"github.com/open-telemetry/opentelemetry-service/extension"
+	"github.com/open-telemetry/opentelemetry-service/internal/admission"
 	"github.com/open-telemetry/opentelemetry-service/internal/config/viperutils"
+	"github.com/open-telemetry/opentelemetry-service/internal/effectiveconfig"
+	"github.com/open-telemetry/opentelemetry-service/internal/panicrecovery"
 	"github.com/open-telemetry/opentelemetry-service/internal/pprofserver"
-	"github.com/open-telemetry/opentelemetry-service/processor"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 	"github.com/open-telemetry/opentelemetry-service/service/builder"
 	"github.com/open-telemetry/opentelemetry-service/zpages"
@@ -44,7 +49,10 @@ type Application struct {
 	v              *viper.Viper
 	logger         *zap.Logger
 	healthCheck    *healthcheck.HealthCheck
+	extensions     builder.Extensions
 	exporters      builder.Exporters
+	pipelines      builder.PipelineProcessors
+	connectors     builder.Connectors
 	builtReceivers builder.Receivers
 
 	factories config.Factories
@@ -64,6 +72,7 @@ type Application struct {
 }
 
 var _ receiver.Host = (*Application)(nil)
+var _ extension.Host = (*Application)(nil)
 
 // Context returns a context provided by the host to be used on the receiver
 // operations.
@@ -80,32 +89,30 @@ func (app *Application) ReportFatalError(err error) {
 }
 
 // New creates and returns a new instance of Application
-func New(
-	receiverFactories map[string]receiver.Factory,
-	processorFactories map[string]processor.Factory,
-	exporterFactories map[string]exporter.Factory,
-) *Application {
+func New(factories config.Factories) *Application {
 	return &Application{
 		v:         viper.New(),
 		readyChan: make(chan struct{}),
-		factories: config.Factories{
-			Receivers:  receiverFactories,
-			Processors: processorFactories,
-			Exporters:  exporterFactories,
-		},
+		factories: factories,
 	}
 }
 
 func (app *Application) init() {
-	file := builder.GetConfigFile(app.v)
-	if file == "" {
+	mode := app.v.GetString(modeCfg)
+	files := builder.GetConfigFiles(app.v)
+	if len(files) == 0 && mode == "" {
 		log.Fatalf("Config file not specified")
 	}
-	app.v.SetConfigFile(file)
-	err := app.v.ReadInConfig()
-	if err != nil {
-		log.Fatalf("Error loading config file %q: %v", file, err)
+	if mode != "" {
+		if err := loadPreset(app.v, mode); err != nil {
+			log.Fatalf("Error loading preset: %v", err)
+		}
 	}
+	// Settings in the files override the ones from the preset.
+	if err := viperutils.MergeConfigFiles(app.v, files...); err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	var err error
 	app.logger, err = newLogger(app.v)
 	if err != nil {
 		log.Fatalf("Failed to get logger: %v", err)
@@ -188,7 +195,7 @@ func (app *Application) shutdownClosableComponents() {
 	}
 }
 
-func (app *Application) setupPipelines() {
+func (app *Application) setupConfigurationComponents() {
 	app.logger.Info("Loading configuration...")
 
 	// Load configuration.
@@ -197,8 +204,49 @@ func (app *Application) setupPipelines() {
 		log.Fatalf("Cannot load configuration: %v", err)
 	}
 
+	if app.logger.Core().Enabled(zapcore.DebugLevel) {
+		if out, err := effectiveconfig.YAML(cfg); err == nil {
+			app.logger.Debug("Effective configuration", zap.String("config", string(out)))
+		} else {
+			app.logger.Debug("Cannot render the effective configuration", zap.Error(err))
+		}
+	}
+
+	// The secrets are resolved after the configuration is logged, so that
+	// only their references are logged.
+	if err := configsecret.Resolve(cfg); err != nil {
+		log.Fatalf("Cannot load configuration: %v", err)
+	}
+
 	app.logger.Info("Applying configuration...")
 
+	// Extensions are started before the pipelines so they can observe the
+	// whole life-cycle of the pipelines.
+	app.setupExtensions(cfg)
+	app.setupPipelines(cfg)
+}
+
+func (app *Application) setupExtensions(cfg *configmodels.Config) {
+	var err error
+	app.extensions, err = builder.NewExtensionsBuilder(app.logger, cfg, app.factories.Extensions).Build()
+	if err != nil {
+		log.Fatalf("Cannot build extensions: %v", err)
+	}
+
+	if err = app.extensions.NotifyConfigLoaded(cfg); err != nil {
+		log.Fatalf("Cannot notify extensions of the configuration: %v", err)
+	}
+
+	app.logger.Info("Starting extensions...")
+	err = app.extensions.StartAll(app.logger, app)
+	if err != nil {
+		log.Fatalf("Cannot start extensions: %v", err)
+	}
+}
+
+func (app *Application) setupPipelines(cfg *configmodels.Config) {
+	var err error
+
 	// Pipeline is built backwards, starting from exporters, so that we create objects
 	// which are referenced before objects which reference them.
 
@@ -208,9 +256,10 @@ func (app *Application) setupPipelines() {
 		log.Fatalf("Cannot load configuration: %v", err)
 	}
 
-	// Create pipelines and their processors and plug exporters to the
-	// end of the pipelines.
-	pipelines, err := builder.NewPipelinesBuilder(app.logger, cfg, app.exporters, app.factories.Processors).Build()
+	// Create pipelines and their processors and plug exporters and connectors
+	// to the end of the pipelines.
+	pipelines, connectors, err := builder.NewPipelinesBuilder(
+		app.logger, cfg, app.exporters, app.factories.Processors, app.factories.Connectors).Build()
 	if err != nil {
 		log.Fatalf("Cannot load configuration: %v", err)
 	}
@@ -221,22 +270,43 @@ func (app *Application) setupPipelines() {
 		log.Fatalf("Cannot load configuration: %v", err)
 	}
 
+	app.pipelines = pipelines
+	app.connectors = connectors
+
+	app.logger.Info("Starting connectors...")
+	if err = app.connectors.StartAll(app.logger, app); err != nil {
+		log.Fatalf("Cannot start connectors: %v", err)
+	}
+
 	app.logger.Info("Starting receivers...")
 	err = app.builtReceivers.StartAll(app.logger, app)
 	if err != nil {
 		log.Fatalf("Cannot start receivers: %v", err)
 	}
+
+	if err = app.extensions.NotifyPipelineReady(); err != nil {
+		app.logger.Warn("Failed to notify extensions that the pipelines are ready", zap.Error(err))
+	}
 }
 
 func (app *Application) shutdownPipelines() {
 	// Shutdown order is the reverse of building: first receivers, then flushing pipelines
-	// giving senders a chance to send all their data. This may take time, the allowed
-	// time should be part of configuration.
+	// giving senders a chance to send all their data. This may take time, up to the
+	// shutdown-timeout flag.
+
+	if err := app.extensions.NotifyPipelineNotReady(); err != nil {
+		app.logger.Warn("Failed to notify extensions that the pipelines are not ready", zap.Error(err))
+	}
 
 	app.logger.Info("Stopping receivers...")
 	app.builtReceivers.StopAll()
 
-	// TODO: shutdown processors
+	// The processors holding data and the connectors send it to the exporters
+	// before they are shut down.
+	app.logger.Info("Draining pipelines...")
+	ctx, cancel := context.WithTimeout(context.Background(), builder.ShutdownTimeout(app.v))
+	builder.ShutdownPipelines(ctx, app.logger, app.pipelines, app.connectors)
+	cancel()
 
 	app.logger.Info("Shutting down exporters...")
 	app.exporters.ShutdownAll()
@@ -254,8 +324,11 @@ func (app *Application) executeUnified() {
 	app.setupPProf()
 	app.setupHealthCheck()
 	app.setupZPages()
+	// The recovery must be set up before the telemetry registers its views.
+	panicrecovery.SetupFromViper(app.v)
 	app.setupTelemetry(ballastSizeBytes)
-	app.setupPipelines()
+	admission.SetupFromViper(app.v)
+	app.setupConfigurationComponents()
 
 	// Everything is ready, now run until an event requiring shutdown happens.
 	app.runAndWaitForShutdownEvent()
@@ -266,6 +339,12 @@ func (app *Application) executeUnified() {
 	app.logger.Info("Starting shutdown...")
 
 	app.shutdownPipelines()
+
+	app.logger.Info("Shutting down extensions...")
+	if err := app.extensions.ShutdownAll(); err != nil {
+		app.logger.Warn("Failed to shutdown extensions", zap.Error(err))
+	}
+
 	app.shutdownClosableComponents()
 
 	AppTelemetry.shutdown()
@@ -287,11 +366,15 @@ func (app *Application) StartUnified() error {
 	viperutils.AddFlags(app.v, rootCmd,
 		telemetryFlags,
 		builder.Flags,
+		presetFlags,
 		healthCheckFlags,
 		loggerFlags,
 		pprofserver.AddFlags,
 		zpages.AddFlags,
+		admission.AddFlags,
+		panicrecovery.AddFlags,
 	)
+	rootCmd.AddCommand(app.schemaCommand())
 
 	return rootCmd.Execute()
 }
@@ -29,10 +29,10 @@ import (
 )
 
 func TestApplication_StartUnified(t *testing.T) {
-	receiverFactories, processorsFactories, exporterFactories, err := defaults.Components()
+	factories, err := defaults.Components()
 	assert.Nil(t, err)
 
-	app := New(receiverFactories, processorsFactories, exporterFactories)
+	app := New(factories)
 
 	portArg := []string{
 		healthCheckHTTPPort, // Keep it as first since its address is used later.
@@ -25,17 +25,27 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-service/exporter/storeforwardexporter"
+	"github.com/open-telemetry/opentelemetry-service/extension/backendhealthextension"
 	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/internal/componentusage"
+	"github.com/open-telemetry/opentelemetry-service/internal/panicrecovery"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/metricvalidationprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/nodefilterprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/stalenessprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor"
+	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerreceiver"
 )
 
 const (
 	metricsPortCfg  = "metrics-port"
 	metricsLevelCfg = "metrics-level"
+
+	metricsComponentSamplingCfg = "metrics-component-sampling"
 )
 
 var (
@@ -48,9 +58,10 @@ type appTelemetry struct {
 }
 
 func telemetryFlags(flags *flag.FlagSet) {
-	flags.String(metricsLevelCfg, "BASIC", "Output level of telemetry metrics (NONE, BASIC, NORMAL, DETAILED)")
+	flags.String(metricsLevelCfg, "BASIC", "Output level of telemetry metrics (NONE, MINIMAL, BASIC, NORMAL, DETAILED)")
 	// At least until we can use a generic, i.e.: OpenCensus, metrics exporter we default to Prometheus at port 8888, if not otherwise specified.
 	flags.Uint(metricsPortCfg, 8888, "Port exposing collector telemetry.")
+	flags.Uint(metricsComponentSamplingCfg, 0, "Account the CPU time and memory allocations of the processors and exporters on one batch out of every given number of batches, disabled if 0 is specified.")
 }
 
 func (tel *appTelemetry) init(asyncErrorChannel chan<- error, ballastSizeBytes uint64, v *viper.Viper, logger *zap.Logger) error {
@@ -60,16 +71,26 @@ func (tel *appTelemetry) init(asyncErrorChannel chan<- error, ballastSizeBytes u
 	}
 
 	if level == telemetry.None {
+		logger.Info("Internal metrics disabled")
 		return nil
 	}
 
 	port := v.GetInt(metricsPortCfg)
+	componentusage.SetSamplingRate(uint64(v.GetInt(metricsComponentSamplingCfg)))
 
 	views := processor.MetricViews(level)
 	views = append(views, queuedprocessor.MetricViews(level)...)
 	views = append(views, nodebatcherprocessor.MetricViews(level)...)
-	views = append(views, observability.AllViews...)
+	views = append(views, observability.Views(level)...)
 	views = append(views, tailsamplingprocessor.SamplingProcessorMetricViews(level)...)
+	views = append(views, stalenessprocessor.MetricViews(level)...)
+	views = append(views, metricvalidationprocessor.MetricViews(level)...)
+	views = append(views, nodefilterprocessor.MetricViews(level)...)
+	views = append(views, componentusage.MetricViews(level)...)
+	views = append(views, panicrecovery.MetricViews(level)...)
+	views = append(views, storeforwardexporter.MetricViews(level)...)
+	views = append(views, jaegerreceiver.MetricViews(level)...)
+	views = append(views, backendhealthextension.MetricViews(level)...)
 	processMetricsViews := telemetry.NewProcessMetricsViews(ballastSizeBytes)
 	views = append(views, processMetricsViews.Views()...)
 	tel.views = views
@@ -79,6 +100,8 @@ func (tel *appTelemetry) init(asyncErrorChannel chan<- error, ballastSizeBytes u
 
 	processMetricsViews.StartCollection()
 
+	logger.Info("Internal metrics enabled", zap.String("level", level.String()))
+
 	// Until we can use a generic metrics exporter, default to Prometheus.
 	opts := prometheus.Options{
 		Namespace: "oc_collector",
@@ -0,0 +1,118 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testbed
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Container is a backend run in a Docker container for the integration
+// tests. The docker command must be available.
+type Container struct {
+	// Name of the container, it must be unique on the host.
+	Name string
+	// Image of the container, including its tag.
+	Image string
+	// Ports published on the host, as "host:container".
+	Ports []string
+	// Env are the environment variables of the container.
+	Env map[string]string
+	// Volumes mounted in the container, as "host-path:container-path".
+	Volumes []string
+	// HostNetwork runs the container in the network of the host, e.g. for it
+	// to reach the agent. The ports are not published then.
+	HostNetwork bool
+	// Args are passed to the entrypoint of the image.
+	Args []string
+
+	id string
+}
+
+// DockerAvailable returns whether the docker command can run containers.
+func DockerAvailable() bool {
+	return exec.Command("docker", "info").Run() == nil
+}
+
+// Start starts the container, removing any stale container with the same
+// name first. The container is removed once stopped.
+func (c *Container) Start() error {
+	// A previous run may have been interrupted before stopping it.
+	_ = exec.Command("docker", "rm", "-f", c.Name).Run()
+
+	args := []string{"run", "-d", "--rm", "--name", c.Name}
+	if c.HostNetwork {
+		args = append(args, "--network", "host")
+	} else {
+		for _, p := range c.Ports {
+			args = append(args, "-p", p)
+		}
+	}
+	for k, v := range c.Env {
+		args = append(args, "-e", k+"="+v)
+	}
+	for _, v := range c.Volumes {
+		args = append(args, "-v", v)
+	}
+	args = append(args, c.Image)
+	args = append(args, c.Args...)
+
+	log.Printf("Starting container %s from image %s.", c.Name, c.Image)
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("cannot start container %s: %v: %s", c.Name, err, stderr.String())
+	}
+	c.id = strings.TrimSpace(string(out))
+	return nil
+}
+
+// Stop removes the container, if started.
+func (c *Container) Stop() {
+	if c.id == "" {
+		return
+	}
+	if err := exec.Command("docker", "rm", "-f", c.id).Run(); err != nil {
+		log.Printf("Cannot remove container %s: %v", c.Name, err)
+	}
+	c.id = ""
+}
+
+// Logs returns the output of the container, for troubleshooting.
+func (c *Container) Logs() string {
+	if c.id == "" {
+		return ""
+	}
+	out, _ := exec.Command("docker", "logs", c.id).CombinedOutput()
+	return string(out)
+}
+
+// WaitReady calls ready until it returns true or the timeout elapses.
+func (c *Container) WaitReady(ready func() bool, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for !ready() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("container %s is not ready after %v:\n%s", c.Name, timeout, c.Logs())
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testbed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The real backends run in containers for the integration tests, the agent
+// exports to them and the data they store is verified with their query APIs.
+// Their ports on the host don't conflict with the ones of the agent.
+const (
+	// JaegerCollectorGRPCEndpoint is the gRPC collector endpoint of the
+	// Jaeger backend.
+	JaegerCollectorGRPCEndpoint = "127.0.0.1:24250"
+	jaegerQueryURL              = "http://127.0.0.1:26686"
+
+	// ZipkinSpansURL is the URL of the v2 spans API of the Zipkin backend.
+	ZipkinSpansURL = "http://127.0.0.1:19411/api/v2/spans"
+	zipkinURL      = "http://127.0.0.1:19411"
+
+	// KafkaBrokerEndpoint is the endpoint of the broker of the Kafka backend.
+	KafkaBrokerEndpoint = "127.0.0.1:9092"
+	// KafkaCollectorGRPCEndpoint is the gRPC endpoint of the jaeger-collector
+	// of the Kafka backend.
+	KafkaCollectorGRPCEndpoint = "127.0.0.1:24251"
+	kafkaCollectorHealthURL    = "http://127.0.0.1:24269"
+
+	prometheusURL = "http://127.0.0.1:19090"
+
+	backendStartTimeout = 2 * time.Minute
+)
+
+// JaegerBackend is a Jaeger all-in-one backend, with in-memory storage.
+type JaegerBackend struct {
+	container *Container
+}
+
+// NewJaegerBackend creates a JaegerBackend.
+func NewJaegerBackend() *JaegerBackend {
+	return &JaegerBackend{container: &Container{
+		Name:  "testbed-jaeger",
+		Image: "jaegertracing/all-in-one:1.13",
+		Ports: []string{"24250:14250", "26686:16686"},
+	}}
+}
+
+// Start starts the backend and waits for its query API to be ready.
+func (b *JaegerBackend) Start() error {
+	if err := b.container.Start(); err != nil {
+		return err
+	}
+	return b.container.WaitReady(func() bool {
+		return getJSON(jaegerQueryURL+"/api/services", nil) == nil
+	}, backendStartTimeout)
+}
+
+// Stop stops the backend, its data is lost.
+func (b *JaegerBackend) Stop() {
+	b.container.Stop()
+}
+
+// SpanCount returns the number of spans of the service stored by the
+// backend, within the given number of most recent traces.
+func (b *JaegerBackend) SpanCount(service string, limit int) (int, error) {
+	var resp struct {
+		Data []struct {
+			Spans []json.RawMessage `json:"spans"`
+		} `json:"data"`
+	}
+	query := url.Values{"service": {service}, "limit": {fmt.Sprint(limit)}}
+	if err := getJSON(jaegerQueryURL+"/api/traces?"+query.Encode(), &resp); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, trace := range resp.Data {
+		count += len(trace.Spans)
+	}
+	return count, nil
+}
+
+// ZipkinBackend is a Zipkin backend, with in-memory storage.
+type ZipkinBackend struct {
+	container *Container
+}
+
+// NewZipkinBackend creates a ZipkinBackend.
+func NewZipkinBackend() *ZipkinBackend {
+	return &ZipkinBackend{container: &Container{
+		Name:  "testbed-zipkin",
+		Image: "openzipkin/zipkin:2.14",
+		Ports: []string{"19411:9411"},
+	}}
+}
+
+// Start starts the backend and waits for it to be healthy.
+func (b *ZipkinBackend) Start() error {
+	if err := b.container.Start(); err != nil {
+		return err
+	}
+	return b.container.WaitReady(func() bool {
+		return getJSON(zipkinURL+"/health", nil) == nil
+	}, backendStartTimeout)
+}
+
+// Stop stops the backend, its data is lost.
+func (b *ZipkinBackend) Stop() {
+	b.container.Stop()
+}
+
+// SpanCount returns the number of spans of the service stored by the
+// backend, within the given number of most recent traces.
+func (b *ZipkinBackend) SpanCount(service string, limit int) (int, error) {
+	var traces [][]json.RawMessage
+	query := url.Values{"serviceName": {service}, "limit": {fmt.Sprint(limit)}}
+	if err := getJSON(zipkinURL+"/api/v2/traces?"+query.Encode(), &traces); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, trace := range traces {
+		count += len(trace)
+	}
+	return count, nil
+}
+
+// PrometheusBackend is a Prometheus server scraping the agent.
+type PrometheusBackend struct {
+	container  *Container
+	configFile string
+}
+
+// NewPrometheusBackend creates a PrometheusBackend scraping the given
+// endpoint of the host every second.
+func NewPrometheusBackend(scrapeEndpoint string) (*PrometheusBackend, error) {
+	f, err := ioutil.TempFile("", "testbed-prometheus-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, `global:
+  scrape_interval: 1s
+scrape_configs:
+  - job_name: otelsvc
+    static_configs:
+      - targets: [%q]
+`, scrapeEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	configFile, err := filepath.Abs(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(configFile, 0644); err != nil {
+		return nil, err
+	}
+
+	return &PrometheusBackend{
+		configFile: configFile,
+		container: &Container{
+			Name:  "testbed-prometheus",
+			Image: "prom/prometheus:v2.11.1",
+			// The host network lets Prometheus scrape the agent.
+			HostNetwork: true,
+			Volumes:     []string{configFile + ":/etc/prometheus/prometheus.yml"},
+			Args: []string{
+				"--config.file=/etc/prometheus/prometheus.yml",
+				"--web.listen-address=127.0.0.1:19090",
+			},
+		},
+	}, nil
+}
+
+// Start starts the backend and waits for it to be ready.
+func (b *PrometheusBackend) Start() error {
+	if err := b.container.Start(); err != nil {
+		return err
+	}
+	return b.container.WaitReady(func() bool {
+		resp, err := http.Get(prometheusURL + "/-/ready")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, backendStartTimeout)
+}
+
+// Stop stops the backend, its data is lost.
+func (b *PrometheusBackend) Stop() {
+	b.container.Stop()
+	os.Remove(b.configFile)
+}
+
+// SeriesCount returns the number of series of the result of the PromQL
+// instant query.
+func (b *PrometheusBackend) SeriesCount(expr string) (int, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Data   struct {
+			Result []json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := getJSON(prometheusURL+"/api/v1/query?"+url.Values{"query": {expr}}.Encode(), &resp); err != nil {
+		return 0, err
+	}
+	if resp.Status != "success" {
+		return 0, fmt.Errorf("query %q failed with status %q", expr, resp.Status)
+	}
+	return len(resp.Data.Result), nil
+}
+
+// KafkaBackend is a single Kafka broker, with its ZooKeeper, and a
+// jaeger-collector producing the spans it receives to the jaeger-spans topic
+// of the broker, for the tests of the components consuming from Kafka.
+type KafkaBackend struct {
+	broker    *Container
+	collector *Container
+}
+
+// NewKafkaBackend creates a KafkaBackend.
+func NewKafkaBackend() *KafkaBackend {
+	return &KafkaBackend{
+		broker: &Container{
+			Name:  "testbed-kafka",
+			Image: "johnnypark/kafka-zookeeper:2.4.0",
+			Ports: []string{"9092:9092"},
+			Env: map[string]string{
+				"ADVERTISED_HOST": "127.0.0.1",
+				"NUM_PARTITIONS":  "1",
+			},
+		},
+		collector: &Container{
+			Name:  "testbed-jaeger-kafka-collector",
+			Image: "jaegertracing/jaeger-collector:1.14",
+			// The host network lets the collector reach the broker at its
+			// advertised address. The ports don't conflict with the ones of
+			// the agent and of the Jaeger backend.
+			HostNetwork: true,
+			Env:         map[string]string{"SPAN_STORAGE_TYPE": "kafka"},
+			Args: []string{
+				"--kafka.producer.brokers=" + KafkaBrokerEndpoint,
+				"--collector.grpc-port=24251",
+				"--collector.http-port=24268",
+				"--collector.port=24267",
+				"--admin-http-port=24269",
+			},
+		},
+	}
+}
+
+// Start starts the broker and the collector and waits for them to be ready.
+func (b *KafkaBackend) Start() error {
+	if err := b.broker.Start(); err != nil {
+		return err
+	}
+	err := b.broker.WaitReady(func() bool {
+		conn, err := net.DialTimeout("tcp", KafkaBrokerEndpoint, time.Second)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, backendStartTimeout)
+	if err != nil {
+		return err
+	}
+	if err := b.collector.Start(); err != nil {
+		return err
+	}
+	return b.collector.WaitReady(func() bool {
+		return getJSON(kafkaCollectorHealthURL, nil) == nil
+	}, backendStartTimeout)
+}
+
+// Stop stops the collector and the broker, their data is lost.
+func (b *KafkaBackend) Stop() {
+	b.collector.Stop()
+	b.broker.Stop()
+}
+
+// getJSON gets the URL and decodes its JSON response into v, if not nil.
+func getJSON(u string, v interface{}) error {
+	resp, err := http.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,122 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+// The integration tests run the agent against real backends in Docker
+// containers and verify the data they store. To run them go to the tests
+// directory and run:
+// TESTBED_CONFIG=local.yaml go test -v -tags integration -run Integration
+
+package tests
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/testbed/testbed"
+)
+
+// loadGeneratorService is the service of the spans of the load generator.
+const loadGeneratorService = "load-generator"
+
+func skipWithoutDocker(t *testing.T) {
+	if !testbed.DockerAvailable() {
+		t.Skip("Docker is not available, skipping the integration tests.")
+	}
+}
+
+// verifyStoredSpans runs the agent with the given config, sends spans with
+// the load generator once the agent warmed up and waits for the backend to
+// store all of them.
+func verifyStoredSpans(t *testing.T, configFile string, warmUp time.Duration, spanCount func(service string, limit int) (int, error)) {
+	tc := testbed.NewTestCase(t, testbed.WithConfigFile(configFile), testbed.WithSkipResults())
+	defer tc.Stop()
+
+	tc.StartAgent()
+	tc.Sleep(warmUp)
+	tc.StartLoad(testbed.LoadOptions{SpansPerSecond: 100, SpansPerTrace: 10})
+	tc.Sleep(3 * time.Second)
+	tc.StopLoad()
+
+	sent := int(tc.LoadGenerator.SpansSent())
+	var stored int
+	tc.WaitForN(func() bool {
+		n, err := spanCount(loadGeneratorService, sent)
+		if err == nil {
+			stored = n
+		}
+		return stored == sent
+	}, 30*time.Second, "backend must store the sent spans")
+	require.Equal(t, sent, stored, "stored and sent span counters do not match")
+}
+
+func TestIntegrationJaeger(t *testing.T) {
+	skipWithoutDocker(t)
+
+	backend := testbed.NewJaegerBackend()
+	defer backend.Stop()
+	require.NoError(t, backend.Start())
+
+	verifyStoredSpans(t, path.Join("testdata", "integration-jaeger-config.yaml"), 0, backend.SpanCount)
+}
+
+func TestIntegrationZipkin(t *testing.T) {
+	skipWithoutDocker(t)
+
+	backend := testbed.NewZipkinBackend()
+	defer backend.Stop()
+	require.NoError(t, backend.Start())
+
+	verifyStoredSpans(t, path.Join("testdata", "integration-zipkin-config.yaml"), 0, backend.SpanCount)
+}
+
+func TestIntegrationKafka(t *testing.T) {
+	skipWithoutDocker(t)
+
+	kafka := testbed.NewKafkaBackend()
+	defer kafka.Stop()
+	require.NoError(t, kafka.Start())
+	backend := testbed.NewJaegerBackend()
+	defer backend.Stop()
+	require.NoError(t, backend.Start())
+
+	// The receiver consumes from the newest offsets once it joined the
+	// consumer group, the spans produced before are not received.
+	verifyStoredSpans(t, path.Join("testdata", "integration-kafka-config.yaml"), 10*time.Second, backend.SpanCount)
+}
+
+func TestIntegrationPrometheus(t *testing.T) {
+	skipWithoutDocker(t)
+
+	backend, err := testbed.NewPrometheusBackend("127.0.0.1:18889")
+	require.NoError(t, err)
+	defer backend.Stop()
+	require.NoError(t, backend.Start())
+
+	tc := testbed.NewTestCase(t,
+		testbed.WithConfigFile(path.Join("testdata", "integration-prometheus-config.yaml")),
+		testbed.WithSkipResults())
+	defer tc.Stop()
+	tc.StartAgent()
+
+	tc.WaitForN(func() bool {
+		n, err := backend.SeriesCount(`otelsvc_uptime{job="otelsvc"}`)
+		return err == nil && n > 0
+	}, 30*time.Second, "Prometheus must scrape the heartbeat of the agent")
+}
@@ -16,6 +16,8 @@ package jaeger
 
 import (
 	"errors"
+
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 )
 
 const (
@@ -26,8 +28,8 @@ const (
 	ocTimeEventMessageEventID        = "oc.timeevent.messageevent.id"
 	ocTimeEventMessageEventUSize     = "oc.timeevent.messageevent.usize"
 	ocTimeEventMessageEventCSize     = "oc.timeevent.messageevent.csize"
-	ocSameProcessAsParentSpan        = "oc.sameprocessasparentspan"
-	ocSpanChildCount                 = "oc.span.childcount"
+	ocSameProcessAsParentSpan        = tracetranslator.TagSameProcessAsParentSpan
+	ocSpanChildCount                 = tracetranslator.TagSpanChildCount
 	opencensusLanguage               = "opencensus.language"
 	opencensusExporterVersion        = "opencensus.exporterversion"
 	opencensusCoreLibVersion         = "opencensus.corelibversion"
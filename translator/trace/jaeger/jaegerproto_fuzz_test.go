@@ -0,0 +1,63 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package jaeger
+
+import (
+	"fmt"
+	"testing"
+
+	jaeger "github.com/jaegertracing/jaeger/model"
+)
+
+// FuzzProtoBatchRoundTrip decodes the input as a Proto batch, as sent to the
+// collector with gRPC, and converts it to OC Proto and back. The conversions
+// must not panic, and must keep all the spans if they succeed.
+func FuzzProtoBatchRoundTrip(f *testing.F) {
+	for i := 1; i <= 2; i++ {
+		protoFile := fmt.Sprintf("./testdata/jaegerproto_batch_%02d.json", i)
+		jb := &jaeger.Batch{}
+		if err := loadFromJSON(protoFile, jb); err != nil {
+			f.Fatalf("Failed to load Jaeger Proto from %q: %v", protoFile, err)
+		}
+		seed, err := jb.Marshal()
+		if err != nil {
+			f.Fatalf("Failed to serialize Jaeger Proto from %q: %v", protoFile, err)
+		}
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		jb := jaeger.Batch{}
+		if err := jb.Unmarshal(data); err != nil {
+			return
+		}
+
+		td, err := ProtoBatchToOCProto(jb)
+		if err != nil {
+			return
+		}
+		gotJBatch, err := OCProtoToJaegerProto(td)
+		if err != nil {
+			// E.g. the spans with a zero trace ID are rejected.
+			return
+		}
+		if len(gotJBatch.Spans) != len(td.Spans) {
+			t.Fatalf("Different number of spans in the batches (want %d, got %d)", len(td.Spans), len(gotJBatch.Spans))
+		}
+	})
+}
@@ -112,6 +112,7 @@ func jProtoSpansToOCProtoSpans(jspans []*model.Span) []*tracepb.Span {
 			Links:      jProtoReferencesToOCProtoLinks(jspan.References),
 			Status:     sStatus,
 		}
+		tracetranslator.OCSpanFieldsFromAttributes(span)
 
 		spans = append(spans, span)
 	}
@@ -0,0 +1,66 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package jaeger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/thriftutil"
+)
+
+// FuzzThriftBatchRoundTrip decodes the input as a Thrift batch, as sent to the
+// collector, and converts it to OC Proto and back. The conversions must not
+// panic, and must keep all the spans if they succeed.
+func FuzzThriftBatchRoundTrip(f *testing.F) {
+	for i := 1; i <= 2; i++ {
+		thriftFile := fmt.Sprintf("./testdata/thrift_batch_%02d.json", i)
+		jb := &jaeger.Batch{}
+		if err := loadFromJSON(thriftFile, jb); err != nil {
+			f.Fatalf("Failed to load Jaeger Thrift from %q: %v", thriftFile, err)
+		}
+		seed, err := thrift.NewTSerializer().Write(jb)
+		if err != nil {
+			f.Fatalf("Failed to serialize Jaeger Thrift from %q: %v", thriftFile, err)
+		}
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		jb := &jaeger.Batch{}
+		if err := thriftutil.Deserialize(jb, data); err != nil {
+			return
+		}
+
+		td, err := ThriftBatchToOCProto(jb)
+		if err != nil {
+			return
+		}
+		gotJBatch, err := OCProtoToJaegerThrift(td)
+		if err != nil {
+			// E.g. the spans with a zero trace ID are rejected.
+			return
+		}
+		if len(gotJBatch.Spans) != len(td.Spans) {
+			t.Fatalf("Different number of spans in the batches (want %d, got %d)", len(td.Spans), len(gotJBatch.Spans))
+		}
+	})
+}
@@ -120,6 +120,7 @@ func jSpansToOCProtoSpans(jspans []*jaeger.Span) []*tracepb.Span {
 			Links:      jReferencesToOCProtoLinks(jspan.References),
 			Status:     sStatus,
 		}
+		tracetranslator.OCSpanFieldsFromAttributes(span)
 
 		spans = append(spans, span)
 	}
@@ -0,0 +1,128 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"fmt"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// Precedence values of ProcessTagsMapping, selecting the tag kept when a key
+// exists both as a process tag and a span tag.
+const (
+	// PrecedenceSpan keeps the span tag, it is the default.
+	PrecedenceSpan = "span"
+	// PrecedenceProcess keeps the process tag.
+	PrecedenceProcess = "process"
+)
+
+// ProcessTagsMapping controls how the process tags of the Jaeger batches are
+// merged into the span attributes. The zero value keeps the default
+// translation: process tags become node attributes only.
+type ProcessTagsMapping struct {
+	// CopyToSpans adds the node attributes translated from the process tags
+	// to the attributes of every span.
+	CopyToSpans bool `mapstructure:"copy-to-spans"`
+
+	// Prefix is prepended to the keys of the process tags added to the spans.
+	Prefix string `mapstructure:"prefix"`
+
+	// Precedence is either PrecedenceSpan, the default, or PrecedenceProcess.
+	Precedence string `mapstructure:"precedence"`
+
+	// ConflictPrefix, if set, keeps the tag that lost the precedence under
+	// its key with this prefix instead of dropping it.
+	ConflictPrefix string `mapstructure:"conflict-prefix"`
+}
+
+// Validate returns an error if the mapping is invalid.
+func (m *ProcessTagsMapping) Validate() error {
+	switch m.Precedence {
+	case "", PrecedenceSpan, PrecedenceProcess:
+		return nil
+	default:
+		return fmt.Errorf("invalid precedence %q, must be %q or %q", m.Precedence, PrecedenceSpan, PrecedenceProcess)
+	}
+}
+
+// IsDefault returns true if the mapping doesn't change the default translation.
+func (m *ProcessTagsMapping) IsDefault() bool {
+	return !m.CopyToSpans
+}
+
+// Apply returns the trace data translated from a Jaeger batch with the
+// process tags merged into the span attributes according to the mapping.
+// The input is not modified.
+func (m *ProcessTagsMapping) Apply(td consumerdata.TraceData) consumerdata.TraceData {
+	if m.IsDefault() || td.Node == nil || len(td.Node.Attributes) == 0 {
+		return td
+	}
+
+	newSpans := make([]*tracepb.Span, 0, len(td.Spans))
+	for _, span := range td.Spans {
+		if span == nil {
+			newSpans = append(newSpans, span)
+			continue
+		}
+
+		attrMap := make(map[string]*tracepb.AttributeValue, len(td.Node.Attributes))
+		newAttrs := &tracepb.Span_Attributes{AttributeMap: attrMap}
+		if span.Attributes != nil {
+			newAttrs.DroppedAttributesCount = span.Attributes.DroppedAttributesCount
+			for k, v := range span.Attributes.AttributeMap {
+				attrMap[k] = v
+			}
+		}
+		for k, v := range td.Node.Attributes {
+			m.merge(attrMap, m.Prefix+k, &tracepb.AttributeValue{
+				Value: &tracepb.AttributeValue_StringValue{
+					StringValue: &tracepb.TruncatableString{Value: v},
+				},
+			})
+		}
+
+		newSpan := *span
+		newSpan.Attributes = newAttrs
+		newSpans = append(newSpans, &newSpan)
+	}
+	td.Spans = newSpans
+	return td
+}
+
+// merge adds the process tag to the span attributes, resolving a conflict
+// with an existing span tag according to the precedence.
+func (m *ProcessTagsMapping) merge(attrMap map[string]*tracepb.AttributeValue, key string, value *tracepb.AttributeValue) {
+	spanValue, ok := attrMap[key]
+	if !ok {
+		attrMap[key] = value
+		return
+	}
+
+	lost := value
+	if m.Precedence == PrecedenceProcess {
+		attrMap[key] = value
+		lost = spanValue
+	}
+	if m.ConflictPrefix == "" {
+		return
+	}
+	// Never overwrite an existing attribute with the tag that lost the precedence.
+	if _, ok := attrMap[m.ConflictPrefix+key]; !ok {
+		attrMap[m.ConflictPrefix+key] = lost
+	}
+}
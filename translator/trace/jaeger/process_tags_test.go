@@ -0,0 +1,167 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+func processTagsTestData() consumerdata.TraceData {
+	return consumerdata.TraceData{
+		Node: &commonpb.Node{
+			ServiceInfo: &commonpb.ServiceInfo{Name: "api"},
+			Attributes: map[string]string{
+				"region":  "us-west",
+				"version": "1.2",
+			},
+		},
+		Spans: []*tracepb.Span{
+			{
+				Name: &tracepb.TruncatableString{Value: "a"},
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{
+						"version": {Value: &tracepb.AttributeValue_IntValue{IntValue: 2}},
+					},
+					DroppedAttributesCount: 1,
+				},
+			},
+			{
+				Name: &tracepb.TruncatableString{Value: "b"},
+			},
+			nil,
+		},
+	}
+}
+
+func stringAttr(v string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: v},
+		},
+	}
+}
+
+func intAttr(v int64) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: v}}
+}
+
+func TestProcessTagsMapping_Default(t *testing.T) {
+	td := processTagsTestData()
+	m := &ProcessTagsMapping{Prefix: "process."}
+	assert.Equal(t, td, m.Apply(td))
+}
+
+func TestProcessTagsMapping_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping ProcessTagsMapping
+		wantA   map[string]*tracepb.AttributeValue
+		wantB   map[string]*tracepb.AttributeValue
+	}{
+		{
+			name:    "span precedence",
+			mapping: ProcessTagsMapping{CopyToSpans: true},
+			wantA: map[string]*tracepb.AttributeValue{
+				"region":  stringAttr("us-west"),
+				"version": intAttr(2),
+			},
+			wantB: map[string]*tracepb.AttributeValue{
+				"region":  stringAttr("us-west"),
+				"version": stringAttr("1.2"),
+			},
+		},
+		{
+			name:    "process precedence",
+			mapping: ProcessTagsMapping{CopyToSpans: true, Precedence: PrecedenceProcess},
+			wantA: map[string]*tracepb.AttributeValue{
+				"region":  stringAttr("us-west"),
+				"version": stringAttr("1.2"),
+			},
+			wantB: map[string]*tracepb.AttributeValue{
+				"region":  stringAttr("us-west"),
+				"version": stringAttr("1.2"),
+			},
+		},
+		{
+			name:    "span precedence with conflict prefix",
+			mapping: ProcessTagsMapping{CopyToSpans: true, ConflictPrefix: "process."},
+			wantA: map[string]*tracepb.AttributeValue{
+				"region":          stringAttr("us-west"),
+				"version":         intAttr(2),
+				"process.version": stringAttr("1.2"),
+			},
+			wantB: map[string]*tracepb.AttributeValue{
+				"region":  stringAttr("us-west"),
+				"version": stringAttr("1.2"),
+			},
+		},
+		{
+			name:    "process precedence with conflict prefix",
+			mapping: ProcessTagsMapping{CopyToSpans: true, Precedence: PrecedenceProcess, ConflictPrefix: "span."},
+			wantA: map[string]*tracepb.AttributeValue{
+				"region":       stringAttr("us-west"),
+				"version":      stringAttr("1.2"),
+				"span.version": intAttr(2),
+			},
+			wantB: map[string]*tracepb.AttributeValue{
+				"region":  stringAttr("us-west"),
+				"version": stringAttr("1.2"),
+			},
+		},
+		{
+			name:    "prefix",
+			mapping: ProcessTagsMapping{CopyToSpans: true, Prefix: "process."},
+			wantA: map[string]*tracepb.AttributeValue{
+				"process.region":  stringAttr("us-west"),
+				"process.version": stringAttr("1.2"),
+				"version":         intAttr(2),
+			},
+			wantB: map[string]*tracepb.AttributeValue{
+				"process.region":  stringAttr("us-west"),
+				"process.version": stringAttr("1.2"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := processTagsTestData()
+			got := tt.mapping.Apply(td)
+
+			require.Len(t, got.Spans, 3)
+			assert.Equal(t, tt.wantA, got.Spans[0].Attributes.AttributeMap)
+			assert.Equal(t, int32(1), got.Spans[0].Attributes.DroppedAttributesCount)
+			assert.Equal(t, tt.wantB, got.Spans[1].Attributes.AttributeMap)
+			assert.Nil(t, got.Spans[2])
+			assert.Equal(t, td.Node, got.Node)
+
+			// The input is not modified.
+			assert.Equal(t, processTagsTestData(), td)
+		})
+	}
+}
+
+func TestProcessTagsMapping_Validate(t *testing.T) {
+	assert.NoError(t, (&ProcessTagsMapping{}).Validate())
+	assert.NoError(t, (&ProcessTagsMapping{Precedence: PrecedenceSpan}).Validate())
+	assert.NoError(t, (&ProcessTagsMapping{Precedence: PrecedenceProcess}).Validate())
+	assert.Error(t, (&ProcessTagsMapping{Precedence: "node"}).Validate())
+}
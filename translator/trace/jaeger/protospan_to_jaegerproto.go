@@ -503,8 +503,13 @@ func ocSpansToJaegerSpansProto(ocSpans []*tracepb.Span) ([]*jaeger.Span, error)
 			jSpan.Tags = appendJaegerTagFromOCStatusProto(jSpan.Tags, ocSpan.Status)
 		}
 		jSpan.Tags = appendJaegerTagFromOCTracestateProto(jSpan.Tags, ocSpan.Tracestate)
-		jSpan.Tags = appendJaegerTagFromOCSameProcessAsParentSpanProto(jSpan.Tags, ocSpan.SameProcessAsParentSpan)
-		jSpan.Tags = appendJaegerTagFromOCChildSpanCountProto(jSpan.Tags, ocSpan.ChildSpanCount)
+		// Only add the OC span fields as tags if not set in the OC span attributes.
+		if !tracetranslator.OCAttributeKeyExist(ocSpan.Attributes, ocSameProcessAsParentSpan) {
+			jSpan.Tags = appendJaegerTagFromOCSameProcessAsParentSpanProto(jSpan.Tags, ocSpan.SameProcessAsParentSpan)
+		}
+		if !tracetranslator.OCAttributeKeyExist(ocSpan.Attributes, ocSpanChildCount) {
+			jSpan.Tags = appendJaegerTagFromOCChildSpanCountProto(jSpan.Tags, ocSpan.ChildSpanCount)
+		}
 		jSpans = append(jSpans, jSpan)
 	}
 
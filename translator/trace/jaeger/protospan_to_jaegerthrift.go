@@ -21,6 +21,7 @@ import (
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
@@ -203,6 +204,13 @@ func ocSpansToJaegerSpans(ocSpans []*tracepb.Span) ([]*jaeger.Span, error) {
 			!tracetranslator.OCAttributeKeyExist(ocSpan.Attributes, tracetranslator.TagStatusMsg) {
 			jSpan.Tags = appendJaegerThriftTagFromOCStatus(jSpan.Tags, ocSpan.Status)
 		}
+		// Only add the OC span fields as tags if not set in the OC span attributes.
+		if !tracetranslator.OCAttributeKeyExist(ocSpan.Attributes, ocSameProcessAsParentSpan) {
+			jSpan.Tags = appendJaegerThriftTagFromOCSameProcessAsParentSpan(jSpan.Tags, ocSpan.SameProcessAsParentSpan)
+		}
+		if !tracetranslator.OCAttributeKeyExist(ocSpan.Attributes, ocSpanChildCount) {
+			jSpan.Tags = appendJaegerThriftTagFromOCChildSpanCount(jSpan.Tags, ocSpan.ChildSpanCount)
+		}
 		jSpans = append(jSpans, jSpan)
 	}
 
@@ -272,6 +280,37 @@ func appendJaegerThriftTagFromOCStatus(jTags []*jaeger.Tag, ocStatus *tracepb.St
 	return jTags
 }
 
+func appendJaegerThriftTagFromOCSameProcessAsParentSpan(jTags []*jaeger.Tag, ocIsSameProcessAsParentSpan *wrappers.BoolValue) []*jaeger.Tag {
+	if ocIsSameProcessAsParentSpan == nil {
+		return jTags
+	}
+
+	jTag := &jaeger.Tag{
+		Key:   ocSameProcessAsParentSpan,
+		VBool: &ocIsSameProcessAsParentSpan.Value,
+		VType: jaeger.TagType_BOOL,
+	}
+	jTags = append(jTags, jTag)
+
+	return jTags
+}
+
+func appendJaegerThriftTagFromOCChildSpanCount(jTags []*jaeger.Tag, ocChildSpanCount *wrappers.UInt32Value) []*jaeger.Tag {
+	if ocChildSpanCount == nil {
+		return jTags
+	}
+
+	childSpanCount := int64(ocChildSpanCount.Value)
+	jTag := &jaeger.Tag{
+		Key:   ocSpanChildCount,
+		VLong: &childSpanCount,
+		VType: jaeger.TagType_LONG,
+	}
+	jTags = append(jTags, jTag)
+
+	return jTags
+}
+
 func appendJaegerTagFromOCSpanKind(jTags []*jaeger.Tag, ocSpanKind tracepb.Span_SpanKind) []*jaeger.Tag {
 
 	// TODO: (@pjanotti): Replace any OpenTracing literals by importing github.com/opentracing/opentracing-go/ext?
@@ -24,6 +24,7 @@ import (
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/google/go-cmp/cmp"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 
@@ -537,3 +538,48 @@ var tds = []consumerdata.TraceData{
 		},
 	},
 }
+
+func TestOCSpanFieldsRoundtrip(t *testing.T) {
+	ocSpan := &tracepb.Span{
+		TraceId:                 []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanId:                  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Name:                    &tracepb.TruncatableString{Value: "span"},
+		SameProcessAsParentSpan: &wrappers.BoolValue{Value: false},
+		ChildSpanCount:          &wrappers.UInt32Value{Value: 3},
+	}
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{ocSpan}}
+
+	thriftBatch, err := OCProtoToJaegerThrift(td)
+	if err != nil {
+		t.Fatalf("Failed to translate to Jaeger Thrift: %v", err)
+	}
+	thriftTD, err := ThriftBatchToOCProto(thriftBatch)
+	if err != nil {
+		t.Fatalf("Failed to translate from Jaeger Thrift: %v", err)
+	}
+
+	protoBatch, err := OCProtoToJaegerProto(td)
+	if err != nil {
+		t.Fatalf("Failed to translate to Jaeger Proto: %v", err)
+	}
+	protoTD, err := ProtoBatchToOCProto(*protoBatch)
+	if err != nil {
+		t.Fatalf("Failed to translate from Jaeger Proto: %v", err)
+	}
+
+	for format, got := range map[string][]*tracepb.Span{"thrift": thriftTD.Spans, "proto": protoTD.Spans} {
+		if len(got) != 1 {
+			t.Errorf("%s: got %d spans, want 1", format, len(got))
+			continue
+		}
+		if !reflect.DeepEqual(got[0].SameProcessAsParentSpan, ocSpan.SameProcessAsParentSpan) {
+			t.Errorf("%s: SameProcessAsParentSpan = %v, want %v", format, got[0].SameProcessAsParentSpan, ocSpan.SameProcessAsParentSpan)
+		}
+		if !reflect.DeepEqual(got[0].ChildSpanCount, ocSpan.ChildSpanCount) {
+			t.Errorf("%s: ChildSpanCount = %v, want %v", format, got[0].ChildSpanCount, ocSpan.ChildSpanCount)
+		}
+		if got[0].Attributes != nil {
+			t.Errorf("%s: the fields must not remain as attributes, got %v", format, got[0].Attributes)
+		}
+	}
+}
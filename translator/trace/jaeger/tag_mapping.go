@@ -0,0 +1,136 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// TagMapping controls how the node attributes and the resource labels are
+// translated to Jaeger tags. The zero value keeps the default translation:
+// node attributes become process tags and resource labels are dropped.
+type TagMapping struct {
+	// IncludeResourceLabels adds the resource labels to the translated tags.
+	// Node attributes take precedence over resource labels with the same key.
+	IncludeResourceLabels bool `mapstructure:"include-resource-labels"`
+
+	// SpanTags lists the node attributes and resource labels that are added
+	// to the tags of every span instead of the process tags. Span attributes
+	// take precedence over these tags.
+	SpanTags []string `mapstructure:"span-tags"`
+
+	// Rename maps node attribute and resource label keys to the key of the
+	// Jaeger tag.
+	Rename map[string]string `mapstructure:"rename"`
+}
+
+// IsDefault returns true if the mapping doesn't change the default translation.
+func (m *TagMapping) IsDefault() bool {
+	return !m.IncludeResourceLabels && len(m.SpanTags) == 0 && len(m.Rename) == 0
+}
+
+// Apply returns the trace data with the node attributes and resource labels
+// rearranged according to the mapping, ready to be passed to the Jaeger
+// translators. The input is not modified.
+func (m *TagMapping) Apply(td consumerdata.TraceData) consumerdata.TraceData {
+	if m.IsDefault() {
+		return td
+	}
+
+	attrs := make(map[string]string)
+	if m.IncludeResourceLabels && td.Resource != nil {
+		for k, v := range td.Resource.Labels {
+			attrs[k] = v
+		}
+	}
+	if td.Node != nil {
+		for k, v := range td.Node.Attributes {
+			attrs[k] = v
+		}
+	}
+	if len(attrs) == 0 {
+		return td
+	}
+
+	toSpan := make(map[string]bool, len(m.SpanTags))
+	for _, k := range m.SpanTags {
+		toSpan[k] = true
+	}
+
+	processTags := make(map[string]string, len(attrs))
+	spanTags := make(map[string]string)
+	for k, v := range attrs {
+		key := k
+		if newKey, ok := m.Rename[k]; ok && newKey != "" {
+			key = newKey
+		}
+		if toSpan[k] {
+			spanTags[key] = v
+		} else {
+			processTags[key] = v
+		}
+	}
+
+	node := &commonpb.Node{}
+	if td.Node != nil {
+		*node = *td.Node
+	}
+	node.Attributes = processTags
+	td.Node = node
+
+	if len(spanTags) > 0 {
+		td.Spans = addSpanTags(td.Spans, spanTags)
+	}
+	return td
+}
+
+// addSpanTags returns copies of the spans with the tags added to their
+// attributes, existing attributes are kept.
+func addSpanTags(spans []*tracepb.Span, tags map[string]string) []*tracepb.Span {
+	newSpans := make([]*tracepb.Span, 0, len(spans))
+	for _, span := range spans {
+		if span == nil {
+			newSpans = append(newSpans, span)
+			continue
+		}
+
+		attrMap := make(map[string]*tracepb.AttributeValue, len(tags))
+		newAttrs := &tracepb.Span_Attributes{AttributeMap: attrMap}
+		if span.Attributes != nil {
+			newAttrs.DroppedAttributesCount = span.Attributes.DroppedAttributesCount
+			for k, v := range span.Attributes.AttributeMap {
+				attrMap[k] = v
+			}
+		}
+		for k, v := range tags {
+			if _, ok := attrMap[k]; ok {
+				continue
+			}
+			attrMap[k] = &tracepb.AttributeValue{
+				Value: &tracepb.AttributeValue_StringValue{
+					StringValue: &tracepb.TruncatableString{Value: v},
+				},
+			}
+		}
+
+		newSpan := *span
+		newSpan.Attributes = newAttrs
+		newSpans = append(newSpans, &newSpan)
+	}
+	return newSpans
+}
@@ -0,0 +1,110 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+func tagMappingTestData() consumerdata.TraceData {
+	return consumerdata.TraceData{
+		Node: &commonpb.Node{
+			ServiceInfo: &commonpb.ServiceInfo{Name: "api"},
+			Attributes: map[string]string{
+				"region":  "us-west",
+				"version": "1.2",
+			},
+		},
+		Resource: &resourcepb.Resource{
+			Labels: map[string]string{
+				"k8s.pod.name": "api-1234",
+				"region":       "ignored",
+			},
+		},
+		Spans: []*tracepb.Span{
+			{
+				TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				Name:    &tracepb.TruncatableString{Value: "a"},
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{
+						"version": {Value: &tracepb.AttributeValue_IntValue{IntValue: 2}},
+					},
+				},
+			},
+			{
+				TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 9},
+				Name:    &tracepb.TruncatableString{Value: "b"},
+			},
+		},
+	}
+}
+
+func TestTagMapping_Default(t *testing.T) {
+	td := tagMappingTestData()
+	m := &TagMapping{}
+	assert.Equal(t, td, m.Apply(td))
+}
+
+func TestTagMapping_Apply(t *testing.T) {
+	td := tagMappingTestData()
+	m := &TagMapping{
+		IncludeResourceLabels: true,
+		SpanTags:              []string{"version", "k8s.pod.name"},
+		Rename: map[string]string{
+			"k8s.pod.name": "pod",
+			"region":       "cloud.region",
+		},
+	}
+	got := m.Apply(td)
+
+	assert.Equal(t, map[string]string{"cloud.region": "us-west"}, got.Node.Attributes)
+	assert.Equal(t, "api", got.Node.ServiceInfo.Name)
+
+	require.Len(t, got.Spans, 2)
+	// Span attributes take precedence over the moved tags.
+	assert.Equal(t, int64(2), got.Spans[0].Attributes.AttributeMap["version"].GetIntValue())
+	assert.Equal(t, "api-1234", got.Spans[0].Attributes.AttributeMap["pod"].GetStringValue().GetValue())
+	assert.Equal(t, "1.2", got.Spans[1].Attributes.AttributeMap["version"].GetStringValue().GetValue())
+	assert.Equal(t, "api-1234", got.Spans[1].Attributes.AttributeMap["pod"].GetStringValue().GetValue())
+
+	// The input is not modified.
+	assert.Equal(t, tagMappingTestData(), td)
+}
+
+func TestTagMapping_ProcessTags(t *testing.T) {
+	m := &TagMapping{IncludeResourceLabels: true}
+	batch, err := OCProtoToJaegerProto(m.Apply(tagMappingTestData()))
+	require.NoError(t, err)
+
+	tags := make(map[string]string)
+	for _, tag := range batch.Process.Tags {
+		tags[tag.Key] = tag.VStr
+	}
+	assert.Equal(t, map[string]string{
+		"region":       "us-west",
+		"version":      "1.2",
+		"k8s.pod.name": "api-1234",
+	}, tags)
+}
@@ -0,0 +1,329 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lightstep contains the translation of Lightstep ReportRequest
+// messages to OpenCensus proto spans.
+package lightstep
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/gogo/protobuf/types"
+	"github.com/lightstep/lightstep-tracer-common/golang/gogo/collectorpb"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// Reporter tags with special meaning, see the Lightstep tracer implementations.
+const (
+	componentNameKey = "lightstep.component_name"
+	hostnameKey      = "lightstep.hostname"
+	tracerVersionKey = "lightstep.tracer_version"
+	tracerPlatform   = "lightstep.tracer_platform"
+	guidKey          = "lightstep.guid"
+)
+
+// codeUnknown is the OC status code for spans tagged as errors without a more
+// specific status.
+const codeUnknown = 2
+
+// ReportRequestToOCProto converts a Lightstep ReportRequest to a OC proto batch.
+// The timestamps of the spans are corrected by the clock offset reported by
+// the tracer.
+func ReportRequestToOCProto(req *collectorpb.ReportRequest) (consumerdata.TraceData, error) {
+	if req == nil {
+		return consumerdata.TraceData{}, nil
+	}
+
+	offset := time.Duration(req.GetTimestampOffsetMicros()) * time.Microsecond
+	spans := make([]*tracepb.Span, 0, len(req.GetSpans()))
+	for i, lspan := range req.GetSpans() {
+		if lspan == nil {
+			continue
+		}
+		span, err := lSpanToOCProtoSpan(lspan, offset)
+		if err != nil {
+			return consumerdata.TraceData{}, fmt.Errorf("span %d: %v", i, err)
+		}
+		spans = append(spans, span)
+	}
+
+	return consumerdata.TraceData{
+		Node:  lReporterToOCProtoNode(req.GetReporter()),
+		Spans: spans,
+	}, nil
+}
+
+func lReporterToOCProtoNode(r *collectorpb.Reporter) *commonpb.Node {
+	if r == nil {
+		return nil
+	}
+
+	node := &commonpb.Node{
+		Identifier:  &commonpb.ProcessIdentifier{},
+		LibraryInfo: &commonpb.LibraryInfo{},
+		ServiceInfo: &commonpb.ServiceInfo{},
+	}
+
+	attribs := make(map[string]string)
+	for _, tag := range r.GetTags() {
+		switch tag.GetKey() {
+		case componentNameKey:
+			node.ServiceInfo.Name = kvToString(tag)
+			continue
+		case hostnameKey:
+			node.Identifier.HostName = kvToString(tag)
+			continue
+		case tracerVersionKey:
+			node.LibraryInfo.ExporterVersion = "Lightstep-" + kvToString(tag)
+			continue
+		case tracerPlatform:
+			node.LibraryInfo.Language = platformToLanguage(kvToString(tag))
+		}
+		attribs[tag.GetKey()] = kvToString(tag)
+	}
+	if _, ok := attribs[guidKey]; !ok && r.GetReporterId() != 0 {
+		attribs[guidKey] = strconv.FormatUint(r.GetReporterId(), 10)
+	}
+
+	if len(attribs) > 0 {
+		node.Attributes = attribs
+	}
+	return node
+}
+
+func platformToLanguage(platform string) commonpb.LibraryInfo_Language {
+	switch platform {
+	case "go":
+		return commonpb.LibraryInfo_GO_LANG
+	case "jvm", "java":
+		return commonpb.LibraryInfo_JAVA
+	case "node":
+		return commonpb.LibraryInfo_NODE_JS
+	case "python":
+		return commonpb.LibraryInfo_PYTHON
+	case "ruby":
+		return commonpb.LibraryInfo_RUBY
+	case "php":
+		return commonpb.LibraryInfo_PHP
+	case "dotnet", "csharp":
+		return commonpb.LibraryInfo_C_SHARP
+	case "cpp":
+		return commonpb.LibraryInfo_CPP
+	case "browser", "javascript":
+		return commonpb.LibraryInfo_WEB_JS
+	default:
+		return commonpb.LibraryInfo_LANGUAGE_UNSPECIFIED
+	}
+}
+
+func lSpanToOCProtoSpan(lspan *collectorpb.Span, offset time.Duration) (*tracepb.Span, error) {
+	sc := lspan.GetSpanContext()
+	if sc == nil || sc.GetTraceId() == 0 {
+		return nil, fmt.Errorf("missing trace id")
+	}
+	if sc.GetSpanId() == 0 {
+		return nil, fmt.Errorf("missing span id")
+	}
+
+	startTime, err := timestampToTime(lspan.GetStartTimestamp(), offset)
+	if err != nil {
+		return nil, err
+	}
+	endTime := startTime.Add(time.Duration(lspan.GetDurationMicros()) * time.Microsecond)
+
+	parentSpanID, links := lReferencesToOCProto(lspan.GetReferences())
+	kind, status, attributes := lTagsToOCProto(lspan.GetTags())
+	if len(sc.GetBaggage()) > 0 {
+		if attributes == nil {
+			attributes = &tracepb.Span_Attributes{AttributeMap: make(map[string]*tracepb.AttributeValue)}
+		}
+		for k, v := range sc.GetBaggage() {
+			attributes.AttributeMap["baggage."+k] = stringAttribute(v)
+		}
+	}
+
+	timeEvents, err := lLogsToOCProtoTimeEvents(lspan.GetLogs(), offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracepb.Span{
+		TraceId:      tracetranslator.UInt64ToByteTraceID(0, sc.GetTraceId()),
+		SpanId:       tracetranslator.UInt64ToByteSpanID(sc.GetSpanId()),
+		ParentSpanId: parentSpanID,
+		Name:         &tracepb.TruncatableString{Value: lspan.GetOperationName()},
+		Kind:         kind,
+		StartTime:    internal.TimeToTimestamp(startTime),
+		EndTime:      internal.TimeToTimestamp(endTime),
+		Attributes:   attributes,
+		TimeEvents:   timeEvents,
+		Links:        links,
+		Status:       status,
+	}, nil
+}
+
+// lReferencesToOCProto uses the first CHILD_OF reference as the parent of the
+// span, all other references become links.
+func lReferencesToOCProto(refs []*collectorpb.Reference) ([]byte, *tracepb.Span_Links) {
+	var parentSpanID []byte
+	var links []*tracepb.Span_Link
+	for _, ref := range refs {
+		sc := ref.GetSpanContext()
+		if sc == nil {
+			continue
+		}
+		if ref.GetRelationship() == collectorpb.Reference_CHILD_OF && parentSpanID == nil {
+			parentSpanID = tracetranslator.UInt64ToByteSpanID(sc.GetSpanId())
+			continue
+		}
+
+		linkType := tracepb.Span_Link_TYPE_UNSPECIFIED
+		if ref.GetRelationship() == collectorpb.Reference_CHILD_OF {
+			linkType = tracepb.Span_Link_PARENT_LINKED_SPAN
+		}
+		links = append(links, &tracepb.Span_Link{
+			TraceId: tracetranslator.UInt64ToByteTraceID(0, sc.GetTraceId()),
+			SpanId:  tracetranslator.UInt64ToByteSpanID(sc.GetSpanId()),
+			Type:    linkType,
+		})
+	}
+
+	if len(links) == 0 {
+		return parentSpanID, nil
+	}
+	return parentSpanID, &tracepb.Span_Links{Link: links}
+}
+
+func lTagsToOCProto(tags []*collectorpb.KeyValue) (tracepb.Span_SpanKind, *tracepb.Status, *tracepb.Span_Attributes) {
+	if len(tags) == 0 {
+		return tracepb.Span_SPAN_KIND_UNSPECIFIED, nil, nil
+	}
+
+	var kind tracepb.Span_SpanKind
+	var status *tracepb.Status
+	attribs := make(map[string]*tracepb.AttributeValue, len(tags))
+	for _, tag := range tags {
+		switch tag.GetKey() {
+		case tracetranslator.TagSpanKind:
+			switch kvToString(tag) {
+			case "client":
+				kind = tracepb.Span_CLIENT
+			case "server":
+				kind = tracepb.Span_SERVER
+			}
+		case tracetranslator.TagHTTPStatusCode:
+			if code, err := strconv.ParseInt(kvToString(tag), 10, 32); err == nil && status == nil {
+				status = &tracepb.Status{Code: tracetranslator.OCStatusCodeFromHTTP(int32(code))}
+			}
+		}
+		attribs[tag.GetKey()] = kvToAttributeValue(tag)
+	}
+	if status == nil {
+		// OpenTracing marks failed spans with the "error" tag.
+		if v, ok := attribs["error"].GetValue().(*tracepb.AttributeValue_BoolValue); ok && v.BoolValue {
+			status = &tracepb.Status{Code: codeUnknown}
+		}
+	}
+	return kind, status, &tracepb.Span_Attributes{AttributeMap: attribs}
+}
+
+func lLogsToOCProtoTimeEvents(logs []*collectorpb.Log, offset time.Duration) (*tracepb.Span_TimeEvents, error) {
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	timeEvents := make([]*tracepb.Span_TimeEvent, 0, len(logs))
+	for _, log := range logs {
+		ts, err := timestampToTime(log.GetTimestamp(), offset)
+		if err != nil {
+			return nil, err
+		}
+
+		var description string
+		attribs := make(map[string]*tracepb.AttributeValue, len(log.GetFields()))
+		for _, field := range log.GetFields() {
+			// "event" and "message" are the OpenTracing keys for the log message.
+			if (field.GetKey() == "event" || field.GetKey() == "message") && description == "" {
+				description = kvToString(field)
+			}
+			attribs[field.GetKey()] = kvToAttributeValue(field)
+		}
+
+		annotation := &tracepb.Span_TimeEvent_Annotation{
+			Description: &tracepb.TruncatableString{Value: description},
+		}
+		if len(attribs) > 0 {
+			annotation.Attributes = &tracepb.Span_Attributes{AttributeMap: attribs}
+		}
+		timeEvents = append(timeEvents, &tracepb.Span_TimeEvent{
+			Time:  internal.TimeToTimestamp(ts),
+			Value: &tracepb.Span_TimeEvent_Annotation_{Annotation: annotation},
+		})
+	}
+	return &tracepb.Span_TimeEvents{TimeEvent: timeEvents}, nil
+}
+
+func timestampToTime(ts *types.Timestamp, offset time.Duration) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, nil
+	}
+	t, err := types.TimestampFromProto(ts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(offset), nil
+}
+
+func stringAttribute(s string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: s}},
+	}
+}
+
+func kvToAttributeValue(kv *collectorpb.KeyValue) *tracepb.AttributeValue {
+	switch v := kv.GetValue().(type) {
+	case *collectorpb.KeyValue_IntValue:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: v.IntValue}}
+	case *collectorpb.KeyValue_DoubleValue:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: v.DoubleValue}}
+	case *collectorpb.KeyValue_BoolValue:
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: v.BoolValue}}
+	default:
+		return stringAttribute(kvToString(kv))
+	}
+}
+
+func kvToString(kv *collectorpb.KeyValue) string {
+	switch v := kv.GetValue().(type) {
+	case *collectorpb.KeyValue_StringValue:
+		return v.StringValue
+	case *collectorpb.KeyValue_JsonValue:
+		return v.JsonValue
+	case *collectorpb.KeyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *collectorpb.KeyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	case *collectorpb.KeyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	default:
+		return ""
+	}
+}
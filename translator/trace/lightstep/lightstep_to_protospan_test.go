@@ -0,0 +1,197 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightstep
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/gogo/protobuf/types"
+	"github.com/lightstep/lightstep-tracer-common/golang/gogo/collectorpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func strKV(key, value string) *collectorpb.KeyValue {
+	return &collectorpb.KeyValue{Key: key, Value: &collectorpb.KeyValue_StringValue{StringValue: value}}
+}
+
+func TestReportRequestToOCProto(t *testing.T) {
+	start := time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC)
+	startProto, err := types.TimestampProto(start)
+	require.NoError(t, err)
+	logProto, err := types.TimestampProto(start.Add(5 * time.Millisecond))
+	require.NoError(t, err)
+
+	req := &collectorpb.ReportRequest{
+		Reporter: &collectorpb.Reporter{
+			ReporterId: 42,
+			Tags: []*collectorpb.KeyValue{
+				strKV("lightstep.component_name", "frontend"),
+				strKV("lightstep.hostname", "host-1"),
+				strKV("lightstep.tracer_version", "0.15.6"),
+				strKV("lightstep.tracer_platform", "go"),
+				strKV("region", "us-east"),
+			},
+		},
+		TimestampOffsetMicros: 1000,
+		Spans: []*collectorpb.Span{
+			{
+				SpanContext: &collectorpb.SpanContext{
+					TraceId: 0x1122,
+					SpanId:  0x33,
+					Baggage: map[string]string{"user": "alice"},
+				},
+				OperationName:  "GET /",
+				StartTimestamp: startProto,
+				DurationMicros: 20000,
+				References: []*collectorpb.Reference{
+					{
+						Relationship: collectorpb.Reference_CHILD_OF,
+						SpanContext:  &collectorpb.SpanContext{TraceId: 0x1122, SpanId: 0x22},
+					},
+					{
+						Relationship: collectorpb.Reference_FOLLOWS_FROM,
+						SpanContext:  &collectorpb.SpanContext{TraceId: 0x99, SpanId: 0x98},
+					},
+				},
+				Tags: []*collectorpb.KeyValue{
+					strKV("span.kind", "server"),
+					{Key: "http.status_code", Value: &collectorpb.KeyValue_IntValue{IntValue: 404}},
+					{Key: "retry", Value: &collectorpb.KeyValue_BoolValue{BoolValue: true}},
+				},
+				Logs: []*collectorpb.Log{
+					{
+						Timestamp: logProto,
+						Fields: []*collectorpb.KeyValue{
+							strKV("event", "cache miss"),
+							{Key: "size", Value: &collectorpb.KeyValue_DoubleValue{DoubleValue: 1.5}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := ReportRequestToOCProto(req)
+	require.NoError(t, err)
+
+	wantNode := &commonpb.Node{
+		Identifier: &commonpb.ProcessIdentifier{HostName: "host-1"},
+		LibraryInfo: &commonpb.LibraryInfo{
+			Language:        commonpb.LibraryInfo_GO_LANG,
+			ExporterVersion: "Lightstep-0.15.6",
+		},
+		ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+		Attributes: map[string]string{
+			"lightstep.tracer_platform": "go",
+			"lightstep.guid":            "42",
+			"region":                    "us-east",
+		},
+	}
+	assert.Equal(t, wantNode, got.Node)
+
+	offsetStart := start.Add(time.Millisecond)
+	wantSpan := &tracepb.Span{
+		TraceId:      tracetranslator.UInt64ToByteTraceID(0, 0x1122),
+		SpanId:       tracetranslator.UInt64ToByteSpanID(0x33),
+		ParentSpanId: tracetranslator.UInt64ToByteSpanID(0x22),
+		Name:         &tracepb.TruncatableString{Value: "GET /"},
+		Kind:         tracepb.Span_SERVER,
+		StartTime:    internal.TimeToTimestamp(offsetStart),
+		EndTime:      internal.TimeToTimestamp(offsetStart.Add(20 * time.Millisecond)),
+		Status:       &tracepb.Status{Code: 5},
+		Attributes: &tracepb.Span_Attributes{
+			AttributeMap: map[string]*tracepb.AttributeValue{
+				"span.kind":        stringAttribute("server"),
+				"http.status_code": {Value: &tracepb.AttributeValue_IntValue{IntValue: 404}},
+				"retry":            {Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}},
+				"baggage.user":     stringAttribute("alice"),
+			},
+		},
+		TimeEvents: &tracepb.Span_TimeEvents{
+			TimeEvent: []*tracepb.Span_TimeEvent{
+				{
+					Time: internal.TimeToTimestamp(offsetStart.Add(5 * time.Millisecond)),
+					Value: &tracepb.Span_TimeEvent_Annotation_{
+						Annotation: &tracepb.Span_TimeEvent_Annotation{
+							Description: &tracepb.TruncatableString{Value: "cache miss"},
+							Attributes: &tracepb.Span_Attributes{
+								AttributeMap: map[string]*tracepb.AttributeValue{
+									"event": stringAttribute("cache miss"),
+									"size":  {Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 1.5}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Links: &tracepb.Span_Links{
+			Link: []*tracepb.Span_Link{
+				{
+					TraceId: tracetranslator.UInt64ToByteTraceID(0, 0x99),
+					SpanId:  tracetranslator.UInt64ToByteSpanID(0x98),
+					Type:    tracepb.Span_Link_TYPE_UNSPECIFIED,
+				},
+			},
+		},
+	}
+	require.Len(t, got.Spans, 1)
+	assert.Equal(t, wantSpan, got.Spans[0])
+}
+
+func TestReportRequestToOCProto_ErrorTag(t *testing.T) {
+	req := &collectorpb.ReportRequest{
+		Spans: []*collectorpb.Span{
+			{
+				SpanContext: &collectorpb.SpanContext{TraceId: 1, SpanId: 2},
+				Tags: []*collectorpb.KeyValue{
+					{Key: "error", Value: &collectorpb.KeyValue_BoolValue{BoolValue: true}},
+				},
+			},
+		},
+	}
+	got, err := ReportRequestToOCProto(req)
+	require.NoError(t, err)
+	require.Len(t, got.Spans, 1)
+	assert.Equal(t, &tracepb.Status{Code: codeUnknown}, got.Spans[0].Status)
+	assert.Nil(t, got.Node)
+}
+
+func TestReportRequestToOCProto_InvalidIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		sc   *collectorpb.SpanContext
+	}{
+		{name: "no_span_context"},
+		{name: "zero_trace_id", sc: &collectorpb.SpanContext{SpanId: 1}},
+		{name: "zero_span_id", sc: &collectorpb.SpanContext{TraceId: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &collectorpb.ReportRequest{
+				Spans: []*collectorpb.Span{{SpanContext: tt.sc}},
+			}
+			_, err := ReportRequestToOCProto(req)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -15,7 +15,11 @@
 package tracetranslator
 
 import (
+	"math"
+	"strconv"
+
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
 )
 
 // OCAttributeKeyExist returns true if a key in attribute of an OC Span exists.
@@ -27,3 +31,56 @@ func OCAttributeKeyExist(ocAttributes *tracepb.Span_Attributes, key string) bool
 	_, foundKey := ocAttributes.AttributeMap[key]
 	return foundKey
 }
+
+// OCSpanFieldsFromAttributes moves the TagSameProcessAsParentSpan and
+// TagSpanChildCount attributes of an OC Span back to the SameProcessAsParentSpan
+// and ChildSpanCount fields, for the formats without these fields that carry
+// them as tags. The attributes with an unexpected value are left unchanged.
+func OCSpanFieldsFromAttributes(span *tracepb.Span) {
+	if span == nil || span.Attributes == nil || len(span.Attributes.AttributeMap) == 0 {
+		return
+	}
+	attribs := span.Attributes.AttributeMap
+
+	if attrib, ok := attribs[TagSameProcessAsParentSpan]; ok {
+		if v, ok := attributeBoolValue(attrib); ok {
+			span.SameProcessAsParentSpan = &wrappers.BoolValue{Value: v}
+			delete(attribs, TagSameProcessAsParentSpan)
+		}
+	}
+	if attrib, ok := attribs[TagSpanChildCount]; ok {
+		if v, ok := attributeUInt32Value(attrib); ok {
+			span.ChildSpanCount = &wrappers.UInt32Value{Value: v}
+			delete(attribs, TagSpanChildCount)
+		}
+	}
+
+	if len(attribs) == 0 && span.Attributes.DroppedAttributesCount == 0 {
+		span.Attributes = nil
+	}
+}
+
+func attributeBoolValue(attrib *tracepb.AttributeValue) (bool, bool) {
+	switch v := attrib.GetValue().(type) {
+	case *tracepb.AttributeValue_BoolValue:
+		return v.BoolValue, true
+	case *tracepb.AttributeValue_StringValue:
+		b, err := strconv.ParseBool(v.StringValue.GetValue())
+		return b, err == nil
+	}
+	return false, false
+}
+
+func attributeUInt32Value(attrib *tracepb.AttributeValue) (uint32, bool) {
+	switch v := attrib.GetValue().(type) {
+	case *tracepb.AttributeValue_IntValue:
+		if v.IntValue < 0 || v.IntValue > math.MaxUint32 {
+			return 0, false
+		}
+		return uint32(v.IntValue), true
+	case *tracepb.AttributeValue_StringValue:
+		n, err := strconv.ParseUint(v.StringValue.GetValue(), 10, 32)
+		return uint32(n), err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracetranslator
+
+import (
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func stringAttribute(s string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: s}},
+	}
+}
+
+func TestOCSpanFieldsFromAttributes(t *testing.T) {
+	tests := []struct {
+		name    string
+		attribs map[string]*tracepb.AttributeValue
+		want    *tracepb.Span
+	}{
+		{
+			name: "typed",
+			attribs: map[string]*tracepb.AttributeValue{
+				TagSameProcessAsParentSpan: {Value: &tracepb.AttributeValue_BoolValue{BoolValue: false}},
+				TagSpanChildCount:          {Value: &tracepb.AttributeValue_IntValue{IntValue: 3}},
+			},
+			want: &tracepb.Span{
+				SameProcessAsParentSpan: &wrappers.BoolValue{Value: false},
+				ChildSpanCount:          &wrappers.UInt32Value{Value: 3},
+			},
+		},
+		{
+			name: "strings",
+			attribs: map[string]*tracepb.AttributeValue{
+				TagSameProcessAsParentSpan: stringAttribute("true"),
+				TagSpanChildCount:          stringAttribute("7"),
+				"other":                    stringAttribute("value"),
+			},
+			want: &tracepb.Span{
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{"other": stringAttribute("value")},
+				},
+				SameProcessAsParentSpan: &wrappers.BoolValue{Value: true},
+				ChildSpanCount:          &wrappers.UInt32Value{Value: 7},
+			},
+		},
+		{
+			name: "unexpected values",
+			attribs: map[string]*tracepb.AttributeValue{
+				TagSameProcessAsParentSpan: stringAttribute("maybe"),
+				TagSpanChildCount:          {Value: &tracepb.AttributeValue_IntValue{IntValue: -1}},
+			},
+			want: &tracepb.Span{
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{
+						TagSameProcessAsParentSpan: stringAttribute("maybe"),
+						TagSpanChildCount:          {Value: &tracepb.AttributeValue_IntValue{IntValue: -1}},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := &tracepb.Span{Attributes: &tracepb.Span_Attributes{AttributeMap: tt.attribs}}
+			OCSpanFieldsFromAttributes(span)
+			assert.Equal(t, tt.want, span)
+		})
+	}
+
+	// Spans without attributes are left unchanged.
+	OCSpanFieldsFromAttributes(nil)
+	span := &tracepb.Span{}
+	OCSpanFieldsFromAttributes(span)
+	assert.Equal(t, &tracepb.Span{}, span)
+}
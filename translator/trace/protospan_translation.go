@@ -31,4 +31,11 @@ const (
 	TagHTTPStatusMsg    = "http.status_message"
 	TagZipkinCensusCode = "census.status_code"
 	TagZipkinCensusMsg  = "census.status_description"
+
+	TagSameProcessAsParentSpan = "oc.sameprocessasparentspan"
+	TagSpanChildCount          = "oc.span.childcount"
+
+	// TagSamplingProbability is the probability with which a span was
+	// sampled, each sampled span stands for 1/probability spans.
+	TagSamplingProbability = "sampling.probability"
 )
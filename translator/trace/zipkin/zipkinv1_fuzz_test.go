@@ -0,0 +1,51 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package zipkin
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// FuzzV1JSONBatchToOCProto translates the input as a Zipkin V1 JSON batch, the
+// translation must not panic.
+func FuzzV1JSONBatchToOCProto(f *testing.F) {
+	for _, file := range []string{
+		"./testdata/zipkin_v1_single_batch.json",
+		"./testdata/zipkin_v1_multiple_batches.json",
+		"./testdata/zipkin_v1_local_component.json",
+	} {
+		blob, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatalf("failed to load test data: %v", err)
+		}
+		f.Add(blob)
+	}
+
+	f.Fuzz(func(t *testing.T, blob []byte) {
+		tds, err := V1JSONBatchToOCProto(blob)
+		if err != nil {
+			return
+		}
+		for _, td := range tds {
+			if len(td.Spans) == 0 {
+				t.Fatalf("translated a batch without spans")
+			}
+		}
+	})
+}
@@ -68,9 +68,13 @@ func zipkinV1ThriftToOCSpan(zSpan *zipkincore.Span) (*tracepb.Span, *annotationP
 	}
 
 	parsedAnnotations := parseZipkinV1ThriftAnnotations(zSpan.Annotations)
-	attributes, ocStatus, localComponent := zipkinV1ThriftBinAnnotationsToOCAttributes(zSpan.BinaryAnnotations)
-	if parsedAnnotations.Endpoint.ServiceName == unknownServiceName && localComponent != "" {
-		parsedAnnotations.Endpoint.ServiceName = localComponent
+	parsedBinAnnotations := parseZipkinV1ThriftBinAnnotations(zSpan.BinaryAnnotations)
+	if parsedAnnotations.Endpoint.ServiceName == unknownServiceName && parsedBinAnnotations.Endpoint != nil {
+		parsedAnnotations.Endpoint = parsedBinAnnotations.Endpoint
+	}
+	parsedAnnotations.RemoteEndpoint = parsedBinAnnotations.RemoteEndpoint
+	if parsedAnnotations.Kind == tracepb.Span_SPAN_KIND_UNSPECIFIED {
+		parsedAnnotations.Kind = parsedBinAnnotations.Kind
 	}
 
 	var startTime, endTime *timestamp.Timestamp
@@ -90,13 +94,14 @@ func zipkinV1ThriftToOCSpan(zSpan *zipkincore.Span) (*tracepb.Span, *annotationP
 		TraceId:      traceID,
 		SpanId:       spanID,
 		ParentSpanId: parentID,
-		Status:       ocStatus,
+		Status:       parsedBinAnnotations.Status,
 		Kind:         parsedAnnotations.Kind,
 		TimeEvents:   parsedAnnotations.TimeEvents,
 		StartTime:    startTime,
 		EndTime:      endTime,
-		Attributes:   attributes,
+		Attributes:   parsedBinAnnotations.Attributes,
 	}
+	tracetranslator.OCSpanFieldsFromAttributes(ocSpan)
 
 	if zSpan.Name != "" {
 		ocSpan.Name = &tracepb.TruncatableString{Value: zSpan.Name}
@@ -140,19 +145,40 @@ func toTranslatorEndpoint(e *zipkincore.Endpoint) *endpoint {
 
 var trueByteSlice = []byte{1}
 
-func zipkinV1ThriftBinAnnotationsToOCAttributes(ztBinAnnotations []*zipkincore.BinaryAnnotation) (attributes *tracepb.Span_Attributes, status *tracepb.Status, fallbackServiceName string) {
+func parseZipkinV1ThriftBinAnnotations(ztBinAnnotations []*zipkincore.BinaryAnnotation) *binAnnotationParseResult {
+	res := &binAnnotationParseResult{}
 	if len(ztBinAnnotations) == 0 {
-		return nil, nil, ""
+		return res
 	}
 
 	sMapper := &statusMapper{}
 	var localComponent string
 	attributeMap := make(map[string]*tracepb.AttributeValue)
 	for _, binaryAnnotation := range ztBinAnnotations {
+		if binaryAnnotation == nil {
+			continue
+		}
+
+		// The address annotations hold the remote endpoint of the span, like
+		// in the JSON spans.
+		switch binaryAnnotation.Key {
+		case zipkincore.CLIENT_ADDR, zipkincore.SERVER_ADDR, zipkincore.MESSAGE_ADDR:
+			if binaryAnnotation.Host != nil {
+				res.RemoteEndpoint = toTranslatorEndpoint(binaryAnnotation.Host)
+				switch binaryAnnotation.Key {
+				case zipkincore.CLIENT_ADDR:
+					res.Kind = tracepb.Span_SERVER
+				case zipkincore.SERVER_ADDR:
+					res.Kind = tracepb.Span_CLIENT
+				}
+			}
+			continue
+		}
+
 		pbAttrib := &tracepb.AttributeValue{}
 		binAnnotationType := binaryAnnotation.AnnotationType
-		if binaryAnnotation.Host != nil {
-			fallbackServiceName = binaryAnnotation.Host.ServiceName
+		if binaryAnnotation.Host != nil && binaryAnnotation.Host.ServiceName != "" {
+			res.Endpoint = toTranslatorEndpoint(binaryAnnotation.Host)
 		}
 		switch binaryAnnotation.AnnotationType {
 		case zipkincore.AnnotationType_BOOL:
@@ -208,20 +234,21 @@ func zipkinV1ThriftBinAnnotationsToOCAttributes(ztBinAnnotations []*zipkincore.B
 		attributeMap[key] = pbAttrib
 	}
 
-	status = sMapper.ocStatus()
+	res.Status = sMapper.ocStatus()
 
 	if len(attributeMap) == 0 {
-		return nil, status, ""
+		res.Endpoint = nil
+		return res
 	}
 
-	if fallbackServiceName == "" {
-		fallbackServiceName = localComponent
+	if res.Endpoint == nil && localComponent != "" {
+		res.Endpoint = &endpoint{ServiceName: localComponent}
 	}
 
-	attributes = &tracepb.Span_Attributes{
+	res.Attributes = &tracepb.Span_Attributes{
 		AttributeMap: attributeMap,
 	}
-	return attributes, status, fallbackServiceName
+	return res
 }
 
 var errNotEnoughBytes = errors.New("not enough bytes representing the number")
@@ -231,7 +258,7 @@ func bytesInt16ToInt64(b []byte) (int64, error) {
 	if len(b) < minSliceLength {
 		return 0, errNotEnoughBytes
 	}
-	return int64(binary.BigEndian.Uint16(b[:minSliceLength])), nil
+	return int64(int16(binary.BigEndian.Uint16(b[:minSliceLength]))), nil
 }
 
 func bytesInt32ToInt64(b []byte) (int64, error) {
@@ -239,7 +266,7 @@ func bytesInt32ToInt64(b []byte) (int64, error) {
 	if len(b) < minSliceLength {
 		return 0, errNotEnoughBytes
 	}
-	return int64(binary.BigEndian.Uint32(b[:minSliceLength])), nil
+	return int64(int32(binary.BigEndian.Uint32(b[:minSliceLength]))), nil
 }
 
 func bytesInt64ToInt64(b []byte) (int64, error) {
@@ -23,6 +23,7 @@ import (
 	"sort"
 	"testing"
 
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
 
@@ -95,6 +96,58 @@ func TestV1ThriftToOCProto(t *testing.T) {
 	}
 }
 
+func TestV1ThriftRemoteEndpoint(t *testing.T) {
+	host := &zipkincore.Endpoint{Ipv4: 0x0a000004, Port: 8080, ServiceName: "frontend"}
+	zSpans := []*zipkincore.Span{{
+		TraceID: 1,
+		ID:      2,
+		Name:    "get /inventory",
+		BinaryAnnotations: []*zipkincore.BinaryAnnotation{
+			{
+				Key:            "http.path",
+				Value:          []byte("/inventory"),
+				AnnotationType: zipkincore.AnnotationType_STRING,
+				Host:           host,
+			},
+			{
+				Key:            zipkincore.SERVER_ADDR,
+				Value:          []byte{1},
+				AnnotationType: zipkincore.AnnotationType_BOOL,
+				Host:           &zipkincore.Endpoint{Ipv4: 0x0a000005, Port: 9000, ServiceName: "inventory"},
+			},
+		},
+	}}
+
+	reqs, err := V1ThriftBatchToOCProto(zSpans)
+	if err != nil {
+		t.Fatalf("failed to translate zipkinv1 thrift to OC proto: %v", err)
+	}
+	if len(reqs) != 1 || len(reqs[0].Spans) != 1 {
+		t.Fatalf("got %v, want a single span", reqs)
+	}
+
+	wantNode := &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+		Attributes: map[string]string{
+			"ipv4":                              "10.0.0.4",
+			"port":                              "8080",
+			"zipkin.remoteEndpoint.ipv4":        "10.0.0.5",
+			"zipkin.remoteEndpoint.port":        "9000",
+			"zipkin.remoteEndpoint.serviceName": "inventory",
+		},
+	}
+	if !reflect.DeepEqual(reqs[0].Node, wantNode) {
+		t.Errorf("got node %v, want %v", reqs[0].Node, wantNode)
+	}
+	span := reqs[0].Spans[0]
+	if span.Kind != tracepb.Span_CLIENT {
+		t.Errorf("got kind %v, want %v", span.Kind, tracepb.Span_CLIENT)
+	}
+	if _, ok := span.Attributes.AttributeMap[zipkincore.SERVER_ADDR]; ok {
+		t.Errorf("got the address annotation in the attributes %v", span.Attributes.AttributeMap)
+	}
+}
+
 func BenchmarkV1ThriftToOCProto(b *testing.B) {
 	blob, err := ioutil.ReadFile("./testdata/zipkin_v1_thrift_single_batch.json")
 	if err != nil {
@@ -497,6 +550,12 @@ func Test_bytesInt16ToInt64(t *testing.T) {
 			want:    128,
 			wantErr: nil,
 		},
+		{
+			name:    "negative number",
+			bytes:   []byte{0xff, 0xfe},
+			want:    -2,
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -537,6 +596,12 @@ func Test_bytesInt32ToInt64(t *testing.T) {
 			want:    128,
 			wantErr: nil,
 		},
+		{
+			name:    "negative number",
+			bytes:   []byte{0xff, 0xff, 0xff, 0xfe},
+			want:    -2,
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
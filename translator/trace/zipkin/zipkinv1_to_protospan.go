@@ -15,6 +15,7 @@
 package zipkin
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -78,11 +79,66 @@ type annotation struct {
 
 // binaryAnnotation used by zipkinV1Span.
 type binaryAnnotation struct {
-	Key      string    `json:"key"`
-	Value    string    `json:"value"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Type is the type of the value, one of the zipkincore.AnnotationType
+	// names, e.g. "I64". The type is inferred from the value if it is empty.
+	Type     string    `json:"type,omitempty"`
 	Endpoint *endpoint `json:"endpoint"`
 }
 
+// UnmarshalJSON decodes a binary annotation whose value can be a JSON string,
+// boolean or number, the type of the non-string values being used unless the
+// annotation has an explicit type.
+func (ba *binaryAnnotation) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Key      string          `json:"key"`
+		Value    json.RawMessage `json:"value"`
+		Type     string          `json:"type"`
+		Endpoint *endpoint       `json:"endpoint"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	ba.Key, ba.Type, ba.Endpoint = raw.Key, raw.Type, raw.Endpoint
+	ba.Value = ""
+
+	value := bytes.TrimSpace(raw.Value)
+	if len(value) == 0 {
+		return nil
+	}
+	switch value[0] {
+	case '"':
+		return json.Unmarshal(value, &ba.Value)
+	case 't', 'f':
+		var bValue bool
+		if err := json.Unmarshal(value, &bValue); err != nil {
+			return err
+		}
+		ba.Value = strconv.FormatBool(bValue)
+		if ba.Type == "" {
+			ba.Type = zipkincore.AnnotationType_BOOL.String()
+		}
+	case 'n':
+		// null, the annotation has no value.
+	case '{', '[':
+		return fmt.Errorf("binary annotation %q has a non scalar value", raw.Key)
+	default:
+		var number json.Number
+		if err := json.Unmarshal(value, &number); err != nil {
+			return err
+		}
+		ba.Value = number.String()
+		if ba.Type == "" {
+			ba.Type = zipkincore.AnnotationType_DOUBLE.String()
+			if _, err := number.Int64(); err == nil {
+				ba.Type = zipkincore.AnnotationType_I64.String()
+			}
+		}
+	}
+	return nil
+}
+
 // V1JSONBatchToOCProto converts a JSON blob with a list of Zipkin v1 spans to OC Proto.
 func V1JSONBatchToOCProto(blob []byte) ([]consumerdata.TraceData, error) {
 	var zSpans []*zipkinV1Span
@@ -115,7 +171,7 @@ func zipkinToOCProtoBatch(ocSpansAndParsedAnnotations []ocSpanAndParsedAnnotatio
 	// Service to batch maps the service name to the trace request with the corresponding node.
 	svcToTD := make(map[string]*consumerdata.TraceData)
 	for _, curr := range ocSpansAndParsedAnnotations {
-		req := getOrCreateNodeRequest(svcToTD, curr.parsedAnnotations.Endpoint)
+		req := getOrCreateNodeRequest(svcToTD, curr.parsedAnnotations.Endpoint, curr.parsedAnnotations.RemoteEndpoint)
 		req.Spans = append(req.Spans, curr.ocSpan)
 	}
 
@@ -145,9 +201,13 @@ func zipkinV1ToOCSpan(zSpan *zipkinV1Span) (*tracepb.Span, *annotationParseResul
 	}
 
 	parsedAnnotations := parseZipkinV1Annotations(zSpan.Annotations)
-	attributes, ocStatus, localComponent := zipkinV1BinAnnotationsToOCAttributes(zSpan.BinaryAnnotations)
-	if parsedAnnotations.Endpoint.ServiceName == unknownServiceName && localComponent != "" {
-		parsedAnnotations.Endpoint.ServiceName = localComponent
+	parsedBinAnnotations := parseZipkinV1BinAnnotations(zSpan.BinaryAnnotations)
+	if parsedAnnotations.Endpoint.ServiceName == unknownServiceName && parsedBinAnnotations.Endpoint != nil {
+		parsedAnnotations.Endpoint = parsedBinAnnotations.Endpoint
+	}
+	parsedAnnotations.RemoteEndpoint = parsedBinAnnotations.RemoteEndpoint
+	if parsedAnnotations.Kind == tracepb.Span_SPAN_KIND_UNSPECIFIED {
+		parsedAnnotations.Kind = parsedBinAnnotations.Kind
 	}
 	var startTime, endTime *timestamp.Timestamp
 	if zSpan.Timestamp == 0 {
@@ -162,13 +222,14 @@ func zipkinV1ToOCSpan(zSpan *zipkinV1Span) (*tracepb.Span, *annotationParseResul
 		TraceId:      traceID,
 		SpanId:       spanID,
 		ParentSpanId: parentID,
-		Status:       ocStatus,
+		Status:       parsedBinAnnotations.Status,
 		Kind:         parsedAnnotations.Kind,
 		TimeEvents:   parsedAnnotations.TimeEvents,
 		StartTime:    startTime,
 		EndTime:      endTime,
-		Attributes:   attributes,
+		Attributes:   parsedBinAnnotations.Attributes,
 	}
+	tracetranslator.OCSpanFieldsFromAttributes(ocSpan)
 
 	if zSpan.Name != "" {
 		ocSpan.Name = &tracepb.TruncatableString{Value: zSpan.Name}
@@ -177,28 +238,54 @@ func zipkinV1ToOCSpan(zSpan *zipkinV1Span) (*tracepb.Span, *annotationParseResul
 	return ocSpan, parsedAnnotations, nil
 }
 
-func zipkinV1BinAnnotationsToOCAttributes(binAnnotations []*binaryAnnotation) (attributes *tracepb.Span_Attributes, status *tracepb.Status, fallbackServiceName string) {
+// binAnnotationParseResult stores the results of examining the original binary
+// annotations.
+type binAnnotationParseResult struct {
+	Attributes *tracepb.Span_Attributes
+	Status     *tracepb.Status
+	// Endpoint is the local endpoint of the span if it has no core
+	// annotations, nil if unknown.
+	Endpoint *endpoint
+	// RemoteEndpoint is the endpoint of the address annotation, nil if none.
+	RemoteEndpoint *endpoint
+	// Kind is the kind of the span implied by the address annotation.
+	Kind tracepb.Span_SpanKind
+}
+
+func parseZipkinV1BinAnnotations(binAnnotations []*binaryAnnotation) *binAnnotationParseResult {
+	res := &binAnnotationParseResult{}
 	if len(binAnnotations) == 0 {
-		return nil, nil, ""
+		return res
 	}
 
 	sMapper := &statusMapper{}
 	var localComponent string
 	attributeMap := make(map[string]*tracepb.AttributeValue)
 	for _, binAnnotation := range binAnnotations {
+		if binAnnotation == nil {
+			continue
+		}
 
-		if binAnnotation.Endpoint != nil && binAnnotation.Endpoint.ServiceName != "" {
-			fallbackServiceName = binAnnotation.Endpoint.ServiceName
+		// The address annotations hold the remote endpoint of the span, their
+		// value is always true.
+		switch binAnnotation.Key {
+		case zipkincore.CLIENT_ADDR, zipkincore.SERVER_ADDR, zipkincore.MESSAGE_ADDR:
+			if binAnnotation.Endpoint != nil {
+				res.RemoteEndpoint = binAnnotation.Endpoint
+				switch binAnnotation.Key {
+				case zipkincore.CLIENT_ADDR:
+					res.Kind = tracepb.Span_SERVER
+				case zipkincore.SERVER_ADDR:
+					res.Kind = tracepb.Span_CLIENT
+				}
+			}
+			continue
 		}
-		pbAttrib := &tracepb.AttributeValue{}
-		if iValue, err := strconv.ParseInt(binAnnotation.Value, 10, 64); err == nil {
-			pbAttrib.Value = &tracepb.AttributeValue_IntValue{IntValue: iValue}
-		} else if bValue, err := strconv.ParseBool(binAnnotation.Value); err == nil {
-			pbAttrib.Value = &tracepb.AttributeValue_BoolValue{BoolValue: bValue}
-		} else {
-			// For now all else go to string
-			pbAttrib.Value = &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: binAnnotation.Value}}
+
+		if binAnnotation.Endpoint != nil && binAnnotation.Endpoint.ServiceName != "" {
+			res.Endpoint = binAnnotation.Endpoint
 		}
+		pbAttrib := binAnnotationValueToOCValue(binAnnotation)
 
 		key := binAnnotation.Key
 
@@ -215,33 +302,78 @@ func zipkinV1BinAnnotationsToOCAttributes(binAnnotations []*binaryAnnotation) (a
 		attributeMap[key] = pbAttrib
 	}
 
-	status = sMapper.ocStatus()
+	res.Status = sMapper.ocStatus()
 
 	if len(attributeMap) == 0 {
-		return nil, status, ""
+		res.Endpoint = nil
+		return res
 	}
 
-	if fallbackServiceName == "" {
-		fallbackServiceName = localComponent
+	if res.Endpoint == nil && localComponent != "" {
+		res.Endpoint = &endpoint{ServiceName: localComponent}
 	}
 
-	attributes = &tracepb.Span_Attributes{
+	res.Attributes = &tracepb.Span_Attributes{
 		AttributeMap: attributeMap,
 	}
 
-	return attributes, status, fallbackServiceName
+	return res
+}
+
+// binAnnotationValueToOCValue converts the value of a binary annotation
+// according to its type. The values without a type are integers or booleans
+// if they parse as such, strings otherwise.
+func binAnnotationValueToOCValue(binAnnotation *binaryAnnotation) *tracepb.AttributeValue {
+	value := binAnnotation.Value
+	switch binAnnotation.Type {
+	case "":
+		if iValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: iValue}}
+		} else if bValue, err := strconv.ParseBool(value); err == nil {
+			return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: bValue}}
+		}
+		// For now all else go to string
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: value}}}
+	case zipkincore.AnnotationType_BOOL.String():
+		bValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return &tracepb.AttributeValue{Value: strAttributeForError(err)}
+		}
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: bValue}}
+	case zipkincore.AnnotationType_I16.String(), zipkincore.AnnotationType_I32.String(), zipkincore.AnnotationType_I64.String():
+		iValue, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return &tracepb.AttributeValue{Value: strAttributeForError(err)}
+		}
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: iValue}}
+	case zipkincore.AnnotationType_DOUBLE.String():
+		dValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return &tracepb.AttributeValue{Value: strAttributeForError(err)}
+		}
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: dValue}}
+	case zipkincore.AnnotationType_STRING.String(), zipkincore.AnnotationType_BYTES.String():
+		// The bytes are base64 encoded in JSON.
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: value}}}
+	default:
+		return &tracepb.AttributeValue{Value: strAttributeForError(fmt.Errorf("unknown zipkin v1 binary annotation type (%s)", binAnnotation.Type))}
+	}
 }
 
 // annotationParseResult stores the results of examining the original annotations,
 // this way multiple passes on the annotations are not needed.
 type annotationParseResult struct {
 	Endpoint            *endpoint
+	RemoteEndpoint      *endpoint
 	TimeEvents          *tracepb.Span_TimeEvents
 	Kind                tracepb.Span_SpanKind
 	EarlyAnnotationTime *timestamp.Timestamp
 	LateAnnotationTime  *timestamp.Timestamp
 }
 
+// remoteEndpointPrefix prefixes the node attributes of the remote endpoint.
+const remoteEndpointPrefix = "zipkin.remoteEndpoint."
+
 // Unknown service name works both as a default value and a flag to indicate that a valid endpoint was found.
 const unknownServiceName = "unknown-service"
 
@@ -252,7 +384,7 @@ func parseZipkinV1Annotations(annotations []*annotation) *annotationParseResult
 	res := &annotationParseResult{}
 	timeEvents := make([]*tracepb.Span_TimeEvent, 0, len(annotations))
 	for _, currAnnotation := range annotations {
-		if currAnnotation == nil && currAnnotation.Value == "" {
+		if currAnnotation == nil || currAnnotation.Value == "" {
 			continue
 		}
 
@@ -382,9 +514,12 @@ func epochMicrosecondsToTimestamp(msecs int64) *timestamp.Timestamp {
 	return t
 }
 
-func getOrCreateNodeRequest(m map[string]*consumerdata.TraceData, endpoint *endpoint) *consumerdata.TraceData {
+func getOrCreateNodeRequest(m map[string]*consumerdata.TraceData, endpoint, remoteEndpoint *endpoint) *consumerdata.TraceData {
 	// this private function assumes that the caller never passes an nil endpoint
 	nodeKey := endpoint.string()
+	if remoteEndpoint != nil {
+		nodeKey += "|" + remoteEndpoint.string()
+	}
 	req := m[nodeKey]
 
 	if req != nil {
@@ -400,6 +535,23 @@ func getOrCreateNodeRequest(m map[string]*consumerdata.TraceData, endpoint *endp
 	if attributeMap := endpoint.createAttributeMap(); attributeMap != nil {
 		req.Node.Attributes = attributeMap
 	}
+	if remoteEndpoint != nil {
+		// Like for the Zipkin v2 spans, the fields of the remote endpoint are
+		// prefixed with "zipkin.remoteEndpoint.".
+		remoteAttributes := remoteEndpoint.createAttributeMap()
+		if remoteEndpoint.ServiceName != "" {
+			if remoteAttributes == nil {
+				remoteAttributes = make(map[string]string, 1)
+			}
+			remoteAttributes["serviceName"] = remoteEndpoint.ServiceName
+		}
+		for key, value := range remoteAttributes {
+			if req.Node.Attributes == nil {
+				req.Node.Attributes = make(map[string]string, len(remoteAttributes))
+			}
+			req.Node.Attributes[remoteEndpointPrefix+key] = value
+		}
+	}
 
 	m[nodeKey] = req
 
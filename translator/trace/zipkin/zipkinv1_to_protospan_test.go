@@ -167,6 +167,118 @@ func TestZipkinJSONFallbackToLocalComponent(t *testing.T) {
 	}
 }
 
+func TestZipkinJSONBinaryAnnotationsAndRemoteEndpoints(t *testing.T) {
+	blob, err := ioutil.ReadFile("./testdata/zipkin_v1_binary_annotations.json")
+	if err != nil {
+		t.Fatalf("failed to load test data: %v", err)
+	}
+	reqs, err := V1JSONBatchToOCProto(blob)
+	if err != nil {
+		t.Fatalf("failed to translate zipkinv1 to OC proto: %v", err)
+	}
+	sortTraceByNodeName(reqs)
+
+	want := []consumerdata.TraceData{
+		{
+			Node: &commonpb.Node{
+				ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"},
+				Attributes: map[string]string{
+					"ipv4":                              "172.31.0.4",
+					"port":                              "8080",
+					"zipkin.remoteEndpoint.ipv4":        "172.31.0.5",
+					"zipkin.remoteEndpoint.port":        "9000",
+					"zipkin.remoteEndpoint.serviceName": "inventory",
+				},
+			},
+		},
+		{
+			Node: &commonpb.Node{
+				ServiceInfo: &commonpb.ServiceInfo{Name: "inventory"},
+				Attributes: map[string]string{
+					"ipv4":                              "172.31.0.5",
+					"port":                              "9000",
+					"zipkin.remoteEndpoint.ipv6":        "::1",
+					"zipkin.remoteEndpoint.serviceName": "frontend",
+				},
+			},
+		},
+	}
+	if len(reqs) != len(want) {
+		t.Fatalf("got %d trace service request(s), want %d", len(reqs), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(reqs[i].Node, want[i].Node) {
+			t.Errorf("#%d: got node %v, want %v", i, reqs[i].Node, want[i].Node)
+		}
+		if len(reqs[i].Spans) != 1 {
+			t.Fatalf("#%d: got %d span(s), want 1", i, len(reqs[i].Spans))
+		}
+	}
+
+	// The address annotation of the client span gives its kind.
+	client := reqs[0].Spans[0]
+	if client.Kind != tracepb.Span_CLIENT {
+		t.Errorf("got kind %v for the client span, want %v", client.Kind, tracepb.Span_CLIENT)
+	}
+	wantAttributes := &tracepb.Span_Attributes{
+		AttributeMap: map[string]*tracepb.AttributeValue{
+			"http.path": {
+				Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "/inventory"}},
+			},
+			tracetranslator.TagHTTPStatusCode: {
+				Value: &tracepb.AttributeValue_IntValue{IntValue: 200},
+			},
+			"cache.hit": {
+				Value: &tracepb.AttributeValue_BoolValue{BoolValue: false},
+			},
+			"retries": {
+				Value: &tracepb.AttributeValue_IntValue{IntValue: 2},
+			},
+			"ratio": {
+				Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 0.25},
+			},
+		},
+	}
+	if !reflect.DeepEqual(client.Attributes, wantAttributes) {
+		t.Errorf("Unsuccessful conversion\nGot:\n\t%v\nWant:\n\t%v", client.Attributes, wantAttributes)
+	}
+
+	server := reqs[1].Spans[0]
+	if server.Kind != tracepb.Span_SERVER {
+		t.Errorf("got kind %v for the server span, want %v", server.Kind, tracepb.Span_SERVER)
+	}
+	if server.Attributes != nil {
+		t.Errorf("got attributes %v for the server span, want none", server.Attributes)
+	}
+}
+
+func TestBinaryAnnotationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    binaryAnnotation
+		wantErr bool
+	}{
+		{json: `{"key": "k", "value": "v"}`, want: binaryAnnotation{Key: "k", Value: "v"}},
+		{json: `{"key": "k", "value": true}`, want: binaryAnnotation{Key: "k", Value: "true", Type: "BOOL"}},
+		{json: `{"key": "k", "value": 42}`, want: binaryAnnotation{Key: "k", Value: "42", Type: "I64"}},
+		{json: `{"key": "k", "value": 4.2}`, want: binaryAnnotation{Key: "k", Value: "4.2", Type: "DOUBLE"}},
+		{json: `{"key": "k", "value": 42, "type": "DOUBLE"}`, want: binaryAnnotation{Key: "k", Value: "42", Type: "DOUBLE"}},
+		{json: `{"key": "k", "value": null}`, want: binaryAnnotation{Key: "k"}},
+		{json: `{"key": "k", "value": {"nested": 1}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		var got binaryAnnotation
+		err := json.Unmarshal([]byte(tt.json), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tt.json, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.json, got, tt.want)
+		}
+	}
+}
+
 func TestSingleJSONV1BatchToOCProto(t *testing.T) {
 	blob, err := ioutil.ReadFile("./testdata/zipkin_v1_single_batch.json")
 	if err != nil {